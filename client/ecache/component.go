@@ -0,0 +1,339 @@
+package ecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// PackageName 包名
+const PackageName = "client.ecache"
+
+// ErrNotFound 缓存中不存在该数据，loader返回该错误时会缓存空值
+var ErrNotFound = errors.New("ecache: not found")
+
+const (
+	tierLocal  = "local"
+	tierRemote = "remote"
+	tierLoader = "loader"
+
+	flagValue    byte = 0 // 正常数据
+	flagNegative byte = 1 // 空值，数据不存在
+)
+
+// invalidation 实例间同步的失效消息
+type invalidation struct {
+	Instance string   `json:"instance"`
+	Keys     []string `json:"keys"`
+}
+
+// Component 本地+远程两级缓存
+type Component struct {
+	name        string
+	config      *Config
+	logger      *elog.Component
+	codec       Codec
+	local       *lru
+	remote      remote
+	group       singleflight.Group
+	instanceID  string
+	channel     string
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	closeOnce   sync.Once
+	unsubscribe func() // 取消订阅凭证轮换
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, codec Codec, r remote) *Component {
+	hostname, _ := os.Hostname()
+	c := &Component{
+		name:       name,
+		config:     config,
+		logger:     logger,
+		codec:      codec,
+		remote:     r,
+		instanceID: hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		channel:    "ecache:invalidate:" + name,
+		cancel:     func() {},
+	}
+	if config.LocalMaxEntries > 0 {
+		c.local = newLRU(config.LocalMaxEntries)
+	}
+	// 只有同时存在本地缓存和远程缓存时，才需要在实例间同步失效
+	if c.local != nil && c.remote != nil && config.EnableInvalidation {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.cancel = cancel
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.remote.subscribe(ctx, c.channel, c.onInvalidation)
+		}()
+	}
+	return c
+}
+
+// Get 读取缓存并解码到dest，依次查找本地缓存和远程缓存，都不存在时返回ErrNotFound
+func (c *Component) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.lookup(ctx, key)
+	if err != nil {
+		return err
+	}
+	return c.decode(data, dest)
+}
+
+// Set 写入缓存，同时通知其他实例清除本地缓存
+func (c *Component) Set(ctx context.Context, key string, value interface{}) error {
+	payload, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("ecache marshal fail, key: %s, err: %w", key, err)
+	}
+	if err := c.store(ctx, key, append([]byte{flagValue}, payload...), c.config.LocalTTL, c.config.RemoteTTL); err != nil {
+		return err
+	}
+	c.publish(ctx, key)
+	return nil
+}
+
+// Delete 删除缓存，同时通知其他实例清除本地缓存
+func (c *Component) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if c.local != nil {
+		c.local.delete(keys...)
+	}
+	if c.remote != nil {
+		remoteKeys := make([]string, 0, len(keys))
+		for _, key := range keys {
+			remoteKeys = append(remoteKeys, c.config.KeyPrefix+key)
+		}
+		err := c.remote.delete(ctx, remoteKeys...)
+		c.metric(tierRemote, "del", resultCode(err), time.Time{})
+		if err != nil {
+			return fmt.Errorf("ecache delete fail, err: %w", err)
+		}
+	}
+	c.publish(ctx, keys...)
+	return nil
+}
+
+// Fetch 读取缓存，不存在时调用loader加载并写入缓存
+// 同一个key同时只会有一个loader在执行，其他的请求等待并共享结果
+// loader返回ErrNotFound时，在NegativeTTL内缓存空值，Fetch返回ErrNotFound
+func (c *Component) Fetch(ctx context.Context, key string, dest interface{}, loader func(ctx context.Context) (interface{}, error)) error {
+	data, err := c.lookup(ctx, key)
+	if err == nil {
+		return c.decode(data, dest)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		// 远程缓存不可用时降级为直接加载
+		c.logger.Warn("ecache get fail, fallback to loader", elog.FieldKey(key), elog.FieldErr(err))
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		beg := time.Now()
+		value, err := loader(ctx)
+		c.metric(tierLoader, "load", resultCode(err), beg)
+		if errors.Is(err, ErrNotFound) {
+			if c.config.NegativeTTL > 0 {
+				c.storeLog(ctx, key, []byte{flagNegative}, c.config.NegativeTTL, c.config.NegativeTTL)
+			}
+			return []byte{flagNegative}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		payload, err := c.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("ecache marshal fail, key: %s, err: %w", key, err)
+		}
+		data := append([]byte{flagValue}, payload...)
+		c.storeLog(ctx, key, data, c.config.LocalTTL, c.config.RemoteTTL)
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.decode(v.([]byte), dest)
+}
+
+// Ping 检查远程缓存是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote.ping(ctx)
+}
+
+// Close 停止订阅失效消息，关闭组件创建的Redis客户端
+func (c *Component) Close() (err error) {
+	c.closeOnce.Do(func() {
+		if c.unsubscribe != nil {
+			c.unsubscribe()
+		}
+		c.cancel()
+		c.wg.Wait()
+		if c.remote != nil {
+			err = c.remote.close()
+		}
+	})
+	return err
+}
+
+// RefreshCredential 实现 esecret.CredentialRefresher，凭证轮换后新建立的Redis连接使用新凭证
+func (c *Component) RefreshCredential(ctx context.Context, cred esecret.Credential) error {
+	r, ok := c.remote.(*redisRemote)
+	if !ok {
+		return errors.New("ecache has no redis remote")
+	}
+	return r.refreshCredential(ctx, cred)
+}
+
+// GetOrLoad 泛型版本的Fetch
+func GetOrLoad[T any](ctx context.Context, c *Component, key string, loader func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	err := c.Fetch(ctx, key, &value, func(ctx context.Context) (interface{}, error) {
+		return loader(ctx)
+	})
+	return value, err
+}
+
+// lookup 返回编码后的缓存数据，包括空值，缓存中不存在时返回ErrNotFound
+func (c *Component) lookup(ctx context.Context, key string) ([]byte, error) {
+	if c.local != nil {
+		data, ok := c.local.get(key)
+		c.metric(tierLocal, "get", hitCode(ok), time.Time{})
+		if ok {
+			return data, nil
+		}
+	}
+	if c.remote == nil {
+		return nil, ErrNotFound
+	}
+	beg := time.Now()
+	data, ok, err := c.remote.get(ctx, c.config.KeyPrefix+key)
+	if err != nil {
+		c.metric(tierRemote, "get", resultCode(err), beg)
+		return nil, fmt.Errorf("ecache get fail, key: %s, err: %w", key, err)
+	}
+	c.metric(tierRemote, "get", hitCode(ok), beg)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if c.local != nil {
+		ttl := c.config.LocalTTL
+		if len(data) > 0 && data[0] == flagNegative && c.config.NegativeTTL < ttl {
+			ttl = c.config.NegativeTTL
+		}
+		c.local.set(key, data, c.jitter(ttl))
+	}
+	return data, nil
+}
+
+// store 写入本地缓存和远程缓存
+func (c *Component) store(ctx context.Context, key string, data []byte, localTTL, remoteTTL time.Duration) error {
+	if c.local != nil {
+		c.local.set(key, data, c.jitter(localTTL))
+	}
+	if c.remote == nil {
+		return nil
+	}
+	beg := time.Now()
+	err := c.remote.set(ctx, c.config.KeyPrefix+key, data, c.jitter(remoteTTL))
+	c.metric(tierRemote, "set", resultCode(err), beg)
+	if err != nil {
+		return fmt.Errorf("ecache set fail, key: %s, err: %w", key, err)
+	}
+	return nil
+}
+
+// storeLog 写入缓存，失败只记录日志，用于加载数据后回填缓存
+func (c *Component) storeLog(ctx context.Context, key string, data []byte, localTTL, remoteTTL time.Duration) {
+	if err := c.store(ctx, key, data, localTTL, remoteTTL); err != nil {
+		c.logger.Warn("ecache store fail", elog.FieldKey(key), elog.FieldErr(err))
+	}
+}
+
+// publish 通知其他实例清除本地缓存
+func (c *Component) publish(ctx context.Context, keys ...string) {
+	if c.local == nil || c.remote == nil || !c.config.EnableInvalidation {
+		return
+	}
+	payload, _ := json.Marshal(invalidation{Instance: c.instanceID, Keys: keys})
+	if err := c.remote.publish(ctx, c.channel, payload); err != nil {
+		c.logger.Warn("ecache publish invalidation fail", elog.FieldErr(err))
+	}
+}
+
+// onInvalidation 收到其他实例的失效消息后清除本地缓存
+func (c *Component) onInvalidation(payload []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		c.logger.Warn("ecache invalid invalidation message", elog.FieldErr(err))
+		return
+	}
+	if msg.Instance == c.instanceID {
+		return
+	}
+	c.local.delete(msg.Keys...)
+}
+
+// decode 解码缓存数据，空值返回ErrNotFound
+func (c *Component) decode(data []byte, dest interface{}) error {
+	if len(data) == 0 || data[0] == flagNegative {
+		return ErrNotFound
+	}
+	if err := c.codec.Unmarshal(data[1:], dest); err != nil {
+		return fmt.Errorf("ecache unmarshal fail, err: %w", err)
+	}
+	return nil
+}
+
+// jitter 为过期时间增加随机抖动，避免大量key同时过期
+func (c *Component) jitter(ttl time.Duration) time.Duration {
+	if c.config.TTLJitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*c.config.TTLJitter*float64(ttl))
+}
+
+// metric 记录缓存的命中、写入和加载情况，beg为零值时不记录耗时
+func (c *Component) metric(tier string, action string, code string, beg time.Time) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.CacheHandleCounter.Inc(tier, c.name, action, code)
+	if !beg.IsZero() {
+		emetric.CacheHandleHistogram.Observe(time.Since(beg).Seconds(), tier, c.name, action)
+	}
+}
+
+func hitCode(hit bool) string {
+	if hit {
+		return "hit"
+	}
+	return "miss"
+}
+
+func resultCode(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrNotFound):
+		return "notfound"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,312 @@
+package ecache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// fakeRemote 内存实现的远程缓存，多个组件共享同一个fakeRemote模拟多个实例
+type fakeRemote struct {
+	mu      sync.Mutex
+	data    map[string][]byte
+	subs    map[string][]chan []byte
+	closed  bool
+	getErr  error
+	getCall int32
+}
+
+func newFakeRemote() *fakeRemote {
+	return &fakeRemote{data: make(map[string][]byte), subs: make(map[string][]chan []byte)}
+}
+
+func (f *fakeRemote) get(_ context.Context, key string) ([]byte, bool, error) {
+	atomic.AddInt32(&f.getCall, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.getErr != nil {
+		return nil, false, f.getErr
+	}
+	v, ok := f.data[key]
+	return v, ok, nil
+}
+
+func (f *fakeRemote) set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key] = value
+	return nil
+}
+
+func (f *fakeRemote) delete(_ context.Context, keys ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		delete(f.data, key)
+	}
+	return nil
+}
+
+func (f *fakeRemote) publish(_ context.Context, channel string, payload []byte) error {
+	f.mu.Lock()
+	subs := append([]chan []byte(nil), f.subs[channel]...)
+	f.mu.Unlock()
+	for _, ch := range subs {
+		ch <- payload
+	}
+	return nil
+}
+
+func (f *fakeRemote) subscribe(ctx context.Context, channel string, fn func(payload []byte)) {
+	ch := make(chan []byte, 16)
+	f.mu.Lock()
+	f.subs[channel] = append(f.subs[channel], ch)
+	f.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload := <-ch:
+			fn(payload)
+		}
+	}
+}
+
+func (f *fakeRemote) ping(context.Context) error { return nil }
+
+func (f *fakeRemote) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeRemote) subscribers(channel string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs[channel])
+}
+
+func newTestComponent(r remote, config *Config) *Component {
+	config.KeyPrefix = "test:"
+	return newComponent("test", config, elog.EgoLogger, jsonCodec{}, r)
+}
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestLRU(t *testing.T) {
+	l := newLRU(2)
+	l.set("a", []byte("1"), time.Minute)
+	l.set("b", []byte("2"), time.Minute)
+	_, ok := l.get("a")
+	assert.True(t, ok)
+	// b最久没有访问，被淘汰
+	l.set("c", []byte("3"), time.Minute)
+	_, ok = l.get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, l.len())
+
+	l.set("d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = l.get("d")
+	assert.False(t, ok)
+}
+
+func TestGetSetDelete(t *testing.T) {
+	r := newFakeRemote()
+	comp := newTestComponent(r, DefaultConfig())
+	defer comp.Close()
+	ctx := context.Background()
+
+	var u user
+	assert.ErrorIs(t, comp.Get(ctx, "u1", &u), ErrNotFound)
+
+	require.NoError(t, comp.Set(ctx, "u1", user{ID: 1, Name: "ego"}))
+	assert.Contains(t, r.data, "test:u1")
+	require.NoError(t, comp.Get(ctx, "u1", &u))
+	assert.Equal(t, user{ID: 1, Name: "ego"}, u)
+
+	// 本地缓存被淘汰后，从远程缓存读取并回填本地缓存
+	comp.local.delete("u1")
+	u = user{}
+	require.NoError(t, comp.Get(ctx, "u1", &u))
+	assert.Equal(t, 1, u.ID)
+	_, ok := comp.local.get("u1")
+	assert.True(t, ok)
+
+	require.NoError(t, comp.Delete(ctx, "u1"))
+	assert.ErrorIs(t, comp.Get(ctx, "u1", &u), ErrNotFound)
+	assert.NotContains(t, r.data, "test:u1")
+}
+
+func TestLocalOnly(t *testing.T) {
+	comp := newTestComponent(nil, DefaultConfig())
+	ctx := context.Background()
+	require.NoError(t, comp.Set(ctx, "k", "v"))
+	var v string
+	require.NoError(t, comp.Get(ctx, "k", &v))
+	assert.Equal(t, "v", v)
+	assert.NoError(t, comp.Ping(ctx))
+	assert.NoError(t, comp.Close())
+}
+
+func TestFetchSingleflight(t *testing.T) {
+	comp := newTestComponent(newFakeRemote(), DefaultConfig())
+	defer comp.Close()
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (user, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return user{ID: 2}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]user, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			u, err := GetOrLoad(context.Background(), comp, "u2", loader)
+			assert.NoError(t, err)
+			results[i] = u
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, u := range results {
+		assert.Equal(t, 2, u.ID)
+	}
+
+	// 之后直接命中缓存，不再调用loader
+	u, err := GetOrLoad(context.Background(), comp, "u2", loader)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, u.ID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetchNegative(t *testing.T) {
+	r := newFakeRemote()
+	comp := newTestComponent(r, DefaultConfig())
+	defer comp.Close()
+	ctx := context.Background()
+
+	var calls int32
+	loader := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return nil, ErrNotFound
+	}
+	var u user
+	assert.ErrorIs(t, comp.Fetch(ctx, "missing", &u, loader), ErrNotFound)
+	assert.ErrorIs(t, comp.Fetch(ctx, "missing", &u, loader), ErrNotFound)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, []byte{flagNegative}, r.data["test:missing"])
+
+	// loader的其他错误不会被缓存
+	loadErr := errors.New("db down")
+	assert.ErrorIs(t, comp.Fetch(ctx, "other", &u, func(ctx context.Context) (interface{}, error) {
+		return nil, loadErr
+	}), loadErr)
+	assert.NotContains(t, r.data, "test:other")
+}
+
+func TestFetchRemoteError(t *testing.T) {
+	r := newFakeRemote()
+	config := DefaultConfig()
+	config.LocalMaxEntries = 0
+	comp := newTestComponent(r, config)
+	defer comp.Close()
+
+	// 远程缓存不可用时降级为直接加载
+	r.getErr = errors.New("redis down")
+	var v string
+	err := comp.Fetch(context.Background(), "k", &v, func(ctx context.Context) (interface{}, error) {
+		return "loaded", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "loaded", v)
+}
+
+func TestInvalidation(t *testing.T) {
+	r := newFakeRemote()
+	a := newTestComponent(r, DefaultConfig())
+	b := newTestComponent(r, DefaultConfig())
+	assert.Eventually(t, func() bool { return r.subscribers(a.channel) == 2 }, time.Second, time.Millisecond)
+	ctx := context.Background()
+
+	require.NoError(t, a.Set(ctx, "k", "v1"))
+	var v string
+	require.NoError(t, b.Get(ctx, "k", &v))
+	assert.Equal(t, "v1", v)
+
+	// a更新后，b的本地缓存被清除，重新从远程缓存读取到新值
+	require.NoError(t, a.Set(ctx, "k", "v2"))
+	assert.Eventually(t, func() bool {
+		_, ok := b.local.get("k")
+		return !ok
+	}, time.Second, time.Millisecond)
+	require.NoError(t, b.Get(ctx, "k", &v))
+	assert.Equal(t, "v2", v)
+	// a不处理自己发出的失效消息
+	_, ok := a.local.get("k")
+	assert.True(t, ok)
+
+	assert.NoError(t, a.Close())
+	assert.NoError(t, b.Close())
+	assert.NoError(t, b.Close())
+}
+
+func TestJitter(t *testing.T) {
+	comp := newTestComponent(nil, DefaultConfig())
+	for i := 0; i < 100; i++ {
+		ttl := comp.jitter(time.Minute)
+		assert.GreaterOrEqual(t, ttl, time.Minute)
+		assert.Less(t, ttl, time.Minute+6*time.Second)
+	}
+	comp.config.TTLJitter = 0
+	assert.Equal(t, time.Minute, comp.jitter(time.Minute))
+}
+
+func TestRefreshCredential(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.RequireUserAuth("app", "p1")
+	ctx := context.Background()
+	require.NoError(t, esecret.Publish(ctx, "ecache-redis", esecret.Credential{Username: "app", Password: "p1", Version: "1"}))
+
+	c := DefaultContainer()
+	c.config.RedisAddrs = []string{s.Addr()}
+	c.config.RedisCredential = "ecache-redis"
+	c.config.EnableInvalidation = false
+	comp := c.Build()
+	defer comp.Close()
+	require.NoError(t, comp.Ping(ctx))
+
+	// 新凭证校验失败时继续使用旧凭证
+	err := esecret.Publish(ctx, "ecache-redis", esecret.Credential{Username: "app", Password: "p2", Version: "2"})
+	assert.ErrorContains(t, err, "new credential")
+
+	// 密码轮换后新建立的连接使用新凭证
+	s.RequireUserAuth("app", "p2")
+	require.NoError(t, esecret.Publish(ctx, "ecache-redis", esecret.Credential{Username: "app", Password: "p2", Version: "3"}))
+	r := comp.remote.(*redisRemote)
+	client := r.newClient(r.options)
+	defer client.Close()
+	assert.NoError(t, client.Ping(ctx).Err())
+}
@@ -0,0 +1,41 @@
+package ecache
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 缓存配置，每个缓存名称一份配置
+type Config struct {
+	KeyPrefix          string        // 远程缓存key的前缀，默认为缓存名称加冒号
+	LocalMaxEntries    int           // 本地缓存的最大条数，超过后淘汰最久没有访问的数据，0为不使用本地缓存，默认10000
+	LocalTTL           time.Duration // 本地缓存的过期时间，默认1m
+	RemoteTTL          time.Duration // 远程缓存的过期时间，默认10m
+	TTLJitter          float64       `unit:"percent"` // 过期时间的随机抖动比例，避免大量key同时过期，默认0.1
+	NegativeTTL        time.Duration // 数据不存在时的缓存时间，避免缓存穿透，0为不缓存，默认30s
+	RedisAddrs         []string      // Redis地址，为空时只使用本地缓存，多个地址时使用集群模式
+	RedisUsername      string        // Redis用户名
+	RedisPassword      string        // Redis密码
+	RedisDB            int           // Redis DB
+	RedisTimeout       time.Duration // Redis读写的超时，默认200ms
+	RedisCredential    string        // Redis凭证名称，配置后使用esecret中的用户名和密码，凭证轮换后新建立的连接使用新凭证
+	EnableInvalidation bool          // 是否通过Redis pub/sub在实例间同步本地缓存的失效，默认开启
+	EnableMetric       bool          // 是否开启监控，默认开启
+	HealthCritical     bool          // Redis健康检查失败时是否影响服务就绪，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		LocalMaxEntries:    10000,
+		LocalTTL:           xtime.Duration("1m"),
+		RemoteTTL:          xtime.Duration("10m"),
+		TTLJitter:          0.1,
+		NegativeTTL:        xtime.Duration("30s"),
+		RedisTimeout:       xtime.Duration("200ms"),
+		EnableInvalidation: true,
+		EnableMetric:       true,
+		HealthCritical:     true,
+	}
+}
@@ -0,0 +1,113 @@
+package ecache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	codec  Codec
+	remote remote
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		codec:  jsonCodec{},
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithRedis 使用已有的Redis客户端作为远程缓存，组件停止时不会关闭该客户端
+func WithRedis(client redis.UniversalClient) Option {
+	return func(c *Container) {
+		c.remote = &redisRemote{client: client}
+	}
+}
+
+// WithCodec 设置缓存数据的编解码，默认使用JSON
+func WithCodec(codec Codec) Option {
+	return func(c *Container) {
+		c.codec = codec
+	}
+}
+
+// Build 创建缓存，配置了Redis时使用本地+Redis两级缓存，否则只使用本地缓存
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	if c.remote == nil && len(c.config.RedisAddrs) > 0 {
+		r := &redisRemote{
+			options: &redis.UniversalOptions{
+				Addrs:        c.config.RedisAddrs,
+				Username:     c.config.RedisUsername,
+				Password:     c.config.RedisPassword,
+				DB:           c.config.RedisDB,
+				ReadTimeout:  c.config.RedisTimeout,
+				WriteTimeout: c.config.RedisTimeout,
+			},
+			owned: true,
+		}
+		if c.config.RedisCredential != "" {
+			cred, ok := esecret.Current(c.config.RedisCredential)
+			if !ok {
+				c.logger.Panic("redis credential not found, build esecret component first", elog.FieldName(c.config.RedisCredential))
+			}
+			r.credential.Store(&cred)
+		}
+		r.client = r.newClient(r.options)
+		c.remote = r
+	}
+	if c.config.KeyPrefix == "" && c.name != "" {
+		c.config.KeyPrefix = c.name + ":"
+	}
+
+	comp := newComponent(c.name, c.config, c.logger, c.codec, c.remote)
+	if c.remote != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.RedisTimeout)
+		defer cancel()
+		if err := comp.Ping(ctx); err != nil {
+			c.logger.Error("ping redis fail", elog.FieldErr(err))
+		}
+		if c.name != "" {
+			ehealth.Register(c.name, comp.Ping, ehealth.WithType("redis"), ehealth.WithCritical(c.config.HealthCritical), ehealth.WithTarget(strings.Join(c.config.RedisAddrs, ",")))
+		}
+	}
+	if c.config.RedisCredential != "" {
+		comp.unsubscribe = esecret.Subscribe(c.config.RedisCredential, comp)
+	}
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
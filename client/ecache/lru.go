@@ -0,0 +1,82 @@
+package ecache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru 带过期时间的本地LRU缓存
+type lru struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+func newLRU(maxEntries int) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expireAt) {
+		l.removeElement(elem)
+		return nil, false
+	}
+	l.ll.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (l *lru) set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expireAt := time.Now().Add(ttl)
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		l.ll.MoveToFront(elem)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for l.ll.Len() > l.maxEntries {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+func (l *lru) delete(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if elem, ok := l.items[key]; ok {
+			l.removeElement(elem)
+		}
+	}
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *lru) removeElement(elem *list.Element) {
+	l.ll.Remove(elem)
+	delete(l.items, elem.Value.(*lruEntry).key)
+}
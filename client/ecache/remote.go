@@ -0,0 +1,124 @@
+package ecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// remote 远程缓存
+type remote interface {
+	get(ctx context.Context, key string) ([]byte, bool, error)
+	set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	delete(ctx context.Context, keys ...string) error
+	publish(ctx context.Context, channel string, payload []byte) error
+	// subscribe 订阅频道，收到消息时回调fn，直到ctx取消
+	subscribe(ctx context.Context, channel string, fn func(payload []byte))
+	ping(ctx context.Context) error
+	close() error
+}
+
+// redisRemote 基于Redis的远程缓存
+type redisRemote struct {
+	client     redis.UniversalClient
+	owned      bool // 由组件创建的client，停止时关闭
+	options    *redis.UniversalOptions
+	credential atomic.Pointer[esecret.Credential] // 使用esecret凭证时，新建立的连接读取最新的凭证
+}
+
+// newClient 创建client，使用esecret凭证时，每次建立连接读取最新的凭证
+func (r *redisRemote) newClient(options *redis.UniversalOptions) redis.UniversalClient {
+	if r.credential.Load() == nil {
+		return redis.NewUniversalClient(options)
+	}
+	credentials := func() (string, string) {
+		cred := r.credential.Load()
+		return cred.Username, cred.Password
+	}
+	if len(options.Addrs) > 1 {
+		cluster := options.Cluster()
+		cluster.NewClient = func(opt *redis.Options) *redis.Client {
+			opt.CredentialsProvider = credentials
+			return redis.NewClient(opt)
+		}
+		return redis.NewClusterClient(cluster)
+	}
+	simple := options.Simple()
+	simple.CredentialsProvider = credentials
+	return redis.NewClient(simple)
+}
+
+// refreshCredential 使用新凭证建立一个连接校验凭证，校验通过后新建立的连接使用新凭证，已经建立的连接继续使用
+func (r *redisRemote) refreshCredential(ctx context.Context, cred esecret.Credential) error {
+	if r.options == nil {
+		return errors.New("redis client is not created by ecache")
+	}
+	options := *r.options
+	options.Username, options.Password = cred.Username, cred.Password
+	options.PoolSize = 1
+	client := redis.NewUniversalClient(&options)
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("ping redis with new credential fail, %w", err)
+	}
+	r.credential.Store(&cred)
+	return nil
+}
+
+func (r *redisRemote) get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *redisRemote) set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisRemote) delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *redisRemote) publish(ctx context.Context, channel string, payload []byte) error {
+	return r.client.Publish(ctx, channel, payload).Err()
+}
+
+func (r *redisRemote) subscribe(ctx context.Context, channel string, fn func(payload []byte)) {
+	// PubSub断开后会自动重连并重新订阅
+	pubsub := r.client.Subscribe(ctx, channel)
+	defer pubsub.Close()
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			fn([]byte(msg.Payload))
+		}
+	}
+}
+
+func (r *redisRemote) ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisRemote) close() error {
+	if !r.owned {
+		return nil
+	}
+	return r.client.Close()
+}
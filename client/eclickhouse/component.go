@@ -0,0 +1,196 @@
+package eclickhouse
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.eclickhouse"
+
+// Component ClickHouse组件，实现了 driver.Conn，查询和写入会记录链路、监控、访问日志和慢日志
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	tracer *etrace.Tracer
+	driver.Conn
+	mu          sync.Mutex                                     // 保护重新建立连接
+	options     *clickhouse.Options                            // 轮换凭证或者重建组件时使用该配置重新建立连接
+	open        func(*clickhouse.Options) (driver.Conn, error) // 建立连接
+	unsubscribe func()                                         // 取消订阅凭证轮换
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, conn driver.Conn) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		tracer: etrace.NewTracer(trace.SpanKindClient),
+		Conn:   conn,
+		open:   openClickhouse,
+	}
+}
+
+// Select 查询并扫描到dest切片
+func (c *Component) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return c.intercept(ctx, "select", query, func(ctx context.Context) error {
+		return c.Conn.Select(ctx, dest, query, args...)
+	})
+}
+
+// Query 查询
+func (c *Component) Query(ctx context.Context, query string, args ...any) (rows driver.Rows, err error) {
+	err = c.intercept(ctx, "query", query, func(ctx context.Context) error {
+		rows, err = c.Conn.Query(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow 查询一行
+func (c *Component) QueryRow(ctx context.Context, query string, args ...any) (row driver.Row) {
+	_ = c.intercept(ctx, "query_row", query, func(ctx context.Context) error {
+		row = c.Conn.QueryRow(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// Exec 执行语句
+func (c *Component) Exec(ctx context.Context, query string, args ...any) error {
+	return c.intercept(ctx, "exec", query, func(ctx context.Context) error {
+		return c.Conn.Exec(ctx, query, args...)
+	})
+}
+
+// AsyncInsert 服务端异步插入，小批量的写入由服务端合并
+func (c *Component) AsyncInsert(ctx context.Context, query string, wait bool, args ...any) error {
+	return c.intercept(ctx, "async_insert", query, func(ctx context.Context) error {
+		return c.Conn.AsyncInsert(ctx, query, wait, args...)
+	})
+}
+
+// PrepareBatch 准备批量写入，Send时记录链路、监控
+func (c *Component) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	var b driver.Batch
+	err := c.intercept(ctx, "prepare_batch", query, func(ctx context.Context) (err error) {
+		b, err = c.Conn.PrepareBatch(ctx, query, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch{Batch: b, comp: c, ctx: ctx, query: query}, nil
+}
+
+// Close 关闭连接
+func (c *Component) Close() error {
+	if c.unsubscribe != nil {
+		c.unsubscribe()
+	}
+	if err := c.Conn.Close(); err != nil {
+		c.logger.Error("close clickhouse fail", elog.FieldErr(err))
+		return err
+	}
+	return nil
+}
+
+// batch 批量写入，Send时记录链路、监控
+type batch struct {
+	driver.Batch
+	comp  *Component
+	ctx   context.Context
+	query string
+}
+
+// Send 发送批量写入的数据
+func (b *batch) Send() error {
+	return b.comp.intercept(b.ctx, "batch_send", b.query, func(context.Context) error {
+		return b.Batch.Send()
+	})
+}
+
+// intercept 执行操作，记录链路、监控、访问日志和慢日志
+func (c *Component) intercept(ctx context.Context, op string, query string, fn func(ctx context.Context) error) error {
+	var span trace.Span
+	if c.config.EnableTraceInterceptor {
+		ctx, span = c.tracer.Start(ctx, "clickhouse."+op, nil, trace.WithAttributes(
+			semconv.DBSystemKey.String("clickhouse"),
+			semconv.DBNameKey.String(c.config.Database),
+			semconv.DBOperationKey.String(op),
+			semconv.DBStatementKey.String(truncate(query, c.config.AccessInterceptorMaxLength)),
+			semconv.PeerServiceKey.String(c.name),
+		))
+	}
+	beg := time.Now()
+	err := fn(ctx)
+	cost := time.Since(beg)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(attribute.Int64("db.cost_ms", cost.Milliseconds()))
+		span.End()
+	}
+
+	if c.config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ClientHandleCounter.Inc(emetric.TypeClickHouse, c.name, op, c.config.Addrs[0], code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeClickHouse, c.name, op, c.config.Addrs[0])
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	if !c.config.EnableAccessInterceptor && !isSlow && err == nil {
+		return err
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(op),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+	}
+	if c.config.EnableAccessInterceptorReq || isSlow {
+		fields = append(fields, elog.String("req", truncate(query, c.config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+	return err
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if max > 0 && len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
@@ -0,0 +1,238 @@
+package eclickhouse
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+type fakeConn struct {
+	driver.Conn
+	mu      sync.Mutex
+	sent    [][]any
+	queries []string
+	sendErr error
+}
+
+func (f *fakeConn) PrepareBatch(_ context.Context, query string, _ ...driver.PrepareBatchOption) (driver.Batch, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, query)
+	f.mu.Unlock()
+	return &fakeBatch{conn: f}, nil
+}
+
+func (f *fakeConn) Exec(context.Context, string, ...any) error {
+	return errors.New("exec fail")
+}
+
+func (f *fakeConn) rows() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.sent)
+}
+
+type fakeBatch struct {
+	driver.Batch
+	conn *fakeConn
+	rows [][]any
+}
+
+func (b *fakeBatch) Append(v ...any) error {
+	b.rows = append(b.rows, v)
+	return nil
+}
+
+func (b *fakeBatch) AppendStruct(v any) error {
+	b.rows = append(b.rows, []any{v})
+	return nil
+}
+
+func (b *fakeBatch) Abort() error { return nil }
+
+func (b *fakeBatch) Send() error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	if b.conn.sendErr != nil {
+		return b.conn.sendErr
+	}
+	b.conn.sent = append(b.conn.sent, b.rows...)
+	return nil
+}
+
+func newTestComponent(conn driver.Conn) *Component {
+	config := DefaultConfig()
+	config.EnableAccessInterceptor = true
+	return newComponent("test.clickhouse", config, elog.EgoLogger, conn)
+}
+
+func TestWriterBatchSize(t *testing.T) {
+	conn := &fakeConn{}
+	w := newTestComponent(conn).NewWriter("events", []string{"id", "name"}, WithWriterBatchSize(2), WithWriterFlushInterval(time.Hour))
+	assert.NoError(t, w.Write(1, "a"))
+	assert.NoError(t, w.Write(2, "b"))
+	assert.Eventually(t, func() bool { return conn.rows() == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "INSERT INTO events (id, name)", conn.queries[0])
+
+	// 停止时写入剩余的数据
+	assert.NoError(t, w.Write(3, "c"))
+	assert.NoError(t, w.Close())
+	assert.Equal(t, 3, conn.rows())
+	assert.Equal(t, 0, w.Buffered())
+	assert.ErrorIs(t, w.Write(4, "d"), ErrWriterClosed)
+	assert.NoError(t, w.Close())
+}
+
+func TestWriterFlushInterval(t *testing.T) {
+	conn := &fakeConn{}
+	w := newTestComponent(conn).NewWriter("events", nil, WithWriterFlushInterval(20*time.Millisecond))
+	defer w.Close()
+	assert.NoError(t, w.WriteStruct(struct{ ID int }{ID: 1}))
+	assert.Eventually(t, func() bool { return conn.rows() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestWriterBufferFullAndError(t *testing.T) {
+	conn := &fakeConn{sendErr: errors.New("send fail")}
+	comp := newTestComponent(conn)
+	comp.config.WriterMaxBufferedRows = 1
+	var failed int
+	w := comp.NewWriter("events", nil, WithWriterFlushInterval(time.Hour), WithWriterOnError(func(rows int, err error) {
+		failed += rows
+	}))
+	assert.NoError(t, w.Write(1))
+	assert.ErrorIs(t, w.Write(2), ErrBufferFull)
+	assert.Error(t, w.Close())
+	assert.Equal(t, 1, failed)
+}
+
+func TestWriterFlushWithDeadline(t *testing.T) {
+	conn := &fakeConn{}
+	w := newTestComponent(conn).NewWriter("events", nil, WithWriterBatchSize(10), WithWriterFlushInterval(time.Hour))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, w.Write(i))
+	}
+	// 停止时间已经用完，剩余的数据全部丢弃
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var failed int
+	w.onError = func(rows int, err error) { failed += rows }
+	flushed, dropped, err := w.FlushWithDeadline(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, flushed)
+	assert.Equal(t, 3, dropped)
+	assert.Equal(t, 3, failed)
+	assert.ErrorIs(t, w.Write(4), ErrWriterClosed)
+
+	w = newTestComponent(conn).NewWriter("events", nil, WithWriterBatchSize(10), WithWriterFlushInterval(time.Hour))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, w.Write(i))
+	}
+	flushed, dropped, err = w.FlushWithDeadline(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, flushed)
+	assert.Equal(t, 0, dropped)
+}
+
+func TestComponentIntercept(t *testing.T) {
+	comp := newTestComponent(&fakeConn{})
+	assert.EqualError(t, comp.Exec(context.Background(), "ALTER TABLE events DELETE WHERE 1"), "exec fail")
+}
+
+func TestClickhouseOptions(t *testing.T) {
+	c := DefaultContainer()
+	c.config.AsyncInsert = true
+	c.config.Settings = map[string]interface{}{"max_execution_time": 60}
+	options, err := c.clickhouseOptions()
+	assert.NoError(t, err)
+	assert.Equal(t, clickhouse.CompressionLZ4, options.Compression.Method)
+	assert.Equal(t, 1, options.Settings["async_insert"])
+	assert.Equal(t, 0, options.Settings["wait_for_async_insert"])
+	assert.Equal(t, 60, options.Settings["max_execution_time"])
+
+	c.config.Compression = "none"
+	options, err = c.clickhouseOptions()
+	assert.NoError(t, err)
+	assert.Nil(t, options.Compression)
+
+	c.config.Compression = "snappy"
+	_, err = c.clickhouseOptions()
+	assert.Error(t, err)
+}
+
+func TestBuildOnFailError(t *testing.T) {
+	c := DefaultContainer()
+	c.name = "test.clickhouse"
+	c.config.Addrs = []string{"127.0.0.1:1"}
+	c.config.DialTimeout = 200 * time.Millisecond
+	c.config.OnFail = OnFailError
+	comp := c.Build()
+	assert.False(t, ehealth.Healthy(ehealth.Check(context.Background())))
+	ehealth.Unregister("test.clickhouse")
+	assert.NoError(t, comp.Close())
+}
+
+type authConn struct {
+	fakeConn
+	username string
+	pingErr  error
+	closed   atomic.Bool
+}
+
+func (a *authConn) Ping(context.Context) error { return a.pingErr }
+
+func (a *authConn) Close() error {
+	a.closed.Store(true)
+	return nil
+}
+
+func TestRefreshCredential(t *testing.T) {
+	old := &authConn{username: "v1"}
+	comp := newTestComponent(newSwapConn(old))
+	comp.config.ReadTimeout = 10 * time.Millisecond
+	comp.options = &clickhouse.Options{Auth: clickhouse.Auth{Username: "v1"}}
+	comp.open = func(options *clickhouse.Options) (driver.Conn, error) {
+		conn := &authConn{username: options.Auth.Username}
+		if options.Auth.Password == "bad" {
+			conn.pingErr = errors.New("authentication failed")
+		}
+		return conn, nil
+	}
+	ctx := context.Background()
+
+	// 新凭证无法连接时保留旧连接
+	err := comp.RefreshCredential(ctx, esecret.Credential{Username: "v2", Password: "bad"})
+	assert.ErrorContains(t, err, "authentication failed")
+	assert.Same(t, old, comp.Conn.(*swapConn).conn())
+
+	assert.NoError(t, comp.RefreshCredential(ctx, esecret.Credential{Username: "v2", Password: "ok"}))
+	assert.Equal(t, "v2", comp.Conn.(*swapConn).conn().(*authConn).username)
+	// 旧连接延迟关闭
+	assert.Eventually(t, old.closed.Load, time.Second, time.Millisecond)
+
+	// 没有配置凭证时不支持刷新
+	assert.Error(t, newTestComponent(&fakeConn{}).RefreshCredential(ctx, esecret.Credential{}))
+}
+
+func TestRestart(t *testing.T) {
+	old := &authConn{username: "v1"}
+	comp := newTestComponent(newSwapConn(old))
+	comp.config.ReadTimeout = 10 * time.Millisecond
+	comp.options = &clickhouse.Options{Auth: clickhouse.Auth{Username: "v1"}}
+	comp.open = func(options *clickhouse.Options) (driver.Conn, error) {
+		return &authConn{username: options.Auth.Username}, nil
+	}
+	assert.NoError(t, comp.Restart(context.Background()))
+	assert.NotSame(t, old, comp.Conn.(*swapConn).conn())
+	assert.Equal(t, "v1", comp.Conn.(*swapConn).conn().(*authConn).username)
+	assert.Eventually(t, old.closed.Load, time.Second, time.Millisecond)
+}
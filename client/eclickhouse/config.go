@@ -0,0 +1,70 @@
+package eclickhouse
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// OnFailPanic 连接失败时panic
+	OnFailPanic = "panic"
+	// OnFailError 连接失败时记录错误日志，继续启动
+	OnFailError = "error"
+)
+
+// Config ClickHouse配置，使用native协议
+type Config struct {
+	Addrs                      []string               // 节点地址，默认 127.0.0.1:9000
+	Database                   string                 // 数据库，默认default
+	Username                   string                 // 用户名，默认default
+	Password                   string                 // 密码
+	Credential                 string                 // 凭证名称，配置后使用esecret中的用户名和密码，凭证轮换后重新建立连接
+	DialTimeout                time.Duration          // 建立连接的超时，默认5s
+	ReadTimeout                time.Duration          // 读取的超时，默认30s
+	MaxOpenConns               int                    // 最大连接数，默认10
+	MaxIdleConns               int                    // 最大空闲连接数，默认5
+	ConnMaxLifetime            time.Duration          // 连接的最大存活时间，默认1h
+	Compression                string                 // 压缩算法，none、lz4、zstd，默认lz4
+	CompressionLevel           int                    // 压缩级别，默认0，使用算法的默认级别
+	Settings                   map[string]interface{} // 会话设置，例如 max_execution_time
+	AsyncInsert                bool                   // 是否开启服务端异步插入，开启后小批量的写入由服务端合并，默认不开启
+	AsyncInsertWait            bool                   // 异步插入是否等待数据落盘后再返回，默认不等待
+	SlowLogThreshold           time.Duration          // 慢查询日志的阈值，默认1s
+	EnableTraceInterceptor     bool                   // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor    bool                   // 是否开启监控，默认开启
+	EnableAccessInterceptor    bool                   // 是否开启记录请求数据，默认不开启
+	EnableAccessInterceptorReq bool                   // 是否开启记录请求参数，默认不开启
+	AccessInterceptorMaxLength int                    // 记录请求参数的最大长度，默认4K
+	EnableHealthCheck          bool                   // 是否注册到组件健康检查，默认开启
+	HealthCritical             bool                   // 健康检查失败时是否影响服务就绪，默认开启
+	OnFail                     string                 // 启动时连接失败的处理，panic或者error，默认panic
+	WriterBatchSize            int                    // 缓冲写入每批的行数，默认10000
+	WriterFlushInterval        time.Duration          // 缓冲写入的刷新间隔，默认1s
+	WriterMaxBufferedRows      int                    // 缓冲写入最多缓存的行数，超过后Write返回ErrBufferFull，默认100000
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Addrs:                      []string{"127.0.0.1:9000"},
+		Database:                   "default",
+		Username:                   "default",
+		DialTimeout:                xtime.Duration("5s"),
+		ReadTimeout:                xtime.Duration("30s"),
+		MaxOpenConns:               10,
+		MaxIdleConns:               5,
+		ConnMaxLifetime:            xtime.Duration("1h"),
+		Compression:                "lz4",
+		SlowLogThreshold:           xtime.Duration("1s"),
+		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
+		AccessInterceptorMaxLength: 4096,
+		EnableHealthCheck:          true,
+		HealthCritical:             true,
+		OnFail:                     OnFailPanic,
+		WriterBatchSize:            10000,
+		WriterFlushInterval:        xtime.Duration("1s"),
+		WriterMaxBufferedRows:      100000,
+	}
+}
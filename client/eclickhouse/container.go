@@ -0,0 +1,142 @@
+package eclickhouse
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config    *Config
+	name      string
+	logger    *elog.Component
+	tlsConfig *tls.Config
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 创建客户端，启动时检查连接，并在ego停止后关闭连接
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	if len(c.config.Addrs) == 0 {
+		c.logger.Panic("addrs is empty")
+	}
+	options, err := c.clickhouseOptions()
+	if err != nil {
+		c.logger.Panic("parse clickhouse options fail", elog.FieldErr(err))
+	}
+	if c.config.Credential != "" {
+		cred, ok := esecret.Current(c.config.Credential)
+		if !ok {
+			c.logger.Panic("clickhouse credential not found, build esecret component first", elog.FieldName(c.config.Credential))
+		}
+		options.Auth.Username, options.Auth.Password = cred.Username, cred.Password
+	}
+	conn, err := clickhouse.Open(options)
+	if err != nil {
+		c.logger.Panic("open clickhouse fail", elog.FieldErr(err))
+	}
+	c.logger = c.logger.With(elog.FieldAddr(strings.Join(c.config.Addrs, ",")))
+	comp := newComponent(c.name, c.config, c.logger, newSwapConn(conn))
+	comp.options = options
+	if c.config.Credential != "" {
+		comp.unsubscribe = esecret.Subscribe(c.config.Credential, comp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.DialTimeout)
+	defer cancel()
+	if err := conn.Ping(ctx); err != nil {
+		if c.config.OnFail == OnFailPanic {
+			c.logger.Panic("ping clickhouse fail", elog.FieldErr(err))
+		}
+		c.logger.Error("ping clickhouse fail", elog.FieldErr(err))
+	}
+
+	// 缓冲写入在连接之后注册，停止时先刷新缓冲写入，再关闭连接
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("clickhouse"), ehealth.WithCritical(c.config.HealthCritical), ehealth.WithTarget(strings.Join(c.config.Addrs, ",")))
+	}
+	ecomponent.Register(c.name, PackageName, comp.Restart)
+	return comp
+}
+
+func (c *Container) clickhouseOptions() (*clickhouse.Options, error) {
+	method, ok := compressionMethods[strings.ToLower(c.config.Compression)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression %s", c.config.Compression)
+	}
+	settings := clickhouse.Settings{}
+	for k, v := range c.config.Settings {
+		settings[k] = v
+	}
+	if c.config.AsyncInsert {
+		settings["async_insert"] = 1
+		settings["wait_for_async_insert"] = 0
+		if c.config.AsyncInsertWait {
+			settings["wait_for_async_insert"] = 1
+		}
+	}
+	options := &clickhouse.Options{
+		Protocol: clickhouse.Native,
+		TLS:      c.tlsConfig,
+		Addr:     c.config.Addrs,
+		Auth: clickhouse.Auth{
+			Database: c.config.Database,
+			Username: c.config.Username,
+			Password: c.config.Password,
+		},
+		Settings:        settings,
+		DialTimeout:     c.config.DialTimeout,
+		ReadTimeout:     c.config.ReadTimeout,
+		MaxOpenConns:    c.config.MaxOpenConns,
+		MaxIdleConns:    c.config.MaxIdleConns,
+		ConnMaxLifetime: c.config.ConnMaxLifetime,
+	}
+	if method != clickhouse.CompressionNone {
+		options.Compression = &clickhouse.Compression{Method: method, Level: c.config.CompressionLevel}
+	}
+	return options, nil
+}
+
+var compressionMethods = map[string]clickhouse.CompressionMethod{
+	"":     clickhouse.CompressionNone,
+	"none": clickhouse.CompressionNone,
+	"lz4":  clickhouse.CompressionLZ4,
+	"zstd": clickhouse.CompressionZSTD,
+}
@@ -0,0 +1,113 @@
+package eclickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esecret"
+)
+
+// swapConn 凭证轮换或者重建组件时替换底层连接
+type swapConn struct {
+	current atomic.Pointer[driver.Conn]
+}
+
+func newSwapConn(conn driver.Conn) *swapConn {
+	s := &swapConn{}
+	s.current.Store(&conn)
+	return s
+}
+
+func (s *swapConn) conn() driver.Conn {
+	return *s.current.Load()
+}
+
+func (s *swapConn) swap(conn driver.Conn) driver.Conn {
+	return *s.current.Swap(&conn)
+}
+
+func (s *swapConn) Contributors() []string { return s.conn().Contributors() }
+
+func (s *swapConn) ServerVersion() (*driver.ServerVersion, error) { return s.conn().ServerVersion() }
+
+func (s *swapConn) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return s.conn().Select(ctx, dest, query, args...)
+}
+
+func (s *swapConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	return s.conn().Query(ctx, query, args...)
+}
+
+func (s *swapConn) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	return s.conn().QueryRow(ctx, query, args...)
+}
+
+func (s *swapConn) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	return s.conn().PrepareBatch(ctx, query, opts...)
+}
+
+func (s *swapConn) Exec(ctx context.Context, query string, args ...any) error {
+	return s.conn().Exec(ctx, query, args...)
+}
+
+func (s *swapConn) AsyncInsert(ctx context.Context, query string, wait bool, args ...any) error {
+	return s.conn().AsyncInsert(ctx, query, wait, args...)
+}
+
+func (s *swapConn) Ping(ctx context.Context) error { return s.conn().Ping(ctx) }
+
+func (s *swapConn) Stats() driver.Stats { return s.conn().Stats() }
+
+func (s *swapConn) Close() error { return s.conn().Close() }
+
+// RefreshCredential 实现 esecret.CredentialRefresher，使用新凭证建立连接，检查通过后替换旧连接
+func (c *Component) RefreshCredential(ctx context.Context, cred esecret.Credential) error {
+	return c.reconnect(ctx, "new credential", func(options *clickhouse.Options) {
+		options.Auth.Username, options.Auth.Password = cred.Username, cred.Password
+	})
+}
+
+// Restart 使用当前配置重新建立连接，检查通过后替换旧连接，用于治理端口重建组件
+func (c *Component) Restart(ctx context.Context) error {
+	return c.reconnect(ctx, "restart", func(*clickhouse.Options) {})
+}
+
+// reconnect 修改配置后建立新连接，检查通过后替换旧连接
+// 旧连接上可能还有正在执行的查询，等待读超时后再关闭
+func (c *Component) reconnect(ctx context.Context, reason string, modify func(*clickhouse.Options)) error {
+	conn, ok := c.Conn.(*swapConn)
+	if !ok || c.options == nil {
+		return errors.New("eclickhouse reconnect is not supported")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	options := *c.options
+	modify(&options)
+	newConn, err := c.open(&options)
+	if err != nil {
+		return fmt.Errorf("open clickhouse with %s fail, %w", reason, err)
+	}
+	if err = newConn.Ping(ctx); err != nil {
+		_ = newConn.Close()
+		return fmt.Errorf("ping clickhouse with %s fail, %w", reason, err)
+	}
+	c.options = &options
+	old := conn.swap(newConn)
+	time.AfterFunc(c.config.ReadTimeout, func() {
+		if err := old.Close(); err != nil {
+			c.logger.Error("close old clickhouse connection fail", elog.FieldErr(err), elog.String("reason", reason))
+		}
+	})
+	return nil
+}
+
+func openClickhouse(options *clickhouse.Options) (driver.Conn, error) {
+	return clickhouse.Open(options)
+}
@@ -0,0 +1,26 @@
+package eclickhouse
+
+import (
+	"crypto/tls"
+)
+
+// WithAddrs 设置节点地址
+func WithAddrs(addrs ...string) Option {
+	return func(c *Container) {
+		c.config.Addrs = addrs
+	}
+}
+
+// WithDatabase 设置数据库
+func WithDatabase(database string) Option {
+	return func(c *Container) {
+		c.config.Database = database
+	}
+}
+
+// WithTLS 设置TLS配置
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(c *Container) {
+		c.tlsConfig = tlsConfig
+	}
+}
@@ -0,0 +1,241 @@
+package eclickhouse
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var (
+	// ErrBufferFull 缓冲写入缓存的行数超过上限
+	ErrBufferFull = errors.New("eclickhouse: writer buffer full")
+	// ErrWriterClosed 缓冲写入已经关闭
+	ErrWriterClosed = errors.New("eclickhouse: writer closed")
+)
+
+// writerFlushTimeout 单次刷新的超时
+const writerFlushTimeout = 30 * time.Second
+
+// WriterOption 缓冲写入选项
+type WriterOption func(w *Writer)
+
+// WithWriterBatchSize 设置每批的行数
+func WithWriterBatchSize(size int) WriterOption {
+	return func(w *Writer) {
+		w.batchSize = size
+	}
+}
+
+// WithWriterFlushInterval 设置刷新间隔
+func WithWriterFlushInterval(interval time.Duration) WriterOption {
+	return func(w *Writer) {
+		w.flushInterval = interval
+	}
+}
+
+// WithWriterOnError 设置写入失败的回调，rows为写入失败的行，可以用于落盘或者重新投递
+func WithWriterOnError(fn func(rows int, err error)) WriterOption {
+	return func(w *Writer) {
+		w.onError = fn
+	}
+}
+
+// Writer 缓冲写入，按照行数或者时间间隔批量写入同一张表
+// ego停止时会刷新缓存的数据，避免停止时丢失分析数据
+type Writer struct {
+	comp          *Component
+	query         string
+	batchSize     int
+	flushInterval time.Duration
+	maxBuffered   int
+	onError       func(rows int, err error)
+
+	mu      sync.Mutex
+	rows    []row
+	closed  bool
+	flushMu sync.Mutex // 保证同一时间只有一个批次在写入
+	notify  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+type row struct {
+	values []any
+	st     any
+}
+
+// NewWriter 创建缓冲写入，table为表名，columns为空时写入所有列
+func (c *Component) NewWriter(table string, columns []string, opts ...WriterOption) *Writer {
+	query := "INSERT INTO " + table
+	if len(columns) > 0 {
+		query += " (" + strings.Join(columns, ", ") + ")"
+	}
+	w := &Writer{
+		comp:          c,
+		query:         query,
+		batchSize:     c.config.WriterBatchSize,
+		flushInterval: c.config.WriterFlushInterval,
+		maxBuffered:   c.config.WriterMaxBufferedRows,
+		notify:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = DefaultConfig().WriterBatchSize
+	}
+	if w.flushInterval <= 0 {
+		w.flushInterval = DefaultConfig().WriterFlushInterval
+	}
+	go w.loop()
+	ehooks.RegisterFlush(c.name+"."+table, w.FlushWithDeadline)
+	ehooks.Register(ehooks.StageAfterStop, w.Close)
+	return w
+}
+
+// Write 缓存一行数据，values的顺序和列的顺序一致
+func (w *Writer) Write(values ...any) error {
+	return w.append(row{values: values})
+}
+
+// WriteStruct 缓存一行数据，使用结构体的ch标签映射列
+func (w *Writer) WriteStruct(v any) error {
+	return w.append(row{st: v})
+}
+
+func (w *Writer) append(r row) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	if w.maxBuffered > 0 && len(w.rows) >= w.maxBuffered {
+		w.mu.Unlock()
+		return ErrBufferFull
+	}
+	w.rows = append(w.rows, r)
+	full := len(w.rows) >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Buffered 缓存中还没有写入的行数
+func (w *Writer) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.rows)
+}
+
+// Flush 写入缓存中所有的数据
+func (w *Writer) Flush(ctx context.Context) error {
+	_, _, err := w.flush(ctx)
+	return err
+}
+
+// flush 分批写入缓存中所有的数据，ctx结束后剩余的数据不再写入，返回写入成功和丢弃的行数
+func (w *Writer) flush(ctx context.Context) (flushed int, dropped int, err error) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	var errs []error
+	for {
+		w.mu.Lock()
+		n := len(w.rows)
+		if ctx.Err() == nil && n > w.batchSize {
+			n = w.batchSize
+		}
+		rows := w.rows[:n:n]
+		w.rows = w.rows[n:]
+		w.mu.Unlock()
+		if n == 0 {
+			return flushed, dropped, errors.Join(errs...)
+		}
+		sendErr := ctx.Err()
+		if sendErr == nil {
+			sendErr = w.send(ctx, rows)
+		}
+		if sendErr != nil {
+			dropped += len(rows)
+			errs = append(errs, sendErr)
+			w.comp.logger.Error("clickhouse writer flush fail", elog.FieldMethod(w.query), elog.Int("rows", len(rows)), elog.FieldErr(sendErr))
+			if w.onError != nil {
+				w.onError(len(rows), sendErr)
+			}
+			continue
+		}
+		flushed += len(rows)
+	}
+}
+
+func (w *Writer) send(ctx context.Context, rows []row) error {
+	b, err := w.comp.PrepareBatch(ctx, w.query)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := appendRow(b, r); err != nil {
+			_ = b.Abort()
+			return err
+		}
+	}
+	return b.Send()
+}
+
+func appendRow(b driver.Batch, r row) error {
+	if r.st != nil {
+		return b.AppendStruct(r.st)
+	}
+	return b.Append(r.values...)
+}
+
+// Close 停止定时刷新，写入缓存中剩余的数据，可以重复调用
+func (w *Writer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), writerFlushTimeout)
+	defer cancel()
+	_, _, err := w.FlushWithDeadline(ctx)
+	return err
+}
+
+// FlushWithDeadline 停止接收数据和定时刷新，在ctx结束前写入缓存中剩余的数据，返回写入成功和丢弃的行数
+// ego停止时会使用剩余的停止时间调用
+func (w *Writer) FlushWithDeadline(ctx context.Context) (int, int, error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.done)
+	}
+	w.mu.Unlock()
+	<-w.stopped
+	return w.flush(ctx)
+}
+
+func (w *Writer) loop() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		case <-w.notify:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), writerFlushTimeout)
+		_ = w.Flush(ctx)
+		cancel()
+	}
+}
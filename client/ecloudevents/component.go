@@ -0,0 +1,185 @@
+package ecloudevents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "client.ecloudevents"
+
+// SpecVersion CloudEvents规范版本
+const SpecVersion = "1.0"
+
+// 框架的生命周期事件类型
+const (
+	// TypeStarted 进程初始化完成，开始启动服务
+	TypeStarted = "io.ego.lifecycle.started"
+	// TypeReady 服务和定时任务已经启动，data为启动摘要
+	TypeReady = "io.ego.lifecycle.ready"
+	// TypeConfigChanged 配置热更新，data中的status为applied、fail、rollback
+	TypeConfigChanged = "io.ego.lifecycle.config.changed"
+	// TypeReload 热升级，新进程已经就绪，当前进程开始停止
+	TypeReload = "io.ego.lifecycle.reload"
+	// TypeStopping 开始停止
+	TypeStopping = "io.ego.lifecycle.stopping"
+	// TypeStopped 停止完成，停止过程中有错误时data中包含error
+	TypeStopped = "io.ego.lifecycle.stopped"
+	// TypeCrashed 服务启动失败
+	TypeCrashed = "io.ego.lifecycle.crashed"
+)
+
+var (
+	// ErrQueueFull 异步发送队列已满
+	ErrQueueFull = errors.New("ecloudevents: queue full")
+	// ErrClosed 组件已经关闭
+	ErrClosed = errors.New("ecloudevents: closed")
+)
+
+var defaultComponent atomic.Pointer[Component]
+
+// Event CloudEvents 1.0 格式的事件
+type Event struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"` // 实例的主机名
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype,omitempty"`
+	Data            interface{} `json:"data,omitempty"`
+}
+
+// Component 生命周期事件组件
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	sinks  map[string]Sink
+	names  []string // 按名称排序的通道，发送顺序固定
+	queue  chan *Event
+	mu     sync.RWMutex // 保证关闭队列之后不会再写入
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, sinks map[string]Sink) *Component {
+	c := &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		sinks:  sinks,
+		queue:  make(chan *Event, config.QueueSize),
+	}
+	for name := range sinks {
+		c.names = append(c.names, name)
+	}
+	sort.Strings(c.names)
+	c.wg.Add(1)
+	go c.work()
+	return c
+}
+
+// Default 返回最后一次Build的组件，没有Build时返回nil
+func Default() *Component {
+	return defaultComponent.Load()
+}
+
+func setDefault(c *Component) {
+	defaultComponent.Store(c)
+}
+
+// NewEvent 创建事件，填充id、source、time等属性
+func (c *Component) NewEvent(typ string, data interface{}) *Event {
+	event := &Event{
+		SpecVersion: SpecVersion,
+		ID:          newID(),
+		Source:      c.config.Source,
+		Type:        typ,
+		Subject:     eapp.HostName(),
+		Time:        time.Now().UTC(),
+		Data:        data,
+	}
+	if data != nil {
+		event.DataContentType = "application/json"
+	}
+	return event
+}
+
+// Send 同步发送事件到所有通道，返回聚合错误
+func (c *Component) Send(ctx context.Context, event *Event) error {
+	errs := make([]error, 0)
+	for _, name := range c.names {
+		sendCtx, cancel := context.WithTimeout(ctx, c.config.SendTimeout)
+		err := c.sinks[name].Send(sendCtx, event)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Emit 异步发送事件，队列满或者组件关闭时丢弃事件并返回错误
+func (c *Component) Emit(typ string, data interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	select {
+	case c.queue <- c.NewEvent(typ, data):
+		return nil
+	default:
+		c.logger.Warn("event queue full, drop event", elog.String("type", typ))
+		return ErrQueueFull
+	}
+}
+
+// Close 停止接收事件，等待队列中的事件发送完成
+func (c *Component) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Component) work() {
+	defer c.wg.Done()
+	for event := range c.queue {
+		if err := c.Send(context.Background(), event); err != nil {
+			c.logger.Error("send event fail", elog.String("type", event.Type), elog.FieldErr(err))
+		}
+	}
+}
+
+// Emit 使用默认组件异步发送事件，没有Build组件时不发送，框架在生命周期的各个阶段调用
+func Emit(typ string, data interface{}) {
+	c := Default()
+	if c == nil {
+		return
+	}
+	_ = c.Emit(typ, data)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,53 @@
+package ecloudevents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSink struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func (m *mockSink) Send(ctx context.Context, event *Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, *event)
+	return m.err
+}
+
+func TestEmit(t *testing.T) {
+	ok := &mockSink{}
+	fail := &mockSink{err: errors.New("send fail")}
+	comp := DefaultContainer().Build(WithSink("ok", ok), WithSink("fail", fail))
+	assert.Equal(t, comp, Default())
+
+	event := comp.NewEvent(TypeStopping, map[string]bool{"graceful": true})
+	assert.Equal(t, SpecVersion, event.SpecVersion)
+	assert.Len(t, event.ID, 32)
+	assert.Equal(t, "application/json", event.DataContentType)
+	assert.ErrorContains(t, comp.Send(context.Background(), event), "sink fail: send fail")
+
+	Emit(TypeReady, nil)
+	assert.NoError(t, comp.Close())
+	assert.Len(t, ok.events, 2)
+	assert.Equal(t, TypeReady, ok.events[1].Type)
+	assert.Equal(t, "", ok.events[1].DataContentType)
+	assert.Equal(t, comp.config.Source, ok.events[1].Source)
+
+	// 关闭之后丢弃事件
+	assert.ErrorIs(t, comp.Emit(TypeStopped, nil), ErrClosed)
+	assert.Len(t, ok.events, 2)
+}
+
+func TestBuildUnknownSink(t *testing.T) {
+	c := DefaultContainer()
+	c.config.Sinks["mq"] = SinkConfig{Type: "mq"}
+	assert.Panics(t, func() { c.Build() })
+}
@@ -0,0 +1,33 @@
+package ecloudevents
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 生命周期事件配置
+type Config struct {
+	Source      string                // CloudEvents的source，默认 /ego/{应用名称}
+	QueueSize   int                   // 异步发送队列长度，队列满时丢弃事件，默认1024
+	SendTimeout time.Duration         // 发送一个事件到一个通道的超时，默认5s
+	Sinks       map[string]SinkConfig // 事件通道，key为通道名
+}
+
+// SinkConfig 事件通道配置
+type SinkConfig struct {
+	Type    string            // 通道类型，http、kafka
+	URL     string            // http为接收地址，kafka为Kafka REST Proxy的地址
+	Mode    string            // http的内容模式，structured或者binary，默认structured
+	Topic   string            // kafka的topic
+	Headers map[string]string // 自定义的header
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		QueueSize:   1024,
+		SendTimeout: xtime.Duration("5s"),
+		Sinks:       make(map[string]SinkConfig),
+	}
+}
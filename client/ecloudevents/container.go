@@ -0,0 +1,80 @@
+package ecloudevents
+
+import (
+	"fmt"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	sinks  map[string]Sink
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		sinks:  make(map[string]Sink),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 构建事件组件，启动异步发送协程，并设置为默认组件，框架使用默认组件发送生命周期事件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	sinks, err := c.buildSinks()
+	if err != nil {
+		c.logger.Panic("build ecloudevents fail", elog.FieldErr(err))
+	}
+	if c.config.Source == "" {
+		c.config.Source = "/ego/" + eapp.Name()
+	}
+	comp := newComponent(c.name, c.config, c.logger, sinks)
+	setDefault(comp)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) buildSinks() (map[string]Sink, error) {
+	sinks := make(map[string]Sink)
+	for name, config := range c.config.Sinks {
+		factory := getSinkFactory(config.Type)
+		if factory == nil {
+			return nil, fmt.Errorf("unknown sink type %s, name: %s", config.Type, name)
+		}
+		sink, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("build sink %s fail, %w", name, err)
+		}
+		sinks[name] = sink
+	}
+	for name, sink := range c.sinks {
+		sinks[name] = sink
+	}
+	return sinks, nil
+}
@@ -0,0 +1,8 @@
+package ecloudevents
+
+// WithSink 通过代码设置事件通道，例如使用项目中的Kafka生产者，和配置中的通道同名时覆盖配置
+func WithSink(name string, sink Sink) Option {
+	return func(c *Container) {
+		c.sinks[name] = sink
+	}
+}
@@ -0,0 +1,174 @@
+package ecloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/eextension"
+)
+
+const (
+	// SinkHTTP 通过HTTP发送事件
+	SinkHTTP = "http"
+	// SinkKafka 通过Kafka REST Proxy写入topic
+	SinkKafka = "kafka"
+)
+
+const (
+	// ModeStructured 整个事件作为body，Content-Type为application/cloudevents+json
+	ModeStructured = "structured"
+	// ModeBinary 事件属性放在ce-开头的header中，data作为body
+	ModeBinary = "binary"
+)
+
+// Sink 事件通道
+type Sink interface {
+	Send(ctx context.Context, event *Event) error
+}
+
+// SinkFunc 函数形式的事件通道，例如使用项目中的Kafka生产者写入事件
+type SinkFunc func(ctx context.Context, event *Event) error
+
+// Send 发送事件
+func (f SinkFunc) Send(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// SinkFactory 根据配置创建事件通道
+type SinkFactory func(config SinkConfig) (Sink, error)
+
+var (
+	factoryMu sync.RWMutex
+	factories = make(map[string]SinkFactory)
+)
+
+func init() {
+	RegisterSink(SinkHTTP, newHTTPSink)
+	RegisterSink(SinkKafka, newKafkaSink)
+}
+
+// RegisterSink 注册事件通道类型，重复注册会覆盖
+func RegisterSink(typ string, factory SinkFactory) {
+	_ = eextension.Register(eextension.KindCloudEventsSink, typ)
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factories[typ] = factory
+}
+
+func getSinkFactory(typ string) SinkFactory {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	return factories[typ]
+}
+
+// httpClient 通道共用的http client，超时由ctx控制
+var httpClient = &http.Client{}
+
+func post(ctx context.Context, rawURL string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("unexpected status %d, body: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+func withHeaders(base map[string]string, custom map[string]string) map[string]string {
+	for k, v := range custom {
+		base[k] = v
+	}
+	return base
+}
+
+type httpSink struct {
+	config SinkConfig
+}
+
+func newHTTPSink(config SinkConfig) (Sink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("http sink url is empty")
+	}
+	switch config.Mode {
+	case "":
+		config.Mode = ModeStructured
+	case ModeStructured, ModeBinary:
+	default:
+		return nil, fmt.Errorf("unknown http sink mode %s", config.Mode)
+	}
+	return &httpSink{config: config}, nil
+}
+
+// Send 按照CloudEvents HTTP协议绑定发送事件
+func (s *httpSink) Send(ctx context.Context, event *Event) error {
+	if s.config.Mode == ModeStructured {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return post(ctx, s.config.URL, withHeaders(map[string]string{"Content-Type": "application/cloudevents+json"}, s.config.Headers), body)
+	}
+
+	var body []byte
+	if event.Data != nil {
+		var err error
+		if body, err = json.Marshal(event.Data); err != nil {
+			return err
+		}
+	}
+	headers := map[string]string{
+		"ce-specversion": event.SpecVersion,
+		"ce-id":          event.ID,
+		"ce-source":      event.Source,
+		"ce-type":        event.Type,
+		"ce-time":        event.Time.Format(time.RFC3339Nano),
+	}
+	if event.Subject != "" {
+		headers["ce-subject"] = event.Subject
+	}
+	if event.DataContentType != "" {
+		headers["Content-Type"] = event.DataContentType
+	}
+	return post(ctx, s.config.URL, withHeaders(headers, s.config.Headers), body)
+}
+
+type kafkaSink struct {
+	config SinkConfig
+	url    string
+}
+
+func newKafkaSink(config SinkConfig) (Sink, error) {
+	if config.URL == "" || config.Topic == "" {
+		return nil, fmt.Errorf("kafka sink url or topic is empty")
+	}
+	return &kafkaSink{config: config, url: strings.TrimSuffix(config.URL, "/") + "/topics/" + url.PathEscape(config.Topic)}, nil
+}
+
+// Send 使用Kafka REST Proxy v2接口写入结构化的事件，key为source，同一个应用的事件写入同一个分区
+func (s *kafkaSink) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{{"key": event.Source, "value": event}},
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.url, withHeaders(map[string]string{"Content-Type": "application/vnd.kafka.json.v2+json"}, s.config.Headers), body)
+}
@@ -0,0 +1,76 @@
+package ecloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var req *http.Request
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+	event := &Event{SpecVersion: SpecVersion, ID: "1", Source: "/ego/svc", Type: TypeReady, Subject: "host", Time: time.Unix(0, 0).UTC(), DataContentType: "application/json", Data: map[string]string{"mode": "prod"}}
+
+	sink, err := newHTTPSink(SinkConfig{URL: ts.URL, Headers: map[string]string{"Authorization": "token"}})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Send(context.Background(), event))
+	assert.Equal(t, "application/cloudevents+json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "token", req.Header.Get("Authorization"))
+	var got Event
+	assert.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, TypeReady, got.Type)
+	assert.Equal(t, "/ego/svc", got.Source)
+
+	sink, err = newHTTPSink(SinkConfig{URL: ts.URL, Mode: ModeBinary})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Send(context.Background(), event))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, TypeReady, req.Header.Get("ce-type"))
+	assert.Equal(t, "host", req.Header.Get("ce-subject"))
+	assert.Equal(t, "1970-01-01T00:00:00Z", req.Header.Get("ce-time"))
+	assert.JSONEq(t, `{"mode":"prod"}`, string(body))
+
+	_, err = newHTTPSink(SinkConfig{URL: ts.URL, Mode: "batch"})
+	assert.EqualError(t, err, "unknown http sink mode batch")
+}
+
+func TestKafkaSink(t *testing.T) {
+	var path string
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+	}))
+	defer ts.Close()
+
+	sink, err := newKafkaSink(SinkConfig{URL: ts.URL + "/", Topic: "ego-lifecycle"})
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Send(context.Background(), &Event{SpecVersion: SpecVersion, ID: "1", Source: "/ego/svc", Type: TypeStopped}))
+	assert.Equal(t, "/topics/ego-lifecycle", path)
+	var got struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Event  `json:"value"`
+		} `json:"records"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "/ego/svc", got.Records[0].Key)
+	assert.Equal(t, TypeStopped, got.Records[0].Value.Type)
+
+	_, err = newKafkaSink(SinkConfig{URL: ts.URL})
+	assert.EqualError(t, err, "kafka sink url or topic is empty")
+}
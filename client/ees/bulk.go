@@ -0,0 +1,240 @@
+package ees
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// BulkIndex 写入文档，存在时覆盖
+	BulkIndex = "index"
+	// BulkCreate 创建文档，存在时失败
+	BulkCreate = "create"
+	// BulkUpdate 更新文档，Doc为 {"doc": ...} 等update请求体
+	BulkUpdate = "update"
+	// BulkDelete 删除文档
+	BulkDelete = "delete"
+)
+
+// ErrBulkClosed 批量写入已经关闭
+var ErrBulkClosed = errors.New("ees: bulk processor closed")
+
+// BulkItem 批量写入的文档
+type BulkItem struct {
+	Action string      // index、create、update、delete，默认index
+	Index  string      // 索引
+	ID     string      // 文档ID，index、create时可以为空
+	Doc    interface{} // 文档，delete时为空
+}
+
+// BulkStats 批量写入的统计
+type BulkStats struct {
+	Added   uint64 // 加入队列的文档数
+	Flushed uint64 // 写入成功的文档数
+	Failed  uint64 // 写入失败的文档数
+}
+
+// BulkProcessor 批量写入，按照文档数、字节数、时间间隔刷新，队列满时Add阻塞，实现背压
+type BulkProcessor struct {
+	comp      *Component
+	onFailure func(item BulkItem, err error)
+	queue     chan BulkItem
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	stats     BulkStats
+}
+
+// NewBulkProcessor 创建批量写入，onFailure在文档写入失败时回调，可以为nil
+func (c *Component) NewBulkProcessor(onFailure func(item BulkItem, err error)) *BulkProcessor {
+	b := &BulkProcessor{
+		comp:      c,
+		onFailure: onFailure,
+		queue:     make(chan BulkItem, c.config.BulkQueueSize),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	ehooks.RegisterFlush(c.name+".bulk", b.FlushWithDeadline)
+	return b
+}
+
+// Add 加入队列，队列满时阻塞直到有空间或者ctx结束
+func (b *BulkProcessor) Add(ctx context.Context, item BulkItem) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBulkClosed
+	}
+	select {
+	case b.queue <- item:
+		atomic.AddUint64(&b.stats.Added, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats 批量写入的统计
+func (b *BulkProcessor) Stats() BulkStats {
+	return BulkStats{
+		Added:   atomic.LoadUint64(&b.stats.Added),
+		Flushed: atomic.LoadUint64(&b.stats.Flushed),
+		Failed:  atomic.LoadUint64(&b.stats.Failed),
+	}
+}
+
+// Close 停止接收文档，写入队列中剩余的文档，ctx结束时不再等待
+func (b *BulkProcessor) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FlushWithDeadline 停止接收文档，在ctx结束前写入队列中剩余的文档，返回本次写入成功和丢弃的文档数
+// 丢弃的文档包括写入失败和ctx结束时还没有写入的文档，ego停止时会使用剩余的停止时间调用
+func (b *BulkProcessor) FlushWithDeadline(ctx context.Context) (int, int, error) {
+	before := b.Stats()
+	err := b.Close(ctx)
+	after := b.Stats()
+	flushed := int(after.Flushed - before.Flushed)
+	dropped := int(after.Failed - before.Failed)
+	if err != nil {
+		dropped = int(after.Added - after.Flushed - after.Failed)
+	}
+	return flushed, dropped, err
+}
+
+func (b *BulkProcessor) run() {
+	defer close(b.done)
+	config := b.comp.config
+	ticker := time.NewTicker(config.BulkFlushInterval)
+	defer ticker.Stop()
+
+	var (
+		buf     bytes.Buffer
+		entries = make([]BulkItem, 0, config.BulkActions)
+	)
+	flush := func() {
+		if len(entries) == 0 {
+			return
+		}
+		b.flush(buf.Bytes(), entries)
+		buf.Reset()
+		entries = entries[:0]
+	}
+	for {
+		select {
+		case item, ok := <-b.queue:
+			if !ok {
+				flush()
+				return
+			}
+			size := buf.Len()
+			if err := encodeBulkItem(&buf, item); err != nil {
+				buf.Truncate(size)
+				b.fail(item, err)
+				continue
+			}
+			entries = append(entries, item)
+			if len(entries) >= config.BulkActions || buf.Len() >= config.BulkSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (b *BulkProcessor) flush(body []byte, entries []BulkItem) {
+	resp, err := b.comp.do(context.Background(), http.MethodPost, "/_bulk", nil, body, "application/x-ndjson")
+	if err != nil {
+		for _, entry := range entries {
+			b.fail(entry, err)
+		}
+		return
+	}
+	var res struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(resp.Body, &res); err != nil {
+		for _, entry := range entries {
+			b.fail(entry, fmt.Errorf("ees: decode bulk response fail, %w", err))
+		}
+		return
+	}
+	flushed := uint64(len(entries))
+	if res.Errors {
+		for i, item := range res.Items {
+			if i >= len(entries) {
+				break
+			}
+			for _, result := range item {
+				if result.Status >= http.StatusMultipleChoices {
+					flushed--
+					b.fail(entries[i], &Error{StatusCode: result.Status, Reason: string(result.Error)})
+				}
+			}
+		}
+	}
+	atomic.AddUint64(&b.stats.Flushed, flushed)
+}
+
+func (b *BulkProcessor) fail(item BulkItem, err error) {
+	atomic.AddUint64(&b.stats.Failed, 1)
+	if b.onFailure != nil {
+		b.onFailure(item, err)
+		return
+	}
+	b.comp.logger.Error("bulk item fail", elog.FieldKey(item.Index), elog.FieldValue(item.ID), elog.FieldErr(err))
+}
+
+// encodeBulkItem 编码为bulk请求的两行：操作行和文档行
+func encodeBulkItem(buf *bytes.Buffer, item BulkItem) error {
+	action := item.Action
+	if action == "" {
+		action = BulkIndex
+	}
+	meta := map[string]string{"_index": item.Index}
+	if item.ID != "" {
+		meta["_id"] = item.ID
+	}
+	line, err := json.Marshal(map[string]interface{}{action: meta})
+	if err != nil {
+		return err
+	}
+	buf.Write(line)
+	buf.WriteByte('\n')
+	if action == BulkDelete {
+		return nil
+	}
+	doc, err := json.Marshal(item.Doc)
+	if err != nil {
+		return err
+	}
+	buf.Write(doc)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,315 @@
+package ees
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.ees"
+
+// ErrNotFound 文档或者索引不存在
+var ErrNotFound = errors.New("ees: not found")
+
+// Error 服务端返回的错误
+type Error struct {
+	StatusCode int
+	Type       string
+	Reason     string
+}
+
+// Error ...
+func (e *Error) Error() string {
+	return fmt.Sprintf("ees: status = %d type = %s reason = %s", e.StatusCode, e.Type, e.Reason)
+}
+
+// Is 404错误可以使用 errors.Is(err, ErrNotFound) 判断
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Response 响应
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Component Elasticsearch/OpenSearch 组件
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	client *http.Client
+	next   uint32 // 轮询的节点下标
+	tracer *etrace.Tracer
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		client: config.httpClient,
+		tracer: etrace.NewTracer(trace.SpanKindClient),
+	}
+}
+
+// Do 发送请求，body为[]byte时直接发送，其他类型序列化为JSON，非2xx的响应转换为 *Error
+func (c *Component) Do(ctx context.Context, method string, path string, query url.Values, body interface{}) (*Response, error) {
+	var payload []byte
+	switch v := body.(type) {
+	case nil:
+	case []byte:
+		payload = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("ees: marshal body fail, %w", err)
+		}
+		payload = data
+	}
+	return c.do(ctx, method, path, query, payload, "application/json")
+}
+
+// Ping 检查集群是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, http.MethodGet, "/", nil, nil)
+	return err
+}
+
+// Search 搜索，query为查询DSL，result为响应的反序列化结果
+func (c *Component) Search(ctx context.Context, index string, query interface{}, result interface{}) error {
+	resp, err := c.Do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", nil, query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Body, result)
+}
+
+// Index 写入文档，id为空时由服务端生成
+func (c *Component) Index(ctx context.Context, index string, id string, doc interface{}) error {
+	method, path := http.MethodPost, "/"+url.PathEscape(index)+"/_doc"
+	if id != "" {
+		method, path = http.MethodPut, path+"/"+url.PathEscape(id)
+	}
+	_, err := c.Do(ctx, method, path, nil, doc)
+	return err
+}
+
+// Get 获取文档，doc为_source的反序列化结果
+func (c *Component) Get(ctx context.Context, index string, id string, doc interface{}) error {
+	resp, err := c.Do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.Unmarshal(resp.Body, &res); err != nil {
+		return err
+	}
+	return json.Unmarshal(res.Source, doc)
+}
+
+// Delete 删除文档
+func (c *Component) Delete(ctx context.Context, index string, id string) error {
+	_, err := c.Do(ctx, http.MethodDelete, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, nil)
+	return err
+}
+
+func (c *Component) do(ctx context.Context, method string, path string, query url.Values, payload []byte, contentType string) (resp *Response, err error) {
+	beg := time.Now()
+	if c.config.EnableTraceInterceptor {
+		var span trace.Span
+		ctx, span = c.tracer.Start(ctx, "ees."+method, nil, trace.WithAttributes(
+			semconv.PeerServiceKey.String(c.name),
+			semconv.HTTPMethodKey.String(method),
+			attribute.String("ees.path", path),
+		))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+	defer func() {
+		c.observe(ctx, method, path, payload, beg, resp, err)
+	}()
+
+	for attempt := 0; ; attempt++ {
+		resp, err = c.send(ctx, method, path, query, payload, contentType)
+		if !retryable(resp, err) || attempt >= c.config.MaxRetries || ctx.Err() != nil {
+			break
+		}
+		etrace.AddRetryEvent(ctx, attempt+1, retryReason(resp, err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		err = parseError(resp)
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (c *Component) send(ctx context.Context, method string, path string, query url.Values, payload []byte, contentType string) (*Response, error) {
+	addr := c.config.Addrs[int(atomic.AddUint32(&c.next, 1)-1)%len(c.config.Addrs)]
+	target := addr + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.config.APIKey)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	httpResp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{StatusCode: httpResp.StatusCode, Header: httpResp.Header, Body: body}, nil
+}
+
+func retryable(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+}
+
+// retryReason 重试的原因，用于链路追踪
+func retryReason(resp *Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return http.StatusText(resp.StatusCode)
+}
+
+// observe 记录监控和日志，超过慢查询阈值的请求记录请求内容，便于排查
+func (c *Component) observe(ctx context.Context, method string, path string, payload []byte, beg time.Time, resp *Response, err error) {
+	cost := time.Since(beg)
+	code := "OK"
+	if resp != nil {
+		code = http.StatusText(resp.StatusCode)
+	} else if err != nil {
+		code = "error"
+	}
+	if c.config.EnableMetricInterceptor {
+		emetric.ClientHandleCounter.Inc(emetric.TypeES, c.name, method+" "+metricPath(path), c.config.Addrs[0], code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeES, c.name, method+" "+metricPath(path), c.config.Addrs[0])
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	isErr := err != nil && !errors.Is(err, ErrNotFound)
+	if !c.config.EnableAccessInterceptor && !isSlow && !isErr {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(method),
+		elog.FieldKey(path),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+	}
+	if resp != nil {
+		fields = append(fields, elog.FieldCode(int32(resp.StatusCode)))
+	}
+	if c.config.EnableAccessInterceptorReq || isSlow {
+		fields = append(fields, elog.String("req", truncate(payload, c.config.AccessInterceptorMaxLength)))
+	}
+	if c.config.EnableAccessInterceptorRes && resp != nil {
+		fields = append(fields, elog.String("res", truncate(resp.Body, c.config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case isErr:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+}
+
+// metricPath 使用path中的操作名作为监控维度，例如 /index/_search => _search，避免监控维度膨胀
+func metricPath(path string) string {
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "_") {
+			return segment
+		}
+	}
+	if path == "/" {
+		return "/"
+	}
+	return "index"
+}
+
+func truncate(data []byte, max int) string {
+	if max > 0 && len(data) > max {
+		return string(data[:max]) + "...(" + strconv.Itoa(len(data)) + " bytes)"
+	}
+	return string(data)
+}
+
+func parseError(resp *Response) error {
+	e := &Error{StatusCode: resp.StatusCode}
+	var res struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(resp.Body, &res) == nil && len(res.Error) > 0 {
+		var detail struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		}
+		if json.Unmarshal(res.Error, &detail) == nil {
+			e.Type, e.Reason = detail.Type, detail.Reason
+		} else {
+			e.Reason = string(res.Error)
+		}
+	}
+	if e.Type == "" && e.Reason == "" {
+		e.Reason = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
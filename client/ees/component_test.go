@@ -0,0 +1,189 @@
+package ees
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ehealth"
+)
+
+type fakeES struct {
+	mu    sync.Mutex
+	docs  map[string]json.RawMessage
+	bulks [][]string
+	down  int // 接下来的请求返回503的次数
+}
+
+func (f *fakeES) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down > 0 {
+		f.down--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if user, pass, _ := r.BasicAuth(); user != "elastic" || pass != "changeme" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.URL.Path == "/":
+		_, _ = w.Write([]byte(`{"version":{"number":"8.0.0"}}`))
+	case r.URL.Path == "/_bulk":
+		lines := make([]string, 0)
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		f.bulks = append(f.bulks, lines)
+		items := make([]string, 0)
+		for _, line := range lines {
+			if strings.HasPrefix(line, `{"index"`) {
+				status := 201
+				if strings.Contains(line, `"_id":"bad"`) {
+					status = 400
+				}
+				items = append(items, `{"index":{"status":`+strconv.Itoa(status)+`}}`)
+			}
+		}
+		_, _ = w.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+	case r.Method == http.MethodPut:
+		f.docs[r.URL.Path] = body
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"result":"created"}`))
+	case r.Method == http.MethodGet:
+		doc, ok := f.docs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"type":"index_not_found_exception","reason":"no such index"},"status":404}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"_source":` + string(doc) + `}`))
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":1}}}`))
+	}
+}
+
+func newTestComponent(t *testing.T, f *fakeES, options ...Option) *Component {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	options = append([]Option{WithAddrs(server.URL), WithBasicAuth("elastic", "changeme")}, options...)
+	return DefaultContainer().Build(options...)
+}
+
+func TestComponent(t *testing.T) {
+	f := &fakeES{docs: map[string]json.RawMessage{}}
+	comp := newTestComponent(t, f)
+	ctx := context.Background()
+
+	assert.NoError(t, comp.Ping(ctx))
+	assert.NoError(t, comp.Index(ctx, "user", "1", map[string]string{"name": "ego"}))
+	var doc map[string]string
+	assert.NoError(t, comp.Get(ctx, "user", "1", &doc))
+	assert.Equal(t, "ego", doc["name"])
+
+	err := comp.Get(ctx, "user", "2", &doc)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	var esErr *Error
+	assert.True(t, errors.As(err, &esErr))
+	assert.Equal(t, "index_not_found_exception", esErr.Type)
+
+	var res struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+	}
+	assert.NoError(t, comp.Search(ctx, "user", map[string]interface{}{"query": map[string]interface{}{"match_all": struct{}{}}}, &res))
+	assert.Equal(t, 1, res.Hits.Total.Value)
+
+	// 503时切换节点重试
+	f.down = 2
+	assert.NoError(t, comp.Ping(ctx))
+	f.down = 3
+	assert.Error(t, comp.Ping(ctx))
+}
+
+func TestBulkProcessor(t *testing.T) {
+	f := &fakeES{docs: map[string]json.RawMessage{}}
+	comp := newTestComponent(t, f, func(c *Container) {
+		c.config.BulkActions = 2
+		c.config.BulkFlushInterval = time.Hour
+	})
+	var failed []string
+	bulk := comp.NewBulkProcessor(func(item BulkItem, err error) {
+		failed = append(failed, item.ID)
+	})
+	ctx := context.Background()
+	assert.NoError(t, bulk.Add(ctx, BulkItem{Index: "user", ID: "1", Doc: map[string]string{"name": "a"}}))
+	assert.NoError(t, bulk.Add(ctx, BulkItem{Index: "user", ID: "bad", Doc: map[string]string{"name": "b"}}))
+	assert.NoError(t, bulk.Add(ctx, BulkItem{Index: "user", ID: "3", Doc: map[string]string{"name": "c"}}))
+	// Close时写入剩余的文档
+	assert.NoError(t, bulk.Close(ctx))
+	assert.ErrorIs(t, bulk.Add(ctx, BulkItem{}), ErrBulkClosed)
+
+	assert.Len(t, f.bulks, 2)
+	assert.Equal(t, `{"index":{"_id":"1","_index":"user"}}`, f.bulks[0][0])
+	assert.Equal(t, []string{"bad"}, failed)
+	assert.Equal(t, BulkStats{Added: 3, Flushed: 2, Failed: 1}, bulk.Stats())
+}
+
+func TestBulkProcessorFlushWithDeadline(t *testing.T) {
+	f := &fakeES{docs: map[string]json.RawMessage{}}
+	comp := newTestComponent(t, f, func(c *Container) {
+		c.config.BulkActions = 10
+		c.config.BulkFlushInterval = time.Hour
+	})
+	bulk := comp.NewBulkProcessor(func(item BulkItem, err error) {})
+	ctx := context.Background()
+	assert.NoError(t, bulk.Add(ctx, BulkItem{Index: "user", ID: "1", Doc: map[string]string{"name": "a"}}))
+	assert.NoError(t, bulk.Add(ctx, BulkItem{Index: "user", ID: "bad", Doc: map[string]string{"name": "b"}}))
+	flushed, dropped, err := bulk.FlushWithDeadline(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+	assert.Equal(t, 1, dropped)
+
+	// 已经关闭时没有需要写入的文档
+	flushed, dropped, err = bulk.FlushWithDeadline(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, flushed+dropped)
+}
+
+func TestHealthCheck(t *testing.T) {
+	f := &fakeES{docs: map[string]json.RawMessage{}, down: 10}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	c := DefaultContainer()
+	c.name = "es.test"
+	c.config.MaxRetries = 0
+	c.Build(WithAddrs(server.URL), WithBasicAuth("elastic", "changeme"))
+	defer ehealth.Unregister("es.test")
+
+	assert.False(t, ehealth.Healthy(ehealth.Check(context.Background())))
+	f.mu.Lock()
+	f.down = 0
+	f.mu.Unlock()
+	assert.True(t, ehealth.Healthy(ehealth.Check(context.Background())))
+}
+
+func TestMetricPath(t *testing.T) {
+	assert.Equal(t, "_search", metricPath("/user/_search"))
+	assert.Equal(t, "_doc", metricPath("/user/_doc/1"))
+	assert.Equal(t, "/", metricPath("/"))
+	assert.Equal(t, "index", metricPath("/user"))
+}
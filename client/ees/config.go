@@ -0,0 +1,51 @@
+package ees
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config Elasticsearch/OpenSearch 配置，两者的REST API兼容
+type Config struct {
+	Addrs                      []string      // 节点地址，请求按照轮询的方式发送，默认 http://127.0.0.1:9200
+	Username                   string        // Basic认证的用户名
+	Password                   string        // Basic认证的密码
+	APIKey                     string        // API Key认证，优先于Basic认证
+	ReadTimeout                time.Duration // 单次请求的超时，默认5s
+	MaxRetries                 int           // 网络错误、502、503、504时切换节点重试的次数，默认2
+	SlowLogThreshold           time.Duration // 慢查询日志的阈值，默认500ms
+	EnableTraceInterceptor     bool          // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor    bool          // 是否开启监控，默认开启
+	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
+	EnableAccessInterceptorReq bool          // 是否开启记录请求参数，默认不开启
+	EnableAccessInterceptorRes bool          // 是否开启记录响应参数，默认不开启
+	AccessInterceptorMaxLength int           // 记录请求、响应参数的最大长度，默认4K
+	EnableHealthCheck          bool          // 是否注册到组件健康检查，默认开启
+	HealthCritical             bool          // 健康检查失败时是否影响服务就绪，默认开启
+	BulkActions                int           // 批量写入每批最多的文档数，默认1000
+	BulkSize                   int           // 批量写入每批最大的字节数，默认5MB
+	BulkFlushInterval          time.Duration // 批量写入的刷新间隔，默认1s
+	BulkQueueSize              int           // 批量写入的队列长度，队列满时Add阻塞，默认4096
+	httpClient                 *http.Client  // 自定义http client
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Addrs:                      []string{"http://127.0.0.1:9200"},
+		ReadTimeout:                xtime.Duration("5s"),
+		MaxRetries:                 2,
+		SlowLogThreshold:           xtime.Duration("500ms"),
+		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
+		AccessInterceptorMaxLength: 4096,
+		EnableHealthCheck:          true,
+		HealthCritical:             true,
+		BulkActions:                1000,
+		BulkSize:                   5 << 20,
+		BulkFlushInterval:          xtime.Duration("1s"),
+		BulkQueueSize:              4096,
+	}
+}
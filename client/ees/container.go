@@ -0,0 +1,65 @@
+package ees
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build constructs a specific component from container.
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if len(c.config.Addrs) == 0 {
+		c.logger.Panic("addrs is empty")
+	}
+	for i, addr := range c.config.Addrs {
+		c.config.Addrs[i] = strings.TrimSuffix(addr, "/")
+	}
+	if c.config.httpClient == nil {
+		c.config.httpClient = &http.Client{Timeout: c.config.ReadTimeout}
+	}
+	c.logger = c.logger.With(elog.FieldAddr(strings.Join(c.config.Addrs, ",")))
+	comp := newComponent(c.name, c.config, c.logger)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("elasticsearch"), ehealth.WithCritical(c.config.HealthCritical), ehealth.WithTarget(strings.Join(c.config.Addrs, ",")))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
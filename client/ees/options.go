@@ -0,0 +1,27 @@
+package ees
+
+import (
+	"net/http"
+)
+
+// WithAddrs 设置节点地址
+func WithAddrs(addrs ...string) Option {
+	return func(c *Container) {
+		c.config.Addrs = addrs
+	}
+}
+
+// WithBasicAuth 设置Basic认证
+func WithBasicAuth(username, password string) Option {
+	return func(c *Container) {
+		c.config.Username = username
+		c.config.Password = password
+	}
+}
+
+// WithHTTPClient 设置自定义http client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Container) {
+		c.config.httpClient = client
+	}
+}
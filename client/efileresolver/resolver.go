@@ -0,0 +1,333 @@
+package efileresolver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gotomicro/ego/client/egrpc/resolver"
+	hresolver "github.com/gotomicro/ego/client/ehttp/resolver"
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/econf/manager"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// PackageName 包名
+const PackageName = "client.efileresolver"
+
+// Scheme 客户端地址的scheme
+const Scheme = "file"
+
+// 空导入后gRPC、HTTP客户端可以使用文件中的节点，文件修改后实时更新节点，例如
+//
+//	file://endpoints.yaml/svc-user          相对路径的文件中svc-user服务的节点
+//	file:///etc/ego/endpoints.yaml/svc-user 绝对路径的文件中svc-user服务的节点
+//	file:///etc/ego/user.yaml               文件中只有一个服务的节点列表
+//
+// 文件格式根据扩展名解析，支持yaml、json、toml等，例如
+//
+//	svc-user:
+//	  - address: 127.0.0.1:9001
+//	    weight: 50
+//	    metadata:
+//	      zone: a
+func init() {
+	reg := newRegistry()
+	resolver.Register(Scheme, reg)
+	hresolver.Register(Scheme, reg)
+}
+
+// NodeConfig 文件中的节点
+type NodeConfig struct {
+	Address  string            // 节点地址，例如 127.0.0.1:9001
+	Weight   float64           // 权重，默认100
+	Metadata map[string]string // 节点元数据
+	Region   string            // 区域
+	Zone     string            // 可用区
+	Disable  bool              // 临时摘除节点
+}
+
+// Registry 基于文件的服务发现，文件只读，服务注册、注销不生效
+type Registry struct {
+	logger   *elog.Component
+	mu       sync.Mutex
+	watchers map[string]*fileWatcher // 每个文件共用一个watcher
+}
+
+func newRegistry() *Registry {
+	return &Registry{
+		logger:   elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		watchers: make(map[string]*fileWatcher),
+	}
+}
+
+// RegisterService 文件只读，不注册服务
+func (r *Registry) RegisterService(context.Context, *server.ServiceInfo) error { return nil }
+
+// UnregisterService 文件只读，不注销服务
+func (r *Registry) UnregisterService(context.Context, *server.ServiceInfo) error { return nil }
+
+// ListServices 读取文件中的节点
+func (r *Registry) ListServices(_ context.Context, target eregistry.Target) ([]*server.ServiceInfo, error) {
+	path, service := parseTarget(target)
+	services, _, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := services[service]
+	res := make([]*server.ServiceInfo, 0, len(nodes))
+	for _, node := range nodes {
+		node := node
+		res = append(res, &node)
+	}
+	return res, nil
+}
+
+// WatchServices 监听文件中的节点，先返回当前的节点，文件修改后推送新的节点
+// 文件解析失败时保留上一次的节点，ctx取消后停止监听
+func (r *Registry) WatchServices(ctx context.Context, target eregistry.Target) (chan eregistry.Endpoints, error) {
+	path, service := parseTarget(target)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.watchers[abs]
+	if !ok {
+		if w, err = newFileWatcher(abs, r.logger); err != nil {
+			return nil, err
+		}
+		r.watchers[abs] = w
+	}
+	ch := w.subscribe(service)
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if w.unsubscribe(service, ch) {
+			w.close()
+			delete(r.watchers, abs)
+		}
+	}()
+	return ch, nil
+}
+
+// SyncServices 文件修改后已经实时推送，不需要同步
+func (r *Registry) SyncServices(context.Context, eregistry.SyncServicesOptions) error { return nil }
+
+// Close ...
+func (r *Registry) Close() error { return nil }
+
+// parseTarget 从地址中解析文件路径和服务名称，文件名以扩展名结尾，之后的部分为服务名称
+func parseTarget(target eregistry.Target) (path string, service string) {
+	full := "/" + target.Endpoint
+	if target.Authority != "" {
+		full = target.Authority + full
+	}
+	full = strings.TrimSuffix(full, "/")
+	for _, ext := range []string{".yaml", ".yml", ".json", ".toml"} {
+		if idx := strings.Index(full, ext+"/"); idx >= 0 {
+			return full[:idx+len(ext)], full[idx+len(ext)+1:]
+		}
+	}
+	return full, ""
+}
+
+// readFile 读取文件中所有服务的节点，只有节点列表时服务名称为空
+func readFile(path string) (map[string][]server.ServiceInfo, []byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	typ := econf.ConfigType(strings.TrimPrefix(filepath.Ext(path), "."))
+	if typ == "yml" {
+		typ = econf.ConfigTypeYaml
+	}
+	unmarshal, err := manager.Unmarshaller(typ)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s fail, %w", path, err)
+	}
+	var raw interface{}
+	if err := unmarshal(content, &raw); err != nil {
+		return nil, nil, fmt.Errorf("parse %s fail, %w", path, err)
+	}
+
+	nodes := make(map[string][]NodeConfig)
+	if _, ok := raw.([]interface{}); ok {
+		var list []NodeConfig
+		err = mapstructure.Decode(raw, &list)
+		nodes[""] = list
+	} else {
+		err = mapstructure.Decode(raw, &nodes)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s fail, %w", path, err)
+	}
+
+	services := make(map[string][]server.ServiceInfo, len(nodes))
+	for name, list := range nodes {
+		for i, node := range list {
+			if node.Address == "" {
+				return nil, nil, fmt.Errorf("parse %s fail, address of node %d in %q is empty", path, i, name)
+			}
+			if node.Disable {
+				continue
+			}
+			if node.Weight == 0 {
+				node.Weight = 100
+			}
+			services[name] = append(services[name], server.ServiceInfo{
+				Name:     name,
+				Address:  node.Address,
+				Weight:   node.Weight,
+				Enable:   true,
+				Healthy:  true,
+				Metadata: node.Metadata,
+				Region:   node.Region,
+				Zone:     node.Zone,
+				Kind:     constant.ServiceProvider,
+			})
+		}
+	}
+	return services, content, nil
+}
+
+// fileWatcher 监听文件所在的目录，兼容编辑器替换文件和k8s ConfigMap更新软链接
+type fileWatcher struct {
+	path     string
+	logger   *elog.Component
+	watcher  *fsnotify.Watcher
+	mu       sync.Mutex
+	content  []byte
+	services map[string][]server.ServiceInfo
+	subs     map[string]map[chan eregistry.Endpoints]struct{}
+}
+
+func newFileWatcher(path string, logger *elog.Component) (*fileWatcher, error) {
+	services, content, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	w := &fileWatcher{
+		path:     path,
+		logger:   logger.With(elog.String("path", path)),
+		watcher:  watcher,
+		content:  content,
+		services: services,
+		subs:     make(map[string]map[chan eregistry.Endpoints]struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *fileWatcher) run() {
+	for {
+		select {
+		case _, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.reload()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("watch file error", elog.FieldErr(err))
+		}
+	}
+}
+
+func (w *fileWatcher) reload() {
+	services, content, err := readFile(w.path)
+	if err != nil {
+		// 文件正在写入或者格式错误，保留上一次的节点
+		w.logger.Warn("reload endpoints fail, keep last endpoints", elog.FieldErr(err))
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if bytes.Equal(content, w.content) {
+		return
+	}
+	w.content, w.services = content, services
+	for service, subs := range w.subs {
+		for ch := range subs {
+			send(ch, w.endpoints(service))
+		}
+	}
+	w.logger.Info("reload endpoints")
+}
+
+func (w *fileWatcher) endpoints(service string) eregistry.Endpoints {
+	res := eregistry.Endpoints{
+		Nodes:           make(map[string]server.ServiceInfo),
+		RouteConfigs:    make(map[string]eregistry.RouteConfig),
+		ConsumerConfigs: make(map[string]eregistry.ConsumerConfig),
+		ProviderConfigs: make(map[string]eregistry.ProviderConfig),
+	}
+	for _, node := range w.services[service] {
+		res.Nodes[node.Address] = node
+	}
+	return res
+}
+
+func (w *fileWatcher) subscribe(service string) chan eregistry.Endpoints {
+	ch := make(chan eregistry.Endpoints, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.subs[service] == nil {
+		w.subs[service] = make(map[chan eregistry.Endpoints]struct{})
+	}
+	w.subs[service][ch] = struct{}{}
+	send(ch, w.endpoints(service))
+	return ch
+}
+
+// unsubscribe 取消订阅，没有订阅者时返回true
+func (w *fileWatcher) unsubscribe(service string, ch chan eregistry.Endpoints) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subs[service], ch)
+	if len(w.subs[service]) == 0 {
+		delete(w.subs, service)
+	}
+	return len(w.subs) == 0
+}
+
+func (w *fileWatcher) close() {
+	_ = w.watcher.Close()
+}
+
+// send 订阅者没有及时读取时只保留最新的节点
+func send(ch chan eregistry.Endpoints, endpoints eregistry.Endpoints) {
+	for {
+		select {
+		case ch <- endpoints:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
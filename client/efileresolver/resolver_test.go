@@ -0,0 +1,108 @@
+package efileresolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/gotomicro/ego/client/egrpc"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/internal/test/helloworld"
+)
+
+type greeter struct {
+	helloworld.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(_ context.Context, req *helloworld.HelloRequest) (*helloworld.HelloResponse, error) {
+	return &helloworld.HelloResponse{Message: "Hello " + req.Name}, nil
+}
+
+func TestParseTarget(t *testing.T) {
+	for _, tt := range []struct {
+		target  eregistry.Target
+		path    string
+		service string
+	}{
+		{eregistry.Target{Authority: "endpoints.yaml"}, "endpoints.yaml", ""},
+		{eregistry.Target{Authority: "endpoints.yaml", Endpoint: "svc-user"}, "endpoints.yaml", "svc-user"},
+		{eregistry.Target{Endpoint: "etc/ego/endpoints.yml/svc-user"}, "/etc/ego/endpoints.yml", "svc-user"},
+		{eregistry.Target{Endpoint: "etc/ego/user.json"}, "/etc/ego/user.json", ""},
+	} {
+		path, service := parseTarget(tt.target)
+		assert.Equal(t, tt.path, path)
+		assert.Equal(t, tt.service, service)
+	}
+}
+
+func TestWatchServices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+svc-user:
+  - address: 127.0.0.1:9001
+    weight: 50
+    metadata:
+      zone: a
+  - address: 127.0.0.1:9002
+    disable: true
+`), 0o644))
+
+	reg := newRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := reg.WatchServices(ctx, eregistry.Target{Endpoint: path[1:] + "/svc-user"})
+	assert.NoError(t, err)
+	endpoints := <-ch
+	assert.Len(t, endpoints.Nodes, 1)
+	assert.Equal(t, 50.0, endpoints.Nodes["127.0.0.1:9001"].Weight)
+	assert.Equal(t, "a", endpoints.Nodes["127.0.0.1:9001"].Metadata["zone"])
+
+	// 格式错误时保留上一次的节点
+	assert.NoError(t, os.WriteFile(path, []byte(`svc-user: [`), 0o644))
+	assert.NoError(t, os.WriteFile(path, []byte(`
+svc-user:
+  - address: 127.0.0.1:9003
+`), 0o644))
+	select {
+	case endpoints = <-ch:
+	case <-time.After(3 * time.Second):
+		t.Fatal("wait endpoints timeout")
+	}
+	assert.Equal(t, []string{"127.0.0.1:9003"}, keys(endpoints))
+	assert.Equal(t, 100.0, endpoints.Nodes["127.0.0.1:9003"].Weight)
+
+	list, err := reg.ListServices(ctx, eregistry.Target{Endpoint: path[1:] + "/svc-user"})
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestGRPCDiscovery(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	helloworld.RegisterGreeterServer(srv, greeter{})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("- address: %s\n", lis.Addr())), 0o644))
+	client := egrpc.DefaultContainer().Build(egrpc.WithAddr("file://" + path))
+	resp, err := helloworld.NewGreeterClient(client.ClientConn).SayHello(context.Background(), &helloworld.HelloRequest{Name: "Ego"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello Ego", resp.Message)
+}
+
+func keys(endpoints eregistry.Endpoints) []string {
+	res := make([]string, 0, len(endpoints.Nodes))
+	for key := range endpoints.Nodes {
+		res = append(res, key)
+	}
+	return res
+}
@@ -7,9 +7,14 @@ import (
 
 	"go.uber.org/zap/zapgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/grpclog"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
+	"github.com/gotomicro/ego/core/eidentity"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/internal/egrpclog"
 )
@@ -44,7 +49,13 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		dialOptions = append(dialOptions, grpc.WithBlock())
 	}
 
-	if config.EnableWithInsecure {
+	if config.EnableIdentity {
+		identity := eidentity.Default()
+		if identity == nil {
+			logger.Panic("identity enabled but eidentity component is not built")
+		}
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(identity.GRPCClientCredentials(config.IdentityAllowedIDs...)))
+	} else if config.EnableWithInsecure {
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
@@ -98,3 +109,24 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 func (c *Component) Error() error {
 	return c.err
 }
+
+// Ping 使用gRPC健康检查协议检查服务端，服务端没有实现健康检查时，连接状态正常视为健康
+func (c *Component) Ping(ctx context.Context) error {
+	if c.err != nil {
+		return c.err
+	}
+	resp, err := healthpb.NewHealthClient(c.ClientConn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.config.HealthService})
+	if status.Code(err) == codes.Unimplemented {
+		if state := c.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			return fmt.Errorf("grpc connection is %s", state)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("grpc health status is %s", resp.GetStatus())
+	}
+	return nil
+}
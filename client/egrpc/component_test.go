@@ -83,3 +83,15 @@ func (g Greeter) SayHello(context context.Context, request *helloworld.HelloRequ
 func bufDialer(context.Context, string) (net.Conn, error) {
 	return svc.Listener().(*bufconn.Listener).Dial()
 }
+
+func TestComponent_Ping(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.dialOptions = append(cfg.dialOptions, grpc.WithContextDialer(bufDialer))
+	cmp := newComponent("test-cmp", cfg, elog.DefaultLogger)
+	assert.NoError(t, cmp.Ping(context.Background()))
+
+	cfg.HealthService = "unknown.Service"
+	assert.Error(t, cmp.Ping(context.Background()))
+
+	assert.EqualError(t, (&Component{err: fmt.Errorf("dial fail")}).Ping(context.Background()), "dial fail")
+}
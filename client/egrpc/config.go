@@ -33,7 +33,18 @@ type Config struct {
 	// EnableCPUUsage               bool          // 是否开启CPU利用率，默认开启
 	EnableServiceConfig          bool // 是否开启服务配置，默认开启
 	EnableFailOnNonTempDialError bool
-	MaxCallRecvMsgSize           int // 最大接收消息大小，默认4MB
+	MaxCallRecvMsgSize           int           `unit:"size"` // 最大接收消息大小，默认4MB
+	BulkheadMaxConcurrent        int           // 最大并发调用数，超过后排队或拒绝，默认0不限制
+	BulkheadMaxQueue             int           // 并发已满时最多排队的调用数，默认0不排队
+	BulkheadQueueTimeout         time.Duration // 最长排队时间，默认0只受请求超时控制
+	Resilience                   string        // eresilience策略的配置key，例如 resilience.user，所有unary调用共享该策略，只重试Unavailable和Aborted，默认为空不开启
+	EnableSingleflight           bool          // 是否合并相同方法和参数的并发unary调用，只适用于幂等并且与调用方身份无关的查询，默认不开启
+	SingleflightMethods          []string      // 需要合并的方法，例如 /helloworld.Greeter/SayHello，为空时合并所有unary调用
+	EnableIdentity               bool          // 是否开启mTLS服务身份认证，使用eidentity默认组件的证书，开启后忽略EnableWithInsecure，默认不开启
+	IdentityAllowedIDs           []string      // 允许的服务端SPIFFE ID，支持*结尾的前缀匹配，为空时使用eidentity配置中的AllowedIDs
+	EnableHealthCheck            bool          // 是否注册到组件健康检查，使用gRPC健康检查协议，服务端没有实现时检查连接状态，默认不开启
+	HealthService                string        // 健康检查的服务名，默认为空，检查服务端整体的状态
+	HealthCritical               bool          // 健康检查失败时是否影响服务就绪，默认开启
 
 	keepAlive   *keepalive.ClientParameters
 	dialOptions []grpc.DialOption
@@ -59,6 +70,7 @@ func DefaultConfig() *Config {
 		EnableAccessInterceptorReq:   false,
 		EnableAccessInterceptorRes:   false,
 		EnableServiceConfig:          true,
+		HealthCritical:               true,
 		// EnableCPUUsage:               true,
 		MaxCallRecvMsgSize: DefaultMaxCallRecvMsgSize,
 	}
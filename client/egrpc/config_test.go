@@ -31,6 +31,7 @@ func TestDefaultConfig(t *testing.T) {
 		EnableAccessInterceptorRes:   false,
 		EnableFailOnNonTempDialError: true,
 		EnableServiceConfig:          true,
+		HealthCritical:               true,
 		keepAlive:                    nil,
 		dialOptions:                  nil,
 		MaxCallRecvMsgSize:           DefaultMaxCallRecvMsgSize,
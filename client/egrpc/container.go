@@ -3,9 +3,15 @@ package egrpc
 import (
 	"google.golang.org/grpc"
 
+	// 注册target的resolver
+	_ "github.com/gotomicro/ego/client/egrpc/resolver"
+
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ecomponent"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etarget"
 )
 
 // Option overrides a Container's default configuration.
@@ -49,6 +55,9 @@ func (c *Container) Build(options ...Option) *Component {
 		unaryInterceptors = append(unaryInterceptors, c.traceUnaryClientInterceptor())
 		streamInterceptors = append(streamInterceptors, c.traceStreamClientInterceptor())
 	}
+	// 透传请求ID
+	unaryInterceptors = append(unaryInterceptors, requestIDUnaryClientInterceptor())
+	streamInterceptors = append(streamInterceptors, requestIDStreamClientInterceptor())
 	// 默认日志
 	unaryInterceptors = append(unaryInterceptors, c.loggerUnaryClientInterceptor())
 	if eapp.IsDevelopmentMode() {
@@ -64,12 +73,34 @@ func (c *Container) Build(options ...Option) *Component {
 	if c.config.EnableMetricInterceptor {
 		unaryInterceptors = append(unaryInterceptors, c.metricUnaryClientInterceptor())
 	}
+	// 合并后的调用只占用一个舱壁许可
+	if c.config.EnableSingleflight {
+		unaryInterceptors = append(unaryInterceptors, c.singleflightUnaryClientInterceptor())
+	}
+	if c.config.Resilience != "" {
+		unaryInterceptors = append(unaryInterceptors, c.resilienceUnaryClientInterceptor())
+	}
+	// 舱壁放在最后，拒绝的调用也会记录日志和监控
+	if c.config.BulkheadMaxConcurrent > 0 {
+		unaryInterceptors = append(unaryInterceptors, c.bulkheadUnaryClientInterceptor())
+	}
 	for _, option := range options {
 		option(c)
 	}
+	// 逻辑服务名称替换为 target:///name，由target的resolver解析真实地址并监听配置变化
+	addr, err := etarget.Resolve(c.config.Addr)
+	if err != nil {
+		c.logger.Panic("resolve target error", elog.FieldErr(err), elog.FieldAddr(c.config.Addr))
+	}
+	c.config.Addr = addr
 	c.config.dialOptions = append(c.config.dialOptions,
 		grpc.WithChainStreamInterceptor(streamInterceptors...),
 		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
 	)
-	return newComponent(c.name, c.config, c.logger)
+	comp := newComponent(c.name, c.config, c.logger)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("grpc"), ehealth.WithCritical(c.config.HealthCritical), ehealth.WithTarget(c.config.Addr))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
 }
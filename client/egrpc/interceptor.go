@@ -3,6 +3,7 @@ package egrpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -17,15 +18,22 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
+	grpcCode "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ebulkhead"
 	"github.com/gotomicro/ego/core/eerrors"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/eresilience"
+	"github.com/gotomicro/ego/core/eslow"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/transport"
 	"github.com/gotomicro/ego/core/util/xdebug"
@@ -51,6 +59,106 @@ func (c *Container) metricUnaryClientInterceptor() func(ctx context.Context, met
 	}
 }
 
+// bulkheadUnaryClientInterceptor 限制对该客户端的并发调用，拒绝时返回 ResourceExhausted，熔断时返回 Unavailable
+func (c *Container) bulkheadUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	bulkhead := ebulkhead.New(c.name, ebulkhead.Config{
+		MaxConcurrent: c.config.BulkheadMaxConcurrent,
+		MaxQueue:      c.config.BulkheadMaxQueue,
+		QueueTimeout:  c.config.BulkheadQueueTimeout,
+	})
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var invoked bool
+		err := bulkhead.Do(ctx, func(ctx context.Context) error {
+			invoked = true
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if invoked {
+			return err
+		}
+		switch {
+		case errors.Is(err, ebulkhead.ErrRejected):
+			return status.Error(grpcCode.ResourceExhausted, err.Error())
+		case errors.Is(err, ebulkhead.ErrBreakerOpen):
+			return status.Error(grpcCode.Unavailable, err.Error())
+		}
+		return status.FromContextError(err).Err()
+	}
+}
+
+// resilienceUnaryClientInterceptor 按照 Resilience 配置的策略执行调用，策略拒绝的调用转换为gRPC错误码
+func (c *Container) resilienceUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	policy := eresilience.Load(c.config.Resilience).Build(eresilience.WithRetryable(resilienceRetryable))
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := policy.Do(ctx, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		switch {
+		case errors.Is(err, ebulkhead.ErrRejected):
+			return status.Error(grpcCode.ResourceExhausted, err.Error())
+		case errors.Is(err, ebulkhead.ErrBreakerOpen):
+			return status.Error(grpcCode.Unavailable, err.Error())
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			return status.FromContextError(err).Err()
+		}
+		return err
+	}
+}
+
+// resilienceRetryable 只重试 Unavailable 和 Aborted，其他错误码重试通常也不会成功
+func resilienceRetryable(err error) bool {
+	if !eresilience.Retryable(err) {
+		return false
+	}
+	switch status.Code(err) {
+	case grpcCode.Unavailable, grpcCode.Aborted:
+		return true
+	}
+	return false
+}
+
+// singleflightUnaryClientInterceptor 合并相同方法和参数的并发调用，只有第一个调用会发送到下游，其余调用共享它的响应
+// 第一个调用被取消时共享它的调用也会失败
+func (c *Container) singleflightUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	var group singleflight.Group
+	methods := make(map[string]struct{}, len(c.config.SingleflightMethods))
+	for _, method := range c.config.SingleflightMethods {
+		methods[method] = struct{}{}
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := methods[method]; len(methods) > 0 && !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		reqMsg, ok1 := req.(proto.Message)
+		replyMsg, ok2 := reply.(proto.Message)
+		if !ok1 || !ok2 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		args, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		v, err, shared := group.Do(method+"|"+string(args), func() (interface{}, error) {
+			res := replyMsg.ProtoReflect().New().Interface()
+			if err := invoker(ctx, method, req, res, cc, opts...); err != nil {
+				return nil, err
+			}
+			return res, nil
+		})
+		if shared {
+			emetric.ClientCollapsedCounter.Inc(emetric.TypeGRPCUnary, c.name, method)
+		}
+		if err != nil {
+			return err
+		}
+		proto.Reset(replyMsg)
+		proto.Merge(replyMsg, v.(proto.Message))
+		return nil
+	}
+}
+
 // debugUnaryClientInterceptor returns grpc unary request request and response details interceptor
 func (c *Container) debugUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
@@ -86,16 +194,17 @@ func (c *Container) traceUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 		)
 		// 因为我们最先执行trace，所以这里，直接new出来metadata
 		ctx = metadata.NewOutgoingContext(ctx, md)
+		beg := time.Now()
 		defer func() {
 			if err != nil {
-				span.RecordError(err)
 				if e := eerrors.FromError(err); e != nil {
 					span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(e.Code)))
 				}
-				span.SetStatus(codes.Error, err.Error())
+				etrace.RecordError(span, err)
 			} else {
 				span.SetStatus(codes.Ok, "OK")
 			}
+			etrace.RecordSlow(span, time.Since(beg), c.config.SlowLogThreshold)
 			span.End()
 		}()
 		return invoker(ctx, method, req, reply, cc, opts...)
@@ -255,11 +364,10 @@ func (c *Container) traceStreamClientInterceptor() grpc.StreamClientInterceptor
 
 		s, err := streamer(ctx, desc, cc, method, opts...)
 		if err != nil {
-			span.RecordError(err)
 			if e := eerrors.FromError(err); e != nil {
 				span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(e.Code)))
 			}
-			span.SetStatus(codes.Error, err.Error())
+			etrace.RecordError(span, err)
 			span.End()
 			return s, err
 		}
@@ -268,11 +376,10 @@ func (c *Container) traceStreamClientInterceptor() grpc.StreamClientInterceptor
 		go func() {
 			err := <-stream.finished
 			if err != nil {
-				span.RecordError(err)
 				if e := eerrors.FromError(err); e != nil {
 					span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(e.Code)))
 				}
-				span.SetStatus(codes.Error, err.Error())
+				etrace.RecordError(span, err)
 			} else {
 				span.SetStatus(codes.Ok, "OK")
 			}
@@ -308,6 +415,33 @@ func (c *Container) timeoutUnaryClientInterceptor() grpc.UnaryClientInterceptor
 	}
 }
 
+// requestIDOutgoingContext 把ctx中的请求ID放入metadata，已经设置过时不重复设置
+func requestIDOutgoingContext(ctx context.Context) context.Context {
+	requestID := erequestid.FromContext(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	key := strings.ToLower(erequestid.Header())
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, key, requestID)
+}
+
+// requestIDUnaryClientInterceptor 透传请求ID
+func requestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, res interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(requestIDOutgoingContext(ctx), method, req, res, cc, opts...)
+	}
+}
+
+// requestIDStreamClientInterceptor 透传请求ID
+func requestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(requestIDOutgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
 // loggerUnaryClientInterceptor returns log interceptor for logging
 func (c *Container) loggerUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, res interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
@@ -328,6 +462,7 @@ func (c *Container) loggerUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 
 		err := invoker(ctx, method, req, res, cc, opts...)
 		cost := time.Since(beg)
+		eslow.AddCall(ctx, eslow.Call{Type: "grpc", Name: c.name, Method: method, Peer: cc.Target()}, beg, err)
 		spbStatus := ecode.Convert(err)
 		httpStatusCode := ecode.GrpcToHTTPStatusCode(spbStatus.Code())
 		event := "normal"
@@ -345,6 +480,9 @@ func (c *Container) loggerUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 		if etrace.IsGlobalTracerRegistered() {
 			fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
 		}
+		if requestID := erequestid.FromContext(ctx); requestID != "" {
+			fields = append(fields, elog.FieldRequestID(requestID))
+		}
 
 		if c.config.EnableAccessInterceptorReq {
 			var reqMap = map[string]any{
@@ -7,15 +7,23 @@ import (
 	"log"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/erequestid"
 	"github.com/gotomicro/ego/core/util/xtime"
 	"github.com/gotomicro/ego/internal/test/helloworld"
 	"github.com/gotomicro/ego/internal/tools"
@@ -193,3 +201,118 @@ func (g GreeterHeader) SayHello(context context.Context, request *helloworld.Hel
 		Message: "Hello",
 	}, nil
 }
+
+func TestRequestIDUnaryClientInterceptor(t *testing.T) {
+	interceptor := requestIDUnaryClientInterceptor()
+	var got []string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		got = md.Get("x-request-id")
+		return nil
+	}
+	ctx := erequestid.WithRequestID(context.Background(), "rid-1")
+	assert.NoError(t, interceptor(ctx, "/hello", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"rid-1"}, got)
+
+	// 已经设置过时不重复设置
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", "manual")
+	assert.NoError(t, interceptor(ctx, "/hello", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"manual"}, got)
+}
+
+func TestBulkheadUnaryClientInterceptor(t *testing.T) {
+	c := DefaultContainer()
+	c.name = "test.bulkhead"
+	c.config.BulkheadMaxConcurrent = 1
+	interceptor := c.bulkheadUnaryClientInterceptor()
+	cc := new(grpc.ClientConn)
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	go func() {
+		_ = interceptor(context.Background(), "/foo", nil, nil, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			close(entered)
+			<-unblock
+			return nil
+		})
+	}()
+	<-entered
+	err := interceptor(context.Background(), "/foo", nil, nil, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	close(unblock)
+}
+
+func TestResilienceUnaryClientInterceptor(t *testing.T) {
+	err := econf.LoadFromReader(strings.NewReader(`
+[resilience.user]
+maxAttempts = 3
+backoff = "constant"
+minBackoff = "1ms"
+`), toml.Unmarshal)
+	assert.NoError(t, err)
+	c := DefaultContainer()
+	c.config.Resilience = "resilience.user"
+	interceptor := c.resilienceUnaryClientInterceptor()
+	cc := new(grpc.ClientConn)
+
+	var calls int32
+	err = interceptor(context.Background(), "/foo", nil, nil, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return status.Error(codes.Unavailable, "connection refused")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+
+	// 其他错误码不重试
+	calls = 0
+	err = interceptor(context.Background(), "/foo", nil, nil, cc, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		return status.Error(codes.InvalidArgument, "bad request")
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestSingleflightUnaryClientInterceptor(t *testing.T) {
+	c := DefaultContainer()
+	c.name = "test.singleflight"
+	interceptor := c.singleflightUnaryClientInterceptor()
+	cc := new(grpc.ClientConn)
+
+	var calls int32
+	unblock := make(chan struct{})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		atomic.AddInt32(&calls, 1)
+		<-unblock
+		reply.(*helloworld.HelloResponse).Message = "hello " + req.(*helloworld.HelloRequest).Name
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	replies := make([]*helloworld.HelloResponse, 5)
+	for i := range replies {
+		replies[i] = &helloworld.HelloResponse{}
+		wg.Add(1)
+		go func(reply *helloworld.HelloResponse) {
+			defer wg.Done()
+			assert.NoError(t, interceptor(context.Background(), "/helloworld.Greeter/SayHello", &helloworld.HelloRequest{Name: "ego"}, reply, cc, invoker))
+		}(replies[i])
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, reply := range replies {
+		assert.Equal(t, "hello ego", reply.Message)
+	}
+
+	// 参数不同不合并
+	reply := &helloworld.HelloResponse{}
+	assert.NoError(t, interceptor(context.Background(), "/helloworld.Greeter/SayHello", &helloworld.HelloRequest{Name: "other"}, reply, cc, invoker))
+	assert.Equal(t, "hello other", reply.Message)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -8,6 +8,7 @@ import (
 	"google.golang.org/grpc/resolver"
 
 	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eextension"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/server"
@@ -15,6 +16,7 @@ import (
 
 // Register ...
 func Register(name string, reg eregistry.Registry) {
+	_ = eextension.Register(eextension.KindGRPCRegistry, name)
 	resolver.Register(&baseBuilder{
 		name: name,
 		reg:  reg,
@@ -0,0 +1,118 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etarget"
+)
+
+func init() {
+	resolver.Register(&targetBuilder{})
+}
+
+// targetBuilder 解析 target:///user-service 别名，别名对应的地址变化后切换到新的地址
+type targetBuilder struct{}
+
+// Build ...
+func (b *targetBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	name := strings.Trim(target.URL.Host+target.URL.Path+target.URL.Opaque, "/")
+	addr, ok := etarget.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("target %q not found in config %q", name, etarget.ConfigKey)
+	}
+	inner, err := buildInner(addr, cc, opts)
+	if err != nil {
+		return nil, err
+	}
+	r := &targetResolver{name: name, cc: cc, opts: opts, addr: addr, inner: inner}
+	// 不持有锁监听，避免与配置热更新的回调互相等待
+	r.watcher = etarget.Watch(name, r.update)
+	if current := r.watcher.Addr(); current != "" && current != addr {
+		r.update(current)
+	}
+	return r, nil
+}
+
+// Scheme ...
+func (b *targetBuilder) Scheme() string {
+	return etarget.Scheme
+}
+
+type targetResolver struct {
+	name    string
+	cc      resolver.ClientConn
+	opts    resolver.BuildOptions
+	watcher *etarget.Watcher
+	mu      sync.Mutex
+	addr    string
+	inner   resolver.Resolver
+}
+
+// ResolveNow ...
+func (r *targetResolver) ResolveNow(options resolver.ResolveNowOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inner != nil {
+		r.inner.ResolveNow(options)
+	}
+}
+
+// Close ...
+func (r *targetResolver) Close() {
+	r.watcher.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inner != nil {
+		r.inner.Close()
+		r.inner = nil
+	}
+}
+
+// update 先关闭旧地址的resolver，避免旧的注册中心继续推送节点，新地址解析失败时恢复旧地址
+func (r *targetResolver) update(addr string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.inner == nil {
+		return
+	}
+	r.inner.Close()
+	inner, err := buildInner(addr, r.cc, r.opts)
+	if err != nil {
+		elog.Error("switch target fail, keep last addr", elog.FieldErr(err), elog.FieldName(r.name), elog.FieldAddr(addr))
+		if inner, err = buildInner(r.addr, r.cc, r.opts); err != nil {
+			elog.Error("restore target fail", elog.FieldErr(err), elog.FieldName(r.name), elog.FieldAddr(r.addr))
+			r.inner = nil
+			return
+		}
+		r.inner = inner
+		return
+	}
+	elog.Info("switch target", elog.FieldName(r.name), elog.String("from", r.addr), elog.FieldAddr(addr))
+	r.addr, r.inner = addr, inner
+}
+
+// buildInner 使用别名对应地址的resolver，grpc://host:port 和没有scheme的地址直连
+func buildInner(addr string, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	scheme, rest := etarget.SplitScheme(addr)
+	if scheme == "" || scheme == "grpc" {
+		addr = "passthrough:///" + rest
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parse target addr %q fail, %w", addr, err)
+	}
+	if u.Scheme == etarget.Scheme {
+		return nil, fmt.Errorf("target addr %q can not be another target", addr)
+	}
+	builder := resolver.Get(u.Scheme)
+	if builder == nil {
+		return nil, fmt.Errorf("resolver of scheme %q not registered, target addr %q", u.Scheme, addr)
+	}
+	return builder.Build(resolver.Target{URL: *u}, cc, opts)
+}
@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/internal/test/helloworld"
+)
+
+type namedGreeter struct {
+	helloworld.UnimplementedGreeterServer
+	name string
+}
+
+func (g namedGreeter) SayHello(context.Context, *helloworld.HelloRequest) (*helloworld.HelloResponse, error) {
+	return &helloworld.HelloResponse{Message: g.name}, nil
+}
+
+func startGreeter(t *testing.T, name string) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	helloworld.RegisterGreeterServer(srv, namedGreeter{name: name})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+type memoryDataSource struct {
+	content string
+}
+
+func (m *memoryDataSource) Parse(string, bool) econf.ConfigType { return econf.ConfigTypeToml }
+func (m *memoryDataSource) ReadConfig() ([]byte, error)         { return []byte(m.content), nil }
+func (m *memoryDataSource) IsConfigChanged() <-chan struct{}    { return nil }
+func (m *memoryDataSource) Close() error                        { return nil }
+
+func TestTargetResolver(t *testing.T) {
+	econf.Reset()
+	defer econf.Reset()
+	blue, green := startGreeter(t, "blue"), startGreeter(t, "green")
+	ds := &memoryDataSource{content: "[targets]\nhello-service = \"grpc://" + blue + "\""}
+	assert.NoError(t, econf.LoadFromDataSource(ds, toml.Unmarshal))
+
+	cc, err := grpc.Dial("target:///hello-service", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer cc.Close()
+	sayHello := func() string {
+		resp, err := helloworld.NewGreeterClient(cc).SayHello(context.Background(), &helloworld.HelloRequest{})
+		if err != nil {
+			return err.Error()
+		}
+		return resp.Message
+	}
+	assert.Equal(t, "blue", sayHello())
+
+	// 配置热更新后切换到新的地址
+	ds.content = "[targets]\nhello-service = \"" + green + "\""
+	assert.NoError(t, econf.Reload())
+	assert.Eventually(t, func() bool { return sayHello() == "green" }, 3*time.Second, 20*time.Millisecond)
+
+	// 新地址的resolver不存在时保留原来的地址
+	ds.content = "[targets]\nhello-service = \"unknown:///hello\""
+	assert.NoError(t, econf.Reload())
+	assert.Equal(t, "green", sayHello())
+
+	_, err = grpc.Dial("target:///order-service", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.ErrorContains(t, err, `target "order-service" not found`)
+}
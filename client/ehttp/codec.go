@@ -0,0 +1,123 @@
+package ehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/internal/ecode"
+)
+
+const (
+	// MIMEJSON JSON格式
+	MIMEJSON = "application/json"
+	// MIMEProtobuf protobuf格式
+	MIMEProtobuf = "application/x-protobuf"
+	// ReasonCodec 编码请求或者解码响应失败时的错误原因
+	ReasonCodec = "ehttp.codec"
+)
+
+// errorBody 下游返回的错误响应，兼容egin的错误响应和eerrors.EgoError的JSON格式
+type errorBody struct {
+	Reason   string            `json:"reason"`
+	Msg      string            `json:"msg"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// JSON 使用JSON编码in作为请求体，in为nil时不发送请求体，2xx的响应解码到out，out为nil时不解码
+// 请求失败或者响应状态码不是2xx时，返回*eerrors.EgoError，状态码转换为对应的gRPC错误码
+func (c *Component) JSON(ctx context.Context, method, path string, in, out any) error {
+	req := c.R().SetContext(ctx).SetHeader("Accept", MIMEJSON)
+	if in != nil {
+		body, err := json.Marshal(in)
+		if err != nil {
+			return eerrors.New(int(codes.InvalidArgument), ReasonCodec, "encode json request fail, "+err.Error())
+		}
+		req.SetHeader("Content-Type", MIMEJSON).SetBody(body)
+	}
+	resp, err := c.execute(req, method, path)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(resp.Body()) == 0 {
+		return nil
+	}
+	if err = json.Unmarshal(resp.Body(), out); err != nil {
+		return eerrors.New(int(codes.Internal), ReasonCodec, "decode json response fail, "+err.Error())
+	}
+	return nil
+}
+
+// Proto 使用protobuf编码in作为请求体，in为nil时不发送请求体，2xx的响应解码到out，out为nil时不解码
+// 错误处理与JSON相同，下游返回的错误响应需要是JSON格式
+func (c *Component) Proto(ctx context.Context, method, path string, in, out proto.Message) error {
+	req := c.R().SetContext(ctx).SetHeader("Accept", MIMEProtobuf)
+	if in != nil {
+		body, err := proto.Marshal(in)
+		if err != nil {
+			return eerrors.New(int(codes.InvalidArgument), ReasonCodec, "encode proto request fail, "+err.Error())
+		}
+		req.SetHeader("Content-Type", MIMEProtobuf).SetBody(body)
+	}
+	resp, err := c.execute(req, method, path)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err = proto.Unmarshal(resp.Body(), out); err != nil {
+		return eerrors.New(int(codes.Internal), ReasonCodec, "decode proto response fail, "+err.Error())
+	}
+	return nil
+}
+
+func (c *Component) execute(req *resty.Request, method, path string) (*resty.Response, error) {
+	resp, err := req.Execute(method, path)
+	if err != nil {
+		return nil, fromTransportError(err)
+	}
+	if !resp.IsSuccess() {
+		return nil, fromResponse(resp)
+	}
+	return resp, nil
+}
+
+// fromTransportError 请求没有拿到响应时的错误
+func fromTransportError(err error) *eerrors.EgoError {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return eerrors.DeadlineExceeded(eerrors.UnknownReason, err.Error())
+	case errors.Is(err, context.Canceled):
+		return eerrors.Canceled(eerrors.UnknownReason, err.Error())
+	}
+	return eerrors.New(int(codes.Unavailable), eerrors.UnknownReason, err.Error())
+}
+
+// fromResponse 将错误响应转换为*eerrors.EgoError，metadata中的httpStatus记录原始的状态码
+func fromResponse(resp *resty.Response) *eerrors.EgoError {
+	var body errorBody
+	_ = json.Unmarshal(resp.Body(), &body)
+	msg := body.Msg
+	if msg == "" {
+		msg = body.Message
+	}
+	if msg == "" {
+		msg = resp.Status()
+	}
+	md := make(map[string]string, len(body.Metadata)+1)
+	for k, v := range body.Metadata {
+		md[k] = v
+	}
+	md["httpStatus"] = strconv.Itoa(resp.StatusCode())
+	err := eerrors.New(int(ecode.HTTPToGrpcStatusCode(resp.StatusCode())), body.Reason, msg)
+	err.Metadata = md
+	return err
+}
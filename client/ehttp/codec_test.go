@@ -0,0 +1,130 @@
+package ehttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		switch r.Header.Get("Content-Encoding") {
+		case "zstd":
+			zr, err := zstd.NewReader(r.Body)
+			require.NoError(t, err)
+			defer zr.Close()
+			body = zr
+		case "gzip":
+			gr, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = gr
+		}
+		content, err := io.ReadAll(body)
+		require.NoError(t, err)
+		w.Header().Set("X-Request-Encoding", r.Header.Get("Content-Encoding"))
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") {
+			w.Header().Set("Content-Encoding", "zstd")
+			zw, _ := zstd.NewWriter(w)
+			_, _ = zw.Write(content)
+			_ = zw.Close()
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.Addr = server.URL
+	config.EnableCompression = true
+	config.CompressionAlgorithm = "zstd"
+	config.CompressionMinSize = 16
+	config.EnableDecompression = true
+	comp := newComponent("test", config, elog.DefaultLogger)
+
+	large := strings.Repeat("hello", 100)
+	resp, err := comp.R().SetBody(large).Post("/echo")
+	require.NoError(t, err)
+	assert.Equal(t, "zstd", resp.Header().Get("X-Request-Encoding"))
+	assert.Equal(t, large, resp.String())
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+
+	// 小于阈值的请求体不压缩
+	resp, err = comp.R().SetBody("hi").Post("/echo")
+	require.NoError(t, err)
+	assert.Empty(t, resp.Header().Get("X-Request-Encoding"))
+	assert.Equal(t, "hi", resp.String())
+
+	gz, err := encode(CompressionGzip, []byte(large))
+	require.NoError(t, err)
+	gr, err := gzip.NewReader(bytes.NewReader(gz))
+	require.NoError(t, err)
+	plain, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, large, string(plain))
+}
+
+func TestJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			var in user
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+			assert.Equal(t, MIMEJSON, r.Header.Get("Content-Type"))
+			_ = json.NewEncoder(w).Encode(user{Name: strings.ToUpper(in.Name)})
+		case "/proto":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", MIMEProtobuf)
+			_, _ = w.Write(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"reason":"user.not_found","msg":"user not found","metadata":{"id":"1"}}`))
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.Addr = server.URL
+	comp := newComponent("test", config, elog.DefaultLogger)
+	ctx := context.Background()
+
+	var out user
+	require.NoError(t, comp.JSON(ctx, http.MethodPost, "/users", user{Name: "ego"}, &out))
+	assert.Equal(t, "EGO", out.Name)
+
+	err := comp.JSON(ctx, http.MethodGet, "/users/1", nil, &out)
+	egoErr := eerrors.FromError(err)
+	assert.Equal(t, int32(codes.NotFound), egoErr.GetCode())
+	assert.Equal(t, "user.not_found", egoErr.GetReason())
+	assert.Equal(t, "user not found", egoErr.GetMessage())
+	assert.Equal(t, map[string]string{"id": "1", "httpStatus": "404"}, egoErr.GetMetadata())
+
+	pb := &wrapperspb.StringValue{}
+	require.NoError(t, comp.Proto(ctx, http.MethodPost, "/proto", wrapperspb.String("ego"), pb))
+	assert.Equal(t, "ego", pb.GetValue())
+
+	// 请求失败转换为Unavailable
+	config = DefaultConfig()
+	config.Addr = "http://127.0.0.1:1"
+	err = newComponent("test", config, elog.DefaultLogger).JSON(ctx, http.MethodGet, "/", nil, nil)
+	assert.Equal(t, int32(codes.Unavailable), eerrors.FromError(err).GetCode())
+}
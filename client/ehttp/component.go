@@ -1,11 +1,11 @@
 package ehttp
 
 import (
-	"net"
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/gotomicro/ego/client/ehttp/resolver"
@@ -37,8 +37,12 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 	// 因为resty默认只要http和https的协议
 	addr := config.Addr
 	if egoTarget.Scheme != "http" && egoTarget.Scheme != "https" {
-		// 因为内部协议，都是内网，所以直接替换为HTTP
-		addr = strings.ReplaceAll(config.Addr, egoTarget.Scheme+"://", "http://")
+		// 因为内部协议，都是内网，所以直接替换为HTTP，开启服务身份认证时替换为HTTPS
+		scheme := "http://"
+		if config.EnableIdentity {
+			scheme = "https://"
+		}
+		addr = strings.ReplaceAll(config.Addr, egoTarget.Scheme+"://", scheme)
 	}
 	builder := resolver.Get(egoTarget.Scheme)
 	resolverBuild, err := builder.Build(addr)
@@ -47,12 +51,23 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 	}
 
 	// resty的默认方法，无法设置长连接个数，和是否开启长连接，这里重新构造http client。
-	interceptors := []interceptor{fixedInterceptor, logInterceptor, metricInterceptor, traceInterceptor}
+	interceptors := []interceptor{fixedInterceptor, bulkheadInterceptor, logInterceptor, metricInterceptor, traceInterceptor}
 	// 如果有设置自定义httpClient，那么不为空，使用用户自定义httpClient
 	if config.httpClient == nil {
 		// 如果用户没有设置，使用ego默认的httpClient
 		config.httpClient = &http.Client{Transport: createTransport(config), Jar: config.cookieJar}
 	}
+	if config.EnableCompression || config.EnableDecompression {
+		httpClient := *config.httpClient
+		httpClient.Transport = newCompressTransport(config, httpClient.Transport)
+		config.httpClient = &httpClient
+	}
+	if config.EnableSingleflight {
+		// 拷贝一份，避免修改用户自定义的httpClient
+		httpClient := *config.httpClient
+		httpClient.Transport = newSingleflightTransport(name, httpClient.Transport)
+		config.httpClient = &httpClient
+	}
 
 	cli := resty.NewWithClient(config.httpClient).
 		SetDebug(config.RawDebug).
@@ -81,6 +96,24 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 	}
 }
 
+// Ping 请求HealthCheckPath检查下游是否可用，响应状态码小于500视为健康
+func (c *Component) Ping(ctx context.Context) error {
+	resp, err := c.R().SetContext(ctx).Get(c.config.HealthCheckPath)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return fmt.Errorf("health check status is %s", resp.Status())
+	}
+	return nil
+}
+
+// Restart 关闭连接池中的空闲连接，之后的请求重新建立连接，用于治理端口重建组件
+func (c *Component) Restart(_ context.Context) error {
+	c.GetClient().CloseIdleConnections()
+	return nil
+}
+
 func parseTarget(addr string) (eregistry.Target, error) {
 	target, err := url.Parse(addr)
 	if err != nil {
@@ -100,23 +133,3 @@ func parseTarget(addr string) (eregistry.Target, error) {
 	}
 	return egoTarget, nil
 }
-
-func createTransport(config *Config) *http.Transport {
-	dialer := &net.Dialer{
-		Timeout:   30 * time.Second,
-		KeepAlive: 30 * time.Second,
-		DualStack: true,
-	}
-
-	return &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
-		ForceAttemptHTTP2:     true,
-		MaxIdleConns:          config.MaxIdleConns,
-		IdleConnTimeout:       config.IdleConnTimeout,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-		DisableKeepAlives:     !config.EnableKeepAlives,
-		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
-	}
-}
@@ -1,7 +1,10 @@
 package ehttp
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
@@ -48,3 +51,17 @@ func TestNewComponent(t *testing.T) {
 	assert.Equal(t, in.builder, out.builder)
 	// assert.Equal(t, in.Client, out.Client)
 }
+
+func TestComponent_Ping(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusNotFound)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+
+	comp := DefaultContainer().Build(WithAddr(server.URL))
+	assert.NoError(t, comp.Ping(context.Background()))
+	status.Store(http.StatusServiceUnavailable)
+	assert.ErrorContains(t, comp.Ping(context.Background()), "503")
+}
@@ -0,0 +1,153 @@
+package ehttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressionGzip gzip压缩算法
+	CompressionGzip = "gzip"
+	// CompressionZstd zstd压缩算法
+	CompressionZstd = "zstd"
+)
+
+// compressTransport 压缩请求体，解压响应体
+// 请求头中已经有Content-Encoding或者Accept-Encoding时，认为调用方自己处理，不做修改
+type compressTransport struct {
+	base       http.RoundTripper
+	compress   bool
+	algorithm  string
+	minSize    int
+	decompress bool
+}
+
+func newCompressTransport(config *Config, base http.RoundTripper) *compressTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	algorithm := strings.ToLower(config.CompressionAlgorithm)
+	if algorithm != CompressionZstd {
+		algorithm = CompressionGzip
+	}
+	return &compressTransport{
+		base:       base,
+		compress:   config.EnableCompression,
+		algorithm:  algorithm,
+		minSize:    config.CompressionMinSize,
+		decompress: config.EnableDecompression,
+	}
+}
+
+// CloseIdleConnections 关闭底层连接池中的空闲连接
+func (t *compressTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// RoundTrip ...
+func (t *compressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper不能修改原始请求，需要时拷贝一份
+	out := req
+	if t.compress && req.Body != nil && req.Body != http.NoBody && req.Header.Get("Content-Encoding") == "" {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out = req.Clone(req.Context())
+		if len(body) >= t.minSize {
+			if body, err = encode(t.algorithm, body); err != nil {
+				return nil, fmt.Errorf("compress request body fail, %w", err)
+			}
+			out.Header.Set("Content-Encoding", t.algorithm)
+		}
+		out.ContentLength = int64(len(body))
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	decompress := t.decompress && req.Header.Get("Accept-Encoding") == "" && req.Method != http.MethodHead
+	if decompress {
+		if out == req {
+			out = req.Clone(req.Context())
+		}
+		out.Header.Set("Accept-Encoding", CompressionZstd+", "+CompressionGzip)
+	}
+	resp, err := t.base.RoundTrip(out)
+	if err != nil || !decompress {
+		return resp, err
+	}
+	return decodeResponse(resp)
+}
+
+func encode(algorithm string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch algorithm {
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+	default:
+		w = gzip.NewWriter(&buf)
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeResponse 按照响应的Content-Encoding解压，解压后删除Content-Encoding和Content-Length
+func decodeResponse(resp *http.Response) (*http.Response, error) {
+	var body io.ReadCloser
+	switch strings.ToLower(resp.Header.Get("Content-Encoding")) {
+	case CompressionGzip:
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("decompress gzip response fail, %w", err)
+		}
+		body = &decodeReader{Reader: r, closer: resp.Body}
+	case CompressionZstd:
+		r, err := zstd.NewReader(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("decompress zstd response fail, %w", err)
+		}
+		body = &decodeReader{Reader: r, closer: resp.Body, release: r.Close}
+	default:
+		return resp, nil
+	}
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// decodeReader 关闭时同时关闭原始的响应体
+type decodeReader struct {
+	io.Reader
+	closer  io.Closer
+	release func()
+}
+
+func (r *decodeReader) Close() error {
+	if r.release != nil {
+		r.release()
+	}
+	return r.closer.Close()
+}
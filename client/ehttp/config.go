@@ -19,15 +19,44 @@ type Config struct {
 	IdleConnTimeout            time.Duration // 设置空闲连接时间，默认90 * time.Second
 	MaxIdleConns               int           // 设置最大空闲连接数
 	MaxIdleConnsPerHost        int           // 设置长连接个数
+	MaxConnsPerHost            int           // 每个host的最大连接数，包括正在使用的连接，默认0不限制
+	EnableHTTP2                bool          // 是否尝试使用HTTP/2，默认开启
+	HTTP2ReadIdleTimeout       time.Duration // HTTP/2连接多久没有收到数据后发送ping检查连接，默认0不检查
+	HTTP2PingTimeout           time.Duration // HTTP/2 ping的超时时间，超时后关闭连接，默认15s
 	EnableTraceInterceptor     bool          // 是否开启链路追踪，默认开启
 	EnableKeepAlives           bool          // 是否开启长连接，默认打开
 	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
 	EnableAccessInterceptorReq bool
-	EnableAccessInterceptorRes bool           // 是否开启记录响应参数，默认不开启
-	PathRelabel                []Relabel      // path 重命名 (metric 用)
-	cookieJar                  http.CookieJar // 用于缓存cookie
-	httpClient                 *http.Client   // 自定义http client
-	EnableMetricInterceptor    bool           // 是否开启Metric采集，默认禁用，开启metrics采集，可能造成metrics在prometheus中膨胀会导致占用大量的prometheus内存
+	EnableAccessInterceptorRes bool                    // 是否开启记录响应参数，默认不开启
+	PathRelabel                []Relabel               // path 重命名 (metric 用)
+	cookieJar                  http.CookieJar          // 用于缓存cookie
+	httpClient                 *http.Client            // 自定义http client
+	EnableMetricInterceptor    bool                    // 是否开启Metric采集，默认禁用，开启metrics采集，可能造成metrics在prometheus中膨胀会导致占用大量的prometheus内存
+	BulkheadMaxConcurrent      int                     // 最大并发调用数，超过后排队或拒绝，默认0不限制
+	BulkheadMaxQueue           int                     // 并发已满时最多排队的调用数，默认0不排队
+	BulkheadQueueTimeout       time.Duration           // 最长排队时间，默认0只受请求超时控制
+	EnableSingleflight         bool                    // 是否合并相同URL的并发GET请求，只适用于幂等并且与调用方身份无关的查询，默认不开启
+	EnableIdentity             bool                    // 是否开启mTLS服务身份认证，使用eidentity默认组件的证书，服务发现的地址使用https，默认不开启
+	IdentityAllowedIDs         []string                // 允许的服务端SPIFFE ID，支持*结尾的前缀匹配，为空时使用eidentity配置中的AllowedIDs
+	EnableCompression          bool                    // 是否压缩请求体，默认不开启
+	CompressionAlgorithm       string                  // 请求体的压缩算法，支持gzip、zstd，默认gzip
+	CompressionMinSize         int                     `unit:"size"` // 请求体不小于该大小时才压缩，默认1024字节
+	EnableDecompression        bool                    // 是否声明支持zstd、gzip压缩的响应并自动解压，默认不开启
+	EnableHealthCheck          bool                    // 是否注册到组件健康检查，请求HealthCheckPath，响应状态码小于500视为健康，默认不开启
+	HealthCheckPath            string                  // 健康检查的路径，默认 /
+	HealthCritical             bool                    // 健康检查失败时是否影响服务就绪，默认开启
+	Targets                    map[string]TargetConfig // 按下游地址隔离的连接池，key为请求URL的host，例如 api.example.com:8443，没有配置的地址使用共享的连接池
+}
+
+// TargetConfig 单个下游地址的连接池配置，零值的字段使用全局配置
+type TargetConfig struct {
+	MaxIdleConns         int           // 最大空闲连接数
+	MaxIdleConnsPerHost  int           // 每个host的最大空闲连接数
+	MaxConnsPerHost      int           // 每个host的最大连接数
+	IdleConnTimeout      time.Duration // 空闲连接的超时时间
+	EnableHTTP2          *bool         // 是否尝试使用HTTP/2
+	HTTP2ReadIdleTimeout time.Duration // HTTP/2连接多久没有收到数据后发送ping检查连接
+	HTTP2PingTimeout     time.Duration // HTTP/2 ping的超时时间
 }
 
 // Relabel ...
@@ -47,6 +76,12 @@ func DefaultConfig() *Config {
 		MaxIdleConns:               100,
 		MaxIdleConnsPerHost:        runtime.GOMAXPROCS(0) + 1,
 		IdleConnTimeout:            90 * time.Second,
+		EnableHTTP2:                true,
+		HTTP2PingTimeout:           15 * time.Second,
+		CompressionAlgorithm:       "gzip",
+		CompressionMinSize:         1024,
+		HealthCheckPath:            "/",
+		HealthCritical:             true,
 		EnableKeepAlives:           true,
 		EnableTraceInterceptor:     true,
 		EnableAccessInterceptor:    false,
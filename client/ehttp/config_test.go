@@ -21,6 +21,12 @@ func Test_DefaultConfig(t *testing.T) {
 		IdleConnTimeout:            90 * time.Second,
 		MaxIdleConns:               100,
 		MaxIdleConnsPerHost:        runtime.GOMAXPROCS(0) + 1,
+		EnableHTTP2:                true,
+		HTTP2PingTimeout:           15 * time.Second,
+		CompressionAlgorithm:       "gzip",
+		CompressionMinSize:         1024,
+		HealthCheckPath:            "/",
+		HealthCritical:             true,
 		EnableTraceInterceptor:     true,
 		EnableKeepAlives:           true,
 		EnableAccessInterceptor:    false,
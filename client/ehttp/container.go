@@ -3,8 +3,11 @@ package ehttp
 import (
 	"regexp"
 
+	"github.com/gotomicro/ego/core/ecomponent"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etarget"
 )
 
 // Option 选项
@@ -50,6 +53,17 @@ func (c *Container) Build(options ...Option) *Component {
 		option(c)
 	}
 
+	// 逻辑服务名称替换为 target:///name，由target的resolver解析真实地址并监听配置变化
+	addr, err := etarget.Resolve(c.config.Addr)
+	if err != nil {
+		c.logger.Panic("resolve target error", elog.FieldErr(err), elog.FieldAddr(c.config.Addr))
+	}
+	c.config.Addr = addr
 	c.logger.With(elog.FieldAddr(c.config.Addr))
-	return newComponent(c.name, c.config, c.logger)
+	comp := newComponent(c.name, c.config, c.logger)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("http"), ehealth.WithCritical(c.config.HealthCritical), ehealth.WithTarget(c.config.Addr))
+	}
+	ecomponent.Register(c.name, PackageName, comp.Restart)
+	return comp
 }
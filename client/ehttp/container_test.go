@@ -1,7 +1,10 @@
 package ehttp
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/BurntSushi/toml"
@@ -20,3 +23,27 @@ func TestLoad(t *testing.T) {
 	logger := DefaultContainer().logger.With(elog.FieldComponentName("test"))
 	assert.Equal(t, logger, Load("test").logger)
 }
+
+func TestBuildWithTarget(t *testing.T) {
+	econf.Reset()
+	defer econf.Reset()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(`
+[targets]
+hello-service = "`+srv.URL+`"
+`), toml.Unmarshal))
+
+	// 使用逻辑服务名称，创建时替换为别名对应的地址
+	comp := DefaultContainer().Build(WithAddr("hello-service"))
+	assert.Equal(t, "target:///hello-service", comp.config.Addr)
+	resp, err := comp.R().Get("/hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "/hello", resp.String())
+
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithAddr("target:///order-service"))
+	})
+}
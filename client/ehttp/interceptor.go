@@ -2,6 +2,7 @@ package ehttp
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"net/url"
@@ -23,8 +24,11 @@ import (
 	"github.com/gotomicro/ego/client/ehttp/resolver"
 
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ebulkhead"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/eslow"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/util/xdebug"
 )
@@ -35,6 +39,7 @@ func logAccess(name string, config *Config, logger *elog.Component, req *resty.R
 	u := req.Context().Value(urlKey{}).(*url.URL)
 	fullMethod := req.Method + "." + u.RequestURI() // GET./hello
 	var cost = time.Since(beg(req.Context()))
+	eslow.AddCall(req.Context(), eslow.Call{Type: emetric.TypeHTTP, Name: name, Method: fullMethod, Peer: u.Host}, beg(req.Context()), err)
 	var respBody string
 	if res != nil {
 		respBody = string(res.Body())
@@ -70,6 +75,9 @@ func logAccess(name string, config *Config, logger *elog.Component, req *resty.R
 	if etrace.IsGlobalTracerRegistered() {
 		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(req.Context())))
 	}
+	if requestID := erequestid.FromContext(req.Context()); requestID != "" {
+		fields = append(fields, elog.FieldRequestID(requestID))
+	}
 	if config.EnableAccessInterceptor {
 		if config.EnableAccessInterceptorReq {
 			fields = append(fields, elog.Any("req", map[string]any{
@@ -118,6 +126,7 @@ func logAccess(name string, config *Config, logger *elog.Component, req *resty.R
 // https://golang.org/pkg/context/#WithValue ，这边文章说明了用struct，可以避免分配
 type begKey struct{}
 type urlKey struct{}
+type bulkheadKey struct{}
 
 func beg(ctx context.Context) time.Time {
 	begTime, _ := ctx.Value(begKey{}).(time.Time)
@@ -128,7 +137,15 @@ func fixedInterceptor(name string, config *Config, logger *elog.Component, build
 	return func(cli *resty.Client, req *resty.Request) error {
 		// 这个URL可能不准，每次请求都需要重复url.Parse()，会增加一定的性能损耗
 		var concatURL string
-		if config.Addr == "" {
+		// 只有存在，才会更新
+		if addr := builder.GetAddr(); addr != "" {
+			cli.HostURL = addr
+			// resty优先使用BaseURL，相对路径的请求直接拼接注册中心、别名解析出的地址
+			if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+				req.URL = strings.TrimRight(addr, "/") + "/" + strings.TrimLeft(req.URL, "/")
+			}
+		}
+		if config.Addr == "" || strings.HasPrefix(req.URL, "http://") || strings.HasPrefix(req.URL, "https://") {
 			// 没有配置addr，host可能在url里面 (request.Get("http://xxx.com/xxx"))
 			concatURL = req.URL
 		} else {
@@ -148,10 +165,6 @@ func fixedInterceptor(name string, config *Config, logger *elog.Component, build
 				}
 			}
 		}
-		// 只有存在，才会更新
-		if builder.GetAddr() != "" {
-			cli.HostURL = builder.GetAddr()
-		}
 		req.SetContext(context.WithValue(context.WithValue(req.Context(), begKey{}, time.Now()), urlKey{}, u))
 		return nil
 	}, nil, nil
@@ -166,6 +179,10 @@ func logInterceptor(name string, config *Config, logger *elog.Component, builder
 				req.SetHeader(key, cast.ToString(value))
 			}
 		}
+		// 透传请求ID
+		if requestID := erequestid.FromContext(req.Context()); requestID != "" && req.Header.Get(erequestid.Header()) == "" {
+			req.SetHeader(erequestid.Header(), requestID)
+		}
 		return nil
 	}
 
@@ -183,6 +200,46 @@ func logInterceptor(name string, config *Config, logger *elog.Component, builder
 	return beforeFn, afterFn, errorFn
 }
 
+// bulkheadInterceptor 限制对该客户端的并发调用，请求前获取许可，响应或者出错后释放
+func bulkheadInterceptor(name string, config *Config, logger *elog.Component, builder resolver.Resolver) (resty.RequestMiddleware, resty.ResponseMiddleware, resty.ErrorHook) {
+	if config.BulkheadMaxConcurrent <= 0 {
+		return nil, nil, nil
+	}
+	bulkhead := ebulkhead.New(name, ebulkhead.Config{
+		MaxConcurrent: config.BulkheadMaxConcurrent,
+		MaxQueue:      config.BulkheadMaxQueue,
+		QueueTimeout:  config.BulkheadQueueTimeout,
+	})
+	finish := func(req *resty.Request, err error) {
+		if done, ok := req.Context().Value(bulkheadKey{}).(func(error)); ok {
+			done(err)
+		}
+	}
+	beforeFn := func(cli *resty.Client, req *resty.Request) error {
+		// 重试时释放上一次请求的许可
+		finish(req, nil)
+		done, err := bulkhead.Begin(req.Context())
+		if err != nil {
+			return err
+		}
+		req.SetContext(context.WithValue(req.Context(), bulkheadKey{}, done))
+		return nil
+	}
+	afterFn := func(cli *resty.Client, res *resty.Response) error {
+		var err error
+		// 服务端错误计入熔断统计
+		if res.StatusCode() >= http.StatusInternalServerError {
+			err = errors.New(res.Status())
+		}
+		finish(res.Request, err)
+		return nil
+	}
+	errorFn := func(req *resty.Request, err error) {
+		finish(req, err)
+	}
+	return beforeFn, afterFn, errorFn
+}
+
 func metricInterceptor(name string, config *Config, logger *elog.Component, builder resolver.Resolver) (resty.RequestMiddleware, resty.ResponseMiddleware, resty.ErrorHook) {
 	if !config.EnableMetricInterceptor {
 		return nil, nil, nil
@@ -228,15 +285,17 @@ func traceInterceptor(name string, config *Config, logger *elog.Component, build
 		span.SetAttributes(
 			semconv.HTTPStatusCodeKey.Int64(int64(res.StatusCode())),
 		)
+		// 客户端把4xx、5xx都作为错误
+		if res.StatusCode() >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, res.Status())
+		}
+		etrace.RecordSlow(span, res.Time(), config.SlowLogThreshold)
 		span.End()
 		return nil
 	}
 	errorFn := func(req *resty.Request, err error) {
 		span := trace.SpanFromContext(req.Context())
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
-		}
+		etrace.RecordError(span, err)
 		span.End()
 	}
 	return beforeFn, afterFn, errorFn
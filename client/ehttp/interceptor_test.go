@@ -2,6 +2,8 @@ package ehttp
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
@@ -10,7 +12,9 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/gotomicro/ego/core/ebulkhead"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/erequestid"
 )
 
 func TestLogAccess(t *testing.T) {
@@ -72,3 +76,30 @@ func TestFileWithLineNum(t *testing.T) {
 	got := fileWithLineNum()
 	assert.True(t, true, strings.HasPrefix(got, file))
 }
+
+func TestLogInterceptorRequestID(t *testing.T) {
+	before, _, _ := logInterceptor("test", &Config{}, elog.EgoLogger, &CustomResolver{})
+	request := resty.New().R().SetContext(erequestid.WithRequestID(context.Background(), "rid-1"))
+	assert.NoError(t, before(nil, request))
+	assert.Equal(t, "rid-1", request.Header.Get(erequestid.DefaultHeader))
+}
+
+func TestBulkheadInterceptor(t *testing.T) {
+	before, after, onErr := bulkheadInterceptor("test.bulkhead", &Config{BulkheadMaxConcurrent: 1}, elog.EgoLogger, &CustomResolver{})
+	first := resty.New().R().SetContext(context.Background())
+	assert.NoError(t, before(nil, first))
+
+	second := resty.New().R().SetContext(context.Background())
+	assert.ErrorIs(t, before(nil, second), ebulkhead.ErrRejected)
+
+	// 出错后释放许可
+	onErr(first, errors.New("timeout"))
+	assert.NoError(t, before(nil, second))
+	assert.NoError(t, after(nil, &resty.Response{Request: second, RawResponse: &http.Response{StatusCode: http.StatusOK}}))
+	assert.NoError(t, before(nil, first))
+
+	before, after, onErr = bulkheadInterceptor("test.bulkhead", &Config{}, elog.EgoLogger, &CustomResolver{})
+	assert.Nil(t, before)
+	assert.Nil(t, after)
+	assert.Nil(t, onErr)
+}
@@ -61,6 +61,22 @@ func WithMaxIdleConnsPerHost(maxIdleConnsPerHost int) Option {
 	}
 }
 
+// WithCompression 设置压缩请求体，algorithm支持gzip、zstd，请求体不小于minSize时才压缩
+func WithCompression(algorithm string, minSize int) Option {
+	return func(c *Container) {
+		c.config.EnableCompression = true
+		c.config.CompressionAlgorithm = algorithm
+		c.config.CompressionMinSize = minSize
+	}
+}
+
+// WithEnableDecompression 设置自动解压zstd、gzip压缩的响应
+func WithEnableDecompression(enableDecompression bool) Option {
+	return func(c *Container) {
+		c.config.EnableDecompression = enableDecompression
+	}
+}
+
 // WithEnableTraceInterceptor 设置开启Trace拦截器
 func WithEnableTraceInterceptor(enableTraceInterceptor bool) Option {
 	return func(c *Container) {
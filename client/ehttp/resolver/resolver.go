@@ -4,11 +4,13 @@ import (
 	"context"
 	"net/url"
 	"strings"
+	"sync"
 
 	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/resolver"
 
 	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eextension"
 	"github.com/gotomicro/ego/core/eregistry"
 	"github.com/gotomicro/ego/server"
 )
@@ -36,6 +38,7 @@ type Resolver interface {
 
 // Register ...
 func Register(name string, reg eregistry.Registry) {
+	_ = eextension.Register(eextension.KindHTTPRegistry, name)
 	b := &baseBuilder{
 		name: name,
 		reg:  reg,
@@ -57,10 +60,11 @@ type baseBuilder struct {
 
 // Build ...
 func (b *baseBuilder) Build(addr string) (Resolver, error) {
+	// ctx在resolver关闭时取消，注册中心在此之前持续推送节点的变化
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 	target, err := url.Parse(addr)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	endpoint := target.Path
@@ -104,10 +108,13 @@ type baseResolver struct {
 	reg    eregistry.Registry
 	cancel context.CancelFunc
 	// addrSlices []string
+	mu       sync.RWMutex                      // 保护nodeInfo，注册中心推送时更新，请求时读取
 	nodeInfo map[string]*attributes.Attributes // node节点的属性
 }
 
 func (b *baseResolver) GetAddr() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	for key := range b.nodeInfo {
 		return "http://" + key
 	}
@@ -146,6 +153,7 @@ func (b *baseResolver) run(endpoints chan eregistry.Endpoints) {
 				}
 				// 如果node信息有变更，那么就添加，更新或者删除
 				b.tryUpdateAttrs(endpoint.Nodes)
+				b.mu.RLock()
 				for key, node := range endpoint.Nodes {
 					var address resolver.Address
 					address.Addr = node.Address
@@ -153,6 +161,7 @@ func (b *baseResolver) run(endpoints chan eregistry.Endpoints) {
 					address.Attributes = b.nodeInfo[key]
 					state.Addresses = append(state.Addresses, address)
 				}
+				b.mu.RUnlock()
 			case <-b.stop:
 				return
 			}
@@ -162,6 +171,8 @@ func (b *baseResolver) run(endpoints chan eregistry.Endpoints) {
 
 // tryUpdateAttrs 更新节点数据
 func (b *baseResolver) tryUpdateAttrs(nodes map[string]server.ServiceInfo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	for addr, node := range nodes {
 		oldAttr, ok := b.nodeInfo[addr]
 		newAttr := attributes.New(constant.KeyServiceInfo, node)
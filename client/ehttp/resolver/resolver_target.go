@@ -0,0 +1,114 @@
+package resolver
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etarget"
+)
+
+func init() {
+	m[etarget.Scheme] = &targetBuilder{}
+}
+
+// targetBuilder 解析 target:///user-service 别名，别名对应的地址变化后切换到新的地址
+type targetBuilder struct{}
+
+// Build 客户端已经将 target:// 替换为 http://，从路径中获取别名
+func (b *targetBuilder) Build(addr string) (Resolver, error) {
+	target, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Trim(target.Host+target.Path+target.Opaque, "/")
+	targetAddr, ok := etarget.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("target %q not found in config %q", name, etarget.ConfigKey)
+	}
+	inner, err := buildTargetInner(targetAddr)
+	if err != nil {
+		return nil, err
+	}
+	r := &targetResolver{name: name, addr: targetAddr, inner: inner}
+	r.watcher = etarget.Watch(name, r.update)
+	if current := r.watcher.Addr(); current != "" && current != targetAddr {
+		r.update(current)
+	}
+	return r, nil
+}
+
+// Scheme ...
+func (b *targetBuilder) Scheme() string {
+	return etarget.Scheme
+}
+
+type targetResolver struct {
+	name    string
+	watcher *etarget.Watcher
+	mu      sync.RWMutex
+	addr    string
+	inner   Resolver
+}
+
+// GetAddr 返回别名当前地址解析出的节点
+func (r *targetResolver) GetAddr() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.inner.GetAddr()
+}
+
+// Close ...
+func (r *targetResolver) Close() {
+	r.watcher.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	closeResolver(r.inner)
+}
+
+// update 新地址解析成功后再替换，解析失败时保留旧地址
+func (r *targetResolver) update(addr string) {
+	inner, err := buildTargetInner(addr)
+	if err != nil {
+		elog.Error("switch target fail, keep last addr", elog.FieldErr(err), elog.FieldName(r.name), elog.FieldAddr(addr))
+		return
+	}
+	r.mu.Lock()
+	old := r.inner
+	r.addr, r.inner = addr, inner
+	r.mu.Unlock()
+	closeResolver(old)
+	elog.Info("switch target", elog.FieldName(r.name), elog.FieldAddr(addr))
+}
+
+// buildTargetInner http、https和没有scheme的地址直接使用，其他scheme使用对应注册中心的resolver
+func buildTargetInner(addr string) (Resolver, error) {
+	scheme, rest := etarget.SplitScheme(addr)
+	switch scheme {
+	case "":
+		return staticResolver("http://" + rest), nil
+	case "http", "https":
+		return staticResolver(addr), nil
+	case etarget.Scheme:
+		return nil, fmt.Errorf("target addr %q can not be another target", addr)
+	}
+	builder := Get(scheme)
+	if builder == nil {
+		return nil, fmt.Errorf("resolver of scheme %q not registered, target addr %q", scheme, addr)
+	}
+	return builder.Build("http://" + rest)
+}
+
+type staticResolver string
+
+func (s staticResolver) GetAddr() string {
+	return strings.TrimRight(string(s), "/")
+}
+
+func closeResolver(r Resolver) {
+	if c, ok := r.(interface{ Close() }); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,69 @@
+package ehttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// singleflightTransport 合并相同URL的并发GET、HEAD请求，只有第一个请求会发送到下游，其余请求共享它的响应
+// 合并时不区分请求头，只适用于响应与调用方身份无关的幂等查询，第一个请求被取消时共享它的请求也会失败
+type singleflightTransport struct {
+	name  string
+	base  http.RoundTripper
+	group singleflight.Group
+}
+
+// sharedResponse 共享的响应，响应体已经读取，每个调用方拿到独立的副本
+type sharedResponse struct {
+	resp *http.Response
+	body []byte
+}
+
+func newSingleflightTransport(name string, base http.RoundTripper) *singleflightTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &singleflightTransport{name: name, base: base}
+}
+
+// CloseIdleConnections 关闭底层连接池中的空闲连接
+func (t *singleflightTransport) CloseIdleConnections() {
+	closeIdleConnections(t.base)
+}
+
+// RoundTrip ...
+func (t *singleflightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+	key := req.Method + " " + req.URL.String()
+	v, err, shared := t.group.Do(key, func() (interface{}, error) {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &sharedResponse{resp: resp, body: body}, nil
+	})
+	if shared {
+		emetric.ClientCollapsedCounter.Inc(emetric.TypeHTTP, t.name, req.Method+"."+req.URL.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := v.(*sharedResponse)
+	resp := *res.resp
+	resp.Header = res.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(res.body))
+	resp.Request = req
+	return &resp, nil
+}
@@ -0,0 +1,52 @@
+package ehttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleflightTransport(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("X-Call", "1")
+		_, _ = w.Write([]byte("hello " + r.URL.Query().Get("name")))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: newSingleflightTransport("test.singleflight", nil)}
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Get(server.URL + "/hello?name=ego")
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			bodies[i] = string(body)
+			assert.Equal(t, "1", resp.Header.Get("X-Call"))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		assert.Equal(t, "hello ego", body)
+	}
+
+	// POST请求不合并
+	resp, err := client.Post(server.URL+"/hello", "text/plain", strings.NewReader("ego"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
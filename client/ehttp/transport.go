@@ -0,0 +1,111 @@
+package ehttp
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"github.com/gotomicro/ego/core/eidentity"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// targetTransport 按请求的host选择独立的连接池，避免慢下游占满其他下游的连接
+type targetTransport struct {
+	targets  map[string]*http.Transport
+	fallback *http.Transport
+}
+
+// RoundTrip 优先按照host:port匹配，其次按照host匹配，没有配置的地址使用共享的连接池
+func (t *targetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport(req).RoundTrip(req)
+}
+
+func (t *targetTransport) transport(req *http.Request) *http.Transport {
+	if transport, ok := t.targets[strings.ToLower(req.URL.Host)]; ok {
+		return transport
+	}
+	if transport, ok := t.targets[strings.ToLower(req.URL.Hostname())]; ok {
+		return transport
+	}
+	return t.fallback
+}
+
+// CloseIdleConnections 关闭所有连接池中的空闲连接
+func (t *targetTransport) CloseIdleConnections() {
+	for _, transport := range t.targets {
+		transport.CloseIdleConnections()
+	}
+	t.fallback.CloseIdleConnections()
+}
+
+// closeIdleConnections 关闭被包装的RoundTripper中的空闲连接
+func closeIdleConnections(rt http.RoundTripper) {
+	if closer, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func createTransport(config *Config) http.RoundTripper {
+	fallback := newTransport(config, TargetConfig{})
+	if len(config.Targets) == 0 {
+		return fallback
+	}
+	targets := make(map[string]*http.Transport, len(config.Targets))
+	for host, target := range config.Targets {
+		targets[strings.ToLower(host)] = newTransport(config, target)
+	}
+	return &targetTransport{targets: targets, fallback: fallback}
+}
+
+// newTransport 创建连接池，target中零值的字段使用全局配置
+func newTransport(config *Config, target TargetConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		DualStack: true,
+	}
+
+	enableHTTP2 := config.EnableHTTP2
+	if target.EnableHTTP2 != nil {
+		enableHTTP2 = *target.EnableHTTP2
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     enableHTTP2,
+		MaxIdleConns:          orDefault(target.MaxIdleConns, config.MaxIdleConns),
+		IdleConnTimeout:       orDefault(target.IdleConnTimeout, config.IdleConnTimeout),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableKeepAlives:     !config.EnableKeepAlives,
+		MaxIdleConnsPerHost:   orDefault(target.MaxIdleConnsPerHost, config.MaxIdleConnsPerHost),
+		MaxConnsPerHost:       orDefault(target.MaxConnsPerHost, config.MaxConnsPerHost),
+	}
+	if config.EnableIdentity {
+		identity := eidentity.Default()
+		if identity == nil {
+			elog.Panic("identity enabled but eidentity component is not built")
+		}
+		transport.TLSClientConfig = identity.ClientTLSConfig(config.IdentityAllowedIDs...)
+	}
+	// 标准库不支持设置HTTP/2的健康检查，需要使用x/net/http2配置
+	if readIdleTimeout := orDefault(target.HTTP2ReadIdleTimeout, config.HTTP2ReadIdleTimeout); enableHTTP2 && readIdleTimeout > 0 {
+		h2, err := http2.ConfigureTransports(transport)
+		if err != nil {
+			elog.Panic("configure http2 transport fail", elog.FieldErr(err))
+		}
+		h2.ReadIdleTimeout = readIdleTimeout
+		h2.PingTimeout = orDefault(target.HTTP2PingTimeout, config.HTTP2PingTimeout)
+	}
+	return transport
+}
+
+func orDefault[T int | time.Duration](value, def T) T {
+	if value == 0 {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package ehttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func TestCreateTransport(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxConnsPerHost = 50
+	transport, ok := createTransport(config).(*http.Transport)
+	require.True(t, ok)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Equal(t, 50, transport.MaxConnsPerHost)
+	assert.Equal(t, config.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+
+	disable := false
+	config.HTTP2ReadIdleTimeout = 10 * time.Second
+	config.Targets = map[string]TargetConfig{
+		"slow.svc:8080": {MaxConnsPerHost: 2, IdleConnTimeout: time.Second},
+		"legacy.svc":    {MaxIdleConnsPerHost: 1, EnableHTTP2: &disable},
+	}
+	tt, ok := createTransport(config).(*targetTransport)
+	require.True(t, ok)
+
+	slow := tt.transport(&http.Request{URL: &url.URL{Host: "SLOW.svc:8080"}})
+	assert.Equal(t, 2, slow.MaxConnsPerHost)
+	assert.Equal(t, time.Second, slow.IdleConnTimeout)
+	assert.Equal(t, config.MaxIdleConns, slow.MaxIdleConns)
+	// 开启了HTTP/2健康检查
+	assert.NotNil(t, slow.TLSNextProto["h2"])
+
+	legacy := tt.transport(&http.Request{URL: &url.URL{Host: "legacy.svc:9090"}})
+	assert.Equal(t, 1, legacy.MaxIdleConnsPerHost)
+	assert.Equal(t, 50, legacy.MaxConnsPerHost)
+	assert.False(t, legacy.ForceAttemptHTTP2)
+
+	other := tt.transport(&http.Request{URL: &url.URL{Host: "slow.svc:9090"}})
+	assert.Same(t, tt.fallback, other)
+	assert.NotSame(t, slow, other)
+}
+
+func TestTargetTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	config := DefaultConfig()
+	config.Addr = server.URL
+	config.Targets = map[string]TargetConfig{u.Host: {MaxConnsPerHost: 1}}
+	comp := newComponent("test", config, elog.DefaultLogger)
+	_, ok := comp.GetClient().Transport.(*targetTransport)
+	assert.True(t, ok)
+	resp, err := comp.R().Get("/hello")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.String())
+	comp.GetClient().CloseIdleConnections()
+}
@@ -0,0 +1,188 @@
+package eid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// WorkerAllocator 为每个副本分配不冲突的worker id
+type WorkerAllocator interface {
+	// Allocate 分配[0, maxWorkerID]之间的worker id，返回该worker id上次使用到的Unix毫秒时间戳
+	// last返回生成器最近使用的时间戳，实现可以定期持久化，用于重启后的时钟回拨检查
+	Allocate(ctx context.Context, maxWorkerID int64, last func() int64) (workerID int64, lastTimestamp int64, err error)
+	// Lost worker id被回收时关闭
+	Lost() <-chan struct{}
+	// Close 释放worker id
+	Close() error
+}
+
+// staticAllocator 使用固定的worker id
+type staticAllocator struct {
+	workerID int64
+}
+
+func (s staticAllocator) Allocate(_ context.Context, maxWorkerID int64, _ func() int64) (int64, int64, error) {
+	if s.workerID < 0 || s.workerID > maxWorkerID {
+		return 0, 0, fmt.Errorf("eid: worker id %d out of range [0, %d]", s.workerID, maxWorkerID)
+	}
+	return s.workerID, 0, nil
+}
+
+func (s staticAllocator) Lost() <-chan struct{} { return nil }
+
+func (s staticAllocator) Close() error { return nil }
+
+// workerValue worker id在etcd中记录的持有者信息
+type workerValue struct {
+	Hostname string `json:"hostname"`
+	Pid      int    `json:"pid"`
+}
+
+// etcdAllocator 通过租约占用worker id，副本退出或者失联后租约过期，worker id可以被其他副本使用
+// 同时在不带租约的key中记录该worker id使用到的时间戳，新的持有者从该时间戳之后开始生成，避免时钟回拨导致重复
+type etcdAllocator struct {
+	client  *clientv3.Client
+	prefix  string
+	ttl     time.Duration
+	timeout time.Duration
+	logger  *elog.Component
+	owned   bool
+
+	workerID int64
+	leaseID  clientv3.LeaseID
+	lost     chan struct{}
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func (e *etcdAllocator) workerKey(id int64) string {
+	return e.prefix + "workers/" + strconv.FormatInt(id, 10)
+}
+
+func (e *etcdAllocator) timestampKey(id int64) string {
+	return e.prefix + "timestamps/" + strconv.FormatInt(id, 10)
+}
+
+func (e *etcdAllocator) Allocate(ctx context.Context, maxWorkerID int64, last func() int64) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	seconds := int64(e.ttl / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	lease, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return 0, 0, fmt.Errorf("eid etcd grant fail, %w", err)
+	}
+	hostname, _ := os.Hostname()
+	value, _ := json.Marshal(workerValue{Hostname: hostname, Pid: os.Getpid()})
+
+	// 从主机名的hash开始查找，减少多个副本同时启动时的冲突
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(hostname))
+	start := int64(h.Sum32()) % (maxWorkerID + 1)
+	for i := int64(0); i <= maxWorkerID; i++ {
+		id := (start + i) % (maxWorkerID + 1)
+		resp, err := e.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(e.workerKey(id)), "=", 0)).
+			Then(clientv3.OpPut(e.workerKey(id), string(value), clientv3.WithLease(lease.ID)), clientv3.OpGet(e.timestampKey(id))).
+			Commit()
+		if err != nil {
+			_, _ = e.client.Revoke(context.Background(), lease.ID)
+			return 0, 0, fmt.Errorf("eid etcd allocate fail, %w", err)
+		}
+		if !resp.Succeeded {
+			continue
+		}
+		var lastTimestamp int64
+		if kvs := resp.Responses[1].GetResponseRange().Kvs; len(kvs) > 0 {
+			lastTimestamp, _ = strconv.ParseInt(string(kvs[0].Value), 10, 64)
+		}
+		if err := e.keepAlive(lease.ID, id, last); err != nil {
+			_, _ = e.client.Revoke(context.Background(), lease.ID)
+			return 0, 0, err
+		}
+		return id, lastTimestamp, nil
+	}
+	_, _ = e.client.Revoke(context.Background(), lease.ID)
+	return 0, 0, fmt.Errorf("eid: no available worker id in [0, %d]", maxWorkerID)
+}
+
+// keepAlive 续期租约，并定期记录生成器使用到的时间戳
+func (e *etcdAllocator) keepAlive(leaseID clientv3.LeaseID, workerID int64, last func() int64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := e.client.KeepAlive(ctx, leaseID)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("eid etcd keepalive fail, %w", err)
+	}
+	e.workerID, e.leaseID, e.cancel = workerID, leaseID, cancel
+	e.lost = make(chan struct{})
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-ch:
+				if !ok {
+					if ctx.Err() == nil {
+						e.logger.Error("eid worker id lost", elog.Int64("workerID", workerID))
+						close(e.lost)
+					}
+					return
+				}
+			case <-ticker.C:
+				e.saveTimestamp(ctx, last())
+			}
+		}
+	}()
+	return nil
+}
+
+// saveTimestamp 记录生成器使用到的时间戳，还没有生成过ID时不覆盖之前的记录
+func (e *etcdAllocator) saveTimestamp(ctx context.Context, timestamp int64) {
+	if timestamp <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	if _, err := e.client.Put(ctx, e.timestampKey(e.workerID), strconv.FormatInt(timestamp, 10)); err != nil {
+		e.logger.Warn("eid save timestamp fail", elog.FieldErr(err))
+	}
+}
+
+func (e *etcdAllocator) Lost() <-chan struct{} {
+	return e.lost
+}
+
+// Close 停止续期并释放worker id
+func (e *etcdAllocator) Close() error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	e.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	_, err := e.client.Revoke(ctx, e.leaseID)
+	if e.owned {
+		if closeErr := e.client.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package eid
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "client.eid"
+
+// ErrNotSnowflake 只有snowflake可以生成数字ID
+var ErrNotSnowflake = errors.New("eid: int64 id is only supported by snowflake generator")
+
+// Component ID生成器组件
+type Component struct {
+	name      string
+	config    *Config
+	logger    *elog.Component
+	snowflake *snowflake
+	allocator WorkerAllocator
+	ulid      *ulidGenerator
+}
+
+// NextID 生成snowflake数字ID
+func (c *Component) NextID() (int64, error) {
+	if c.snowflake == nil {
+		return 0, ErrNotSnowflake
+	}
+	return c.snowflake.next()
+}
+
+// NextString 按照配置的生成算法生成字符串ID，snowflake为十进制字符串
+func (c *Component) NextString() (string, error) {
+	switch c.config.Generator {
+	case GeneratorULID:
+		return c.ulid.next(time.Now()), nil
+	case GeneratorKSUID:
+		return NewKSUID(), nil
+	default:
+		id, err := c.NextID()
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(id, 10), nil
+	}
+}
+
+// WorkerID 返回分配到的worker id，非snowflake时返回-1
+func (c *Component) WorkerID() int64 {
+	if c.snowflake == nil {
+		return -1
+	}
+	return c.snowflake.worker()
+}
+
+// Decompose 解析snowflake ID中的生成时间、worker id和序列号
+func (c *Component) Decompose(id int64) (time.Time, int64, int64, error) {
+	if c.snowflake == nil {
+		return time.Time{}, 0, 0, ErrNotSnowflake
+	}
+	t, workerID, sequence := c.snowflake.decompose(id)
+	return t, workerID, sequence, nil
+}
+
+// RequestIDGenerator 返回用于中间件生成请求ID的函数，例如egin.WithRequestIDGenerator
+// 生成失败时降级为ULID，保证总能返回请求ID
+func (c *Component) RequestIDGenerator() func() string {
+	return func() string {
+		id, err := c.NextString()
+		if err != nil {
+			c.logger.Warn("eid generate request id fail, fallback to ulid", elog.FieldErr(err))
+			return NewULID()
+		}
+		return id
+	}
+}
+
+// Close 释放worker id
+func (c *Component) Close() error {
+	if c.allocator == nil {
+		return nil
+	}
+	return c.allocator.Close()
+}
@@ -0,0 +1,184 @@
+package eid
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/ehooks"
+)
+
+// fakeAllocator 记录分配和释放，可以模拟worker id丢失
+type fakeAllocator struct {
+	workerID      int64
+	lastTimestamp int64
+	lost          chan struct{}
+	closed        bool
+}
+
+func (f *fakeAllocator) Allocate(_ context.Context, maxWorkerID int64, _ func() int64) (int64, int64, error) {
+	if f.workerID > maxWorkerID {
+		return 0, 0, errors.New("out of range")
+	}
+	return f.workerID, f.lastTimestamp, nil
+}
+
+func (f *fakeAllocator) Lost() <-chan struct{} { return f.lost }
+
+func (f *fakeAllocator) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestSnowflake(t *testing.T) {
+	c := DefaultContainer()
+	c.config.WorkerID = 7
+	comp := c.Build()
+	defer comp.Close()
+	assert.Equal(t, int64(7), comp.WorkerID())
+
+	var (
+		mu  sync.Mutex
+		ids = make(map[int64]struct{})
+		wg  sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				id, err := comp.NextID()
+				assert.NoError(t, err)
+				mu.Lock()
+				ids[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, ids, 16000)
+
+	id, err := comp.NextID()
+	require.NoError(t, err)
+	ts, workerID, _, err := comp.Decompose(id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), workerID)
+	assert.WithinDuration(t, time.Now(), ts, time.Second)
+
+	s, err := comp.NextString()
+	require.NoError(t, err)
+	assert.NotEmpty(t, s)
+}
+
+func TestSnowflakeClockBackward(t *testing.T) {
+	config := DefaultConfig()
+	now := int64(1700000000000)
+	sf := newSnowflake(config, 1, 0, nil)
+	sf.now = func() int64 { return now }
+	sf.sleep = func(d time.Duration) { now += int64(d / time.Millisecond) }
+
+	first, err := sf.next()
+	require.NoError(t, err)
+
+	// 小范围回拨等待时钟追上
+	now -= 5
+	second, err := sf.next()
+	require.NoError(t, err)
+	assert.Greater(t, second, first)
+
+	// 超过阈值返回错误
+	now -= 100
+	_, err = sf.next()
+	assert.ErrorIs(t, err, ErrClockBackward)
+}
+
+func TestSnowflakeSequenceOverflow(t *testing.T) {
+	config := DefaultConfig()
+	config.SequenceBits = 2
+	now := int64(1700000000000)
+	sf := newSnowflake(config, 1, 0, nil)
+	sf.now = func() int64 { return now }
+	sf.sleep = func(time.Duration) { now++ }
+
+	var last int64
+	for i := 0; i < 10; i++ {
+		id, err := sf.next()
+		require.NoError(t, err)
+		assert.Greater(t, id, last)
+		last = id
+	}
+	// 每毫秒只有4个序列号
+	assert.Equal(t, int64(1700000000002), now)
+}
+
+func TestWorkerAllocator(t *testing.T) {
+	allocator := &fakeAllocator{workerID: 3, lastTimestamp: time.Now().Add(5 * time.Millisecond).UnixMilli(), lost: make(chan struct{})}
+	comp := DefaultContainer().Build(WithWorkerAllocator(allocator))
+	assert.Equal(t, int64(3), comp.WorkerID())
+
+	// 从上次使用的时间戳之后开始生成
+	id, err := comp.NextID()
+	require.NoError(t, err)
+	ts, _, _, _ := comp.Decompose(id)
+	assert.GreaterOrEqual(t, ts.UnixMilli(), allocator.lastTimestamp)
+
+	close(allocator.lost)
+	_, err = comp.NextID()
+	assert.ErrorIs(t, err, ErrWorkerLost)
+	// 生成请求ID时降级为ULID
+	assert.Len(t, comp.RequestIDGenerator()(), 26)
+
+	require.NoError(t, ehooks.Do(ehooks.StageAfterStop))
+	assert.True(t, allocator.closed)
+}
+
+func TestStaticAllocatorOutOfRange(t *testing.T) {
+	c := DefaultContainer()
+	c.config.WorkerID = 1024
+	assert.Panics(t, func() { c.Build() })
+}
+
+func TestULID(t *testing.T) {
+	g := &ulidGenerator{}
+	now := time.Now()
+	ids := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, g.next(now))
+	}
+	// 同一毫秒内单调递增
+	assert.True(t, sort.StringsAreSorted(ids))
+	// 时钟回拨时仍然递增
+	assert.Greater(t, g.next(now.Add(-time.Second)), ids[len(ids)-1])
+
+	id := NewULID()
+	assert.Len(t, id, 26)
+	for _, r := range id {
+		assert.True(t, strings.ContainsRune(crockfordAlphabet, r))
+	}
+	assert.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID([16]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}))
+
+	comp := DefaultContainer()
+	comp.config.Generator = GeneratorULID
+	s, err := comp.Build().NextString()
+	require.NoError(t, err)
+	assert.Len(t, s, 26)
+	_, err = comp.Build().NextID()
+	assert.ErrorIs(t, err, ErrNotSnowflake)
+}
+
+func TestKSUID(t *testing.T) {
+	now := time.Now()
+	a, b := newKSUID(now.Add(-time.Second)), newKSUID(now)
+	assert.Len(t, a, 27)
+	assert.Less(t, a, b)
+	assert.Equal(t, "000000000000000000000000000", encodeBase62(make([]byte, 20), 27))
+	assert.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", encodeBase62([]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}, 27))
+}
@@ -0,0 +1,53 @@
+package eid
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// GeneratorSnowflake 64位可排序的数字ID，需要为每个副本分配不同的worker id
+	GeneratorSnowflake = "snowflake"
+	// GeneratorULID 26位Crockford base32编码的ULID，同一毫秒内单调递增
+	GeneratorULID = "ulid"
+	// GeneratorKSUID 27位base62编码的KSUID，按秒排序
+	GeneratorKSUID = "ksuid"
+
+	// AllocatorStatic 使用配置中的WorkerID
+	AllocatorStatic = "static"
+	// AllocatorEtcd 通过etcd租约为每个副本分配不冲突的worker id
+	AllocatorEtcd = "etcd"
+)
+
+// Config ID生成器配置
+type Config struct {
+	Generator        string        // 生成算法，snowflake、ulid或者ksuid，默认snowflake
+	Epoch            int64         // snowflake的起始时间，Unix毫秒时间戳，默认2020-01-01，设置后不能修改
+	WorkerIDBits     int           // snowflake的worker id位数，默认10
+	SequenceBits     int           // snowflake的序列号位数，默认12
+	MaxClockBackward time.Duration // 允许等待的最大时钟回拨，超过后返回ErrClockBackward，默认10ms
+	WorkerAllocator  string        // worker id分配方式，static或者etcd，默认static
+	WorkerID         int64         // static方式使用的worker id
+	EtcdEndpoints    []string      // etcd地址
+	EtcdUsername     string        // etcd用户名
+	EtcdPassword     string        // etcd密码
+	EtcdPrefix       string        // worker id在etcd中的key前缀，默认/ego/eid/，会拼接应用名称
+	LeaseTTL         time.Duration // worker id租约的过期时间，默认10s
+	Timeout          time.Duration // 访问etcd的超时，默认3s
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Generator:        GeneratorSnowflake,
+		Epoch:            1577836800000,
+		WorkerIDBits:     10,
+		SequenceBits:     12,
+		MaxClockBackward: xtime.Duration("10ms"),
+		WorkerAllocator:  AllocatorStatic,
+		EtcdPrefix:       "/ego/eid/",
+		LeaseTTL:         xtime.Duration("10s"),
+		Timeout:          xtime.Duration("3s"),
+	}
+}
@@ -0,0 +1,136 @@
+package eid
+
+import (
+	"context"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config     *Config
+	name       string
+	logger     *elog.Component
+	allocator  WorkerAllocator
+	etcdClient *clientv3.Client
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithEtcdClient 使用已有的etcd客户端分配worker id，组件停止时不会关闭该客户端
+func WithEtcdClient(client *clientv3.Client) Option {
+	return func(c *Container) {
+		c.etcdClient = client
+	}
+}
+
+// WithWorkerAllocator 使用自定义的worker id分配方式
+func WithWorkerAllocator(allocator WorkerAllocator) Option {
+	return func(c *Container) {
+		c.allocator = allocator
+	}
+}
+
+// Build 创建ID生成器，snowflake会在启动时分配worker id，并在ego停止后释放
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	comp := &Component{
+		name:   c.name,
+		config: c.config,
+		logger: c.logger,
+		ulid:   &ulidGenerator{},
+	}
+	if c.config.Generator != GeneratorSnowflake {
+		return comp
+	}
+	if c.config.WorkerIDBits+c.config.SequenceBits > 22 {
+		c.logger.Panic("workerIDBits + sequenceBits must not exceed 22")
+	}
+
+	if c.allocator == nil {
+		c.allocator = c.newAllocator()
+	}
+	// 先创建生成器，分配器持久化时间戳时读取生成器的状态
+	sf := newSnowflake(c.config, 0, 0, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	workerID, lastTimestamp, err := c.allocator.Allocate(ctx, 1<<uint(c.config.WorkerIDBits)-1, sf.last)
+	if err != nil {
+		c.logger.Panic("allocate worker id fail", elog.FieldErr(err))
+	}
+	if backward := time.Duration(lastTimestamp-time.Now().UnixMilli()) * time.Millisecond; backward > 0 {
+		c.logger.Warn("clock is behind last timestamp of worker id", elog.Int64("workerID", workerID), elog.String("backward", backward.String()))
+	}
+	sf.assign(workerID, lastTimestamp, c.allocator.Lost())
+	comp.snowflake = sf
+	comp.allocator = c.allocator
+	c.logger.Info("allocate worker id", elog.Int64("workerID", workerID))
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) newAllocator() WorkerAllocator {
+	switch c.config.WorkerAllocator {
+	case AllocatorEtcd:
+		client, owned := c.etcdClient, false
+		if client == nil {
+			var err error
+			client, err = clientv3.New(clientv3.Config{
+				Endpoints:   c.config.EtcdEndpoints,
+				Username:    c.config.EtcdUsername,
+				Password:    c.config.EtcdPassword,
+				DialTimeout: c.config.Timeout,
+			})
+			if err != nil {
+				c.logger.Panic("new etcd client fail", elog.FieldErr(err))
+			}
+			owned = true
+		}
+		return &etcdAllocator{
+			client:  client,
+			prefix:  c.config.EtcdPrefix + eapp.Name() + "/",
+			ttl:     c.config.LeaseTTL,
+			timeout: c.config.Timeout,
+			logger:  c.logger,
+			owned:   owned,
+		}
+	case AllocatorStatic, "":
+		return staticAllocator{workerID: c.config.WorkerID}
+	default:
+		c.logger.Panic("unknown worker allocator", elog.String("allocator", c.config.WorkerAllocator))
+		return nil
+	}
+}
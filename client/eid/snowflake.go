@@ -0,0 +1,117 @@
+package eid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClockBackward 时钟回拨超过MaxClockBackward
+	ErrClockBackward = errors.New("eid: clock moved backwards")
+	// ErrWorkerLost worker id的租约已经失效，继续生成可能和其他副本冲突
+	ErrWorkerLost = errors.New("eid: worker id lost")
+)
+
+// snowflake 1位符号位 + 毫秒时间戳 + worker id + 序列号
+type snowflake struct {
+	mu            sync.Mutex
+	epoch         int64
+	workerID      int64
+	workerBits    uint
+	sequenceBits  uint
+	maxSequence   int64
+	maxTimestamp  int64
+	maxBackward   time.Duration
+	lastTimestamp int64 // 最近一次生成ID的Unix毫秒时间戳
+	sequence      int64
+	lost          <-chan struct{}
+	now           func() int64
+	sleep         func(time.Duration)
+}
+
+func newSnowflake(config *Config, workerID int64, lastTimestamp int64, lost <-chan struct{}) *snowflake {
+	return &snowflake{
+		epoch:         config.Epoch,
+		workerID:      workerID,
+		workerBits:    uint(config.WorkerIDBits),
+		sequenceBits:  uint(config.SequenceBits),
+		maxSequence:   1<<uint(config.SequenceBits) - 1,
+		maxTimestamp:  1<<(63-uint(config.WorkerIDBits)-uint(config.SequenceBits)) - 1,
+		maxBackward:   config.MaxClockBackward,
+		lastTimestamp: lastTimestamp,
+		lost:          lost,
+		now:           func() int64 { return time.Now().UnixMilli() },
+		sleep:         time.Sleep,
+	}
+}
+
+func (s *snowflake) next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.lost:
+		return 0, ErrWorkerLost
+	default:
+	}
+
+	now := s.now()
+	if now < s.lastTimestamp {
+		// 小范围的时钟回拨等待时钟追上，超过阈值直接报错，避免产生重复ID
+		backward := time.Duration(s.lastTimestamp-now) * time.Millisecond
+		if backward > s.maxBackward {
+			return 0, fmt.Errorf("%w, backward: %s", ErrClockBackward, backward)
+		}
+		for now < s.lastTimestamp {
+			s.sleep(time.Duration(s.lastTimestamp-now) * time.Millisecond)
+			now = s.now()
+		}
+	}
+	if now == s.lastTimestamp {
+		s.sequence = (s.sequence + 1) & s.maxSequence
+		// 当前毫秒的序列号用完，等待下一毫秒
+		for s.sequence == 0 && now <= s.lastTimestamp {
+			s.sleep(time.Millisecond / 10)
+			now = s.now()
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastTimestamp = now
+
+	timestamp := now - s.epoch
+	if timestamp < 0 || timestamp > s.maxTimestamp {
+		return 0, fmt.Errorf("eid: timestamp %d out of range, check epoch", timestamp)
+	}
+	return timestamp<<(s.workerBits+s.sequenceBits) | s.workerID<<s.sequenceBits | s.sequence, nil
+}
+
+// assign 设置分配到的worker id和该worker id上次使用到的时间戳
+func (s *snowflake) assign(workerID int64, lastTimestamp int64, lost <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workerID = workerID
+	s.lastTimestamp = lastTimestamp
+	s.lost = lost
+}
+
+// last 返回最近一次生成ID的时间戳，用于持久化时钟位置
+func (s *snowflake) last() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastTimestamp
+}
+
+// decompose 解析ID中的时间、worker id和序列号
+func (s *snowflake) decompose(id int64) (time.Time, int64, int64) {
+	timestamp := id>>(s.workerBits+s.sequenceBits) + s.epoch
+	workerID := id >> s.sequenceBits & (1<<s.workerBits - 1)
+	return time.UnixMilli(timestamp), workerID, id & s.maxSequence
+}
+
+func (s *snowflake) worker() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.workerID
+}
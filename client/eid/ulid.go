@@ -0,0 +1,95 @@
+package eid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	base62Alphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// ksuidEpoch KSUID的起始时间，2014-05-13
+	ksuidEpoch = 1400000000
+)
+
+// ulidGenerator 同一毫秒内递增随机部分，保证同一进程生成的ULID单调递增
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+var defaultULID = &ulidGenerator{}
+
+// NewULID 生成ULID
+func NewULID() string {
+	return defaultULID.next(time.Now())
+}
+
+func (g *ulidGenerator) next(now time.Time) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := uint64(now.UnixMilli())
+	if ms > g.lastMs {
+		_, _ = rand.Read(g.entropy[:])
+		g.lastMs = ms
+	} else if !incr(g.entropy[:]) {
+		// 随机部分溢出时借用下一毫秒，时钟回拨时沿用上次的时间戳，保证单调递增
+		_, _ = rand.Read(g.entropy[:])
+		g.lastMs++
+	}
+	ms = g.lastMs
+	var id [16]byte
+	id[0], id[1], id[2], id[3], id[4], id[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	copy(id[6:], g.entropy[:])
+	return encodeULID(id)
+}
+
+// incr 大端序加1，溢出时返回false
+func incr(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID 128位数据按5位一组编码为26个字符，最高位补2个0
+func encodeULID(id [16]byte) string {
+	hi, lo := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// NewKSUID 生成KSUID，4字节秒级时间戳加16字节随机数，base62编码为27个字符
+func NewKSUID() string {
+	return newKSUID(time.Now())
+}
+
+func newKSUID(now time.Time) string {
+	var id [20]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(now.Unix()-ksuidEpoch))
+	_, _ = rand.Read(id[4:])
+	return encodeBase62(id[:], 27)
+}
+
+func encodeBase62(b []byte, length int) string {
+	n := new(big.Int).SetBytes(b)
+	base, mod := big.NewInt(62), new(big.Int)
+	out := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		out[i] = base62Alphabet[mod.Int64()]
+	}
+	return string(out)
+}
@@ -0,0 +1,189 @@
+package einmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// PackageName 包名
+const PackageName = "client.einmemory"
+
+var defaultStore = NewStore()
+
+// Store 保存服务节点，同一个进程中的多个服务通过同一个Store互相发现
+type Store struct {
+	mu       sync.RWMutex
+	services map[serviceKey]map[string]server.ServiceInfo // 节点按照地址去重
+	watchers map[serviceKey]map[*watcher]struct{}
+}
+
+// serviceKey 协议和服务名称
+type serviceKey struct {
+	scheme string
+	name   string
+}
+
+type watcher struct {
+	ch chan eregistry.Endpoints
+}
+
+// NewStore 创建独立的存储
+func NewStore() *Store {
+	return &Store{
+		services: make(map[serviceKey]map[string]server.ServiceInfo),
+		watchers: make(map[serviceKey]map[*watcher]struct{}),
+	}
+}
+
+func (s *Store) put(info *server.ServiceInfo) {
+	key := serviceKey{scheme: info.Scheme, name: info.Name}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nodes, ok := s.services[key]
+	if !ok {
+		nodes = make(map[string]server.ServiceInfo)
+		s.services[key] = nodes
+	}
+	nodes[info.Address] = *info
+	s.notify(key)
+}
+
+func (s *Store) delete(info *server.ServiceInfo) {
+	key := serviceKey{scheme: info.Scheme, name: info.Name}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.services[key][info.Address]; !ok {
+		return
+	}
+	delete(s.services[key], info.Address)
+	s.notify(key)
+}
+
+func (s *Store) list(key serviceKey) []*server.ServiceInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]*server.ServiceInfo, 0, len(s.services[key]))
+	for _, info := range s.services[key] {
+		info := info
+		res = append(res, &info)
+	}
+	return res
+}
+
+// endpoints 需要持有锁
+func (s *Store) endpoints(key serviceKey) eregistry.Endpoints {
+	res := eregistry.Endpoints{
+		Nodes:           make(map[string]server.ServiceInfo, len(s.services[key])),
+		RouteConfigs:    make(map[string]eregistry.RouteConfig),
+		ConsumerConfigs: make(map[string]eregistry.ConsumerConfig),
+		ProviderConfigs: make(map[string]eregistry.ProviderConfig),
+	}
+	for addr, info := range s.services[key] {
+		res.Nodes[addr] = info
+	}
+	return res
+}
+
+// notify 通知watcher最新的节点，watcher没有及时读取时只保留最新的节点，需要持有写锁
+func (s *Store) notify(key serviceKey) {
+	for w := range s.watchers[key] {
+		w.send(s.endpoints(key))
+	}
+}
+
+func (w *watcher) send(endpoints eregistry.Endpoints) {
+	for {
+		select {
+		case w.ch <- endpoints:
+			return
+		default:
+		}
+		select {
+		case <-w.ch:
+		default:
+		}
+	}
+}
+
+func (s *Store) watch(ctx context.Context, key serviceKey) chan eregistry.Endpoints {
+	w := &watcher{ch: make(chan eregistry.Endpoints, 1)}
+	s.mu.Lock()
+	if s.watchers[key] == nil {
+		s.watchers[key] = make(map[*watcher]struct{})
+	}
+	s.watchers[key][w] = struct{}{}
+	w.send(s.endpoints(key))
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		delete(s.watchers[key], w)
+		s.mu.Unlock()
+	}()
+	return w.ch
+}
+
+// Component 内存注册中心，用于本地开发和测试，不依赖etcd、consul等外部服务
+type Component struct {
+	name       string
+	config     *Config
+	logger     *elog.Component
+	store      *Store
+	mu         sync.Mutex
+	registered map[string]*server.ServiceInfo // 当前组件注册的节点，关闭时注销
+}
+
+// RegisterService 注册服务节点
+func (c *Component) RegisterService(_ context.Context, info *server.ServiceInfo) error {
+	c.store.put(info)
+	c.mu.Lock()
+	c.registered[info.Scheme+"://"+info.Name+"/"+info.Address] = info
+	c.mu.Unlock()
+	c.logger.Info("register service", elog.FieldName(info.Name), elog.FieldAddr(info.Address))
+	return nil
+}
+
+// UnregisterService 注销服务节点
+func (c *Component) UnregisterService(_ context.Context, info *server.ServiceInfo) error {
+	c.store.delete(info)
+	c.mu.Lock()
+	delete(c.registered, info.Scheme+"://"+info.Name+"/"+info.Address)
+	c.mu.Unlock()
+	return nil
+}
+
+// ListServices 返回服务的所有节点
+func (c *Component) ListServices(_ context.Context, target eregistry.Target) ([]*server.ServiceInfo, error) {
+	return c.store.list(targetKey(target)), nil
+}
+
+// WatchServices 监听服务节点的变化，先返回当前的节点，ctx取消后停止监听
+func (c *Component) WatchServices(ctx context.Context, target eregistry.Target) (chan eregistry.Endpoints, error) {
+	return c.store.watch(ctx, targetKey(target)), nil
+}
+
+// SyncServices 节点变化时已经实时通知，不需要同步
+func (c *Component) SyncServices(context.Context, eregistry.SyncServicesOptions) error {
+	return nil
+}
+
+// Close 注销当前组件注册的节点
+func (c *Component) Close() error {
+	c.mu.Lock()
+	registered := c.registered
+	c.registered = make(map[string]*server.ServiceInfo)
+	c.mu.Unlock()
+	for _, info := range registered {
+		c.store.delete(info)
+	}
+	return nil
+}
+
+func targetKey(target eregistry.Target) serviceKey {
+	return serviceKey{scheme: target.Protocol, name: target.Endpoint}
+}
@@ -0,0 +1,89 @@
+package einmemory
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/gotomicro/ego/client/egrpc"
+	hresolver "github.com/gotomicro/ego/client/ehttp/resolver"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/internal/test/helloworld"
+	"github.com/gotomicro/ego/server"
+)
+
+type greeter struct {
+	helloworld.UnimplementedGreeterServer
+}
+
+func (greeter) SayHello(_ context.Context, req *helloworld.HelloRequest) (*helloworld.HelloResponse, error) {
+	return &helloworld.HelloResponse{Message: "Hello " + req.Name}, nil
+}
+
+func TestWatchServices(t *testing.T) {
+	comp := DefaultContainer().Build(WithStore(NewStore()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	target := eregistry.Target{Protocol: eregistry.ProtocolGRPC, Endpoint: "svc-user"}
+	ch, err := comp.WatchServices(ctx, target)
+	assert.NoError(t, err)
+	assert.Empty(t, (<-ch).Nodes)
+
+	info := &server.ServiceInfo{Name: "svc-user", Scheme: "grpc", Address: "127.0.0.1:9002"}
+	assert.NoError(t, comp.RegisterService(ctx, info))
+	assert.Contains(t, (<-ch).Nodes, "127.0.0.1:9002")
+	// 其他协议的同名服务不影响
+	assert.NoError(t, comp.RegisterService(ctx, &server.ServiceInfo{Name: "svc-user", Scheme: "http", Address: "127.0.0.1:9001"}))
+	list, err := comp.ListServices(ctx, target)
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	assert.NoError(t, comp.Close())
+	assert.Empty(t, (<-ch).Nodes)
+}
+
+func TestLoadEndpoints(t *testing.T) {
+	econf.Reset()
+	defer econf.Reset()
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(`
+[registry]
+scheme = "inmemory-test"
+[[registry.endpoints]]
+name = "svc-order"
+scheme = "http"
+address = "127.0.0.1:9003"
+`), toml.Unmarshal))
+	comp := Load("registry").Build(WithStore(NewStore()))
+	defer comp.Close()
+
+	builder := hresolver.Get("inmemory-test")
+	assert.NotNil(t, builder)
+	r, err := builder.Build("inmemory-test:///svc-order")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return r.GetAddr() == "http://127.0.0.1:9003" }, time.Second, 10*time.Millisecond)
+}
+
+func TestGRPCDiscovery(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	helloworld.RegisterGreeterServer(srv, greeter{})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	comp := DefaultContainer().Build()
+	defer comp.Close()
+	assert.NoError(t, comp.RegisterService(context.Background(), &server.ServiceInfo{Name: "svc-hello", Scheme: "grpc", Address: lis.Addr().String()}))
+
+	client := egrpc.DefaultContainer().Build(egrpc.WithAddr("inmemory:///svc-hello"))
+	resp, err := helloworld.NewGreeterClient(client.ClientConn).SayHello(context.Background(), &helloworld.HelloRequest{Name: "Ego"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello Ego", resp.Message)
+}
@@ -0,0 +1,25 @@
+package einmemory
+
+// Config 内存注册中心配置
+type Config struct {
+	Scheme    string           // 服务发现的scheme，客户端地址为 inmemory:///svc-user，默认inmemory
+	Endpoints []EndpointConfig // 静态节点，例如本地启动的其他服务，组件创建时注册，关闭时注销
+}
+
+// EndpointConfig 静态节点配置
+type EndpointConfig struct {
+	Name     string            // 服务名称，与客户端地址中的服务名称一致
+	Scheme   string            // 协议，grpc或者http，默认grpc
+	Address  string            // 节点地址，例如 127.0.0.1:9002
+	Weight   float64           // 权重，默认100
+	Metadata map[string]string // 节点元数据
+	Region   string            // 区域
+	Zone     string            // 可用区
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Scheme: "inmemory",
+	}
+}
@@ -0,0 +1,114 @@
+package einmemory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotomicro/ego/client/egrpc/resolver"
+	hresolver "github.com/gotomicro/ego/client/ehttp/resolver"
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	store  *Store
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		store:  defaultStore,
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithEndpoint 添加静态节点
+func WithEndpoint(endpoint EndpointConfig) Option {
+	return func(c *Container) {
+		c.config.Endpoints = append(c.config.Endpoints, endpoint)
+	}
+}
+
+// WithStore 使用独立的存储，默认同一个进程中的组件共享存储，测试中需要隔离时使用 NewStore
+func WithStore(store *Store) Option {
+	return func(c *Container) {
+		c.store = store
+	}
+}
+
+// Build 创建内存注册中心，并注册gRPC、HTTP客户端的resolver
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	comp := &Component{
+		name:       c.name,
+		config:     c.config,
+		logger:     c.logger,
+		store:      c.store,
+		registered: make(map[string]*server.ServiceInfo),
+	}
+	for _, endpoint := range c.config.Endpoints {
+		info, err := endpoint.serviceInfo()
+		if err != nil {
+			c.logger.Panic("invalid endpoint", elog.FieldErr(err))
+		}
+		if err := comp.RegisterService(context.Background(), info); err != nil {
+			c.logger.Panic("register endpoint fail", elog.FieldErr(err))
+		}
+	}
+	resolver.Register(c.config.Scheme, comp)
+	hresolver.Register(c.config.Scheme, comp)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (e EndpointConfig) serviceInfo() (*server.ServiceInfo, error) {
+	if e.Name == "" || e.Address == "" {
+		return nil, fmt.Errorf("endpoint name and address are required, name: %q, address: %q", e.Name, e.Address)
+	}
+	info := &server.ServiceInfo{
+		Name:     e.Name,
+		Scheme:   e.Scheme,
+		Address:  e.Address,
+		Weight:   e.Weight,
+		Enable:   true,
+		Healthy:  true,
+		Metadata: e.Metadata,
+		Region:   e.Region,
+		Zone:     e.Zone,
+		Kind:     constant.ServiceProvider,
+	}
+	if info.Scheme == "" {
+		info.Scheme = eregistry.ProtocolGRPC
+	}
+	if info.Weight == 0 {
+		info.Weight = 100
+	}
+	return info, nil
+}
@@ -0,0 +1,28 @@
+package elock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotObtained 锁被其他持有者占用
+	ErrNotObtained = errors.New("elock: lock not obtained")
+	// ErrNotHeld 锁已经过期或者被其他持有者获取
+	ErrNotHeld = errors.New("elock: lock not held")
+)
+
+// Backend 锁的存储后端
+type Backend interface {
+	// Acquire 获取锁，成功时返回单调递增的fencing token，锁被占用时返回ErrNotObtained
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, error)
+	// Refresh 延长锁的过期时间，锁不属于owner时返回ErrNotHeld
+	Refresh(ctx context.Context, key, owner string, ttl time.Duration) error
+	// Release 释放锁，锁不属于owner时返回ErrNotHeld
+	Release(ctx context.Context, key, owner string) error
+	// Ping 检查后端是否可用
+	Ping(ctx context.Context) error
+	// Close 关闭后端连接
+	Close() error
+}
@@ -0,0 +1,72 @@
+package elock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "client.elock"
+
+// Component 分布式锁组件
+type Component struct {
+	name    string
+	config  *Config
+	logger  *elog.Component
+	backend Backend
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, backend Backend) *Component {
+	return &Component{
+		name:    name,
+		config:  config,
+		logger:  logger,
+		backend: backend,
+	}
+}
+
+// Ping 检查后端是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	return c.backend.Ping(ctx)
+}
+
+// Close 关闭组件创建的后端连接
+func (c *Component) Close() error {
+	return c.backend.Close()
+}
+
+// metric 记录锁操作的结果，contended表示锁被其他持有者占用，beg为零值时不记录耗时
+func (c *Component) metric(action string, key string, code string, beg time.Time) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.ClientHandleCounter.Inc(emetric.TypeLock, c.name, action, key, code)
+	if !beg.IsZero() {
+		emetric.ClientHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypeLock, c.name, action, key)
+	}
+}
+
+// observeWait 记录Lock等待锁的耗时
+func (c *Component) observeWait(key string, beg time.Time) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.ClientHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypeLock, c.name, "wait", key)
+}
+
+func resultCode(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrNotObtained):
+		return "contended"
+	case errors.Is(err, ErrNotHeld):
+		return "notheld"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,182 @@
+package elock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ecron"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+var _ ecron.Lock = (*cronLock)(nil)
+
+// newTestComponent 使用3个独立的miniredis节点
+func newTestComponent(t *testing.T, config *Config) (*Component, []*miniredis.Miniredis) {
+	servers := make([]*miniredis.Miniredis, 0, 3)
+	clients := make([]redis.UniversalClient, 0, 3)
+	for i := 0; i < 3; i++ {
+		s := miniredis.RunT(t)
+		servers = append(servers, s)
+		clients = append(clients, redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	}
+	comp := newComponent("test", config, elog.EgoLogger, NewRedisBackend(clients...))
+	return comp, servers
+}
+
+func TestTryLock(t *testing.T) {
+	comp, _ := newTestComponent(t, DefaultConfig())
+	ctx := context.Background()
+
+	a := comp.NewMutex("order")
+	b := comp.NewMutex("order")
+	require.NoError(t, a.TryLock(ctx))
+	assert.ErrorIs(t, b.TryLock(ctx), ErrNotObtained)
+	// 其他持有者不能释放锁
+	assert.ErrorIs(t, b.Unlock(ctx), ErrNotHeld)
+
+	token := a.Token()
+	require.NoError(t, a.Unlock(ctx))
+	require.NoError(t, b.TryLock(ctx))
+	// fencing token单调递增
+	assert.Greater(t, b.Token(), token)
+	require.NoError(t, b.Unlock(ctx))
+}
+
+func TestRedlockQuorum(t *testing.T) {
+	comp, servers := newTestComponent(t, DefaultConfig())
+	ctx := context.Background()
+
+	// 一个节点不可用时仍然可以获取锁
+	servers[0].Close()
+	m := comp.NewMutex("quorum", WithAutoRenew(false))
+	require.NoError(t, m.TryLock(ctx))
+	require.NoError(t, m.Unlock(ctx))
+
+	// 超过半数节点不可用时返回错误
+	servers[1].Close()
+	err := m.TryLock(ctx)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotObtained))
+}
+
+func TestLockWait(t *testing.T) {
+	config := DefaultConfig()
+	config.RetryInterval = 10 * time.Millisecond
+	comp, _ := newTestComponent(t, config)
+
+	a := comp.NewMutex("wait")
+	require.NoError(t, a.TryLock(context.Background()))
+
+	// 等待超时
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	b := comp.NewMutex("wait")
+	err := b.Lock(ctx)
+	assert.ErrorIs(t, err, ErrNotObtained)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 锁释放后获取成功
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = a.Unlock(context.Background())
+	}()
+	require.NoError(t, b.Lock(context.Background()))
+	require.NoError(t, b.Unlock(context.Background()))
+}
+
+func TestAutoRenew(t *testing.T) {
+	config := DefaultConfig()
+	config.TTL = 300 * time.Millisecond
+	comp, servers := newTestComponent(t, config)
+
+	m := comp.NewMutex("renew")
+	require.NoError(t, m.TryLock(context.Background()))
+	// miniredis需要手动推进时间，续期后key的过期时间被重置
+	for i := 0; i < 5; i++ {
+		time.Sleep(120 * time.Millisecond)
+		for _, s := range servers {
+			s.FastForward(120 * time.Millisecond)
+		}
+	}
+	for _, s := range servers {
+		assert.True(t, s.Exists("{elock:renew}"))
+	}
+	select {
+	case <-m.Lost():
+		t.Fatal("lock should not be lost")
+	default:
+	}
+
+	// 锁被删除后续期失败，通知锁丢失
+	for _, s := range servers {
+		s.Del("{elock:renew}")
+	}
+	select {
+	case <-m.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lock should be lost")
+	}
+	assert.ErrorIs(t, m.Unlock(context.Background()), ErrNotHeld)
+}
+
+func TestGuard(t *testing.T) {
+	comp, _ := newTestComponent(t, DefaultConfig())
+	ctx := context.Background()
+
+	var tokens []int64
+	err := comp.Guard(ctx, "guard", func(ctx context.Context, token int64) error {
+		tokens = append(tokens, token)
+		// 执行期间其他副本获取不到锁
+		return comp.Guard(ctx, "guard", func(context.Context, int64) error {
+			t.Fatal("should not run")
+			return nil
+		})
+	})
+	assert.ErrorIs(t, err, ErrNotObtained)
+	require.Len(t, tokens, 1)
+
+	// 执行结束后释放锁
+	ran := false
+	job := comp.GuardCron("guard", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.NoError(t, job(ctx))
+	assert.True(t, ran)
+
+	m := comp.NewMutex("job")
+	require.NoError(t, m.TryLock(ctx))
+	fn := comp.GuardJob("job", func(ctx ejob.Context) error {
+		t.Fatal("should be skipped")
+		return nil
+	})
+	assert.NoError(t, fn(ejob.Context{Ctx: ctx}))
+	require.NoError(t, m.Unlock(ctx))
+}
+
+func TestCronLock(t *testing.T) {
+	comp, _ := newTestComponent(t, DefaultConfig())
+	ctx := context.Background()
+
+	lock := comp.CronLock("cron")
+	assert.ErrorIs(t, lock.Refresh(ctx, time.Second), ErrNotHeld)
+	require.NoError(t, lock.Lock(ctx, time.Second))
+	require.NoError(t, lock.Refresh(ctx, time.Second))
+
+	other := comp.CronLock("cron")
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, other.Lock(waitCtx, time.Second), ErrNotObtained)
+
+	require.NoError(t, lock.Unlock(ctx))
+	require.NoError(t, other.Lock(ctx, time.Second))
+	require.NoError(t, other.Unlock(ctx))
+}
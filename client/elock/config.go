@@ -0,0 +1,46 @@
+package elock
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// BackendRedis 基于Redis的Redlock实现，RedisAddrs中的每个地址都是独立的Redis节点
+	BackendRedis = "redis"
+	// BackendEtcd 基于etcd租约的实现
+	BackendEtcd = "etcd"
+)
+
+// Config 分布式锁配置
+type Config struct {
+	Backend       string        // 存储后端，redis或者etcd，默认redis
+	KeyPrefix     string        // 锁key的前缀，默认为elock:
+	TTL           time.Duration // 锁的过期时间，默认10s
+	RenewInterval time.Duration // 自动续期的间隔，默认为TTL的1/3
+	RetryInterval time.Duration // Lock等待锁时的重试间隔，默认100ms
+	Timeout       time.Duration // 访问单个节点的超时，默认500ms
+	DriftFactor   float64       // Redlock的时钟漂移系数，默认0.01
+	RedisAddrs    []string      // Redis节点地址，超过半数节点加锁成功才算获取到锁
+	RedisUsername string        // Redis用户名
+	RedisPassword string        // Redis密码
+	RedisDB       int           // Redis DB
+	EtcdEndpoints []string      // etcd地址
+	EtcdUsername  string        // etcd用户名
+	EtcdPassword  string        // etcd密码
+	EnableMetric  bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Backend:       BackendRedis,
+		KeyPrefix:     "elock:",
+		TTL:           xtime.Duration("10s"),
+		RetryInterval: xtime.Duration("100ms"),
+		Timeout:       xtime.Duration("500ms"),
+		DriftFactor:   0.01,
+		EnableMetric:  true,
+	}
+}
@@ -0,0 +1,100 @@
+package elock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// etcdBackend 基于etcd租约的实现，fencing token为加锁成功时的集群revision
+type etcdBackend struct {
+	client  *clientv3.Client
+	timeout time.Duration
+	owned   bool
+}
+
+// NewEtcdBackend 使用已有的etcd客户端创建后端
+func NewEtcdBackend(client *clientv3.Client) Backend {
+	return &etcdBackend{client: client, timeout: DefaultConfig().Timeout}
+}
+
+func (e *etcdBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	seconds := int64(ttl / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	lease, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return 0, fmt.Errorf("elock etcd grant fail, %w", err)
+	}
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, owner, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		_, _ = e.client.Revoke(context.Background(), lease.ID)
+		if err != nil {
+			return 0, fmt.Errorf("elock etcd acquire fail, %w", err)
+		}
+		return 0, ErrNotObtained
+	}
+	return resp.Header.Revision, nil
+}
+
+// Refresh etcd租约的ttl在创建时确定，续期时使用创建时的ttl
+func (e *etcdBackend) Refresh(ctx context.Context, key, owner string, _ time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	lease, err := e.lease(ctx, key, owner)
+	if err != nil {
+		return err
+	}
+	if _, err := e.client.KeepAliveOnce(ctx, lease); err != nil {
+		return fmt.Errorf("elock etcd keepalive fail, %w", err)
+	}
+	return nil
+}
+
+func (e *etcdBackend) Release(ctx context.Context, key, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	lease, err := e.lease(ctx, key, owner)
+	if err != nil {
+		return err
+	}
+	// 撤销租约时会同时删除key
+	if _, err := e.client.Revoke(ctx, lease); err != nil {
+		return fmt.Errorf("elock etcd revoke fail, %w", err)
+	}
+	return nil
+}
+
+// lease 返回owner持有的锁对应的租约
+func (e *etcdBackend) lease(ctx context.Context, key, owner string) (clientv3.LeaseID, error) {
+	resp, err := e.client.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("elock etcd get fail, %w", err)
+	}
+	if len(resp.Kvs) == 0 || string(resp.Kvs[0].Value) != owner {
+		return 0, ErrNotHeld
+	}
+	return clientv3.LeaseID(resp.Kvs[0].Lease), nil
+}
+
+func (e *etcdBackend) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	_, err := e.client.Get(ctx, "health", clientv3.WithCountOnly())
+	return err
+}
+
+func (e *etcdBackend) Close() error {
+	if !e.owned {
+		return nil
+	}
+	return e.client.Close()
+}
@@ -0,0 +1,104 @@
+package elock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ecron"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+// Guard 获取锁后执行fn，执行结束后释放锁，锁被占用时返回ErrNotObtained
+// 持有锁期间自动续期，锁丢失时取消fn的ctx，token为本次获取锁的fencing token
+func (c *Component) Guard(ctx context.Context, key string, fn func(ctx context.Context, token int64) error, opts ...MutexOption) error {
+	m := c.NewMutex(key, append([]MutexOption{WithAutoRenew(true)}, opts...)...)
+	if err := m.TryLock(ctx); err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-m.Lost():
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+	defer func() {
+		unlockCtx, unlockCancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		defer unlockCancel()
+		if err := m.Unlock(unlockCtx); err != nil {
+			c.logger.Warn("elock unlock fail", elog.FieldKey(m.Key()), elog.FieldErr(err))
+		}
+	}()
+	return fn(runCtx, m.Token())
+}
+
+// GuardCron 包装定时任务，多个副本同时触发时只有获取到锁的副本执行，其余副本跳过本次执行
+func (c *Component) GuardCron(key string, job ecron.FuncJob) ecron.FuncJob {
+	return func(ctx context.Context) error {
+		err := c.Guard(ctx, key, func(ctx context.Context, _ int64) error {
+			return job(ctx)
+		})
+		if errors.Is(err, ErrNotObtained) {
+			c.logger.Info("cron skipped, lock held by other instance", elog.FieldKey(key))
+			return nil
+		}
+		return err
+	}
+}
+
+// GuardJob 包装短时任务，保证同一时间只有一个副本在执行，其余副本跳过执行
+func (c *Component) GuardJob(key string, fn func(ctx ejob.Context) error) func(ctx ejob.Context) error {
+	return func(jobCtx ejob.Context) error {
+		err := c.Guard(jobCtx.Ctx, key, func(ctx context.Context, _ int64) error {
+			jobCtx.Ctx = ctx
+			return fn(jobCtx)
+		})
+		if errors.Is(err, ErrNotObtained) {
+			c.logger.Info("job skipped, lock held by other instance", elog.FieldKey(key))
+			return nil
+		}
+		return err
+	}
+}
+
+// cronLock 适配ecron.Lock，续期由ecron负责
+type cronLock struct {
+	comp  *Component
+	key   string
+	mutex *Mutex
+}
+
+// CronLock 返回可以用于ecron.WithLock的分布式锁
+func (c *Component) CronLock(key string) ecron.Lock {
+	return &cronLock{comp: c, key: key}
+}
+
+// Lock 在ctx结束前等待获取锁
+func (l *cronLock) Lock(ctx context.Context, ttl time.Duration) error {
+	m := l.comp.NewMutex(l.key, WithTTL(ttl), WithAutoRenew(false))
+	if err := m.Lock(ctx); err != nil {
+		return err
+	}
+	l.mutex = m
+	return nil
+}
+
+func (l *cronLock) Unlock(ctx context.Context) error {
+	if l.mutex == nil {
+		return nil
+	}
+	err := l.mutex.Unlock(ctx)
+	l.mutex = nil
+	return err
+}
+
+func (l *cronLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if l.mutex == nil {
+		return ErrNotHeld
+	}
+	return l.mutex.Refresh(ctx, ttl)
+}
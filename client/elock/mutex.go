@@ -0,0 +1,207 @@
+package elock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// MutexOption 锁选项
+type MutexOption func(m *Mutex)
+
+// WithTTL 设置锁的过期时间，默认使用配置中的TTL
+func WithTTL(ttl time.Duration) MutexOption {
+	return func(m *Mutex) {
+		m.ttl = ttl
+	}
+}
+
+// WithAutoRenew 设置持有锁期间是否自动续期，默认开启
+func WithAutoRenew(enable bool) MutexOption {
+	return func(m *Mutex) {
+		m.autoRenew = enable
+	}
+}
+
+// Mutex 分布式锁，同一个Mutex不能被并发获取
+type Mutex struct {
+	comp      *Component
+	key       string
+	owner     string
+	ttl       time.Duration
+	autoRenew bool
+
+	mu     sync.Mutex
+	held   bool
+	token  int64
+	lost   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMutex 创建分布式锁，每个Mutex有唯一的持有者标识
+func (c *Component) NewMutex(key string, opts ...MutexOption) *Mutex {
+	m := &Mutex{
+		comp:      c,
+		key:       c.config.KeyPrefix + key,
+		owner:     newOwner(),
+		ttl:       c.config.TTL,
+		autoRenew: true,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Key 返回锁的key，包含前缀
+func (m *Mutex) Key() string {
+	return m.key
+}
+
+// TryLock 尝试获取锁，锁被占用时立即返回ErrNotObtained
+func (m *Mutex) TryLock(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.held {
+		return fmt.Errorf("elock already held, key: %s", m.key)
+	}
+	beg := time.Now()
+	token, err := m.comp.backend.Acquire(ctx, m.key, m.owner, m.ttl)
+	m.comp.metric("acquire", m.key, resultCode(err), beg)
+	if err != nil {
+		return err
+	}
+	m.held = true
+	m.token = token
+	m.lost = make(chan struct{})
+	m.cancel = func() {}
+	m.done = nil
+	if m.autoRenew {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancel = cancel
+		m.done = make(chan struct{})
+		go m.renew(ctx, m.lost, m.done)
+	}
+	return nil
+}
+
+// Lock 获取锁，锁被占用时按照RetryInterval重试，直到获取成功或者ctx结束
+func (m *Mutex) Lock(ctx context.Context) error {
+	beg := time.Now()
+	defer func() {
+		m.comp.observeWait(m.key, beg)
+	}()
+	for {
+		err := m.TryLock(ctx)
+		if err == nil {
+			return nil
+		}
+		// 等待超时可能发生在请求后端的过程中，此时同样按照等待失败处理
+		if !errors.Is(err, ErrNotObtained) && ctx.Err() == nil {
+			return err
+		}
+		// 增加随机抖动，避免多个等待者同时重试
+		interval := m.comp.config.RetryInterval
+		interval += time.Duration(mrand.Int63n(int64(interval)/2 + 1))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("elock wait fail, key: %s, %w", m.key, errors.Join(ErrNotObtained, ctx.Err()))
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Unlock 释放锁，锁已经过期时返回ErrNotHeld
+func (m *Mutex) Unlock(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.held {
+		return ErrNotHeld
+	}
+	m.stopRenew()
+	m.held = false
+	err := m.comp.backend.Release(ctx, m.key, m.owner)
+	m.comp.metric("release", m.key, resultCode(err), time.Time{})
+	return err
+}
+
+// Refresh 手动延长锁的过期时间
+func (m *Mutex) Refresh(ctx context.Context, ttl time.Duration) error {
+	err := m.comp.backend.Refresh(ctx, m.key, m.owner, ttl)
+	m.comp.metric("refresh", m.key, resultCode(err), time.Time{})
+	return err
+}
+
+// Token 返回本次获取锁时的fencing token，每次获取锁得到的token单调递增
+// 写入受保护的资源时带上token，资源方拒绝比已见过的token更小的请求，避免锁过期后旧持有者的写入
+func (m *Mutex) Token() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token
+}
+
+// Lost 自动续期失败，锁可能已经被其他持有者获取时关闭
+func (m *Mutex) Lost() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lost == nil {
+		lost := make(chan struct{})
+		close(lost)
+		return lost
+	}
+	return m.lost
+}
+
+func (m *Mutex) stopRenew() {
+	m.cancel()
+	if m.done != nil {
+		<-m.done
+	}
+}
+
+// renew 每隔RenewInterval续期一次，锁被其他持有者获取，或者在ttl内都没有续期成功时，认为锁已经丢失
+func (m *Mutex) renew(ctx context.Context, lost chan struct{}, done chan struct{}) {
+	defer close(done)
+	interval := m.comp.config.RenewInterval
+	if interval <= 0 || interval >= m.ttl {
+		interval = m.ttl / 3
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := m.Refresh(ctx, m.ttl)
+		if err == nil {
+			lastRenew = time.Now()
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		m.comp.logger.Warn("elock renew fail", elog.FieldKey(m.key), elog.FieldErr(err))
+		if errors.Is(err, ErrNotHeld) || time.Since(lastRenew)+interval >= m.ttl {
+			m.comp.logger.Error("elock lost", elog.FieldKey(m.key), elog.FieldErr(err))
+			close(lost)
+			return
+		}
+	}
+}
+
+func newOwner() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
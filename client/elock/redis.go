@@ -0,0 +1,175 @@
+package elock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// acquireScript 加锁成功时递增fencing计数器
+	acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0`)
+	// fenceScript 把fencing计数器提升到本次获取的token，保证后续获取到的token更大
+	fenceScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+if current < tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1`)
+	refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`)
+)
+
+// redisBackend Redlock实现，超过半数节点加锁成功，并且剩余有效时间大于0，才算获取到锁
+// fencing token取多数节点计数器的最大值，任意两个多数派至少有一个公共节点，因此token单调递增
+type redisBackend struct {
+	clients     []redis.UniversalClient
+	timeout     time.Duration
+	driftFactor float64
+	owned       bool
+}
+
+// NewRedisBackend 使用已有的Redis客户端创建Redlock后端，每个客户端需要指向独立的Redis节点
+func NewRedisBackend(clients ...redis.UniversalClient) Backend {
+	return &redisBackend{clients: clients, timeout: DefaultConfig().Timeout, driftFactor: DefaultConfig().DriftFactor}
+}
+
+func (r *redisBackend) quorum() int {
+	return len(r.clients)/2 + 1
+}
+
+// lockKeys 使用hash tag，保证集群模式下锁和计数器在同一个slot
+func lockKeys(key string) []string {
+	return []string{"{" + key + "}", "{" + key + "}:fence"}
+}
+
+// each 并发在所有节点上执行fn，返回每个节点的结果
+func (r *redisBackend) each(ctx context.Context, fn func(ctx context.Context, client redis.UniversalClient) (int64, error)) ([]int64, []error) {
+	results := make([]int64, len(r.clients))
+	errs := make([]error, len(r.clients))
+	var wg sync.WaitGroup
+	for i, client := range r.clients {
+		wg.Add(1)
+		go func(i int, client redis.UniversalClient) {
+			defer wg.Done()
+			nodeCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+			results[i], errs[i] = fn(nodeCtx, client)
+		}(i, client)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+func (r *redisBackend) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, error) {
+	keys := lockKeys(key)
+	beg := time.Now()
+	results, errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) (int64, error) {
+		return acquireScript.Run(ctx, client, keys, owner, ttl.Milliseconds()).Int64()
+	})
+	var (
+		token    int64
+		acquired int
+	)
+	for i, result := range results {
+		if errs[i] == nil && result > 0 {
+			acquired++
+			if result > token {
+				token = result
+			}
+		}
+	}
+	drift := time.Duration(float64(ttl)*r.driftFactor) + 2*time.Millisecond
+	if acquired < r.quorum() || ttl-time.Since(beg)-drift <= 0 {
+		// 没有获取到锁，释放已经加锁成功的节点
+		_ = r.Release(context.Background(), key, owner)
+		if err := errors.Join(errs...); err != nil && countNil(errs) < r.quorum() {
+			return 0, fmt.Errorf("elock redis acquire fail, %w", err)
+		}
+		return 0, ErrNotObtained
+	}
+	r.each(ctx, func(ctx context.Context, client redis.UniversalClient) (int64, error) {
+		return fenceScript.Run(ctx, client, keys[1:], token).Int64()
+	})
+	return token, nil
+}
+
+func (r *redisBackend) Refresh(ctx context.Context, key, owner string, ttl time.Duration) error {
+	results, errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) (int64, error) {
+		return refreshScript.Run(ctx, client, lockKeys(key)[:1], owner, ttl.Milliseconds()).Int64()
+	})
+	return r.check(results, errs, "refresh")
+}
+
+func (r *redisBackend) Release(ctx context.Context, key, owner string) error {
+	results, errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) (int64, error) {
+		return releaseScript.Run(ctx, client, lockKeys(key)[:1], owner).Int64()
+	})
+	return r.check(results, errs, "release")
+}
+
+// check 超过半数节点成功时返回nil，节点都正常响应但是不足半数成功时返回ErrNotHeld
+func (r *redisBackend) check(results []int64, errs []error, action string) error {
+	succeeded := 0
+	for i, result := range results {
+		if errs[i] == nil && result > 0 {
+			succeeded++
+		}
+	}
+	if succeeded >= r.quorum() {
+		return nil
+	}
+	if err := errors.Join(errs...); err != nil && countNil(errs) < r.quorum() {
+		return fmt.Errorf("elock redis %s fail, %w", action, err)
+	}
+	return ErrNotHeld
+}
+
+func (r *redisBackend) Ping(ctx context.Context) error {
+	_, errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) (int64, error) {
+		return 0, client.Ping(ctx).Err()
+	})
+	if countNil(errs) < r.quorum() {
+		return fmt.Errorf("elock redis ping fail, %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (r *redisBackend) Close() error {
+	if !r.owned {
+		return nil
+	}
+	var errs []error
+	for _, client := range r.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func countNil(errs []error) int {
+	n := 0
+	for _, err := range errs {
+		if err == nil {
+			n++
+		}
+	}
+	return n
+}
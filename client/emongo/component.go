@@ -0,0 +1,55 @@
+package emongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "client.emongo"
+
+// disconnectTimeout 断开连接的超时
+const disconnectTimeout = 5 * time.Second
+
+// Component MongoDB组件
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	*mongo.Client
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, client *mongo.Client) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		Client: client,
+	}
+}
+
+// DB 返回配置中的默认数据库
+func (c *Component) DB() *mongo.Database {
+	return c.Client.Database(c.config.Database)
+}
+
+// Ping 检查主节点是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	return c.Client.Ping(ctx, readpref.Primary())
+}
+
+// Close 断开连接，等待正在执行的操作完成
+func (c *Component) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+	defer cancel()
+	if err := c.Client.Disconnect(ctx); err != nil && err != mongo.ErrClientDisconnected {
+		c.logger.Error("disconnect mongo fail", elog.FieldErr(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package emongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func TestBuildOnFailError(t *testing.T) {
+	c := DefaultContainer()
+	c.name = "test.mongo"
+	c.config.DSN = "mongodb://127.0.0.1:1"
+	c.config.ConnectTimeout = 200 * time.Millisecond
+	c.config.ServerSelectionTimeout = 100 * time.Millisecond
+	c.config.OnFail = OnFailError
+	comp := c.Build(WithDatabase("test"))
+	assert.Equal(t, "test", comp.DB().Name())
+
+	// 连接失败时健康检查不通过
+	results := ehealth.Check(context.Background())
+	assert.False(t, ehealth.Healthy(results))
+	ehealth.Unregister("test.mongo")
+
+	// 停止后断开连接，重复断开不报错
+	assert.NoError(t, ehooks.Do(ehooks.StageAfterStop))
+	assert.NoError(t, comp.Close())
+}
+
+func TestCommandMonitor(t *testing.T) {
+	config := DefaultConfig()
+	config.EnableAccessInterceptor = true
+	config.EnableAccessInterceptorReq = true
+	config.SlowLogThreshold = time.Millisecond
+	m := newCommandMonitor("test.mongo", config, elog.EgoLogger)
+	cm := m.monitor()
+
+	cmd, err := bson.Marshal(bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{{Key: "age", Value: 18}}}})
+	assert.NoError(t, err)
+
+	cm.Started(context.Background(), &event.CommandStartedEvent{
+		Command:      cmd,
+		DatabaseName: "test",
+		CommandName:  "find",
+		RequestID:    1,
+	})
+	value, ok := m.commands.Load(int64(1))
+	assert.True(t, ok)
+	started := value.(*startedCommand)
+	assert.Equal(t, "users", started.collection)
+	assert.Contains(t, started.command, `"age"`)
+	assert.NotNil(t, started.span)
+
+	cm.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{DatabaseName: "test", CommandName: "find", RequestID: 1, Duration: 2 * time.Millisecond},
+	})
+	_, ok = m.commands.Load(int64(1))
+	assert.False(t, ok)
+
+	cm.Started(context.Background(), &event.CommandStartedEvent{Command: cmd, DatabaseName: "test", CommandName: "find", RequestID: 2})
+	cm.Failed(context.Background(), &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{DatabaseName: "test", CommandName: "find", RequestID: 2},
+		Failure:              errors.New("boom").Error(),
+	})
+	_, ok = m.commands.Load(int64(2))
+	assert.False(t, ok)
+
+	// 没有开始事件的结束事件直接忽略
+	cm.Succeeded(context.Background(), &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{RequestID: 3}})
+}
+
+func TestPoolMonitor(t *testing.T) {
+	pm := newPoolMonitor("test.mongo")
+	for _, typ := range []string{event.ConnectionCreated, event.GetSucceeded, event.ConnectionReturned, event.GetFailed, event.ConnectionClosed} {
+		pm.Event(&event.PoolEvent{Type: typ, Address: "127.0.0.1:27017", Reason: event.ReasonTimedOut})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "abc", truncate("abc", 0))
+	assert.Equal(t, "ab...", truncate("abc", 2))
+}
@@ -0,0 +1,56 @@
+package emongo
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// OnFailPanic 连接失败时panic
+	OnFailPanic = "panic"
+	// OnFailError 连接失败时记录错误日志，继续启动
+	OnFailError = "error"
+)
+
+// Config MongoDB配置
+type Config struct {
+	DSN                        string        // 连接地址，例如 mongodb://127.0.0.1:27017/?replicaSet=rs0
+	Database                   string        // 默认数据库，DB方法返回该数据库
+	Username                   string        // 用户名，为空时使用DSN中的认证信息
+	Password                   string        // 密码
+	AuthSource                 string        // 认证数据库，默认admin
+	MaxPoolSize                uint64        // 每个节点的最大连接数，默认100
+	MinPoolSize                uint64        // 每个节点的最小连接数，默认0
+	MaxConnIdleTime            time.Duration // 连接的最大空闲时间，默认0，不限制
+	ConnectTimeout             time.Duration // 建立连接的超时，默认5s
+	SocketTimeout              time.Duration // 读写的超时，默认0，由ctx控制
+	ServerSelectionTimeout     time.Duration // 选择节点的超时，默认5s
+	SlowLogThreshold           time.Duration // 慢日志记录的阈值，默认500ms
+	EnableTraceInterceptor     bool          // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor    bool          // 是否开启监控，默认开启
+	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
+	EnableAccessInterceptorReq bool          // 是否开启记录请求参数，默认不开启
+	AccessInterceptorMaxLength int           `unit:"size"` // 记录请求参数的最大长度，默认4K
+	EnableHealthCheck          bool          // 是否注册到组件健康检查，默认开启
+	HealthCritical             bool          // 健康检查失败时是否影响服务就绪，默认开启
+	OnFail                     string        // 启动时连接失败的处理，panic或者error，默认panic
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		DSN:                        "mongodb://127.0.0.1:27017",
+		AuthSource:                 "admin",
+		MaxPoolSize:                100,
+		ConnectTimeout:             xtime.Duration("5s"),
+		ServerSelectionTimeout:     xtime.Duration("5s"),
+		SlowLogThreshold:           xtime.Duration("500ms"),
+		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
+		AccessInterceptorMaxLength: 4096,
+		EnableHealthCheck:          true,
+		HealthCritical:             true,
+		OnFail:                     OnFailPanic,
+	}
+}
@@ -0,0 +1,98 @@
+package emongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config        *Config
+	name          string
+	logger        *elog.Component
+	clientOptions []*options.ClientOptions
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 创建客户端，启动时检查连接，并在ego停止后断开连接
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.ConnectTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{c.clientOptionsFromConfig()}, c.clientOptions...)...)
+	if err != nil {
+		c.logger.Panic("connect mongo fail", elog.FieldErr(err))
+	}
+	comp := newComponent(c.name, c.config, c.logger, client)
+	if err := comp.Ping(ctx); err != nil {
+		if c.config.OnFail == OnFailPanic {
+			c.logger.Panic("ping mongo fail", elog.FieldErr(err))
+		}
+		c.logger.Error("ping mongo fail", elog.FieldErr(err))
+	}
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("mongo"), ehealth.WithCritical(c.config.HealthCritical))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) clientOptionsFromConfig() *options.ClientOptions {
+	opts := options.Client().
+		ApplyURI(c.config.DSN).
+		SetMaxPoolSize(c.config.MaxPoolSize).
+		SetMinPoolSize(c.config.MinPoolSize).
+		SetConnectTimeout(c.config.ConnectTimeout).
+		SetServerSelectionTimeout(c.config.ServerSelectionTimeout).
+		SetMonitor(newCommandMonitor(c.name, c.config, c.logger).monitor()).
+		SetPoolMonitor(newPoolMonitor(c.name))
+	if c.config.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(c.config.MaxConnIdleTime)
+	}
+	if c.config.SocketTimeout > 0 {
+		opts.SetSocketTimeout(c.config.SocketTimeout)
+	}
+	if c.config.Username != "" {
+		opts.SetAuth(options.Credential{
+			AuthSource: c.config.AuthSource,
+			Username:   c.config.Username,
+			Password:   c.config.Password,
+		})
+	}
+	return opts
+}
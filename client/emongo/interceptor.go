@@ -0,0 +1,177 @@
+package emongo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+var (
+	// poolConnections 连接池的连接数，state为open、in_use
+	poolConnections = emetric.GaugeVecOpts{
+		Namespace: emetric.DefaultNamespace,
+		Name:      "client_mongo_pool_connections",
+		Labels:    []string{"name", "addr", "state"},
+	}.Build()
+	// poolCheckoutFailed 获取连接失败的次数
+	poolCheckoutFailed = emetric.CounterVecOpts{
+		Namespace: emetric.DefaultNamespace,
+		Name:      "client_mongo_pool_checkout_failed_total",
+		Labels:    []string{"name", "addr", "reason"},
+	}.Build()
+)
+
+// commandMonitor 命令监控，记录链路、监控、访问日志和慢日志
+type commandMonitor struct {
+	name     string
+	config   *Config
+	logger   *elog.Component
+	tracer   *etrace.Tracer
+	commands sync.Map // requestID => *startedCommand
+}
+
+type startedCommand struct {
+	ctx        context.Context
+	span       trace.Span
+	collection string
+	command    string
+}
+
+func newCommandMonitor(name string, config *Config, logger *elog.Component) *commandMonitor {
+	return &commandMonitor{
+		name:   name,
+		config: config,
+		logger: logger,
+		tracer: etrace.NewTracer(trace.SpanKindClient),
+	}
+}
+
+// monitor 转换为驱动的命令监控
+func (m *commandMonitor) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+func (m *commandMonitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	cmd := &startedCommand{ctx: ctx}
+	// 命令的第一个元素为操作的集合，例如 {"find": "users"}
+	if elem, err := evt.Command.IndexErr(0); err == nil {
+		cmd.collection, _ = elem.Value().StringValueOK()
+	}
+	if m.config.EnableAccessInterceptorReq {
+		cmd.command = truncate(evt.Command.String(), m.config.AccessInterceptorMaxLength)
+	}
+	if m.config.EnableTraceInterceptor {
+		cmd.ctx, cmd.span = m.tracer.Start(ctx, "mongo."+evt.CommandName, nil, trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBNameKey.String(evt.DatabaseName),
+			semconv.DBOperationKey.String(evt.CommandName),
+			semconv.DBMongoDBCollectionKey.String(cmd.collection),
+			semconv.PeerServiceKey.String(m.name),
+			attribute.String("db.connection_id", evt.ConnectionID),
+		))
+	}
+	m.commands.Store(evt.RequestID, cmd)
+}
+
+func (m *commandMonitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	m.finished(evt.CommandFinishedEvent, nil)
+}
+
+func (m *commandMonitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	m.finished(evt.CommandFinishedEvent, errors.New(evt.Failure))
+}
+
+func (m *commandMonitor) finished(evt event.CommandFinishedEvent, err error) {
+	value, ok := m.commands.LoadAndDelete(evt.RequestID)
+	if !ok {
+		return
+	}
+	cmd := value.(*startedCommand)
+	if cmd.span != nil {
+		if err != nil {
+			cmd.span.RecordError(err)
+			cmd.span.SetStatus(codes.Error, err.Error())
+		}
+		cmd.span.End()
+	}
+
+	method := evt.DatabaseName + "." + evt.CommandName
+	if m.config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ClientHandleCounter.Inc(emetric.TypeMongo, m.name, method, cmd.collection, code)
+		emetric.ClientHandleHistogram.Observe(evt.Duration.Seconds(), emetric.TypeMongo, m.name, method, cmd.collection)
+	}
+
+	isSlow := m.config.SlowLogThreshold > 0 && evt.Duration > m.config.SlowLogThreshold
+	if !m.config.EnableAccessInterceptor && !isSlow && err == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(method),
+		elog.FieldKey(cmd.collection),
+		elog.FieldCost(evt.Duration),
+		elog.FieldEvent(event),
+	}
+	if cmd.command != "" {
+		fields = append(fields, elog.String("req", cmd.command))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(cmd.ctx)))
+	}
+	switch {
+	case err != nil:
+		m.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		m.logger.Warn("access", fields...)
+	default:
+		m.logger.Info("access", fields...)
+	}
+}
+
+// newPoolMonitor 连接池监控
+func newPoolMonitor(name string) *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				poolConnections.Inc(name, evt.Address, "open")
+			case event.ConnectionClosed:
+				poolConnections.Add(-1, name, evt.Address, "open")
+			case event.GetSucceeded:
+				poolConnections.Inc(name, evt.Address, "in_use")
+			case event.ConnectionReturned:
+				poolConnections.Add(-1, name, evt.Address, "in_use")
+			case event.GetFailed:
+				poolCheckoutFailed.Inc(name, evt.Address, evt.Reason)
+			}
+		},
+	}
+}
+
+func truncate(s string, max int) string {
+	if max > 0 && len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
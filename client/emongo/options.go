@@ -0,0 +1,26 @@
+package emongo
+
+import (
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WithDSN 设置连接地址
+func WithDSN(dsn string) Option {
+	return func(c *Container) {
+		c.config.DSN = dsn
+	}
+}
+
+// WithDatabase 设置默认数据库
+func WithDatabase(database string) Option {
+	return func(c *Container) {
+		c.config.Database = database
+	}
+}
+
+// WithClientOptions 设置原生的客户端选项，在配置之后应用，可以覆盖配置
+func WithClientOptions(opts ...*options.ClientOptions) Option {
+	return func(c *Container) {
+		c.clientOptions = append(c.clientOptions, opts...)
+	}
+}
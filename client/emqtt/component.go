@@ -0,0 +1,208 @@
+package emqtt
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.emqtt"
+
+// disconnectTimeout 断开连接的超时
+const disconnectTimeout = 5 * time.Second
+
+// ErrNotConnected 连接断开，正在重连
+var ErrNotConnected = errors.New("emqtt: not connected")
+
+// Message MQTT消息
+type Message struct {
+	Topic      string
+	Payload    []byte
+	QoS        byte
+	Retained   bool
+	Duplicate  bool
+	Properties map[string]string // 5.0的用户属性，3.1.1不支持
+}
+
+// PublishOption 发布选项
+type PublishOption func(msg *Message)
+
+// WithQoS 设置发布的QoS
+func WithQoS(qos byte) PublishOption {
+	return func(msg *Message) {
+		msg.QoS = qos
+	}
+}
+
+// WithRetained 设置保留消息
+func WithRetained() PublishOption {
+	return func(msg *Message) {
+		msg.Retained = true
+	}
+}
+
+// WithProperty 设置5.0的用户属性
+func WithProperty(key, value string) PublishOption {
+	return func(msg *Message) {
+		if msg.Properties == nil {
+			msg.Properties = make(map[string]string)
+		}
+		msg.Properties[key] = value
+	}
+}
+
+// Component MQTT组件，负责发布消息，订阅由 Subscriber 管理
+type Component struct {
+	name       string
+	config     *Config
+	logger     *elog.Component
+	transport  transport
+	router     *router
+	subscriber *Subscriber
+	tracer     *etrace.Tracer
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	comp := &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		router: &router{},
+		tracer: etrace.NewTracer(trace.SpanKindProducer),
+	}
+	comp.subscriber = newSubscriber(comp)
+	h := transportHandler{
+		onMessage: comp.subscriber.handle,
+		onConnect: comp.subscriber.resubscribe,
+	}
+	if config.ProtocolVersion == ProtocolV5 {
+		comp.transport = newTransportV5(config, logger, h)
+	} else {
+		comp.transport = newTransportV311(config, logger, h)
+	}
+	return comp
+}
+
+// Publish 发布消息，默认使用配置的QoS，QoS大于0时等待服务端确认
+func (c *Component) Publish(ctx context.Context, topic string, payload []byte, opts ...PublishOption) error {
+	msg := &Message{Topic: topic, Payload: payload, QoS: c.config.PublishQoS}
+	for _, opt := range opts {
+		opt(msg)
+	}
+	if c.config.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.PublishTimeout)
+		defer cancel()
+	}
+
+	var span trace.Span
+	if c.config.EnableTraceInterceptor {
+		var carrier propagation.TextMapCarrier
+		// 3.1.1没有消息头，无法传递链路信息
+		if c.config.ProtocolVersion == ProtocolV5 {
+			if msg.Properties == nil {
+				msg.Properties = make(map[string]string)
+			}
+			carrier = propagation.MapCarrier(msg.Properties)
+		}
+		ctx, span = c.tracer.Start(ctx, "mqtt.publish "+topic, carrier, trace.WithAttributes(
+			semconv.MessagingSystemKey.String("mqtt"),
+			semconv.MessagingDestinationKey.String(topic),
+			semconv.PeerServiceKey.String(c.name),
+		))
+	}
+	beg := time.Now()
+	err := c.transport.publish(ctx, msg)
+	cost := time.Since(beg)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	c.observe(ctx, msg, cost, err)
+	return err
+}
+
+// Subscriber 订阅服务，需要注册到ego
+func (c *Component) Subscriber() *Subscriber {
+	return c.subscriber
+}
+
+// Ping 检查连接是否可用
+func (c *Component) Ping(_ context.Context) error {
+	if !c.transport.isConnected() {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Close 断开连接，停止重连
+func (c *Component) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+	defer cancel()
+	if err := c.transport.disconnect(ctx); err != nil {
+		c.logger.Error("disconnect mqtt fail", elog.FieldErr(err))
+		return err
+	}
+	return nil
+}
+
+func (c *Component) observe(ctx context.Context, msg *Message, cost time.Duration, err error) {
+	if c.config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ClientHandleCounter.Inc(emetric.TypeMQTT, c.name, "publish", msg.Topic, code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeMQTT, c.name, "publish", msg.Topic)
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	if !c.config.EnableAccessInterceptor && !isSlow && err == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod("publish"),
+		elog.FieldKey(msg.Topic),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+		elog.Int("qos", int(msg.QoS)),
+	}
+	if c.config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(msg.Payload, c.config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+}
+
+func truncate(b []byte, max int) string {
+	if max > 0 && len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
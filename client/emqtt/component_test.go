@@ -0,0 +1,218 @@
+package emqtt
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+var _ server.Server = (*Subscriber)(nil)
+
+type fakeTransport struct {
+	mu           sync.Mutex
+	connected    bool
+	published    []*Message
+	subscribed   map[string]byte
+	unsubscribed []string
+}
+
+func (f *fakeTransport) connect(context.Context) error { f.connected = true; return nil }
+func (f *fakeTransport) publish(_ context.Context, msg *Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, msg)
+	return nil
+}
+func (f *fakeTransport) subscribe(_ context.Context, filters map[string]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k, v := range filters {
+		f.subscribed[k] = v
+	}
+	return nil
+}
+func (f *fakeTransport) unsubscribe(_ context.Context, filters ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unsubscribed = append(f.unsubscribed, filters...)
+	return nil
+}
+func (f *fakeTransport) filters() map[string]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	filters := make(map[string]byte, len(f.subscribed))
+	for k, v := range f.subscribed {
+		filters[k] = v
+	}
+	return filters
+}
+func (f *fakeTransport) isConnected() bool                { return f.connected }
+func (f *fakeTransport) disconnect(context.Context) error { f.connected = false; return nil }
+
+func newTestComponent(version int) (*Component, *fakeTransport) {
+	config := DefaultConfig()
+	config.ProtocolVersion = version
+	config.EnableAccessInterceptor = true
+	comp := newComponent("test.mqtt", config, elog.EgoLogger)
+	ft := &fakeTransport{connected: true, subscribed: map[string]byte{}}
+	comp.transport = ft
+	return comp, ft
+}
+
+func TestSubscriber(t *testing.T) {
+	comp, ft := newTestComponent(ProtocolV5)
+	received := make(chan *Message, 1)
+	release := make(chan struct{})
+	sub := comp.Subscriber().
+		Handle("devices/+/telemetry", func(ctx context.Context, msg *Message) error {
+			received <- msg
+			<-release
+			return nil
+		}, WithSubscribeQoS(2)).
+		Handle("devices/#", func(ctx context.Context, msg *Message) error {
+			return errors.New("unexpected")
+		})
+
+	go func() { _ = sub.Start() }()
+	assert.Eventually(t, func() bool { return len(ft.filters()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.True(t, sub.Health())
+	assert.Equal(t, map[string]byte{"devices/+/telemetry": 2, "devices/#": 1}, ft.filters())
+
+	// 启动后注册的主题立即订阅
+	sub.Handle("alarms/#", func(context.Context, *Message) error { return nil })
+	assert.Equal(t, byte(1), ft.filters()["alarms/#"])
+
+	// 发布时通过用户属性传递链路信息
+	assert.NoError(t, comp.Publish(context.Background(), "devices/1/telemetry", []byte("42"), WithQoS(0), WithRetained()))
+	msg := ft.published[0]
+	assert.Equal(t, byte(0), msg.QoS)
+	assert.True(t, msg.Retained)
+
+	acked := make(chan struct{})
+	go sub.handle(msg, func() { close(acked) })
+	assert.Equal(t, "42", string((<-received).Payload))
+
+	// 优雅停止时等待正在处理的消息完成
+	stopped := make(chan error, 1)
+	go func() { stopped <- sub.GracefulStop(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-stopped:
+		t.Fatal("graceful stop returned before handler finished")
+	default:
+	}
+	close(release)
+	assert.NoError(t, <-stopped)
+	<-acked
+	assert.ElementsMatch(t, []string{"devices/+/telemetry", "devices/#", "alarms/#"}, ft.unsubscribed)
+	assert.False(t, sub.Health())
+
+	// 停止后的消息不再处理，也不确认
+	sub.handle(&Message{Topic: "devices/1/telemetry"}, func() { t.Fatal("should not ack") })
+}
+
+func TestSubscriberGracefulStopTimeout(t *testing.T) {
+	comp, _ := newTestComponent(ProtocolV311)
+	release := make(chan struct{})
+	defer close(release)
+	sub := comp.Subscriber().Handle("a", func(context.Context, *Message) error {
+		<-release
+		return nil
+	})
+	go sub.handle(&Message{Topic: "a"}, nil)
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, sub.GracefulStop(ctx), context.DeadlineExceeded)
+}
+
+func TestSubscriberHandleBatch(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	comp, ft := newTestComponent(ProtocolV5)
+	batches := make(chan []*Message, 2)
+	sub := comp.Subscriber().HandleBatch("devices/+/telemetry", func(_ context.Context, msgs []*Message) error {
+		batches <- msgs
+		return nil
+	}, 2, 20*time.Millisecond)
+
+	assert.NoError(t, comp.Publish(context.Background(), "devices/1/telemetry", []byte("42")))
+	producer := recorder.Ended()[0].SpanContext()
+
+	// 凑满一批后处理，处理完后确认
+	var acked sync.WaitGroup
+	acked.Add(3)
+	sub.handle(ft.published[0], acked.Done)
+	sub.handle(&Message{Topic: "devices/2/telemetry"}, acked.Done)
+	assert.Len(t, <-batches, 2)
+	// 不满一批时等待后处理
+	sub.handle(&Message{Topic: "devices/3/telemetry"}, acked.Done)
+	assert.Len(t, <-batches, 1)
+	acked.Wait()
+	assert.NoError(t, sub.GracefulStop(context.Background()))
+
+	// 消费span通过link关联生产者span
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "mqtt.consume devices/+/telemetry", spans[1].Name())
+	assert.Len(t, spans[1].Links(), 1)
+	assert.Equal(t, producer.TraceID(), spans[1].Links()[0].SpanContext.TraceID())
+	assert.Equal(t, producer.SpanID(), spans[1].Links()[0].SpanContext.SpanID())
+}
+
+func TestMatchTopic(t *testing.T) {
+	cases := []struct {
+		filter string
+		topic  string
+		match  bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"a/#", "a", true},
+		{"a/#", "a/b/c", true},
+		{"#", "a/b", true},
+		{"#", "$SYS/broker", false},
+		{"$SYS/#", "$SYS/broker", true},
+		{"+/+/c", "a/b/c", true},
+		{"$share/group/a/+", "a/b", true},
+		{"$share/group", "a", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, matchTopic(c.filter, c.topic), "%s %s", c.filter, c.topic)
+	}
+}
+
+func TestBuildOnFailError(t *testing.T) {
+	for _, version := range []int{ProtocolV311, ProtocolV5} {
+		c := DefaultContainer()
+		c.name = "test.mqtt"
+		c.config.Brokers = []string{"tcp://127.0.0.1:1"}
+		c.config.ProtocolVersion = version
+		c.config.ConnectTimeout = 100 * time.Millisecond
+		c.config.ReconnectInitialInterval = 20 * time.Millisecond
+		c.config.OnFail = OnFailError
+		comp := c.Build()
+		assert.NotEmpty(t, comp.config.ClientID)
+		assert.ErrorIs(t, comp.Ping(context.Background()), ErrNotConnected)
+		assert.False(t, ehealth.Healthy(ehealth.Check(context.Background())))
+		ehealth.Unregister("test.mqtt")
+		assert.Equal(t, "127.0.0.1:1", comp.Subscriber().Info().Address)
+		assert.NoError(t, comp.Close())
+	}
+}
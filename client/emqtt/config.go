@@ -0,0 +1,72 @@
+package emqtt
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// OnFailPanic 连接失败时panic
+	OnFailPanic = "panic"
+	// OnFailError 连接失败时记录错误日志，后台继续重连
+	OnFailError = "error"
+)
+
+const (
+	// ProtocolV311 MQTT 3.1.1
+	ProtocolV311 = 4
+	// ProtocolV5 MQTT 5
+	ProtocolV5 = 5
+)
+
+// Config MQTT配置
+type Config struct {
+	Brokers                    []string      // 服务端地址，支持tcp、ssl、ws、wss，默认 tcp://127.0.0.1:1883
+	ProtocolVersion            int           // 协议版本，4为3.1.1，5为5.0，默认4
+	ClientID                   string        // 客户端ID，持久会话需要固定的ID，默认为应用名加主机名
+	Username                   string        // 用户名
+	Password                   string        // 密码
+	KeepAlive                  time.Duration // 心跳间隔，默认30s
+	ConnectTimeout             time.Duration // 建立连接的超时，默认5s
+	ReconnectInitialInterval   time.Duration // 重连的初始间隔，默认1s
+	ReconnectMaxInterval       time.Duration // 重连的最大间隔，3.1.1按照2倍递增到该值，默认60s
+	CleanSession               bool          // 是否清除会话，关闭后服务端为该客户端保留订阅和离线消息，默认开启
+	SessionExpiry              time.Duration // 5.0的会话过期时间，CleanSession关闭时生效，默认0，断开即过期
+	StoreDir                   string        // 3.1.1未完成的QoS 1、2消息的持久化目录，为空时保存在内存
+	PublishQoS                 byte          // 发布消息的默认QoS，默认1
+	SubscribeQoS               byte          // 订阅的默认QoS，默认1
+	PublishTimeout             time.Duration // 发布的超时，默认5s
+	EnableTraceInterceptor     bool          // 是否开启链路追踪，5.0使用用户属性传递链路信息，默认开启
+	EnableMetricInterceptor    bool          // 是否开启监控，默认开启
+	EnableAccessInterceptor    bool          // 是否开启记录请求数据，默认不开启
+	EnableAccessInterceptorReq bool          // 是否开启记录消息内容，默认不开启
+	AccessInterceptorMaxLength int           `unit:"size"` // 记录消息内容的最大长度，默认4K
+	SlowLogThreshold           time.Duration // 慢日志记录的阈值，默认500ms
+	EnableHealthCheck          bool          // 是否注册到组件健康检查，默认开启
+	HealthCritical             bool          // 健康检查失败时是否影响服务就绪，默认开启
+	OnFail                     string        // 启动时连接失败的处理，panic或者error，默认panic
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Brokers:                    []string{"tcp://127.0.0.1:1883"},
+		ProtocolVersion:            ProtocolV311,
+		KeepAlive:                  xtime.Duration("30s"),
+		ConnectTimeout:             xtime.Duration("5s"),
+		ReconnectInitialInterval:   xtime.Duration("1s"),
+		ReconnectMaxInterval:       xtime.Duration("60s"),
+		CleanSession:               true,
+		PublishQoS:                 1,
+		SubscribeQoS:               1,
+		PublishTimeout:             xtime.Duration("5s"),
+		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
+		AccessInterceptorMaxLength: 4096,
+		SlowLogThreshold:           xtime.Duration("500ms"),
+		EnableHealthCheck:          true,
+		HealthCritical:             true,
+		OnFail:                     OnFailPanic,
+	}
+}
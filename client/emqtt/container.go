@@ -0,0 +1,89 @@
+package emqtt
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithBrokers 设置服务端地址
+func WithBrokers(brokers ...string) Option {
+	return func(c *Container) {
+		c.config.Brokers = brokers
+	}
+}
+
+// WithClientID 设置客户端ID
+func WithClientID(clientID string) Option {
+	return func(c *Container) {
+		c.config.ClientID = clientID
+	}
+}
+
+// Build 建立连接，断开后在后台按照退避策略重连，并在ego停止后断开连接
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if len(c.config.Brokers) == 0 {
+		c.logger.Panic("brokers is empty")
+	}
+	if c.config.ClientID == "" {
+		hostname, _ := os.Hostname()
+		c.config.ClientID = fmt.Sprintf("%s-%s-%d", eapp.Name(), hostname, os.Getpid())
+	}
+	comp := newComponent(c.name, c.config, c.logger.With(elog.String("clientId", c.config.ClientID)))
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.ConnectTimeout)
+	defer cancel()
+	if err := comp.transport.connect(ctx); err != nil {
+		if c.config.OnFail == OnFailPanic {
+			c.logger.Panic("connect mqtt fail", elog.FieldErr(err))
+		}
+		c.logger.Error("connect mqtt fail", elog.FieldErr(err))
+	}
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("mqtt"), ehealth.WithCritical(c.config.HealthCritical))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
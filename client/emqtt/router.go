@@ -0,0 +1,85 @@
+package emqtt
+
+import (
+	"strings"
+	"sync"
+)
+
+// route 订阅的主题过滤器和处理函数
+type route struct {
+	filter  string
+	qos     byte
+	handler Handler
+	batch   *batcher // 批量处理时不为空
+}
+
+// router 按照主题过滤器把消息路由到处理函数，支持+、#通配符和$share共享订阅
+type router struct {
+	mu     sync.RWMutex
+	routes []route
+}
+
+func (r *router) add(rt route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.routes {
+		if r.routes[i].filter == rt.filter {
+			r.routes[i] = rt
+			return
+		}
+	}
+	r.routes = append(r.routes, rt)
+}
+
+// filters 所有的主题过滤器和QoS
+func (r *router) filters() map[string]byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	filters := make(map[string]byte, len(r.routes))
+	for _, rt := range r.routes {
+		filters[rt.filter] = rt.qos
+	}
+	return filters
+}
+
+// match 返回第一个匹配主题的路由，按照注册的顺序匹配
+func (r *router) match(topic string) (route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rt := range r.routes {
+		if matchTopic(rt.filter, topic) {
+			return rt, true
+		}
+	}
+	return route{}, false
+}
+
+// matchTopic 主题是否匹配过滤器
+func matchTopic(filter, topic string) bool {
+	// 共享订阅 $share/<group>/<filter>
+	if strings.HasPrefix(filter, "$share/") {
+		parts := strings.SplitN(filter, "/", 3)
+		if len(parts) < 3 {
+			return false
+		}
+		filter = parts[2]
+	}
+	// 通配符不匹配以$开头的系统主题
+	if strings.HasPrefix(topic, "$") && !strings.HasPrefix(filter, "$") {
+		return false
+	}
+	fs := strings.Split(filter, "/")
+	ts := strings.Split(topic, "/")
+	for i, f := range fs {
+		if f == "#" {
+			return true
+		}
+		if i >= len(ts) {
+			return false
+		}
+		if f != "+" && f != ts[i] {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
@@ -0,0 +1,402 @@
+package emqtt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+	"github.com/gotomicro/ego/server"
+)
+
+// Handler 消息处理函数，返回的错误只记录日志，MQTT没有否定确认
+type Handler func(ctx context.Context, msg *Message) error
+
+// BatchHandler 批量处理函数，返回的错误只记录日志
+type BatchHandler func(ctx context.Context, msgs []*Message) error
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(r *route)
+
+const (
+	defaultBatchSize = 100
+	defaultBatchWait = 100 * time.Millisecond
+)
+
+// WithSubscribeQoS 设置订阅的QoS
+func WithSubscribeQoS(qos byte) SubscribeOption {
+	return func(r *route) {
+		r.qos = qos
+	}
+}
+
+// Subscriber 订阅服务，实现了 server.Server，由ego管理启动和停止
+// 启动时订阅所有注册的主题，重连后重新订阅；停止时取消订阅，等待正在处理的消息完成
+type Subscriber struct {
+	comp   *Component
+	tracer *etrace.Tracer
+
+	mu       sync.Mutex
+	drained  bool           // 停止后不再处理新的消息
+	wg       sync.WaitGroup // 正在处理的消息
+	started  atomic.Bool
+	stopOnce sync.Once
+	stopping chan struct{}
+}
+
+func newSubscriber(comp *Component) *Subscriber {
+	return &Subscriber{
+		comp:     comp,
+		tracer:   etrace.NewTracer(trace.SpanKindConsumer),
+		stopping: make(chan struct{}),
+	}
+}
+
+// Handle 注册主题过滤器的处理函数，支持+、#通配符和$share共享订阅
+// 一条消息只会交给第一个匹配的处理函数，启动后注册的主题会立即订阅
+func (s *Subscriber) Handle(filter string, handler Handler, opts ...SubscribeOption) *Subscriber {
+	rt := route{filter: filter, qos: s.comp.config.SubscribeQoS, handler: handler}
+	for _, opt := range opts {
+		opt(&rt)
+	}
+	s.comp.router.add(rt)
+	if s.started.Load() && s.comp.transport.isConnected() {
+		if err := s.subscribe(map[string]byte{rt.filter: rt.qos}); err != nil {
+			s.comp.logger.Error("mqtt subscribe fail", elog.String("filter", filter), elog.FieldErr(err))
+		}
+	}
+	return s
+}
+
+// HandleBatch 注册主题过滤器的批量处理函数，凑满size条或者等待wait后处理一批，size、wait小于等于0时使用默认值
+// QoS 1、2的消息在所在的批处理完后确认；批量消费的span通过link关联每条消息的生产者span
+func (s *Subscriber) HandleBatch(filter string, handler BatchHandler, size int, wait time.Duration, opts ...SubscribeOption) *Subscriber {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	if wait <= 0 {
+		wait = defaultBatchWait
+	}
+	batch := &batcher{sub: s, filter: filter, handler: handler, size: size, wait: wait}
+	return s.Handle(filter, nil, append([]SubscribeOption{func(r *route) { r.batch = batch }}, opts...)...)
+}
+
+// Name 配置名称
+func (s *Subscriber) Name() string {
+	return s.comp.name
+}
+
+// PackageName 包名
+func (s *Subscriber) PackageName() string {
+	return PackageName
+}
+
+// Init 初始化
+func (s *Subscriber) Init() error {
+	return nil
+}
+
+// Start 订阅所有注册的主题，阻塞直到停止
+func (s *Subscriber) Start() error {
+	s.started.Store(true)
+	if s.comp.transport.isConnected() {
+		if err := s.subscribe(s.comp.router.filters()); err != nil {
+			return fmt.Errorf("emqtt: subscribe fail, %w", err)
+		}
+	}
+	<-s.stopping
+	return nil
+}
+
+// resubscribe 连接成功后重新订阅，清除会话时服务端不会保留之前的订阅
+func (s *Subscriber) resubscribe() {
+	if !s.started.Load() || s.isStopping() {
+		return
+	}
+	if err := s.subscribe(s.comp.router.filters()); err != nil {
+		s.comp.logger.Error("mqtt resubscribe fail", elog.FieldErr(err))
+	}
+}
+
+func (s *Subscriber) subscribe(filters map[string]byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.comp.config.ConnectTimeout)
+	defer cancel()
+	if err := s.comp.transport.subscribe(ctx, filters); err != nil {
+		return err
+	}
+	s.comp.logger.Info("mqtt subscribed", elog.Any("filters", filters))
+	return nil
+}
+
+// handle 处理收到的消息，ack为nil时由客户端自动确认
+func (s *Subscriber) handle(msg *Message, ack func()) {
+	s.mu.Lock()
+	if s.drained {
+		s.mu.Unlock()
+		return
+	}
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	rt, ok := s.comp.router.match(msg.Topic)
+	if ok && rt.batch != nil {
+		// 攒批处理，处理完一批后再确认
+		rt.batch.add(msg, ack)
+		return
+	}
+	defer s.wg.Done()
+	if ack != nil {
+		defer ack()
+	}
+	if !ok {
+		s.comp.logger.Warn("mqtt message without handler", elog.FieldKey(msg.Topic))
+		return
+	}
+	ctx := context.Background()
+	var span trace.Span
+	if s.comp.config.EnableTraceInterceptor {
+		ctx, span = s.tracer.Start(ctx, "mqtt.consume "+rt.filter, propagation.MapCarrier(msg.Properties), trace.WithAttributes(
+			semconv.MessagingSystemKey.String("mqtt"),
+			semconv.MessagingDestinationKey.String(msg.Topic),
+			semconv.MessagingOperationProcess,
+		))
+	}
+	beg := time.Now()
+	err := invoke(ctx, rt.handler, msg)
+	cost := time.Since(beg)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	s.observe(ctx, rt, msg, cost, err)
+}
+
+// batcher 攒批处理同一个主题过滤器的消息
+type batcher struct {
+	sub     *Subscriber
+	filter  string
+	handler BatchHandler
+	size    int
+	wait    time.Duration
+
+	mu    sync.Mutex
+	msgs  []*Message
+	acks  []func()
+	timer *time.Timer
+}
+
+// add 加入一条消息，凑满size条时立即处理，否则等待wait后处理
+func (b *batcher) add(msg *Message, ack func()) {
+	b.mu.Lock()
+	b.msgs = append(b.msgs, msg)
+	b.acks = append(b.acks, ack)
+	if len(b.msgs) == 1 {
+		b.timer = time.AfterFunc(b.wait, b.flush)
+	}
+	full := len(b.msgs) >= b.size
+	b.mu.Unlock()
+	if full {
+		b.flush()
+	}
+}
+
+// flush 处理当前攒批的消息，处理完后确认
+func (b *batcher) flush() {
+	b.mu.Lock()
+	msgs, acks := b.msgs, b.acks
+	b.msgs, b.acks = nil, nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+	if len(msgs) == 0 {
+		return
+	}
+	b.sub.handleBatch(route{filter: b.filter}, b.handler, msgs)
+	for _, ack := range acks {
+		if ack != nil {
+			ack()
+		}
+		b.sub.wg.Done()
+	}
+}
+
+func (s *Subscriber) handleBatch(rt route, handler BatchHandler, msgs []*Message) {
+	ctx := context.Background()
+	var span trace.Span
+	if s.comp.config.EnableTraceInterceptor {
+		carriers := make([]propagation.TextMapCarrier, 0, len(msgs))
+		for _, msg := range msgs {
+			carriers = append(carriers, propagation.MapCarrier(msg.Properties))
+		}
+		ctx, span = s.tracer.StartBatch(ctx, "mqtt.consume "+rt.filter, carriers, trace.WithAttributes(
+			semconv.MessagingSystemKey.String("mqtt"),
+			semconv.MessagingOperationProcess,
+		))
+	}
+	beg := time.Now()
+	err := invokeBatch(ctx, handler, msgs)
+	cost := time.Since(beg)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	for _, msg := range msgs {
+		s.observe(ctx, rt, msg, cost, err)
+	}
+}
+
+// invokeBatch 执行批量处理函数，panic转换为错误
+func invokeBatch(ctx context.Context, handler BatchHandler, msgs []*Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("emqtt: batch handler panic, %v", rec)
+		}
+	}()
+	return handler(ctx, msgs)
+}
+
+// invoke 执行处理函数，panic转换为错误
+func invoke(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("emqtt: handler panic, %v", rec)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+func (s *Subscriber) observe(ctx context.Context, rt route, msg *Message, cost time.Duration, err error) {
+	config := s.comp.config
+	if config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ServerHandleCounter.Inc(emetric.TypeMQTT, rt.filter, msg.Topic, code, "", "")
+		emetric.ServerHandleHistogram.Observe(cost.Seconds(), emetric.TypeMQTT, rt.filter, msg.Topic, "")
+	}
+
+	isSlow := config.SlowLogThreshold > 0 && cost > config.SlowLogThreshold
+	if !config.EnableAccessInterceptor && !isSlow && err == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod("consume " + rt.filter),
+		elog.FieldKey(msg.Topic),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+		elog.Int("qos", int(msg.QoS)),
+	}
+	if config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(msg.Payload, config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case err != nil:
+		s.comp.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		s.comp.logger.Warn("access", fields...)
+	default:
+		s.comp.logger.Info("access", fields...)
+	}
+}
+
+// Stop 取消订阅，不再处理新的消息
+func (s *Subscriber) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), disconnectTimeout)
+	defer cancel()
+	s.unsubscribe(ctx)
+	return nil
+}
+
+// GracefulStop 取消订阅，等待正在处理的消息完成，超时后返回
+// 没有处理的QoS 1、2消息不会确认，持久会话下服务端会重新投递
+func (s *Subscriber) GracefulStop(ctx context.Context) error {
+	s.unsubscribe(ctx)
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Subscriber) unsubscribe(ctx context.Context) {
+	s.stopOnce.Do(func() {
+		close(s.stopping)
+	})
+	if s.comp.transport.isConnected() {
+		filters := make([]string, 0)
+		for filter := range s.comp.router.filters() {
+			filters = append(filters, filter)
+		}
+		if err := s.comp.transport.unsubscribe(ctx, filters...); err != nil {
+			s.comp.logger.Warn("mqtt unsubscribe fail", elog.FieldErr(err))
+		}
+	}
+	s.mu.Lock()
+	s.drained = true
+	s.mu.Unlock()
+}
+
+func (s *Subscriber) isStopping() bool {
+	select {
+	case <-s.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Health 是否已经订阅并且连接正常
+func (s *Subscriber) Health() bool {
+	return s.started.Load() && !s.isStopping() && s.comp.transport.isConnected()
+}
+
+// Info 服务信息
+func (s *Subscriber) Info() *server.ServiceInfo {
+	addr := ""
+	if len(s.comp.config.Brokers) > 0 {
+		addr = s.comp.config.Brokers[0]
+		if u, err := url.Parse(addr); err == nil && u.Host != "" {
+			addr = u.Host
+		}
+	}
+	info := server.ApplyOptions(
+		server.WithName(s.Name()),
+		server.WithScheme("mqtt"),
+		server.WithAddress(addr),
+		server.WithKind(constant.ServiceConsumer),
+	)
+	return &info
+}
@@ -0,0 +1,291 @@
+package emqtt
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// transport 屏蔽3.1.1和5.0客户端的差异，断开后由客户端在后台重连
+type transport interface {
+	// connect 建立连接，在超时内没有连接成功时返回错误，后台继续重连
+	connect(ctx context.Context) error
+	publish(ctx context.Context, msg *Message) error
+	subscribe(ctx context.Context, filters map[string]byte) error
+	unsubscribe(ctx context.Context, filters ...string) error
+	isConnected() bool
+	disconnect(ctx context.Context) error
+}
+
+// transportHandler 收到消息和连接成功的回调
+type transportHandler struct {
+	onMessage func(msg *Message, ack func())
+	onConnect func()
+}
+
+// transportV311 基于paho.mqtt.golang的3.1.1客户端
+type transportV311 struct {
+	client mqtt.Client
+}
+
+func newTransportV311(config *Config, logger *elog.Component, h transportHandler) *transportV311 {
+	opts := mqtt.NewClientOptions().
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetProtocolVersion(ProtocolV311).
+		SetKeepAlive(config.KeepAlive).
+		SetConnectTimeout(config.ConnectTimeout).
+		SetCleanSession(config.CleanSession).
+		SetResumeSubs(!config.CleanSession).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(config.ReconnectInitialInterval).
+		SetMaxReconnectInterval(config.ReconnectMaxInterval).
+		SetOrderMatters(false).
+		SetAutoAckDisabled(true).
+		SetDefaultPublishHandler(func(_ mqtt.Client, m mqtt.Message) {
+			h.onMessage(&Message{
+				Topic:     m.Topic(),
+				Payload:   m.Payload(),
+				QoS:       m.Qos(),
+				Retained:  m.Retained(),
+				Duplicate: m.Duplicate(),
+			}, m.Ack)
+		}).
+		SetOnConnectHandler(func(mqtt.Client) {
+			h.onConnect()
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn("mqtt connection lost", elog.FieldErr(err))
+		})
+	for _, broker := range config.Brokers {
+		opts.AddBroker(broker)
+	}
+	if config.StoreDir != "" {
+		opts.SetStore(mqtt.NewFileStore(config.StoreDir))
+	}
+	return &transportV311{client: mqtt.NewClient(opts)}
+}
+
+func (t *transportV311) connect(ctx context.Context) error {
+	if err := waitToken(ctx, t.client.Connect()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *transportV311) publish(ctx context.Context, msg *Message) error {
+	return waitToken(ctx, t.client.Publish(msg.Topic, msg.QoS, msg.Retained, msg.Payload))
+}
+
+func (t *transportV311) subscribe(ctx context.Context, filters map[string]byte) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	return waitToken(ctx, t.client.SubscribeMultiple(filters, nil))
+}
+
+func (t *transportV311) unsubscribe(ctx context.Context, filters ...string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	return waitToken(ctx, t.client.Unsubscribe(filters...))
+}
+
+func (t *transportV311) isConnected() bool {
+	return t.client.IsConnectionOpen()
+}
+
+func (t *transportV311) disconnect(_ context.Context) error {
+	t.client.Disconnect(250)
+	return nil
+}
+
+func waitToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// transportV5 基于paho.golang的5.0客户端
+type transportV5 struct {
+	config    *Config
+	logger    *elog.Component
+	handler   transportHandler
+	mu        sync.Mutex
+	cm        *autopaho.ConnectionManager
+	cancel    context.CancelFunc
+	connected atomic.Bool
+}
+
+func newTransportV5(config *Config, logger *elog.Component, h transportHandler) *transportV5 {
+	return &transportV5{config: config, logger: logger, handler: h}
+}
+
+func (t *transportV5) connect(ctx context.Context) error {
+	urls := make([]*url.URL, 0, len(t.config.Brokers))
+	for _, broker := range t.config.Brokers {
+		u, err := url.Parse(broker)
+		if err != nil {
+			return fmt.Errorf("parse broker %s fail, %w", broker, err)
+		}
+		urls = append(urls, u)
+	}
+	cfg := autopaho.ClientConfig{
+		BrokerUrls:        urls,
+		KeepAlive:         uint16(t.config.KeepAlive.Seconds()),
+		ConnectRetryDelay: t.config.ReconnectInitialInterval,
+		ConnectTimeout:    t.config.ConnectTimeout,
+		OnConnectionUp: func(cm *autopaho.ConnectionManager, _ *paho.Connack) {
+			t.mu.Lock()
+			t.cm = cm
+			t.mu.Unlock()
+			t.connected.Store(true)
+			t.handler.onConnect()
+		},
+		OnConnectError: func(err error) {
+			t.logger.Warn("mqtt connect fail", elog.FieldErr(err))
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: t.config.ClientID,
+			Router: paho.NewSingleHandlerRouter(func(p *paho.Publish) {
+				msg := &Message{
+					Topic:    p.Topic,
+					Payload:  p.Payload,
+					QoS:      p.QoS,
+					Retained: p.Retain,
+				}
+				if p.Properties != nil && len(p.Properties.User) > 0 {
+					msg.Properties = make(map[string]string, len(p.Properties.User))
+					for _, prop := range p.Properties.User {
+						msg.Properties[prop.Key] = prop.Value
+					}
+				}
+				t.handler.onMessage(msg, nil)
+			}),
+			OnClientError: func(err error) {
+				t.connected.Store(false)
+				t.logger.Warn("mqtt connection lost", elog.FieldErr(err))
+			},
+			OnServerDisconnect: func(d *paho.Disconnect) {
+				t.connected.Store(false)
+				t.logger.Warn("mqtt server disconnect", elog.Int("reasonCode", int(d.ReasonCode)))
+			},
+		},
+	}
+	if t.config.Username != "" {
+		cfg.SetUsernamePassword(t.config.Username, []byte(t.config.Password))
+	}
+	cfg.SetConnectPacketConfigurator(func(c *paho.Connect) *paho.Connect {
+		c.CleanStart = t.config.CleanSession
+		if !t.config.CleanSession && t.config.SessionExpiry > 0 {
+			expiry := uint32(t.config.SessionExpiry.Seconds())
+			c.Properties = &paho.ConnectProperties{SessionExpiryInterval: &expiry}
+		}
+		return c
+	})
+
+	connCtx, cancel := context.WithCancel(context.Background())
+	cm, err := autopaho.NewConnection(connCtx, cfg)
+	if err != nil {
+		cancel()
+		return err
+	}
+	t.mu.Lock()
+	t.cm = cm
+	t.cancel = cancel
+	t.mu.Unlock()
+	return cm.AwaitConnection(ctx)
+}
+
+func (t *transportV5) manager() (*autopaho.ConnectionManager, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cm == nil {
+		return nil, ErrNotConnected
+	}
+	return t.cm, nil
+}
+
+func (t *transportV5) publish(ctx context.Context, msg *Message) error {
+	cm, err := t.manager()
+	if err != nil {
+		return err
+	}
+	p := &paho.Publish{
+		QoS:     msg.QoS,
+		Retain:  msg.Retained,
+		Topic:   msg.Topic,
+		Payload: msg.Payload,
+	}
+	if len(msg.Properties) > 0 {
+		p.Properties = &paho.PublishProperties{}
+		for k, v := range msg.Properties {
+			p.Properties.User.Add(k, v)
+		}
+	}
+	_, err = cm.Publish(ctx, p)
+	return err
+}
+
+func (t *transportV5) subscribe(ctx context.Context, filters map[string]byte) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	cm, err := t.manager()
+	if err != nil {
+		return err
+	}
+	s := &paho.Subscribe{Subscriptions: make(map[string]paho.SubscribeOptions, len(filters))}
+	for filter, qos := range filters {
+		s.Subscriptions[filter] = paho.SubscribeOptions{QoS: qos}
+	}
+	_, err = cm.Subscribe(ctx, s)
+	return err
+}
+
+func (t *transportV5) unsubscribe(ctx context.Context, filters ...string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	cm, err := t.manager()
+	if err != nil {
+		return err
+	}
+	_, err = cm.Unsubscribe(ctx, &paho.Unsubscribe{Topics: filters})
+	return err
+}
+
+func (t *transportV5) isConnected() bool {
+	return t.connected.Load()
+}
+
+func (t *transportV5) disconnect(ctx context.Context) error {
+	t.mu.Lock()
+	cm, cancel := t.cm, t.cancel
+	t.mu.Unlock()
+	if cm == nil {
+		return nil
+	}
+	defer cancel()
+	// 没有连接成功时，停止后台重连即可
+	if !t.connected.Swap(false) {
+		return nil
+	}
+	if err := cm.Disconnect(ctx); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
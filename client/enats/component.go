@@ -0,0 +1,197 @@
+package enats
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.enats"
+
+var (
+	// ErrNotConnected 连接断开，正在重连
+	ErrNotConnected = errors.New("enats: not connected")
+	// ErrJetStreamDisabled 没有开启JetStream
+	ErrJetStreamDisabled = errors.New("enats: jetstream disabled")
+)
+
+// Component NATS组件，负责发布消息，并创建消费者
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	nc     *nats.Conn
+	js     nats.JetStreamContext
+	tracer *etrace.Tracer
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, nc *nats.Conn) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		nc:     nc,
+		tracer: etrace.NewTracer(trace.SpanKindProducer),
+	}
+}
+
+// Conn 原生的连接
+func (c *Component) Conn() *nats.Conn {
+	return c.nc
+}
+
+// JetStream 原生的JetStream，没有开启JetStream时返回nil
+func (c *Component) JetStream() nats.JetStreamContext {
+	return c.js
+}
+
+// Publish 发布消息
+func (c *Component) Publish(ctx context.Context, subject string, data []byte) error {
+	return c.PublishMsg(ctx, &nats.Msg{Subject: subject, Data: data})
+}
+
+// PublishMsg 发布消息，链路信息通过消息头传递
+func (c *Component) PublishMsg(ctx context.Context, msg *nats.Msg) error {
+	return c.intercept(ctx, "publish", msg, func(ctx context.Context) error {
+		return c.nc.PublishMsg(msg)
+	})
+}
+
+// Request 发送请求并等待响应
+func (c *Component) Request(ctx context.Context, msg *nats.Msg) (resp *nats.Msg, err error) {
+	err = c.intercept(ctx, "request", msg, func(ctx context.Context) error {
+		resp, err = c.nc.RequestMsgWithContext(ctx, msg)
+		return err
+	})
+	return resp, err
+}
+
+// JSPublish 发布JetStream消息，等待服务端持久化的确认
+func (c *Component) JSPublish(ctx context.Context, msg *nats.Msg, opts ...nats.PubOpt) (ack *nats.PubAck, err error) {
+	if c.js == nil {
+		return nil, ErrJetStreamDisabled
+	}
+	err = c.intercept(ctx, "js_publish", msg, func(ctx context.Context) error {
+		ack, err = c.js.PublishMsg(msg, append(opts, nats.Context(ctx))...)
+		return err
+	})
+	return ack, err
+}
+
+// Ping 检查连接是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	if !c.nc.IsConnected() {
+		return ErrNotConnected
+	}
+	return c.nc.FlushWithContext(ctx)
+}
+
+// Close drain连接，等待已经收到的消息处理完，已经发布的消息发送完
+func (c *Component) Close() error {
+	if c.nc.IsClosed() {
+		return nil
+	}
+	closed := c.nc.StatusChanged(nats.CLOSED)
+	if err := c.nc.Drain(); err != nil {
+		c.nc.Close()
+		// 连接已经关闭或者正在重连时，没有需要drain的数据
+		if errors.Is(err, nats.ErrConnectionClosed) || errors.Is(err, nats.ErrConnectionReconnecting) {
+			return nil
+		}
+		c.logger.Error("drain nats fail", elog.FieldErr(err))
+		return err
+	}
+	select {
+	case <-closed:
+	case <-time.After(c.config.DrainTimeout + time.Second):
+		c.nc.Close()
+	}
+	return nil
+}
+
+func (c *Component) intercept(ctx context.Context, op string, msg *nats.Msg, fn func(ctx context.Context) error) error {
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+	var span trace.Span
+	if c.config.EnableTraceInterceptor {
+		ctx, span = c.tracer.Start(ctx, "nats."+op+" "+msg.Subject, headerCarrier(msg.Header), trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationKey.String(msg.Subject),
+			semconv.PeerServiceKey.String(c.name),
+		))
+	}
+	beg := time.Now()
+	err := fn(ctx)
+	cost := time.Since(beg)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+
+	if c.config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ClientHandleCounter.Inc(emetric.TypeNATS, c.name, op, msg.Subject, code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeNATS, c.name, op, msg.Subject)
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	if !c.config.EnableAccessInterceptor && !isSlow && err == nil {
+		return nil
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(op),
+		elog.FieldKey(msg.Subject),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+	}
+	if c.config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(msg.Data, c.config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+	return err
+}
+
+// headerCarrier 使用消息头传递链路信息
+func headerCarrier(h nats.Header) propagation.TextMapCarrier {
+	return propagation.HeaderCarrier(http.Header(h))
+}
+
+func truncate(b []byte, max int) string {
+	if max > 0 && len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
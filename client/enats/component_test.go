@@ -0,0 +1,162 @@
+package enats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/etrace"
+	"github.com/gotomicro/ego/server"
+)
+
+var _ server.Server = (*Consumer)(nil)
+
+func newTestComponent() *Component {
+	c := DefaultContainer()
+	c.name = "test.nats"
+	c.config.Addr = "nats://127.0.0.1:1"
+	c.config.DialTimeout = 100 * time.Millisecond
+	c.config.ReconnectWait = 10 * time.Millisecond
+	c.config.OnFail = OnFailError
+	return c.Build(WithConsumer("orders", ConsumerConfig{Subject: "orders.*", Queue: "workers", MaxInFlight: 2}))
+}
+
+func TestComponentNotConnected(t *testing.T) {
+	comp := newTestComponent()
+	defer ehealth.Unregister("test.nats")
+	assert.ErrorIs(t, comp.Ping(context.Background()), ErrNotConnected)
+	assert.False(t, ehealth.Healthy(ehealth.Check(context.Background())))
+	_, err := comp.JSPublish(context.Background(), &nats.Msg{Subject: "orders.created"})
+	assert.ErrorIs(t, err, ErrJetStreamDisabled)
+
+	// 重连期间可以订阅，停止时drain订阅
+	consumer := comp.Consumer("orders", func(context.Context, *nats.Msg) error { return nil })
+	assert.Equal(t, "127.0.0.1:1", consumer.Info().Address)
+	errCh := make(chan error, 1)
+	go func() { errCh <- consumer.Start() }()
+	assert.Eventually(t, func() bool { return consumer.running.Load() }, time.Second, 10*time.Millisecond)
+	assert.False(t, consumer.Health())
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, consumer.GracefulStop(ctx))
+	assert.NoError(t, <-errCh)
+	assert.NoError(t, comp.Close())
+	assert.NoError(t, comp.Close())
+}
+
+func TestConsumerInit(t *testing.T) {
+	comp := newComponent("test", DefaultConfig(), DefaultContainer().logger, nil)
+	cases := map[string]ConsumerConfig{
+		"subject":   {},
+		"mode":      {Subject: "a", Mode: "unknown"},
+		"jetstream": {Subject: "a", Mode: ModePush},
+	}
+	for name, config := range cases {
+		comp.config.Consumers = map[string]ConsumerConfig{name: config}
+		assert.Error(t, comp.Consumer(name, nil).Init(), name)
+	}
+	comp.config.Consumers = map[string]ConsumerConfig{"core": {Subject: "a"}}
+	assert.NoError(t, comp.Consumer("core", nil).Init())
+}
+
+func TestConsumerHandle(t *testing.T) {
+	comp := newComponent("test", DefaultConfig(), DefaultContainer().logger, nil)
+	comp.config.EnableAccessInterceptor = true
+	var calls int32
+	consumer := comp.Consumer("orders", func(_ context.Context, msg *nats.Msg) error {
+		atomic.AddInt32(&calls, 1)
+		if string(msg.Data) == "panic" {
+			panic("boom")
+		}
+		return nil
+	})
+	consumer.dispatch(&nats.Msg{Subject: "orders.created", Data: []byte("ok")})
+	consumer.dispatch(&nats.Msg{Subject: "orders.created", Data: []byte("panic")})
+	consumer.wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 核心NATS不需要确认
+	assert.NoError(t, consumer.ack(&nats.Msg{}, errors.New("fail")))
+	// JetStream消息需要确认，没有绑定订阅的消息确认失败
+	consumer.config.Mode = ModePull
+	assert.Error(t, consumer.ack(&nats.Msg{}, nil))
+	consumer.config.AckPolicy = AckNone
+	assert.NoError(t, consumer.ack(&nats.Msg{}, nil))
+}
+
+func TestBatchConsumer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	comp := newComponent("test", DefaultConfig(), DefaultContainer().logger, nil)
+	comp.config.Consumers = map[string]ConsumerConfig{
+		"core": {Subject: "orders.*"},
+		"pull": {Subject: "orders.*", Mode: ModePull, Durable: "orders", AckPolicy: AckNone},
+	}
+	// 批量消费只支持pull模式
+	noop := func(context.Context, []*nats.Msg) error { return nil }
+	assert.ErrorContains(t, comp.BatchConsumer("core", noop).Init(), "requires pull mode")
+
+	var got []*nats.Msg
+	consumer := comp.BatchConsumer("pull", func(_ context.Context, msgs []*nats.Msg) error {
+		got = msgs
+		return nil
+	})
+	producer := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	msg := &nats.Msg{Subject: "orders.created", Header: nats.Header{}}
+	etrace.Inject(trace.ContextWithSpanContext(context.Background(), producer), headerCarrier(msg.Header))
+	consumer.dispatchBatch([]*nats.Msg{msg, {Subject: "orders.paid"}})
+	consumer.wg.Wait()
+	assert.Len(t, got, 2)
+
+	// 消费span通过link关联生产者span
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "nats.consume orders.*", spans[0].Name())
+	assert.Len(t, spans[0].Links(), 1)
+	assert.Equal(t, producer.TraceID(), spans[0].Links()[0].SpanContext.TraceID())
+	assert.Equal(t, producer.SpanID(), spans[0].Links()[0].SpanContext.SpanID())
+}
+
+func TestConsumerConfigDefaults(t *testing.T) {
+	config := ConsumerConfig{MaxInFlight: 4, FetchBatch: 10}.withDefaults()
+	assert.Equal(t, ModeCore, config.Mode)
+	assert.Equal(t, AckExplicit, config.AckPolicy)
+	assert.Equal(t, 4, config.FetchBatch)
+	assert.Equal(t, -1, config.MaxDeliver)
+
+	policy, err := ackPolicy(AckAll)
+	assert.NoError(t, err)
+	assert.Equal(t, nats.AckAllPolicy, policy)
+	_, err = ackPolicy("unknown")
+	assert.Error(t, err)
+}
+
+func TestTerm(t *testing.T) {
+	err := Term(errors.New("bad message"))
+	var term *termError
+	assert.True(t, errors.As(err, &term))
+	assert.EqualError(t, err, "bad message")
+}
+
+func TestHeaderCarrier(t *testing.T) {
+	h := nats.Header{}
+	headerCarrier(h).Set("traceparent", "00-abc")
+	assert.Equal(t, "00-abc", headerCarrier(h).Get("traceparent"))
+	assert.Len(t, headerCarrier(h).Keys(), 1)
+}
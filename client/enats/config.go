@@ -0,0 +1,133 @@
+package enats
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// OnFailPanic 连接失败时panic
+	OnFailPanic = "panic"
+	// OnFailError 连接失败时记录错误日志，后台继续重连
+	OnFailError = "error"
+)
+
+const (
+	// ModeCore 核心NATS订阅，没有持久化和确认
+	ModeCore = "core"
+	// ModePush JetStream推模式消费
+	ModePush = "push"
+	// ModePull JetStream拉模式消费
+	ModePull = "pull"
+)
+
+const (
+	// AckExplicit 每条消息单独确认
+	AckExplicit = "explicit"
+	// AckAll 确认一条消息时，同时确认之前的所有消息
+	AckAll = "all"
+	// AckNone 不需要确认
+	AckNone = "none"
+)
+
+// Config NATS配置
+type Config struct {
+	Addr                       string                    // 连接地址，多个地址使用逗号分隔，默认 nats://127.0.0.1:4222
+	Username                   string                    // 用户名
+	Password                   string                    // 密码
+	Token                      string                    // token认证
+	CredentialsFile            string                    // NATS 2.0 credentials文件
+	DialTimeout                time.Duration             // 建立连接的超时，默认5s
+	ReconnectWait              time.Duration             // 重连的间隔，默认2s
+	MaxReconnects              int                       // 最大重连次数，-1为一直重连，默认-1
+	DrainTimeout               time.Duration             // 停止时drain的超时，默认30s
+	EnableJetStream            bool                      // 是否开启JetStream，默认不开启
+	EnableTraceInterceptor     bool                      // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor    bool                      // 是否开启监控，默认开启
+	EnableAccessInterceptor    bool                      // 是否开启记录请求数据，默认不开启
+	EnableAccessInterceptorReq bool                      // 是否开启记录消息内容，默认不开启
+	AccessInterceptorMaxLength int                       `unit:"size"` // 记录消息内容的最大长度，默认4K
+	SlowLogThreshold           time.Duration             // 慢日志记录的阈值，默认500ms
+	EnableHealthCheck          bool                      // 是否注册到组件健康检查，默认开启
+	HealthCritical             bool                      // 健康检查失败时是否影响服务就绪，默认开启
+	OnFail                     string                    // 启动时连接失败的处理，panic或者error，默认panic
+	Consumers                  map[string]ConsumerConfig // 消费者配置，key为消费者名称
+}
+
+// ConsumerConfig 消费者配置
+type ConsumerConfig struct {
+	Mode          string        // 消费模式，core、push、pull，默认core
+	Subject       string        // 订阅的主题
+	Queue         string        // 队列组，同一个组内的消费者分摊消息，pull模式不需要
+	Stream        string        // JetStream的流，为空时根据主题查找
+	Durable       string        // JetStream的持久化消费者名称，不存在时自动创建，停止时不会删除
+	AckPolicy     string        // JetStream的确认策略，explicit、all、none，默认explicit
+	AckWait       time.Duration // JetStream等待确认的时间，超时后重新投递，默认30s
+	MaxDeliver    int           // JetStream最大投递次数，默认-1，不限制
+	MaxInFlight   int           // 同时处理的最大消息数，JetStream同时作为未确认消息的上限，默认64
+	FetchBatch    int           // pull模式每次拉取的消息数，也是批量消费时每批的最大消息数，默认10
+	FetchTimeout  time.Duration // pull模式每次拉取的等待时间，默认5s
+	HandleTimeout time.Duration // 单条消息的处理超时，默认0，不限制
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Addr:                       "nats://127.0.0.1:4222",
+		DialTimeout:                xtime.Duration("5s"),
+		ReconnectWait:              xtime.Duration("2s"),
+		MaxReconnects:              -1,
+		DrainTimeout:               xtime.Duration("30s"),
+		EnableTraceInterceptor:     true,
+		EnableMetricInterceptor:    true,
+		AccessInterceptorMaxLength: 4096,
+		SlowLogThreshold:           xtime.Duration("500ms"),
+		EnableHealthCheck:          true,
+		HealthCritical:             true,
+		OnFail:                     OnFailPanic,
+	}
+}
+
+// DefaultConsumerConfig 默认消费者配置
+func DefaultConsumerConfig() ConsumerConfig {
+	return ConsumerConfig{
+		Mode:         ModeCore,
+		AckPolicy:    AckExplicit,
+		AckWait:      xtime.Duration("30s"),
+		MaxDeliver:   -1,
+		MaxInFlight:  64,
+		FetchBatch:   10,
+		FetchTimeout: xtime.Duration("5s"),
+	}
+}
+
+// withDefaults 为空的字段使用默认值
+func (c ConsumerConfig) withDefaults() ConsumerConfig {
+	def := DefaultConsumerConfig()
+	if c.Mode == "" {
+		c.Mode = def.Mode
+	}
+	if c.AckPolicy == "" {
+		c.AckPolicy = def.AckPolicy
+	}
+	if c.AckWait <= 0 {
+		c.AckWait = def.AckWait
+	}
+	if c.MaxDeliver == 0 {
+		c.MaxDeliver = def.MaxDeliver
+	}
+	if c.MaxInFlight <= 0 {
+		c.MaxInFlight = def.MaxInFlight
+	}
+	if c.FetchBatch <= 0 {
+		c.FetchBatch = def.FetchBatch
+	}
+	if c.FetchBatch > c.MaxInFlight {
+		c.FetchBatch = c.MaxInFlight
+	}
+	if c.FetchTimeout <= 0 {
+		c.FetchTimeout = def.FetchTimeout
+	}
+	return c
+}
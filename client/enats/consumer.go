@@ -0,0 +1,556 @@
+package enats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+	"github.com/gotomicro/ego/server"
+)
+
+// Handler 消息处理函数
+// JetStream消费时，返回nil时ack，返回错误时nak，消息会重新投递；不需要重新投递时返回 Term(err)
+type Handler func(ctx context.Context, msg *nats.Msg) error
+
+// BatchHandler 批量处理函数，pull模式每次拉取的消息一起处理
+// 返回nil时全部ack，返回错误时全部nak；不需要重新投递时返回 Term(err)
+type BatchHandler func(ctx context.Context, msgs []*nats.Msg) error
+
+type termError struct {
+	err error
+}
+
+func (e *termError) Error() string { return e.err.Error() }
+func (e *termError) Unwrap() error { return e.err }
+
+// Term 处理失败，并且消息不需要重新投递
+func Term(err error) error {
+	return &termError{err: err}
+}
+
+// Consumer 消费者，实现了 server.Server，由ego管理启动和停止
+// 优雅停止时drain订阅，等待已经收到的消息处理完
+type Consumer struct {
+	name    string
+	comp    *Component
+	config  ConsumerConfig
+	handler Handler
+	batch   BatchHandler // 不为空时批量处理
+	logger  *elog.Component
+	tracer  *etrace.Tracer
+
+	sem      chan struct{}  // 限制同时处理的消息数
+	wg       sync.WaitGroup // 正在处理的消息
+	mu       sync.Mutex
+	sub      *nats.Subscription
+	running  atomic.Bool
+	started  atomic.Bool
+	stopOnce sync.Once
+	stopping chan struct{} // 开始停止时关闭
+	done     chan struct{} // Start返回时关闭
+}
+
+// Consumer 创建消费者，使用配置中Consumers[name]的配置
+func (c *Component) Consumer(name string, handler Handler) *Consumer {
+	config := c.config.Consumers[name].withDefaults()
+	return &Consumer{
+		name:     name,
+		comp:     c,
+		config:   config,
+		handler:  handler,
+		logger:   c.logger.With(elog.FieldName(name), elog.String("subject", config.Subject)),
+		tracer:   etrace.NewTracer(trace.SpanKindConsumer),
+		sem:      make(chan struct{}, config.MaxInFlight),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// BatchConsumer 创建批量消费者，只支持pull模式，每批最多FetchBatch条消息
+// 批量消费的span通过link关联每条消息的生产者span
+func (c *Component) BatchConsumer(name string, handler BatchHandler) *Consumer {
+	consumer := c.Consumer(name, nil)
+	consumer.batch = handler
+	return consumer
+}
+
+// Name 配置名称
+func (c *Consumer) Name() string {
+	return c.comp.name + "." + c.name
+}
+
+// PackageName 包名
+func (c *Consumer) PackageName() string {
+	return PackageName
+}
+
+// Init 检查配置
+func (c *Consumer) Init() error {
+	if c.config.Subject == "" {
+		return fmt.Errorf("enats: consumer %s subject is empty", c.name)
+	}
+	if c.batch != nil && c.config.Mode != ModePull {
+		return fmt.Errorf("enats: consumer %s batch consume requires pull mode", c.name)
+	}
+	switch c.config.Mode {
+	case ModeCore:
+		return nil
+	case ModePush, ModePull:
+	default:
+		return fmt.Errorf("enats: consumer %s unsupported mode %s", c.name, c.config.Mode)
+	}
+	if c.comp.js == nil {
+		return fmt.Errorf("enats: consumer %s mode %s, %w", c.name, c.config.Mode, ErrJetStreamDisabled)
+	}
+	if c.config.Mode == ModePull && c.config.Durable == "" {
+		return fmt.Errorf("enats: consumer %s pull mode requires durable", c.name)
+	}
+	if _, err := ackPolicy(c.config.AckPolicy); err != nil {
+		return fmt.Errorf("enats: consumer %s, %w", c.name, err)
+	}
+	return nil
+}
+
+// Start 订阅主题，阻塞直到停止
+func (c *Consumer) Start() (err error) {
+	c.started.Store(true)
+	defer close(c.done)
+	if err := c.Init(); err != nil {
+		return err
+	}
+	var sub *nats.Subscription
+	switch c.config.Mode {
+	case ModeCore:
+		sub, err = c.comp.nc.QueueSubscribe(c.config.Subject, c.config.Queue, c.dispatch)
+	case ModePush:
+		sub, err = c.subscribePush()
+	case ModePull:
+		sub, err = c.subscribePull()
+	}
+	if err != nil {
+		return fmt.Errorf("enats: consumer %s subscribe fail, %w", c.name, err)
+	}
+	c.mu.Lock()
+	c.sub = sub
+	c.mu.Unlock()
+	c.running.Store(true)
+	defer c.running.Store(false)
+	c.logger.Info("nats consume start", elog.String("mode", c.config.Mode))
+
+	if c.config.Mode == ModePull {
+		c.fetchLoop(sub)
+		return nil
+	}
+	<-c.stopping
+	return nil
+}
+
+func (c *Consumer) subscribePush() (*nats.Subscription, error) {
+	if c.config.Durable == "" {
+		// 临时消费者由客户端创建，停止时删除
+		opts, err := c.subOptions()
+		if err != nil {
+			return nil, err
+		}
+		return c.comp.js.QueueSubscribe(c.config.Subject, c.config.Queue, c.dispatch, opts...)
+	}
+	stream, err := c.ensureConsumer()
+	if err != nil {
+		return nil, err
+	}
+	return c.comp.js.QueueSubscribe(c.config.Subject, c.config.Queue, c.dispatch, nats.Bind(stream, c.config.Durable), nats.ManualAck())
+}
+
+func (c *Consumer) subscribePull() (*nats.Subscription, error) {
+	stream, err := c.ensureConsumer()
+	if err != nil {
+		return nil, err
+	}
+	return c.comp.js.PullSubscribe(c.config.Subject, c.config.Durable, nats.Bind(stream, c.config.Durable))
+}
+
+func (c *Consumer) subOptions() ([]nats.SubOpt, error) {
+	policy, err := ackPolicy(c.config.AckPolicy)
+	if err != nil {
+		return nil, err
+	}
+	opts := []nats.SubOpt{
+		nats.ManualAck(),
+		nats.AckWait(c.config.AckWait),
+		nats.MaxDeliver(c.config.MaxDeliver),
+		nats.MaxAckPending(c.config.MaxInFlight),
+	}
+	switch policy {
+	case nats.AckNonePolicy:
+		opts = append(opts, nats.AckNone())
+	case nats.AckAllPolicy:
+		opts = append(opts, nats.AckAll())
+	default:
+		opts = append(opts, nats.AckExplicit())
+	}
+	return opts, nil
+}
+
+// ensureConsumer 持久化消费者不存在时创建，由组件创建的消费者绑定后不会在停止时被删除
+func (c *Consumer) ensureConsumer() (string, error) {
+	stream := c.config.Stream
+	if stream == "" {
+		var err error
+		stream, err = c.comp.js.StreamNameBySubject(c.config.Subject)
+		if err != nil {
+			return "", fmt.Errorf("find stream by subject fail, %w", err)
+		}
+	}
+	_, err := c.comp.js.ConsumerInfo(stream, c.config.Durable)
+	if err == nil {
+		return stream, nil
+	}
+	if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return "", err
+	}
+	policy, err := ackPolicy(c.config.AckPolicy)
+	if err != nil {
+		return "", err
+	}
+	cc := &nats.ConsumerConfig{
+		Durable:       c.config.Durable,
+		FilterSubject: c.config.Subject,
+		AckPolicy:     policy,
+		AckWait:       c.config.AckWait,
+		MaxDeliver:    c.config.MaxDeliver,
+		MaxAckPending: c.config.MaxInFlight,
+	}
+	if c.config.Mode == ModePush {
+		cc.DeliverSubject = nats.NewInbox()
+		cc.DeliverGroup = c.config.Queue
+	}
+	if _, err := c.comp.js.AddConsumer(stream, cc); err != nil {
+		return "", fmt.Errorf("add consumer fail, %w", err)
+	}
+	c.logger.Info("nats consumer created", elog.String("stream", stream), elog.String("durable", c.config.Durable))
+	return stream, nil
+}
+
+// fetchLoop pull模式循环拉取消息，直到停止
+func (c *Consumer) fetchLoop(sub *nats.Subscription) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for !c.isStopping() {
+		fetchCtx, fetchCancel := context.WithTimeout(ctx, c.config.FetchTimeout)
+		msgs, err := sub.Fetch(c.config.FetchBatch, nats.Context(fetchCtx))
+		fetchCancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) && !errors.Is(err, nats.ErrTimeout) {
+			c.logger.Warn("nats fetch fail", elog.FieldErr(err))
+			select {
+			case <-c.stopping:
+			case <-time.After(c.comp.config.ReconnectWait):
+			}
+		}
+		if c.batch != nil && len(msgs) > 0 {
+			c.dispatchBatch(msgs)
+			continue
+		}
+		for _, msg := range msgs {
+			c.dispatch(msg)
+		}
+	}
+}
+
+// dispatchBatch 处理一批消息，超过MaxInFlight时阻塞
+func (c *Consumer) dispatchBatch(msgs []*nats.Msg) {
+	c.sem <- struct{}{}
+	c.wg.Add(1)
+	go func() {
+		defer func() {
+			<-c.sem
+			c.wg.Done()
+		}()
+		c.handleBatch(msgs)
+	}()
+}
+
+func (c *Consumer) handleBatch(msgs []*nats.Msg) {
+	ctx := context.Background()
+	if c.config.HandleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.HandleTimeout)
+		defer cancel()
+	}
+	var span trace.Span
+	if c.comp.config.EnableTraceInterceptor {
+		carriers := make([]propagation.TextMapCarrier, 0, len(msgs))
+		for _, msg := range msgs {
+			carriers = append(carriers, headerCarrier(msg.Header))
+		}
+		ctx, span = c.tracer.StartBatch(ctx, "nats.consume "+c.config.Subject, carriers, trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationKey.String(c.config.Subject),
+			semconv.MessagingOperationProcess,
+		))
+	}
+
+	beg := time.Now()
+	err := c.invokeBatch(ctx, msgs)
+	var ackErr error
+	for _, msg := range msgs {
+		if e := c.ack(msg, err); e != nil && ackErr == nil {
+			ackErr = e
+		}
+	}
+	cost := time.Since(beg)
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	for _, msg := range msgs {
+		c.observe(ctx, msg, cost, err, ackErr)
+	}
+}
+
+// dispatch 并发处理消息，超过MaxInFlight时阻塞
+func (c *Consumer) dispatch(msg *nats.Msg) {
+	c.sem <- struct{}{}
+	c.wg.Add(1)
+	go func() {
+		defer func() {
+			<-c.sem
+			c.wg.Done()
+		}()
+		c.handle(msg)
+	}()
+}
+
+func (c *Consumer) handle(msg *nats.Msg) {
+	ctx := context.Background()
+	if c.config.HandleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.HandleTimeout)
+		defer cancel()
+	}
+	var span trace.Span
+	if c.comp.config.EnableTraceInterceptor {
+		ctx, span = c.tracer.Start(ctx, "nats.consume "+msg.Subject, headerCarrier(msg.Header), trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationKey.String(msg.Subject),
+			semconv.MessagingOperationProcess,
+		))
+	}
+
+	beg := time.Now()
+	err := c.invoke(ctx, msg)
+	ackErr := c.ack(msg, err)
+	cost := time.Since(beg)
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	c.observe(ctx, msg, cost, err, ackErr)
+}
+
+// ack 根据处理结果确认消息，核心NATS和AckNone不需要确认
+func (c *Consumer) ack(msg *nats.Msg, err error) error {
+	if c.config.Mode == ModeCore || c.config.AckPolicy == AckNone {
+		return nil
+	}
+	var term *termError
+	switch {
+	case err == nil:
+		return msg.Ack()
+	case errors.As(err, &term):
+		return msg.Term()
+	default:
+		return msg.Nak()
+	}
+}
+
+// invoke 执行处理函数，panic转换为错误
+func (c *Consumer) invoke(ctx context.Context, msg *nats.Msg) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("enats: handler panic, %v", rec)
+		}
+	}()
+	return c.handler(ctx, msg)
+}
+
+// invokeBatch 执行批量处理函数，panic转换为错误
+func (c *Consumer) invokeBatch(ctx context.Context, msgs []*nats.Msg) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("enats: batch handler panic, %v", rec)
+		}
+	}()
+	return c.batch(ctx, msgs)
+}
+
+func (c *Consumer) observe(ctx context.Context, msg *nats.Msg, cost time.Duration, err error, ackErr error) {
+	config := c.comp.config
+	if config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ServerHandleCounter.Inc(emetric.TypeNATS, c.config.Subject, msg.Subject, code, "", "")
+		emetric.ServerHandleHistogram.Observe(cost.Seconds(), emetric.TypeNATS, c.config.Subject, msg.Subject, "")
+	}
+
+	isSlow := config.SlowLogThreshold > 0 && cost > config.SlowLogThreshold
+	if !config.EnableAccessInterceptor && !isSlow && err == nil && ackErr == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod("consume " + c.config.Subject),
+		elog.FieldKey(msg.Subject),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+	}
+	if config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(msg.Data, config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case ackErr != nil:
+		c.logger.Error("access", append(fields, elog.String("ackErr", ackErr.Error()), elog.FieldErr(err))...)
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+}
+
+// Stop 立即取消订阅，没有确认的JetStream消息会重新投递
+func (c *Consumer) Stop() error {
+	c.stop()
+	if sub := c.subscription(); sub != nil && sub.IsValid() {
+		return sub.Unsubscribe()
+	}
+	return nil
+}
+
+// GracefulStop drain订阅，等待已经收到的消息处理完，超时后立即停止
+func (c *Consumer) GracefulStop(ctx context.Context) error {
+	c.stop()
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		if sub := c.subscription(); sub != nil && sub.IsValid() {
+			// 连接断开时无法drain，直接取消订阅
+			if !c.comp.nc.IsConnected() {
+				_ = sub.Unsubscribe()
+			} else if err := sub.Drain(); err != nil {
+				c.logger.Warn("nats drain subscription fail", elog.FieldErr(err))
+				_ = sub.Unsubscribe()
+			}
+			for sub.IsValid() {
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		if c.started.Load() {
+			<-c.done
+		}
+		c.wg.Wait()
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		_ = c.Stop()
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) subscription() *nats.Subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sub
+}
+
+func (c *Consumer) stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopping)
+	})
+}
+
+func (c *Consumer) isStopping() bool {
+	select {
+	case <-c.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Health 是否正在订阅
+func (c *Consumer) Health() bool {
+	return c.running.Load() && !c.isStopping() && c.comp.nc.IsConnected()
+}
+
+// Info 服务信息
+func (c *Consumer) Info() *server.ServiceInfo {
+	addr := strings.Split(c.comp.config.Addr, ",")[0]
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		addr = u.Host
+	}
+	info := server.ApplyOptions(
+		server.WithName(c.Name()),
+		server.WithScheme("nats"),
+		server.WithAddress(addr),
+		server.WithKind(constant.ServiceConsumer),
+		server.WithMetaData("subject", c.config.Subject),
+		server.WithMetaData("mode", c.config.Mode),
+		server.WithMetaData("maxInFlight", strconv.Itoa(c.config.MaxInFlight)),
+	)
+	return &info
+}
+
+func ackPolicy(policy string) (nats.AckPolicy, error) {
+	switch policy {
+	case AckExplicit:
+		return nats.AckExplicitPolicy, nil
+	case AckAll:
+		return nats.AckAllPolicy, nil
+	case AckNone:
+		return nats.AckNonePolicy, nil
+	default:
+		return nats.AckExplicitPolicy, fmt.Errorf("unsupported ack policy %s", policy)
+	}
+}
@@ -0,0 +1,137 @@
+package enats
+
+import (
+	"github.com/nats-io/nats.go"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config      *Config
+	name        string
+	logger      *elog.Component
+	natsOptions []nats.Option
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithAddr 设置连接地址
+func WithAddr(addr string) Option {
+	return func(c *Container) {
+		c.config.Addr = addr
+	}
+}
+
+// WithConsumer 设置消费者配置
+func WithConsumer(name string, config ConsumerConfig) Option {
+	return func(c *Container) {
+		if c.config.Consumers == nil {
+			c.config.Consumers = make(map[string]ConsumerConfig)
+		}
+		c.config.Consumers[name] = config
+	}
+}
+
+// WithNatsOptions 设置原生的连接选项，在配置之后应用
+func WithNatsOptions(opts ...nats.Option) Option {
+	return func(c *Container) {
+		c.natsOptions = append(c.natsOptions, opts...)
+	}
+}
+
+// Build 建立连接，断开后由客户端自动重连，并在ego停止后drain连接
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	opts := append(c.connectOptions(), c.natsOptions...)
+	nc, err := nats.Connect(c.config.Addr, opts...)
+	if err != nil {
+		if c.config.OnFail == OnFailPanic {
+			c.logger.Panic("connect nats fail", elog.FieldErr(err))
+		}
+		c.logger.Error("connect nats fail", elog.FieldErr(err))
+		// 启动时连接失败，在后台继续重连
+		nc, err = nats.Connect(c.config.Addr, append(opts, nats.RetryOnFailedConnect(true))...)
+		if err != nil {
+			c.logger.Panic("connect nats fail", elog.FieldErr(err))
+		}
+	}
+	comp := newComponent(c.name, c.config, c.logger, nc)
+	if c.config.EnableJetStream {
+		js, err := nc.JetStream()
+		if err != nil {
+			c.logger.Panic("init jetstream fail", elog.FieldErr(err))
+		}
+		comp.js = js
+	}
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("nats"), ehealth.WithCritical(c.config.HealthCritical))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) connectOptions() []nats.Option {
+	opts := []nats.Option{
+		nats.Name(c.name),
+		nats.Timeout(c.config.DialTimeout),
+		nats.ReconnectWait(c.config.ReconnectWait),
+		nats.MaxReconnects(c.config.MaxReconnects),
+		nats.DrainTimeout(c.config.DrainTimeout),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				c.logger.Warn("nats disconnected", elog.FieldErr(err))
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			c.logger.Info("nats reconnected", elog.FieldAddr(nc.ConnectedUrlRedacted()))
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			fields := []elog.Field{elog.FieldErr(err)}
+			if sub != nil {
+				fields = append(fields, elog.String("subject", sub.Subject))
+			}
+			c.logger.Error("nats async error", fields...)
+		}),
+	}
+	if c.config.Username != "" {
+		opts = append(opts, nats.UserInfo(c.config.Username, c.config.Password))
+	}
+	if c.config.Token != "" {
+		opts = append(opts, nats.Token(c.config.Token))
+	}
+	if c.config.CredentialsFile != "" {
+		opts = append(opts, nats.UserCredentials(c.config.CredentialsFile))
+	}
+	return opts
+}
@@ -0,0 +1,242 @@
+package enotify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "client.enotify"
+
+var (
+	// ErrQueueFull 异步发送队列已满
+	ErrQueueFull = errors.New("enotify: queue full")
+	// ErrClosed 组件已经关闭
+	ErrClosed = errors.New("enotify: closed")
+	// ErrRateLimited 通道发送太频繁
+	ErrRateLimited = errors.New("enotify: rate limited")
+)
+
+var defaultComponent atomic.Pointer[Component]
+
+// Message 通知消息
+type Message struct {
+	Title     string      // 标题
+	Content   string      // 内容，设置Template时使用模板渲染的内容
+	Template  string      // 模板名
+	Data      interface{} // 模板数据
+	Providers []string    // 发送的通道名，为空时发送到所有通道
+}
+
+// templateData 模板变量
+type templateData struct {
+	App      string
+	Hostname string
+	Title    string
+	Data     interface{}
+}
+
+// Component 通知组件
+type Component struct {
+	name      string
+	config    *Config
+	logger    *elog.Component
+	providers map[string]Provider
+	limiters  map[string]*limiter
+	templates map[string]*template.Template
+	queue     chan *Message
+	mu        sync.RWMutex // 保证关闭队列之后不会再写入
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, providers map[string]Provider, templates map[string]*template.Template) *Component {
+	c := &Component{
+		name:      name,
+		config:    config,
+		logger:    logger,
+		providers: providers,
+		limiters:  make(map[string]*limiter),
+		templates: templates,
+		queue:     make(chan *Message, config.QueueSize),
+	}
+	for name := range providers {
+		c.limiters[name] = newLimiter(config.RateLimit, config.RateBurst)
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		c.wg.Add(1)
+		go c.work()
+	}
+	return c
+}
+
+// Default 返回最后一次Build的组件，没有Build时返回nil
+func Default() *Component {
+	return defaultComponent.Load()
+}
+
+func setDefault(c *Component) {
+	defaultComponent.Store(c)
+}
+
+// Send 同步发送消息，返回所有通道的聚合错误
+func (c *Component) Send(ctx context.Context, msg *Message) error {
+	content, err := c.render(msg)
+	if err != nil {
+		return err
+	}
+	rendered := *msg
+	rendered.Content = content
+
+	names := msg.Providers
+	if len(names) == 0 {
+		names = make([]string, 0, len(c.providers))
+		for name := range c.providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	errs := make([]error, 0)
+	for _, name := range names {
+		if err := c.sendTo(ctx, name, &rendered); err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Notify 异步发送消息，队列满或者组件关闭时丢弃消息并返回错误
+func (c *Component) Notify(msg *Message) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return ErrClosed
+	}
+	select {
+	case c.queue <- msg:
+		return nil
+	default:
+		c.logger.Warn("notify queue full, drop message", elog.String("title", msg.Title))
+		return ErrQueueFull
+	}
+}
+
+// Close 停止接收消息，等待队列中的消息发送完成
+func (c *Component) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+	c.mu.Unlock()
+	c.wg.Wait()
+	return nil
+}
+
+func (c *Component) work() {
+	defer c.wg.Done()
+	for msg := range c.queue {
+		if err := c.Send(context.Background(), msg); err != nil {
+			c.logger.Error("notify fail", elog.String("title", msg.Title), elog.FieldErr(err))
+		}
+	}
+}
+
+func (c *Component) sendTo(ctx context.Context, name string, msg *Message) error {
+	provider, ok := c.providers[name]
+	if !ok {
+		return fmt.Errorf("provider not found")
+	}
+	if !c.limiters[name].allow() {
+		return ErrRateLimited
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.SendTimeout)
+	defer cancel()
+	return provider.Send(ctx, msg)
+}
+
+func (c *Component) render(msg *Message) (string, error) {
+	if msg.Template == "" {
+		return msg.Content, nil
+	}
+	tmpl, ok := c.templates[msg.Template]
+	if !ok {
+		return "", fmt.Errorf("enotify: template %s not found", msg.Template)
+	}
+	hostname, _ := os.Hostname()
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, templateData{
+		App:      eapp.Name(),
+		Hostname: hostname,
+		Title:    msg.Title,
+		Data:     msg.Data,
+	}); err != nil {
+		return "", fmt.Errorf("enotify: render template %s fail, %w", msg.Template, err)
+	}
+	return buf.String(), nil
+}
+
+// Alert 使用默认组件异步发送告警，没有Build组件时不发送，框架在崩溃、配置热加载失败时调用
+func Alert(title string, content string) {
+	c := Default()
+	if c == nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	_ = c.Notify(&Message{
+		Title:   fmt.Sprintf("[%s] %s", eapp.Name(), title),
+		Content: fmt.Sprintf("%s\n\nhostname: %s\ntime: %s", content, hostname, time.Now().Format(time.RFC3339)),
+	})
+}
+
+// limiter 令牌桶限流
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *limiter) allow() bool {
+	if l.rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,73 @@
+package enotify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	mu   sync.Mutex
+	msgs []Message
+	err  error
+}
+
+func (m *mockProvider) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.msgs = append(m.msgs, *msg)
+	return m.err
+}
+
+func TestSend(t *testing.T) {
+	ok := &mockProvider{}
+	fail := &mockProvider{err: errors.New("send fail")}
+	comp := DefaultContainer().Build(
+		WithProvider("ok", ok),
+		WithProvider("fail", fail),
+		WithTemplate("crash", "{{.Title}}: {{.Data.reason}}"),
+	)
+	defer comp.Close()
+
+	err := comp.Send(context.Background(), &Message{Title: "crash", Template: "crash", Data: map[string]string{"reason": "oom"}})
+	assert.ErrorContains(t, err, "provider fail: send fail")
+	assert.Equal(t, "crash: oom", ok.msgs[0].Content)
+
+	// 指定通道
+	assert.NoError(t, comp.Send(context.Background(), &Message{Title: "hello", Providers: []string{"ok"}}))
+	assert.Len(t, ok.msgs, 2)
+	assert.Len(t, fail.msgs, 1)
+
+	assert.Error(t, comp.Send(context.Background(), &Message{Template: "unknown"}))
+}
+
+func TestRateLimit(t *testing.T) {
+	p := &mockProvider{}
+	c := DefaultContainer()
+	c.config.RateLimit = 0.001
+	c.config.RateBurst = 2
+	comp := c.Build(WithProvider("p", p))
+	defer comp.Close()
+
+	assert.NoError(t, comp.Send(context.Background(), &Message{Title: "1"}))
+	assert.NoError(t, comp.Send(context.Background(), &Message{Title: "2"}))
+	assert.ErrorIs(t, comp.Send(context.Background(), &Message{Title: "3"}), ErrRateLimited)
+}
+
+func TestNotify(t *testing.T) {
+	p := &mockProvider{}
+	comp := DefaultContainer().Build(WithProvider("p", p))
+	assert.Equal(t, comp, Default())
+
+	assert.NoError(t, comp.Notify(&Message{Title: "async"}))
+	Alert("reload fail", "bad config")
+	// Close 等待队列中的消息发送完成
+	assert.NoError(t, comp.Close())
+	assert.Len(t, p.msgs, 2)
+	assert.Equal(t, "async", p.msgs[0].Title)
+	assert.Contains(t, p.msgs[1].Content, "bad config")
+	assert.ErrorIs(t, comp.Notify(&Message{Title: "closed"}), ErrClosed)
+}
@@ -0,0 +1,45 @@
+package enotify
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 通知配置
+type Config struct {
+	QueueSize   int                       // 异步发送队列长度，队列满时丢弃消息，默认1024
+	Workers     int                       // 异步发送的协程数，默认1
+	RateLimit   float64                   // 每个通道每秒允许发送的消息数，小于等于0时不限制，默认1
+	RateBurst   int                       // 每个通道允许突发发送的消息数，默认10
+	SendTimeout time.Duration             // 单个通道发送一条消息的超时，默认5s
+	Templates   map[string]string         // 消息模板，key为模板名，支持 {{.App}} {{.Hostname}} {{.Title}} {{.Data}} 变量
+	Providers   map[string]ProviderConfig // 通知通道，key为通道名
+}
+
+// ProviderConfig 通知通道配置
+type ProviderConfig struct {
+	Type     string            // 通道类型，smtp、webhook、dingtalk、feishu、slack
+	URL      string            // webhook、dingtalk、feishu、slack的机器人地址
+	Secret   string            // dingtalk、feishu的加签密钥，为空时不加签
+	Headers  map[string]string // webhook自定义的header
+	Host     string            // smtp服务地址
+	Port     int               // smtp端口，465使用TLS连接，其他端口在服务端支持时使用STARTTLS，默认25
+	Username string            // smtp用户名，为空时不认证
+	Password string            // smtp密码
+	From     string            // 发件人
+	To       []string          // 收件人
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		QueueSize:   1024,
+		Workers:     1,
+		RateLimit:   1,
+		RateBurst:   10,
+		SendTimeout: xtime.Duration("5s"),
+		Templates:   make(map[string]string),
+		Providers:   make(map[string]ProviderConfig),
+	}
+}
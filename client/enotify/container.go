@@ -0,0 +1,86 @@
+package enotify
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config    *Config
+	name      string
+	logger    *elog.Component
+	providers map[string]Provider
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config:    DefaultConfig(),
+		logger:    elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		providers: make(map[string]Provider),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 构建通知组件，启动异步发送协程，并设置为默认组件，框架使用默认组件发送告警
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	providers, templates, err := c.build()
+	if err != nil {
+		c.logger.Panic("build enotify fail", elog.FieldErr(err))
+	}
+	comp := newComponent(c.name, c.config, c.logger, providers, templates)
+	setDefault(comp)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) build() (map[string]Provider, map[string]*template.Template, error) {
+	providers := make(map[string]Provider)
+	for name, config := range c.config.Providers {
+		factory := getProviderFactory(config.Type)
+		if factory == nil {
+			return nil, nil, fmt.Errorf("unknown provider type %s, name: %s", config.Type, name)
+		}
+		provider, err := factory(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("build provider %s fail, %w", name, err)
+		}
+		providers[name] = provider
+	}
+	for name, provider := range c.providers {
+		providers[name] = provider
+	}
+
+	templates := make(map[string]*template.Template)
+	for name, tmpl := range c.config.Templates {
+		t, err := template.New(name).Parse(tmpl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse template %s fail, %w", name, err)
+		}
+		templates[name] = t
+	}
+	return providers, templates, nil
+}
@@ -0,0 +1,15 @@
+package enotify
+
+// WithProvider 设置自定义的通知通道，同名时覆盖配置中的通道
+func WithProvider(name string, provider Provider) Option {
+	return func(c *Container) {
+		c.providers[name] = provider
+	}
+}
+
+// WithTemplate 设置消息模板，同名时覆盖配置中的模板
+func WithTemplate(name string, tmpl string) Option {
+	return func(c *Container) {
+		c.config.Templates[name] = tmpl
+	}
+}
@@ -0,0 +1,237 @@
+package enotify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/eextension"
+)
+
+const (
+	// ProviderSMTP 邮件
+	ProviderSMTP = "smtp"
+	// ProviderWebhook 通用webhook，POST JSON
+	ProviderWebhook = "webhook"
+	// ProviderDingTalk 钉钉机器人
+	ProviderDingTalk = "dingtalk"
+	// ProviderFeishu 飞书机器人
+	ProviderFeishu = "feishu"
+	// ProviderSlack Slack Incoming Webhook
+	ProviderSlack = "slack"
+)
+
+// Provider 通知通道
+type Provider interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ProviderFactory 根据配置创建通知通道
+type ProviderFactory func(config ProviderConfig) (Provider, error)
+
+var (
+	factoryMu sync.RWMutex
+	factories = make(map[string]ProviderFactory)
+)
+
+func init() {
+	RegisterProvider(ProviderSMTP, newSMTPProvider)
+	RegisterProvider(ProviderWebhook, newWebhookProvider)
+	RegisterProvider(ProviderDingTalk, newDingTalkProvider)
+	RegisterProvider(ProviderFeishu, newFeishuProvider)
+	RegisterProvider(ProviderSlack, newSlackProvider)
+}
+
+// RegisterProvider 注册通知通道类型，例如短信通道，重复注册会覆盖
+func RegisterProvider(typ string, factory ProviderFactory) {
+	_ = eextension.Register(eextension.KindNotifyProvider, typ)
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factories[typ] = factory
+}
+
+func getProviderFactory(typ string) ProviderFactory {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+	return factories[typ]
+}
+
+// httpClient 通道共用的http client，超时由ctx控制
+var httpClient = &http.Client{}
+
+// postJSON 发送JSON请求，返回响应body
+func postJSON(ctx context.Context, rawURL string, headers map[string]string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d, body: %s", resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+type webhookProvider struct {
+	config ProviderConfig
+}
+
+func newWebhookProvider(config ProviderConfig) (Provider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook url is empty")
+	}
+	return &webhookProvider{config: config}, nil
+}
+
+// Send 发送 {"title","content","app","hostname","time"}
+func (p *webhookProvider) Send(ctx context.Context, msg *Message) error {
+	hostname, _ := os.Hostname()
+	_, err := postJSON(ctx, p.config.URL, p.config.Headers, map[string]interface{}{
+		"title":    msg.Title,
+		"content":  msg.Content,
+		"app":      eapp.Name(),
+		"hostname": hostname,
+		"time":     time.Now().Unix(),
+	})
+	return err
+}
+
+type dingTalkProvider struct {
+	config ProviderConfig
+	now    func() time.Time
+}
+
+func newDingTalkProvider(config ProviderConfig) (Provider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("dingtalk url is empty")
+	}
+	return &dingTalkProvider{config: config, now: time.Now}, nil
+}
+
+// Send 发送markdown消息，配置Secret时按照钉钉的规则加签
+func (p *dingTalkProvider) Send(ctx context.Context, msg *Message) error {
+	target, err := url.Parse(p.config.URL)
+	if err != nil {
+		return err
+	}
+	if p.config.Secret != "" {
+		timestamp := strconv.FormatInt(p.now().UnixMilli(), 10)
+		query := target.Query()
+		query.Set("timestamp", timestamp)
+		query.Set("sign", hmacBase64([]byte(p.config.Secret), timestamp+"\n"+p.config.Secret))
+		target.RawQuery = query.Encode()
+	}
+	body, err := postJSON(ctx, target.String(), nil, map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": msg.Title,
+			"text":  "### " + msg.Title + "\n\n" + msg.Content,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var res struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("decode dingtalk response fail, %w", err)
+	}
+	if res.ErrCode != 0 {
+		return fmt.Errorf("dingtalk errcode %d, errmsg: %s", res.ErrCode, res.ErrMsg)
+	}
+	return nil
+}
+
+type feishuProvider struct {
+	config ProviderConfig
+	now    func() time.Time
+}
+
+func newFeishuProvider(config ProviderConfig) (Provider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("feishu url is empty")
+	}
+	return &feishuProvider{config: config, now: time.Now}, nil
+}
+
+// Send 发送文本消息，配置Secret时按照飞书的规则加签
+func (p *feishuProvider) Send(ctx context.Context, msg *Message) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content": map[string]string{
+			"text": msg.Title + "\n" + msg.Content,
+		},
+	}
+	if p.config.Secret != "" {
+		timestamp := strconv.FormatInt(p.now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = hmacBase64([]byte(timestamp+"\n"+p.config.Secret), "")
+	}
+	body, err := postJSON(ctx, p.config.URL, nil, payload)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return fmt.Errorf("decode feishu response fail, %w", err)
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("feishu code %d, msg: %s", res.Code, res.Msg)
+	}
+	return nil
+}
+
+type slackProvider struct {
+	config ProviderConfig
+}
+
+func newSlackProvider(config ProviderConfig) (Provider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("slack url is empty")
+	}
+	return &slackProvider{config: config}, nil
+}
+
+// Send 发送文本消息
+func (p *slackProvider) Send(ctx context.Context, msg *Message) error {
+	_, err := postJSON(ctx, p.config.URL, nil, map[string]string{
+		"text": "*" + msg.Title + "*\n" + msg.Content,
+	})
+	return err
+}
+
+func hmacBase64(key []byte, data string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
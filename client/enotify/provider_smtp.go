@@ -0,0 +1,93 @@
+package enotify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type smtpProvider struct {
+	config ProviderConfig
+}
+
+func newSMTPProvider(config ProviderConfig) (Provider, error) {
+	if config.Host == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("smtp host, from and to are required")
+	}
+	if config.Port == 0 {
+		config.Port = 25
+	}
+	return &smtpProvider{config: config}, nil
+}
+
+// Send 发送纯文本邮件
+func (p *smtpProvider) Send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(p.config.Host, strconv.Itoa(p.config.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: p.config.Host}
+	if p.config.Port == 465 {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, p.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && p.config.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if p.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(p.config.From); err != nil {
+		return err
+	}
+	for _, to := range p.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMail(p.config.From, p.config.To, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func buildMail(from string, to []string, msg *Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Content, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,77 @@
+package enotify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviders(t *testing.T) {
+	var (
+		query   string
+		payload map[string]interface{}
+		header  string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		header = r.Header.Get("X-Token")
+		body, _ := io.ReadAll(r.Body)
+		payload = map[string]interface{}{}
+		_ = json.Unmarshal(body, &payload)
+		_, _ = w.Write([]byte(`{"errcode":0,"code":0}`))
+	}))
+	defer server.Close()
+	now := func() time.Time { return time.Unix(1700000000, 0) }
+	msg := &Message{Title: "title", Content: "content"}
+
+	p, err := newDingTalkProvider(ProviderConfig{URL: server.URL + "?access_token=token", Secret: "secret"})
+	assert.NoError(t, err)
+	p.(*dingTalkProvider).now = now
+	assert.NoError(t, p.Send(context.Background(), msg))
+	assert.Contains(t, query, "access_token=token")
+	assert.Contains(t, query, "timestamp=1700000000000")
+	assert.Contains(t, query, "sign=")
+	assert.Equal(t, "markdown", payload["msgtype"])
+
+	p, err = newFeishuProvider(ProviderConfig{URL: server.URL, Secret: "secret"})
+	assert.NoError(t, err)
+	p.(*feishuProvider).now = now
+	assert.NoError(t, p.Send(context.Background(), msg))
+	assert.Equal(t, "1700000000", payload["timestamp"])
+	assert.Equal(t, hmacBase64([]byte("1700000000\nsecret"), ""), payload["sign"])
+
+	p, _ = newSlackProvider(ProviderConfig{URL: server.URL})
+	assert.NoError(t, p.Send(context.Background(), msg))
+	assert.Equal(t, "*title*\ncontent", payload["text"])
+
+	p, _ = newWebhookProvider(ProviderConfig{URL: server.URL, Headers: map[string]string{"X-Token": "t"}})
+	assert.NoError(t, p.Send(context.Background(), msg))
+	assert.Equal(t, "content", payload["content"])
+	assert.Equal(t, "t", header)
+
+	_, err = newWebhookProvider(ProviderConfig{})
+	assert.Error(t, err)
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+	}))
+	defer server.Close()
+	p, _ := newDingTalkProvider(ProviderConfig{URL: server.URL})
+	assert.ErrorContains(t, p.Send(context.Background(), &Message{}), "sign not match")
+}
+
+func TestBuildMail(t *testing.T) {
+	mail := string(buildMail("a@ego.dev", []string{"b@ego.dev", "c@ego.dev"}, &Message{Title: "告警", Content: "line1\nline2"}))
+	assert.Contains(t, mail, "To: b@ego.dev, c@ego.dev\r\n")
+	assert.Contains(t, mail, "Subject: =?UTF-8?b?")
+	assert.True(t, strings.HasSuffix(mail, "\r\n\r\nline1\r\nline2"))
+}
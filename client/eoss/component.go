@@ -0,0 +1,484 @@
+package eoss
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.eoss"
+
+// metaPrefix 自定义元数据的header前缀
+const metaPrefix = "X-Amz-Meta-"
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("eoss: object not found")
+
+// Error 对象存储服务端返回的错误
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	RequestID  string `xml:"RequestId"`
+}
+
+// Error ...
+func (e *Error) Error() string {
+	return fmt.Sprintf("eoss: status = %d code = %s message = %s request_id = %s", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// Is 404错误可以使用 errors.Is(err, ErrNotFound) 判断
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	Metadata     map[string]string
+}
+
+// ListResult 列举对象的结果
+type ListResult struct {
+	Objects   []ObjectInfo
+	NextToken string // 下一页的token，为空时表示没有更多对象
+}
+
+// Component 对象存储组件
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	client *http.Client
+	signer *signer
+	base   *url.URL
+	tracer *etrace.Tracer
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	base, err := url.Parse(config.Endpoint)
+	if err != nil {
+		logger.Panic("parse endpoint fail", elog.FieldErr(err))
+	}
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		client: config.httpClient,
+		signer: &signer{
+			accessKeyID:     config.AccessKeyID,
+			accessKeySecret: config.AccessKeySecret,
+			sessionToken:    config.SessionToken,
+			region:          config.Region,
+			now:             time.Now,
+		},
+		base:   base,
+		tracer: etrace.NewTracer(trace.SpanKindClient),
+	}
+}
+
+// Put 流式上传对象，对象大于PartSize时自动使用分片上传，内存中最多缓存一个分片
+func (c *Component) Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) error {
+	o := &putOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	buf := make([]byte, c.config.PartSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("eoss: read object body fail, %w", err)
+	}
+	// 不足一个分片，直接上传
+	if err != nil {
+		_, err = c.do(ctx, "PutObject", http.MethodPut, key, nil, putHeader(o), buf[:n])
+		return err
+	}
+	return c.multipartPut(ctx, key, r, buf, o)
+}
+
+// PutObject 上传对象，用于实现 egin.ObjectStorage，size仅作为参考
+func (c *Component) PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return c.Put(ctx, key, r, WithContentType(contentType))
+}
+
+// Get 获取对象，返回的body需要调用方关闭
+func (c *Component) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	resp, err := c.do(ctx, "GetObject", http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, objectInfo(key, resp), nil
+}
+
+// Head 获取对象信息
+func (c *Component) Head(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := c.do(ctx, "HeadObject", http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return objectInfo(key, resp), nil
+}
+
+// Delete 删除对象，对象不存在时不返回错误
+func (c *Component) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, "DeleteObject", http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteObject 删除对象，用于实现 egin.ObjectStorage
+func (c *Component) DeleteObject(ctx context.Context, key string) error {
+	return c.Delete(ctx, key)
+}
+
+// List 列举前缀下的对象，token为上一页返回的NextToken，limit小于等于0时使用服务端默认值
+func (c *Component) List(ctx context.Context, prefix string, token string, limit int) (*ListResult, error) {
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if token != "" {
+		query.Set("continuation-token", token)
+	}
+	if limit > 0 {
+		query.Set("max-keys", strconv.Itoa(limit))
+	}
+	resp, err := c.do(ctx, "ListObjectsV2", http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Contents []struct {
+			Key          string
+			Size         int64
+			ETag         string
+			LastModified time.Time
+		}
+		IsTruncated           bool
+		NextContinuationToken string
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("eoss: decode list result fail, %w", err)
+	}
+	result := &ListResult{Objects: make([]ObjectInfo, 0, len(res.Contents))}
+	for _, content := range res.Contents {
+		result.Objects = append(result.Objects, ObjectInfo{
+			Key:          content.Key,
+			Size:         content.Size,
+			ETag:         strings.Trim(content.ETag, `"`),
+			LastModified: content.LastModified,
+		})
+	}
+	if res.IsTruncated {
+		result.NextToken = res.NextContinuationToken
+	}
+	return result, nil
+}
+
+// Presign 生成预签名URL，客户端可以直接使用该URL上传或者下载对象，expires小于等于0时使用PresignExpires
+func (c *Component) Presign(method string, key string, expires time.Duration) string {
+	if expires <= 0 {
+		expires = c.config.PresignExpires
+	}
+	return c.signer.presign(method, c.objectURL(key, nil), expires)
+}
+
+// multipartPut 分片上传，失败时取消分片上传
+func (c *Component) multipartPut(ctx context.Context, key string, r io.Reader, buf []byte, o *putOptions) error {
+	resp, err := c.do(ctx, "CreateMultipartUpload", http.MethodPost, key, url.Values{"uploads": {""}}, putHeader(o), nil)
+	if err != nil {
+		return err
+	}
+	var initRes struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initRes)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("eoss: decode create multipart upload result fail, %w", err)
+	}
+
+	if err = c.uploadParts(ctx, key, initRes.UploadID, r, buf); err != nil {
+		abortResp, abortErr := c.do(context.WithoutCancel(ctx), "AbortMultipartUpload", http.MethodDelete, key, url.Values{"uploadId": {initRes.UploadID}}, nil, nil)
+		if abortErr != nil {
+			c.logger.Error("abort multipart upload fail", elog.FieldKey(key), elog.FieldErr(abortErr))
+		} else {
+			_ = abortResp.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+func (c *Component) uploadParts(ctx context.Context, key string, uploadID string, r io.Reader, buf []byte) error {
+	parts := make([]completePart, 0)
+	n := len(buf)
+	for number := 1; n > 0; number++ {
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		resp, err := c.do(ctx, "UploadPart", http.MethodPut, key, query, nil, buf[:n])
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		parts = append(parts, completePart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		var readErr error
+		n, readErr = io.ReadFull(r, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("eoss: read object body fail, %w", readErr)
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+		Parts   []completePart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, "CompleteMultipartUpload", http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// CompleteMultipartUpload 可能返回200，但是body中包含错误
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(respBody, []byte("<Error>")) {
+		return parseError(resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// do 签名并发送请求，对网络错误、5xx、429进行重试，非2xx的响应转换为 *Error
+func (c *Component) do(ctx context.Context, op string, method string, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	var (
+		beg  = time.Now()
+		resp *http.Response
+		err  error
+	)
+	if c.config.EnableTraceInterceptor {
+		var span trace.Span
+		ctx, span = c.tracer.Start(ctx, "eoss."+op, nil, trace.WithAttributes(
+			semconv.PeerServiceKey.String(c.name),
+			semconv.HTTPMethodKey.String(method),
+			attribute.String("eoss.bucket", c.config.Bucket),
+			attribute.String("eoss.key", key),
+		))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+	defer func() {
+		c.observe(ctx, op, key, beg, resp, err)
+	}()
+
+	payloadHash := hashHex(body)
+	interval := c.config.RetryInterval
+	for attempt := 0; ; attempt++ {
+		resp, err = c.send(ctx, method, key, query, header, body, payloadHash)
+		if !c.retryable(resp, err) || attempt >= c.config.MaxRetries {
+			break
+		}
+		etrace.AddRetryEvent(ctx, attempt+1, retryReason(resp, err))
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return nil, err
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		err = parseError(resp.StatusCode, respBody)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Component) send(ctx context.Context, method string, key string, query url.Values, header http.Header, body []byte, payloadHash string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(key, query).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.signer.sign(req, payloadHash)
+	return c.client.Do(req)
+}
+
+func (c *Component) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryReason 重试的原因，用于链路追踪
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
+
+// objectURL 生成对象的URL，key为空时为存储桶的URL
+func (c *Component) objectURL(key string, query url.Values) *url.URL {
+	u := *c.base
+	key = strings.TrimPrefix(key, "/")
+	if c.config.ForcePathStyle {
+		u.Path = "/" + c.config.Bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = c.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = encodePath(u.Path)
+	if query != nil {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+func (c *Component) observe(ctx context.Context, op string, key string, beg time.Time, resp *http.Response, err error) {
+	cost := time.Since(beg)
+	code := "OK"
+	if err != nil {
+		code = "error"
+		var ossErr *Error
+		if errors.As(err, &ossErr) {
+			code = http.StatusText(ossErr.StatusCode)
+		}
+	}
+	if c.config.EnableMetricInterceptor {
+		emetric.ClientHandleCounter.Inc(emetric.TypeOSS, c.name, op, c.config.Bucket, code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeOSS, c.name, op, c.config.Bucket)
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	// 对象不存在是正常的业务情况，不记录错误日志
+	isErr := err != nil && !errors.Is(err, ErrNotFound)
+	if !c.config.EnableAccessInterceptor && !isSlow && !isErr {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(op),
+		elog.FieldKey(key),
+		elog.FieldCost(cost),
+		elog.FieldCode(int32(statusCode(resp, err))),
+		elog.FieldEvent(event),
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	if isErr {
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+		return
+	}
+	if isSlow {
+		c.logger.Warn("access", fields...)
+		return
+	}
+	c.logger.Info("access", fields...)
+}
+
+func statusCode(resp *http.Response, err error) int {
+	var ossErr *Error
+	if errors.As(err, &ossErr) {
+		return ossErr.StatusCode
+	}
+	if resp != nil {
+		return resp.StatusCode
+	}
+	return 0
+}
+
+func parseError(statusCode int, body []byte) error {
+	e := &Error{StatusCode: statusCode}
+	if len(body) > 0 {
+		_ = xml.Unmarshal(body, e)
+	}
+	if e.Code == "" {
+		e.Code = http.StatusText(statusCode)
+	}
+	return e
+}
+
+func putHeader(o *putOptions) http.Header {
+	header := http.Header{}
+	if o.contentType != "" {
+		header.Set("Content-Type", o.contentType)
+	}
+	for k, v := range o.metadata {
+		header.Set(metaPrefix+k, v)
+	}
+	return header
+}
+
+func objectInfo(key string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+		Metadata:    make(map[string]string),
+	}
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	for k, v := range resp.Header {
+		if strings.HasPrefix(k, metaPrefix) && len(v) > 0 {
+			info.Metadata[strings.ToLower(strings.TrimPrefix(k, metaPrefix))] = v[0]
+		}
+	}
+	return info
+}
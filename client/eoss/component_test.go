@@ -0,0 +1,175 @@
+package eoss
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 内存实现的S3服务端，只实现了组件用到的接口
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	types    map[string]string
+	uploads  map[string]map[int][]byte
+	failures int // 接下来的请求返回503的次数
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, types: map[string]string{}, uploads: map[string]map[int][]byte{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !strings.HasPrefix(r.Header.Get("Authorization"), signAlgorithm) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/bucket"), "/")
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		keys := make([]string, 0)
+		for k := range f.objects {
+			if strings.HasPrefix(k, query.Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		_, _ = fmt.Fprint(w, "<ListBucketResult>")
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", k, len(f.objects[k]))
+		}
+		_, _ = fmt.Fprint(w, "</ListBucketResult>")
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.uploads["id-"+key] = map[int][]byte{}
+		f.types[key] = r.Header.Get("Content-Type")
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>id-%s</UploadId></InitiateMultipartUploadResult>", key)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][number] = body
+		w.Header().Set("ETag", `"etag-`+query.Get("partNumber")+`"`)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []completePart `xml:"Part"`
+		}
+		_ = xml.Unmarshal(body, &complete)
+		data := make([]byte, 0)
+		for _, part := range complete.Parts {
+			data = append(data, f.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		f.objects[key] = data
+		delete(f.uploads, query.Get("uploadId"))
+		_, _ = fmt.Fprint(w, "<CompleteMultipartUploadResult/>")
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, "<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("X-Amz-Meta-Owner", "ego")
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestComponent(t *testing.T, f *fakeS3) *Component {
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return DefaultContainer().Build(
+		func(c *Container) {
+			c.config.Provider = ProviderMinIO
+			c.config.RetryInterval = 0
+		},
+		WithEndpoint(server.URL),
+		WithBucket("bucket"),
+		WithCredentials("ak", "sk", ""),
+	)
+}
+
+func TestPutGet(t *testing.T) {
+	f := newFakeS3()
+	comp := newTestComponent(t, f)
+	ctx := context.Background()
+
+	assert.NoError(t, comp.Put(ctx, "dir/a.txt", strings.NewReader("hello ego"), WithContentType("text/plain"), WithMetadata(map[string]string{"owner": "ego"})))
+	body, info, err := comp.Get(ctx, "dir/a.txt")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(body)
+	_ = body.Close()
+	assert.Equal(t, "hello ego", string(data))
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, "ego", info.Metadata["owner"])
+
+	info, err = comp.Head(ctx, "dir/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), info.Size)
+
+	res, err := comp.List(ctx, "dir/", "", 10)
+	assert.NoError(t, err)
+	assert.Len(t, res.Objects, 1)
+	assert.Equal(t, "dir/a.txt", res.Objects[0].Key)
+
+	assert.NoError(t, comp.Delete(ctx, "dir/a.txt"))
+	_, err = comp.Head(ctx, "dir/a.txt")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestMultipartPut(t *testing.T) {
+	f := newFakeS3()
+	comp := newTestComponent(t, f)
+	data := bytes.Repeat([]byte("0123456789"), minPartSize/10*2+7)
+	assert.NoError(t, comp.PutObject(context.Background(), "big.bin", bytes.NewReader(data), -1, "application/octet-stream"))
+	assert.Equal(t, data, f.objects["big.bin"])
+	assert.Equal(t, "application/octet-stream", f.types["big.bin"])
+	assert.Empty(t, f.uploads)
+}
+
+func TestRetry(t *testing.T) {
+	f := newFakeS3()
+	comp := newTestComponent(t, f)
+	f.failures = 2
+	assert.NoError(t, comp.Put(context.Background(), "a.txt", strings.NewReader("hello")))
+	assert.Equal(t, []byte("hello"), f.objects["a.txt"])
+
+	f.failures = 10
+	err := comp.Put(context.Background(), "b.txt", strings.NewReader("hello"))
+	var ossErr *Error
+	assert.True(t, errors.As(err, &ossErr))
+	assert.Equal(t, http.StatusServiceUnavailable, ossErr.StatusCode)
+}
+
+func TestPresignURL(t *testing.T) {
+	comp := newTestComponent(t, newFakeS3())
+	signed := comp.Presign(http.MethodGet, "a b.txt", 0)
+	assert.Contains(t, signed, "/bucket/a%20b.txt?")
+	assert.Contains(t, signed, "X-Amz-Expires=900")
+}
@@ -0,0 +1,59 @@
+package eoss
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// ProviderS3 AWS S3
+	ProviderS3 = "s3"
+	// ProviderOSS 阿里云OSS，使用OSS的S3兼容接口
+	ProviderOSS = "oss"
+	// ProviderMinIO MinIO
+	ProviderMinIO = "minio"
+)
+
+// minPartSize S3要求除最后一个分片外，分片大小不能小于5MB
+const minPartSize = 5 << 20
+
+// Config 对象存储配置
+type Config struct {
+	Provider                string        // 存储类型，s3、oss、minio，默认s3
+	Endpoint                string        // 服务地址，例如 http://127.0.0.1:9000，s3、oss为空时根据Region生成
+	Region                  string        // 区域，默认us-east-1，oss例如cn-hangzhou
+	Bucket                  string        // 存储桶
+	AccessKeyID             string        // AccessKey ID
+	AccessKeySecret         string        // AccessKey Secret
+	SessionToken            string        // 临时凭证的token，默认为空
+	ForcePathStyle          bool          // 是否使用path风格访问存储桶，minio默认开启，oss不支持
+	ResponseHeaderTimeout   time.Duration // 等待响应header的超时，不限制body的传输时间，默认10s
+	MaxRetries              int           // 网络错误、5xx、429时的最大重试次数，默认3
+	RetryInterval           time.Duration // 重试的初始间隔，每次重试翻倍，默认100ms
+	PartSize                int64         `unit:"size"` // 分片上传的分片大小，超过该大小的对象使用分片上传，最小5MB，默认8MB
+	PresignExpires          time.Duration // 预签名URL默认的有效期，默认15m
+	SlowLogThreshold        time.Duration // 慢日志记录的阈值，默认500ms
+	EnableTraceInterceptor  bool          // 是否开启链路追踪，默认开启
+	EnableMetricInterceptor bool          // 是否开启监控，默认开启
+	EnableAccessInterceptor bool          // 是否开启记录请求数据，默认不开启
+	httpClient              *http.Client  // 自定义http client
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Provider:                ProviderS3,
+		Region:                  "us-east-1",
+		ResponseHeaderTimeout:   xtime.Duration("10s"),
+		MaxRetries:              3,
+		RetryInterval:           xtime.Duration("100ms"),
+		PartSize:                8 << 20,
+		PresignExpires:          xtime.Duration("15m"),
+		SlowLogThreshold:        xtime.Duration("500ms"),
+		EnableTraceInterceptor:  true,
+		EnableMetricInterceptor: true,
+		EnableAccessInterceptor: false,
+	}
+}
@@ -0,0 +1,95 @@
+package eoss
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build constructs a specific component from container.
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if err := c.normalize(); err != nil {
+		c.logger.Panic("build eoss fail", elog.FieldErr(err))
+	}
+	if c.config.httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = c.config.ResponseHeaderTimeout
+		c.config.httpClient = &http.Client{Transport: transport}
+	}
+	c.logger = c.logger.With(elog.FieldAddr(c.config.Endpoint))
+	comp := newComponent(c.name, c.config, c.logger)
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+// normalize 根据存储类型补全配置
+func (c *Container) normalize() error {
+	config := c.config
+	if config.Bucket == "" {
+		return fmt.Errorf("bucket is empty")
+	}
+	switch config.Provider {
+	case ProviderS3:
+		if config.Endpoint == "" {
+			config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+		}
+	case ProviderOSS:
+		if config.Endpoint == "" {
+			config.Endpoint = "https://oss-" + strings.TrimPrefix(config.Region, "oss-") + ".aliyuncs.com"
+		}
+		// OSS只支持虚拟主机风格
+		config.ForcePathStyle = false
+	case ProviderMinIO:
+		if config.Endpoint == "" {
+			return fmt.Errorf("minio endpoint is empty")
+		}
+		config.ForcePathStyle = true
+	default:
+		return fmt.Errorf("unknown provider %s", config.Provider)
+	}
+	if !strings.Contains(config.Endpoint, "://") {
+		config.Endpoint = "https://" + config.Endpoint
+	}
+	if config.PartSize < minPartSize {
+		config.PartSize = minPartSize
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package eoss
+
+import (
+	"net/http"
+)
+
+// WithEndpoint 设置服务地址
+func WithEndpoint(endpoint string) Option {
+	return func(c *Container) {
+		c.config.Endpoint = endpoint
+	}
+}
+
+// WithBucket 设置存储桶
+func WithBucket(bucket string) Option {
+	return func(c *Container) {
+		c.config.Bucket = bucket
+	}
+}
+
+// WithCredentials 设置访问凭证
+func WithCredentials(accessKeyID, accessKeySecret, sessionToken string) Option {
+	return func(c *Container) {
+		c.config.AccessKeyID = accessKeyID
+		c.config.AccessKeySecret = accessKeySecret
+		c.config.SessionToken = sessionToken
+	}
+}
+
+// WithHTTPClient 设置自定义http client
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Container) {
+		c.config.httpClient = client
+	}
+}
+
+// PutOption 上传选项
+type PutOption func(o *putOptions)
+
+type putOptions struct {
+	contentType string
+	metadata    map[string]string
+}
+
+// WithContentType 设置对象的Content-Type
+func WithContentType(contentType string) PutOption {
+	return func(o *putOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithMetadata 设置对象的自定义元数据，以x-amz-meta-保存
+func WithMetadata(metadata map[string]string) PutOption {
+	return func(o *putOptions) {
+		o.metadata = metadata
+	}
+}
@@ -0,0 +1,181 @@
+package eoss
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	signService     = "s3"
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+	// unsignedPayload 预签名URL不对body签名
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash 空body的sha256
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signer AWS Signature Version 4 签名，S3、MinIO、OSS（S3兼容接口）都支持该签名方式
+type signer struct {
+	accessKeyID     string
+	accessKeySecret string
+	sessionToken    string
+	region          string
+	now             func() time.Time
+}
+
+// sign 对请求签名，payloadHash为body的sha256
+func (s *signer) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		canonicalQuery(req.URL.Query()),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	signature := s.signature(now, canonicalRequest)
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+s.accessKeyID+"/"+s.scope(now)+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// presign 生成预签名URL，只对host签名
+func (s *signer) presign(method string, u *url.URL, expires time.Duration) string {
+	now := s.now().UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", signAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		encodePath(u.Path),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonicalRequest))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(query)
+	return signed.String()
+}
+
+func (s *signer) scope(now time.Time) string {
+	return now.Format(shortDateFormat) + "/" + s.region + "/" + signService + "/aws4_request"
+}
+
+func (s *signer) signature(now time.Time, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		signAlgorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+	key := hmacSHA256([]byte("AWS4"+s.accessKeySecret), now.Format(shortDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, signService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalHeaders 签名host、content-type、content-md5、range以及所有x-amz-开头的header
+func canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for k, v := range req.Header {
+		key := strings.ToLower(k)
+		if key == "content-type" || key == "content-md5" || key == "range" || strings.HasPrefix(key, "x-amz-") {
+			values[key] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k + ":" + values[k] + "\n")
+	}
+	return b.String(), strings.Join(keys, ";")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string{}, query[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, encode(k, true)+"="+encode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func encodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return encode(path, false)
+}
+
+// encode 按照SigV4的规则编码，只保留非保留字符，encodeSlash为false时保留/
+func encode(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexChars[c>>4])
+		b.WriteByte(hexChars[c&15])
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,33 @@
+package epubsub
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrClosed 组件或者后端已经关闭
+var ErrClosed = errors.New("epubsub: closed")
+
+// Message 后端投递的原始消息
+type Message struct {
+	ID      string
+	Topic   string
+	Payload []byte
+}
+
+// Backend 消息的存储后端
+type Backend interface {
+	// Publish 发布消息，返回消息ID
+	Publish(ctx context.Context, topic string, payload []byte) (string, error)
+	// CreateGroup 创建消费组，消费组只接收创建之后发布的消息，已经存在时不做任何操作
+	CreateGroup(ctx context.Context, topic, group string) error
+	// Fetch 拉取消费组的消息，优先返回投递后超过ackTimeout没有确认的消息，没有消息时最多等待block
+	Fetch(ctx context.Context, topic, group, consumer string, count int, block, ackTimeout time.Duration) ([]Message, error)
+	// Ack 确认消息，确认后不会再投递
+	Ack(ctx context.Context, topic, group string, ids ...string) error
+	// Ping 检查后端是否可用
+	Ping(ctx context.Context) error
+	// Close 关闭后端连接
+	Close() error
+}
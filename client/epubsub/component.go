@@ -0,0 +1,260 @@
+package epubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "client.epubsub"
+
+// Component 发布订阅组件
+type Component struct {
+	name    string
+	config  *Config
+	logger  *elog.Component
+	backend Backend
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, backend Backend) *Component {
+	return &Component{
+		name:    name,
+		config:  config,
+		logger:  logger,
+		backend: backend,
+		subs:    make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish 发布消息，消息使用JSON编码
+func Publish[T any](ctx context.Context, c *Component, topic string, msg T) (string, error) {
+	beg := time.Now()
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("epubsub: marshal message fail, %w", err)
+	}
+	id, err := c.backend.Publish(ctx, topic, payload)
+	c.metric("publish", topic, err, beg)
+	if err != nil {
+		return "", fmt.Errorf("epubsub: publish %s fail, %w", topic, err)
+	}
+	return id, nil
+}
+
+// Handler 消息处理函数，返回nil时确认消息，返回错误时消息在AckTimeout之后重新投递
+type Handler[T any] func(ctx context.Context, msg T) error
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(s *Subscription)
+
+// WithGroup 设置消费组，同一个消费组内的订阅者分摊消息，不同的消费组各自收到全部消息
+func WithGroup(group string) SubscribeOption {
+	return func(s *Subscription) {
+		s.group = group
+	}
+}
+
+// WithConcurrency 设置同时处理的消息数
+func WithConcurrency(n int) SubscribeOption {
+	return func(s *Subscription) {
+		s.concurrency = n
+	}
+}
+
+// Subscription 订阅，由组件管理订阅goroutine，组件关闭时停止
+type Subscription struct {
+	comp        *Component
+	topic       string
+	group       string
+	consumer    string
+	concurrency int
+	handle      func(ctx context.Context, msg Message) error
+	logger      *elog.Component
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe 订阅主题，消费组创建之后发布的消息至少投递一次
+// 消息解码失败时无法重试，记录错误日志并确认
+func Subscribe[T any](c *Component, topic string, handler Handler[T], opts ...SubscribeOption) (*Subscription, error) {
+	s := &Subscription{
+		comp:        c,
+		topic:       topic,
+		group:       c.config.Group,
+		consumer:    eapp.AppInstance(),
+		concurrency: c.config.Concurrency,
+		done:        make(chan struct{}),
+	}
+	if s.group == "" {
+		s.group = eapp.Name()
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = 1
+	}
+	s.logger = c.logger.With(elog.String("topic", topic), elog.String("group", s.group))
+	s.handle = func(ctx context.Context, msg Message) error {
+		var val T
+		if err := json.Unmarshal(msg.Payload, &val); err != nil {
+			s.logger.Error("unmarshal message fail, drop it", elog.String("id", msg.ID), elog.FieldErr(err))
+			return nil
+		}
+		return handler(ctx, val)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	if err := c.backend.CreateGroup(ctx, topic, s.group); err != nil {
+		return nil, fmt.Errorf("epubsub: create group %s fail, %w", s.group, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	c.subs[s] = struct{}{}
+	var runCtx context.Context
+	runCtx, s.cancel = context.WithCancel(context.Background())
+	go s.run(runCtx)
+	return s, nil
+}
+
+// Topic 订阅的主题
+func (s *Subscription) Topic() string {
+	return s.topic
+}
+
+// Group 消费组
+func (s *Subscription) Group() string {
+	return s.group
+}
+
+// Close 停止订阅，等待正在处理的消息处理完
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		s.comp.mu.Lock()
+		delete(s.comp.subs, s)
+		s.comp.mu.Unlock()
+	})
+	<-s.done
+}
+
+func (s *Subscription) run(ctx context.Context) {
+	defer close(s.done)
+	config := s.comp.config
+	for {
+		msgs, err := s.comp.backend.Fetch(ctx, s.topic, s.group, s.consumer, config.FetchCount, config.BlockTimeout, config.AckTimeout)
+		if ctx.Err() != nil || errors.Is(err, ErrClosed) {
+			return
+		}
+		if err != nil {
+			s.logger.Error("fetch message fail", elog.FieldErr(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(config.BlockTimeout):
+			}
+			continue
+		}
+		s.dispatch(msgs)
+	}
+}
+
+// dispatch 并发处理一批消息，全部处理完之后再拉取下一批
+func (s *Subscription) dispatch(msgs []Message) {
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for _, msg := range msgs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(msg Message) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			s.consume(msg)
+		}(msg)
+	}
+	wg.Wait()
+}
+
+func (s *Subscription) consume(msg Message) {
+	beg := time.Now()
+	// 停止订阅时正在处理的消息继续处理完，因此不使用订阅的ctx
+	ctx := context.Background()
+	if timeout := s.comp.config.HandleTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := s.safeHandle(ctx, msg)
+	s.comp.metric("consume", s.topic, err, beg)
+	if err != nil {
+		s.logger.Error("handle message fail, redeliver later", elog.String("id", msg.ID), elog.FieldErr(err))
+		return
+	}
+	ackCtx, cancel := context.WithTimeout(context.Background(), s.comp.config.Timeout)
+	defer cancel()
+	if err := s.comp.backend.Ack(ackCtx, s.topic, s.group, msg.ID); err != nil {
+		s.logger.Error("ack message fail", elog.String("id", msg.ID), elog.FieldErr(err))
+	}
+}
+
+func (s *Subscription) safeHandle(ctx context.Context, msg Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("epubsub: handler panic, %v", rec)
+		}
+	}()
+	return s.handle(ctx, msg)
+}
+
+// Ping 检查后端是否可用
+func (c *Component) Ping(ctx context.Context) error {
+	return c.backend.Ping(ctx)
+}
+
+// Close 停止所有订阅，等待正在处理的消息处理完，然后关闭后端连接
+func (c *Component) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	subs := make([]*Subscription, 0, len(c.subs))
+	for s := range c.subs {
+		subs = append(subs, s)
+	}
+	c.mu.Unlock()
+	for _, s := range subs {
+		s.Close()
+	}
+	return c.backend.Close()
+}
+
+func (c *Component) metric(action string, topic string, err error, beg time.Time) {
+	if !c.config.EnableMetric {
+		return
+	}
+	code := "ok"
+	if err != nil {
+		code = "error"
+	}
+	emetric.ClientHandleCounter.Inc(emetric.TypePubSub, c.name, action, topic, code)
+	emetric.ClientHandleHistogram.Observe(time.Since(beg).Seconds(), emetric.TypePubSub, c.name, action, topic)
+}
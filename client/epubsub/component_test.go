@@ -0,0 +1,164 @@
+package epubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+type order struct {
+	ID    int    `json:"id"`
+	State string `json:"state"`
+}
+
+func newTestComponents(t *testing.T, config *Config) map[string]*Component {
+	s := miniredis.RunT(t)
+	comps := map[string]*Component{
+		BackendMemory: newComponent("memory", config, elog.EgoLogger, NewMemoryBackend(config.MaxLen)),
+		BackendRedis:  newComponent("redis", config, elog.EgoLogger, NewRedisBackend(redis.NewClient(&redis.Options{Addr: s.Addr()}))),
+	}
+	for _, comp := range comps {
+		t.Cleanup(func() { _ = comp.Close() })
+	}
+	return comps
+}
+
+func testConfig() *Config {
+	config := DefaultConfig()
+	config.Group = "test"
+	config.BlockTimeout = 20 * time.Millisecond
+	config.AckTimeout = 50 * time.Millisecond
+	return config
+}
+
+type collector struct {
+	mu   sync.Mutex
+	msgs []order
+}
+
+func (c *collector) handle(ctx context.Context, msg order) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.msgs = append(c.msgs, msg)
+	return nil
+}
+
+func (c *collector) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.msgs)
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	for backend, comp := range newTestComponents(t, testConfig()) {
+		t.Run(backend, func(t *testing.T) {
+			a, b, other := &collector{}, &collector{}, &collector{}
+			_, err := Subscribe(comp, "order", a.handle)
+			require.NoError(t, err)
+			_, err = Subscribe(comp, "order", b.handle)
+			require.NoError(t, err)
+			otherSub, err := Subscribe(comp, "order", other.handle, WithGroup("other"))
+			require.NoError(t, err)
+			assert.Equal(t, "other", otherSub.Group())
+
+			for i := 0; i < 10; i++ {
+				_, err := Publish(context.Background(), comp, "order", order{ID: i, State: "paid"})
+				require.NoError(t, err)
+			}
+			// 同一个消费组内分摊消息，不同的消费组各自收到全部消息
+			assert.Eventually(t, func() bool { return a.len()+b.len() == 10 && other.len() == 10 }, time.Second, 5*time.Millisecond)
+			time.Sleep(100 * time.Millisecond)
+			assert.Equal(t, 10, a.len()+b.len())
+			assert.Equal(t, order{ID: 0, State: "paid"}, other.msgs[0])
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	config := testConfig()
+	config.Concurrency = 2
+	for backend, comp := range newTestComponents(t, config) {
+		t.Run(backend, func(t *testing.T) {
+			var attempts int32
+			done := make(chan order, 1)
+			_, err := Subscribe(comp, "retry", func(ctx context.Context, msg order) error {
+				if atomic.AddInt32(&attempts, 1) < 3 {
+					return errors.New("not ready")
+				}
+				if atomic.LoadInt32(&attempts) == 3 {
+					panic("boom")
+				}
+				done <- msg
+				return nil
+			})
+			require.NoError(t, err)
+			_, err = Publish(context.Background(), comp, "retry", order{ID: 1})
+			require.NoError(t, err)
+
+			select {
+			case msg := <-done:
+				assert.Equal(t, 1, msg.ID)
+			case <-time.After(2 * time.Second):
+				t.Fatal("message should be redelivered")
+			}
+			// 确认之后不再投递
+			time.Sleep(150 * time.Millisecond)
+			assert.Equal(t, int32(4), atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	for backend, comp := range newTestComponents(t, testConfig()) {
+		t.Run(backend, func(t *testing.T) {
+			started := make(chan struct{})
+			var finished atomic.Bool
+			sub, err := Subscribe(comp, "close", func(ctx context.Context, msg order) error {
+				close(started)
+				time.Sleep(50 * time.Millisecond)
+				finished.Store(true)
+				return nil
+			})
+			require.NoError(t, err)
+			_, err = Publish(context.Background(), comp, "close", order{ID: 1})
+			require.NoError(t, err)
+			<-started
+			// 停止时等待正在处理的消息处理完
+			require.NoError(t, comp.Close())
+			assert.True(t, finished.Load())
+			sub.Close()
+			_, err = Subscribe(comp, "close", func(ctx context.Context, msg order) error { return nil })
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMemoryMaxLen(t *testing.T) {
+	config := testConfig()
+	config.MaxLen = 3
+	comp := newComponent("memory", config, elog.EgoLogger, NewMemoryBackend(config.MaxLen))
+	defer comp.Close()
+	b := comp.backend
+	require.NoError(t, b.CreateGroup(context.Background(), "trim", "g"))
+	for i := 0; i < 5; i++ {
+		_, err := Publish(context.Background(), comp, "trim", order{ID: i})
+		require.NoError(t, err)
+	}
+	msgs, err := b.Fetch(context.Background(), "trim", "g", "c", 10, time.Millisecond, time.Minute)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	assert.Equal(t, []string{"2", "3", "4"}, ids)
+}
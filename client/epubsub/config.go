@@ -0,0 +1,48 @@
+package epubsub
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// BackendMemory 进程内的消息代理，适用于单实例应用，进程退出后未消费的消息会丢失
+	BackendMemory = "memory"
+	// BackendRedis 基于Redis Streams的实现，适用于多实例应用，同一个消费组内的实例分摊消息
+	BackendRedis = "redis"
+)
+
+// Config 发布订阅配置
+type Config struct {
+	Backend       string        // 存储后端，memory或者redis，默认memory
+	KeyPrefix     string        // Redis Stream key的前缀，默认为epubsub:
+	MaxLen        int64         // 每个主题保留的最大消息数，超过后删除最早的消息，默认100000
+	Group         string        // 默认的消费组，为空时使用应用名
+	AckTimeout    time.Duration // 消息投递后超过该时间没有确认，会重新投递，默认30s
+	FetchCount    int           // 订阅者每次拉取的消息数，默认10
+	BlockTimeout  time.Duration // 没有消息时每次拉取的等待时间，默认1s
+	Concurrency   int           // 订阅者同时处理的消息数，默认1
+	HandleTimeout time.Duration // 单条消息的处理超时，默认0，不限制
+	Timeout       time.Duration // 访问Redis的超时，默认1s
+	RedisAddr     string        // Redis地址
+	RedisUsername string        // Redis用户名
+	RedisPassword string        // Redis密码
+	RedisDB       int           // Redis DB
+	EnableMetric  bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Backend:      BackendMemory,
+		KeyPrefix:    "epubsub:",
+		MaxLen:       100000,
+		AckTimeout:   xtime.Duration("30s"),
+		FetchCount:   10,
+		BlockTimeout: xtime.Duration("1s"),
+		Concurrency:  1,
+		Timeout:      xtime.Duration("1s"),
+		EnableMetric: true,
+	}
+}
@@ -0,0 +1,98 @@
+package epubsub
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config  *Config
+	name    string
+	logger  *elog.Component
+	backend Backend
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithBackend 使用自定义的存储后端，例如NewMemoryBackend、NewRedisBackend
+func WithBackend(backend Backend) Option {
+	return func(c *Container) {
+		c.backend = backend
+	}
+}
+
+// Build 根据配置创建存储后端，ego停止后停止所有订阅并关闭连接
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	if c.backend == nil {
+		c.backend = c.newBackend()
+	}
+	comp := newComponent(c.name, c.config, c.logger, c.backend)
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	if err := comp.Ping(ctx); err != nil {
+		c.logger.Error("ping pubsub backend fail", elog.FieldErr(err))
+	}
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType(c.config.Backend))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+func (c *Container) newBackend() Backend {
+	switch c.config.Backend {
+	case BackendMemory:
+		return NewMemoryBackend(c.config.MaxLen)
+	case BackendRedis:
+		if c.config.RedisAddr == "" {
+			c.logger.Panic("redis addr can not be empty")
+		}
+		client := redis.NewClient(&redis.Options{
+			Addr:         c.config.RedisAddr,
+			Username:     c.config.RedisUsername,
+			Password:     c.config.RedisPassword,
+			DB:           c.config.RedisDB,
+			ReadTimeout:  c.config.Timeout,
+			WriteTimeout: c.config.Timeout,
+		})
+		return &redisBackend{client: client, keyPrefix: c.config.KeyPrefix, maxLen: c.config.MaxLen, owned: true}
+	default:
+		c.logger.Panic("unknown pubsub backend", elog.String("backend", c.config.Backend))
+		return nil
+	}
+}
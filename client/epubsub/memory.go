@@ -0,0 +1,191 @@
+package epubsub
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryBackend 进程内的消息代理，每个主题是一个追加写的日志，消费组记录投递位置和未确认的消息
+type memoryBackend struct {
+	mu     sync.Mutex
+	maxLen int64
+	topics map[string]*memoryTopic
+	closed bool
+	done   chan struct{}
+}
+
+type memoryTopic struct {
+	first   int64 // entries[0]的序号
+	entries [][]byte
+	groups  map[string]*memoryGroup
+	notify  chan struct{} // 发布消息时关闭并替换，唤醒等待的订阅者
+}
+
+type memoryGroup struct {
+	next    int64 // 下一条投递的序号
+	pending map[int64]*memoryPending
+}
+
+type memoryPending struct {
+	payload     []byte
+	deliveredAt time.Time
+}
+
+// NewMemoryBackend 创建进程内的消息代理，maxLen为每个主题保留的最大消息数，小于等于0时不限制
+func NewMemoryBackend(maxLen int64) Backend {
+	return &memoryBackend{
+		maxLen: maxLen,
+		topics: make(map[string]*memoryTopic),
+		done:   make(chan struct{}),
+	}
+}
+
+func (m *memoryBackend) topic(name string) *memoryTopic {
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memoryTopic{groups: make(map[string]*memoryGroup), notify: make(chan struct{})}
+		m.topics[name] = t
+	}
+	return t
+}
+
+func (m *memoryBackend) Publish(ctx context.Context, topic string, payload []byte) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return "", ErrClosed
+	}
+	t := m.topic(topic)
+	seq := t.first + int64(len(t.entries))
+	t.entries = append(t.entries, append([]byte(nil), payload...))
+	if m.maxLen > 0 && int64(len(t.entries)) > m.maxLen {
+		drop := int64(len(t.entries)) - m.maxLen
+		t.entries = append([][]byte(nil), t.entries[drop:]...)
+		t.first += drop
+	}
+	close(t.notify)
+	t.notify = make(chan struct{})
+	return strconv.FormatInt(seq, 10), nil
+}
+
+func (m *memoryBackend) CreateGroup(ctx context.Context, topic, group string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	m.group(m.topic(topic), group)
+	return nil
+}
+
+func (m *memoryBackend) group(t *memoryTopic, name string) *memoryGroup {
+	g, ok := t.groups[name]
+	if !ok {
+		g = &memoryGroup{next: t.first + int64(len(t.entries)), pending: make(map[int64]*memoryPending)}
+		t.groups[name] = g
+	}
+	return g
+}
+
+func (m *memoryBackend) Fetch(ctx context.Context, topic, group, consumer string, count int, block, ackTimeout time.Duration) ([]Message, error) {
+	timer := time.NewTimer(block)
+	defer timer.Stop()
+	for {
+		m.mu.Lock()
+		if m.closed {
+			m.mu.Unlock()
+			return nil, ErrClosed
+		}
+		t := m.topic(topic)
+		msgs := m.fetch(t, m.group(t, group), topic, count, ackTimeout)
+		notify := t.notify
+		m.mu.Unlock()
+		if len(msgs) > 0 {
+			return msgs, nil
+		}
+		select {
+		case <-notify:
+		case <-timer.C:
+			return nil, nil
+		case <-m.done:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch 先重新投递超时未确认的消息，再投递新消息
+func (m *memoryBackend) fetch(t *memoryTopic, g *memoryGroup, topic string, count int, ackTimeout time.Duration) []Message {
+	now := time.Now()
+	expired := make([]int64, 0)
+	for seq, p := range g.pending {
+		if now.Sub(p.deliveredAt) >= ackTimeout {
+			expired = append(expired, seq)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+	msgs := make([]Message, 0, count)
+	for _, seq := range expired {
+		if len(msgs) >= count {
+			return msgs
+		}
+		p := g.pending[seq]
+		p.deliveredAt = now
+		msgs = append(msgs, Message{ID: strconv.FormatInt(seq, 10), Topic: topic, Payload: p.payload})
+	}
+	// 超过maxLen被删除的消息不再投递
+	if g.next < t.first {
+		g.next = t.first
+	}
+	for len(msgs) < count && g.next < t.first+int64(len(t.entries)) {
+		payload := t.entries[g.next-t.first]
+		g.pending[g.next] = &memoryPending{payload: payload, deliveredAt: now}
+		msgs = append(msgs, Message{ID: strconv.FormatInt(g.next, 10), Topic: topic, Payload: payload})
+		g.next++
+	}
+	return msgs
+}
+
+func (m *memoryBackend) Ack(ctx context.Context, topic, group string, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.topics[topic]
+	if !ok {
+		return nil
+	}
+	g, ok := t.groups[group]
+	if !ok {
+		return nil
+	}
+	for _, id := range ids {
+		seq, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		delete(g.pending, seq)
+	}
+	return nil
+}
+
+func (m *memoryBackend) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (m *memoryBackend) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.closed {
+		m.closed = true
+		close(m.done)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package epubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// payloadField Stream消息中保存消息内容的字段
+const payloadField = "payload"
+
+// redisBackend 基于Redis Streams的实现，使用消费组记录投递位置，XAUTOCLAIM重新投递超时未确认的消息
+type redisBackend struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	maxLen    int64
+	owned     bool
+}
+
+// NewRedisBackend 使用已有的Redis客户端创建Redis Streams后端
+func NewRedisBackend(client redis.UniversalClient) Backend {
+	config := DefaultConfig()
+	return &redisBackend{client: client, keyPrefix: config.KeyPrefix, maxLen: config.MaxLen}
+}
+
+func (r *redisBackend) stream(topic string) string {
+	return r.keyPrefix + topic
+}
+
+func (r *redisBackend) Publish(ctx context.Context, topic string, payload []byte) (string, error) {
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: r.stream(topic),
+		MaxLen: r.maxLen,
+		Approx: r.maxLen > 0,
+		Values: map[string]interface{}{payloadField: payload},
+	}).Result()
+}
+
+func (r *redisBackend) CreateGroup(ctx context.Context, topic, group string) error {
+	err := r.client.XGroupCreateMkStream(ctx, r.stream(topic), group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+func (r *redisBackend) Fetch(ctx context.Context, topic, group, consumer string, count int, block, ackTimeout time.Duration) ([]Message, error) {
+	stream := r.stream(topic)
+	claimed, _, err := r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  ackTimeout,
+		Start:    "0-0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		return toMessages(topic, claimed), nil
+	}
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]Message, 0, count)
+	for _, s := range streams {
+		msgs = append(msgs, toMessages(topic, s.Messages)...)
+	}
+	return msgs, nil
+}
+
+func toMessages(topic string, items []redis.XMessage) []Message {
+	msgs := make([]Message, 0, len(items))
+	for _, item := range items {
+		payload, _ := item.Values[payloadField].(string)
+		msgs = append(msgs, Message{ID: item.ID, Topic: topic, Payload: []byte(payload)})
+	}
+	return msgs
+}
+
+func (r *redisBackend) Ack(ctx context.Context, topic, group string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.client.XAck(ctx, r.stream(topic), group, ids...).Err()
+}
+
+func (r *redisBackend) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisBackend) Close() error {
+	if !r.owned {
+		return nil
+	}
+	return r.client.Close()
+}
@@ -0,0 +1,138 @@
+package equota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result 配额检查结果
+type Result struct {
+	Allowed    bool          // 是否允许
+	Limit      int           // 配额上限
+	Remaining  int           // 剩余配额
+	ResetAfter time.Duration // 配额完全恢复的时间
+	RetryAfter time.Duration // 被拒绝时，需要等待的时间
+}
+
+// backend 配额计数器
+type backend interface {
+	allow(ctx context.Context, algorithm string, key string, limit Limit, n int, now time.Time) (Result, error)
+	close() error
+}
+
+// tokenBucketResult 根据剩余令牌计算结果
+func tokenBucketResult(allowed bool, tokens float64, limit Limit, n int) Result {
+	rate := float64(limit.Rate) / float64(limit.Window)
+	res := Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((float64(n) - tokens) / rate)
+	}
+	return res
+}
+
+// slidingWindowResult 根据窗口内的请求数计算结果
+func slidingWindowResult(allowed bool, count int, limit Limit, now time.Time) Result {
+	reset := limit.Window - time.Duration(now.UnixNano()%int64(limit.Window))
+	res := Result{
+		Allowed:    allowed,
+		Limit:      limit.Rate,
+		Remaining:  limit.Rate - count,
+		ResetAfter: reset,
+	}
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	if !allowed {
+		res.RetryAfter = reset
+	}
+	return res
+}
+
+// maxMemoryEntries 进程内计数器超过该数量时清理过期的计数器
+const maxMemoryEntries = 10000
+
+// memoryBackend 进程内的计数器，适用于单实例部署和测试
+type memoryBackend struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	tokens   float64
+	last     time.Time
+	window   int64 // 当前窗口的序号
+	curr     int
+	prev     int
+	expireAt time.Time
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{buckets: make(map[string]*memoryEntry)}
+}
+
+func (m *memoryBackend) allow(_ context.Context, algorithm string, key string, limit Limit, n int, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.buckets[key]
+	if !ok {
+		if len(m.buckets) >= maxMemoryEntries {
+			m.sweep(now)
+		}
+		entry = &memoryEntry{tokens: float64(limit.Burst), last: now}
+		m.buckets[key] = entry
+	}
+	entry.expireAt = now.Add(2 * limit.Window)
+
+	if algorithm == AlgorithmSlidingWindow {
+		index := now.UnixNano() / int64(limit.Window)
+		switch {
+		case index == entry.window+1:
+			entry.prev, entry.curr = entry.curr, 0
+		case index > entry.window+1:
+			entry.prev, entry.curr = 0, 0
+		}
+		entry.window = index
+		count := slidingCount(entry.prev, entry.curr, limit, now)
+		if count+n > limit.Rate {
+			return slidingWindowResult(false, count, limit, now), nil
+		}
+		entry.curr += n
+		return slidingWindowResult(true, count+n, limit, now), nil
+	}
+
+	if elapsed := now.Sub(entry.last); elapsed > 0 {
+		entry.tokens += float64(elapsed) * float64(limit.Rate) / float64(limit.Window)
+		if entry.tokens > float64(limit.Burst) {
+			entry.tokens = float64(limit.Burst)
+		}
+		entry.last = now
+	}
+	if entry.tokens < float64(n) {
+		return tokenBucketResult(false, entry.tokens, limit, n), nil
+	}
+	entry.tokens -= float64(n)
+	return tokenBucketResult(true, entry.tokens, limit, n), nil
+}
+
+func (m *memoryBackend) sweep(now time.Time) {
+	for key, entry := range m.buckets {
+		if now.After(entry.expireAt) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func (m *memoryBackend) close() error { return nil }
+
+// slidingCount 上一个窗口按照剩余比例加权，加上当前窗口的请求数
+func slidingCount(prev, curr int, limit Limit, now time.Time) int {
+	elapsed := now.UnixNano() % int64(limit.Window)
+	weight := float64(int64(limit.Window)-elapsed) / float64(limit.Window)
+	return int(float64(prev)*weight) + curr
+}
@@ -0,0 +1,98 @@
+package equota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "client.equota"
+
+// Component 配额组件
+type Component struct {
+	name    string
+	config  *Config
+	logger  *elog.Component
+	backend backend
+
+	mu           sync.RWMutex
+	defaultLimit Limit
+	rules        map[string]Limit
+
+	ginKeyFunc  GinKeyFunc
+	grpcKeyFunc GRPCKeyFunc
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, b backend) *Component {
+	c := &Component{
+		name:    name,
+		config:  config,
+		logger:  logger,
+		backend: b,
+	}
+	c.setLimits(config)
+	return c
+}
+
+// setLimits 更新配额，热加载时调用
+func (c *Component) setLimits(config *Config) {
+	defaultLimit, rules := config.limits()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultLimit = defaultLimit
+	c.rules = rules
+}
+
+// limit 返回key对应的配额，以及用于监控的规则名称
+func (c *Component) limit(key string) (Limit, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if limit, ok := c.rules[key]; ok {
+		return limit, key
+	}
+	return c.defaultLimit, "default"
+}
+
+// Allow 消耗key的一个配额
+func (c *Component) Allow(ctx context.Context, key string) (Result, error) {
+	return c.AllowN(ctx, key, 1)
+}
+
+// AllowN 消耗key的n个配额，配额为0时不限制
+// 计数器不可用时，FailOpen为true返回允许，否则返回拒绝，两种情况都会返回错误
+func (c *Component) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	limit, rule := c.limit(key)
+	if limit.Rate <= 0 {
+		return Result{Allowed: true}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+	res, err := c.backend.allow(ctx, c.config.Algorithm, c.config.KeyPrefix+key, limit, n, time.Now())
+	if err != nil {
+		c.metric(rule, "error")
+		c.logger.Warn("equota allow fail", elog.FieldKey(key), elog.FieldErr(err))
+		return Result{Allowed: c.config.FailOpen, Limit: limit.Rate}, err
+	}
+	if res.Allowed {
+		c.metric(rule, "allowed")
+	} else {
+		c.metric(rule, "limited")
+	}
+	return res, nil
+}
+
+// Close 关闭组件创建的Redis客户端
+func (c *Component) Close() error {
+	return c.backend.close()
+}
+
+func (c *Component) metric(rule string, code string) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.ClientHandleCounter.Inc(emetric.TypeQuota, c.name, "allow", rule, code)
+}
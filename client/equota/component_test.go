@@ -0,0 +1,214 @@
+package equota
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func newTestComponent(b backend, algorithm string, limit Limit, rules ...Rule) *Component {
+	config := DefaultConfig()
+	config.Algorithm = algorithm
+	config.Default = limit
+	config.Rules = rules
+	config.KeySource = "header:X-Tenant-ID"
+	return newComponent("test", config, elog.EgoLogger, b)
+}
+
+func newRedisBackend(t *testing.T) backend {
+	s := miniredis.RunT(t)
+	return &redisBackend{client: redis.NewClient(&redis.Options{Addr: s.Addr()}), owned: true}
+}
+
+func TestTokenBucket(t *testing.T) {
+	for name, b := range map[string]backend{"memory": newMemoryBackend(), "redis": newRedisBackend(t)} {
+		t.Run(name, func(t *testing.T) {
+			limit := Limit{Rate: 10, Window: time.Second, Burst: 3}.normalize()
+			now := time.Now()
+			for i := 0; i < 3; i++ {
+				res, err := b.allow(context.Background(), AlgorithmTokenBucket, "tb", limit, 1, now)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+				assert.Equal(t, 2-i, res.Remaining)
+			}
+			res, err := b.allow(context.Background(), AlgorithmTokenBucket, "tb", limit, 1, now)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 3, res.Limit)
+			assert.InDelta(t, 100*time.Millisecond, res.RetryAfter, float64(time.Millisecond))
+
+			// 每100ms补充一个令牌
+			res, err = b.allow(context.Background(), AlgorithmTokenBucket, "tb", limit, 1, now.Add(100*time.Millisecond))
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.NoError(t, b.close())
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	for name, b := range map[string]backend{"memory": newMemoryBackend(), "redis": newRedisBackend(t)} {
+		t.Run(name, func(t *testing.T) {
+			limit := Limit{Rate: 4, Window: time.Second}.normalize()
+			// 窗口的开始
+			start := time.Unix(time.Now().Unix()+10, 0)
+			for i := 0; i < 4; i++ {
+				res, err := b.allow(context.Background(), AlgorithmSlidingWindow, "sw", limit, 1, start.Add(100*time.Millisecond))
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+			}
+			res, err := b.allow(context.Background(), AlgorithmSlidingWindow, "sw", limit, 1, start.Add(200*time.Millisecond))
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 800*time.Millisecond, res.RetryAfter)
+
+			// 下一个窗口过去一半时，上一个窗口按照一半计算
+			next := start.Add(1500 * time.Millisecond)
+			for i := 0; i < 2; i++ {
+				res, err = b.allow(context.Background(), AlgorithmSlidingWindow, "sw", limit, 1, next)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+			}
+			res, err = b.allow(context.Background(), AlgorithmSlidingWindow, "sw", limit, 1, next)
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	comp := newTestComponent(newMemoryBackend(), AlgorithmTokenBucket, Limit{Rate: 1}, Rule{Key: "vip", Rate: 100})
+	ctx := context.Background()
+
+	res, err := comp.Allow(ctx, "normal")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	res, _ = comp.Allow(ctx, "normal")
+	assert.False(t, res.Allowed)
+
+	for i := 0; i < 10; i++ {
+		res, _ = comp.Allow(ctx, "vip")
+		assert.True(t, res.Allowed)
+	}
+
+	// 配额为0时不限制
+	comp = newTestComponent(newMemoryBackend(), AlgorithmTokenBucket, Limit{})
+	res, _ = comp.Allow(ctx, "any")
+	assert.True(t, res.Allowed)
+	assert.Empty(t, res.headers())
+}
+
+func TestFailOpen(t *testing.T) {
+	s := miniredis.RunT(t)
+	comp := newTestComponent(&redisBackend{client: redis.NewClient(&redis.Options{Addr: s.Addr()})}, AlgorithmTokenBucket, Limit{Rate: 1})
+	s.Close()
+
+	res, err := comp.Allow(context.Background(), "k")
+	assert.Error(t, err)
+	assert.True(t, res.Allowed)
+
+	comp.config.FailOpen = false
+	res, err = comp.Allow(context.Background(), "k")
+	assert.Error(t, err)
+	assert.False(t, res.Allowed)
+}
+
+func TestGinMiddleware(t *testing.T) {
+	comp := newTestComponent(newMemoryBackend(), AlgorithmTokenBucket, Limit{Rate: 1, Window: time.Minute})
+	router := gin.New()
+	router.Use(comp.GinMiddleware())
+	router.GET("/hello", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	do := func(tenant string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderLimit))
+	assert.Equal(t, "0", w.Header().Get(HeaderRemaining))
+	assert.Equal(t, "60", w.Header().Get(HeaderReset))
+
+	w = do("a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get(HeaderRetryAfter))
+
+	// 不同租户的配额互不影响
+	assert.Equal(t, http.StatusOK, do("b").Code)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	comp := newTestComponent(newMemoryBackend(), AlgorithmTokenBucket, Limit{Rate: 1, Window: time.Minute})
+	interceptor := comp.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/hello.Greeter/SayHello"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "a"))
+	res, err := interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// 没有租户时按照对端ip限流
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	assert.Equal(t, "10.0.0.1", comp.grpcKey(ctx, info.FullMethod))
+}
+
+func TestReload(t *testing.T) {
+	conf := `
+[quota]
+algorithm = "slidingWindow"
+[quota.default]
+rate = 1
+`
+	require.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	c := Load("quota")
+	comp := c.Build()
+	limit, rule := comp.limit("vip")
+	assert.Equal(t, 1, limit.Rate)
+	assert.Equal(t, "default", rule)
+
+	conf = `
+[quota]
+algorithm = "slidingWindow"
+[quota.default]
+rate = 2
+[[quota.rules]]
+key = "vip"
+rate = 50
+window = "1m"
+`
+	newConf := econf.New()
+	require.NoError(t, newConf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	assert.NoError(t, c.reload(comp, newConf))
+	limit, rule = comp.limit("vip")
+	assert.Equal(t, Limit{Rate: 50, Window: time.Minute, Burst: 50}, limit)
+	assert.Equal(t, "vip", rule)
+	limit, _ = comp.limit("normal")
+	assert.Equal(t, 2, limit.Rate)
+}
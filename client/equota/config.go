@@ -0,0 +1,77 @@
+package equota
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// AlgorithmTokenBucket 令牌桶，允许Burst大小的突发流量
+	AlgorithmTokenBucket = "tokenBucket"
+	// AlgorithmSlidingWindow 滑动窗口，按照前后两个固定窗口加权计算窗口内的请求数
+	AlgorithmSlidingWindow = "slidingWindow"
+)
+
+// Limit 配额
+type Limit struct {
+	Rate   int           // 每个Window允许的请求数，0为不限制
+	Window time.Duration // 时间窗口，默认1s
+	Burst  int           // 令牌桶的容量，默认等于Rate
+}
+
+// Rule 指定租户、用户或者api key的配额，覆盖默认配额
+type Rule struct {
+	Key    string        // 租户、用户或者api key
+	Rate   int           // 每个Window允许的请求数，0为不限制
+	Window time.Duration // 时间窗口，默认1s
+	Burst  int           // 令牌桶的容量，默认等于Rate
+}
+
+// Config 配额配置，Default和Rules支持热加载
+type Config struct {
+	Algorithm            string        // 限流算法，tokenBucket或者slidingWindow，默认tokenBucket
+	KeySource            string        // 配额key的来源，header:X-Tenant-ID、query:api_key或者ip，为空或者取不到时使用客户端ip
+	Default              Limit         // 默认配额
+	Rules                []Rule        // 指定key的配额
+	KeyPrefix            string        // Redis key的前缀，默认为equota:
+	RedisAddrs           []string      // Redis地址，为空时使用进程内的计数器，多个地址时使用集群模式
+	RedisUsername        string        // Redis用户名
+	RedisPassword        string        // Redis密码
+	RedisDB              int           // Redis DB
+	Timeout              time.Duration // 访问Redis的超时，默认200ms
+	FailOpen             bool          // 计数器不可用时是否放行，默认放行
+	EnableResponseHeader bool          // 是否返回X-RateLimit-*响应头，默认开启
+	EnableMetric         bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Algorithm:            AlgorithmTokenBucket,
+		KeyPrefix:            "equota:",
+		Timeout:              xtime.Duration("200ms"),
+		FailOpen:             true,
+		EnableResponseHeader: true,
+		EnableMetric:         true,
+	}
+}
+
+// limits 返回默认配额和按key索引的配额
+func (config *Config) limits() (Limit, map[string]Limit) {
+	rules := make(map[string]Limit, len(config.Rules))
+	for _, rule := range config.Rules {
+		rules[rule.Key] = Limit{Rate: rule.Rate, Window: rule.Window, Burst: rule.Burst}.normalize()
+	}
+	return config.Default.normalize(), rules
+}
+
+func (l Limit) normalize() Limit {
+	if l.Window <= 0 {
+		l.Window = time.Second
+	}
+	if l.Burst <= 0 {
+		l.Burst = l.Rate
+	}
+	return l
+}
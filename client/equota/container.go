@@ -0,0 +1,121 @@
+package equota
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config      *Config
+	name        string
+	logger      *elog.Component
+	backend     backend
+	ginKeyFunc  GinKeyFunc
+	grpcKeyFunc GRPCKeyFunc
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithRedis 使用已有的Redis客户端作为计数器，组件停止时不会关闭该客户端
+func WithRedis(client redis.UniversalClient) Option {
+	return func(c *Container) {
+		c.backend = &redisBackend{client: client}
+	}
+}
+
+// WithGinKeyFunc 自定义HTTP请求的配额key，例如从登录态中获取租户，返回空时按照KeySource获取
+func WithGinKeyFunc(fn GinKeyFunc) Option {
+	return func(c *Container) {
+		c.ginKeyFunc = fn
+	}
+}
+
+// WithGRPCKeyFunc 自定义gRPC请求的配额key，返回空时按照KeySource获取
+func WithGRPCKeyFunc(fn GRPCKeyFunc) Option {
+	return func(c *Container) {
+		c.grpcKeyFunc = fn
+	}
+}
+
+// Build 创建配额组件，配置变更时热加载Default和Rules
+func (c *Container) Build(opts ...Option) *Component {
+	for _, option := range opts {
+		option(c)
+	}
+	if c.backend == nil {
+		if len(c.config.RedisAddrs) > 0 {
+			c.backend = &redisBackend{
+				client: redis.NewUniversalClient(&redis.UniversalOptions{
+					Addrs:        c.config.RedisAddrs,
+					Username:     c.config.RedisUsername,
+					Password:     c.config.RedisPassword,
+					DB:           c.config.RedisDB,
+					ReadTimeout:  c.config.Timeout,
+					WriteTimeout: c.config.Timeout,
+				}),
+				owned: true,
+			}
+		} else {
+			c.backend = newMemoryBackend()
+		}
+	}
+	if c.config.Algorithm != AlgorithmTokenBucket && c.config.Algorithm != AlgorithmSlidingWindow {
+		c.logger.Panic("unknown quota algorithm", elog.String("algorithm", c.config.Algorithm))
+	}
+
+	comp := newComponent(c.name, c.config, c.logger, c.backend)
+	comp.ginKeyFunc = c.ginKeyFunc
+	comp.grpcKeyFunc = c.grpcKeyFunc
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+
+	if c.name == "" {
+		return comp
+	}
+	// 配额规则错误时返回错误，热加载会回滚到上一个可用的配置
+	econf.OnApply(func(newConf *econf.Configuration) error {
+		return c.reload(comp, newConf)
+	})
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
+
+// reload 重新读取配额
+func (c *Container) reload(comp *Component, newConf *econf.Configuration) error {
+	config := DefaultConfig()
+	if err := newConf.UnmarshalKey(c.name, config); err != nil {
+		c.logger.Error("reload quota config fail", elog.FieldErr(err))
+		return fmt.Errorf("equota reload %s, err: %w", c.name, err)
+	}
+	comp.setLimits(config)
+	c.logger.Info("reload quota limits", elog.Int("rules", len(config.Rules)))
+	return nil
+}
@@ -0,0 +1,160 @@
+package equota
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// HeaderLimit 配额上限
+	HeaderLimit = "X-RateLimit-Limit"
+	// HeaderRemaining 剩余配额
+	HeaderRemaining = "X-RateLimit-Remaining"
+	// HeaderReset 配额完全恢复的秒数
+	HeaderReset = "X-RateLimit-Reset"
+	// HeaderRetryAfter 被拒绝时需要等待的秒数
+	HeaderRetryAfter = "Retry-After"
+)
+
+// GinKeyFunc 从HTTP请求中获取配额key
+type GinKeyFunc func(c *gin.Context) string
+
+// GRPCKeyFunc 从gRPC请求中获取配额key
+type GRPCKeyFunc func(ctx context.Context, fullMethod string) string
+
+// headers 返回需要写入响应的X-RateLimit-*头，没有配额时返回空
+func (res Result) headers() map[string]string {
+	if res.Limit <= 0 {
+		return nil
+	}
+	headers := map[string]string{
+		HeaderLimit:     strconv.Itoa(res.Limit),
+		HeaderRemaining: strconv.Itoa(res.Remaining),
+		HeaderReset:     seconds(res.ResetAfter),
+	}
+	if !res.Allowed {
+		headers[HeaderRetryAfter] = seconds(res.RetryAfter)
+	}
+	return headers
+}
+
+// seconds 向上取整的秒数
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// keySource 解析KeySource配置，返回来源类型和名称
+func (c *Component) keySource() (string, string) {
+	source, name, _ := strings.Cut(c.config.KeySource, ":")
+	return source, name
+}
+
+// ginKey 按照KeySource获取配额key，取不到时使用客户端ip
+func (c *Component) ginKey(ctx *gin.Context) string {
+	if c.ginKeyFunc != nil {
+		if key := c.ginKeyFunc(ctx); key != "" {
+			return key
+		}
+	}
+	source, name := c.keySource()
+	var key string
+	switch source {
+	case "header":
+		key = ctx.GetHeader(name)
+	case "query":
+		key = ctx.Query(name)
+	}
+	if key == "" {
+		key = ctx.ClientIP()
+	}
+	return key
+}
+
+// grpcKey 按照KeySource获取配额key，header从metadata读取，取不到时使用对端ip
+func (c *Component) grpcKey(ctx context.Context, fullMethod string) string {
+	if c.grpcKeyFunc != nil {
+		if key := c.grpcKeyFunc(ctx, fullMethod); key != "" {
+			return key
+		}
+	}
+	if source, name := c.keySource(); source == "header" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(name); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			return p.Addr.String()
+		}
+		return host
+	}
+	return ""
+}
+
+// GinMiddleware 返回egin中间件，超过配额时返回429
+func (c *Component) GinMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		res, _ := c.Allow(ctx.Request.Context(), c.ginKey(ctx))
+		if c.config.EnableResponseHeader {
+			for k, v := range res.headers() {
+				ctx.Header(k, v)
+			}
+		}
+		if !res.Allowed {
+			ctx.AbortWithStatus(http.StatusTooManyRequests)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// UnaryServerInterceptor 返回egrpc拦截器，超过配额时返回ResourceExhausted
+func (c *Component) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := c.checkGRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 返回egrpc流式拦截器，建立流时消耗一个配额
+func (c *Component) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := c.checkGRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func (c *Component) checkGRPC(ctx context.Context, fullMethod string) error {
+	res, _ := c.Allow(ctx, c.grpcKey(ctx, fullMethod))
+	if c.config.EnableResponseHeader {
+		if headers := res.headers(); len(headers) > 0 {
+			md := metadata.MD{}
+			for k, v := range headers {
+				md.Set(k, v)
+			}
+			_ = grpc.SetHeader(ctx, md)
+		}
+	}
+	if !res.Allowed {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
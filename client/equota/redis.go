@@ -0,0 +1,93 @@
+package equota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// tokenBucketScript 按照时间补充令牌，令牌足够时扣减
+	tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+	ts = now
+end
+local allowed = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return {allowed, tostring(tokens)}`)
+	// slidingWindowScript KEYS[1]为当前窗口，KEYS[2]为上一个窗口
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local window = tonumber(ARGV[4])
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+local curr = tonumber(redis.call('GET', KEYS[1]) or '0')
+local count = math.floor(prev * weight) + curr
+if count + n > limit then
+	return {0, count}
+end
+redis.call('INCRBY', KEYS[1], n)
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {1, count + n}`)
+)
+
+// redisBackend 基于Redis的计数器，多个实例共享配额
+type redisBackend struct {
+	client redis.UniversalClient
+	owned  bool
+}
+
+func (r *redisBackend) allow(ctx context.Context, algorithm string, key string, limit Limit, n int, now time.Time) (Result, error) {
+	// 使用hash tag，保证集群模式下同一个key的两个窗口在同一个slot
+	key = "{" + key + "}"
+	if algorithm == AlgorithmSlidingWindow {
+		index := now.UnixNano() / int64(limit.Window)
+		elapsed := now.UnixNano() % int64(limit.Window)
+		weight := float64(int64(limit.Window)-elapsed) / float64(limit.Window)
+		values, err := slidingWindowScript.Run(ctx, r.client,
+			[]string{key + ":" + strconv.FormatInt(index, 10), key + ":" + strconv.FormatInt(index-1, 10)},
+			limit.Rate, weight, n, limit.Window.Milliseconds(),
+		).Int64Slice()
+		if err != nil {
+			return Result{}, fmt.Errorf("equota redis fail, %w", err)
+		}
+		return slidingWindowResult(values[0] == 1, int(values[1]), limit, now), nil
+	}
+
+	// 每毫秒补充的令牌数
+	rate := float64(limit.Rate) / float64(limit.Window.Milliseconds())
+	values, err := tokenBucketScript.Run(ctx, r.client, []string{key}, rate, limit.Burst, now.UnixMilli(), n).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("equota redis fail, %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("equota redis fail, unexpected result %v", values)
+	}
+	allowed, _ := values[0].(int64)
+	tokens, _ := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	return tokenBucketResult(allowed == 1, tokens, limit, n), nil
+}
+
+func (r *redisBackend) close() error {
+	if !r.owned {
+		return nil
+	}
+	return r.client.Close()
+}
@@ -0,0 +1,217 @@
+package erabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "client.erabbitmq"
+
+// ErrNack 服务端拒绝了发布的消息
+var ErrNack = errors.New("erabbitmq: publish nacked")
+
+// Component RabbitMQ组件，负责发布消息，并创建消费者
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	conn   *connection
+	tracer *etrace.Tracer
+
+	mu sync.Mutex    // 保护发布使用的channel
+	ch *amqp.Channel // 发布使用的channel，关闭后在下次发布时重新创建
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		conn:   newConnection(config, logger),
+		tracer: etrace.NewTracer(trace.SpanKindProducer),
+	}
+}
+
+// Channel 创建一个新的channel，用于声明交换机、队列等操作，使用完需要关闭
+func (c *Component) Channel() (*amqp.Channel, error) {
+	return c.conn.channel()
+}
+
+// Publish 发布消息，开启发布确认时等待服务端ack
+func (c *Component) Publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) (err error) {
+	if c.config.PublishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.PublishTimeout)
+		defer cancel()
+	}
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	var span trace.Span
+	if c.config.EnableTraceInterceptor {
+		ctx, span = c.tracer.Start(ctx, "rabbitmq.publish "+exchange, headerCarrier(msg.Headers), trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingDestinationKey.String(exchange),
+			semconv.MessagingRabbitmqRoutingKeyKey.String(routingKey),
+			semconv.PeerServiceKey.String(c.name),
+		))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+	beg := time.Now()
+	err = c.publish(ctx, exchange, routingKey, msg)
+	c.observe(ctx, exchange, routingKey, msg, time.Since(beg), err)
+	return err
+}
+
+func (c *Component) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch, err := c.publishChannel()
+	if err != nil {
+		return err
+	}
+	if !c.config.EnablePublisherConfirms {
+		err = ch.PublishWithContext(ctx, exchange, routingKey, false, false, msg)
+	} else {
+		var confirm *amqp.DeferredConfirmation
+		confirm, err = ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, msg)
+		if err == nil {
+			var ack bool
+			ack, err = confirm.WaitContext(ctx)
+			if err == nil && !ack {
+				err = ErrNack
+			}
+		}
+	}
+	// channel异常关闭后，下次发布重新创建
+	if errors.Is(err, amqp.ErrClosed) {
+		c.ch = nil
+	}
+	return err
+}
+
+// publishChannel 获取发布使用的channel，需要持有锁
+func (c *Component) publishChannel() (*amqp.Channel, error) {
+	if c.ch != nil && !c.ch.IsClosed() {
+		return c.ch, nil
+	}
+	ch, err := c.conn.channel()
+	if err != nil {
+		return nil, err
+	}
+	if c.config.EnablePublisherConfirms {
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return nil, fmt.Errorf("enable publisher confirms fail, %w", err)
+		}
+	}
+	c.ch = ch
+	return ch, nil
+}
+
+// Ping 检查连接是否可用
+func (c *Component) Ping(_ context.Context) error {
+	if !c.conn.isConnected() {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Close 关闭连接，停止重连
+func (c *Component) Close() error {
+	if err := c.conn.close(); err != nil {
+		c.logger.Error("close rabbitmq fail", elog.FieldErr(err))
+		return err
+	}
+	return nil
+}
+
+func (c *Component) observe(ctx context.Context, exchange, routingKey string, msg amqp.Publishing, cost time.Duration, err error) {
+	method := "publish " + exchange
+	if c.config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ClientHandleCounter.Inc(emetric.TypeRabbitMQ, c.name, method, routingKey, code)
+		emetric.ClientHandleHistogram.Observe(cost.Seconds(), emetric.TypeRabbitMQ, c.name, method, routingKey)
+	}
+
+	isSlow := c.config.SlowLogThreshold > 0 && cost > c.config.SlowLogThreshold
+	if !c.config.EnableAccessInterceptor && !isSlow && err == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod(method),
+		elog.FieldKey(routingKey),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+	}
+	if c.config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(msg.Body, c.config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+}
+
+// headerCarrier 使用消息头传递链路信息
+type headerCarrier amqp.Table
+
+// Get ...
+func (h headerCarrier) Get(key string) string {
+	v, _ := h[key].(string)
+	return v
+}
+
+// Set ...
+func (h headerCarrier) Set(key string, value string) {
+	h[key] = value
+}
+
+// Keys ...
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func truncate(b []byte, max int) string {
+	if max > 0 && len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,145 @@
+package erabbitmq
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var (
+	// ErrNotConnected 连接断开，正在重连
+	ErrNotConnected = errors.New("erabbitmq: not connected")
+	// ErrClosed 组件已经关闭
+	ErrClosed = errors.New("erabbitmq: closed")
+)
+
+// connection 维护一个长连接，连接断开后按照退避策略重连
+type connection struct {
+	config *Config
+	logger *elog.Component
+
+	mu     sync.RWMutex
+	conn   *amqp.Connection
+	closed bool
+	done   chan struct{}
+}
+
+func newConnection(config *Config, logger *elog.Component) *connection {
+	return &connection{
+		config: config,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+func (c *connection) connect() error {
+	conn, err := amqp.DialConfig(c.config.Addr, amqp.Config{
+		Heartbeat: c.config.Heartbeat,
+		Dial:      amqp.DefaultDial(c.config.DialTimeout),
+	})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		_ = conn.Close()
+		return ErrClosed
+	}
+	c.conn = conn
+	return nil
+}
+
+// watch 监听连接关闭，非主动关闭时重连
+func (c *connection) watch() {
+	for {
+		c.mu.RLock()
+		conn := c.conn
+		c.mu.RUnlock()
+		if conn != nil {
+			select {
+			case <-c.done:
+				return
+			case amqpErr := <-conn.NotifyClose(make(chan *amqp.Error, 1)):
+				if amqpErr != nil {
+					c.logger.Warn("rabbitmq connection closed", elog.FieldErr(amqpErr))
+				}
+			}
+		}
+		if c.reconnect() != nil {
+			return
+		}
+	}
+}
+
+// reconnect 按照退避策略重连，直到成功或者组件关闭
+func (c *connection) reconnect() error {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-c.done:
+			return ErrClosed
+		case <-time.After(backoff(attempt, c.config.ReconnectInitialInterval, c.config.ReconnectMaxInterval)):
+		}
+		err := c.connect()
+		if err == nil {
+			c.logger.Info("rabbitmq reconnected", elog.Int("attempt", attempt+1))
+			return nil
+		}
+		if errors.Is(err, ErrClosed) {
+			return err
+		}
+		c.logger.Warn("rabbitmq reconnect fail", elog.Int("attempt", attempt+1), elog.FieldErr(err))
+	}
+}
+
+// channel 创建一个新的channel，连接断开时返回 ErrNotConnected
+func (c *connection) channel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	if c.conn == nil || c.conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	return c.conn.Channel()
+}
+
+func (c *connection) isConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.closed && c.conn != nil && !c.conn.IsClosed()
+}
+
+func (c *connection) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	if c.conn == nil || c.conn.IsClosed() {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// backoff 第attempt次重试的等待时间，按照2倍递增，不超过max
+func backoff(attempt int, initial, max time.Duration) time.Duration {
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	d := initial
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if max > 0 && d >= max {
+			return max
+		}
+	}
+	return d
+}
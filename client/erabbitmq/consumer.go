@@ -0,0 +1,443 @@
+package erabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+	"github.com/gotomicro/ego/server"
+)
+
+// Handler 消息处理函数，返回nil时ack，返回错误时nack
+// 默认不重新入队，队列配置了死信交换机时进入死信队列；需要重新入队时返回 Requeue(err)
+type Handler func(ctx context.Context, d *amqp.Delivery) error
+
+// BatchHandler 批量处理函数，返回nil时全部ack，返回错误时全部nack，Requeue(err)的处理同Handler
+type BatchHandler func(ctx context.Context, ds []*amqp.Delivery) error
+
+type requeueError struct {
+	err error
+}
+
+func (e *requeueError) Error() string { return e.err.Error() }
+func (e *requeueError) Unwrap() error { return e.err }
+
+// Requeue 处理失败，并且消息需要重新入队
+func Requeue(err error) error {
+	return &requeueError{err: err}
+}
+
+// Consumer 消费者，实现了 server.Server，由ego管理启动和停止
+// 连接或者channel断开后，按照退避策略重新订阅
+type Consumer struct {
+	name    string
+	comp    *Component
+	config  ConsumerConfig
+	handler Handler
+	batch   BatchHandler // 不为空时批量处理
+	logger  *elog.Component
+	tracer  *etrace.Tracer
+
+	mu        sync.Mutex
+	ch        *amqp.Channel // 当前订阅使用的channel
+	consuming atomic.Bool   // 是否正在订阅
+	stopOnce  sync.Once
+	stopping  chan struct{} // 开始停止时关闭
+	done      chan struct{} // Start返回时关闭
+}
+
+// Consumer 创建消费者，使用配置中Consumers[name]的配置
+func (c *Component) Consumer(name string, handler Handler) *Consumer {
+	config := DefaultConsumerConfig()
+	if cc, ok := c.config.Consumers[name]; ok {
+		config = cc
+		if config.Prefetch <= 0 {
+			config.Prefetch = DefaultConsumerConfig().Prefetch
+		}
+		if config.Concurrency <= 0 {
+			config.Concurrency = DefaultConsumerConfig().Concurrency
+		}
+		if config.BatchWait <= 0 {
+			config.BatchWait = DefaultConsumerConfig().BatchWait
+		}
+	}
+	if config.BatchSize <= 0 || config.BatchSize > config.Prefetch {
+		config.BatchSize = config.Prefetch
+	}
+	if config.ConsumerTag == "" {
+		config.ConsumerTag = fmt.Sprintf("%s.%s.%d", eapp.Name(), name, os.Getpid())
+	}
+	return &Consumer{
+		name:     name,
+		comp:     c,
+		config:   config,
+		handler:  handler,
+		logger:   c.logger.With(elog.FieldName(name), elog.String("queue", config.Queue)),
+		tracer:   etrace.NewTracer(trace.SpanKindConsumer),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// BatchConsumer 创建批量消费者，凑满BatchSize条或者等待BatchWait后处理一批
+// 批量消费的span通过link关联每条消息的生产者span
+func (c *Component) BatchConsumer(name string, handler BatchHandler) *Consumer {
+	consumer := c.Consumer(name, nil)
+	consumer.batch = handler
+	return consumer
+}
+
+// Name 配置名称
+func (c *Consumer) Name() string {
+	return c.comp.name + "." + c.name
+}
+
+// PackageName 包名
+func (c *Consumer) PackageName() string {
+	return PackageName
+}
+
+// Init 初始化
+func (c *Consumer) Init() error {
+	if c.config.Queue == "" {
+		return fmt.Errorf("erabbitmq: consumer %s queue is empty", c.name)
+	}
+	return nil
+}
+
+// Start 订阅队列，阻塞直到停止
+func (c *Consumer) Start() error {
+	defer close(c.done)
+	if err := c.Init(); err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		consumed, err := c.consume()
+		if c.isStopping() {
+			return nil
+		}
+		if consumed {
+			attempt = 0
+		}
+		c.logger.Warn("rabbitmq consume interrupted, resubscribe later", elog.Int("attempt", attempt+1), elog.FieldErr(err))
+		select {
+		case <-c.stopping:
+			return nil
+		case <-time.After(backoff(attempt, c.comp.config.ReconnectInitialInterval, c.comp.config.ReconnectMaxInterval)):
+		}
+	}
+}
+
+// consume 订阅一次，阻塞直到channel关闭，consumed表示是否订阅成功过
+func (c *Consumer) consume() (consumed bool, err error) {
+	ch, err := c.comp.conn.channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+	if err := ch.Qos(c.config.Prefetch, 0, false); err != nil {
+		return false, fmt.Errorf("set qos fail, %w", err)
+	}
+	deliveries, err := ch.Consume(c.config.Queue, c.config.ConsumerTag, false, c.config.Exclusive, false, false, nil)
+	if err != nil {
+		return false, fmt.Errorf("consume fail, %w", err)
+	}
+	c.mu.Lock()
+	c.ch = ch
+	c.mu.Unlock()
+	// 订阅后才开始停止的情况，需要取消订阅
+	if c.isStopping() {
+		_ = ch.Cancel(c.config.ConsumerTag, false)
+	}
+	c.consuming.Store(true)
+	c.logger.Info("rabbitmq consume start")
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.batch != nil {
+				c.consumeBatch(deliveries)
+				return
+			}
+			for d := range deliveries {
+				d := d
+				c.handle(&d)
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.consuming.Store(false)
+	c.mu.Lock()
+	c.ch = nil
+	c.mu.Unlock()
+	return true, errors.New("deliveries closed")
+}
+
+func (c *Consumer) handle(d *amqp.Delivery) {
+	ctx := context.Background()
+	if c.config.HandleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.HandleTimeout)
+		defer cancel()
+	}
+	var span trace.Span
+	if c.comp.config.EnableTraceInterceptor {
+		ctx, span = c.tracer.Start(ctx, "rabbitmq.consume "+c.config.Queue, headerCarrier(d.Headers), trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingDestinationKey.String(d.Exchange),
+			semconv.MessagingRabbitmqRoutingKeyKey.String(d.RoutingKey),
+			semconv.MessagingOperationProcess,
+		))
+	}
+
+	beg := time.Now()
+	err := c.invoke(ctx, d)
+	var requeueErr *requeueError
+	requeue := c.config.RequeueOnError || errors.As(err, &requeueErr)
+	var ackErr error
+	if err == nil {
+		ackErr = d.Ack(false)
+	} else {
+		ackErr = d.Nack(false, requeue)
+	}
+	cost := time.Since(beg)
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	c.observe(ctx, d, cost, err, requeue, ackErr)
+}
+
+// consumeBatch 攒批处理消息，凑满BatchSize条或者等待BatchWait后处理一批，直到deliveries关闭
+func (c *Consumer) consumeBatch(deliveries <-chan amqp.Delivery) {
+	for d := range deliveries {
+		d := d
+		batch := []*amqp.Delivery{&d}
+		timer := time.NewTimer(c.config.BatchWait)
+		closed := false
+	collect:
+		for len(batch) < c.config.BatchSize {
+			select {
+			case next, ok := <-deliveries:
+				if !ok {
+					closed = true
+					break collect
+				}
+				batch = append(batch, &next)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		c.handleBatch(batch)
+		if closed {
+			return
+		}
+	}
+}
+
+func (c *Consumer) handleBatch(ds []*amqp.Delivery) {
+	ctx := context.Background()
+	if c.config.HandleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.HandleTimeout)
+		defer cancel()
+	}
+	var span trace.Span
+	if c.comp.config.EnableTraceInterceptor {
+		carriers := make([]propagation.TextMapCarrier, 0, len(ds))
+		for _, d := range ds {
+			carriers = append(carriers, headerCarrier(d.Headers))
+		}
+		ctx, span = c.tracer.StartBatch(ctx, "rabbitmq.consume "+c.config.Queue, carriers, trace.WithAttributes(
+			semconv.MessagingSystemKey.String("rabbitmq"),
+			semconv.MessagingOperationProcess,
+		))
+	}
+
+	beg := time.Now()
+	err := c.invokeBatch(ctx, ds)
+	var requeueErr *requeueError
+	requeue := c.config.RequeueOnError || errors.As(err, &requeueErr)
+	var ackErr error
+	for _, d := range ds {
+		var e error
+		if err == nil {
+			e = d.Ack(false)
+		} else {
+			e = d.Nack(false, requeue)
+		}
+		if e != nil && ackErr == nil {
+			ackErr = e
+		}
+	}
+	cost := time.Since(beg)
+
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	for _, d := range ds {
+		c.observe(ctx, d, cost, err, requeue, ackErr)
+	}
+}
+
+// invokeBatch 执行批量处理函数，panic转换为错误
+func (c *Consumer) invokeBatch(ctx context.Context, ds []*amqp.Delivery) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("erabbitmq: batch handler panic, %v", rec)
+		}
+	}()
+	return c.batch(ctx, ds)
+}
+
+// invoke 执行处理函数，panic转换为错误
+func (c *Consumer) invoke(ctx context.Context, d *amqp.Delivery) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("erabbitmq: handler panic, %v", rec)
+		}
+	}()
+	return c.handler(ctx, d)
+}
+
+func (c *Consumer) observe(ctx context.Context, d *amqp.Delivery, cost time.Duration, err error, requeue bool, ackErr error) {
+	config := c.comp.config
+	if config.EnableMetricInterceptor {
+		code := "OK"
+		if err != nil {
+			code = "Error"
+		}
+		emetric.ServerHandleCounter.Inc(emetric.TypeRabbitMQ, c.config.Queue, d.Exchange, code, "", "")
+		emetric.ServerHandleHistogram.Observe(cost.Seconds(), emetric.TypeRabbitMQ, c.config.Queue, d.Exchange, "")
+	}
+
+	isSlow := config.SlowLogThreshold > 0 && cost > config.SlowLogThreshold
+	if !config.EnableAccessInterceptor && !isSlow && err == nil && ackErr == nil {
+		return
+	}
+	event := "normal"
+	if isSlow {
+		event = "slow"
+	}
+	fields := []elog.Field{
+		elog.FieldMethod("consume " + c.config.Queue),
+		elog.FieldKey(d.RoutingKey),
+		elog.FieldCost(cost),
+		elog.FieldEvent(event),
+		elog.Bool("redelivered", d.Redelivered),
+	}
+	if config.EnableAccessInterceptorReq {
+		fields = append(fields, elog.String("req", truncate(d.Body, config.AccessInterceptorMaxLength)))
+	}
+	if etrace.IsGlobalTracerRegistered() {
+		fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
+	}
+	switch {
+	case ackErr != nil:
+		c.logger.Error("access", append(fields, elog.String("ackErr", ackErr.Error()), elog.FieldErr(err))...)
+	case err != nil:
+		c.logger.Error("access", append(fields, elog.Bool("requeue", requeue), elog.FieldErr(err))...)
+	case isSlow:
+		c.logger.Warn("access", fields...)
+	default:
+		c.logger.Info("access", fields...)
+	}
+}
+
+// Stop 立即停止，未确认的消息由服务端重新投递
+func (c *Consumer) Stop() error {
+	c.stop()
+	c.mu.Lock()
+	ch := c.ch
+	c.mu.Unlock()
+	if ch != nil {
+		_ = ch.Close()
+	}
+	return nil
+}
+
+// GracefulStop 取消订阅，等待正在处理的消息完成，超时后立即停止
+func (c *Consumer) GracefulStop(ctx context.Context) error {
+	c.stop()
+	c.mu.Lock()
+	ch := c.ch
+	c.mu.Unlock()
+	if ch != nil {
+		if err := ch.Cancel(c.config.ConsumerTag, false); err != nil {
+			c.logger.Warn("rabbitmq cancel consume fail", elog.FieldErr(err))
+		}
+	}
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		_ = c.Stop()
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopping)
+	})
+}
+
+func (c *Consumer) isStopping() bool {
+	select {
+	case <-c.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Health 是否正在订阅
+func (c *Consumer) Health() bool {
+	return c.consuming.Load() && !c.isStopping()
+}
+
+// Info 服务信息
+func (c *Consumer) Info() *server.ServiceInfo {
+	addr := c.comp.config.Addr
+	if u, err := url.Parse(addr); err == nil {
+		addr = u.Host
+	}
+	info := server.ApplyOptions(
+		server.WithName(c.Name()),
+		server.WithScheme("amqp"),
+		server.WithAddress(addr),
+		server.WithKind(constant.ServiceConsumer),
+		server.WithMetaData("queue", c.config.Queue),
+		server.WithMetaData("concurrency", strconv.Itoa(c.config.Concurrency)),
+	)
+	return &info
+}
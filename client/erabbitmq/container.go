@@ -0,0 +1,79 @@
+package erabbitmq
+
+import (
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 选项
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithAddr 设置连接地址
+func WithAddr(addr string) Option {
+	return func(c *Container) {
+		c.config.Addr = addr
+	}
+}
+
+// WithConsumer 设置消费者配置
+func WithConsumer(name string, config ConsumerConfig) Option {
+	return func(c *Container) {
+		if c.config.Consumers == nil {
+			c.config.Consumers = make(map[string]ConsumerConfig)
+		}
+		c.config.Consumers[name] = config
+	}
+}
+
+// Build 建立连接，连接断开后在后台按照退避策略重连，并在ego停止后关闭连接
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	comp := newComponent(c.name, c.config, c.logger)
+	if err := comp.conn.connect(); err != nil {
+		if c.config.OnFail == OnFailPanic {
+			c.logger.Panic("connect rabbitmq fail", elog.FieldErr(err))
+		}
+		c.logger.Error("connect rabbitmq fail", elog.FieldErr(err))
+	}
+	go comp.conn.watch()
+
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	if c.config.EnableHealthCheck && c.name != "" {
+		ehealth.Register(c.name, comp.Ping, ehealth.WithType("rabbitmq"), ehealth.WithCritical(c.config.HealthCritical))
+	}
+	ecomponent.Register(c.name, PackageName, nil)
+	return comp
+}
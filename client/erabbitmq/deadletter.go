@@ -0,0 +1,75 @@
+package erabbitmq
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter 死信配置，为空的字段使用队列名生成
+type DeadLetter struct {
+	Exchange   string // 死信交换机，默认 <queue>.dlx
+	RoutingKey string // 死信路由键，默认 <queue>
+	Queue      string // 死信队列，默认 <queue>.dlq
+}
+
+// DeadLetterArgs 队列的死信参数，声明队列时使用
+func DeadLetterArgs(exchange, routingKey string) amqp.Table {
+	args := amqp.Table{"x-dead-letter-exchange": exchange}
+	if routingKey != "" {
+		args["x-dead-letter-routing-key"] = routingKey
+	}
+	return args
+}
+
+// DeclareQueueWithDeadLetter 声明持久化的队列，同时声明死信交换机、死信队列和绑定
+// 处理失败并且没有重新入队的消息会进入死信队列
+func (c *Component) DeclareQueueWithDeadLetter(queue string, dl DeadLetter) error {
+	dl = dl.withDefaults(queue)
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err := ch.ExchangeDeclare(dl.Exchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := ch.QueueDeclare(dl.Queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := ch.QueueBind(dl.Queue, dl.RoutingKey, dl.Exchange, false, nil); err != nil {
+		return err
+	}
+	_, err = ch.QueueDeclare(queue, true, false, false, false, DeadLetterArgs(dl.Exchange, dl.RoutingKey))
+	return err
+}
+
+func (dl DeadLetter) withDefaults(queue string) DeadLetter {
+	if dl.Exchange == "" {
+		dl.Exchange = queue + ".dlx"
+	}
+	if dl.RoutingKey == "" {
+		dl.RoutingKey = queue
+	}
+	if dl.Queue == "" {
+		dl.Queue = queue + ".dlq"
+	}
+	return dl
+}
+
+// DeathCount 消息进入死信队列的次数，读取服务端设置的x-death头，可以用于限制重试次数
+func DeathCount(d *amqp.Delivery) int64 {
+	deaths, ok := d.Headers["x-death"].([]interface{})
+	if !ok {
+		return 0
+	}
+	var count int64
+	for _, death := range deaths {
+		table, ok := death.(amqp.Table)
+		if !ok {
+			continue
+		}
+		if n, ok := table["count"].(int64); ok {
+			count += n
+		}
+	}
+	return count
+}
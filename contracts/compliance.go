@@ -0,0 +1,189 @@
+package contracts
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+// ComplianceTimeout 每个步骤的最长等待时间
+var ComplianceTimeout = 5 * time.Second
+
+// TestServerCompliance 验证服务是否符合契约，newServer每次需要返回一个新的、监听不同地址（例如端口为0）的服务
+//   - Name 和 PackageName 不为空
+//   - Init 之后 Info 不为nil，Scheme 不为空
+//   - Start 阻塞直到服务停止，实现了 Health 时启动后变为健康
+//   - GracefulStop 和 Stop 都可以让 Start 返回nil
+//   - 实现了 Reloadable 时，运行中 Reload 返回nil
+func TestServerCompliance(t *testing.T, newServer func() server.Server) {
+	t.Helper()
+	t.Run("Identity", func(t *testing.T) {
+		s := newServer()
+		if s.Name() == "" {
+			t.Error("Name() is empty")
+		}
+		if s.PackageName() == "" {
+			t.Error("PackageName() is empty")
+		}
+	})
+	t.Run("GracefulStop", func(t *testing.T) {
+		s := newServer()
+		done := startServer(t, s)
+		if r, ok := s.(Reloadable); ok {
+			if err := r.Reload(context.Background()); err != nil {
+				t.Errorf("Reload() error: %v", err)
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ComplianceTimeout)
+		defer cancel()
+		if err := s.GracefulStop(ctx); err != nil {
+			t.Errorf("GracefulStop() error: %v", err)
+		}
+		waitStopped(t, done)
+	})
+	t.Run("Stop", func(t *testing.T) {
+		s := newServer()
+		done := startServer(t, s)
+		if err := s.Stop(); err != nil {
+			t.Errorf("Stop() error: %v", err)
+		}
+		waitStopped(t, done)
+	})
+}
+
+// startServer 初始化并启动服务，返回 Start 的结果
+func startServer(t *testing.T, s server.Server) chan error {
+	t.Helper()
+	if err := s.Init(); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	info := s.Info()
+	if info == nil {
+		t.Fatal("Info() is nil after Init()")
+	}
+	if info.Scheme == "" {
+		t.Error("Info().Scheme is empty")
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Start() }()
+
+	deadline := time.Now().Add(ComplianceTimeout)
+	h, ok := s.(Health)
+	for {
+		select {
+		case err := <-done:
+			t.Fatalf("Start() returned before stop: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		if !ok || h.Health() {
+			return done
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Health() is still false %v after Start()", ComplianceTimeout)
+		}
+	}
+}
+
+func waitStopped(t *testing.T, done chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() returned error after stop: %v", err)
+		}
+	case <-time.After(ComplianceTimeout):
+		t.Errorf("Start() did not return %v after stop", ComplianceTimeout)
+	}
+}
+
+// TestClientCompliance 验证客户端组件是否符合契约，build需要通过组件的 Container.Build 创建组件
+//   - Name 和 PackageName 不为空
+//   - 创建后注册到 ecomponent，可以通过治理端 /components 查看
+//   - 实现了 Restartable、Reloadable 时返回nil
+//   - 实现了 io.Closer 时 Close 返回nil
+func TestClientCompliance(t *testing.T, build func() Client) {
+	t.Helper()
+	c := build()
+	if c.Name() == "" {
+		t.Error("Name() is empty")
+	}
+	if c.PackageName() == "" {
+		t.Error("PackageName() is empty")
+	}
+	registered := false
+	for _, info := range ecomponent.List() {
+		if info.Name == c.Name() && info.Type == c.PackageName() {
+			registered = true
+		}
+	}
+	if !registered {
+		t.Errorf("component %s is not registered by ecomponent.Register", c.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ComplianceTimeout)
+	defer cancel()
+	if r, ok := c.(Restartable); ok {
+		if err := r.Restart(ctx); err != nil {
+			t.Errorf("Restart() error: %v", err)
+		}
+	}
+	if r, ok := c.(Reloadable); ok {
+		if err := r.Reload(ctx); err != nil {
+			t.Errorf("Reload() error: %v", err)
+		}
+	}
+	if closer, ok := c.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			t.Errorf("Close() error: %v", err)
+		}
+	}
+}
+
+// TestRegistryCompliance 验证注册中心是否符合契约，info为测试使用的服务信息
+//   - RegisterService 是幂等的，重复注册返回nil
+//   - 注册后 ListServices 可以查询到该服务的地址，注销后查询不到
+//   - UnregisterService 是幂等的，Close 返回nil
+func TestRegistryCompliance(t *testing.T, reg eregistry.Registry, info *server.ServiceInfo) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), ComplianceTimeout)
+	defer cancel()
+	target := eregistry.Target{Protocol: info.Scheme, Endpoint: info.Name}
+	listed := func() bool {
+		services, err := reg.ListServices(ctx, target)
+		if err != nil {
+			t.Errorf("ListServices() error: %v", err)
+			return false
+		}
+		for _, s := range services {
+			if s.Address == info.Address {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := reg.RegisterService(ctx, info); err != nil {
+			t.Fatalf("RegisterService() error: %v", err)
+		}
+	}
+	if !listed() {
+		t.Errorf("service %s is not listed after RegisterService()", info.Label())
+	}
+	for i := 0; i < 2; i++ {
+		if err := reg.UnregisterService(ctx, info); err != nil {
+			t.Errorf("UnregisterService() error: %v", err)
+		}
+	}
+	if listed() {
+		t.Errorf("service %s is still listed after UnregisterService()", info.Label())
+	}
+	if err := reg.Close(); err != nil {
+		t.Errorf("Close() error: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package contracts
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+	"github.com/gotomicro/ego/server/egin"
+	"github.com/gotomicro/ego/server/eworker"
+)
+
+func TestBuiltinServerCompliance(t *testing.T) {
+	conf := `
+[server.test]
+host = "127.0.0.1"
+port = 0
+`
+	if err := econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("egin", func(t *testing.T) {
+		TestServerCompliance(t, func() server.Server {
+			return egin.Load("server.test").Build()
+		})
+	})
+	t.Run("eworker", func(t *testing.T) {
+		TestServerCompliance(t, func() server.Server {
+			return eworker.DefaultContainer().Build(eworker.WithName("test.worker"), eworker.WithWorkFunc(func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}))
+		})
+	})
+}
+
+type testClient struct {
+	restarts int
+	closed   bool
+}
+
+func (c *testClient) Name() string                  { return "test.client" }
+func (c *testClient) PackageName() string           { return "client.etest" }
+func (c *testClient) Restart(context.Context) error { c.restarts++; return nil }
+func (c *testClient) Close() error                  { c.closed = true; return nil }
+
+func TestClient(t *testing.T) {
+	c := &testClient{}
+	TestClientCompliance(t, func() Client {
+		ecomponent.Register(c.Name(), c.PackageName(), c.Restart)
+		return c
+	})
+	defer ecomponent.Unregister(c.Name())
+	if c.restarts != 1 || !c.closed {
+		t.Errorf("restarts: %d, closed: %v", c.restarts, c.closed)
+	}
+}
+
+// memoryRegistry 内存注册中心
+type memoryRegistry struct {
+	eregistry.Nop
+	mu       sync.Mutex
+	services map[string]*server.ServiceInfo
+}
+
+func (r *memoryRegistry) RegisterService(_ context.Context, info *server.ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.services[info.Label()] = info
+	return nil
+}
+
+func (r *memoryRegistry) UnregisterService(_ context.Context, info *server.ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.services, info.Label())
+	return nil
+}
+
+func (r *memoryRegistry) ListServices(_ context.Context, target eregistry.Target) ([]*server.ServiceInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []*server.ServiceInfo
+	for _, info := range r.services {
+		if info.Name == target.Endpoint && info.Scheme == target.Protocol {
+			list = append(list, info)
+		}
+	}
+	return list, nil
+}
+
+func TestRegistry(t *testing.T) {
+	reg := &memoryRegistry{services: map[string]*server.ServiceInfo{}}
+	TestRegistryCompliance(t, reg, &server.ServiceInfo{Name: "test", Scheme: "grpc", Address: "127.0.0.1:9002"})
+}
@@ -0,0 +1,55 @@
+// Package contracts 第三方组件需要实现的接口
+//
+// contracts 中的接口遵循语义化版本，版本号为 Version：
+// 同一个主版本内只会增加新的可选接口，不会修改或者删除已有接口的方法；
+// 框架通过类型断言使用可选接口（例如 Health、Reloadable），没有实现时使用默认行为。
+// 组件作者可以在测试中调用 TestServerCompliance、TestClientCompliance、TestRegistryCompliance 验证组件是否符合契约。
+package contracts
+
+import (
+	"context"
+
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/standard"
+	"github.com/gotomicro/ego/server"
+)
+
+// Version 契约的版本
+const Version = "v1.0.0"
+
+type (
+	// Component 组件的生命周期，由框架调度
+	Component = standard.Component
+	// Server 服务，通过 ego.Serve 注册
+	Server = server.Server
+	// OrderServer 按顺序启动的服务，通过 ego.OrderServe 注册
+	OrderServer = server.OrderServer
+	// ServiceInfo 服务信息，用于服务注册和治理
+	ServiceInfo = server.ServiceInfo
+	// Registry 注册中心，通过 ego.Registry 注册
+	Registry = eregistry.Registry
+)
+
+// Client 客户端组件，由 Container.Build 创建，并且调用 ecomponent.Register 注册到组件列表
+type Client interface {
+	// Name 组件名称，通常为配置key，例如 "redis.default"
+	Name() string
+	// PackageName 组件包名，例如 "client.eredis"
+	PackageName() string
+}
+
+// Health 可选接口，返回组件是否健康，开启 ego.registry.healthGate 时框架在服务健康后才注册服务
+// 服务、定时任务实现时在Run时汇总到 ego.Health 和治理端的 /health/ready
+type Health interface {
+	Health() bool
+}
+
+// Reloadable 可选接口，配置更新后重新加载组件，返回错误时组件需要继续使用旧的配置
+type Reloadable interface {
+	Reload(ctx context.Context) error
+}
+
+// Restartable 可选接口，重建组件的资源，例如重新建立连接池，可以通过 ecomponent.Register 注册给治理端
+type Restartable interface {
+	Restart(ctx context.Context) error
+}
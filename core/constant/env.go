@@ -21,6 +21,8 @@ const (
 	EgoLogPath = "EGO_LOG_PATH"
 	// EgoLogAddApp defines if we should append application name to every logger entries.
 	EgoLogAddApp = "EGO_LOG_ADD_APP"
+	// EgoLogAddResource defines if we should append application resource, such as service version, env, zone and instance ID, to every logger entries.
+	EgoLogAddResource = "EGO_LOG_ADD_RESOURCE"
 	// EgoLogExtraKeys used to append extra tracing keys to every access logger entries, the keys usually comes from HTTP Headers or gRPC Metadata.
 	// you can trace you custom business clues, such as "X-Biz-Uid"(your application user ID) or "X-Biz-Order-Id"(your application order ID).
 	// each keys separated with ",". For example, export EGO_LOG_EXTRA_KEYS=X-Ego-Uid,X-Ego-Order-Id
@@ -35,9 +37,39 @@ const (
 	EgoGovernorEnableConfig = "EGO_GOVERNOR_ENABLE_CONFIG"
 	// EgoLogEnableAddCaller when set to true, your log will show caller, default value is false
 	EgoLogEnableAddCaller = "EGO_LOG_ENABLE_ADD_CALLER"
-	// EgoDefaultConfigExt defines default config file extension, support ".toml"，".yaml"，".json",
+	// EgoDefaultConfigExt defines default config file extension, support ".toml"，".yaml"，".json"，".json5"，".hcl"，".ini",
 	// EgoDefaultConfigExt effective only the configuration file path without extension name
 	EgoDefaultConfigExt = "EGO_DEFAULT_CONFIG_EXT"
+	// EgoConfigFormat defines config format, such as "toml", "hcl", "ini", it takes precedence over the file extension
+	EgoConfigFormat = "EGO_CONFIG_FORMAT"
+	// EgoConfigOnUnavailable defines startup behavior when the config data source, such as etcd or consul, is unreachable, available types are "failfast/retry/degraded"
+	EgoConfigOnUnavailable = "EGO_CONFIG_ON_UNAVAILABLE"
+	// EgoConfigRetryTimeout defines how long to retry the config data source at startup when EgoConfigOnUnavailable is "retry", default value is "30s"
+	EgoConfigRetryTimeout = "EGO_CONFIG_RETRY_TIMEOUT"
+	// EgoConfigInterpolate enables replacing ${ENV_VAR:default} and ${func(arg)} expressions in config values, default value is false.
+	// Once enabled, a literal "${" in config values must be written as "$${", otherwise loading fails when the env is not set
+	EgoConfigInterpolate = "EGO_CONFIG_INTERPOLATE"
+	// EgoConfigSnapshotDir defines directory of config snapshots, the latest snapshot is used at startup when EgoConfigOnUnavailable is "degraded"
+	EgoConfigSnapshotDir = "EGO_CONFIG_SNAPSHOT_DIR"
+	// EgoConfigCacheFile defines the file to persist the last successfully loaded config, it is used before snapshots at startup when EgoConfigOnUnavailable is "degraded"
+	EgoConfigCacheFile = "EGO_CONFIG_CACHE_FILE"
+	// EgoConfigCacheSecret defines the secret to encrypt EgoConfigCacheFile, the cache file is not encrypted when it is empty
+	EgoConfigCacheSecret = "EGO_CONFIG_CACHE_SECRET"
+	// EgoDiagnosticsDir defines directory to write diagnostics bundle when stop timeout or exit with error, no bundle is written when it is empty
+	EgoDiagnosticsDir = "EGO_DIAGNOSTICS_DIR"
+	// EgoStartupJSON defines whether to print startup summary in json instead of banner, it is the same as flag "--startup-json"
+	EgoStartupJSON = "EGO_STARTUP_JSON"
+	// EgoAddrFile defines the file to write actual listening addresses of servers in env format, it is the same as flag "--addr-file"
+	EgoAddrFile = "EGO_ADDR_FILE"
+	// EgoPlugins defines go plugin files to load before loading config, separated by comma, it is the same as flag "--plugins"
+	EgoPlugins = "EGO_PLUGINS"
 	// EgoDeploymentEnv defines deployment environment, such as "k8s", "ecs"
 	EgoDeploymentEnv = "EGO_DEPLOYMENT_ENV"
+	// EgoGracefulListeners defines listeners inherited from the parent process during a hot upgrade, separated with ",".
+	// the listener file descriptors start from 3 in the same order, it is set by ego automatically.
+	EgoGracefulListeners = "EGO_GRACEFUL_LISTENERS"
+	// EgoGracefulReportFD defines the pipe file descriptor used by the child process to report its compatibility info to the parent, it is set by ego automatically.
+	EgoGracefulReportFD = "EGO_GRACEFUL_REPORT_FD"
+	// EgoUpgradeEnable defines whether to start a hot upgrade when receiving SIGUSR2, default false
+	EgoUpgradeEnable = "EGO_UPGRADE_ENABLE"
 )
@@ -12,6 +12,8 @@ const (
 	ServiceGovernor
 	// ServiceConsumer service consumer
 	ServiceConsumer
+	// ServiceWorker background worker without listener, it will not be registered to registry
+	ServiceWorker
 )
 
 var serviceKinds = make(map[ServiceKind]string)
@@ -21,6 +23,7 @@ func init() {
 	serviceKinds[ServiceProvider] = "providers"
 	serviceKinds[ServiceGovernor] = "governors"
 	serviceKinds[ServiceConsumer] = "consumers"
+	serviceKinds[ServiceWorker] = "workers"
 }
 
 func (sk ServiceKind) String() string {
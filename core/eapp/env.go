@@ -16,6 +16,7 @@ var (
 	egoDebug                string
 	egoLogPath              string
 	egoLogAddApp            string
+	egoLogAddResource       bool
 	egoTraceIDName          string
 	egoLogExtraKeys         []string
 	egoLogWriter            string
@@ -32,6 +33,7 @@ func initEnv() {
 	egoDebug = os.Getenv(constant.EgoDebug)
 	egoLogPath = os.Getenv(constant.EgoLogPath)
 	egoLogAddApp = os.Getenv(constant.EgoLogAddApp)
+	egoLogAddResource = ienv.EnvOrBool(constant.EgoLogAddResource, false)
 	egoTraceIDName = ienv.EnvOrStr(constant.EgoTraceIDName, "x-trace-id")
 	egoGovernorEnableConfig = os.Getenv(constant.EgoGovernorEnableConfig)
 	if envEgoLogExtraKeys := strings.TrimSpace(os.Getenv(constant.EgoLogExtraKeys)); envEgoLogExtraKeys != "" {
@@ -80,6 +82,11 @@ func EnableLoggerAddApp() bool {
 	return egoLogAddApp == "true"
 }
 
+// EnableLoggerAddResource returns flag if logger has append resource Field to log entry.
+func EnableLoggerAddResource() bool {
+	return egoLogAddResource
+}
+
 // EgoTraceIDName returns the key in Metadata for storing traceID
 func EgoTraceIDName() string {
 	return egoTraceIDName
@@ -0,0 +1,69 @@
+package eapp
+
+import (
+	"sort"
+)
+
+// 资源属性的key，与OpenTelemetry语义约定保持一致
+const (
+	ResourceServiceName    = "service.name"
+	ResourceServiceVersion = "service.version"
+	ResourceDeploymentEnv  = "deployment.environment"
+	ResourceRegion         = "cloud.region"
+	ResourceZone           = "cloud.availability_zone"
+	ResourceInstanceID     = "service.instance.id"
+	ResourceHostName       = "host.name"
+)
+
+// Resource 应用的资源信息，日志、指标、链路、注册中心使用同一份数据
+type Resource struct {
+	ServiceName    string `json:"serviceName"`
+	ServiceVersion string `json:"serviceVersion"`
+	Env            string `json:"env"` // 运行模式，EGO_MODE
+	Region         string `json:"region"`
+	Zone           string `json:"zone"`
+	InstanceID     string `json:"instanceId"`
+	HostName       string `json:"hostName"`
+}
+
+// GetResource 返回应用的资源信息，数据来源于环境变量和编译参数
+func GetResource() Resource {
+	return Resource{
+		ServiceName:    Name(),
+		ServiceVersion: AppVersion(),
+		Env:            AppMode(),
+		Region:         AppRegion(),
+		Zone:           AppZone(),
+		InstanceID:     AppInstance(),
+		HostName:       HostName(),
+	}
+}
+
+// Attributes 返回资源属性，值为空时不返回
+func (r Resource) Attributes() map[string]string {
+	attrs := make(map[string]string, 7)
+	add := func(key, value string) {
+		if value != "" {
+			attrs[key] = value
+		}
+	}
+	add(ResourceServiceName, r.ServiceName)
+	add(ResourceServiceVersion, r.ServiceVersion)
+	add(ResourceDeploymentEnv, r.Env)
+	add(ResourceRegion, r.Region)
+	add(ResourceZone, r.Zone)
+	add(ResourceInstanceID, r.InstanceID)
+	add(ResourceHostName, r.HostName)
+	return attrs
+}
+
+// Keys 返回资源属性的key，按照字母排序
+func (r Resource) Keys() []string {
+	attrs := r.Attributes()
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,32 @@
+package eapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+)
+
+func TestResourceAttributes(t *testing.T) {
+	res := Resource{ServiceName: "svc", ServiceVersion: "v1.0.0", Env: "dev", Zone: "us-east-1a"}
+	assert.Equal(t, map[string]string{
+		ResourceServiceName:    "svc",
+		ResourceServiceVersion: "v1.0.0",
+		ResourceDeploymentEnv:  "dev",
+		ResourceZone:           "us-east-1a",
+	}, res.Attributes())
+	assert.Equal(t, []string{ResourceZone, ResourceDeploymentEnv, ResourceServiceName, ResourceServiceVersion}, res.Keys())
+}
+
+func TestGetResource(t *testing.T) {
+	t.Cleanup(initEnv)
+	t.Setenv(constant.EnvAppMode, "test")
+	t.Setenv(constant.EnvAppZone, "zone-1")
+	initEnv()
+	res := GetResource()
+	assert.Equal(t, Name(), res.ServiceName)
+	assert.Equal(t, "test", res.Env)
+	assert.Equal(t, "zone-1", res.Zone)
+	assert.Equal(t, HostName(), res.HostName)
+}
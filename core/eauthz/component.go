@@ -0,0 +1,171 @@
+package eauthz
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.eauthz"
+
+const (
+	// KindGRPC gRPC请求，Resource为完整的方法名
+	KindGRPC = "grpc"
+	// KindHTTP HTTP请求，Resource为 "方法 路由"
+	KindHTTP = "http"
+)
+
+var defaultComponent atomic.Pointer[Component]
+
+// Subject 请求的用户
+type Subject struct {
+	ID    string
+	Roles []string
+}
+
+type subjectKey struct{}
+
+// WithSubject 把用户放入ctx，通常由认证中间件调用
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext 返回ctx中的用户
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(Subject)
+	return subject, ok
+}
+
+// Request 授权请求
+type Request struct {
+	Kind     string
+	Resource string
+	Subject  Subject
+}
+
+// Decision 授权结果
+type Decision struct {
+	Allowed bool
+	Rule    string // 决定结果的规则名称
+	Reason  string
+}
+
+// Evaluator 自定义的授权决策，例如调用OPA/Rego，设置后替代内置的RBAC规则
+type Evaluator interface {
+	Evaluate(ctx context.Context, req Request) (Decision, error)
+}
+
+// EvaluatorFunc 函数形式的Evaluator
+type EvaluatorFunc func(ctx context.Context, req Request) (Decision, error)
+
+// Evaluate 实现Evaluator
+func (f EvaluatorFunc) Evaluate(ctx context.Context, req Request) (Decision, error) {
+	return f(ctx, req)
+}
+
+// Component 授权组件
+type Component struct {
+	name      string
+	config    atomic.Pointer[Config]
+	policy    atomic.Pointer[policy]
+	evaluator Evaluator
+	logger    *elog.Component
+}
+
+func newComponent(name string, config *Config, p *policy, evaluator Evaluator, logger *elog.Component) *Component {
+	c := &Component{
+		name:      name,
+		evaluator: evaluator,
+		logger:    logger,
+	}
+	c.config.Store(config)
+	c.policy.Store(p)
+	return c
+}
+
+// Default 返回最后一次Build的组件，没有Build时返回nil
+func Default() *Component {
+	return defaultComponent.Load()
+}
+
+func setDefault(c *Component) {
+	defaultComponent.Store(c)
+}
+
+// Subject 返回请求的用户，ctx中没有用户时从可信网关的header读取
+func (c *Component) Subject(ctx context.Context, header func(key string) string) (Subject, bool) {
+	if subject, ok := SubjectFromContext(ctx); ok {
+		return subject, true
+	}
+	config := c.config.Load()
+	if config.SubjectHeader == "" || header == nil {
+		return Subject{}, false
+	}
+	id := header(config.SubjectHeader)
+	if id == "" {
+		return Subject{}, false
+	}
+	subject := Subject{ID: id}
+	if config.RolesHeader != "" {
+		for _, role := range strings.Split(header(config.RolesHeader), ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				subject.Roles = append(subject.Roles, role)
+			}
+		}
+	}
+	return subject, true
+}
+
+// Authorize 授权决策，记录决策日志和监控
+// 自定义Evaluator返回错误时拒绝访问
+func (c *Component) Authorize(ctx context.Context, req Request) Decision {
+	var decision Decision
+	if c.evaluator != nil {
+		var err error
+		decision, err = c.evaluator.Evaluate(ctx, req)
+		if err != nil {
+			c.logger.Error("authz evaluate fail", elog.FieldMethod(req.Resource), elog.FieldErr(err))
+			decision = Decision{Allowed: false, Reason: "evaluate fail: " + err.Error()}
+		}
+	} else {
+		decision = c.policy.Load().evaluate(req)
+	}
+
+	result := EffectAllow
+	if !decision.Allowed {
+		result = EffectDeny
+	}
+	emetric.AuthzDecisionCounter.Inc(req.Kind, req.Resource, result)
+	if !decision.Allowed || c.config.Load().EnableDecisionLog {
+		fields := []elog.Field{
+			elog.FieldType(req.Kind),
+			elog.FieldMethod(req.Resource),
+			elog.String("subject", req.Subject.ID),
+			elog.Any("roles", req.Subject.Roles),
+			elog.String("decision", result),
+			elog.String("rule", decision.Rule),
+			elog.String("reason", decision.Reason),
+		}
+		if decision.Allowed {
+			c.logger.Info("authz decision", fields...)
+		} else {
+			c.logger.Warn("authz decision", fields...)
+		}
+	}
+	return decision
+}
+
+// reload 编译新的规则并替换，编译失败时保留旧规则
+func (c *Component) reload(config *Config) error {
+	p, err := compile(config)
+	if err != nil {
+		return err
+	}
+	c.config.Store(config)
+	c.policy.Store(p)
+	return nil
+}
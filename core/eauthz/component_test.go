@@ -0,0 +1,134 @@
+package eauthz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+const testConf = `
+[authz]
+rolesHeader = "x-roles"
+subjectHeader = "x-user"
+[authz.inherits]
+admin = ["editor"]
+editor = ["viewer"]
+[[authz.rules]]
+name = "public"
+resources = ["/helloworld.Greeter/SayHello", "GET /api/status"]
+roles = ["*"]
+[[authz.rules]]
+name = "read"
+resources = ["GET /api/articles*"]
+roles = ["viewer"]
+[[authz.rules]]
+name = "write"
+resources = ["POST /api/articles*", "DELETE /api/articles/:id"]
+roles = ["editor"]
+[[authz.rules]]
+name = "banned"
+resources = ["*"]
+roles = ["banned"]
+effect = "deny"
+[[authz.rules]]
+name = "profile"
+resources = ["GET /api/profile"]
+roles = ["@authenticated"]
+`
+
+func authorize(c *Component, resource string, subject Subject) Decision {
+	return c.Authorize(context.Background(), Request{Kind: KindHTTP, Resource: resource, Subject: subject})
+}
+
+func TestAuthorize(t *testing.T) {
+	require.NoError(t, econf.LoadFromReader(bytes.NewBufferString(testConf), toml.Unmarshal))
+	comp := Load("authz").Build()
+	assert.Same(t, comp, Default())
+
+	anonymous := Subject{}
+	viewer := Subject{ID: "1", Roles: []string{"viewer"}}
+	admin := Subject{ID: "2", Roles: []string{"admin"}}
+	banned := Subject{ID: "3", Roles: []string{"admin", "banned"}}
+
+	assert.True(t, authorize(comp, "GET /api/status", anonymous).Allowed)
+	assert.False(t, authorize(comp, "GET /api/articles", anonymous).Allowed)
+	assert.False(t, authorize(comp, "GET /api/unknown", admin).Allowed)
+	assert.True(t, authorize(comp, "GET /api/profile", viewer).Allowed)
+	assert.False(t, authorize(comp, "GET /api/profile", anonymous).Allowed)
+
+	decision := authorize(comp, "GET /api/articles/:id", viewer)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "read", decision.Rule)
+	assert.False(t, authorize(comp, "DELETE /api/articles/:id", viewer).Allowed)
+	// admin继承editor和viewer
+	assert.True(t, authorize(comp, "DELETE /api/articles/:id", admin).Allowed)
+	assert.True(t, authorize(comp, "GET /api/articles", admin).Allowed)
+	// deny规则优先
+	decision = authorize(comp, "GET /api/status", banned)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "banned", decision.Rule)
+
+	// 匹配结果缓存后结果不变
+	assert.True(t, authorize(comp, "GET /api/articles/:id", viewer).Allowed)
+	assert.Len(t, comp.policy.Load().cache, 6)
+}
+
+func TestSubject(t *testing.T) {
+	require.NoError(t, econf.LoadFromReader(bytes.NewBufferString(testConf), toml.Unmarshal))
+	comp := Load("authz").Build()
+	headers := map[string]string{"x-user": "7", "x-roles": "editor, viewer"}
+	subject, ok := comp.Subject(context.Background(), func(key string) string { return headers[key] })
+	assert.True(t, ok)
+	assert.Equal(t, Subject{ID: "7", Roles: []string{"editor", "viewer"}}, subject)
+
+	// ctx中的用户优先
+	ctx := WithSubject(context.Background(), Subject{ID: "1"})
+	subject, ok = comp.Subject(ctx, func(key string) string { return headers[key] })
+	assert.True(t, ok)
+	assert.Equal(t, "1", subject.ID)
+
+	_, ok = comp.Subject(context.Background(), func(string) string { return "" })
+	assert.False(t, ok)
+}
+
+func TestReload(t *testing.T) {
+	comp := DefaultContainer().Build(WithRules(Rule{Resources: []string{"/pkg.Svc/*"}, Roles: []string{"ops"}}))
+	ops := Subject{ID: "1", Roles: []string{"ops"}}
+	assert.True(t, authorize(comp, "/pkg.Svc/Get", ops).Allowed)
+
+	config := DefaultConfig()
+	config.DefaultEffect = EffectAllow
+	require.NoError(t, comp.reload(config))
+	assert.True(t, authorize(comp, "/other.Svc/Get", Subject{}).Allowed)
+
+	// 编译失败时保留旧规则
+	config = DefaultConfig()
+	config.Inherits = map[string][]string{"a": {"b"}, "b": {"a"}}
+	assert.ErrorContains(t, comp.reload(config), "inherits itself")
+	assert.True(t, authorize(comp, "/other.Svc/Get", Subject{}).Allowed)
+
+	config = DefaultConfig()
+	config.Rules = []Rule{{Resources: []string{"/a"}, Roles: []string{"x"}, Effect: "maybe"}}
+	assert.ErrorContains(t, comp.reload(config), "invalid effect")
+}
+
+func TestEvaluator(t *testing.T) {
+	comp := DefaultContainer().Build(WithEvaluator(EvaluatorFunc(func(ctx context.Context, req Request) (Decision, error) {
+		if req.Subject.ID == "" {
+			return Decision{}, errors.New("opa unavailable")
+		}
+		return Decision{Allowed: req.Subject.ID == "root", Rule: "rego"}, nil
+	})))
+	assert.True(t, authorize(comp, "/any", Subject{ID: "root"}).Allowed)
+	assert.False(t, authorize(comp, "/any", Subject{ID: "guest"}).Allowed)
+	decision := authorize(comp, "/any", Subject{})
+	assert.False(t, decision.Allowed)
+	assert.Contains(t, decision.Reason, "opa unavailable")
+}
@@ -0,0 +1,39 @@
+package eauthz
+
+const (
+	// EffectAllow 允许访问
+	EffectAllow = "allow"
+	// EffectDeny 拒绝访问
+	EffectDeny = "deny"
+)
+
+// Config 授权配置
+type Config struct {
+	DefaultEffect     string              // 没有规则匹配时的结果，allow或者deny，默认deny
+	EnableDecisionLog bool                // 是否记录允许访问的决策日志，拒绝访问总是记录，默认不开启
+	SubjectHeader     string              // 可信网关传递的用户ID header，为空时只从ctx读取用户，默认为空
+	RolesHeader       string              // 可信网关传递的角色header，多个角色使用逗号分隔，默认为空
+	CacheSize         int                 // 资源匹配结果的缓存数，默认10000
+	Inherits          map[string][]string // 角色继承，例如 admin = ["editor"]，admin拥有editor的所有权限
+	Rules             []Rule              // 授权规则，deny规则优先
+}
+
+// Rule 授权规则
+type Rule struct {
+	Name      string   // 规则名称，记录在决策日志中，默认为规则的序号
+	Resources []string // gRPC方法，例如 /helloworld.Greeter/SayHello；HTTP路由，例如 GET /api/users/:id；以*结尾表示前缀匹配
+	Roles     []string // 匹配的角色，* 表示任意请求，包括未登录用户，@authenticated 表示任意已登录用户
+	Effect    string   // allow或者deny，默认allow
+}
+
+// RoleAuthenticated 匹配任意已登录用户的角色
+const RoleAuthenticated = "@authenticated"
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		DefaultEffect: EffectDeny,
+		CacheSize:     10000,
+		Inherits:      make(map[string][]string),
+	}
+}
@@ -0,0 +1,84 @@
+package eauthz
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config    *Config
+	name      string
+	logger    *elog.Component
+	evaluator Evaluator
+	rules     []Rule
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithEvaluator 使用自定义的授权决策，例如OPA/Rego，替代内置的RBAC规则
+func WithEvaluator(evaluator Evaluator) Option {
+	return func(c *Container) {
+		c.evaluator = evaluator
+	}
+}
+
+// WithRules 追加代码中定义的规则，配置热更新时保留
+func WithRules(rules ...Rule) Option {
+	return func(c *Container) {
+		c.rules = append(c.rules, rules...)
+	}
+}
+
+// Build 编译规则，设置为默认组件，并在配置热更新时重新编译
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	c.config.Rules = append(c.config.Rules, c.rules...)
+	p, err := compile(c.config)
+	if err != nil {
+		c.logger.Panic("compile authz policy fail", elog.FieldErr(err))
+	}
+	comp := newComponent(c.name, c.config, p, c.evaluator, c.logger)
+	setDefault(comp)
+	if c.name == "" {
+		return comp
+	}
+	econf.OnChange(func(newConf *econf.Configuration) {
+		config := DefaultConfig()
+		if err := newConf.UnmarshalKey(c.name, config); err != nil {
+			c.logger.Error("reload authz config fail", elog.FieldErr(err))
+			return
+		}
+		config.Rules = append(config.Rules, c.rules...)
+		if err := comp.reload(config); err != nil {
+			c.logger.Error("reload authz policy fail, keep old policy", elog.FieldErr(err))
+			return
+		}
+		c.logger.Info("authz policy reloaded", elog.Int("rules", len(config.Rules)))
+	})
+	return comp
+}
@@ -0,0 +1,206 @@
+package eauthz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// policy 编译后的授权规则，配置热更新时整体替换
+type policy struct {
+	defaultAllow bool
+	rules        []compiledRule
+	inherits     map[string][]string
+	cacheSize    int
+
+	mu    sync.RWMutex
+	cache map[string][]int // 资源 => 匹配的规则序号
+}
+
+type compiledRule struct {
+	name     string
+	exact    map[string]bool
+	prefixes []string
+	anyone   bool // *，包括未登录用户
+	anyUser  bool // @authenticated
+	roles    map[string]bool
+	allow    bool
+}
+
+func compile(config *Config) (*policy, error) {
+	p := &policy{
+		inherits:  config.Inherits,
+		cacheSize: config.CacheSize,
+		cache:     make(map[string][]int),
+	}
+	switch config.DefaultEffect {
+	case EffectAllow:
+		p.defaultAllow = true
+	case EffectDeny, "":
+	default:
+		return nil, fmt.Errorf("eauthz: invalid default effect %s", config.DefaultEffect)
+	}
+	for i, rule := range config.Rules {
+		c := compiledRule{name: rule.Name, exact: make(map[string]bool), roles: make(map[string]bool)}
+		if c.name == "" {
+			c.name = strconv.Itoa(i)
+		}
+		switch rule.Effect {
+		case EffectAllow, "":
+			c.allow = true
+		case EffectDeny:
+		default:
+			return nil, fmt.Errorf("eauthz: rule %s has invalid effect %s", c.name, rule.Effect)
+		}
+		if len(rule.Resources) == 0 || len(rule.Roles) == 0 {
+			return nil, fmt.Errorf("eauthz: rule %s must have resources and roles", c.name)
+		}
+		for _, res := range rule.Resources {
+			if strings.HasSuffix(res, "*") {
+				c.prefixes = append(c.prefixes, strings.TrimSuffix(res, "*"))
+				continue
+			}
+			c.exact[res] = true
+		}
+		for _, role := range rule.Roles {
+			switch role {
+			case "*":
+				c.anyone = true
+			case RoleAuthenticated:
+				c.anyUser = true
+			default:
+				c.roles[role] = true
+			}
+		}
+		p.rules = append(p.rules, c)
+	}
+	if err := checkInherits(config.Inherits); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkInherits 角色继承不能有环
+func checkInherits(inherits map[string][]string) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(role string) error
+	visit = func(role string) error {
+		switch state[role] {
+		case visiting:
+			return fmt.Errorf("eauthz: role %s inherits itself", role)
+		case done:
+			return nil
+		}
+		state[role] = visiting
+		for _, parent := range inherits[role] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[role] = done
+		return nil
+	}
+	for role := range inherits {
+		if err := visit(role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match 返回匹配资源的规则序号，结果会被缓存
+func (p *policy) match(resource string) []int {
+	p.mu.RLock()
+	idx, ok := p.cache[resource]
+	p.mu.RUnlock()
+	if ok {
+		return idx
+	}
+	idx = make([]int, 0)
+	for i, rule := range p.rules {
+		if rule.matchResource(resource) {
+			idx = append(idx, i)
+		}
+	}
+	p.mu.Lock()
+	// 缓存满了之后清空，资源的数量通常有限
+	if len(p.cache) >= p.cacheSize {
+		p.cache = make(map[string][]int)
+	}
+	p.cache[resource] = idx
+	p.mu.Unlock()
+	return idx
+}
+
+func (r *compiledRule) matchResource(resource string) bool {
+	if r.exact[resource] {
+		return true
+	}
+	for _, prefix := range r.prefixes {
+		if strings.HasPrefix(resource, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand 返回角色以及继承的所有角色
+func (p *policy) expand(roles []string) map[string]bool {
+	out := make(map[string]bool, len(roles))
+	var add func(role string)
+	add = func(role string) {
+		if out[role] {
+			return
+		}
+		out[role] = true
+		for _, parent := range p.inherits[role] {
+			add(parent)
+		}
+	}
+	for _, role := range roles {
+		add(role)
+	}
+	return out
+}
+
+// evaluate deny规则优先，其次allow规则，都不匹配时使用默认结果
+func (p *policy) evaluate(req Request) Decision {
+	var roles map[string]bool
+	var allowRule string
+	for _, i := range p.match(req.Resource) {
+		rule := &p.rules[i]
+		matched := rule.anyone || (rule.anyUser && req.Subject.ID != "")
+		if !matched {
+			if roles == nil {
+				roles = p.expand(req.Subject.Roles)
+			}
+			for role := range rule.roles {
+				if roles[role] {
+					matched = true
+					break
+				}
+			}
+		}
+		if !matched {
+			continue
+		}
+		if !rule.allow {
+			return Decision{Allowed: false, Rule: rule.name, Reason: "denied by rule"}
+		}
+		if allowRule == "" {
+			allowRule = rule.name
+		}
+	}
+	if allowRule != "" {
+		return Decision{Allowed: true, Rule: allowRule, Reason: "allowed by rule"}
+	}
+	if p.defaultAllow {
+		return Decision{Allowed: true, Reason: "default allow"}
+	}
+	return Decision{Allowed: false, Reason: "no rule matched"}
+}
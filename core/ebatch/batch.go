@@ -0,0 +1,279 @@
+package ebatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.ebatch"
+
+var (
+	// ErrFull 等待攒批的数据已满
+	ErrFull = errors.New("ebatch: queue full")
+	// ErrClosed 批处理已经关闭
+	ErrClosed = errors.New("ebatch: closed")
+)
+
+// FlushFunc 写入一批数据，返回错误时按照MaxRetries重试
+type FlushFunc[T any] func(ctx context.Context, items []T) error
+
+// Component 按照条数、字节数、时间攒批写入，例如日志上报、指标上报、业务事件
+// 等待攒批的数据超过QueueSize时Add阻塞，内存占用不超过 QueueSize + (Flushers+1)*MaxItems 条
+type Component[T any] struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	flush  FlushFunc[T]
+	sizer  func(item T) int
+	dlq    func(items []T, err error)
+
+	input     chan T
+	batches   chan []T
+	closing   chan struct{}
+	addMu     sync.RWMutex // 关闭时等待正在添加的数据入队
+	closeOnce sync.Once
+	done      chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	pending   int64 // 还没有写入的条数
+	flushed   int64 // 写入成功的条数
+}
+
+// Option 可选项
+type Option[T any] func(c *Component[T])
+
+// WithSizer 设置每条数据的字节数计算方法，配合MaxBytes使用
+func WithSizer[T any](sizer func(item T) int) Option[T] {
+	return func(c *Component[T]) {
+		c.sizer = sizer
+	}
+}
+
+// WithDLQ 设置重试后仍然失败的数据的处理方法，例如写入死信队列，默认只记录日志
+func WithDLQ[T any](dlq func(items []T, err error)) Option[T] {
+	return func(c *Component[T]) {
+		c.dlq = dlq
+	}
+}
+
+// Load 读取配置key创建批处理
+func Load[T any](key string, flush FlushFunc[T], options ...Option[T]) *Component[T] {
+	config := DefaultConfig()
+	if err := econf.UnmarshalKey(key, &config); err != nil {
+		elog.EgoLogger.With(elog.FieldComponent(PackageName)).Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+	}
+	return New(key, config, flush, options...)
+}
+
+// New 创建批处理，ego停止时写入剩余的数据
+func New[T any](name string, config *Config, flush FlushFunc[T], options ...Option[T]) *Component[T] {
+	if config.MaxItems <= 0 {
+		config.MaxItems = 1
+	}
+	if config.Flushers <= 0 {
+		config.Flushers = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultConfig().FlushInterval
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	c := &Component[T]{
+		name:    name,
+		config:  config,
+		logger:  elog.EgoLogger.With(elog.FieldComponent(PackageName), elog.FieldComponentName(name)),
+		flush:   flush,
+		input:   make(chan T, config.QueueSize),
+		batches: make(chan []T),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(config.Flushers)
+	for i := 0; i < config.Flushers; i++ {
+		go func() {
+			defer wg.Done()
+			for items := range c.batches {
+				c.write(items)
+			}
+		}()
+	}
+	go func() {
+		c.accumulate()
+		close(c.batches)
+		wg.Wait()
+		close(c.done)
+	}()
+	ehooks.RegisterFlush(PackageName+"."+name, c.Close)
+	return c
+}
+
+// Name 名称
+func (c *Component[T]) Name() string {
+	return c.name
+}
+
+// Pending 还没有写入的条数
+func (c *Component[T]) Pending() int {
+	return int(atomic.LoadInt64(&c.pending))
+}
+
+// Add 添加数据，等待攒批的数据已满时阻塞，直到入队、ctx取消或者批处理关闭
+func (c *Component[T]) Add(ctx context.Context, item T) error {
+	c.addMu.RLock()
+	defer c.addMu.RUnlock()
+	select {
+	case <-c.closing:
+		return ErrClosed
+	default:
+	}
+	c.addPending(1)
+	select {
+	case c.input <- item:
+		return nil
+	case <-ctx.Done():
+		c.addPending(-1)
+		return ctx.Err()
+	case <-c.closing:
+		c.addPending(-1)
+		return ErrClosed
+	}
+}
+
+// TryAdd 添加数据，等待攒批的数据已满时返回ErrFull
+func (c *Component[T]) TryAdd(item T) error {
+	c.addMu.RLock()
+	defer c.addMu.RUnlock()
+	select {
+	case <-c.closing:
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.input <- item:
+		c.addPending(1)
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+// accumulate 攒批，输入关闭后写入最后一批
+func (c *Component[T]) accumulate() {
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]T, 0, c.config.MaxItems)
+	bytes := 0
+	emit := func() {
+		if len(batch) == 0 {
+			return
+		}
+		c.batches <- batch
+		batch = make([]T, 0, c.config.MaxItems)
+		bytes = 0
+	}
+	for {
+		select {
+		case item, ok := <-c.input:
+			if !ok {
+				emit()
+				return
+			}
+			size := 0
+			if c.sizer != nil && c.config.MaxBytes > 0 {
+				size = c.sizer(item)
+				// 加上这条数据会超过MaxBytes时，先写入之前的数据
+				if len(batch) > 0 && bytes+size > c.config.MaxBytes {
+					emit()
+				}
+			}
+			batch = append(batch, item)
+			bytes += size
+			if len(batch) >= c.config.MaxItems || (c.config.MaxBytes > 0 && bytes >= c.config.MaxBytes) {
+				emit()
+			}
+		case <-ticker.C:
+			emit()
+		}
+	}
+}
+
+// write 写入一批数据，失败时重试，重试后仍然失败交给DLQ
+func (c *Component[T]) write(items []T) {
+	beg := time.Now()
+	backoff := c.config.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-c.ctx.Done():
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = c.flush(c.ctx, items); err == nil || c.ctx.Err() != nil {
+			break
+		}
+		c.logger.Warn("batch flush fail", elog.Int("attempt", attempt), elog.Int("items", len(items)), elog.FieldErr(err))
+	}
+	c.addPending(-int64(len(items)))
+	code := "OK"
+	if err == nil {
+		atomic.AddInt64(&c.flushed, int64(len(items)))
+	} else {
+		code = "Error"
+		if c.dlq != nil {
+			code = "DLQ"
+			c.dlq(items, err)
+		} else {
+			c.logger.Error("batch flush fail, drop items", elog.Int("items", len(items)), elog.FieldErr(err))
+		}
+	}
+	if c.config.EnableMetric {
+		emetric.BatchFlushCounter.Inc(c.name, code)
+		emetric.BatchFlushHistogram.Observe(time.Since(beg).Seconds(), c.name)
+	}
+}
+
+func (c *Component[T]) addPending(delta int64) {
+	pending := atomic.AddInt64(&c.pending, delta)
+	if c.config.EnableMetric {
+		emetric.BatchPendingGauge.Set(float64(pending), c.name)
+	}
+}
+
+// Close 拒绝新的数据，写入剩余的数据，ctx超时后取消正在执行的写入
+// 返回关闭期间写入成功的条数和没有写入的条数，可以多次调用
+func (c *Component[T]) Close(ctx context.Context) (flushed int, dropped int, err error) {
+	start := atomic.LoadInt64(&c.flushed)
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		c.addMu.Lock()
+		close(c.input)
+		c.addMu.Unlock()
+	})
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		c.cancel()
+		err = fmt.Errorf("ebatch %s close, err: %w", c.name, context.Cause(ctx))
+		dropped = c.Pending()
+	}
+	return int(atomic.LoadInt64(&c.flushed) - start), dropped, err
+}
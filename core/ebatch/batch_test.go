@@ -0,0 +1,131 @@
+package ebatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) flush(ctx context.Context, items []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, append([]int{}, items...))
+	return nil
+}
+
+func (r *recorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, 0, len(r.batches))
+	for _, b := range r.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestFlushByCount(t *testing.T) {
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.MaxItems = 10
+	config.FlushInterval = time.Hour
+	b := New("count", config, rec.flush)
+	for i := 0; i < 25; i++ {
+		assert.NoError(t, b.Add(context.Background(), i))
+	}
+	assert.Eventually(t, func() bool { return len(rec.sizes()) == 2 }, time.Second, time.Millisecond)
+	flushed, dropped, err := b.Close(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, 5, flushed)
+	assert.Equal(t, []int{10, 10, 5}, rec.sizes())
+	assert.ErrorIs(t, b.Add(context.Background(), 1), ErrClosed)
+}
+
+func TestFlushByInterval(t *testing.T) {
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.FlushInterval = 20 * time.Millisecond
+	b := New("interval", config, rec.flush)
+	defer b.Close(context.Background())
+	assert.NoError(t, b.Add(context.Background(), 1))
+	assert.NoError(t, b.TryAdd(2))
+	assert.Eventually(t, func() bool { return len(rec.sizes()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 0, b.Pending())
+}
+
+func TestFlushByBytes(t *testing.T) {
+	rec := &recorder{}
+	config := DefaultConfig()
+	config.MaxBytes = 10
+	config.FlushInterval = time.Hour
+	b := New("bytes", config, rec.flush, WithSizer(func(item int) int { return item }))
+	for _, item := range []int{4, 4, 4, 6, 3} {
+		assert.NoError(t, b.Add(context.Background(), item))
+	}
+	_, _, err := b.Close(context.Background())
+	assert.NoError(t, err)
+	// 4+4+4超过10，先写入4+4；4+6达到10
+	assert.Equal(t, [][]int{{4, 4}, {4, 6}, {3}}, rec.batches)
+}
+
+func TestRetryAndDLQ(t *testing.T) {
+	var attempts int32
+	var dlq [][]string
+	config := DefaultConfig()
+	config.MaxRetries = 2
+	config.RetryBackoff = time.Millisecond
+	b := New("dlq", config, func(ctx context.Context, items []string) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("downstream unavailable")
+	}, WithDLQ(func(items []string, err error) {
+		assert.EqualError(t, err, "downstream unavailable")
+		dlq = append(dlq, items)
+	}))
+	assert.NoError(t, b.Add(context.Background(), "a"))
+	assert.NoError(t, b.Add(context.Background(), "b"))
+	flushed, dropped, err := b.Close(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	assert.Equal(t, [][]string{{"a", "b"}}, dlq)
+}
+
+func TestBackpressure(t *testing.T) {
+	block := make(chan struct{})
+	config := DefaultConfig()
+	config.MaxItems = 1
+	config.QueueSize = 1
+	b := New("backpressure", config, func(ctx context.Context, items []int) error {
+		select {
+		case <-block:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	})
+	// 1条正在写入，1条等待写入，1条在队列中
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, b.Add(context.Background(), i))
+	}
+	assert.Eventually(t, func() bool { return b.TryAdd(3) == ErrFull }, time.Second, time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, b.Add(ctx, 4), context.DeadlineExceeded)
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer closeCancel()
+	_, dropped, err := b.Close(closeCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 3, dropped)
+	close(block)
+}
@@ -0,0 +1,32 @@
+package ebatch
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 批处理配置
+type Config struct {
+	MaxItems      int           // 每批最多的条数，默认100
+	MaxBytes      int           // 每批最大的字节数，需要通过WithSizer计算每条数据的大小，0为不限制
+	FlushInterval time.Duration // 没有攒满一批时的最长等待时间，默认1s
+	QueueSize     int           // 等待攒批的最大条数，超过后Add阻塞，默认10000
+	Flushers      int           // 并发执行flush的协程数，默认1
+	MaxRetries    int           // flush失败后的重试次数，默认3
+	RetryBackoff  time.Duration // 第一次重试的等待时间，之后每次翻倍，默认100ms
+	EnableMetric  bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		MaxItems:      100,
+		FlushInterval: xtime.Duration("1s"),
+		QueueSize:     10000,
+		Flushers:      1,
+		MaxRetries:    3,
+		RetryBackoff:  xtime.Duration("100ms"),
+		EnableMetric:  true,
+	}
+}
@@ -0,0 +1,137 @@
+package ebudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.ebudget"
+
+const (
+	// ReasonExhausted 剩余时间不足，调用前快速失败
+	ReasonExhausted = "exhausted"
+	// ReasonExceeded 下游调用超出了分配的预算
+	ReasonExceeded = "exceeded"
+)
+
+// ErrExhausted 剩余时间不足以发起下游调用，可以通过 errors.Is 判断
+var ErrExhausted = errors.New("ebudget: deadline budget exhausted")
+
+// Error 预算不足时返回的错误
+type Error struct {
+	Name      string        // 下游依赖名称
+	Remaining time.Duration // 请求剩余的时间
+	Reserve   time.Duration // 需要为后续处理保留的时间
+}
+
+// Error ...
+func (e *Error) Error() string {
+	return fmt.Sprintf("ebudget: deadline budget exhausted, name: %s, remaining: %s, reserve: %s", e.Name, e.Remaining, e.Reserve)
+}
+
+// Is 支持 errors.Is(err, ErrExhausted)
+func (e *Error) Is(target error) bool {
+	return target == ErrExhausted
+}
+
+// Budget 某个下游依赖的预算，Percent和Fixed同时设置时取较小值
+type Budget struct {
+	Name    string
+	Percent float64       // 占剩余时间的比例，取值(0, 1]
+	Fixed   time.Duration // 固定时长，不能超过剩余时间
+}
+
+// Percent 按照剩余时间的比例分配预算
+func Percent(name string, percent float64) Budget {
+	return Budget{Name: name, Percent: percent}
+}
+
+// Fixed 分配固定时长的预算
+func Fixed(name string, d time.Duration) Budget {
+	return Budget{Name: name, Fixed: d}
+}
+
+// Planner 将请求剩余的deadline按照预算分配给依次调用的下游依赖
+type Planner struct {
+	budgets map[string]Budget
+	reserve time.Duration
+}
+
+// Option 可选项
+type Option func(p *Planner)
+
+// WithReserve 为下游调用之后的处理保留时间，剩余时间不超过reserve时快速失败
+func WithReserve(reserve time.Duration) Option {
+	return func(p *Planner) {
+		p.reserve = reserve
+	}
+}
+
+// New 创建Planner
+func New(budgets []Budget, options ...Option) *Planner {
+	p := &Planner{budgets: make(map[string]Budget, len(budgets))}
+	for _, budget := range budgets {
+		p.budgets[budget.Name] = budget
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Begin 为下游调用name分配预算，返回带有预算deadline的context
+// ctx没有deadline时只按照Fixed设置超时，剩余时间不足时返回 *Error
+func (p *Planner) Begin(ctx context.Context, name string) (context.Context, context.CancelFunc, error) {
+	budget := p.budgets[name]
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		if budget.Fixed > 0 {
+			ctx, cancel := context.WithTimeout(ctx, budget.Fixed)
+			return ctx, cancel, nil
+		}
+		return ctx, func() {}, nil
+	}
+	remaining := time.Until(deadline) - p.reserve
+	if remaining <= 0 {
+		emetric.BudgetViolationCounter.Inc(name, ReasonExhausted)
+		return ctx, func() {}, &Error{Name: name, Remaining: remaining + p.reserve, Reserve: p.reserve}
+	}
+	timeout := remaining
+	if budget.Percent > 0 && budget.Percent < 1 {
+		timeout = time.Duration(float64(remaining) * budget.Percent)
+	}
+	if budget.Fixed > 0 && budget.Fixed < timeout {
+		timeout = budget.Fixed
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// Do 在name的预算内执行fn，fn因为预算耗尽而超时时记录超出预算的指标
+func (p *Planner) Do(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	budgetCtx, cancel, err := p.Begin(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	err = fn(budgetCtx)
+	// 请求本身还没有超时，说明是分配的预算不足
+	if errors.Is(budgetCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		emetric.BudgetViolationCounter.Inc(name, ReasonExceeded)
+	}
+	return err
+}
+
+// Remaining 请求剩余的时间，ctx没有deadline时返回false
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
@@ -0,0 +1,74 @@
+package ebudget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+func TestPlannerBegin(t *testing.T) {
+	p := New([]Budget{Percent("user", 0.5), Fixed("cache", 10*time.Millisecond)}, WithReserve(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 210*time.Millisecond)
+	defer cancel()
+
+	userCtx, userCancel, err := p.Begin(ctx, "user")
+	assert.NoError(t, err)
+	defer userCancel()
+	remaining, ok := Remaining(userCtx)
+	assert.True(t, ok)
+	assert.InDelta(t, 100*time.Millisecond, remaining, float64(20*time.Millisecond))
+
+	cacheCtx, cacheCancel, err := p.Begin(ctx, "cache")
+	assert.NoError(t, err)
+	defer cacheCancel()
+	remaining, _ = Remaining(cacheCtx)
+	assert.LessOrEqual(t, remaining, 10*time.Millisecond)
+
+	// 未配置预算的依赖使用全部剩余时间
+	otherCtx, otherCancel, err := p.Begin(ctx, "other")
+	assert.NoError(t, err)
+	defer otherCancel()
+	remaining, _ = Remaining(otherCtx)
+	assert.Greater(t, remaining, 150*time.Millisecond)
+}
+
+func TestPlannerExhausted(t *testing.T) {
+	p := New([]Budget{Percent("exhausted", 0.5)}, WithReserve(50*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	before := testutil.ToFloat64(emetric.BudgetViolationCounter.WithLabelValues("exhausted", ReasonExhausted))
+	_, _, err := p.Begin(ctx, "exhausted")
+	assert.True(t, errors.Is(err, ErrExhausted))
+	var budgetErr *Error
+	assert.True(t, errors.As(err, &budgetErr))
+	assert.Equal(t, "exhausted", budgetErr.Name)
+	assert.Equal(t, before+1, testutil.ToFloat64(emetric.BudgetViolationCounter.WithLabelValues("exhausted", ReasonExhausted)))
+
+	// 没有deadline时不限制
+	_, noCancel, err := p.Begin(context.Background(), "exhausted")
+	assert.NoError(t, err)
+	noCancel()
+}
+
+func TestPlannerDo(t *testing.T) {
+	p := New([]Budget{Fixed("slow", 5*time.Millisecond)})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	before := testutil.ToFloat64(emetric.BudgetViolationCounter.WithLabelValues("slow", ReasonExceeded))
+	err := p.Do(ctx, "slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, before+1, testutil.ToFloat64(emetric.BudgetViolationCounter.WithLabelValues("slow", ReasonExceeded)))
+
+	assert.NoError(t, p.Do(ctx, "slow", func(ctx context.Context) error { return nil }))
+}
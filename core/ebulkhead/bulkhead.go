@@ -0,0 +1,166 @@
+package ebulkhead
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sentinelapi "github.com/alibaba/sentinel-golang/api"
+	"github.com/alibaba/sentinel-golang/core/base"
+	"github.com/alibaba/sentinel-golang/core/circuitbreaker"
+
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "core.ebulkhead"
+
+const (
+	// ReasonQueueFull 并发已满并且等待队列已满
+	ReasonQueueFull = "queue_full"
+	// ReasonTimeout 排队超时
+	ReasonTimeout = "timeout"
+	// ReasonBreakerOpen 熔断器打开
+	ReasonBreakerOpen = "breaker_open"
+)
+
+var (
+	// ErrRejected 舱壁拒绝了调用，并发和等待队列已满或者排队超时
+	ErrRejected = errors.New("ebulkhead: rejected")
+	// ErrBreakerOpen 熔断器打开，调用被拒绝
+	ErrBreakerOpen = errors.New("ebulkhead: circuit breaker open")
+)
+
+// Config 舱壁配置
+type Config struct {
+	MaxConcurrent int           // 最大并发调用数，0表示不限制
+	MaxQueue      int           // 并发已满时最多等待的调用数，默认0，不等待直接拒绝
+	QueueTimeout  time.Duration // 最长排队时间，默认0，只受ctx控制
+}
+
+// Bulkhead 基于信号量的舱壁，限制对某个下游依赖的并发调用，避免一个慢依赖耗尽所有goroutine和连接
+// 如果sentinel配置了同名资源的熔断规则，Do会先经过熔断器，舱壁拒绝和调用失败都会计入熔断统计
+type Bulkhead struct {
+	name    string
+	config  Config
+	sem     chan struct{}
+	waiting int64
+}
+
+// New 创建舱壁，name用于指标和熔断资源名，通常为客户端组件名
+func New(name string, config Config) *Bulkhead {
+	b := &Bulkhead{name: name, config: config}
+	if config.MaxConcurrent > 0 {
+		b.sem = make(chan struct{}, config.MaxConcurrent)
+	}
+	return b
+}
+
+// Name 名称
+func (b *Bulkhead) Name() string {
+	return b.name
+}
+
+// Inflight 正在执行的调用数
+func (b *Bulkhead) Inflight() int {
+	return len(b.sem)
+}
+
+// Waiting 正在排队的调用数
+func (b *Bulkhead) Waiting() int {
+	return int(atomic.LoadInt64(&b.waiting))
+}
+
+// Acquire 获取调用许可，调用结束后必须执行返回的release，release可以重复执行
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	if b == nil || b.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case b.sem <- struct{}{}:
+		return b.release(), nil
+	default:
+	}
+	if atomic.AddInt64(&b.waiting, 1) > int64(b.config.MaxQueue) {
+		atomic.AddInt64(&b.waiting, -1)
+		emetric.BulkheadRejectCounter.Inc(b.name, ReasonQueueFull)
+		etrace.AddRejectEvent(ctx, b.name, ReasonQueueFull)
+		return nil, ErrRejected
+	}
+	defer atomic.AddInt64(&b.waiting, -1)
+
+	var timeout <-chan time.Time
+	if b.config.QueueTimeout > 0 {
+		timer := time.NewTimer(b.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.sem <- struct{}{}:
+		return b.release(), nil
+	case <-timeout:
+		emetric.BulkheadRejectCounter.Inc(b.name, ReasonTimeout)
+		etrace.AddRejectEvent(ctx, b.name, ReasonTimeout)
+		return nil, ErrRejected
+	case <-ctx.Done():
+		emetric.BulkheadRejectCounter.Inc(b.name, ReasonTimeout)
+		etrace.AddRejectEvent(ctx, b.name, ReasonTimeout)
+		return nil, ctx.Err()
+	}
+}
+
+func (b *Bulkhead) release() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-b.sem })
+	}
+}
+
+// Begin 经过熔断器并获取调用许可，调用结束后必须执行返回的done，传入调用的错误用于熔断统计
+// 适用于无法用Do包装的场景，例如HTTP客户端的请求前后中间件
+func (b *Bulkhead) Begin(ctx context.Context) (func(err error), error) {
+	var entry *base.SentinelEntry
+	if len(circuitbreaker.GetRulesOfResource(b.name)) > 0 {
+		var blockErr *base.BlockError
+		entry, blockErr = sentinelapi.Entry(b.name, sentinelapi.WithTrafficType(base.Outbound))
+		if blockErr != nil {
+			emetric.BulkheadRejectCounter.Inc(b.name, ReasonBreakerOpen)
+			etrace.AddRejectEvent(ctx, b.name, ReasonBreakerOpen)
+			return nil, ErrBreakerOpen
+		}
+	}
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		if entry != nil {
+			// 舱壁拒绝说明下游已经处理不过来，计入熔断统计
+			sentinelapi.TraceError(entry, err)
+			entry.Exit()
+		}
+		return nil, err
+	}
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			release()
+			if entry != nil {
+				if err != nil {
+					sentinelapi.TraceError(entry, err)
+				}
+				entry.Exit()
+			}
+		})
+	}, nil
+}
+
+// Do 在舱壁内执行fn
+func (b *Bulkhead) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	done, err := b.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+	return fn(ctx)
+}
@@ -0,0 +1,75 @@
+package ebulkhead
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alibaba/sentinel-golang/core/circuitbreaker"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+func TestAcquire(t *testing.T) {
+	b := New("test.acquire", Config{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	release, err := b.Acquire(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, b.Inflight())
+
+	// 排队超时
+	before := testutil.ToFloat64(emetric.BulkheadRejectCounter.WithLabelValues("test.acquire", ReasonTimeout))
+	_, err = b.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Equal(t, before+1, testutil.ToFloat64(emetric.BulkheadRejectCounter.WithLabelValues("test.acquire", ReasonTimeout)))
+
+	// 排队中释放许可后获取成功，同时队列已满的调用直接拒绝
+	result := make(chan error)
+	go func() {
+		_, err := b.Acquire(context.Background())
+		result <- err
+	}()
+	assert.Eventually(t, func() bool { return b.Waiting() == 1 }, time.Second, time.Millisecond)
+	_, err = b.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrRejected)
+	release()
+	release()
+	assert.NoError(t, <-result)
+	assert.Equal(t, 1, b.Inflight())
+
+	// 不限制并发
+	release, err = New("test.unlimited", Config{}).Acquire(context.Background())
+	assert.NoError(t, err)
+	release()
+}
+
+func TestDoWithBreaker(t *testing.T) {
+	name := "test.breaker"
+	_, err := circuitbreaker.LoadRulesOfResource(name, []*circuitbreaker.Rule{{
+		Resource:         name,
+		Strategy:         circuitbreaker.ErrorCount,
+		RetryTimeoutMs:   10000,
+		MinRequestAmount: 1,
+		StatIntervalMs:   10000,
+		Threshold:        2,
+	}})
+	assert.NoError(t, err)
+	defer func() { _ = circuitbreaker.ClearRulesOfResource(name) }()
+
+	b := New(name, Config{MaxConcurrent: 1})
+	errFail := errors.New("fail")
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, b.Do(context.Background(), func(ctx context.Context) error { return errFail }), errFail)
+	}
+	// 错误数达到阈值后熔断
+	var called bool
+	err = b.Do(context.Background(), func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrBreakerOpen)
+	assert.False(t, called)
+	assert.Equal(t, 0, b.Inflight())
+}
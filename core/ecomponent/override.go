@@ -0,0 +1,48 @@
+package ecomponent
+
+import "context"
+
+// overridesKey ctx中替换组件的key
+type overridesKey struct{}
+
+// WithOverride 返回替换了名称为name的组件的ctx，只影响当前请求，不修改全局的组件
+// 测试中替换为fake组件，灰度中间件替换为指向其他地址的组件，name通常为组件的配置key
+func WithOverride(ctx context.Context, name string, comp any) context.Context {
+	return WithOverrides(ctx, map[string]any{name: comp})
+}
+
+// WithOverrides 返回替换了多个组件的ctx，与ctx中已有的替换合并，同名时使用新的组件
+func WithOverrides(ctx context.Context, comps map[string]any) context.Context {
+	if len(comps) == 0 {
+		return ctx
+	}
+	parent, _ := ctx.Value(overridesKey{}).(map[string]any)
+	// 复制一份，避免影响父ctx
+	merged := make(map[string]any, len(parent)+len(comps))
+	for name, comp := range parent {
+		merged[name] = comp
+	}
+	for name, comp := range comps {
+		merged[name] = comp
+	}
+	return context.WithValue(ctx, overridesKey{}, merged)
+}
+
+// Overridden 返回ctx中替换的组件
+func Overridden(ctx context.Context, name string) (any, bool) {
+	comps, _ := ctx.Value(overridesKey{}).(map[string]any)
+	comp, ok := comps[name]
+	return comp, ok
+}
+
+// Resolve 返回ctx中替换的组件，没有替换或者类型不一致时返回def，例如
+//
+//	userClient := ecomponent.Resolve(ctx, "grpc.user", invoker.UserClient)
+func Resolve[T any](ctx context.Context, name string, def T) T {
+	if comp, ok := Overridden(ctx, name); ok {
+		if typed, ok := comp.(T); ok {
+			return typed
+		}
+	}
+	return def
+}
@@ -0,0 +1,37 @@
+package ecomponent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type greeter interface{ Hello() string }
+
+type fakeGreeter string
+
+func (f fakeGreeter) Hello() string { return string(f) }
+
+func TestResolve(t *testing.T) {
+	var def greeter = fakeGreeter("default")
+	ctx := context.Background()
+	assert.Equal(t, "default", Resolve(ctx, "grpc.user", def).Hello())
+
+	ctx1 := WithOverride(ctx, "grpc.user", fakeGreeter("fake"))
+	assert.Equal(t, "fake", Resolve(ctx1, "grpc.user", def).Hello())
+	assert.Equal(t, "default", Resolve(ctx1, "grpc.order", def).Hello())
+
+	// 子ctx的替换不影响父ctx
+	ctx2 := WithOverrides(ctx1, map[string]any{"grpc.user": fakeGreeter("canary"), "grpc.order": fakeGreeter("order")})
+	assert.Equal(t, "canary", Resolve(ctx2, "grpc.user", def).Hello())
+	assert.Equal(t, "order", Resolve(ctx2, "grpc.order", def).Hello())
+	assert.Equal(t, "fake", Resolve(ctx1, "grpc.user", def).Hello())
+
+	// 类型不一致时使用默认组件
+	ctx3 := WithOverride(ctx, "grpc.user", "not a greeter")
+	assert.Equal(t, "default", Resolve(ctx3, "grpc.user", def).Hello())
+	comp, ok := Overridden(ctx3, "grpc.user")
+	assert.True(t, ok)
+	assert.Equal(t, "not a greeter", comp)
+}
@@ -0,0 +1,127 @@
+package ecomponent
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PackageName 包名
+const PackageName = "core.ecomponent"
+
+const (
+	// StatusRunning 正常运行
+	StatusRunning = "running"
+	// StatusRestarting 正在重建
+	StatusRestarting = "restarting"
+	// StatusFailed 最近一次重建失败，组件继续使用重建之前的资源
+	StatusFailed = "failed"
+)
+
+var (
+	// ErrNotFound 组件没有注册
+	ErrNotFound = errors.New("ecomponent: component not found")
+	// ErrNotRestartable 组件不支持重建
+	ErrNotRestartable = errors.New("ecomponent: component is not restartable")
+	// ErrRestarting 组件正在重建
+	ErrRestarting = errors.New("ecomponent: component is restarting")
+)
+
+// RestartFunc 重建组件的资源，例如重新建立连接池，返回错误时组件需要继续使用旧的资源
+type RestartFunc func(ctx context.Context) error
+
+// Info 组件信息
+type Info struct {
+	Name        string    `json:"name"`        // 组件名称，通常为配置key
+	Type        string    `json:"type"`        // 组件包名，例如 client.ehttp
+	BuildTime   time.Time `json:"buildTime"`   // 组件创建的时间
+	Status      string    `json:"status"`      // running、restarting、failed
+	Restartable bool      `json:"restartable"` // 是否支持重建
+	Restarts    int       `json:"restarts"`    // 重建成功的次数
+	LastRestart time.Time `json:"lastRestart"` // 最近一次重建的时间
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+type entry struct {
+	info    Info
+	restart RestartFunc
+}
+
+var (
+	mu      sync.RWMutex
+	entries = make(map[string]*entry)
+)
+
+// Register 注册创建好的组件，restart为nil时不支持重建，重复注册会覆盖，name为空时不注册
+func Register(name string, typ string, restart RestartFunc) {
+	if name == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	entries[name] = &entry{
+		info: Info{
+			Name:        name,
+			Type:        typ,
+			BuildTime:   time.Now(),
+			Status:      StatusRunning,
+			Restartable: restart != nil,
+		},
+		restart: restart,
+	}
+}
+
+// Unregister 取消注册组件
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(entries, name)
+}
+
+// List 返回所有组件的信息，按照名称排序
+func List() []Info {
+	mu.RLock()
+	out := make([]Info, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.info)
+	}
+	mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Restart 重建组件，同一个组件同时只能有一个重建
+func Restart(ctx context.Context, name string) error {
+	mu.Lock()
+	e, ok := entries[name]
+	switch {
+	case !ok:
+		mu.Unlock()
+		return ErrNotFound
+	case e.restart == nil:
+		mu.Unlock()
+		return ErrNotRestartable
+	case e.info.Status == StatusRestarting:
+		mu.Unlock()
+		return ErrRestarting
+	}
+	e.info.Status = StatusRestarting
+	mu.Unlock()
+
+	err := e.restart(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	e.info.LastRestart = time.Now()
+	if err != nil {
+		e.info.Status = StatusFailed
+		e.info.LastError = err.Error()
+		return err
+	}
+	e.info.Status = StatusRunning
+	e.info.LastError = ""
+	e.info.Restarts++
+	return nil
+}
@@ -0,0 +1,51 @@
+package ecomponent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestart(t *testing.T) {
+	fail := true
+	block := make(chan struct{})
+	Register("mysql", "client.egorm", func(ctx context.Context) error {
+		if fail {
+			return errors.New("dial fail")
+		}
+		<-block
+		return nil
+	})
+	Register("http", "client.ehttp", nil)
+	// 没有名称的组件不注册
+	Register("", "client.eoss", nil)
+	defer Unregister("mysql")
+	defer Unregister("http")
+
+	infos := List()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "http", infos[0].Name)
+	assert.False(t, infos[0].Restartable)
+	assert.Equal(t, StatusRunning, infos[1].Status)
+
+	assert.ErrorIs(t, Restart(context.Background(), "redis"), ErrNotFound)
+	assert.ErrorIs(t, Restart(context.Background(), "http"), ErrNotRestartable)
+	assert.EqualError(t, Restart(context.Background(), "mysql"), "dial fail")
+	assert.Equal(t, StatusFailed, List()[1].Status)
+	assert.Equal(t, "dial fail", List()[1].LastError)
+
+	fail = false
+	done := make(chan error)
+	go func() { done <- Restart(context.Background(), "mysql") }()
+	assert.Eventually(t, func() bool { return List()[1].Status == StatusRestarting }, time.Second, time.Millisecond)
+	assert.ErrorIs(t, Restart(context.Background(), "mysql"), ErrRestarting)
+	close(block)
+	assert.NoError(t, <-done)
+	info := List()[1]
+	assert.Equal(t, StatusRunning, info.Status)
+	assert.Equal(t, 1, info.Restarts)
+	assert.Empty(t, info.LastError)
+}
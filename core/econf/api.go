@@ -13,6 +13,12 @@ var (
 	ConfigTypeToml ConfigType = "toml"
 	// ConfigTypeYaml ...
 	ConfigTypeYaml ConfigType = "yaml"
+	// ConfigTypeJSON5 ...
+	ConfigTypeJSON5 ConfigType = "json5"
+	// ConfigTypeHCL ...
+	ConfigTypeHCL ConfigType = "hcl"
+	// ConfigTypeIni ...
+	ConfigTypeIni ConfigType = "ini"
 )
 
 // ConfigType 配置类型
@@ -36,6 +42,11 @@ func OnChange(fn func(*Configuration)) {
 	defaultConfiguration.OnChange(fn)
 }
 
+// OnReloadError 注册配置热加载失败的回调
+func OnReloadError(fn func(error)) {
+	defaultConfiguration.OnReloadError(fn)
+}
+
 // Sub return sub-configuration of defaultConfiguration
 func Sub(key string) *Configuration {
 	return defaultConfiguration.Sub(key)
@@ -48,6 +59,11 @@ func LoadFromDataSource(ds DataSource, unmarshaller Unmarshaller, opts ...Option
 	return defaultConfiguration.LoadFromDataSource(ds, unmarshaller, opts...)
 }
 
+// Reload reloads configuration from the data source of defaultConfiguration.
+func Reload() error {
+	return defaultConfiguration.Reload()
+}
+
 // LoadFromReader loads configuration from provided provider with default defaultConfiguration.
 func LoadFromReader(r io.Reader, unmarshaller Unmarshaller) error {
 	return defaultConfiguration.LoadFromReader(r, unmarshaller)
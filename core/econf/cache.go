@@ -0,0 +1,120 @@
+package econf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// cacheMagic 加密的缓存文件头，用于区分明文缓存
+var cacheMagic = []byte("EGOCFG1\n")
+
+// SetCacheFile 设置配置缓存文件，每次成功加载配置后覆盖写入，数据源不可用时可以使用缓存启动
+// key不为空时，使用key的SHA-256摘要作为AES-256-GCM密钥加密缓存；当前已经加载的配置会立即写入
+func (c *Configuration) SetCacheFile(path string, key []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("econf SetCacheFile, err: %w", err)
+	}
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	c.cacheFile, c.cacheKey = path, key
+	for i := len(c.snapshots) - 1; i >= 0; i-- {
+		if c.snapshots[i].Good {
+			return c.writeCache(c.snapshots[i].Content)
+		}
+	}
+	return nil
+}
+
+// writeCache 写入临时文件后重命名，避免进程退出时留下不完整的缓存
+func (c *Configuration) writeCache(content []byte) error {
+	if c.cacheFile == "" {
+		return nil
+	}
+	data, err := encryptCache(content, c.cacheKey)
+	if err != nil {
+		return fmt.Errorf("econf write cache, err: %w", err)
+	}
+	tmp := c.cacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("econf write cache, err: %w", err)
+	}
+	if err := os.Rename(tmp, c.cacheFile); err != nil {
+		return fmt.Errorf("econf write cache, err: %w", err)
+	}
+	return nil
+}
+
+// ReadCacheFile 读取配置缓存文件，缓存加密时需要传入写入时使用的key
+func ReadCacheFile(path string, key []byte) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("econf ReadCacheFile, err: %w", err)
+	}
+	content, err := decryptCache(data, key)
+	if err != nil {
+		return nil, fmt.Errorf("econf ReadCacheFile %s, err: %w", path, err)
+	}
+	return content, nil
+}
+
+// SetCacheFile 设置默认配置的缓存文件
+func SetCacheFile(path string, key []byte) error {
+	return defaultConfiguration.SetCacheFile(path, key)
+}
+
+func newCacheCipher(key []byte) (cipher.AEAD, error) {
+	sum := sha256.Sum256(key)
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptCache(content []byte, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return content, nil
+	}
+	aead, err := newCacheCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(append([]byte{}, cacheMagic...), nonce...)
+	return aead.Seal(out, nonce, content, nil), nil
+}
+
+func decryptCache(data []byte, key []byte) ([]byte, error) {
+	encrypted := len(data) >= len(cacheMagic) && string(data[:len(cacheMagic)]) == string(cacheMagic)
+	switch {
+	case !encrypted && len(key) == 0:
+		return data, nil
+	case !encrypted:
+		return nil, errors.New("cache is not encrypted")
+	case len(key) == 0:
+		return nil, errors.New("cache is encrypted, key is required")
+	}
+	aead, err := newCacheCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(cacheMagic):]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("cache is corrupted")
+	}
+	content, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt cache fail, %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,42 @@
+package econf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cache", "config.toml")
+	v := New()
+	v.recordSnapshot([]byte(`foo = "bar"`), SnapshotSourceLoad, true)
+	assert.NoError(t, v.SetCacheFile(file, []byte("secret")))
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "bar")
+	content, err := ReadCacheFile(file, []byte("secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, `foo = "bar"`, string(content))
+
+	// 加载失败的配置不覆盖缓存
+	v.recordSnapshot([]byte(`foo = "bad"`), SnapshotSourceReload, false)
+	v.recordSnapshot([]byte(`foo = "baz"`), SnapshotSourceReload, true)
+	content, err = ReadCacheFile(file, []byte("secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, `foo = "baz"`, string(content))
+
+	_, err = ReadCacheFile(file, []byte("wrong"))
+	assert.Error(t, err)
+	_, err = ReadCacheFile(file, nil)
+	assert.ErrorContains(t, err, "key is required")
+
+	// 不加密
+	assert.NoError(t, v.SetCacheFile(file, nil))
+	content, err = ReadCacheFile(file, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `foo = "baz"`, string(content))
+	_, err = ReadCacheFile(file, []byte("secret"))
+	assert.ErrorContains(t, err, "not encrypted")
+}
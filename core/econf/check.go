@@ -0,0 +1,73 @@
+package econf
+
+import (
+	"reflect"
+)
+
+// SchemaIssue 配置校验发现的问题，例如配置中存在组件结构体没有的字段
+type SchemaIssue struct {
+	Key  string `json:"key"`
+	Type string `json:"type"` // 组件配置的结构体类型
+	Err  string `json:"error"`
+}
+
+// EnableSchemaCheck 开启配置校验，开启后UnmarshalKey会记录配置中组件不认识的字段
+func (c *Configuration) EnableSchemaCheck() {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+	c.schemaCheck = true
+}
+
+// SchemaIssues 返回配置校验发现的问题
+func (c *Configuration) SchemaIssues() []SchemaIssue {
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+	return append([]SchemaIssue(nil), c.schemaIssues...)
+}
+
+// checkSchema 使用ErrorUnused重新解析一次配置，找出结构体中不存在的字段（通常是拼写错误）
+func (c *Configuration) checkSchema(key string, rawVal interface{}, opts []Option) {
+	c.schemaMu.Lock()
+	enabled := c.schemaCheck
+	c.schemaMu.Unlock()
+	if !enabled {
+		return
+	}
+	typ := reflect.TypeOf(rawVal)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return
+	}
+	// 组件的配置通常是 **Config
+	typ = typ.Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	fresh := reflect.New(typ).Interface()
+	err := c.UnmarshalKey(key, fresh, append(opts, WithErrorUnused(true))...)
+	if err == nil {
+		return
+	}
+	issue := SchemaIssue{Key: key, Type: typ.String(), Err: err.Error()}
+	c.schemaMu.Lock()
+	defer c.schemaMu.Unlock()
+	// 同一个组件可能被多次加载
+	for _, item := range c.schemaIssues {
+		if item == issue {
+			return
+		}
+	}
+	c.schemaIssues = append(c.schemaIssues, issue)
+}
+
+// EnableSchemaCheck 开启默认配置的配置校验
+func EnableSchemaCheck() {
+	defaultConfiguration.EnableSchemaCheck()
+}
+
+// SchemaIssues 返回默认配置校验发现的问题
+func SchemaIssues() []SchemaIssue {
+	return defaultConfiguration.SchemaIssues()
+}
@@ -0,0 +1,50 @@
+package econf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCheck(t *testing.T) {
+	type serverConfig struct {
+		Host        string
+		Port        int
+		ReadTimeout time.Duration
+	}
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`
+[server.http]
+host = "0.0.0.0"
+port = 9001
+readTimout = "1s"
+[server.grpc]
+port = 9002
+`), toml.Unmarshal))
+
+	// 未开启时不记录
+	var config serverConfig
+	assert.NoError(t, v.UnmarshalKey("server.http", &config))
+	assert.Empty(t, v.SchemaIssues())
+
+	v.EnableSchemaCheck()
+	assert.NoError(t, v.UnmarshalKey("server.http", &config))
+	assert.NoError(t, v.UnmarshalKey("server.http", &config))
+	assert.NoError(t, v.UnmarshalKey("server.grpc", &serverConfig{}))
+	// 组件的配置通常是指针
+	ptr := &serverConfig{}
+	assert.NoError(t, v.UnmarshalKey("server.http", &ptr))
+	assert.Equal(t, 9001, config.Port)
+
+	issues := v.SchemaIssues()
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "server.http", issues[0].Key)
+	assert.Contains(t, issues[0].Type, "serverConfig")
+	assert.Contains(t, issues[0].Err, "readTimout")
+
+	// 显式开启ErrorUnused时直接返回错误
+	assert.Error(t, v.UnmarshalKey("server.http", &serverConfig{}, WithErrorUnused(true)))
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
@@ -27,8 +28,37 @@ type Configuration struct {
 	rawConfig []byte
 	keyMap    *sync.Map
 	onChanges []func(*Configuration)
+	onErrors  []func(error) // 热加载失败的回调
 
 	watchers map[string][]func(*Configuration)
+
+	ds           DataSource   // 配置数据源，用于重新加载配置
+	unmarshaller Unmarshaller // 配置数据源的解析器
+
+	onApplies   []func(*Configuration) error // 配置生效的回调，返回错误时回滚
+	onRollbacks []func(RollbackEvent)
+
+	loadMu        sync.Mutex // 保证热加载和回滚串行执行
+	snapMu        sync.Mutex
+	version       int
+	snapshots     []Snapshot
+	snapshotLimit int
+	snapshotDir   string
+	cacheFile     string // 最近一次加载成功的配置的缓存文件
+	cacheKey      []byte // 缓存文件的加密密钥
+
+	mergeStrategies map[string]mergeStrategy // 数组的合并策略，key为数组的完整路径
+
+	typed           sync.Map     // GetT、UnmarshalT的转换结果
+	typedGeneration atomic.Int64 // 配置每次变更加一，缓存的转换结果随之失效
+
+	reloadStats reloadStats // 热加载的统计
+
+	schemaMu     sync.Mutex
+	schemaCheck  bool
+	schemaIssues []SchemaIssue
+
+	interpolate atomic.Bool // 是否替换配置值中的 ${ENV_VAR:default} 和 ${func(arg)} 表达式
 }
 
 const (
@@ -73,6 +103,13 @@ func (c *Configuration) OnChange(fn func(*Configuration)) {
 	c.mu.Unlock()
 }
 
+// OnReloadError register a callback when configuration reload fail.
+func (c *Configuration) OnReloadError(fn func(error)) {
+	c.mu.Lock()
+	c.onErrors = append(c.onErrors, fn)
+	c.mu.Unlock()
+}
+
 // LoadFromDataSource ...
 func (c *Configuration) LoadFromDataSource(ds DataSource, unmarshaller Unmarshaller, opts ...Option) error {
 	for _, opt := range opts {
@@ -81,36 +118,102 @@ func (c *Configuration) LoadFromDataSource(ds DataSource, unmarshaller Unmarshal
 
 	content, err := ds.ReadConfig()
 	if err != nil {
-		return fmt.Errorf("LoadFromDataSource ReadConfig, err: %w", err)
+		return fmt.Errorf("LoadFromDataSource ReadConfig, %w, err: %w", ErrDataSourceUnavailable, err)
 	}
 
 	if err := c.Load(content, unmarshaller); err != nil {
 		return fmt.Errorf("LoadFromDataSource Load, err: %w", err)
 	}
+	c.mu.Lock()
+	c.ds = ds
+	c.unmarshaller = unmarshaller
+	c.mu.Unlock()
+	c.recordSnapshot(content, SnapshotSourceLoad, true)
+	c.reloadStats.update(func(stats *ReloadStats) { stats.LastSuccess = time.Now() })
 
 	go func() {
 		// 首次加载配置执行 OnChange
-		c.mu.RLock()
-		for _, change := range c.onChanges {
-			change(c)
-		}
-		c.mu.RUnlock()
+		_ = c.runOnChanges()
 
 		for range ds.IsConfigChanged() {
-			if content, err := ds.ReadConfig(); err == nil {
-				_ = c.Load(content, unmarshaller)
-				c.mu.RLock()
-				for _, change := range c.onChanges {
-					change(c)
-				}
-				c.mu.RUnlock()
-			}
+			c.reloadStats.update(func(stats *ReloadStats) { stats.WatchEvents++ })
+			_ = c.Reload()
 		}
 	}()
 
 	return nil
 }
 
+// Reload 从数据源重新读取并加载配置，成功后执行 OnChange 回调，失败后执行 OnReloadError 回调
+func (c *Configuration) Reload() error {
+	rebuilds, err := c.reload()
+	c.recordReload(err, rebuilds)
+	if err != nil {
+		c.mu.RLock()
+		fns := c.onErrors
+		c.mu.RUnlock()
+		for _, fn := range fns {
+			fn(err)
+		}
+	}
+	return err
+}
+
+// reload 返回执行的回调次数
+func (c *Configuration) reload() (int, error) {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	c.mu.RLock()
+	ds, unmarshaller := c.ds, c.unmarshaller
+	c.mu.RUnlock()
+	if ds == nil {
+		return 0, errors.New("econf Reload, err: no data source loaded")
+	}
+
+	content, err := ds.ReadConfig()
+	if err != nil {
+		return 0, fmt.Errorf("econf Reload ReadConfig, err: %w", err)
+	}
+	if err := c.Load(content, unmarshaller); err != nil {
+		return 0, fmt.Errorf("econf Reload Load, err: %w", err)
+	}
+	c.mu.RLock()
+	rebuilds := len(c.onChanges) + len(c.onApplies)
+	c.mu.RUnlock()
+	err = c.runOnChanges()
+	c.recordSnapshot(content, SnapshotSourceReload, err == nil)
+	if err == nil {
+		return rebuilds, nil
+	}
+
+	// 组件无法使用新配置，回滚到上一个可用的配置
+	applyErr := fmt.Errorf("econf Reload apply, err: %w", err)
+	current, target, ok := c.findSnapshot(0)
+	if !ok {
+		return rebuilds, applyErr
+	}
+	if err := c.rollback(current, target, applyErr.Error()); err != nil {
+		return rebuilds, errors.Join(applyErr, err)
+	}
+	return rebuilds, fmt.Errorf("%w, rollback to version %d", applyErr, target.Version)
+}
+
+// runOnChanges 执行 OnChange 和 OnApply 回调，返回 OnApply 回调的聚合错误
+func (c *Configuration) runOnChanges() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, change := range c.onChanges {
+		change(c)
+	}
+	var errs []error
+	for _, apply := range c.onApplies {
+		if err := apply(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Load ...
 func (c *Configuration) Load(content []byte, unmarshal Unmarshaller) error {
 	c.rawConfig = content
@@ -118,6 +221,12 @@ func (c *Configuration) Load(content []byte, unmarshal Unmarshaller) error {
 	if err := unmarshal(content, &configuration); err != nil {
 		return err
 	}
+	// 开启后所有数据源在加载、热加载时都会替换环境变量和模板函数
+	if c.interpolate.Load() {
+		if _, err := interpolate(configuration); err != nil {
+			return fmt.Errorf("interpolate config, err: %w", err)
+		}
+	}
 	return c.apply(configuration)
 }
 
@@ -136,10 +245,15 @@ func (c *Configuration) apply(conf map[string]interface{}) error {
 
 	var changes = make(map[string]interface{})
 
-	xmap.MergeStringMap(c.override, conf)
+	if err := c.loadMergeStrategies(conf); err != nil {
+		return err
+	}
+	c.merge(c.override, conf)
+	c.typedGeneration.Add(1)
 	for k, v := range c.traverse(c.keyDelim) {
+		// 新增的配置项也通知 WatchKey
 		orig, ok := c.keyMap.Load(k)
-		if ok && !reflect.DeepEqual(orig, v) {
+		if !ok || !reflect.DeepEqual(orig, v) {
 			changes[k] = v
 		}
 		c.keyMap.Store(k, v)
@@ -353,6 +467,9 @@ func UnmarshalKey(key string, rawVal interface{}, opts ...Option) error {
 // ErrInvalidKey ...
 var ErrInvalidKey = errors.New("invalid key, maybe not exist in config")
 
+// ErrDataSourceUnavailable 数据源读取配置失败，例如无法连接etcd、consul等配置中心
+var ErrDataSourceUnavailable = errors.New("data source unavailable")
+
 // UnmarshalKey takes a single key and unmarshal it into a Struct.
 func (c *Configuration) UnmarshalKey(key string, rawVal interface{}, opts ...Option) error {
 	var options = defaultContainer
@@ -361,11 +478,12 @@ func (c *Configuration) UnmarshalKey(key string, rawVal interface{}, opts ...Opt
 	}
 
 	config := mapstructure.DecoderConfig{
-		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(unitHookFunc(), mapstructure.StringToTimeDurationHookFunc(), stringToBasicTypeHookFunc()),
 		Result:           rawVal,
 		TagName:          options.TagName,
 		WeaklyTypedInput: options.WeaklyTypedInput,
 		Squash:           options.Squash,
+		ErrorUnused:      options.ErrorUnused,
 	}
 	decoder, err := mapstructure.NewDecoder(&config)
 	if err != nil {
@@ -374,15 +492,29 @@ func (c *Configuration) UnmarshalKey(key string, rawVal interface{}, opts ...Opt
 	if key == "" {
 		c.mu.RLock()
 		defer c.mu.RUnlock()
-		return decoder.Decode(c.override)
+		value, err := convertUnits(reflect.TypeOf(rawVal), c.override, key, options)
+		if err != nil {
+			return err
+		}
+		return decoder.Decode(value)
 	}
 
 	value := c.Get(key)
 	if value == nil {
 		return fmt.Errorf(key+",err: %w", ErrInvalidKey)
 	}
+	// 按照结构体字段的 unit tag 解析 "512MB"、"30%" 等配置
+	if value, err = convertUnits(reflect.TypeOf(rawVal), value, key, options); err != nil {
+		return err
+	}
 
-	return decoder.Decode(value)
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	if !options.ErrorUnused {
+		c.checkSchema(key, rawVal, opts)
+	}
+	return nil
 }
 
 func (c *Configuration) find(key string) interface{} {
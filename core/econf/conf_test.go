@@ -1,9 +1,12 @@
 package econf
 
 import (
+	"os"
+	"path"
 	"sync"
 	"testing"
 
+	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +52,31 @@ func TestSet(t *testing.T) {
 	assert.Equal(t, float64(42), v.GetFloat64(key))
 	assert.Equal(t, []string{"42"}, v.GetStringSlice(key))
 }
+
+func TestReload(t *testing.T) {
+	v := New()
+	assert.Error(t, v.Reload())
+
+	ds := &mockDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "bar"`), 0640))
+	assert.NoError(t, v.LoadFromDataSource(ds, toml.Unmarshal))
+	assert.Equal(t, "bar", v.GetString("foo"))
+
+	changed := make(chan struct{}, 10)
+	v.OnChange(func(*Configuration) {
+		changed <- struct{}{}
+	})
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "baz"`), 0640))
+	assert.NoError(t, v.Reload())
+	assert.Equal(t, "baz", v.GetString("foo"))
+	<-changed
+
+	var reloadErr error
+	v.OnReloadError(func(err error) {
+		reloadErr = err
+	})
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = `), 0640))
+	assert.Error(t, v.Reload())
+	assert.Error(t, reloadErr)
+	assert.Equal(t, "baz", v.GetString("foo"))
+}
@@ -5,6 +5,7 @@ type Container struct {
 	TagName          string
 	WeaklyTypedInput bool
 	Squash           bool
+	ErrorUnused      bool // 配置中存在结构体没有的字段时返回错误
 }
 
 var defaultContainer = Container{
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gotomicro/ego/core/constant"
 
@@ -61,12 +62,17 @@ func extParser(configAddr string) econf.ConfigType {
 		return econf.ConfigTypeJSON
 	case ".toml":
 		return econf.ConfigTypeToml
-	case ".yaml":
+	case ".yaml", ".yml":
 		return econf.ConfigTypeYaml
-	default:
-		elog.EgoLogger.Panic("data source: invalid configuration type")
+	case ".json5":
+		return econf.ConfigTypeJSON5
+	case ".hcl":
+		return econf.ConfigTypeHCL
+	case ".ini":
+		return econf.ConfigTypeIni
 	}
-	return ""
+	// 其他扩展名需要通过 --config-format 指定配置类型
+	return econf.ConfigType(strings.TrimPrefix(ext, "."))
 }
 
 // ReadConfig implements DataSource method
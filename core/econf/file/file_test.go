@@ -148,6 +148,22 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestExtParser(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected econf.ConfigType
+	}{
+		{in: "config.yml", expected: econf.ConfigTypeYaml},
+		{in: "config.json5", expected: econf.ConfigTypeJSON5},
+		{in: "config.hcl", expected: econf.ConfigTypeHCL},
+		{in: "config.ini", expected: econf.ConfigTypeIni},
+		{in: "config.conf", expected: "conf"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, extParser(c.in))
+	}
+}
+
 func TestReadConfig(t *testing.T) {
 	cases := []struct {
 		in       string
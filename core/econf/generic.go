@@ -0,0 +1,70 @@
+package econf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// typedKey 类型化读取的缓存key，同一个配置项可以按不同的类型读取
+type typedKey struct {
+	key string
+	typ reflect.Type
+}
+
+// typedValue 缓存的转换结果，generation与配置的版本不一致时失效
+type typedValue struct {
+	generation int64
+	value      interface{}
+	err        error
+}
+
+// GetT 读取默认配置中的配置项并转换为T类型，例如 econf.GetT[time.Duration]("server.http.readTimeout")
+// 配置项不存在时返回的错误包含 ErrInvalidKey，转换失败时返回的错误包含配置项和目标类型
+// 转换结果会缓存到配置下一次变更，不要修改返回值中的map、slice
+func GetT[T any](key string) (T, error) {
+	return GetTFrom[T](defaultConfiguration, key)
+}
+
+// GetTFrom 读取配置中的配置项并转换为T类型
+func GetTFrom[T any](c *Configuration, key string) (T, error) {
+	return typedGet[T](c, "GetT", key, nil)
+}
+
+// UnmarshalT 将默认配置中prefix下的配置解析为T类型，例如 econf.UnmarshalT[Config]("server.http")
+// 没有传入opts时结果会缓存到配置下一次变更
+func UnmarshalT[T any](prefix string, opts ...Option) (T, error) {
+	return UnmarshalTFrom[T](defaultConfiguration, prefix, opts...)
+}
+
+// UnmarshalTFrom 将配置中prefix下的配置解析为T类型
+func UnmarshalTFrom[T any](c *Configuration, prefix string, opts ...Option) (T, error) {
+	return typedGet[T](c, "UnmarshalT", prefix, opts)
+}
+
+func typedGet[T any](c *Configuration, method string, key string, opts []Option) (T, error) {
+	var res T
+	typ := reflect.TypeOf(&res).Elem()
+	// Option是函数无法比较，传入时不使用缓存
+	cacheable := len(opts) == 0
+	k := typedKey{key: key, typ: typ}
+	generation := c.typedGeneration.Load()
+	if cacheable {
+		if v, ok := c.typed.Load(k); ok {
+			if tv := v.(typedValue); tv.generation == generation {
+				if tv.err != nil {
+					return res, tv.err
+				}
+				return tv.value.(T), nil
+			}
+		}
+	}
+
+	err := c.UnmarshalKey(key, &res, opts...)
+	if err != nil {
+		err = fmt.Errorf("econf %s %s as %s, err: %w", method, key, typ, err)
+	}
+	if cacheable {
+		c.typed.Store(k, typedValue{generation: generation, value: res, err: err})
+	}
+	return res, err
+}
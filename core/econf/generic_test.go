@@ -0,0 +1,71 @@
+package econf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+type typedServer struct {
+	Port        int
+	ReadTimeout time.Duration
+	Hosts       []string
+}
+
+func TestGetT(t *testing.T) {
+	c := New()
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`
+[server.http]
+port = "9001"
+readTimeout = "3s"
+hosts = ["a", "b"]
+debug = true
+`), toml.Unmarshal))
+
+	timeout, err := GetTFrom[time.Duration](c, "server.http.readTimeout")
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, timeout)
+
+	port, err := GetTFrom[int](c, "server.http.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 9001, port)
+
+	hosts, err := GetTFrom[[]string](c, "server.http.hosts")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	_, err = GetTFrom[int](c, "server.http.debug")
+	assert.ErrorContains(t, err, "econf GetT server.http.debug as int")
+
+	_, err = GetTFrom[string](c, "server.http.notExist")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	server, err := UnmarshalTFrom[typedServer](c, "server.http")
+	assert.NoError(t, err)
+	assert.Equal(t, typedServer{Port: 9001, ReadTimeout: 3 * time.Second, Hosts: []string{"a", "b"}}, server)
+}
+
+func TestGetTCache(t *testing.T) {
+	c := New()
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`timeout = "1s"`), toml.Unmarshal))
+	timeout, err := GetTFrom[time.Duration](c, "timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+	_, ok := c.typed.Load(typedKey{key: "timeout", typ: reflect.TypeOf(time.Duration(0))})
+	assert.True(t, ok)
+
+	// 配置变更后缓存失效
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`timeout = "2s"`), toml.Unmarshal))
+	timeout, err = GetTFrom[time.Duration](c, "timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, timeout)
+
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`timeout = "2x"`), toml.Unmarshal))
+	_, err = GetTFrom[time.Duration](c, "timeout")
+	assert.ErrorContains(t, err, "econf GetT timeout as time.Duration")
+}
@@ -0,0 +1,199 @@
+package econf
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// TemplateFunc 配置值中可以使用的模板函数，例如 ${file(/etc/secret/password)}
+type TemplateFunc func(arg string) (string, error)
+
+var (
+	templateMu    sync.RWMutex
+	templateFuncs = map[string]TemplateFunc{
+		"file":         readFileFunc,
+		"base64decode": base64DecodeFunc,
+	}
+)
+
+// RegisterTemplateFunc 注册配置值中可以使用的模板函数，重名时覆盖
+func RegisterTemplateFunc(name string, fn TemplateFunc) {
+	templateMu.Lock()
+	defer templateMu.Unlock()
+	templateFuncs[name] = fn
+}
+
+func readFileFunc(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// 挂载的secret文件通常以换行结尾
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func base64DecodeFunc(arg string) (string, error) {
+	content, err := base64.StdEncoding.DecodeString(arg)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// EnableInterpolate 开启配置值的表达式替换，需要在加载配置之前调用
+// 默认关闭，开启后配置中原样使用的 ${ 需要写成 $${，环境变量不存在且没有默认值时加载失败
+func (c *Configuration) EnableInterpolate() {
+	c.interpolate.Store(true)
+}
+
+// EnableInterpolate 开启默认配置的表达式替换
+func EnableInterpolate() {
+	defaultConfiguration.EnableInterpolate()
+}
+
+// interpolate 替换配置中所有字符串值的 ${ENV_VAR:default} 和 ${func(arg)} 表达式
+func interpolate(value interface{}) (interface{}, error) {
+	switch val := value.(type) {
+	case string:
+		return expand(val)
+	case map[string]interface{}:
+		for k, v := range val {
+			out, err := interpolate(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			val[k] = out
+		}
+	case []interface{}:
+		for i, v := range val {
+			out, err := interpolate(v)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			val[i] = out
+		}
+	case []map[string]interface{}:
+		for i, v := range val {
+			if _, err := interpolate(v); err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	return value, nil
+}
+
+// expand 替换字符串中的表达式，$${ 表示 ${ 本身
+// 表达式可以嵌套，例如 ${base64decode(${PASSWORD})}、${HOST:${DEFAULT_HOST}}
+func expand(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "$${") {
+			out.WriteString("${")
+			i += 2
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			out.WriteByte(s[i])
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("unclosed expression %q", s[i:])
+		}
+		res, err := eval(s[i+2 : end])
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(res)
+		i = end
+	}
+	return out.String(), nil
+}
+
+// closingBrace 返回与表达式开头匹配的 } 位置
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// eval 计算单个表达式，func(arg) 调用模板函数，否则读取环境变量，不存在时使用默认值
+// 环境变量不存在且没有默认值时返回错误，避免配置被静默替换为空，需要空值时使用 ${ENV_VAR:}，需要 ${ 本身时使用 $${
+func eval(expr string) (string, error) {
+	if idx := strings.IndexByte(expr, '('); idx > 0 && strings.HasSuffix(expr, ")") {
+		name := expr[:idx]
+		templateMu.RLock()
+		fn, ok := templateFuncs[name]
+		templateMu.RUnlock()
+		if ok {
+			arg, err := expand(expr[idx+1 : len(expr)-1])
+			if err != nil {
+				return "", err
+			}
+			res, err := fn(arg)
+			if err != nil {
+				return "", fmt.Errorf("template func %s fail, %w", name, err)
+			}
+			return res, nil
+		}
+	}
+	name, def, hasDefault := strings.Cut(expr, ":")
+	if val, ok := os.LookupEnv(name); ok {
+		return val, nil
+	}
+	if !hasDefault {
+		return "", fmt.Errorf("env %s not set and no default, use ${%s:} for empty value or $${ to escape", name, name)
+	}
+	return expand(def)
+}
+
+// stringToBasicTypeHookFunc 解析时把字符串转换为数字、布尔类型，插值后的值都是字符串，例如 port = "${PORT:9001}"
+func stringToBasicTypeHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String {
+			return data, nil
+		}
+		str := reflect.ValueOf(data).String()
+		// 转换失败时保持原值，由mapstructure返回类型错误
+		switch to.Kind() {
+		case reflect.Bool:
+			if v, err := strconv.ParseBool(str); err == nil {
+				return v, nil
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v, err := strconv.ParseInt(str, 0, to.Bits()); err == nil {
+				return v, nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v, err := strconv.ParseUint(str, 0, to.Bits()); err == nil {
+				return v, nil
+			}
+		case reflect.Float32, reflect.Float64:
+			if v, err := strconv.ParseFloat(str, to.Bits()); err == nil {
+				return v, nil
+			}
+		}
+		return data, nil
+	}
+}
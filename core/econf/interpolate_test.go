@@ -0,0 +1,114 @@
+package econf
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("EGO_TEST_HOST", "10.0.0.1")
+	t.Setenv("EGO_TEST_SECRET", "cGFzc3dvcmQ=")
+	secret := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(secret, []byte("from-file\n"), 0600))
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "${EGO_TEST_HOST}:9001", want: "10.0.0.1:9001"},
+		{in: "${EGO_TEST_NOT_EXIST:}", want: ""},
+		{in: "${EGO_TEST_NOT_EXIST:127.0.0.1}", want: "127.0.0.1"},
+		{in: "${EGO_TEST_NOT_EXIST:http://${EGO_TEST_HOST}}", want: "http://10.0.0.1"},
+		{in: "${base64decode(${EGO_TEST_SECRET})}", want: "password"},
+		{in: "${file(" + secret + ")}", want: "from-file"},
+		{in: "$${EGO_TEST_HOST}", want: "${EGO_TEST_HOST}"},
+		{in: "$${EGO_TEST_NOT_EXIST}", want: "${EGO_TEST_NOT_EXIST}"},
+		{in: "echo $${HOME} > ${EGO_TEST_HOST}", want: "echo ${HOME} > 10.0.0.1"},
+	}
+	for _, tt := range tests {
+		got, err := expand(tt.in)
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	_, err := expand("${EGO_TEST_HOST")
+	assert.ErrorContains(t, err, "unclosed expression")
+	// 环境变量不存在且没有默认值时返回错误，不会静默替换为空
+	_, err = expand("${EGO_TEST_NOT_EXIST}")
+	assert.ErrorContains(t, err, "env EGO_TEST_NOT_EXIST not set and no default")
+	_, err = expand("${file(/not/exist)}")
+	assert.ErrorContains(t, err, "template func file fail")
+
+	RegisterTemplateFunc("upper", func(arg string) (string, error) {
+		return string(bytes.ToUpper([]byte(arg))), nil
+	})
+	got, err := expand("${upper(ego)}")
+	assert.NoError(t, err)
+	assert.Equal(t, "EGO", got)
+}
+
+func TestLoadInterpolate(t *testing.T) {
+	t.Setenv("EGO_TEST_PORT", "9002")
+	v := New()
+	v.EnableInterpolate()
+	ds := &mockDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`
+[server]
+host = "${EGO_TEST_HOST:0.0.0.0}"
+port = "${EGO_TEST_PORT:9001}"
+enable = "${EGO_TEST_ENABLE:true}"
+timeout = "${EGO_TEST_TIMEOUT:1s}"
+addrs = ["${EGO_TEST_HOST:127.0.0.1}:6379"]
+`), 0640))
+	assert.NoError(t, v.LoadFromDataSource(ds, toml.Unmarshal))
+	assert.Equal(t, "0.0.0.0", v.GetString("server.host"))
+	assert.Equal(t, []string{"127.0.0.1:6379"}, v.GetStringSlice("server.addrs"))
+
+	var config struct {
+		Host    string
+		Port    int
+		Enable  bool
+		Timeout time.Duration
+	}
+	assert.NoError(t, v.UnmarshalKey("server", &config))
+	assert.Equal(t, 9002, config.Port)
+	assert.True(t, config.Enable)
+	assert.Equal(t, time.Second, config.Timeout)
+
+	// 热加载时重新读取环境变量
+	t.Setenv("EGO_TEST_HOST", "10.0.0.2")
+	assert.NoError(t, v.Reload())
+	assert.Equal(t, "10.0.0.2", v.GetString("server.host"))
+}
+
+func TestLoadInterpolateUndefined(t *testing.T) {
+	v := New()
+	v.EnableInterpolate()
+	ds := &mockDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`
+[mysql]
+dsn = "root:${EGO_TEST_NOT_EXIST}@tcp(127.0.0.1:3306)/ego"
+`), 0640))
+	err := v.LoadFromDataSource(ds, toml.Unmarshal)
+	assert.ErrorContains(t, err, "mysql: dsn: env EGO_TEST_NOT_EXIST not set and no default")
+}
+
+func TestLoadInterpolateDisabled(t *testing.T) {
+	// 默认不替换，兼容配置值中原本就包含 ${ 的情况
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`
+[server]
+host = "${EGO_TEST_HOST:0.0.0.0}"
+script = "echo ${HOME}"
+`), toml.Unmarshal))
+	assert.Equal(t, "${EGO_TEST_HOST:0.0.0.0}", v.GetString("server.host"))
+	assert.Equal(t, "echo ${HOME}", v.GetString("server.script"))
+}
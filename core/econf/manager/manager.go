@@ -7,9 +7,11 @@ import (
 	"os"
 
 	"github.com/BurntSushi/toml"
+	"github.com/titanous/json5"
 	"gopkg.in/yaml.v3"
 
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eextension"
 )
 
 var defaultScheme = "file"
@@ -24,9 +26,12 @@ var (
 	registry                 map[string]econf.DataSource
 
 	unmarshallers = map[econf.ConfigType]econf.Unmarshaller{
-		econf.ConfigTypeJSON: json.Unmarshal,
-		econf.ConfigTypeToml: toml.Unmarshal,
-		econf.ConfigTypeYaml: yaml.Unmarshal,
+		econf.ConfigTypeJSON:  json.Unmarshal,
+		econf.ConfigTypeToml:  toml.Unmarshal,
+		econf.ConfigTypeYaml:  yaml.Unmarshal,
+		econf.ConfigTypeJSON5: json5.Unmarshal,
+		econf.ConfigTypeHCL:   hclUnmarshal,
+		econf.ConfigTypeIni:   iniUnmarshal,
 	}
 )
 
@@ -37,13 +42,35 @@ func init() {
 	registry = make(map[string]econf.DataSource)
 }
 
-// Register registers a dataSource creator function to the registry.
+// Register registers a dataSource creator function to the registry, it overrides the existing one.
+// The registration is listed on governor /extensions, and the conflict is recorded when different packages register the same scheme.
 func Register(scheme string, creator econf.DataSource) {
+	_ = eextension.Register(eextension.KindConfigDataSource, scheme)
 	registry[scheme] = creator
 }
 
+// RegisterUnmarshaller registers an unmarshaller for the config type, it overrides the existing one.
+func RegisterUnmarshaller(typ econf.ConfigType, unmarshaller econf.Unmarshaller) {
+	_ = eextension.Register(eextension.KindConfigUnmarshaller, string(typ))
+	unmarshallers[typ] = unmarshaller
+}
+
+// Unmarshaller returns the unmarshaller of the config type.
+func Unmarshaller(typ econf.ConfigType) (econf.Unmarshaller, error) {
+	parser, ok := unmarshallers[typ]
+	if !ok {
+		return nil, ErrInvalidUnmarshaller
+	}
+	return parser, nil
+}
+
 // NewDataSource constructs a new configuration provider by supplied config address.
 func NewDataSource(configAddr string, watch bool) (econf.DataSource, econf.Unmarshaller, econf.ConfigType, error) {
+	return NewDataSourceWithFormat(configAddr, watch, "")
+}
+
+// NewDataSourceWithFormat constructs a new configuration provider, the config type is specified by format instead of the data source when format is not empty.
+func NewDataSourceWithFormat(configAddr string, watch bool, format econf.ConfigType) (econf.DataSource, econf.Unmarshaller, econf.ConfigType, error) {
 	var scheme = defaultScheme
 	urlObj, err := url.Parse(configAddr)
 	if err == nil && len(urlObj.Scheme) > 1 {
@@ -63,6 +90,9 @@ func NewDataSource(configAddr string, watch bool) (econf.DataSource, econf.Unmar
 		return nil, nil, "", ErrInvalidDataSource
 	}
 	tag := creatorFunc.Parse(configAddr, watch)
+	if format != "" {
+		tag = format
+	}
 
 	parser, flag := unmarshallers[tag]
 	if !flag {
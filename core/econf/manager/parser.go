@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"gopkg.in/ini.v1"
+)
+
+// hclUnmarshal 解析HCL配置，HCL的block会被解析为 []map[string]interface{}，这里合并为嵌套的map
+func hclUnmarshal(content []byte, v interface{}) error {
+	out, ok := v.(*map[string]interface{})
+	if !ok {
+		return hcl.Unmarshal(content, v)
+	}
+	conf := make(map[string]interface{})
+	if err := hcl.Unmarshal(content, &conf); err != nil {
+		return err
+	}
+	*out = mergeBlocks(conf)
+	return nil
+}
+
+// mergeBlocks 合并同名的block，例如 server "http" {} 和 server "grpc" {} 合并为 server.http、server.grpc
+func mergeBlocks(conf map[string]interface{}) map[string]interface{} {
+	for k, v := range conf {
+		switch val := v.(type) {
+		case []map[string]interface{}:
+			merged := make(map[string]interface{})
+			for _, block := range val {
+				for bk, bv := range mergeBlocks(block) {
+					if exist, ok := merged[bk].(map[string]interface{}); ok {
+						if sub, ok := bv.(map[string]interface{}); ok {
+							for sk, sv := range sub {
+								exist[sk] = sv
+							}
+							continue
+						}
+					}
+					merged[bk] = bv
+				}
+			}
+			conf[k] = merged
+		case map[string]interface{}:
+			conf[k] = mergeBlocks(val)
+		}
+	}
+	return conf
+}
+
+// iniUnmarshal 解析ini配置，section名中的 . 表示层级，例如 [server.http]
+func iniUnmarshal(content []byte, v interface{}) error {
+	out, ok := v.(*map[string]interface{})
+	if !ok {
+		return fmt.Errorf("ini unmarshal, err: unsupported type %T", v)
+	}
+	file, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, content)
+	if err != nil {
+		return err
+	}
+	conf := make(map[string]interface{})
+	for _, section := range file.Sections() {
+		target := conf
+		if section.Name() != ini.DefaultSection {
+			for _, name := range strings.Split(section.Name(), ".") {
+				sub, ok := target[name].(map[string]interface{})
+				if !ok {
+					sub = make(map[string]interface{})
+					target[name] = sub
+				}
+				target = sub
+			}
+		}
+		for _, key := range section.Keys() {
+			target[key.Name()] = key.Value()
+		}
+	}
+	*out = conf
+	return nil
+}
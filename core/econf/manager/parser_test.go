@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestParsers(t *testing.T) {
+	tests := []struct {
+		typ     econf.ConfigType
+		content string
+	}{
+		{
+			typ: econf.ConfigTypeJSON5,
+			content: `{
+  // 注释
+  name: 'svc',
+  server: {
+    http: {port: 9001, tags: ["a", "b",],},
+    grpc: {port: 9002},
+  },
+}`,
+		},
+		{
+			typ: econf.ConfigTypeHCL,
+			content: `
+name = "svc"
+server "http" {
+  port = 9001
+  tags = ["a", "b"]
+}
+server "grpc" {
+  port = 9002
+}`,
+		},
+		{
+			typ: econf.ConfigTypeIni,
+			content: `
+name = svc
+[server.http]
+port = 9001
+tags = a,b
+[server.grpc]
+port = 9002`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.typ), func(t *testing.T) {
+			parser, err := Unmarshaller(tt.typ)
+			assert.NoError(t, err)
+			v := econf.New()
+			assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(tt.content), parser))
+			assert.Equal(t, "svc", v.GetString("name"))
+			assert.Equal(t, 9001, v.GetInt("server.http.port"))
+			assert.Equal(t, 9002, v.GetInt("server.grpc.port"))
+
+			var config struct {
+				Port int
+			}
+			assert.NoError(t, v.UnmarshalKey("server.http", &config))
+			assert.Equal(t, 9001, config.Port)
+		})
+	}
+
+	_, err := Unmarshaller("xml")
+	assert.ErrorIs(t, err, ErrInvalidUnmarshaller)
+	RegisterUnmarshaller("xml", func([]byte, interface{}) error { return nil })
+	_, err = Unmarshaller("xml")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,239 @@
+package econf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gotomicro/ego/core/util/xmap"
+)
+
+// MergeKey 配置中声明数组合并策略的配置项，配置加载、热加载、Apply时生效，例如
+//
+//	[ego.config.merge]
+//	"server.http.middlewares" = "append"
+//	"rules" = "mergeByKey:name"
+const MergeKey = "ego.config.merge"
+
+// 数组的合并策略
+const (
+	// MergeReplace 新的数组替换原来的数组，默认策略
+	MergeReplace = "replace"
+	// MergeAppend 新的数组追加到原来的数组之后，相同的元素只保留一个，重复加载同一份配置不会产生重复元素
+	MergeAppend = "append"
+	// MergeByKey 数组元素为table时，按照指定字段合并，例如 mergeByKey:name
+	// 字段相同的元素合并，新元素中的配置项覆盖原来的配置项，其他元素按照 append 处理
+	MergeByKey = "mergeByKey"
+)
+
+// mergeStrategy 数组的合并策略
+type mergeStrategy struct {
+	kind string
+	key  string // mergeByKey 的字段
+}
+
+func parseMergeStrategy(value string) (mergeStrategy, error) {
+	kind, key, _ := strings.Cut(value, ":")
+	switch kind {
+	case MergeReplace, MergeAppend:
+		if key != "" {
+			return mergeStrategy{}, fmt.Errorf("merge strategy %s does not accept key", kind)
+		}
+	case MergeByKey:
+		if key == "" {
+			return mergeStrategy{}, fmt.Errorf("merge strategy %s requires key, such as %s:name", kind, kind)
+		}
+	default:
+		return mergeStrategy{}, fmt.Errorf("unknown merge strategy %q", value)
+	}
+	return mergeStrategy{kind: kind, key: key}, nil
+}
+
+// SetMergeStrategy 设置配置项的数组合并策略，key为数组的完整路径，例如 server.http.middlewares
+// 与配置中 ego.config.merge 声明的策略作用相同，后设置的覆盖先设置的
+func (c *Configuration) SetMergeStrategy(key string, strategy string) error {
+	s, err := parseMergeStrategy(strategy)
+	if err != nil {
+		return fmt.Errorf("econf SetMergeStrategy %s, err: %w", key, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setMergeStrategy(key, s)
+	return nil
+}
+
+// SetMergeStrategy 设置默认配置的数组合并策略
+func SetMergeStrategy(key string, strategy string) error {
+	return defaultConfiguration.SetMergeStrategy(key, strategy)
+}
+
+func (c *Configuration) setMergeStrategy(key string, s mergeStrategy) {
+	if c.mergeStrategies == nil {
+		c.mergeStrategies = make(map[string]mergeStrategy)
+	}
+	c.mergeStrategies[key] = s
+}
+
+// loadMergeStrategies 读取配置中声明的合并策略，需要持有写锁
+func (c *Configuration) loadMergeStrategies(conf map[string]interface{}) error {
+	node := interface{}(conf)
+	for _, path := range strings.Split(MergeKey, defaultKeyDelim) {
+		m, ok := toStringMap(node)
+		if !ok {
+			return nil
+		}
+		if node, ok = m[path]; !ok {
+			return nil
+		}
+	}
+	declared, ok := toStringMap(node)
+	if !ok {
+		return fmt.Errorf("%s should be a table", MergeKey)
+	}
+	strategies := make(map[string]mergeStrategy, len(declared))
+	for key, value := range declared {
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s.%s should be a string", MergeKey, key)
+		}
+		s, err := parseMergeStrategy(str)
+		if err != nil {
+			return fmt.Errorf("%s.%s, %w", MergeKey, key, err)
+		}
+		strategies[key] = s
+	}
+	// 全部校验通过之后再生效，避免只生效一部分
+	for key, s := range strategies {
+		c.setMergeStrategy(key, s)
+	}
+	return nil
+}
+
+// merge 合并配置，没有声明合并策略时与原来的合并方式一致，数组整体替换
+func (c *Configuration) merge(dest, src map[string]interface{}) {
+	if len(c.mergeStrategies) == 0 {
+		xmap.MergeStringMap(dest, src)
+		return
+	}
+	c.mergeWithStrategies(dest, src, "")
+}
+
+func (c *Configuration) mergeWithStrategies(dest, src map[string]interface{}, prefix string) {
+	for sk, sv := range src {
+		key := sk
+		if prefix != "" {
+			key = prefix + c.keyDelim + sk
+		}
+		tv, ok := dest[sk]
+		if !ok {
+			dest[sk] = sv
+			continue
+		}
+		if s, ok := c.mergeStrategies[key]; ok && s.kind != MergeReplace {
+			if merged, ok := s.mergeSlice(tv, sv); ok {
+				dest[sk] = merged
+				continue
+			}
+		}
+		if reflect.TypeOf(sv) != reflect.TypeOf(tv) {
+			continue
+		}
+		ttv, tok := toStringMap(tv)
+		ssv, sok := toStringMap(sv)
+		if tok && sok {
+			c.mergeWithStrategies(ttv, ssv, key)
+			dest[sk] = ttv
+			continue
+		}
+		dest[sk] = sv
+	}
+}
+
+// mergeSlice 合并两个数组，任意一个不是数组时返回false
+func (s mergeStrategy) mergeSlice(dest, src interface{}) ([]interface{}, bool) {
+	dst, ok := toSlice(dest)
+	if !ok {
+		return nil, false
+	}
+	items, ok := toSlice(src)
+	if !ok {
+		return nil, false
+	}
+	res := append(make([]interface{}, 0, len(dst)+len(items)), dst...)
+	for _, item := range items {
+		if s.kind == MergeByKey {
+			if idx := s.indexByKey(res, item); idx >= 0 {
+				orig, _ := toStringMap(res[idx])
+				merged := make(map[string]interface{}, len(orig))
+				for k, v := range orig {
+					merged[k] = v
+				}
+				next, _ := toStringMap(item)
+				xmap.MergeStringMap(merged, next)
+				res[idx] = merged
+				continue
+			}
+		}
+		if !containsItem(res, item) {
+			res = append(res, item)
+		}
+	}
+	return res, true
+}
+
+// indexByKey 返回字段值相同的元素的下标，元素不是table或者没有该字段时返回-1
+func (s mergeStrategy) indexByKey(items []interface{}, item interface{}) int {
+	m, ok := toStringMap(item)
+	if !ok {
+		return -1
+	}
+	value, ok := m[s.key]
+	if !ok {
+		return -1
+	}
+	for i, orig := range items {
+		om, ok := toStringMap(orig)
+		if !ok {
+			continue
+		}
+		if ov, ok := om[s.key]; ok && reflect.DeepEqual(ov, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsItem(items []interface{}, item interface{}) bool {
+	for _, orig := range items {
+		if reflect.DeepEqual(orig, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// toSlice 不同的解析器返回的数组类型不同，例如toml的table数组为[]map[string]interface{}
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if s, ok := v.([]interface{}); ok {
+		return s, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	res := make([]interface{}, rv.Len())
+	for i := range res {
+		res[i] = rv.Index(i).Interface()
+	}
+	return res, true
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		return xmap.ToMapStringInterface(m), true
+	}
+	return nil, false
+}
@@ -0,0 +1,85 @@
+package econf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeRule struct {
+	Name  string
+	Limit int
+}
+
+func TestMergeStrategy(t *testing.T) {
+	base := `
+[ego.config.merge]
+"server.http.middlewares" = "append"
+"rules" = "mergeByKey:name"
+
+[server.http]
+middlewares = ["access", "trace"]
+hosts = ["a"]
+
+[[rules]]
+name = "foo"
+limit = 1
+[[rules]]
+name = "bar"
+limit = 2
+`
+	layer := `
+[server.http]
+middlewares = ["trace", "authz"]
+hosts = ["b"]
+
+[[rules]]
+name = "bar"
+limit = 20
+[[rules]]
+name = "baz"
+limit = 3
+`
+	c := New()
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(base), toml.Unmarshal))
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(layer), toml.Unmarshal))
+	// 重复加载同一份配置不会产生重复元素
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(layer), toml.Unmarshal))
+
+	assert.Equal(t, []string{"access", "trace", "authz"}, c.GetStringSlice("server.http.middlewares"))
+	// 没有声明策略的数组整体替换
+	assert.Equal(t, []string{"b"}, c.GetStringSlice("server.http.hosts"))
+
+	var rules []mergeRule
+	assert.NoError(t, c.UnmarshalKey("rules", &rules))
+	assert.Equal(t, []mergeRule{{Name: "foo", Limit: 1}, {Name: "bar", Limit: 20}, {Name: "baz", Limit: 3}}, rules)
+}
+
+func TestSetMergeStrategy(t *testing.T) {
+	c := New()
+	assert.NoError(t, c.SetMergeStrategy("hosts", MergeAppend))
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`hosts = ["a"]`), toml.Unmarshal))
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`hosts = ["b"]`), toml.Unmarshal))
+	assert.Equal(t, []string{"a", "b"}, c.GetStringSlice("hosts"))
+
+	// 改回默认策略
+	assert.NoError(t, c.SetMergeStrategy("hosts", MergeReplace))
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`hosts = ["c"]`), toml.Unmarshal))
+	assert.Equal(t, []string{"c"}, c.GetStringSlice("hosts"))
+
+	assert.EqualError(t, c.SetMergeStrategy("hosts", "prepend"), `econf SetMergeStrategy hosts, err: unknown merge strategy "prepend"`)
+	assert.EqualError(t, c.SetMergeStrategy("hosts", MergeByKey), "econf SetMergeStrategy hosts, err: merge strategy mergeByKey requires key, such as mergeByKey:name")
+}
+
+func TestMergeStrategyInvalid(t *testing.T) {
+	c := New()
+	err := c.LoadFromReader(strings.NewReader(`
+[ego.config.merge]
+"hosts" = "mergeByKey"
+`), toml.Unmarshal)
+	assert.EqualError(t, err, "ego.config.merge.hosts, merge strategy mergeByKey requires key, such as mergeByKey:name")
+	// 校验失败时不加载配置
+	assert.Nil(t, c.Get("ego.config.merge"))
+}
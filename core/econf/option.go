@@ -23,3 +23,10 @@ func WithSquash(squash bool) Option {
 		o.Squash = squash
 	}
 }
+
+// WithErrorUnused sets if return error when config has keys not in struct
+func WithErrorUnused(errorUnused bool) Option {
+	return func(o *Container) {
+		o.ErrorUnused = errorUnused
+	}
+}
@@ -0,0 +1,63 @@
+package econf
+
+import (
+	"sync"
+	"time"
+)
+
+// ReloadStats 配置热加载的统计，用于监控推送的配置是否生效
+type ReloadStats struct {
+	WatchEvents int64     // 数据源通知配置变化的次数
+	Attempts    int64     // 热加载的次数
+	Successes   int64     // 热加载成功的次数
+	Failures    int64     // 热加载失败的次数，包括读取、解析失败和组件无法使用新配置回滚
+	Rebuilds    int64     // 热加载后执行的 OnChange、OnApply 回调次数，即触发的组件重建次数
+	LastSuccess time.Time // 最近一次加载成功的时间，包括首次加载
+	LastFailure time.Time // 最近一次热加载失败的时间
+	LastError   string    // 最近一次热加载失败的错误
+}
+
+// reloadStats 并发安全的统计
+type reloadStats struct {
+	mu    sync.Mutex
+	stats ReloadStats
+}
+
+func (s *reloadStats) update(fn func(stats *ReloadStats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.stats)
+}
+
+func (s *reloadStats) get() ReloadStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// GetReloadStats 返回默认配置热加载的统计
+func GetReloadStats() ReloadStats {
+	return defaultConfiguration.ReloadStats()
+}
+
+// ReloadStats 返回配置热加载的统计
+func (c *Configuration) ReloadStats() ReloadStats {
+	return c.reloadStats.get()
+}
+
+// recordReload 记录一次热加载的结果，rebuilds为执行的回调次数
+func (c *Configuration) recordReload(err error, rebuilds int) {
+	now := time.Now()
+	c.reloadStats.update(func(stats *ReloadStats) {
+		stats.Attempts++
+		stats.Rebuilds += int64(rebuilds)
+		if err != nil {
+			stats.Failures++
+			stats.LastFailure = now
+			stats.LastError = err.Error()
+			return
+		}
+		stats.Successes++
+		stats.LastSuccess = now
+	})
+}
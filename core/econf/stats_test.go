@@ -0,0 +1,39 @@
+package econf
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadStats(t *testing.T) {
+	v := New()
+	ds := &mockDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "v1"`), 0640))
+	assert.NoError(t, v.LoadFromDataSource(ds, toml.Unmarshal))
+	stats := v.ReloadStats()
+	assert.False(t, stats.LastSuccess.IsZero())
+	assert.Equal(t, int64(0), stats.Attempts)
+
+	v.OnApply(func(c *Configuration) error { return nil })
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "v2"`), 0640))
+	assert.NoError(t, v.Reload())
+	stats = v.ReloadStats()
+	assert.Equal(t, int64(1), stats.Attempts)
+	assert.Equal(t, int64(1), stats.Successes)
+	assert.Equal(t, int64(1), stats.Rebuilds)
+	assert.True(t, stats.LastFailure.IsZero())
+
+	// 读取失败不触发组件重建
+	assert.NoError(t, os.Remove(ds.path))
+	assert.Error(t, v.Reload())
+	stats = v.ReloadStats()
+	assert.Equal(t, int64(2), stats.Attempts)
+	assert.Equal(t, int64(1), stats.Failures)
+	assert.Equal(t, int64(1), stats.Rebuilds)
+	assert.False(t, stats.LastFailure.IsZero())
+	assert.Contains(t, stats.LastError, "ReadConfig")
+}
@@ -0,0 +1,304 @@
+package econf
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 配置项的单位，在结构体字段上通过 unit tag 声明，例如
+//
+//	type Config struct {
+//		MaxBodySize int64         `unit:"size"`     // 支持 "512MB"
+//		ReadTimeout time.Duration `unit:"duration"` // 支持 "1.5s"
+//		SampleRatio float64       `unit:"percent"`  // 支持 "30%"，解析为0.3
+//	}
+//
+// 配置值为数字时保持原值，配置值为字符串且无法解析时返回包含配置项和支持格式的错误
+const (
+	// UnitSize 字节数，单位为B、KB、MB、GB、TB，按照1024换算，KiB、MiB等写法等价
+	UnitSize = "size"
+	// UnitDuration 时间，格式与 time.ParseDuration 一致
+	UnitDuration = "duration"
+	// UnitPercent 百分比，解析为0~1的小数，不带%时按照小数处理
+	UnitPercent = "percent"
+)
+
+const (
+	sizeFormats     = "1024, 512B, 64KB, 512MB, 1.5GB"
+	durationFormats = "300ms, 1.5s, 1m30s, 2h"
+	percentFormats  = "30%, 0.3"
+)
+
+// ByteSize 字节数，配置支持 "512MB" 等写法，例如 econf.GetT[econf.ByteSize]("server.http.maxBodySize")
+type ByteSize int64
+
+// Percent 百分比，配置支持 "30%" 等写法，值为0~1的小数
+type Percent float64
+
+var (
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	percentType  = reflect.TypeOf(Percent(0))
+)
+
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// ParseByteSize 解析字节数，例如 "512MB"、"1.5GB"
+func ParseByteSize(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	idx := strings.IndexFunc(str, unicode.IsLetter)
+	if idx < 0 {
+		idx = len(str)
+	}
+	mult, ok := sizeUnits[strings.ToLower(strings.TrimSpace(str[idx:]))]
+	num, err := strconv.ParseFloat(strings.TrimSpace(str[:idx]), 64)
+	if !ok || err != nil || num < 0 || num*mult > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size %q, accepted formats: %s", s, sizeFormats)
+	}
+	return int64(num * mult), nil
+}
+
+// ParsePercent 解析百分比，例如 "30%" 解析为0.3，"0.3" 解析为0.3
+func ParsePercent(s string) (float64, error) {
+	str := strings.TrimSpace(s)
+	percent := strings.HasSuffix(str, "%")
+	num, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(str, "%")), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percent %q, accepted formats: %s", s, percentFormats)
+	}
+	if percent {
+		num /= 100
+	}
+	return num, nil
+}
+
+// parseDuration 与 time.ParseDuration 一致，错误中包含支持的格式
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, accepted formats: %s", s, durationFormats)
+	}
+	return d, nil
+}
+
+// parseUnit 按照单位解析字符串配置，其他类型的值保持不变
+func parseUnit(unit string, value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	switch unit {
+	case UnitSize:
+		return ParseByteSize(str)
+	case UnitDuration:
+		return parseDuration(str)
+	case UnitPercent:
+		return ParsePercent(str)
+	}
+	return nil, fmt.Errorf("unknown unit %q, accepted units: %s, %s, %s", unit, UnitSize, UnitDuration, UnitPercent)
+}
+
+// unitHookFunc 解析 ByteSize、Percent 类型的字符串配置
+func unitHookFunc() func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String {
+			return data, nil
+		}
+		switch to {
+		case byteSizeType:
+			return parseUnit(UnitSize, data)
+		case percentType:
+			return parseUnit(UnitPercent, data)
+		}
+		return data, nil
+	}
+}
+
+// unitTypes 记录类型中是否有声明了单位的字段，避免每次解析都遍历结构体
+var unitTypes sync.Map
+
+func hasUnitTag(t reflect.Type) bool {
+	if v, ok := unitTypes.Load(t); ok {
+		return v.(bool)
+	}
+	res := checkUnitTag(t, map[reflect.Type]bool{})
+	unitTypes.Store(t, res)
+	return res
+}
+
+func checkUnitTag(t reflect.Type, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get("unit") != "" || checkUnitTag(field.Type, visited) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return checkUnitTag(t.Elem(), visited)
+	}
+	return false
+}
+
+// convertUnits 按照结构体字段的 unit tag 转换配置，返回转换后的副本，不修改原来的配置
+func convertUnits(t reflect.Type, data interface{}, key string, options Container) (interface{}, error) {
+	if t == nil {
+		return data, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if !hasUnitTag(t) {
+		return data, nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := toStringMap(data)
+		if !ok {
+			return data, nil
+		}
+		res := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			res[k] = v
+		}
+		return res, convertStruct(t, res, key, options)
+	case reflect.Slice, reflect.Array:
+		items, ok := toSlice(data)
+		if !ok {
+			return data, nil
+		}
+		res := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := convertUnits(t.Elem(), item, fmt.Sprintf("%s[%d]", key, i), options)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = v
+		}
+		return res, nil
+	case reflect.Map:
+		m, ok := toStringMap(data)
+		if !ok {
+			return data, nil
+		}
+		res := make(map[string]interface{}, len(m))
+		for k, item := range m {
+			v, err := convertUnits(t.Elem(), item, joinKey(key, k), options)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = v
+		}
+		return res, nil
+	}
+	return data, nil
+}
+
+// convertStruct 按照mapstructure的规则匹配字段，字段名不区分大小写
+func convertStruct(t reflect.Type, m map[string]interface{}, key string, options Container) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get(options.TagName)
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && (options.Squash || strings.Contains(opts, "squash")) {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := convertStruct(ft, m, key, options); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		mapKey, ok := findKey(m, name)
+		if !ok {
+			continue
+		}
+		fieldKey := joinKey(key, mapKey)
+		if unit := field.Tag.Get("unit"); unit != "" {
+			v, err := parseUnit(unit, m[mapKey])
+			if err != nil {
+				return fmt.Errorf("%s, %w", fieldKey, err)
+			}
+			m[mapKey] = v
+			continue
+		}
+		v, err := convertUnits(field.Type, m[mapKey], fieldKey, options)
+		if err != nil {
+			return err
+		}
+		m[mapKey] = v
+	}
+	return nil
+}
+
+func findKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + defaultKeyDelim + key
+}
+
+// GetByteSize 返回默认配置中的字节数，解析失败时返回0
+func GetByteSize(key string) int64 {
+	return defaultConfiguration.GetByteSize(key)
+}
+
+// GetByteSize 返回配置中的字节数，支持 "512MB" 等写法，解析失败时返回0，需要错误时使用 GetT[ByteSize]
+func (c *Configuration) GetByteSize(key string) int64 {
+	v, _ := GetTFrom[ByteSize](c, key)
+	return int64(v)
+}
+
+// GetPercent 返回默认配置中的百分比，解析失败时返回0
+func GetPercent(key string) float64 {
+	return defaultConfiguration.GetPercent(key)
+}
+
+// GetPercent 返回配置中的百分比，支持 "30%" 等写法，解析失败时返回0，需要错误时使用 GetT[Percent]
+func (c *Configuration) GetPercent(key string) float64 {
+	v, _ := GetTFrom[Percent](c, key)
+	return float64(v)
+}
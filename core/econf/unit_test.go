@@ -0,0 +1,91 @@
+package econf
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{
+		"1024":  1024,
+		"512B":  512,
+		"64KB":  64 << 10,
+		"512mb": 512 << 20,
+		"1.5GB": 3 << 29,
+		"2 MiB": 2 << 20,
+		"1t":    1 << 40,
+	} {
+		got, err := ParseByteSize(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "MB", "-1KB", "1PB", "1.5.2MB"} {
+		_, err := ParseByteSize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	got, err := ParsePercent("30%")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.3, got, 1e-9)
+	got, err = ParsePercent("0.25")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.25, got, 1e-9)
+	_, err = ParsePercent("abc%")
+	assert.EqualError(t, err, `invalid percent "abc%", accepted formats: 30%, 0.3`)
+}
+
+type unitRule struct {
+	Ratio float64 `unit:"percent"`
+}
+
+type unitConfig struct {
+	MaxBodySize int64         `unit:"size"`
+	BufferSize  int           `unit:"size"`
+	ReadTimeout time.Duration `unit:"duration"`
+	Rules       []unitRule
+	Named       map[string]unitRule
+}
+
+func TestUnmarshalUnits(t *testing.T) {
+	c := New()
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`
+[server]
+maxBodySize = "512MB"
+bufferSize = 4096
+readTimeout = "1.5s"
+ratio = "30%"
+size = "1KB"
+[[server.rules]]
+ratio = "10%"
+[server.named.foo]
+ratio = 0.5
+`), toml.Unmarshal))
+
+	var config unitConfig
+	assert.NoError(t, c.UnmarshalKey("server", &config))
+	assert.Equal(t, int64(512<<20), config.MaxBodySize)
+	assert.Equal(t, 4096, config.BufferSize)
+	assert.Equal(t, 1500*time.Millisecond, config.ReadTimeout)
+	assert.InDelta(t, 0.1, config.Rules[0].Ratio, 1e-9)
+	assert.InDelta(t, 0.5, config.Named["foo"].Ratio, 1e-9)
+	// 原来的配置不变
+	assert.Equal(t, "512MB", c.GetString("server.maxBodySize"))
+
+	assert.Equal(t, int64(1024), c.GetByteSize("server.size"))
+	assert.InDelta(t, 0.3, c.GetPercent("server.ratio"), 1e-9)
+	_, err := GetTFrom[ByteSize](c, "server.readTimeout")
+	assert.ErrorContains(t, err, `invalid size "1.5s", accepted formats: 1024, 512B, 64KB, 512MB, 1.5GB`)
+
+	assert.NoError(t, c.LoadFromReader(strings.NewReader(`
+[server]
+maxBodySize = "512XB"
+`), toml.Unmarshal))
+	err = c.UnmarshalKey("server", &config)
+	assert.EqualError(t, err, `server.maxBodySize, invalid size "512XB", accepted formats: 1024, 512B, 64KB, 512MB, 1.5GB`)
+}
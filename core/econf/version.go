@@ -0,0 +1,265 @@
+package econf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultSnapshotLimit 默认保留的配置快照数
+const defaultSnapshotLimit = 10
+
+const (
+	// SnapshotSourceLoad 首次加载
+	SnapshotSourceLoad = "load"
+	// SnapshotSourceReload 热加载
+	SnapshotSourceReload = "reload"
+	// SnapshotSourceRollback 回滚
+	SnapshotSourceRollback = "rollback"
+)
+
+// Snapshot 加载过的配置快照，版本号从1开始递增
+type Snapshot struct {
+	Version int       `json:"version"`
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"` // load、reload、rollback
+	Good    bool      `json:"good"`   // 所有 OnApply 回调都执行成功
+	Content []byte    `json:"-"`
+}
+
+// RollbackEvent 配置回滚事件
+type RollbackEvent struct {
+	From   int    // 回滚前的版本
+	To     int    // 回滚到的版本
+	Reason string // 回滚原因，手动回滚或者热加载失败的错误
+}
+
+// OnApply 注册配置生效的回调，通常用于根据新配置重建组件
+// 热加载后回调返回错误时，自动回滚到上一个可用的配置快照
+func (c *Configuration) OnApply(fn func(*Configuration) error) {
+	c.mu.Lock()
+	c.onApplies = append(c.onApplies, fn)
+	c.mu.Unlock()
+}
+
+// OnRollback 注册配置回滚的回调
+func (c *Configuration) OnRollback(fn func(RollbackEvent)) {
+	c.mu.Lock()
+	c.onRollbacks = append(c.onRollbacks, fn)
+	c.mu.Unlock()
+}
+
+// SetSnapshotLimit 设置保留的配置快照数，小于等于0时不修改
+func (c *Configuration) SetSnapshotLimit(limit int) {
+	if limit <= 0 {
+		return
+	}
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	c.snapshotLimit = limit
+	if len(c.snapshots) > limit {
+		c.snapshots = append(c.snapshots[:0:0], c.snapshots[len(c.snapshots)-limit:]...)
+	}
+}
+
+// SetSnapshotDir 设置配置快照的落盘目录，已有的快照会立即写入，便于进程重启后排查
+func (c *Configuration) SetSnapshotDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("econf SetSnapshotDir, err: %w", err)
+	}
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	c.snapshotDir = dir
+	var errs []error
+	for _, snapshot := range c.snapshots {
+		errs = append(errs, c.writeSnapshot(snapshot))
+	}
+	return errors.Join(errs...)
+}
+
+// Snapshots 返回保留的配置快照，最新的在前
+func (c *Configuration) Snapshots() []Snapshot {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	out := make([]Snapshot, len(c.snapshots))
+	for i := range c.snapshots {
+		out[len(c.snapshots)-1-i] = c.snapshots[i]
+	}
+	return out
+}
+
+// Rollback 回滚到指定版本的配置快照，version为0时回滚到当前版本之前最近的可用快照
+func (c *Configuration) Rollback(version int) error {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	current, target, ok := c.findSnapshot(version)
+	if !ok {
+		return fmt.Errorf("econf Rollback, err: snapshot version %d not found", version)
+	}
+	return c.rollback(current, target, "manual rollback")
+}
+
+// rollback 加载快照的配置，回滚的配置替换当前配置，热加载中新增的配置项会被移除
+func (c *Configuration) rollback(current int, target Snapshot, reason string) error {
+	c.mu.RLock()
+	unmarshaller := c.unmarshaller
+	c.mu.RUnlock()
+	if unmarshaller == nil {
+		return errors.New("econf Rollback, err: no data source loaded")
+	}
+	configuration := make(map[string]interface{})
+	if err := unmarshaller(target.Content, &configuration); err != nil {
+		return fmt.Errorf("econf Rollback Load, err: %w", err)
+	}
+	if c.interpolate.Load() {
+		if _, err := interpolate(configuration); err != nil {
+			return fmt.Errorf("econf Rollback interpolate, err: %w", err)
+		}
+	}
+	c.mu.Lock()
+	c.override = make(map[string]interface{})
+	c.rawConfig = target.Content
+	c.mu.Unlock()
+	if err := c.apply(configuration); err != nil {
+		return fmt.Errorf("econf Rollback Load, err: %w", err)
+	}
+	// 清理回滚后不存在的配置项，以及查询时缓存的上级配置
+	c.mu.RLock()
+	keys := c.traverse(c.keyDelim)
+	c.mu.RUnlock()
+	c.keyMap.Range(func(k, _ interface{}) bool {
+		if _, ok := keys[k.(string)]; !ok {
+			c.keyMap.Delete(k)
+		}
+		return true
+	})
+	err := c.runOnChanges()
+	c.recordSnapshot(target.Content, SnapshotSourceRollback, err == nil)
+
+	c.mu.RLock()
+	fns := c.onRollbacks
+	c.mu.RUnlock()
+	for _, fn := range fns {
+		fn(RollbackEvent{From: current, To: target.Version, Reason: reason})
+	}
+	if err != nil {
+		return fmt.Errorf("econf Rollback apply, err: %w", err)
+	}
+	return nil
+}
+
+// findSnapshot 返回当前版本和需要回滚到的快照
+func (c *Configuration) findSnapshot(version int) (int, Snapshot, bool) {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	if len(c.snapshots) == 0 {
+		return 0, Snapshot{}, false
+	}
+	current := c.snapshots[len(c.snapshots)-1].Version
+	for i := len(c.snapshots) - 1; i >= 0; i-- {
+		snapshot := c.snapshots[i]
+		if version == 0 && snapshot.Version != current && snapshot.Good {
+			return current, snapshot, true
+		}
+		if version != 0 && snapshot.Version == version {
+			return current, snapshot, true
+		}
+	}
+	return current, Snapshot{}, false
+}
+
+// recordSnapshot 记录加载成功的配置，超过保留数时丢弃最早的快照
+func (c *Configuration) recordSnapshot(content []byte, source string, good bool) {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	c.version++
+	snapshot := Snapshot{Version: c.version, Time: time.Now(), Source: source, Good: good, Content: content}
+	limit := c.snapshotLimit
+	if limit <= 0 {
+		limit = defaultSnapshotLimit
+	}
+	if len(c.snapshots) >= limit {
+		c.snapshots = append(c.snapshots[:0:0], c.snapshots[len(c.snapshots)-limit+1:]...)
+	}
+	c.snapshots = append(c.snapshots, snapshot)
+	_ = c.writeSnapshot(snapshot)
+	if good {
+		_ = c.writeCache(content)
+	}
+}
+
+// writeSnapshot 快照落盘，文件名包含时间和版本，只保留最近的快照文件
+func (c *Configuration) writeSnapshot(snapshot Snapshot) error {
+	if c.snapshotDir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("config.%s.v%d", snapshot.Time.Format("20060102150405.000000"), snapshot.Version)
+	if err := os.WriteFile(filepath.Join(c.snapshotDir, name), snapshot.Content, 0o644); err != nil {
+		return fmt.Errorf("econf write snapshot, err: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(c.snapshotDir, "config.*.v*"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(files)
+	limit := c.snapshotLimit
+	if limit <= 0 {
+		limit = defaultSnapshotLimit
+	}
+	for len(files) > limit {
+		_ = os.Remove(files[0])
+		files = files[1:]
+	}
+	return nil
+}
+
+// LatestSnapshot 返回落盘目录中最新的配置快照内容和文件路径，用于配置中心不可用时降级启动
+func LatestSnapshot(dir string) ([]byte, string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "config.*.v*"))
+	if err != nil {
+		return nil, "", fmt.Errorf("econf LatestSnapshot, err: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, "", fmt.Errorf("econf LatestSnapshot, err: no snapshot in %s", dir)
+	}
+	sort.Strings(files)
+	file := files[len(files)-1]
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("econf LatestSnapshot, err: %w", err)
+	}
+	return content, file, nil
+}
+
+// Snapshots 返回默认配置保留的快照，最新的在前
+func Snapshots() []Snapshot {
+	return defaultConfiguration.Snapshots()
+}
+
+// Rollback 回滚默认配置到指定版本，version为0时回滚到上一个可用的快照
+func Rollback(version int) error {
+	return defaultConfiguration.Rollback(version)
+}
+
+// OnApply 注册默认配置生效的回调，热加载后回调返回错误时自动回滚
+func OnApply(fn func(*Configuration) error) {
+	defaultConfiguration.OnApply(fn)
+}
+
+// OnRollback 注册默认配置回滚的回调
+func OnRollback(fn func(RollbackEvent)) {
+	defaultConfiguration.OnRollback(fn)
+}
+
+// SetSnapshotLimit 设置默认配置保留的快照数
+func SetSnapshotLimit(limit int) {
+	defaultConfiguration.SetSnapshotLimit(limit)
+}
+
+// SetSnapshotDir 设置默认配置快照的落盘目录
+func SetSnapshotDir(dir string) error {
+	return defaultConfiguration.SetSnapshotDir(dir)
+}
@@ -0,0 +1,81 @@
+package econf
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadRollback(t *testing.T) {
+	v := New()
+	ds := &mockDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "v1"`), 0640))
+	assert.NoError(t, v.LoadFromDataSource(ds, toml.Unmarshal))
+
+	var events []RollbackEvent
+	v.OnRollback(func(event RollbackEvent) {
+		events = append(events, event)
+	})
+	v.OnApply(func(c *Configuration) error {
+		if c.GetString("foo") == "bad" {
+			return errors.New("rebuild component fail")
+		}
+		return nil
+	})
+
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`foo = "v2"`), 0640))
+	assert.NoError(t, v.Reload())
+	assert.Equal(t, "v2", v.GetString("foo"))
+
+	// 组件重建失败，自动回滚到版本2，新增的配置项被移除
+	assert.NoError(t, os.WriteFile(ds.path, []byte("foo = \"bad\"\nextra = 1"), 0640))
+	err := v.Reload()
+	assert.ErrorContains(t, err, "rebuild component fail")
+	assert.ErrorContains(t, err, "rollback to version 2")
+	assert.Equal(t, "v2", v.GetString("foo"))
+	assert.Nil(t, v.Get("extra"))
+	assert.Len(t, events, 1)
+	assert.Equal(t, RollbackEvent{From: 3, To: 2, Reason: events[0].Reason}, events[0])
+
+	snapshots := v.Snapshots()
+	assert.Equal(t, []int{4, 3, 2, 1}, []int{snapshots[0].Version, snapshots[1].Version, snapshots[2].Version, snapshots[3].Version})
+	assert.Equal(t, SnapshotSourceRollback, snapshots[0].Source)
+	assert.False(t, snapshots[1].Good)
+
+	// 手动回滚到指定版本
+	assert.NoError(t, v.Rollback(1))
+	assert.Equal(t, "v1", v.GetString("foo"))
+	assert.Len(t, events, 2)
+	assert.ErrorContains(t, v.Rollback(100), "not found")
+}
+
+func TestSnapshotLimitAndDir(t *testing.T) {
+	v := New()
+	for i := 0; i < 5; i++ {
+		v.recordSnapshot([]byte(`foo = "bar"`), SnapshotSourceReload, true)
+	}
+	v.SetSnapshotLimit(3)
+	assert.Len(t, v.Snapshots(), 3)
+	assert.Equal(t, 5, v.Snapshots()[0].Version)
+
+	dir := t.TempDir()
+	assert.NoError(t, v.SetSnapshotDir(dir))
+	v.recordSnapshot([]byte(`foo = "baz"`), SnapshotSourceReload, true)
+	files, _ := filepath.Glob(filepath.Join(dir, "config.*"))
+	assert.Len(t, files, 3)
+	content, err := os.ReadFile(files[len(files)-1])
+	assert.NoError(t, err)
+	assert.Equal(t, `foo = "baz"`, string(content))
+
+	content, file, err := LatestSnapshot(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, files[len(files)-1], file)
+	assert.Equal(t, `foo = "baz"`, string(content))
+	_, _, err = LatestSnapshot(t.TempDir())
+	assert.Error(t, err)
+}
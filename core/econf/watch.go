@@ -0,0 +1,111 @@
+package econf
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WatchOptions WatchKey的选项
+type WatchOptions struct {
+	Debounce time.Duration               // 防抖窗口，窗口内的多次变更只回调一次最新的值
+	OnError  func(key string, err error) // 配置项转换失败或者回调panic时执行，为空时输出到标准日志
+}
+
+// WatchKey 监听默认配置中的配置项，值变化后以旧值、新值回调fn，返回取消监听的函数
+// 回调串行执行，新值按照 GetT 转换为T类型，例如
+//
+//	econf.WatchKey("server.http.readTimeout", econf.WatchOptions{}, func(oldVal, newVal time.Duration) {})
+func WatchKey[T any](key string, opts WatchOptions, fn func(oldVal, newVal T)) (cancel func()) {
+	return WatchKeyFrom(defaultConfiguration, key, opts, fn)
+}
+
+// WatchKeyFrom 监听配置中的配置项，注册时的值作为第一次回调的旧值
+func WatchKeyFrom[T any](c *Configuration, key string, opts WatchOptions, fn func(oldVal, newVal T)) (cancel func()) {
+	w := &keyWatcher[T]{c: c, key: key, opts: opts, fn: fn}
+	// 配置项不存在时旧值为零值
+	w.last, _ = GetTFrom[T](c, key)
+
+	c.mu.Lock()
+	c.watchers[key] = append(c.watchers[key], w.notify)
+	c.mu.Unlock()
+	return w.cancel
+}
+
+type keyWatcher[T any] struct {
+	c    *Configuration
+	key  string
+	opts WatchOptions
+	fn   func(oldVal, newVal T)
+
+	stopped atomic.Bool
+	mu      sync.Mutex // 保证回调串行执行
+	last    T
+	timerMu sync.Mutex
+	timer   *time.Timer
+}
+
+// notify 配置项变化时执行，开启防抖时窗口结束后再读取最新的值
+func (w *keyWatcher[T]) notify(*Configuration) {
+	if w.stopped.Load() {
+		return
+	}
+	if w.opts.Debounce <= 0 {
+		w.check()
+		return
+	}
+	w.timerMu.Lock()
+	defer w.timerMu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.opts.Debounce, w.check)
+}
+
+// check 读取最新的值，与上一次回调的值不同时执行回调
+func (w *keyWatcher[T]) check() {
+	if w.stopped.Load() {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	newVal, err := GetTFrom[T](w.c, w.key)
+	if err != nil {
+		w.error(err)
+		return
+	}
+	if reflect.DeepEqual(w.last, newVal) {
+		return
+	}
+	oldVal := w.last
+	w.last = newVal
+	defer func() {
+		if rec := recover(); rec != nil {
+			w.error(fmt.Errorf("econf WatchKey %s panic: %v\n%s", w.key, rec, debug.Stack()))
+		}
+	}()
+	w.fn(oldVal, newVal)
+}
+
+func (w *keyWatcher[T]) error(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(w.key, err)
+		return
+	}
+	// econf 先于 elog 初始化，无法使用 elog
+	log.Printf("econf WatchKey %s, err: %v", w.key, err)
+}
+
+// cancel 取消后不再回调，正在执行的回调不受影响
+func (w *keyWatcher[T]) cancel() {
+	w.stopped.Store(true)
+	w.timerMu.Lock()
+	defer w.timerMu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
@@ -0,0 +1,98 @@
+package econf
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+)
+
+type watchEvent struct {
+	oldVal, newVal time.Duration
+}
+
+func TestWatchKey(t *testing.T) {
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`[server]
+timeout = "1s"
+name = "a"`), toml.Unmarshal))
+
+	var mu sync.Mutex
+	var events []watchEvent
+	cancel := WatchKeyFrom(v, "server.timeout", WatchOptions{}, func(oldVal, newVal time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, watchEvent{oldVal, newVal})
+	})
+	get := func() []watchEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]watchEvent(nil), events...)
+	}
+
+	// 其他配置项变化不回调
+	assert.NoError(t, v.Set("server.name", "b"))
+	assert.NoError(t, v.Set("server.timeout", "2s"))
+	assert.Eventually(t, func() bool { return len(get()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, watchEvent{time.Second, 2 * time.Second}, get()[0])
+
+	cancel()
+	assert.NoError(t, v.Set("server.timeout", "3s"))
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, get(), 1)
+}
+
+func TestWatchKeyDebounce(t *testing.T) {
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`count = 0`), toml.Unmarshal))
+
+	called := make(chan [2]int, 10)
+	WatchKeyFrom(v, "count", WatchOptions{Debounce: 50 * time.Millisecond}, func(oldVal, newVal int) {
+		called <- [2]int{oldVal, newVal}
+	})
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, v.Set("count", i))
+	}
+	// 窗口内的多次变更只回调最新的值
+	assert.Equal(t, [2]int{0, 5}, <-called)
+	select {
+	case got := <-called:
+		t.Fatalf("unexpected callback %v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchKeyError(t *testing.T) {
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`count = 1`), toml.Unmarshal))
+
+	errs := make(chan error, 10)
+	opts := WatchOptions{OnError: func(key string, err error) {
+		assert.Equal(t, "count", key)
+		errs <- err
+	}}
+	WatchKeyFrom(v, "count", opts, func(oldVal, newVal int) {
+		panic("rebuild fail")
+	})
+	assert.NoError(t, v.Set("count", 2))
+	assert.ErrorContains(t, <-errs, "panic: rebuild fail")
+
+	// 转换失败
+	assert.NoError(t, v.Set("count", "abc"))
+	assert.ErrorContains(t, <-errs, "econf GetT count as int")
+}
+
+func TestWatchKeyAdded(t *testing.T) {
+	v := New()
+	assert.NoError(t, v.LoadFromReader(bytes.NewBufferString(`foo = "bar"`), toml.Unmarshal))
+
+	called := make(chan string, 1)
+	WatchKeyFrom(v, "added", WatchOptions{}, func(oldVal, newVal string) {
+		called <- oldVal + "->" + newVal
+	})
+	assert.NoError(t, v.Set("added", "x"))
+	assert.Equal(t, "->x", <-called)
+}
@@ -0,0 +1,310 @@
+package edegrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.edegrade"
+
+// 降级的原因
+const (
+	// ReasonSwitch 降级开关打开
+	ReasonSwitch = "switch"
+	// ReasonError 调用失败
+	ReasonError = "error"
+)
+
+// 开关状态的来源
+const (
+	// SourceConfig 来自配置
+	SourceConfig = "config"
+	// SourceRuntime 来自运行时设置，例如治理端
+	SourceRuntime = "runtime"
+)
+
+// ErrDegraded 降级开关打开并且功能没有注册降级行为时返回，同时作为开关降级时传给 Fallback 的原因
+var ErrDegraded = errors.New("feature degraded")
+
+// Fallback 功能的降级行为，cause为降级的原因，开关打开时为 ErrDegraded，否则为调用失败的错误
+// 返回 cause 表示无法降级
+type Fallback func(ctx context.Context, cause error) (interface{}, error)
+
+// FeatureOption 功能的可选项
+type FeatureOption func(f *feature)
+
+// Info 功能的降级状态
+type Info struct {
+	Name       string `json:"name"`
+	Registered bool   `json:"registered"` // 是否注册了降级行为
+	Degraded   bool   `json:"degraded"`
+	Source     string `json:"source"` // 开关状态的来源，config或者runtime，没有设置时为空
+	Served     uint64 `json:"served"` // 已经返回的降级响应数
+}
+
+type feature struct {
+	name     string
+	fallback Fallback
+	cacheTTL time.Duration // 大于0时缓存最近一次成功的结果，降级时优先返回
+	served   atomic.Uint64
+
+	mu       sync.RWMutex
+	cached   interface{}
+	cachedAt time.Time
+}
+
+var (
+	mu        sync.RWMutex
+	refreshMu sync.Mutex // 保证状态变化的通知按顺序执行
+	config    = DefaultConfig()
+	overrides = make(map[string]bool)     // 运行时设置的开关，优先于配置
+	features  = make(map[string]*feature) // 注册了降级行为的功能
+	listeners []func(feature string, degraded bool)
+	state     atomic.Value // map[string]bool，当前处于降级状态的功能，用于请求路径上的快速判断
+)
+
+func init() {
+	state.Store(map[string]bool{})
+}
+
+// Static 降级时返回固定的数据
+func Static(value interface{}) Fallback {
+	return func(context.Context, error) (interface{}, error) {
+		return value, nil
+	}
+}
+
+// Skip 降级时跳过该功能，返回空数据，例如跳过非核心的数据补全
+func Skip() Fallback {
+	return Static(nil)
+}
+
+// WithCache 缓存功能最近一次成功的结果，降级时优先返回不超过ttl的缓存数据，没有缓存时再执行降级行为
+func WithCache(ttl time.Duration) FeatureOption {
+	return func(f *feature) {
+		f.cacheTTL = ttl
+	}
+}
+
+// Register 注册功能的降级行为，重复注册会覆盖之前的行为
+func Register(name string, fallback Fallback, options ...FeatureOption) {
+	f := &feature{name: name, fallback: fallback}
+	for _, option := range options {
+		option(f)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	features[name] = f
+}
+
+// Unregister 取消注册功能的降级行为
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(features, name)
+}
+
+// IsDegraded 功能的降级开关是否打开
+func IsDegraded(name string) bool {
+	return state.Load().(map[string]bool)[name]
+}
+
+// Set 运行时打开或者关闭功能的降级开关，优先于配置，配置热更新不会覆盖该状态
+func Set(name string, degraded bool) {
+	mu.Lock()
+	overrides[name] = degraded
+	mu.Unlock()
+	refresh()
+}
+
+// Reset 清除功能运行时设置的开关，重新跟随配置，name为空时清除所有功能
+func Reset(name string) {
+	mu.Lock()
+	if name == "" {
+		overrides = make(map[string]bool)
+	} else {
+		delete(overrides, name)
+	}
+	mu.Unlock()
+	refresh()
+}
+
+// OnChange 注册降级开关状态变化的回调
+func OnChange(fn func(feature string, degraded bool)) {
+	mu.Lock()
+	defer mu.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// Execute 执行功能，降级开关打开时不执行fn，直接返回降级数据，fn失败时也返回降级数据
+// 功能没有注册降级行为时，开关打开返回 ErrDegraded，fn失败返回原来的错误
+func Execute(ctx context.Context, name string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	mu.RLock()
+	f := features[name]
+	mu.RUnlock()
+
+	if IsDegraded(name) {
+		if f == nil {
+			return nil, ErrDegraded
+		}
+		return f.degrade(ctx, ReasonSwitch, ErrDegraded)
+	}
+	value, err := fn(ctx)
+	if f == nil {
+		return value, err
+	}
+	if err != nil {
+		return f.degrade(ctx, ReasonError, err)
+	}
+	f.store(value)
+	return value, nil
+}
+
+// Do 执行功能，返回类型化的结果，参考 Execute
+func Do[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	value, err := Execute(ctx, name, func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+	if err != nil || value == nil {
+		return zero, err
+	}
+	v, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("degrade feature %s returned %T, expect %T", name, value, zero)
+	}
+	return v, nil
+}
+
+// List 所有注册了降级行为或者设置了开关的功能，按照名称排序
+func List() []Info {
+	mu.RLock()
+	infos := make(map[string]*Info, len(features))
+	for name, f := range features {
+		infos[name] = &Info{Name: name, Registered: true, Served: f.served.Load()}
+	}
+	info := func(name string) *Info {
+		if infos[name] == nil {
+			infos[name] = &Info{Name: name}
+		}
+		return infos[name]
+	}
+	for name, degraded := range config.Features {
+		i := info(name)
+		i.Degraded, i.Source = degraded, SourceConfig
+	}
+	for name, degraded := range overrides {
+		i := info(name)
+		i.Degraded, i.Source = degraded, SourceRuntime
+	}
+	mu.RUnlock()
+
+	list := make([]Info, 0, len(infos))
+	for _, i := range infos {
+		list = append(list, *i)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// degrade 优先返回缓存的数据，否则执行降级行为
+func (f *feature) degrade(ctx context.Context, reason string, cause error) (interface{}, error) {
+	if value, ok := f.load(); ok {
+		f.served.Add(1)
+		emetric.DegradeCounter.Inc(f.name, reason, "cache")
+		return value, nil
+	}
+	if f.fallback == nil {
+		return nil, cause
+	}
+	value, err := f.fallback(ctx, cause)
+	if err != nil {
+		emetric.DegradeCounter.Inc(f.name, reason, "fail")
+		return nil, err
+	}
+	f.served.Add(1)
+	emetric.DegradeCounter.Inc(f.name, reason, "fallback")
+	return value, nil
+}
+
+func (f *feature) store(value interface{}) {
+	if f.cacheTTL <= 0 {
+		return
+	}
+	f.mu.Lock()
+	f.cached, f.cachedAt = value, time.Now()
+	f.mu.Unlock()
+}
+
+func (f *feature) load() (interface{}, bool) {
+	if f.cacheTTL <= 0 {
+		return nil, false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.cachedAt.IsZero() || time.Since(f.cachedAt) > f.cacheTTL {
+		return nil, false
+	}
+	return f.cached, true
+}
+
+func setConfig(c *Config) {
+	if c.Features == nil {
+		c.Features = map[string]bool{}
+	}
+	mu.Lock()
+	config = c
+	mu.Unlock()
+	refresh()
+}
+
+// refresh 重新计算所有功能的降级状态，状态变化时通知回调
+func refresh() {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	mu.RLock()
+	next := make(map[string]bool, len(config.Features)+len(overrides))
+	for name, degraded := range config.Features {
+		if degraded {
+			next[name] = true
+		}
+	}
+	for name, degraded := range overrides {
+		if degraded {
+			next[name] = true
+		} else {
+			delete(next, name)
+		}
+	}
+	fns := listeners
+	mu.RUnlock()
+
+	prev := state.Load().(map[string]bool)
+	state.Store(next)
+	notify := func(name string, degraded bool) {
+		elog.EgoLogger.Warn("degrade switch changed", elog.FieldComponent(PackageName), elog.FieldName(name), elog.Bool("degraded", degraded))
+		for _, fn := range fns {
+			fn(name, degraded)
+		}
+	}
+	for name := range next {
+		if !prev[name] {
+			notify(name, true)
+		}
+	}
+	for name := range prev {
+		if !next[name] {
+			notify(name, false)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package edegrade
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestExecute(t *testing.T) {
+	defer Reset("")
+	defer Unregister("test.recommend")
+	Register("test.recommend", Static([]string{"default"}))
+
+	calls := 0
+	fn := func(context.Context) ([]string, error) {
+		calls++
+		return []string{"personal"}, nil
+	}
+	got, err := Do(context.Background(), "test.recommend", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"personal"}, got)
+
+	// 开关打开时不执行fn
+	Set("test.recommend", true)
+	got, err = Do(context.Background(), "test.recommend", fn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, got)
+	assert.Equal(t, 1, calls)
+
+	// 调用失败时执行降级行为
+	Reset("test.recommend")
+	got, err = Do(context.Background(), "test.recommend", func(context.Context) ([]string, error) {
+		return nil, errors.New("unavailable")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, got)
+
+	// 没有注册降级行为
+	Set("test.unknown", true)
+	_, err = Execute(context.Background(), "test.unknown", func(context.Context) (interface{}, error) { return 1, nil })
+	assert.ErrorIs(t, err, ErrDegraded)
+	_, err = Do(context.Background(), "test.recommend", func(context.Context) (int, error) { return 0, errors.New("unavailable") })
+	assert.EqualError(t, err, "degrade feature test.recommend returned []string, expect int")
+
+	assert.Equal(t, []Info{
+		{Name: "test.recommend", Registered: true, Served: 3},
+		{Name: "test.unknown", Degraded: true, Source: SourceRuntime},
+	}, List())
+}
+
+func TestCacheAndSkip(t *testing.T) {
+	defer Reset("")
+	defer Unregister("test.price")
+	defer Unregister("test.enrich")
+	Register("test.price", func(ctx context.Context, cause error) (interface{}, error) {
+		return nil, cause
+	}, WithCache(time.Minute))
+	Register("test.enrich", Skip())
+
+	_, err := Do(context.Background(), "test.price", func(context.Context) (int, error) { return 0, errors.New("unavailable") })
+	assert.EqualError(t, err, "unavailable")
+	got, err := Do(context.Background(), "test.price", func(context.Context) (int, error) { return 100, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 100, got)
+
+	// 降级时返回缓存的数据
+	Set("test.price", true)
+	got, err = Do(context.Background(), "test.price", func(context.Context) (int, error) { return 200, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 100, got)
+
+	Set("test.enrich", true)
+	tags, err := Do(context.Background(), "test.enrich", func(context.Context) ([]string, error) { return []string{"hot"}, nil })
+	assert.NoError(t, err)
+	assert.Nil(t, tags)
+}
+
+func TestOverrideSurvivesConfigReload(t *testing.T) {
+	defer setConfig(DefaultConfig())
+	defer Reset("")
+	var mu sync.Mutex
+	changes := map[string]bool{}
+	OnChange(func(feature string, degraded bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes[feature] = degraded
+	})
+
+	conf := `
+[degrade.features]
+"test.search" = true
+"test.comment" = false
+`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	Load("degrade").Build()
+	assert.True(t, IsDegraded("test.search"))
+	assert.False(t, IsDegraded("test.comment"))
+	assert.Equal(t, map[string]bool{"test.search": true}, changes)
+
+	// 运行时打开后，配置更新不会覆盖运行时开关
+	Set("test.comment", true)
+	Set("test.search", false)
+	setConfig(&Config{Features: map[string]bool{"test.search": true}})
+	assert.False(t, IsDegraded("test.search"))
+	assert.True(t, IsDegraded("test.comment"))
+
+	Reset("")
+	assert.True(t, IsDegraded("test.search"))
+	assert.False(t, IsDegraded("test.comment"))
+	assert.Equal(t, map[string]bool{"test.search": true, "test.comment": false}, changes)
+}
@@ -0,0 +1,13 @@
+package edegrade
+
+// Config 降级开关配置
+type Config struct {
+	Features map[string]bool // 功能的降级开关，key为功能名称，true表示降级，默认都不降级
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Features: map[string]bool{},
+	}
+}
@@ -0,0 +1,238 @@
+package edynconf
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cast"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "core.edynconf"
+
+// Validator 校验配置项的新值，返回错误时保留旧值
+type Validator func(value interface{}) error
+
+// Listener 配置项变化的回调，配置项被删除时value为nil
+type Listener func(key string, old, value interface{})
+
+// Settings 动态配置，读取econf中某个前缀下的配置项
+// 配置变化时整体替换快照，读取时没有锁
+type Settings struct {
+	prefix    string
+	conf      *econf.Configuration
+	defaults  map[string]interface{}
+	logger    *elog.Component
+	snapshot  atomic.Pointer[map[string]interface{}]
+	mu        sync.Mutex // 保证快照按顺序替换
+	validates map[string][]Validator
+	listeners map[string][]Listener
+}
+
+// Option 可选项
+type Option func(s *Settings)
+
+// WithConfiguration 设置配置来源，默认读取econf的默认配置
+func WithConfiguration(conf *econf.Configuration) Option {
+	return func(s *Settings) {
+		s.conf = conf
+	}
+}
+
+// WithDefaults 设置配置项的默认值，配置中不存在该配置项时使用
+func WithDefaults(defaults map[string]interface{}) Option {
+	return func(s *Settings) {
+		for k, v := range defaults {
+			s.defaults[k] = v
+		}
+	}
+}
+
+// New 创建动态配置，prefix为econf中的配置前缀，例如 "dyn"
+// 配置文件热加载后自动更新
+func New(prefix string, opts ...Option) *Settings {
+	s := &Settings{
+		prefix:    prefix,
+		defaults:  make(map[string]interface{}),
+		logger:    elog.EgoLogger.With(elog.FieldComponent(PackageName), elog.FieldComponentName(prefix)),
+		validates: make(map[string][]Validator),
+		listeners: make(map[string][]Listener),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	onChange := econf.OnChange
+	if s.conf != nil {
+		onChange = s.conf.OnChange
+	}
+	s.refresh(s.load())
+	onChange(func(*econf.Configuration) {
+		s.refresh(s.load())
+	})
+	return s
+}
+
+// Validate 注册配置项的校验，热更新时新值校验失败会保留旧值，当前值校验失败时返回错误
+func (s *Settings) Validate(key string, fn Validator) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validates[key] = append(s.validates[key], fn)
+	if value, ok := (*s.snapshot.Load())[key]; ok {
+		if err := fn(value); err != nil {
+			return fmt.Errorf("edynconf validate %s fail, %w", key, err)
+		}
+	}
+	return nil
+}
+
+// OnChange 注册配置项变化的回调，key为空时任意配置项变化都会回调
+func (s *Settings) OnChange(key string, fn Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners[key] = append(s.listeners[key], fn)
+}
+
+// Get 返回配置项的值，不存在时返回nil
+func (s *Settings) Get(key string) interface{} {
+	return (*s.snapshot.Load())[key]
+}
+
+// Exists 配置项是否存在
+func (s *Settings) Exists(key string) bool {
+	_, ok := (*s.snapshot.Load())[key]
+	return ok
+}
+
+// Int 返回int类型的配置项
+func (s *Settings) Int(key string) int {
+	return cast.ToInt(s.Get(key))
+}
+
+// Int64 返回int64类型的配置项
+func (s *Settings) Int64(key string) int64 {
+	return cast.ToInt64(s.Get(key))
+}
+
+// Float64 返回float64类型的配置项
+func (s *Settings) Float64(key string) float64 {
+	return cast.ToFloat64(s.Get(key))
+}
+
+// Bool 返回bool类型的配置项
+func (s *Settings) Bool(key string) bool {
+	return cast.ToBool(s.Get(key))
+}
+
+// String 返回string类型的配置项
+func (s *Settings) String(key string) string {
+	return cast.ToString(s.Get(key))
+}
+
+// Duration 返回time.Duration类型的配置项，支持 "1s" 格式
+func (s *Settings) Duration(key string) time.Duration {
+	return cast.ToDuration(s.Get(key))
+}
+
+// StringSlice 返回[]string类型的配置项
+func (s *Settings) StringSlice(key string) []string {
+	return cast.ToStringSlice(s.Get(key))
+}
+
+// Keys 返回所有配置项
+func (s *Settings) Keys() []string {
+	snapshot := *s.snapshot.Load()
+	keys := make([]string, 0, len(snapshot))
+	for k := range snapshot {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// load 读取配置，多级配置展开为 a.b 格式的key
+func (s *Settings) load() map[string]interface{} {
+	getStringMap := econf.GetStringMap
+	if s.conf != nil {
+		getStringMap = s.conf.GetStringMap
+	}
+	raw := getStringMap(s.prefix)
+	values := make(map[string]interface{}, len(s.defaults)+len(raw))
+	for k, v := range s.defaults {
+		values[k] = v
+	}
+	flatten("", raw, values)
+	return values
+}
+
+func flatten(prefix string, raw map[string]interface{}, out map[string]interface{}) {
+	for k, v := range raw {
+		key := prefix + k
+		if sub, ok := v.(map[string]interface{}); ok {
+			flatten(key+".", sub, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+type change struct {
+	key        string
+	old, value interface{}
+}
+
+// refresh 校验新值，替换快照，然后通知变化的配置项
+func (s *Settings) refresh(values map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var old map[string]interface{}
+	if p := s.snapshot.Load(); p != nil {
+		old = *p
+	}
+	for key, fns := range s.validates {
+		value, ok := values[key]
+		if !ok || reflect.DeepEqual(value, old[key]) {
+			continue
+		}
+		for _, fn := range fns {
+			if err := fn(value); err != nil {
+				s.logger.Error("invalid dynamic config, keep old value", elog.FieldKey(key), elog.FieldValueAny(value), elog.FieldErr(err))
+				if prev, exist := old[key]; exist {
+					values[key] = prev
+				} else {
+					delete(values, key)
+				}
+				break
+			}
+		}
+	}
+	s.snapshot.Store(&values)
+	if old == nil {
+		return
+	}
+
+	var changes []change
+	for key, value := range values {
+		if prev, ok := old[key]; !ok || !reflect.DeepEqual(prev, value) {
+			changes = append(changes, change{key: key, old: old[key], value: value})
+		}
+	}
+	for key, prev := range old {
+		if _, ok := values[key]; !ok {
+			changes = append(changes, change{key: key, old: prev})
+		}
+	}
+	for _, c := range changes {
+		s.logger.Info("dynamic config changed", elog.FieldKey(c.key), elog.FieldValueAny(c.value))
+		for _, fn := range s.listeners[c.key] {
+			fn(c.key, c.old, c.value)
+		}
+		for _, fn := range s.listeners[""] {
+			fn(c.key, c.old, c.value)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package edynconf
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+type fileDataSource struct {
+	path string
+}
+
+func (f *fileDataSource) Parse(string, bool) econf.ConfigType { return econf.ConfigTypeToml }
+func (f *fileDataSource) ReadConfig() ([]byte, error)         { return os.ReadFile(f.path) }
+func (f *fileDataSource) IsConfigChanged() <-chan struct{}    { return nil }
+func (f *fileDataSource) Close() error                        { return nil }
+
+func TestSettings(t *testing.T) {
+	conf := econf.New()
+	ds := &fileDataSource{path: path.Join(t.TempDir(), "config.toml")}
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`
+[dyn]
+timeoutMs = 100
+ratio = 0.5
+enable = true
+interval = "1s"
+hosts = ["a", "b"]
+[dyn.feature]
+name = "v1"
+`), 0640))
+	assert.NoError(t, conf.LoadFromDataSource(ds, toml.Unmarshal))
+
+	dyn := New("dyn", WithConfiguration(conf), WithDefaults(map[string]interface{}{"retry": 3}))
+	assert.Equal(t, 100, dyn.Int("timeoutMs"))
+	assert.Equal(t, int64(100), dyn.Int64("timeoutMs"))
+	assert.Equal(t, 0.5, dyn.Float64("ratio"))
+	assert.True(t, dyn.Bool("enable"))
+	assert.Equal(t, time.Second, dyn.Duration("interval"))
+	assert.Equal(t, []string{"a", "b"}, dyn.StringSlice("hosts"))
+	assert.Equal(t, "v1", dyn.String("feature.name"))
+	assert.Equal(t, 3, dyn.Int("retry"))
+	assert.False(t, dyn.Exists("notExist"))
+
+	assert.NoError(t, dyn.Validate("timeoutMs", func(value interface{}) error {
+		if v := value.(int64); v <= 0 || v > 1000 {
+			return errors.New("timeoutMs must be in (0, 1000]")
+		}
+		return nil
+	}))
+	type event struct {
+		key        string
+		old, value interface{}
+	}
+	var events []event
+	dyn.OnChange("", func(key string, old, value interface{}) {
+		events = append(events, event{key: key, old: old, value: value})
+	})
+
+	// timeoutMs校验失败保留旧值，其他配置项正常更新
+	assert.NoError(t, os.WriteFile(ds.path, []byte(`
+[dyn]
+timeoutMs = 5000
+ratio = 0.8
+enable = true
+interval = "1s"
+hosts = ["a", "b"]
+[dyn.feature]
+name = "v2"
+`), 0640))
+	assert.NoError(t, conf.Reload())
+	assert.Equal(t, 100, dyn.Int("timeoutMs"))
+	assert.Equal(t, 0.8, dyn.Float64("ratio"))
+	assert.Equal(t, "v2", dyn.String("feature.name"))
+	assert.ElementsMatch(t, []event{
+		{key: "ratio", old: 0.5, value: 0.8},
+		{key: "feature.name", old: "v1", value: "v2"},
+	}, events)
+
+	assert.Error(t, dyn.Validate("ratio", func(value interface{}) error {
+		return errors.New("invalid")
+	}))
+}
@@ -0,0 +1,126 @@
+package eextension
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 框架的扩展点，扩展包在init中调用对应的注册函数，业务通过空导入加载扩展，例如 import _ "github.com/xxx/ego-apollo"
+const (
+	// KindConfigDataSource 配置数据源，注册函数为 manager.Register
+	KindConfigDataSource = "econf.datasource"
+	// KindConfigUnmarshaller 配置解析，注册函数为 manager.RegisterUnmarshaller
+	KindConfigUnmarshaller = "econf.unmarshaller"
+	// KindLogWriter 日志输出，注册函数为 elog.Register
+	KindLogWriter = "elog.writer"
+	// KindMetricExporter 指标导出，注册函数为 emetric.RegisterExporter
+	KindMetricExporter = "emetric.exporter"
+	// KindGRPCRegistry gRPC客户端的注册中心，注册函数为 egrpc/resolver.Register
+	KindGRPCRegistry = "egrpc.registry"
+	// KindHTTPRegistry HTTP客户端的注册中心，注册函数为 ehttp/resolver.Register
+	KindHTTPRegistry = "ehttp.registry"
+	// KindNotifyProvider 告警通道，注册函数为 enotify.RegisterProvider
+	KindNotifyProvider = "enotify.provider"
+	// KindCloudEventsSink 生命周期事件通道，注册函数为 ecloudevents.RegisterSink
+	KindCloudEventsSink = "ecloudevents.sink"
+)
+
+// Extension 已经注册的扩展
+type Extension struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Package  string   `json:"package"`            // 生效的注册所在的包
+	Packages []string `json:"packages,omitempty"` // 发生冲突时，按注册顺序记录所有注册过的包
+}
+
+// Conflict 是否有不同的包注册了同一个扩展，后注册的覆盖先注册的
+func (e Extension) Conflict() bool {
+	return len(e.Packages) > 1
+}
+
+type key struct {
+	kind string
+	name string
+}
+
+var (
+	mu         sync.RWMutex
+	extensions = make(map[key]*Extension)
+)
+
+// Register 记录扩展，在框架的注册函数中调用，注册者为调用该注册函数的包
+// 不同的包注册同一个扩展时返回错误，由注册函数决定是否覆盖，同一个包重复注册不算冲突
+func Register(kind, name string) error {
+	return record(kind, name, callerPackage(3))
+}
+
+func record(kind, name, pkg string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	k := key{kind: kind, name: name}
+	ext, ok := extensions[k]
+	if !ok {
+		extensions[k] = &Extension{Kind: kind, Name: name, Package: pkg}
+		return nil
+	}
+	if ext.Package == pkg {
+		return nil
+	}
+	if len(ext.Packages) == 0 {
+		ext.Packages = []string{ext.Package}
+	}
+	prev := ext.Package
+	ext.Package = pkg
+	ext.Packages = append(ext.Packages, pkg)
+	return fmt.Errorf("extension %s %q registered by %s overridden by %s", kind, name, prev, pkg)
+}
+
+// List 返回已经注册的扩展，按类型和名称排序，治理端 /extensions 输出该列表
+func List() []Extension {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Extension, 0, len(extensions))
+	for _, ext := range extensions {
+		list = append(list, *ext)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Conflicts 返回发生冲突的扩展
+func Conflicts() []Extension {
+	list := make([]Extension, 0)
+	for _, ext := range List() {
+		if ext.Conflict() {
+			list = append(list, ext)
+		}
+	}
+	return list
+}
+
+// callerPackage 返回调用栈中第skip层函数所在的包
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	// 函数名的格式为 github.com/gotomicro/ego/core/econf/file.init.0，包路径的最后一段之后的第一个点分隔了函数名
+	name := fn.Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
@@ -0,0 +1,54 @@
+package eextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	extensions = make(map[key]*Extension)
+}
+
+// registerFor 模拟框架的注册函数
+func registerFor(kind, name string) error {
+	return Register(kind, name)
+}
+
+func TestRegister(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.NoError(t, registerFor(KindConfigDataSource, "apollo"))
+	// 同一个包重复注册不算冲突
+	assert.NoError(t, registerFor(KindConfigDataSource, "apollo"))
+	assert.NoError(t, registerFor(KindLogWriter, "apollo"))
+
+	list := List()
+	assert.Equal(t, []Extension{
+		{Kind: KindConfigDataSource, Name: "apollo", Package: "github.com/gotomicro/ego/core/eextension"},
+		{Kind: KindLogWriter, Name: "apollo", Package: "github.com/gotomicro/ego/core/eextension"},
+	}, list)
+	assert.Empty(t, Conflicts())
+}
+
+func TestConflict(t *testing.T) {
+	reset()
+	defer reset()
+
+	assert.NoError(t, record(KindConfigDataSource, "file", "github.com/gotomicro/ego/core/econf/file"))
+	err := record(KindConfigDataSource, "file", "github.com/foo/bar")
+	assert.EqualError(t, err, `extension econf.datasource "file" registered by github.com/gotomicro/ego/core/econf/file overridden by github.com/foo/bar`)
+
+	conflicts := Conflicts()
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "github.com/foo/bar", conflicts[0].Package)
+	assert.Equal(t, []string{"github.com/gotomicro/ego/core/econf/file", "github.com/foo/bar"}, conflicts[0].Packages)
+}
+
+func TestLoadPlugins(t *testing.T) {
+	assert.NoError(t, LoadPlugins(nil))
+	assert.Error(t, LoadPlugins([]string{"not-exist.so"}))
+}
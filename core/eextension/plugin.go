@@ -0,0 +1,17 @@
+package eextension
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugins 加载Go plugin，plugin的init中调用注册函数完成注册
+// plugin需要使用与主程序相同版本的Go和依赖编译，只支持linux、darwin等平台，并且需要开启cgo
+func LoadPlugins(paths []string) error {
+	for _, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("load plugin %s fail, %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package efeature
+
+import (
+	"sort"
+	"sync"
+)
+
+// 可以通过build tag去掉的集成，例如 go build -tags ego_nosentinel,ego_nojaeger 构建不依赖sentinel和jaeger的二进制
+const (
+	// Sentinel 限流熔断，去掉时egin、egrpc的EnableSentinel不生效，tag为ego_nosentinel
+	Sentinel = "sentinel"
+	// MaxProcs 根据cgroup自动设置GOMAXPROCS，去掉时只支持ego.maxProc配置，tag为ego_nomaxprocs
+	MaxProcs = "automaxprocs"
+	// OtelJaeger otel的jaeger exporter，去掉时trace的otelType只支持otlp，tag为ego_nojaeger
+	OtelJaeger = "otel.jaeger"
+)
+
+var (
+	mu       sync.RWMutex
+	features = make(map[string]struct{})
+)
+
+// register 编译进二进制的集成在init中注册
+func register(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	features[name] = struct{}{}
+}
+
+// Enabled 集成是否编译进了二进制
+func Enabled(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := features[name]
+	return ok
+}
+
+// List 返回编译进二进制的集成，按名称排序，启动时输出到banner和启动摘要
+func List() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]string, 0, len(features))
+	for name := range features {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
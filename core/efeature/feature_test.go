@@ -0,0 +1,34 @@
+package efeature
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	register("test.feature")
+	defer func() {
+		mu.Lock()
+		delete(features, "test.feature")
+		mu.Unlock()
+	}()
+	list := List()
+	assert.Contains(t, list, "test.feature")
+	assert.True(t, sort.StringsAreSorted(list))
+	assert.True(t, Enabled("test.feature"))
+	assert.False(t, Enabled("unknown"))
+	for _, name := range []string{Sentinel, MaxProcs, OtelJaeger} {
+		assert.Equal(t, Enabled(name), contains(list, name))
+	}
+}
+
+func contains(list []string, name string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
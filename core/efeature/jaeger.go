@@ -0,0 +1,7 @@
+//go:build !ego_nojaeger
+
+package efeature
+
+func init() {
+	register(OtelJaeger)
+}
@@ -0,0 +1,7 @@
+//go:build !ego_nomaxprocs
+
+package efeature
+
+func init() {
+	register(MaxProcs)
+}
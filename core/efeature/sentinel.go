@@ -0,0 +1,7 @@
+//go:build !ego_nosentinel
+
+package efeature
+
+func init() {
+	register(Sentinel)
+}
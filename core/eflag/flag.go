@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/gotomicro/ego/internal/ienv"
 )
 
 var (
@@ -76,6 +79,11 @@ func ParseWithArgs(arguments []string) error {
 	return flagset.ParseWithArgs(arguments)
 }
 
+// ParseWithArgsE parses the flagset with given args, returns error instead of exiting.
+func ParseWithArgsE(arguments []string) error {
+	return flagset.ParseWithArgsE(arguments)
+}
+
 // Lookup lookup flag value by name
 // priority: flag > env > default
 func (fs *FlagSet) Lookup(name string) *flag.Flag {
@@ -110,6 +118,14 @@ func (fs *FlagSet) ParseWithArgs(arguments []string) error {
 	return nil
 }
 
+// ParseWithArgsE 解析失败时返回错误，不退出进程，解析后恢复原来的错误处理方式
+func (fs *FlagSet) ParseWithArgsE(arguments []string) error {
+	handling := fs.ErrorHandling()
+	fs.Init(fs.Name(), flag.ContinueOnError)
+	defer fs.Init(fs.Name(), handling)
+	return fs.ParseWithArgs(arguments)
+}
+
 // BoolE parses bool flag of the flagset with error returned.
 func BoolE(name string) (bool, error) { return flagset.BoolE(name) }
 
@@ -219,3 +235,89 @@ func (fs *FlagSet) Float64(name string) float64 {
 	ret, _ := fs.Float64E(name)
 	return ret
 }
+
+// DurationE parses duration flag of the flagset with error returned.
+func DurationE(name string) (time.Duration, error) { return flagset.DurationE(name) }
+
+// DurationE parses duration flag of provided flagset with error returned.
+func (fs *FlagSet) DurationE(name string) (time.Duration, error) {
+	flag := fs.Lookup(name)
+	if flag != nil {
+		return time.ParseDuration(flag.Value.String())
+	}
+
+	return 0, fmt.Errorf("undefined flag name: %s", name)
+}
+
+// Duration parses duration flag of the flagset.
+func Duration(name string) time.Duration { return flagset.Duration(name) }
+
+// Duration parses duration flag of provided flagset.
+func (fs *FlagSet) Duration(name string) time.Duration {
+	ret, _ := fs.DurationE(name)
+	return ret
+}
+
+// StringSliceE parses string slice flag of the flagset with error returned.
+func StringSliceE(name string) ([]string, error) { return flagset.StringSliceE(name) }
+
+// StringSliceE parses string slice flag of provided flagset with error returned.
+func (fs *FlagSet) StringSliceE(name string) ([]string, error) {
+	flag := fs.Lookup(name)
+	if flag != nil {
+		if value, ok := flag.Value.(*stringSliceValue); ok {
+			return value.Get().([]string), nil
+		}
+		return ienv.SplitStrSlice(flag.Value.String()), nil
+	}
+
+	return nil, fmt.Errorf("undefined flag name: %s", name)
+}
+
+// StringSlice parses string slice flag of the flagset.
+func StringSlice(name string) []string { return flagset.StringSlice(name) }
+
+// StringSlice parses string slice flag of provided flagset.
+func (fs *FlagSet) StringSlice(name string) []string {
+	ret, _ := fs.StringSliceE(name)
+	return ret
+}
+
+// StringMapE parses string map flag of the flagset with error returned.
+func StringMapE(name string) (map[string]string, error) { return flagset.StringMapE(name) }
+
+// StringMapE parses string map flag of provided flagset with error returned.
+func (fs *FlagSet) StringMapE(name string) (map[string]string, error) {
+	flag := fs.Lookup(name)
+	if flag != nil {
+		if value, ok := flag.Value.(*stringMapValue); ok {
+			return value.Get().(map[string]string), nil
+		}
+		return ienv.SplitStrMap(flag.Value.String())
+	}
+
+	return nil, fmt.Errorf("undefined flag name: %s", name)
+}
+
+// StringMap parses string map flag of the flagset.
+func StringMap(name string) map[string]string { return flagset.StringMap(name) }
+
+// StringMap parses string map flag of provided flagset.
+func (fs *FlagSet) StringMap(name string) map[string]string {
+	ret, _ := fs.StringMapE(name)
+	return ret
+}
+
+// IsSet reports whether the flag is set explicitly in the command line of the flagset.
+func IsSet(name string) bool { return flagset.IsSet(name) }
+
+// IsSet reports whether the flag is set explicitly in the command line of provided flagset.
+func (fs *FlagSet) IsSet(name string) bool {
+	set := false
+	fs.FlagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
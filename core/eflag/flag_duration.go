@@ -0,0 +1,31 @@
+package eflag
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gotomicro/ego/internal/ienv"
+)
+
+// DurationFlag is a duration flag implements of Flag interface, such as "--timeout=1s".
+type DurationFlag struct {
+	Name     string
+	Usage    string
+	EnvVar   string
+	Default  time.Duration
+	Variable *time.Duration
+	Action   func(string, *FlagSet)
+}
+
+// Apply implements of Flag Apply function.
+func (f *DurationFlag) Apply(set *FlagSet) {
+	for _, field := range strings.Split(f.Name, ",") {
+		field = strings.TrimSpace(field)
+		if f.Variable != nil {
+			set.FlagSet.DurationVar(f.Variable, field, ienv.EnvOrDuration(f.EnvVar, f.Default), f.Usage)
+		} else {
+			set.FlagSet.Duration(field, ienv.EnvOrDuration(f.EnvVar, f.Default), f.Usage)
+		}
+		set.actions[field] = f.Action
+	}
+}
@@ -0,0 +1,77 @@
+package eflag
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gotomicro/ego/internal/ienv"
+)
+
+// StringMapFlag is a string map flag implements of Flag interface.
+// Pairs can be separated by comma or passed repeatedly, such as "--label=env=prod,zone=a --label=app=ego".
+type StringMapFlag struct {
+	Name     string
+	Usage    string
+	EnvVar   string
+	Default  map[string]string
+	Variable *map[string]string
+	Action   func(string, *FlagSet)
+}
+
+// Apply implements of Flag Apply function.
+func (f *StringMapFlag) Apply(set *FlagSet) {
+	for _, field := range strings.Split(f.Name, ",") {
+		field = strings.TrimSpace(field)
+		value := &stringMapValue{value: f.Variable}
+		if value.value == nil {
+			value.value = new(map[string]string)
+		}
+		*value.value = make(map[string]string)
+		for k, v := range ienv.EnvOrStrMap(f.EnvVar, f.Default) {
+			(*value.value)[k] = v
+		}
+		set.FlagSet.Var(value, field, f.Usage)
+		set.actions[field] = f.Action
+	}
+}
+
+// stringMapValue 命令行第一次传入时覆盖默认值，之后合并
+type stringMapValue struct {
+	value   *map[string]string
+	changed bool
+}
+
+func (v *stringMapValue) Set(s string) error {
+	pairs, err := ienv.SplitStrMap(s)
+	if err != nil {
+		return err
+	}
+	if !v.changed {
+		*v.value = make(map[string]string)
+		v.changed = true
+	}
+	for k, val := range pairs {
+		(*v.value)[k] = val
+	}
+	return nil
+}
+
+func (v *stringMapValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*v.value))
+	for k, val := range *v.value {
+		pairs = append(pairs, k+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *stringMapValue) Get() interface{} {
+	out := make(map[string]string, len(*v.value))
+	for k, val := range *v.value {
+		out[k] = val
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package eflag
+
+import (
+	"strings"
+
+	"github.com/gotomicro/ego/internal/ienv"
+)
+
+// StringSliceFlag is a string slice flag implements of Flag interface.
+// Values can be separated by comma or passed repeatedly, such as "--tag=a,b --tag=c".
+type StringSliceFlag struct {
+	Name     string
+	Usage    string
+	EnvVar   string
+	Default  []string
+	Variable *[]string
+	Action   func(string, *FlagSet)
+}
+
+// Apply implements of Flag Apply function.
+func (f *StringSliceFlag) Apply(set *FlagSet) {
+	for _, field := range strings.Split(f.Name, ",") {
+		field = strings.TrimSpace(field)
+		value := &stringSliceValue{value: f.Variable}
+		if value.value == nil {
+			value.value = new([]string)
+		}
+		*value.value = append([]string(nil), ienv.EnvOrStrSlice(f.EnvVar, f.Default)...)
+		set.FlagSet.Var(value, field, f.Usage)
+		set.actions[field] = f.Action
+	}
+}
+
+// stringSliceValue 命令行第一次传入时覆盖默认值，之后追加
+type stringSliceValue struct {
+	value   *[]string
+	changed bool
+}
+
+func (v *stringSliceValue) Set(s string) error {
+	if !v.changed {
+		*v.value = nil
+		v.changed = true
+	}
+	*v.value = append(*v.value, ienv.SplitStrSlice(s)...)
+	return nil
+}
+
+func (v *stringSliceValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	return strings.Join(*v.value, ",")
+}
+
+func (v *stringSliceValue) Get() interface{} {
+	return append([]string(nil), *v.value...)
+}
@@ -0,0 +1,61 @@
+package eflag
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedFlags(t *testing.T) {
+	t.Setenv("EGO_TEST_INTERVAL", "3s")
+	t.Setenv("EGO_TEST_LABELS", "env=prod, zone=a")
+	var tags []string
+	fs := NewFlagSet(flag.NewFlagSet("test", flag.ContinueOnError),
+		&DurationFlag{Name: "timeout", Usage: "--timeout", Default: time.Second},
+		&DurationFlag{Name: "interval", Usage: "--interval", EnvVar: "EGO_TEST_INTERVAL", Default: time.Second},
+		&StringSliceFlag{Name: "tag", Usage: "--tag", Default: []string{"default"}, Variable: &tags},
+		&StringSliceFlag{Name: "peer", Usage: "--peer", Default: []string{"127.0.0.1"}},
+		&StringMapFlag{Name: "label", Usage: "--label", EnvVar: "EGO_TEST_LABELS"},
+		&StringMapFlag{Name: "meta", Usage: "--meta", Default: map[string]string{"k": "v"}},
+	)
+	assert.NoError(t, fs.ParseWithArgs([]string{"--timeout=5s", "--tag=a,b", "--tag", "c", "--label=app=ego"}))
+
+	assert.Equal(t, 5*time.Second, fs.Duration("timeout"))
+	assert.Equal(t, 3*time.Second, fs.Duration("interval"))
+	assert.Equal(t, []string{"a", "b", "c"}, fs.StringSlice("tag"))
+	assert.Equal(t, []string{"a", "b", "c"}, tags)
+	assert.Equal(t, []string{"127.0.0.1"}, fs.StringSlice("peer"))
+	// 命令行覆盖环境变量
+	assert.Equal(t, map[string]string{"app": "ego"}, fs.StringMap("label"))
+	assert.Equal(t, map[string]string{"k": "v"}, fs.StringMap("meta"))
+
+	_, err := fs.DurationE("notExist")
+	assert.Error(t, err)
+	_, err = fs.StringSliceE("notExist")
+	assert.Error(t, err)
+	_, err = fs.StringMapE("notExist")
+	assert.Error(t, err)
+
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	assert.Contains(t, usage.String(), "-tag value")
+	assert.Contains(t, usage.String(), `(default 127.0.0.1)`)
+	assert.Contains(t, usage.String(), `(default k=v)`)
+}
+
+func TestStringMapFlagInvalid(t *testing.T) {
+	fs := NewFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), &StringMapFlag{Name: "label"})
+	fs.SetOutput(&bytes.Buffer{})
+	assert.Error(t, fs.ParseWithArgs([]string{"--label=app"}))
+}
+
+func TestParseWithArgsE(t *testing.T) {
+	fs := NewFlagSet(flag.NewFlagSet("test", flag.ExitOnError), &StringFlag{Name: "name", Usage: "--name"})
+	fs.SetOutput(&bytes.Buffer{})
+	assert.ErrorContains(t, fs.ParseWithArgsE([]string{"--unknown"}), "flag provided but not defined")
+	assert.Equal(t, flag.ExitOnError, fs.ErrorHandling())
+}
@@ -0,0 +1,160 @@
+package egraceful
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gotomicro/ego/core/constant"
+)
+
+// PackageName 包名
+const PackageName = "core.egraceful"
+
+// 第一个继承的文件描述符，0、1、2为标准输入输出
+const firstInheritedFD = 3
+
+var (
+	mu        sync.Mutex
+	listeners []*listener
+	inherited map[string][]*os.File
+)
+
+// listener 记录通过Listen创建的监听，关闭时从列表中移除
+// 同一个地址可能有多个监听，例如多个服务都监听 :0，所以使用列表而不是map
+type listener struct {
+	net.Listener
+	key  string
+	once sync.Once
+}
+
+// Close 关闭监听，并且不再传递给热升级的子进程
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, item := range listeners {
+			if item == l {
+				listeners = append(listeners[:i:i], listeners[i+1:]...)
+				break
+			}
+		}
+	})
+	return l.Listener.Close()
+}
+
+func listenerKey(network, addr string) string {
+	return network + "://" + addr
+}
+
+// loadInherited 解析父进程传递的监听，只解析一次
+// 相同key的监听按照父进程传递的顺序依次分配
+func loadInherited() {
+	if inherited != nil {
+		return
+	}
+	inherited = make(map[string][]*os.File)
+	env := os.Getenv(constant.EgoGracefulListeners)
+	if env == "" {
+		return
+	}
+	for i, key := range strings.Split(env, ",") {
+		inherited[key] = append(inherited[key], os.NewFile(uintptr(firstInheritedFD+i), key))
+	}
+}
+
+// Listen 创建监听，热升级的子进程优先使用父进程传递的监听，保证升级过程中不会拒绝连接
+func Listen(network, addr string) (net.Listener, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	loadInherited()
+	key := listenerKey(network, addr)
+	if files := inherited[key]; len(files) > 0 {
+		file := files[0]
+		inherited[key] = files[1:]
+		ln, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("egraceful inherit listener %s fail, %w", key, err)
+		}
+		return track(key, ln), nil
+	}
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return track(key, ln), nil
+}
+
+func track(key string, ln net.Listener) net.Listener {
+	l := &listener{Listener: ln, key: key}
+	listeners = append(listeners, l)
+	return l
+}
+
+// Listeners 返回通过Listen创建、还没有关闭的监听，按照key排序
+func Listeners() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	sorted := sortedListeners()
+	keys := make([]string, 0, len(sorted))
+	for _, l := range sorted {
+		keys = append(keys, l.key)
+	}
+	return keys
+}
+
+// sortedListeners 按照key排序，相同key保持创建顺序，子进程按照同样的顺序分配
+func sortedListeners() []*listener {
+	sorted := make([]*listener, len(listeners))
+	copy(sorted, listeners)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+	return sorted
+}
+
+// listenerFiles 复制所有监听的文件描述符，用于传递给子进程，同时返回其中的unix socket监听
+func listenerFiles() ([]string, []*os.File, []*net.UnixListener, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	sorted := sortedListeners()
+	keys := make([]string, 0, len(sorted))
+	files := make([]*os.File, 0, len(sorted))
+	var unixListeners []*net.UnixListener
+	for _, l := range sorted {
+		fl, ok := l.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles(files)
+			return nil, nil, nil, fmt.Errorf("egraceful listener %s can not be inherited", l.key)
+		}
+		file, err := fl.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, nil, fmt.Errorf("egraceful dup listener %s fail, %w", l.key, err)
+		}
+		keys = append(keys, l.key)
+		files = append(files, file)
+		if ul, ok := l.Listener.(*net.UnixListener); ok {
+			unixListeners = append(unixListeners, ul)
+		}
+	}
+	return keys, files, unixListeners, nil
+}
+
+// keepSocketFiles 子进程就绪后调用，父进程退出时不能删除子进程正在使用的unix socket文件
+// 升级失败时不调用，父进程退出时仍然清理socket文件
+func keepSocketFiles(unixListeners []*net.UnixListener) {
+	for _, ul := range unixListeners {
+		ul.SetUnlinkOnClose(false)
+	}
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
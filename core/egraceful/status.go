@@ -0,0 +1,107 @@
+package egraceful
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// State 热升级的状态
+type State string
+
+const (
+	// StateIdle 没有进行热升级
+	StateIdle State = "idle"
+	// StateForked 子进程已经启动，等待子进程报告
+	StateForked State = "forked"
+	// StateChildReady 子进程的报告通过了兼容性检查，开始接收请求
+	StateChildReady State = "child-ready"
+	// StateParentDraining 父进程正在优雅停止
+	StateParentDraining State = "parent-draining"
+	// StateCompleted 父进程已经停止，热升级完成
+	StateCompleted State = "completed"
+	// StateFailed 热升级失败，子进程已经被终止，父进程继续提供服务
+	StateFailed State = "failed"
+)
+
+var allStates = []State{StateIdle, StateForked, StateChildReady, StateParentDraining, StateCompleted, StateFailed}
+
+// Transition 一次状态变化
+type Transition struct {
+	State State     `json:"state"`
+	Time  time.Time `json:"time"`
+}
+
+// Status 最近一次热升级的状态
+type Status struct {
+	State       State        `json:"state"`
+	ChildPID    int          `json:"childPid,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	StartTime   time.Time    `json:"startTime,omitempty"`
+	UpdateTime  time.Time    `json:"updateTime,omitempty"`
+	Transitions []Transition `json:"transitions"`
+}
+
+var (
+	statusMu sync.RWMutex
+	status   = Status{State: StateIdle, Transitions: []Transition{}}
+)
+
+func init() {
+	setStateGauge(StateIdle)
+}
+
+// GetStatus 返回最近一次热升级的状态
+func GetStatus() Status {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	out := status
+	out.Transitions = append([]Transition{}, status.Transitions...)
+	return out
+}
+
+// MarkDraining 父进程开始优雅停止时调用
+func MarkDraining() {
+	transition(StateParentDraining, 0, nil)
+}
+
+// MarkCompleted 父进程停止后调用，err不为空时记录停止过程中的错误，热升级仍然认为已经完成
+func MarkCompleted(err error) {
+	transition(StateCompleted, 0, err)
+}
+
+// transition 切换状态，进入forked时开始新的一次热升级
+func transition(state State, childPID int, err error) {
+	now := time.Now()
+	statusMu.Lock()
+	if state == StateForked {
+		status = Status{StartTime: now}
+	}
+	status.State = state
+	status.UpdateTime = now
+	if childPID != 0 {
+		status.ChildPID = childPID
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.Transitions = append(status.Transitions, Transition{State: state, Time: now})
+	statusMu.Unlock()
+
+	setStateGauge(state)
+	switch state {
+	case StateCompleted, StateFailed:
+		emetric.GracefulUpgradeCounter.Inc(string(state))
+	}
+}
+
+func setStateGauge(state State) {
+	for _, item := range allStates {
+		value := 0.0
+		if item == state {
+			value = 1
+		}
+		emetric.GracefulUpgradeStateGauge.Set(value, string(item))
+	}
+}
@@ -0,0 +1,181 @@
+package egraceful
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// ErrIncompatible 子进程与父进程不兼容，热升级终止
+var ErrIncompatible = errors.New("egraceful incompatible child")
+
+// Report 热升级时子进程通过管道告诉父进程的信息，父进程据此判断是否可以把流量交给子进程
+type Report struct {
+	PID        int      `json:"pid"`
+	Version    string   `json:"version"`
+	EgoVersion string   `json:"egoVersion"`
+	ConfigHash string   `json:"configHash"`
+	Listeners  []string `json:"listeners"`
+}
+
+// Current 返回当前进程的信息
+func Current() Report {
+	sum := sha256.Sum256(econf.RawConfig())
+	return Report{
+		PID:        os.Getpid(),
+		Version:    eapp.AppVersion(),
+		EgoVersion: eapp.EgoVersion(),
+		ConfigHash: hex.EncodeToString(sum[:8]),
+		Listeners:  Listeners(),
+	}
+}
+
+// Check 检查子进程与父进程是否兼容，主版本号或者监听不一致时返回ErrIncompatible
+// 配置可以不一致，热升级通常伴随配置变更
+func Check(parent, child Report) error {
+	if majorVersion(parent.Version) != majorVersion(child.Version) {
+		return fmt.Errorf("%w: major version mismatch, parent %s, child %s", ErrIncompatible, parent.Version, child.Version)
+	}
+	if strings.Join(parent.Listeners, ",") != strings.Join(child.Listeners, ",") {
+		return fmt.Errorf("%w: listeners mismatch, parent [%s], child [%s]", ErrIncompatible, strings.Join(parent.Listeners, ","), strings.Join(child.Listeners, ","))
+	}
+	return nil
+}
+
+// majorVersion 返回语义化版本的主版本号，例如 v1.2.3 返回 v1，无法解析时返回原值
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), ".")
+	if _, err := strconv.Atoi(major); err != nil {
+		return version
+	}
+	return "v" + major
+}
+
+// IsChild 当前进程是否是热升级启动的子进程，并且还没有向父进程报告
+func IsChild() bool {
+	return os.Getenv(constant.EgoGracefulReportFD) != ""
+}
+
+// Ready 子进程的监听准备好后调用，向父进程报告当前进程的信息，不是热升级的子进程时直接返回
+func Ready() error {
+	fd := os.Getenv(constant.EgoGracefulReportFD)
+	if fd == "" {
+		return nil
+	}
+	_ = os.Unsetenv(constant.EgoGracefulReportFD)
+	_ = os.Unsetenv(constant.EgoGracefulListeners)
+	num, err := strconv.Atoi(fd)
+	if err != nil {
+		return fmt.Errorf("egraceful invalid report fd %q, %w", fd, err)
+	}
+	pipe := os.NewFile(uintptr(num), "egraceful-report")
+	defer pipe.Close()
+	if err := json.NewEncoder(pipe).Encode(Current()); err != nil {
+		return fmt.Errorf("egraceful report fail, %w", err)
+	}
+	return nil
+}
+
+// Upgrade 启动新的子进程并传递所有监听，等待子进程报告并检查兼容性
+// 返回nil时子进程已经开始处理请求，调用方应该优雅停止当前进程；返回错误时子进程已经被终止，当前进程继续提供服务
+func Upgrade(ctx context.Context) (*Report, error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("egraceful upgrade is not supported on windows")
+	}
+	if IsChild() {
+		return nil, errors.New("egraceful upgrade is not allowed before the process is ready")
+	}
+	path, err := exec.LookPath(os.Args[0])
+	if err != nil {
+		return nil, fmt.Errorf("egraceful look path fail, %w", err)
+	}
+	keys, files, unixListeners, err := listenerFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFiles(files)
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("egraceful pipe fail, %w", err)
+	}
+	defer reader.Close()
+
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = append(files, writer)
+	cmd.Env = append(childEnv(),
+		constant.EgoGracefulListeners+"="+strings.Join(keys, ","),
+		constant.EgoGracefulReportFD+"="+strconv.Itoa(firstInheritedFD+len(files)),
+	)
+	err = cmd.Start()
+	// 父进程必须关闭写端，子进程异常退出时读端才能读到EOF
+	_ = writer.Close()
+	if err != nil {
+		err = fmt.Errorf("egraceful start child fail, %w", err)
+		transition(StateFailed, 0, err)
+		return nil, err
+	}
+	transition(StateForked, cmd.Process.Pid, nil)
+
+	report, err := readReport(ctx, reader)
+	if err == nil {
+		err = Check(Current(), *report)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		transition(StateFailed, 0, err)
+		return report, err
+	}
+	keepSocketFiles(unixListeners)
+	_ = cmd.Process.Release()
+	transition(StateChildReady, 0, nil)
+	return report, nil
+}
+
+func readReport(ctx context.Context, reader *os.File) (*Report, error) {
+	type result struct {
+		report Report
+		err    error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		var res result
+		res.err = json.NewDecoder(reader).Decode(&res.report)
+		ch <- res
+	}()
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			return nil, fmt.Errorf("egraceful read child report fail, child exited before ready, %w", res.err)
+		}
+		return &res.report, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("egraceful wait child report fail, %w", context.Cause(ctx))
+	}
+}
+
+// childEnv 去掉从父进程继承的热升级环境变量
+func childEnv() []string {
+	env := os.Environ()
+	out := make([]string, 0, len(env))
+	for _, item := range env {
+		if strings.HasPrefix(item, constant.EgoGracefulListeners+"=") || strings.HasPrefix(item, constant.EgoGracefulReportFD+"=") {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
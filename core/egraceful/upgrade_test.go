@@ -0,0 +1,136 @@
+package egraceful
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+const testChildEnv = "EGO_GRACEFUL_TEST_CHILD"
+
+// TestMain 热升级启动的子进程是测试程序本身，按照父进程的要求创建监听后报告
+func TestMain(m *testing.M) {
+	if IsChild() {
+		_, _ = Listen("tcp", "127.0.0.1:0")
+		if os.Getenv(testChildEnv) == "extra" {
+			_, _ = Listen("tcp4", "127.0.0.1:0")
+		}
+		if os.Getenv(testChildEnv) != "crash" {
+			_ = Ready()
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestCheck(t *testing.T) {
+	parent := Report{Version: "v1.2.0", Listeners: []string{"tcp://0.0.0.0:9001"}}
+	assert.NoError(t, Check(parent, Report{Version: "v1.3.1", Listeners: []string{"tcp://0.0.0.0:9001"}}))
+	assert.ErrorIs(t, Check(parent, Report{Version: "v2.0.0", Listeners: []string{"tcp://0.0.0.0:9001"}}), ErrIncompatible)
+	assert.ErrorIs(t, Check(parent, Report{Version: "v1.2.0", Listeners: []string{"tcp://0.0.0.0:9002"}}), ErrIncompatible)
+	// 无法解析的版本号需要完全一致
+	assert.NoError(t, Check(Report{Version: "unknown"}, Report{Version: "unknown"}))
+	assert.ErrorIs(t, Check(Report{Version: "unknown"}, Report{Version: "dev"}), ErrIncompatible)
+}
+
+func TestUpgrade(t *testing.T) {
+	ln, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	assert.Equal(t, []string{"tcp://127.0.0.1:0"}, Listeners())
+
+	report, err := Upgrade(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tcp://127.0.0.1:0"}, report.Listeners)
+	assert.NotEqual(t, os.Getpid(), report.PID)
+
+	t.Setenv(testChildEnv, "extra")
+	_, err = Upgrade(context.Background())
+	assert.ErrorIs(t, err, ErrIncompatible)
+
+	t.Setenv(testChildEnv, "crash")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = Upgrade(ctx)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestListen(t *testing.T) {
+	// 多个服务监听同一个地址时都需要传递给子进程
+	ln1, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	ln2, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	assert.NotEqual(t, ln1.Addr().String(), ln2.Addr().String())
+	assert.Equal(t, []string{"tcp://127.0.0.1:0", "tcp://127.0.0.1:0"}, Listeners())
+
+	// 关闭的监听不再传递给子进程
+	assert.NoError(t, ln1.Close())
+	assert.Equal(t, []string{"tcp://127.0.0.1:0"}, Listeners())
+	assert.NoError(t, ln2.Close())
+	assert.Empty(t, Listeners())
+}
+
+func TestUpgradeFailUnlinkSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "egraceful")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ego.sock")
+	ln, err := Listen("unix", path)
+	assert.NoError(t, err)
+
+	// 子进程没有监听unix socket，升级失败，父进程关闭监听时仍然删除socket文件
+	_, err = Upgrade(context.Background())
+	assert.ErrorIs(t, err, ErrIncompatible)
+	assert.NoError(t, ln.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestChildEnv(t *testing.T) {
+	t.Setenv(constant.EgoGracefulListeners, "tcp://127.0.0.1:9001")
+	t.Setenv(constant.EgoGracefulReportFD, "4")
+	for _, item := range childEnv() {
+		assert.NotContains(t, item, constant.EgoGracefulListeners)
+		assert.NotContains(t, item, constant.EgoGracefulReportFD)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ln, err := Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	report, err := Upgrade(context.Background())
+	assert.NoError(t, err)
+	MarkDraining()
+	MarkCompleted(nil)
+	status := GetStatus()
+	assert.Equal(t, StateCompleted, status.State)
+	assert.Equal(t, report.PID, status.ChildPID)
+	var states []State
+	for _, item := range status.Transitions {
+		states = append(states, item.State)
+	}
+	assert.Equal(t, []State{StateForked, StateChildReady, StateParentDraining, StateCompleted}, states)
+	assert.Equal(t, 1.0, testutil.ToFloat64(emetric.GracefulUpgradeStateGauge.WithLabelValues(string(StateCompleted))))
+	assert.Equal(t, 0.0, testutil.ToFloat64(emetric.GracefulUpgradeStateGauge.WithLabelValues(string(StateForked))))
+
+	t.Setenv(testChildEnv, "crash")
+	_, err = Upgrade(context.Background())
+	assert.Error(t, err)
+	status = GetStatus()
+	assert.Equal(t, StateFailed, status.State)
+	assert.NotEmpty(t, status.Error)
+	assert.Len(t, status.Transitions, 2)
+}
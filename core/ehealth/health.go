@@ -0,0 +1,223 @@
+package ehealth
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PackageName 包名
+const PackageName = "core.ehealth"
+
+// DefaultTimeout 没有设置超时时间时，单个健康检查的超时时间
+const DefaultTimeout = 3 * time.Second
+
+const (
+	// StatusOK 所有组件都健康
+	StatusOK = "ok"
+	// StatusDegraded 只有非关键组件不健康，服务还可以对外提供服务
+	StatusDegraded = "degraded"
+	// StatusUnavailable 有关键组件不健康
+	StatusUnavailable = "unavailable"
+)
+
+// Checker 组件健康检查，返回nil表示健康
+type Checker func(ctx context.Context) error
+
+// Result 健康检查结果
+type Result struct {
+	Name      string        `json:"name"`
+	Type      string        `json:"type,omitempty"`
+	Target    string        `json:"target,omitempty"`
+	Critical  bool          `json:"critical"`
+	Liveness  bool          `json:"liveness,omitempty"`
+	DependsOn []string      `json:"dependsOn,omitempty"`
+	Healthy   bool          `json:"healthy"`
+	Error     string        `json:"error,omitempty"`
+	Cost      time.Duration `json:"cost"`
+}
+
+// Option 注册健康检查的选项
+type Option func(*entry)
+
+// WithCritical 设置是否是关键组件，关键组件不健康时服务不就绪，默认true
+func WithCritical(critical bool) Option {
+	return func(e *entry) {
+		e.critical = critical
+	}
+}
+
+// WithLiveness 设置是否参与存活检查，存活检查失败时通常由k8s重启实例，只应用于进程自身卡死等无法恢复的情况，默认false
+func WithLiveness(liveness bool) Option {
+	return func(e *entry) {
+		e.liveness = liveness
+	}
+}
+
+// WithTimeout 设置健康检查的超时时间，默认3s
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *entry) {
+		e.timeout = timeout
+	}
+}
+
+// WithType 设置组件类型，例如 redis、mongo、http、grpc
+func WithType(typ string) Option {
+	return func(e *entry) {
+		e.typ = typ
+	}
+}
+
+// WithTarget 设置组件连接的地址，不能包含密码
+func WithTarget(target string) Option {
+	return func(e *entry) {
+		e.target = target
+	}
+}
+
+// WithDependsOn 设置组件依赖的其他组件，用于生成依赖关系图
+func WithDependsOn(names ...string) Option {
+	return func(e *entry) {
+		e.dependsOn = append(e.dependsOn, names...)
+	}
+}
+
+type entry struct {
+	checker   Checker
+	critical  bool
+	liveness  bool
+	timeout   time.Duration
+	typ       string
+	target    string
+	dependsOn []string
+}
+
+var (
+	mu       sync.RWMutex
+	checkers = make(map[string]*entry)
+)
+
+// Register 注册组件的健康检查，name通常为组件的配置key，重复注册会覆盖
+func Register(name string, checker Checker, opts ...Option) {
+	e := &entry{checker: checker, critical: true, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(e)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	checkers[name] = e
+}
+
+// Unregister 取消注册组件的健康检查
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(checkers, name)
+}
+
+// Check 并发执行所有健康检查，结果按照名称排序
+func Check(ctx context.Context) []Result {
+	mu.RLock()
+	names := make([]string, 0, len(checkers))
+	entries := make(map[string]*entry, len(checkers))
+	for name, e := range checkers {
+		names = append(names, name)
+		entries[name] = e
+	}
+	mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]Result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			e := entries[name]
+			checkCtx := ctx
+			if e.timeout > 0 {
+				var cancel context.CancelFunc
+				checkCtx, cancel = context.WithTimeout(ctx, e.timeout)
+				defer cancel()
+			}
+			beg := time.Now()
+			err := e.checker(checkCtx)
+			results[i] = Result{
+				Name:      name,
+				Type:      e.typ,
+				Target:    e.target,
+				Critical:  e.critical,
+				Liveness:  e.liveness,
+				DependsOn: e.dependsOn,
+				Healthy:   err == nil,
+				Cost:      time.Since(beg),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
+
+// Healthy 所有组件是否都健康
+func Healthy(results []Result) bool {
+	for _, result := range results {
+		if !result.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Ready 所有关键组件是否都健康，非关键组件不健康时服务仍然就绪
+func Ready(results []Result) bool {
+	for _, result := range results {
+		if result.Critical && !result.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Live 参与存活检查的组件是否都健康，依赖的外部组件不健康时不影响存活
+func Live(results []Result) bool {
+	for _, result := range results {
+		if result.Liveness && !result.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Status 汇总健康检查结果，返回 ok、degraded、unavailable
+func Status(results []Result) string {
+	switch {
+	case !Ready(results):
+		return StatusUnavailable
+	case !Healthy(results):
+		return StatusDegraded
+	default:
+		return StatusOK
+	}
+}
+
+// Report 汇总的健康状态，用于就绪检查、存活检查
+type Report struct {
+	Status     string   `json:"status"`
+	Ready      bool     `json:"ready"`
+	Live       bool     `json:"live"`
+	Components []Result `json:"components"`
+}
+
+// NewReport 汇总健康检查结果
+func NewReport(results []Result) Report {
+	return Report{
+		Status:     Status(results),
+		Ready:      Ready(results),
+		Live:       Live(results),
+		Components: results,
+	}
+}
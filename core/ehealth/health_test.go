@@ -0,0 +1,70 @@
+package ehealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	Register("b", func(ctx context.Context) error { return errors.New("down") })
+	Register("a", func(ctx context.Context) error { return nil })
+	defer Unregister("a")
+	defer Unregister("b")
+
+	results := Check(context.Background())
+	assert.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Name)
+	assert.True(t, results[0].Healthy)
+	assert.Equal(t, "down", results[1].Error)
+	assert.False(t, Healthy(results))
+
+	Unregister("b")
+	assert.True(t, Healthy(Check(context.Background())))
+}
+
+func TestReady(t *testing.T) {
+	Register("redis", func(ctx context.Context) error { return nil }, WithType("redis"), WithTarget("127.0.0.1:6379"))
+	Register("search", func(ctx context.Context) error { return errors.New("down") }, WithCritical(false), WithDependsOn("redis"))
+	Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond), WithCritical(false))
+	defer Unregister("redis")
+	defer Unregister("search")
+	defer Unregister("slow")
+
+	results := Check(context.Background())
+	assert.Len(t, results, 3)
+	assert.Equal(t, "redis", results[0].Type)
+	assert.Equal(t, "127.0.0.1:6379", results[0].Target)
+	assert.True(t, results[0].Critical)
+	assert.Equal(t, []string{"redis"}, results[1].DependsOn)
+	assert.Equal(t, context.DeadlineExceeded.Error(), results[2].Error)
+	assert.False(t, Healthy(results))
+	assert.True(t, Ready(results))
+	assert.Equal(t, StatusDegraded, Status(results))
+
+	Register("mysql", func(ctx context.Context) error { return errors.New("down") })
+	defer Unregister("mysql")
+	assert.Equal(t, StatusUnavailable, Status(Check(context.Background())))
+}
+
+func TestLive(t *testing.T) {
+	Register("worker", func(ctx context.Context) error { return nil }, WithLiveness(true))
+	Register("redis", func(ctx context.Context) error { return errors.New("down") })
+	defer Unregister("worker")
+	defer Unregister("redis")
+
+	report := NewReport(Check(context.Background()))
+	assert.True(t, report.Live)
+	assert.False(t, report.Ready)
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Len(t, report.Components, 2)
+
+	Register("worker", func(ctx context.Context) error { return errors.New("stuck") }, WithLiveness(true))
+	assert.False(t, Live(Check(context.Background())))
+}
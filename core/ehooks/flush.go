@@ -0,0 +1,60 @@
+package ehooks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FlushFunc 在ctx的deadline之前写入缓冲的数据，返回写入成功和丢弃的条数
+type FlushFunc func(ctx context.Context) (flushed int, dropped int, err error)
+
+// FlushResult 组件停止时写入缓冲数据的结果
+type FlushResult struct {
+	Name    string
+	Flushed int
+	Dropped int
+	Cost    time.Duration
+	Err     error
+}
+
+type flusher struct {
+	name string
+	fn   FlushFunc
+}
+
+var (
+	flushMu  sync.Mutex
+	flushers []flusher
+)
+
+// RegisterFlush 注册缓冲数据的写入，例如消息生产者、批量写入、日志上报
+// ego停止时，在服务和定时任务停止之后、StageAfterStop之前，使用剩余的停止时间并发执行
+func RegisterFlush(name string, fn FlushFunc) {
+	flushMu.Lock()
+	defer flushMu.Unlock()
+	flushers = append(flushers, flusher{name: name, fn: fn})
+}
+
+// Flush 并发执行所有注册的写入，结果按照注册顺序返回
+// 执行后清空注册的写入，保证只执行一次
+func Flush(ctx context.Context) []FlushResult {
+	flushMu.Lock()
+	fns := flushers
+	flushers = nil
+	flushMu.Unlock()
+
+	results := make([]FlushResult, len(fns))
+	var wg sync.WaitGroup
+	for i, f := range fns {
+		wg.Add(1)
+		go func(i int, f flusher) {
+			defer wg.Done()
+			beg := time.Now()
+			flushed, dropped, err := f.fn(ctx)
+			results[i] = FlushResult{Name: f.name, Flushed: flushed, Dropped: dropped, Cost: time.Since(beg), Err: err}
+		}(i, f)
+	}
+	wg.Wait()
+	return results
+}
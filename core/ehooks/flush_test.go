@@ -0,0 +1,31 @@
+package ehooks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlush(t *testing.T) {
+	RegisterFlush("writer", func(ctx context.Context) (int, int, error) {
+		return 10, 0, nil
+	})
+	RegisterFlush("producer", func(ctx context.Context) (int, int, error) {
+		<-ctx.Done()
+		return 3, 2, ctx.Err()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := Flush(ctx)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "writer", results[0].Name)
+	assert.Equal(t, 10, results[0].Flushed)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 2, results[1].Dropped)
+	assert.True(t, errors.Is(results[1].Err, context.Canceled))
+
+	// 只执行一次
+	assert.Empty(t, Flush(context.Background()))
+}
@@ -0,0 +1,45 @@
+package ehooks
+
+import (
+	"errors"
+	"sync"
+)
+
+// Stage 生命周期阶段
+type Stage int
+
+const (
+	// StageBeforeStop 停止服务之前执行，和 ego.WithBeforeStopClean 一起执行
+	StageBeforeStop Stage = iota + 1
+	// StageAfterStop 停止服务之后、日志flush之前执行，组件通常在这里关闭连接、写入缓冲的数据
+	StageAfterStop
+)
+
+var (
+	mu    sync.Mutex
+	hooks = make(map[Stage][]func() error)
+)
+
+// Register 注册生命周期钩子，组件可以在Build时注册，不需要用户在main中手动清理
+func Register(stage Stage, fns ...func() error) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks[stage] = append(hooks[stage], fns...)
+}
+
+// Do 按照注册的逆序执行该阶段的钩子，后创建的组件先关闭，返回聚合错误
+// 执行后清空该阶段的钩子，保证只执行一次
+func Do(stage Stage) error {
+	mu.Lock()
+	fns := hooks[stage]
+	delete(hooks, stage)
+	mu.Unlock()
+
+	errs := make([]error, 0)
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package ehooks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	var order []int
+	Register(StageAfterStop, func() error {
+		order = append(order, 1)
+		return nil
+	}, func() error {
+		order = append(order, 2)
+		return errors.New("close fail")
+	})
+	assert.ErrorContains(t, Do(StageAfterStop), "close fail")
+	assert.Equal(t, []int{2, 1}, order)
+
+	// 只执行一次
+	assert.NoError(t, Do(StageAfterStop))
+	assert.Equal(t, []int{2, 1}, order)
+}
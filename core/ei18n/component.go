@@ -0,0 +1,159 @@
+package ei18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "core.ei18n"
+
+var defaultComponent atomic.Pointer[Component]
+
+// Component 国际化组件
+type Component struct {
+	name     string
+	config   *Config
+	logger   *elog.Component
+	mu       sync.RWMutex
+	messages map[string]map[string]string // 语言 => 消息key => 消息内容
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	return &Component{
+		name:     name,
+		config:   config,
+		logger:   logger,
+		messages: make(map[string]map[string]string),
+	}
+}
+
+// Default 返回最后一次Build的组件，没有Build时返回nil
+func Default() *Component {
+	return defaultComponent.Load()
+}
+
+func setDefault(c *Component) {
+	defaultComponent.Store(c)
+}
+
+func (c *Component) setMessages(messages map[string]map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = messages
+}
+
+// DefaultLocale 默认语言
+func (c *Component) DefaultLocale() string {
+	return canonicalLocale(c.config.DefaultLocale)
+}
+
+// Locales 支持的语言
+func (c *Component) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for locale := range c.messages {
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// Message 获取某个语言的消息，找不到时依次尝试基础语言（zh-CN => zh）和默认语言
+func (c *Component) Message(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range []string{canonicalLocale(locale), baseLocale(locale), c.DefaultLocale()} {
+		if msg, ok := c.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// T 根据ctx中的语言翻译消息，args不为空时使用fmt.Sprintf格式化，找不到消息时返回key
+func (c *Component) T(ctx context.Context, key string, args ...interface{}) string {
+	msg, ok := c.Message(c.localeFromContext(ctx), key)
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// LocalizeError 以EgoError的Reason为消息key，根据ctx中的语言翻译错误信息
+// 消息中的 {key} 会替换为错误Metadata中的值，非EgoError或者找不到消息时返回原错误
+func (c *Component) LocalizeError(ctx context.Context, err error) error {
+	egoErr := &eerrors.EgoError{}
+	if !errors.As(err, &egoErr) || egoErr.GetReason() == "" {
+		return err
+	}
+	msg, ok := c.Message(c.localeFromContext(ctx), egoErr.GetReason())
+	if !ok {
+		return err
+	}
+	if len(egoErr.GetMetadata()) > 0 {
+		pairs := make([]string, 0, len(egoErr.GetMetadata())*2)
+		for k, v := range egoErr.GetMetadata() {
+			pairs = append(pairs, "{"+k+"}", v)
+		}
+		msg = strings.NewReplacer(pairs...).Replace(msg)
+	}
+	return egoErr.WithMsg(msg)
+}
+
+// LocalizeGRPCError 翻译错误信息，返回gRPC status错误，翻译后的消息同时作为LocalizedMessage放入status的details
+// 非EgoError或者找不到消息时返回原错误
+func (c *Component) LocalizeGRPCError(ctx context.Context, err error) error {
+	localized := c.LocalizeError(ctx, err)
+	egoErr := &eerrors.EgoError{}
+	if localized == err || !errors.As(localized, &egoErr) {
+		return err
+	}
+	st, detailErr := status.New(codes.Code(egoErr.GetCode()), egoErr.GetMessage()).WithDetails(
+		&errdetails.ErrorInfo{Reason: egoErr.GetReason(), Metadata: egoErr.GetMetadata()},
+		&errdetails.LocalizedMessage{Locale: c.localeFromContext(ctx), Message: egoErr.GetMessage()},
+	)
+	if detailErr != nil {
+		return egoErr
+	}
+	return st.Err()
+}
+
+func (c *Component) localeFromContext(ctx context.Context) string {
+	if locale := LocaleFromContext(ctx); locale != "" {
+		return locale
+	}
+	return c.DefaultLocale()
+}
+
+// T 使用默认组件翻译消息，没有Build组件时返回key
+func T(ctx context.Context, key string, args ...interface{}) string {
+	c := Default()
+	if c == nil {
+		c = newComponent("", DefaultConfig(), elog.EgoLogger)
+	}
+	return c.T(ctx, key, args...)
+}
+
+// LocalizeError 使用默认组件翻译错误信息，没有Build组件时返回原错误
+func LocalizeError(ctx context.Context, err error) error {
+	c := Default()
+	if c == nil {
+		return err
+	}
+	return c.LocalizeError(ctx, err)
+}
@@ -0,0 +1,100 @@
+package ei18n
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eerrors"
+)
+
+func newTestComponent(t *testing.T) *Component {
+	fsys := fstest.MapFS{
+		"i18n/en.toml":    {Data: []byte("[user]\nnotFound = \"user {name} not found\"\nhello = \"hello %s\"\n")},
+		"i18n/zh-CN.json": {Data: []byte(`{"user":{"notFound":"用户 {name} 不存在","hello":"你好 %s"}}`)},
+	}
+	return DefaultContainer().Build(WithFS(fsys, "i18n/*"), WithMessages("ja", map[string]string{"user.hello": "こんにちは %s"}))
+}
+
+func TestNegotiate(t *testing.T) {
+	comp := newTestComponent(t)
+	assert.Equal(t, "zh-CN", comp.Negotiate("", "fr;q=0.9, zh-cn;q=0.8, *"))
+	assert.Equal(t, "en", comp.Negotiate("en-US"))
+	assert.Equal(t, "ja", comp.Negotiate("de", "ja-JP"))
+	// 都不支持时返回默认语言
+	assert.Equal(t, "en", comp.Negotiate("de, fr"))
+
+	req := httptest.NewRequest("GET", "/?lang=ja", nil)
+	req.Header.Set("Accept-Language", "zh-CN")
+	assert.Equal(t, "ja", comp.NegotiateHTTP(req))
+	req.Header.Set("X-Ego-Locale", "zh_CN")
+	req.URL.RawQuery = ""
+	assert.Equal(t, "zh-CN", comp.NegotiateHTTP(req))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "zh-CN,en;q=0.5"))
+	assert.Equal(t, "zh-CN", comp.NegotiateGRPC(ctx))
+}
+
+func TestT(t *testing.T) {
+	comp := newTestComponent(t)
+	ctx := WithLocale(context.Background(), "zh-CN")
+	assert.Equal(t, "你好 ego", comp.T(ctx, "user.hello", "ego"))
+	assert.Equal(t, "你好 ego", T(ctx, "user.hello", "ego"))
+	// 找不到时回退到默认语言，再回退到key
+	assert.Equal(t, "hello ego", comp.T(context.Background(), "user.hello", "ego"))
+	assert.Equal(t, "user.unknown", comp.T(ctx, "user.unknown"))
+}
+
+func TestLocalizeError(t *testing.T) {
+	comp := newTestComponent(t)
+	ctx := WithLocale(context.Background(), "zh-CN")
+	err := eerrors.New(int(codes.NotFound), "user.notFound", "user not found").WithMd(map[string]string{"name": "ego"})
+
+	localized := comp.LocalizeError(ctx, err)
+	assert.Equal(t, "用户 ego 不存在", eerrors.FromError(localized).GetMessage())
+	assert.Equal(t, "user.notFound", eerrors.FromError(localized).GetReason())
+
+	// 没有对应消息的错误不做处理
+	other := eerrors.New(int(codes.Internal), "unknown", "internal")
+	assert.Equal(t, error(other), comp.LocalizeError(ctx, other))
+
+	st, _ := status.FromError(comp.LocalizeGRPCError(ctx, err))
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, "用户 ego 不存在", st.Message())
+	var locale string
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.LocalizedMessage); ok {
+			locale = d.Locale
+		}
+	}
+	assert.Equal(t, "zh-CN", locale)
+	assert.Equal(t, "user.notFound", eerrors.FromError(st.Err()).GetReason())
+}
+
+func TestLoad(t *testing.T) {
+	conf := `
+[i18n]
+defaultLocale = "zh"
+[i18n.messages.zh.user]
+hello = "你好 %s"
+[i18n.messages.en]
+"user.hello" = "hello %s"
+`
+	err := econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal)
+	assert.NoError(t, err)
+	comp := Load("i18n").Build()
+	assert.Equal(t, comp, Default())
+	assert.Equal(t, "zh", comp.DefaultLocale())
+	assert.Equal(t, "你好 ego", comp.T(context.Background(), "user.hello", "ego"))
+	assert.Equal(t, "hello ego", comp.T(WithLocale(context.Background(), "en-GB"), "user.hello", "ego"))
+}
@@ -0,0 +1,19 @@
+package ei18n
+
+// Config 国际化配置
+type Config struct {
+	DefaultLocale string                            // 默认语言，无法协商出支持的语言时使用，默认en
+	QueryKey      string                            // HTTP请求中指定语言的query参数，默认lang
+	HeaderKey     string                            // HTTP、gRPC请求中指定语言的header，优先于Accept-Language，默认X-Ego-Locale
+	Messages      map[string]map[string]interface{} // 配置中的消息，key为语言，value为消息key和消息内容，支持嵌套表，嵌套的key以.连接
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		DefaultLocale: "en",
+		QueryKey:      "lang",
+		HeaderKey:     "X-Ego-Locale",
+		Messages:      make(map[string]map[string]interface{}),
+	}
+}
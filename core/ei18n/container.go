@@ -0,0 +1,166 @@
+package ei18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config  *Config
+	name    string
+	logger  *elog.Component
+	bundles []bundle
+	files   []fsFiles
+}
+
+type bundle struct {
+	locale   string
+	messages map[string]string
+}
+
+type fsFiles struct {
+	fsys    fs.FS
+	pattern string
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 构建国际化组件，并设置为默认组件，HTTP、gRPC服务使用默认组件翻译错误信息
+// 消息优先级：option设置的消息 > 配置中的消息 > 文件中的消息，配置热更新时重新加载配置中的消息
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	fileBundles, err := c.loadFiles()
+	if err != nil {
+		c.logger.Panic("load i18n files fail", elog.FieldErr(err))
+	}
+
+	comp := newComponent(c.name, c.config, c.logger)
+	comp.setMessages(c.merge(fileBundles, c.config))
+	setDefault(comp)
+
+	if c.name == "" {
+		return comp
+	}
+	econf.OnChange(func(newConf *econf.Configuration) {
+		config := DefaultConfig()
+		if err := newConf.UnmarshalKey(c.name, config); err != nil {
+			c.logger.Error("reload i18n config fail", elog.FieldErr(err))
+			return
+		}
+		comp.setMessages(c.merge(fileBundles, config))
+	})
+	return comp
+}
+
+// merge 按照优先级合并消息
+func (c *Container) merge(fileBundles []bundle, config *Config) map[string]map[string]string {
+	messages := make(map[string]map[string]string)
+	add := func(locale string, kv map[string]string) {
+		locale = canonicalLocale(locale)
+		if messages[locale] == nil {
+			messages[locale] = make(map[string]string)
+		}
+		for k, v := range kv {
+			messages[locale][k] = v
+		}
+	}
+	for _, b := range fileBundles {
+		add(b.locale, b.messages)
+	}
+	for locale, kv := range config.Messages {
+		flat := make(map[string]string)
+		flatten("", kv, flat)
+		add(locale, flat)
+	}
+	for _, b := range c.bundles {
+		add(b.locale, b.messages)
+	}
+	return messages
+}
+
+func (c *Container) loadFiles() ([]bundle, error) {
+	bundles := make([]bundle, 0)
+	for _, f := range c.files {
+		matches, err := fs.Glob(f.fsys, f.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("glob i18n files fail, pattern: %s, err: %w", f.pattern, err)
+		}
+		for _, file := range matches {
+			content, err := fs.ReadFile(f.fsys, file)
+			if err != nil {
+				return nil, fmt.Errorf("read i18n file fail, file: %s, err: %w", file, err)
+			}
+			raw := make(map[string]interface{})
+			ext := path.Ext(file)
+			switch ext {
+			case ".toml":
+				err = toml.Unmarshal(content, &raw)
+			case ".json":
+				err = json.Unmarshal(content, &raw)
+			case ".yaml", ".yml":
+				err = yaml.Unmarshal(content, &raw)
+			default:
+				err = fmt.Errorf("unsupported file type %s", ext)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("parse i18n file fail, file: %s, err: %w", file, err)
+			}
+			flat := make(map[string]string)
+			flatten("", raw, flat)
+			bundles = append(bundles, bundle{locale: strings.TrimSuffix(path.Base(file), ext), messages: flat})
+		}
+	}
+	return bundles, nil
+}
+
+// flatten 把嵌套的消息展开，嵌套的key以.连接
+func flatten(prefix string, value map[string]interface{}, out map[string]string) {
+	for k, v := range value {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flatten(key, val, out)
+		case string:
+			out[key] = val
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package ei18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type localeKey struct{}
+
+// WithLocale 把语言放入ctx
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, canonicalLocale(locale))
+}
+
+// LocaleFromContext 获取ctx中的语言，没有时返回空
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// Negotiate 按顺序协商语言，每个候选值可以是单个语言或者Accept-Language格式，返回第一个支持的语言，都不支持时返回默认语言
+func (c *Component) Negotiate(candidates ...string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, candidate := range candidates {
+		for _, locale := range parseAcceptLanguage(candidate) {
+			if _, ok := c.messages[locale]; ok {
+				return locale
+			}
+			if _, ok := c.messages[baseLocale(locale)]; ok {
+				return baseLocale(locale)
+			}
+		}
+	}
+	return c.DefaultLocale()
+}
+
+// NegotiateHTTP 根据query参数、header、Accept-Language协商HTTP请求的语言
+func (c *Component) NegotiateHTTP(r *http.Request) string {
+	return c.Negotiate(
+		r.URL.Query().Get(c.config.QueryKey),
+		r.Header.Get(c.config.HeaderKey),
+		r.Header.Get("Accept-Language"),
+	)
+}
+
+// NegotiateGRPC 根据metadata中的header、accept-language协商gRPC请求的语言
+func (c *Component) NegotiateGRPC(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	return c.Negotiate(get(c.config.HeaderKey), get("accept-language"))
+}
+
+// parseAcceptLanguage 解析Accept-Language，按照q值从高到低返回语言
+func parseAcceptLanguage(value string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	items := make([]weighted, 0)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		q := 1.0
+		if idx := strings.Index(part, ";"); idx >= 0 {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(part[idx+1:]), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+			part = strings.TrimSpace(part[:idx])
+		}
+		if part == "*" || q <= 0 {
+			continue
+		}
+		items = append(items, weighted{locale: canonicalLocale(part), q: q})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+	locales := make([]string, 0, len(items))
+	for _, item := range items {
+		locales = append(locales, item.locale)
+	}
+	return locales
+}
+
+// canonicalLocale 统一语言格式，例如 zh_cn => zh-CN
+func canonicalLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) == 2 {
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func baseLocale(locale string) string {
+	locale = canonicalLocale(locale)
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		return locale[:idx]
+	}
+	return locale
+}
@@ -0,0 +1,27 @@
+package ei18n
+
+import (
+	"io/fs"
+)
+
+// WithDefaultLocale 设置默认语言
+func WithDefaultLocale(locale string) Option {
+	return func(c *Container) {
+		c.config.DefaultLocale = locale
+	}
+}
+
+// WithMessages 设置某个语言的消息，优先于配置中的消息
+func WithMessages(locale string, messages map[string]string) Option {
+	return func(c *Container) {
+		c.bundles = append(c.bundles, bundle{locale: locale, messages: messages})
+	}
+}
+
+// WithFS 从文件系统（通常是embed.FS）加载消息文件，pattern为fs.Glob的匹配规则，例如 i18n/*.toml
+// 文件名（去掉扩展名）为语言，例如 zh-CN.toml，支持 toml、json、yaml 格式
+func WithFS(fsys fs.FS, pattern string) Option {
+	return func(c *Container) {
+		c.files = append(c.files, fsFiles{fsys: fsys, pattern: pattern})
+	}
+}
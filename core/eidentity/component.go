@@ -0,0 +1,221 @@
+package eidentity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.eidentity"
+
+var defaultComponent atomic.Pointer[Component]
+
+// Component 服务身份组件，持有本服务的SVID并自动轮换
+type Component struct {
+	name      string
+	config    *Config
+	logger    *elog.Component
+	source    Source
+	svid      atomic.Pointer[SVID]
+	ready     chan struct{}
+	readyOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mu        sync.Mutex
+	listeners []func(svid *SVID)
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, source Source) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		logger: logger,
+		source: source,
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Default 返回默认的身份组件，没有构建时返回nil
+func Default() *Component {
+	return defaultComponent.Load()
+}
+
+func setDefault(c *Component) {
+	defaultComponent.Store(c)
+}
+
+type peerIDKey struct{}
+
+// WithPeerID 把对端的SPIFFE ID写入ctx
+func WithPeerID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, peerIDKey{}, id)
+}
+
+// PeerIDFromContext 读取ctx中对端的SPIFFE ID
+func PeerIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(peerIDKey{}).(string)
+	return id, ok
+}
+
+// start 启动证书来源，等待第一个证书
+func (c *Component) start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go func() {
+		defer close(c.done)
+		if err := c.source.Run(ctx, c.update); err != nil {
+			c.logger.Error("svid source stopped", elog.FieldErr(err))
+		}
+	}()
+	select {
+	case <-c.ready:
+		return nil
+	case <-c.done:
+		return errors.New("svid source stopped before fetching svid")
+	case <-time.After(c.config.FetchTimeout):
+		return fmt.Errorf("fetch svid timeout after %s", c.config.FetchTimeout)
+	}
+}
+
+// update 替换当前证书，通知轮换回调
+func (c *Component) update(svid *SVID) {
+	old := c.svid.Swap(svid)
+	c.readyOnce.Do(func() { close(c.ready) })
+	if c.config.EnableMetric {
+		emetric.IdentityExpiryGauge.Set(float64(svid.ExpiresAt().Unix()), c.name, c.config.Source)
+		if old != nil {
+			emetric.IdentityRotateCounter.Inc(c.name, c.config.Source)
+		}
+	}
+	c.logger.Info("svid updated", elog.String("id", svid.ID), elog.String("expiresAt", svid.ExpiresAt().String()))
+	if old == nil {
+		return
+	}
+	c.mu.Lock()
+	listeners := append([]func(*SVID){}, c.listeners...)
+	c.mu.Unlock()
+	for _, fn := range listeners {
+		fn(svid)
+	}
+}
+
+// Close 停止证书轮换
+func (c *Component) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	return nil
+}
+
+// SVID 返回当前证书
+func (c *Component) SVID() *SVID {
+	return c.svid.Load()
+}
+
+// ID 返回本服务的SPIFFE ID
+func (c *Component) ID() string {
+	return c.svid.Load().ID
+}
+
+// OnRotate 注册证书轮换的回调
+func (c *Component) OnRotate(fn func(svid *SVID)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// ServerTLSConfig 返回服务端的mTLS配置，每次握手使用最新的证书，要求客户端提供SVID
+func (c *Component) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.svid.Load().tlsCertificate(), nil
+		},
+		VerifyPeerCertificate: c.verifyPeer(c.config.AllowedIDs),
+	}
+}
+
+// ClientTLSConfig 返回客户端的mTLS配置，allowedIDs为允许的服务端身份，为空时使用配置中的AllowedIDs
+func (c *Component) ClientTLSConfig(allowedIDs ...string) *tls.Config {
+	if len(allowedIDs) == 0 {
+		allowedIDs = c.config.AllowedIDs
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// SPIFFE使用URI SAN标识身份，不校验域名，在VerifyPeerCertificate中校验证书链和身份
+		InsecureSkipVerify: true, //nolint:gosec
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.svid.Load().tlsCertificate(), nil
+		},
+		VerifyPeerCertificate: c.verifyPeer(allowedIDs),
+	}
+}
+
+// GRPCServerCredentials 返回gRPC服务端的mTLS凭证
+func (c *Component) GRPCServerCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(c.ServerTLSConfig())
+}
+
+// GRPCClientCredentials 返回gRPC客户端的mTLS凭证
+func (c *Component) GRPCClientCredentials(allowedIDs ...string) credentials.TransportCredentials {
+	return credentials.NewTLS(c.ClientTLSConfig(allowedIDs...))
+}
+
+// verifyPeer 使用当前的信任根校验对端证书链，并校验对端身份
+func (c *Component) verifyPeer(allowedIDs []string) func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("peer has no certificate")
+		}
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("parse peer certificate fail, %w", err)
+			}
+			certs = append(certs, cert)
+		}
+		svid := c.svid.Load()
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         svid.roots(),
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return fmt.Errorf("verify peer certificate fail, %w", err)
+		}
+		id, err := IDFromCertificate(certs[0])
+		if err != nil {
+			return err
+		}
+		td := c.config.TrustDomain
+		if td == "" {
+			td = svid.TrustDomain()
+		}
+		if peerTD, _ := trustDomain(id); peerTD != td {
+			return fmt.Errorf("peer %s is not in trust domain %s", id, td)
+		}
+		if !matchID(allowedIDs, id) {
+			return fmt.Errorf("peer %s is not allowed", id)
+		}
+		return nil
+	}
+}
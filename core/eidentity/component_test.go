@@ -0,0 +1,299 @@
+package eidentity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, td string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: td},
+		URIs:                  []*url.URL{{Scheme: "spiffe", Host: td}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, id string, serial int64) *SVID {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	u, err := url.Parse(id)
+	require.NoError(t, err)
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		URIs:         []*url.URL{u},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &SVID{ID: id, Certificates: []*x509.Certificate{cert}, PrivateKey: key, Bundle: []*x509.Certificate{ca.cert}}
+}
+
+// staticSource 测试用的证书来源，从channel读取新证书
+type staticSource chan *SVID
+
+func (s staticSource) Run(ctx context.Context, update func(*SVID)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case svid := <-s:
+			update(svid)
+		}
+	}
+}
+
+func build(t *testing.T, svid *SVID, allowed ...string) *Component {
+	source := make(staticSource, 1)
+	source <- svid
+	c := DefaultContainer()
+	c.config.AllowedIDs = allowed
+	comp := c.Build(WithSource(source))
+	t.Cleanup(func() { _ = comp.Close() })
+	return comp
+}
+
+func handshake(server, client *tls.Config) (tls.ConnectionState, error) {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer lis.Close()
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		srv := conn.(*tls.Conn)
+		err = srv.Handshake()
+		results <- result{state: srv.ConnectionState(), err: err}
+	}()
+	cli, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer cli.Close()
+	// TLS1.3中服务端在客户端握手完成后才校验客户端证书，读取服务端的alert
+	_ = cli.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _ = cli.Read(make([]byte, 1))
+	res := <-results
+	return res.state, res.err
+}
+
+func TestMTLS(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	server := build(t, ca.issue(t, "spiffe://example.org/ns/default/sa/order", 2), "spiffe://example.org/ns/default/*")
+	client := build(t, ca.issue(t, "spiffe://example.org/ns/default/sa/user", 3))
+	assert.Same(t, client, Default())
+
+	state, err := handshake(server.ServerTLSConfig(), client.ClientTLSConfig("spiffe://example.org/ns/default/sa/order"))
+	require.NoError(t, err)
+	id, err := PeerID(state)
+	require.NoError(t, err)
+	assert.Equal(t, "spiffe://example.org/ns/default/sa/user", id)
+
+	// 客户端不信任服务端身份
+	_, err = handshake(server.ServerTLSConfig(), client.ClientTLSConfig("spiffe://example.org/ns/default/sa/pay"))
+	assert.ErrorContains(t, err, "is not allowed")
+
+	// 服务端只允许default命名空间
+	other := build(t, ca.issue(t, "spiffe://example.org/ns/test/sa/user", 4))
+	_, err = handshake(server.ServerTLSConfig(), other.ClientTLSConfig())
+	assert.Error(t, err)
+
+	// 其他信任域的证书
+	evil := newTestCA(t, "evil.org")
+	stranger := build(t, evil.issue(t, "spiffe://evil.org/ns/default/sa/user", 5))
+	_, err = handshake(server.ServerTLSConfig(), stranger.ClientTLSConfig())
+	assert.Error(t, err)
+}
+
+func TestRotate(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	source := make(staticSource, 1)
+	source <- ca.issue(t, "spiffe://example.org/order", 2)
+	comp := DefaultContainer().Build(WithSource(source))
+	defer comp.Close()
+
+	rotated := make(chan *SVID, 1)
+	comp.OnRotate(func(svid *SVID) { rotated <- svid })
+	next := ca.issue(t, "spiffe://example.org/order", 3)
+	source <- next
+	select {
+	case svid := <-rotated:
+		assert.Same(t, next, svid)
+		assert.Same(t, next, comp.SVID())
+	case <-time.After(time.Second):
+		t.Fatal("svid not rotated")
+	}
+}
+
+func writePEM(t *testing.T, file string, blocks ...*pem.Block) {
+	var content []byte
+	for _, block := range blocks {
+		content = append(content, pem.EncodeToMemory(block)...)
+	}
+	require.NoError(t, os.WriteFile(file, content, 0600))
+}
+
+func writeSVID(t *testing.T, dir string, svid *SVID) {
+	key, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "tls.crt"), &pem.Block{Type: "CERTIFICATE", Bytes: svid.Certificates[0].Raw})
+	writePEM(t, filepath.Join(dir, "tls.key"), &pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	writePEM(t, filepath.Join(dir, "ca.crt"), &pem.Block{Type: "CERTIFICATE", Bytes: svid.Bundle[0].Raw})
+}
+
+func TestFileSource(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	dir := t.TempDir()
+	writeSVID(t, dir, ca.issue(t, "spiffe://example.org/order", 2))
+
+	c := DefaultContainer()
+	c.config.Source = SourceFile
+	c.config.CertFile = filepath.Join(dir, "tls.crt")
+	c.config.KeyFile = filepath.Join(dir, "tls.key")
+	c.config.BundleFile = filepath.Join(dir, "ca.crt")
+	c.config.RefreshInterval = 10 * time.Millisecond
+	comp := c.Build()
+	defer comp.Close()
+	assert.Equal(t, "spiffe://example.org/order", comp.ID())
+	assert.Equal(t, "example.org", comp.SVID().TrustDomain())
+
+	rotated := make(chan *SVID, 1)
+	comp.OnRotate(func(svid *SVID) { rotated <- svid })
+	// 保证修改时间变化
+	time.Sleep(10 * time.Millisecond)
+	writeSVID(t, dir, ca.issue(t, "spiffe://example.org/order", 3))
+	select {
+	case svid := <-rotated:
+		assert.Equal(t, int64(3), svid.Certificates[0].SerialNumber.Int64())
+	case <-time.After(time.Second):
+		t.Fatal("svid not reloaded")
+	}
+}
+
+func encodeX509SVIDResponse(svid *SVID) []byte {
+	key, _ := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+	var msg []byte
+	msg = protowire.AppendTag(msg, 1, protowire.BytesType)
+	msg = protowire.AppendString(msg, svid.ID)
+	msg = protowire.AppendTag(msg, 2, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, svid.Certificates[0].Raw)
+	msg = protowire.AppendTag(msg, 3, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, key)
+	msg = protowire.AppendTag(msg, 4, protowire.BytesType)
+	msg = protowire.AppendBytes(msg, svid.Bundle[0].Raw)
+	var resp []byte
+	resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+	return protowire.AppendBytes(resp, msg)
+}
+
+func TestWorkloadSource(t *testing.T) {
+	ca := newTestCA(t, "example.org")
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	lis, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	updates := make(chan *SVID, 1)
+	updates <- ca.issue(t, "spiffe://example.org/order", 2)
+	srv := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}), grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		assert.Equal(t, workloadMethod, method)
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		assert.Equal(t, []string{"true"}, md.Get("workload.spiffe.io"))
+		var req []byte
+		if err := stream.RecvMsg(&req); err != nil {
+			return err
+		}
+		for {
+			select {
+			case <-stream.Context().Done():
+				return nil
+			case svid := <-updates:
+				resp := encodeX509SVIDResponse(svid)
+				if err := stream.SendMsg(&resp); err != nil {
+					return err
+				}
+			}
+		}
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	c := DefaultContainer()
+	c.config.SocketPath = "unix://" + socket
+	comp := c.Build()
+	defer comp.Close()
+	assert.Equal(t, "spiffe://example.org/order", comp.ID())
+
+	rotated := make(chan *SVID, 1)
+	comp.OnRotate(func(svid *SVID) { rotated <- svid })
+	updates <- ca.issue(t, "spiffe://example.org/order", 3)
+	select {
+	case svid := <-rotated:
+		assert.Equal(t, int64(3), svid.Certificates[0].SerialNumber.Int64())
+	case <-time.After(time.Second):
+		t.Fatal("svid not rotated")
+	}
+}
+
+func TestIDFromCertificate(t *testing.T) {
+	for _, id := range []string{"https://example.org/order", "spiffe:///order", "spiffe://example.org/order?a=1"} {
+		u, err := url.Parse(id)
+		require.NoError(t, err)
+		_, err = IDFromCertificate(&x509.Certificate{URIs: []*url.URL{u}})
+		assert.Error(t, err, id)
+	}
+	_, err := IDFromCertificate(&x509.Certificate{})
+	assert.Error(t, err)
+	assert.True(t, matchID(nil, "spiffe://example.org/a"))
+	assert.True(t, matchID([]string{"spiffe://example.org/*"}, "spiffe://example.org/a"))
+	assert.False(t, matchID([]string{"spiffe://example.org/b"}, "spiffe://example.org/a"))
+}
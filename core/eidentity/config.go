@@ -0,0 +1,40 @@
+package eidentity
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// SourceSPIFFE 从SPIFFE Workload API获取证书
+	SourceSPIFFE = "spiffe"
+	// SourceFile 从文件读取证书
+	SourceFile = "file"
+)
+
+// Config 服务身份配置
+type Config struct {
+	Source          string        // 证书来源，spiffe或file，默认spiffe
+	SocketPath      string        // Workload API地址，例如 unix:///run/spire/sockets/agent.sock，默认读取环境变量SPIFFE_ENDPOINT_SOCKET
+	CertFile        string        // file模式的证书，可以包含证书链，SAN中需要有spiffe://的URI
+	KeyFile         string        // file模式的私钥
+	BundleFile      string        // file模式的信任根证书
+	RefreshInterval time.Duration // file模式检查证书文件变化的间隔，默认1m
+	RetryInterval   time.Duration // Workload API断开后重连的间隔，默认1s
+	FetchTimeout    time.Duration // 启动时等待第一个证书的超时时间，默认10s
+	TrustDomain     string        // 信任域，为空时使用本服务证书的信任域，对端身份不在信任域内时拒绝连接
+	AllowedIDs      []string      // 允许连接的对端身份，支持*结尾的前缀匹配，为空时允许信任域内的所有身份
+	EnableMetric    bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Source:          SourceSPIFFE,
+		SocketPath:      os.Getenv("SPIFFE_ENDPOINT_SOCKET"),
+		RefreshInterval: time.Minute,
+		RetryInterval:   time.Second,
+		FetchTimeout:    10 * time.Second,
+		EnableMetric:    true,
+	}
+}
@@ -0,0 +1,72 @@
+package eidentity
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	source Source
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithSource 设置证书来源，优先于配置中的Source
+func WithSource(source Source) Option {
+	return func(c *Container) {
+		c.source = source
+	}
+}
+
+// Build 构建身份组件，等待获取到第一个证书，并设置为默认组件，HTTP、gRPC服务和客户端开启身份认证后使用默认组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.source == nil {
+		switch c.config.Source {
+		case SourceSPIFFE:
+			c.source = NewWorkloadSource(c.config.SocketPath, c.config.RetryInterval)
+		case SourceFile:
+			c.source = NewFileSource(c.config.CertFile, c.config.KeyFile, c.config.BundleFile, c.config.RefreshInterval)
+		default:
+			c.logger.Panic("unknown identity source", elog.String("source", c.config.Source))
+		}
+	}
+	comp := newComponent(c.name, c.config, c.logger, c.source)
+	if err := comp.start(); err != nil {
+		_ = comp.Close()
+		c.logger.Panic("start identity fail", elog.FieldErr(err))
+	}
+	c.logger.Info("identity started", elog.String("id", comp.ID()))
+	setDefault(comp)
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	return comp
+}
@@ -0,0 +1,100 @@
+package eidentity
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Source 证书来源，Run阻塞运行直到ctx取消，每次获取到新证书时调用update
+type Source interface {
+	Run(ctx context.Context, update func(*SVID)) error
+}
+
+// fileSource 从文件读取证书，定时检查文件变化，适用于cert-manager等工具挂载的证书
+type fileSource struct {
+	certFile   string
+	keyFile    string
+	bundleFile string
+	interval   time.Duration
+	logger     *elog.Component
+}
+
+// NewFileSource 创建从文件读取证书的来源，文件为PEM格式，keyFile为空时从certFile中读取私钥
+func NewFileSource(certFile, keyFile, bundleFile string, interval time.Duration) Source {
+	return &fileSource{
+		certFile:   certFile,
+		keyFile:    keyFile,
+		bundleFile: bundleFile,
+		interval:   interval,
+		logger:     elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Run 文件修改时间或大小变化时重新加载
+func (s *fileSource) Run(ctx context.Context, update func(*SVID)) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	var version string
+	for {
+		if current := s.version(); current != version {
+			svid, err := s.load()
+			if err != nil {
+				s.logger.Error("load svid from file fail", elog.FieldErr(err), elog.String("file", s.certFile))
+			} else {
+				version = current
+				update(svid)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *fileSource) version() string {
+	var version string
+	for _, file := range []string{s.certFile, s.keyFile, s.bundleFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			version += fmt.Sprintf("%d-%d;", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return version
+}
+
+func (s *fileSource) load() (*SVID, error) {
+	content, err := os.ReadFile(s.certFile)
+	if err != nil {
+		return nil, err
+	}
+	chain, key, err := parsePEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s fail, %w", s.certFile, err)
+	}
+	if s.keyFile != "" {
+		content, err = os.ReadFile(s.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, key, err = parsePEM(content); err != nil {
+			return nil, fmt.Errorf("parse %s fail, %w", s.keyFile, err)
+		}
+	}
+	content, err = os.ReadFile(s.bundleFile)
+	if err != nil {
+		return nil, err
+	}
+	roots, _, err := parsePEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s fail, %w", s.bundleFile, err)
+	}
+	return buildSVID(chain, key, roots)
+}
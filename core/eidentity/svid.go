@@ -0,0 +1,159 @@
+package eidentity
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SVID 服务的X.509身份证书
+type SVID struct {
+	ID           string              // SPIFFE ID，例如 spiffe://example.org/ns/default/sa/order
+	Certificates []*x509.Certificate // 证书链，第一个为叶子证书
+	PrivateKey   crypto.Signer
+	Bundle       []*x509.Certificate // 信任根证书
+}
+
+// TrustDomain 返回证书所在的信任域
+func (s *SVID) TrustDomain() string {
+	td, _ := trustDomain(s.ID)
+	return td
+}
+
+// ExpiresAt 返回叶子证书的过期时间
+func (s *SVID) ExpiresAt() time.Time {
+	return s.Certificates[0].NotAfter
+}
+
+func (s *SVID) tlsCertificate() *tls.Certificate {
+	cert := &tls.Certificate{PrivateKey: s.PrivateKey, Leaf: s.Certificates[0]}
+	for _, c := range s.Certificates {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	return cert
+}
+
+func (s *SVID) roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, c := range s.Bundle {
+		pool.AddCert(c)
+	}
+	return pool
+}
+
+// newSVID 解析DER格式的证书链、PKCS8私钥和信任根证书
+func newSVID(certs, key, bundle []byte) (*SVID, error) {
+	chain, err := x509.ParseCertificates(certs)
+	if err != nil {
+		return nil, fmt.Errorf("parse svid certificates fail, %w", err)
+	}
+	roots, err := x509.ParseCertificates(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("parse svid bundle fail, %w", err)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("parse svid private key fail, %w", err)
+	}
+	return buildSVID(chain, privateKey, roots)
+}
+
+func buildSVID(chain []*x509.Certificate, key crypto.PrivateKey, roots []*x509.Certificate) (*SVID, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("svid has no certificate")
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("svid has no bundle")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	// 证书和私钥文件分别更新时，可能读到不匹配的证书和私钥
+	if pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(chain[0].PublicKey) {
+		return nil, errors.New("svid private key does not match certificate")
+	}
+	id, err := IDFromCertificate(chain[0])
+	if err != nil {
+		return nil, err
+	}
+	return &SVID{ID: id, Certificates: chain, PrivateKey: signer, Bundle: roots}, nil
+}
+
+// parsePEM 解析PEM格式的证书或私钥文件
+func parsePEM(content []byte) (certs []*x509.Certificate, key crypto.PrivateKey, err error) {
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return certs, key, nil
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			certs = append(certs, cert)
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// IDFromCertificate 返回证书中的SPIFFE ID，SPIFFE规范要求证书中有且只有一个URI SAN
+func IDFromCertificate(cert *x509.Certificate) (string, error) {
+	if len(cert.URIs) != 1 {
+		return "", fmt.Errorf("certificate must contain exactly one URI SAN, got %d", len(cert.URIs))
+	}
+	id := cert.URIs[0].String()
+	if _, err := trustDomain(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// PeerID 返回TLS连接中对端证书的SPIFFE ID
+func PeerID(state tls.ConnectionState) (string, error) {
+	if len(state.PeerCertificates) == 0 {
+		return "", errors.New("no peer certificate")
+	}
+	return IDFromCertificate(state.PeerCertificates[0])
+}
+
+func trustDomain(id string) (string, error) {
+	u, err := url.Parse(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid spiffe id %q, %w", id, err)
+	}
+	if u.Scheme != "spiffe" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid spiffe id %q", id)
+	}
+	return u.Host, nil
+}
+
+// matchID 判断身份是否在允许列表中，支持*结尾的前缀匹配，列表为空时允许所有身份
+func matchID(allowed []string, id string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		if pattern == id || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(id, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
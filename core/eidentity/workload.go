@@ -0,0 +1,185 @@
+package eidentity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// SPIFFE Workload API只需要FetchX509SVID一个方法，这里直接编解码protobuf，避免引入go-spiffe依赖
+// https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE_Workload_API.md
+const workloadMethod = "/SpiffeWorkloadAPI/FetchX509SVID"
+
+var workloadStreamDesc = &grpc.StreamDesc{StreamName: "FetchX509SVID", ServerStreams: true}
+
+// rawCodec 直接收发编码后的protobuf消息
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("raw codec marshal unsupported type %T", v)
+	}
+	return *b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("raw codec unmarshal unsupported type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// workloadSource 从SPIFFE Workload API获取证书，agent轮换证书时会推送新的证书
+type workloadSource struct {
+	addr   string
+	retry  time.Duration
+	logger *elog.Component
+}
+
+// NewWorkloadSource 创建从SPIFFE Workload API获取证书的来源，addr例如 unix:///run/spire/sockets/agent.sock
+func NewWorkloadSource(addr string, retry time.Duration) Source {
+	return &workloadSource{
+		addr:   addr,
+		retry:  retry,
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Run 断开后按照重连间隔重新订阅
+func (s *workloadSource) Run(ctx context.Context, update func(*SVID)) error {
+	if s.addr == "" {
+		return errors.New("spiffe workload api address is empty")
+	}
+	for {
+		err := s.watch(ctx, update)
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.logger.Error("watch spiffe workload api fail", elog.FieldErr(err), elog.FieldAddr(s.addr))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.retry):
+		}
+	}
+}
+
+func (s *workloadSource) watch(ctx context.Context, update func(*SVID)) error {
+	target := strings.TrimPrefix(s.addr, "tcp://")
+	conn, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Workload API要求请求带上这个header，防止SSRF
+	ctx = metadata.AppendToOutgoingContext(ctx, "workload.spiffe.io", "true")
+	stream, err := conn.NewStream(ctx, workloadStreamDesc, workloadMethod, grpc.ForceCodec(rawCodec{}))
+	if err != nil {
+		return err
+	}
+	// X509SVIDRequest是空消息
+	if err = stream.SendMsg(&[]byte{}); err != nil {
+		return err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		var resp []byte
+		if err = stream.RecvMsg(&resp); err != nil {
+			return err
+		}
+		svid, err := decodeX509SVIDResponse(resp)
+		if err != nil {
+			s.logger.Error("decode x509 svid response fail", elog.FieldErr(err))
+			continue
+		}
+		update(svid)
+	}
+}
+
+// decodeX509SVIDResponse 解析X509SVIDResponse，多个SVID时使用第一个
+//
+//	message X509SVIDResponse { repeated X509SVID svids = 1; ... }
+//	message X509SVID { string spiffe_id = 1; bytes x509_svid = 2; bytes x509_svid_key = 3; bytes bundle = 4; }
+func decodeX509SVIDResponse(b []byte) (*SVID, error) {
+	var first []byte
+	err := rangeFields(b, func(num protowire.Number, value []byte) {
+		if num == 1 && first == nil {
+			first = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if first == nil {
+		return nil, errors.New("response has no svid")
+	}
+	var id string
+	var certs, key, bundle []byte
+	err = rangeFields(first, func(num protowire.Number, value []byte) {
+		switch num {
+		case 1:
+			id = string(value)
+		case 2:
+			certs = value
+		case 3:
+			key = value
+		case 4:
+			bundle = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	svid, err := newSVID(certs, key, bundle)
+	if err != nil {
+		return nil, err
+	}
+	if svid.ID != id {
+		return nil, fmt.Errorf("svid id mismatch, response: %s, certificate: %s", id, svid.ID)
+	}
+	return svid, nil
+}
+
+// rangeFields 遍历消息中length-delimited类型的字段，其他类型的字段跳过
+func rangeFields(b []byte, fn func(num protowire.Number, value []byte)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		fn(num, value)
+		b = b[n:]
+	}
+	return nil
+}
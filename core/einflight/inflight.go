@@ -0,0 +1,124 @@
+package einflight
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.einflight"
+
+// Route 路由正在处理的请求
+type Route struct {
+	Type     string  `json:"type"`     // http、unary、stream
+	Route    string  `json:"route"`    // 路由或者gRPC方法
+	Inflight int     `json:"inflight"` // 正在处理的请求数
+	Oldest   float64 `json:"oldestMs"` // 最早开始的请求已经处理的时间
+}
+
+type entry struct {
+	mu     sync.Mutex
+	typ    string
+	route  string
+	seq    uint64
+	starts map[uint64]time.Time
+}
+
+var (
+	mu     sync.RWMutex
+	routes = make(map[string]*entry)
+)
+
+func lookup(typ, route string) *entry {
+	key := typ + " " + route
+	mu.RLock()
+	e, ok := routes[key]
+	mu.RUnlock()
+	if ok {
+		return e
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if e, ok = routes[key]; ok {
+		return e
+	}
+	e = &entry{typ: typ, route: route, starts: make(map[uint64]time.Time)}
+	routes[key] = e
+	return e
+}
+
+// Begin 开始处理请求，返回请求结束时调用的函数
+func Begin(typ, route string) (done func()) {
+	e := lookup(typ, route)
+	e.mu.Lock()
+	e.seq++
+	id := e.seq
+	e.starts[id] = time.Now()
+	e.mu.Unlock()
+	emetric.ServerInflightGauge.Inc(typ, route)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.starts, id)
+			e.mu.Unlock()
+			emetric.ServerInflightGauge.Dec(typ, route)
+		})
+	}
+}
+
+// Total 返回所有正在处理的请求数
+func Total() int {
+	total := 0
+	for _, r := range snapshot() {
+		total += r.Inflight
+	}
+	return total
+}
+
+// Top 返回正在处理的请求数最多的n个路由，请求数相同时按照最早请求的处理时间排序，n小于等于0时返回所有路由
+func Top(n int) []Route {
+	out := snapshot()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Inflight != out[j].Inflight {
+			return out[i].Inflight > out[j].Inflight
+		}
+		return out[i].Oldest > out[j].Oldest
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// snapshot 返回有正在处理的请求的路由
+func snapshot() []Route {
+	mu.RLock()
+	entries := make([]*entry, 0, len(routes))
+	for _, e := range routes {
+		entries = append(entries, e)
+	}
+	mu.RUnlock()
+	now := time.Now()
+	out := make([]Route, 0)
+	for _, e := range entries {
+		e.mu.Lock()
+		if len(e.starts) == 0 {
+			e.mu.Unlock()
+			continue
+		}
+		var oldest time.Time
+		for _, start := range e.starts {
+			if oldest.IsZero() || start.Before(oldest) {
+				oldest = start
+			}
+		}
+		r := Route{Type: e.typ, Route: e.route, Inflight: len(e.starts), Oldest: float64(now.Sub(oldest).Microseconds()) / 1000}
+		e.mu.Unlock()
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package einflight
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+func TestTop(t *testing.T) {
+	poll1 := Begin("http", "GET./poll")
+	poll2 := Begin("http", "GET./poll")
+	time.Sleep(5 * time.Millisecond)
+	watch := Begin("stream", "/pkg.Svc/Watch")
+	get := Begin("unary", "/pkg.Svc/Get")
+	assert.Equal(t, float64(2), testutil.ToFloat64(emetric.ServerInflightGauge.WithLabelValues("http", "GET./poll")))
+
+	top := Top(2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, Route{Type: "http", Route: "GET./poll", Inflight: 2, Oldest: top[0].Oldest}, top[0])
+	assert.GreaterOrEqual(t, top[0].Oldest, float64(5))
+	assert.Equal(t, 4, Total())
+
+	// 重复调用只减少一次
+	poll1()
+	poll1()
+	get()
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.ServerInflightGauge.WithLabelValues("http", "GET./poll")))
+	top = Top(0)
+	assert.Len(t, top, 2)
+	// 请求数相同时最早的请求排在前面
+	assert.Equal(t, "GET./poll", top[0].Route)
+	assert.Equal(t, "/pkg.Svc/Watch", top[1].Route)
+
+	poll2()
+	watch()
+	assert.Empty(t, Top(10))
+	assert.Equal(t, 0, Total())
+}
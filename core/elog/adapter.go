@@ -0,0 +1,77 @@
+package elog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// levelTags 第三方日志前缀中的级别标识，按顺序匹配
+var levelTags = []struct {
+	level Level
+	tags  []string
+}{
+	{ErrorLevel, []string{"[ERROR]", "[ERRO]", "ERROR:", "[FATAL]", "[PANIC]"}},
+	{WarnLevel, []string{"[WARN]", "[WARNING]", "WARN:", "WARNING:"}},
+	{DebugLevel, []string{"[DEBUG]", "-DEBUG]", "DEBUG:"}},
+	{InfoLevel, []string{"[INFO]", "INFO:"}},
+}
+
+// NewWriter 将写入的每一行作为一条日志输出，日志前缀带有[ERROR]、[WARNING]、[DEBUG]等级别标识时使用对应级别，否则使用level
+// 用于接管标准库log、gin.DefaultWriter等只支持io.Writer的第三方日志，调用位置对第三方日志没有意义，不输出caller
+func NewWriter(logger *Component, level Level) io.Writer {
+	return &writer{logger: logger.desugar.WithOptions(zap.WithCaller(false)), level: level}
+}
+
+type writer struct {
+	logger *zap.Logger
+	level  Level
+}
+
+// Write ...
+func (w *writer) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		msg := strings.TrimSpace(string(line))
+		if msg == "" {
+			continue
+		}
+		if ce := w.logger.Check(levelOf(msg, w.level), msg); ce != nil {
+			ce.Write()
+		}
+	}
+	return len(p), nil
+}
+
+// levelOf 只匹配日志开头的级别标识，避免消息中的error等单词影响级别
+// [ERROR]等带括号的标识可以跟在其他前缀之后，例如 [GIN-debug] [WARNING]
+func levelOf(msg string, level Level) Level {
+	prefix := msg
+	if len(prefix) > 32 {
+		prefix = prefix[:32]
+	}
+	prefix = strings.ToUpper(prefix)
+	for _, item := range levelTags {
+		for _, tag := range item.tags {
+			if strings.HasPrefix(prefix, tag) || (strings.HasSuffix(tag, "]") && strings.Contains(prefix, tag)) {
+				return item.level
+			}
+		}
+	}
+	return level
+}
+
+// RedirectStdLog 将标准库log的输出重定向到logger，返回恢复的函数
+func RedirectStdLog(logger *Component) func() {
+	flags, prefix, output := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(NewWriter(logger.With(FieldComponentName("stdlog")), InfoLevel))
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(output)
+	}
+}
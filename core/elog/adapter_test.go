@@ -0,0 +1,43 @@
+package elog
+
+import (
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewWriter(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := DefaultContainer().Build(WithZapCore(core))
+	w := NewWriter(logger, InfoLevel)
+	_, err := w.Write([]byte("[GIN-debug] GET /hello --> main.hello (3 handlers)\n[GIN-debug] [WARNING] Running in \"debug\" mode\n"))
+	assert.NoError(t, err)
+	_, _ = w.Write([]byte("[ERROR] connect fail\n\n"))
+	_, _ = w.Write([]byte("request fail, error: timeout"))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 4)
+	assert.Equal(t, DebugLevel, entries[0].Level)
+	assert.Equal(t, "[GIN-debug] GET /hello --> main.hello (3 handlers)", entries[0].Message)
+	assert.Equal(t, WarnLevel, entries[1].Level)
+	assert.Equal(t, ErrorLevel, entries[2].Level)
+	// 只匹配开头的级别标识
+	assert.Equal(t, InfoLevel, entries[3].Level)
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := DefaultContainer().Build(WithZapCore(core))
+	restore := RedirectStdLog(logger)
+	log.Print("hello")
+	restore()
+	log.Print("not redirected")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "hello", entries[0].Message)
+	assert.Equal(t, "stdlog", entries[0].ContextMap()["compName"])
+}
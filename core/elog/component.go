@@ -56,6 +56,10 @@ var (
 	Int32 = zap.Int32
 	// Uint alias for zap.Uint
 	Uint = zap.Uint
+	// Bool alias for zap.Bool
+	Bool = zap.Bool
+	// Float64 alias for zap.Float64
+	Float64 = zap.Float64
 	// Duration alias for zap.Duration
 	Duration = zap.Duration
 	// Durationp alias for zap.Duration
@@ -94,7 +98,8 @@ func newLogger(name string, key string, config *Config) *Component {
 		config.asyncStopFunc = w.Close
 	}
 
-	zapLogger := zap.New(config.core, zapOptions...)
+	// 同时写入最近日志，开启诊断包时异常退出可以保留现场
+	zapLogger := zap.New(zapcore.NewTee(config.core, newRecentCore(config.Name, &config.al)), zapOptions...)
 	l := &Component{
 		desugar:       zapLogger,
 		lv:            &config.al,
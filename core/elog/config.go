@@ -30,6 +30,10 @@ type Config struct {
 	CallerSkip      int
 	encoderConfig   *zapcore.EncoderConfig
 	al              zap.AtomicLevel
+
+	FailOnWriterError    bool // [fileWriter]日志文件无法创建时是否panic，默认false，降级输出到stderr并告警
+	EnableRedirectStdLog bool // 是否将标准库log的输出重定向到该日志，默认关闭
+	EnableRedirectSlog   bool // 是否将log/slog的默认logger输出到该日志，默认关闭
 }
 
 // Filename ...
@@ -54,6 +54,9 @@ func (c *Container) Build(options ...Option) *Component {
 	if eapp.EnableLoggerAddApp() {
 		c.config.fields = append(c.config.fields, FieldApp(eapp.Name()))
 	}
+	if eapp.EnableLoggerAddResource() {
+		c.config.fields = append(c.config.fields, FieldResource(eapp.GetResource()))
+	}
 
 	// 设置ego日志的log name，用于stderr区分系统日志和业务日志
 	// config writer setting > env writer setting
@@ -61,5 +64,12 @@ func (c *Container) Build(options ...Option) *Component {
 		c.config.fields = append(c.config.fields, FieldLogName(c.config.Name))
 	}
 
-	return newLogger(c.name, c.name, c.config)
+	logger := newLogger(c.name, c.name, c.config)
+	if c.config.EnableRedirectSlog {
+		RedirectSlog(logger)
+	}
+	if c.config.EnableRedirectStdLog {
+		RedirectStdLog(logger)
+	}
+	return logger
 }
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
+	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/etrace"
 )
 
@@ -25,6 +27,17 @@ func FieldApp(value string) Field {
 	return String("app", value)
 }
 
+// FieldResource constructs an elog Field with application resource attributes
+func FieldResource(res eapp.Resource) Field {
+	attrs := res.Attributes()
+	return zap.Object("resource", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		for _, key := range res.Keys() {
+			enc.AddString(key, attrs[key])
+		}
+		return nil
+	}))
+}
+
 // FieldAddr constructs an elog Field with some address
 func FieldAddr(value string) Field {
 	return String("addr", value)
@@ -154,3 +167,8 @@ func FieldCustomKeyValue(key string, value string) Field {
 func FieldLogName(value string) Field {
 	return String("lname", value)
 }
+
+// FieldRequestID constructs an elog Field with request id
+func FieldRequestID(value string) Field {
+	return String("rid", value)
+}
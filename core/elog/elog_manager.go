@@ -4,6 +4,8 @@ import (
 	"io"
 
 	"go.uber.org/zap/zapcore"
+
+	"github.com/gotomicro/ego/core/eextension"
 )
 
 var (
@@ -32,6 +34,7 @@ type CloseFunc func() error
 
 // Register registers a dataSource creator function to the registry
 func Register(builder WriterBuilder) {
+	_ = eextension.Register(eextension.KindLogWriter, builder.Scheme())
 	registry[builder.Scheme()] = builder
 }
 
@@ -1,6 +1,8 @@
 package elog
 
 import (
+	"log/slog"
+
 	"go.uber.org/zap/zapcore"
 )
 
@@ -73,3 +75,10 @@ func WithCallSkip(callerSkip int) Option {
 		c.config.CallerSkip = callerSkip
 	}
 }
+
+// WithSlogHandler 日志输出到slog.Handler，用于使用slog作为日志前端的应用，日志级别仍然由Level控制
+func WithSlogHandler(handler slog.Handler) Option {
+	return func(c *Container) {
+		c.config.core = NewSlogCore(handler, c.config.al)
+	}
+}
@@ -0,0 +1,46 @@
+package elog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gotomicro/ego/core/util/xcolor"
+)
+
+var fallbacks = struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}{counts: make(map[string]int64)}
+
+// WriterFallbacks 返回日志文件无法创建、降级输出到stderr的次数，key为日志名称
+func WriterFallbacks() map[string]int64 {
+	fallbacks.mu.Lock()
+	defer fallbacks.mu.Unlock()
+	res := make(map[string]int64, len(fallbacks.counts))
+	for name, count := range fallbacks.counts {
+		res[name] = count
+	}
+	return res
+}
+
+// checkWritable 提前创建日志目录和文件，只读文件系统、目录没有权限时返回错误
+func checkWritable(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// fallbackStderr 记录降级并在终端输出醒目的告警
+func fallbackStderr(name string, filename string, err error) {
+	fallbacks.mu.Lock()
+	fallbacks.counts[name]++
+	fallbacks.mu.Unlock()
+	fmt.Fprintf(os.Stderr, "%s: log file %s is unwritable, fallback to stderr, %s: %v\n", xcolor.Red("warning"), filename, xcolor.Red("err"), err)
+}
@@ -0,0 +1,39 @@
+package elog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterFallback(t *testing.T) {
+	// 日志目录是一个文件，无法创建日志文件
+	dir := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(dir, nil, 0644))
+
+	c := DefaultContainer()
+	c.config.Writer = writerRotateLogger
+	c.config.Dir = dir
+	c.config.Name = "fallback.log"
+	logger := c.Build()
+	_, ok := logger.config.core.(*stderrWriter)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), WriterFallbacks()["fallback.log"])
+	logger.Info("fallback to stderr")
+
+	c = DefaultContainer()
+	c.config.Writer = writerRotateLogger
+	c.config.Dir = dir
+	c.config.FailOnWriterError = true
+	assert.PanicsWithError(t, "elog create log file "+dir+"/default.log fail, mkdir "+dir+": not a directory", func() {
+		c.Build()
+	})
+
+	c = DefaultContainer()
+	c.config.Writer = writerRotateLogger
+	c.config.Dir = t.TempDir()
+	_, ok = c.Build().config.core.(*rotateWriter)
+	assert.True(t, ok)
+}
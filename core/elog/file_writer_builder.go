@@ -1,6 +1,7 @@
 package elog
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"time"
@@ -56,6 +57,13 @@ func (r *rotateWriterBuilder) Build(key string, commonConfig *Config) Writer {
 	if err := econf.UnmarshalKey(key, &c); err != nil {
 		panic(err)
 	}
+	if err := checkWritable(commonConfig.Filename()); err != nil {
+		if commonConfig.FailOnWriterError {
+			panic(fmt.Errorf("elog create log file %s fail, %w", commonConfig.Filename(), err))
+		}
+		fallbackStderr(commonConfig.Name, commonConfig.Filename(), err)
+		return (&stderrWriterBuilder{}).Build(key, commonConfig)
+	}
 	// NewRotateFileCore constructs a zapcore.Core with rotate file syncer
 	// Debug output to console and file by default
 	cf := noopCloseFunc
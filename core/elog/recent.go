@@ -0,0 +1,131 @@
+package elog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// recentLogs 所有logger共享的最近日志，默认不保留
+var recentLogs = &recentRing{}
+
+// SetRecentLines 设置保留的最近日志行数，用于异常退出时的诊断包，0为不保留
+func SetRecentLines(n int) {
+	recentLogs.resize(n)
+}
+
+// RecentLines 返回最近的日志，JSON格式，最早的日志在前
+func RecentLines() []string {
+	return recentLogs.get()
+}
+
+type recentRing struct {
+	size  atomic.Int64
+	mu    sync.Mutex
+	lines []string
+	next  int // lines写满后下一次覆盖的位置
+}
+
+func (r *recentRing) resize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := r.getLocked()
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	r.lines = append(make([]string, 0, n), lines...)
+	r.next = 0
+	r.size.Store(int64(n))
+}
+
+func (r *recentRing) enabled() bool {
+	return r.size.Load() > 0
+}
+
+func (r *recentRing) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size := int(r.size.Load())
+	if size == 0 {
+		return
+	}
+	if len(r.lines) < size {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % size
+}
+
+func (r *recentRing) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getLocked()
+}
+
+func (r *recentRing) getLocked() []string {
+	out := make([]string, 0, len(r.lines))
+	out = append(out, r.lines[r.next:]...)
+	return append(out, r.lines[:r.next]...)
+}
+
+// recentCore 将日志编码后写入recentLogs，没有开启时不编码
+type recentCore struct {
+	name    string
+	enabler zapcore.LevelEnabler
+	fields  []zapcore.Field
+}
+
+func newRecentCore(name string, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &recentCore{name: name, enabler: enabler}
+}
+
+// Enabled ...
+func (c *recentCore) Enabled(level zapcore.Level) bool {
+	return recentLogs.enabled() && c.enabler.Enabled(level)
+}
+
+// With 只保存字段，写入时再编码
+func (c *recentCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recentCore{
+		name:    c.name,
+		enabler: c.enabler,
+		fields:  append(append(make([]zapcore.Field, 0, len(c.fields)+len(fields)), c.fields...), fields...),
+	}
+}
+
+// Check ...
+func (c *recentCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write ...
+func (c *recentCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.LoggerName == "" {
+		entry.LoggerName = c.name
+	}
+	enc := zapcore.NewJSONEncoder(*defaultZapConfig())
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	buf, err := enc.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	recentLogs.add(strings.TrimSuffix(buf.String(), "\n"))
+	buf.Free()
+	return nil
+}
+
+// Sync ...
+func (c *recentCore) Sync() error {
+	return nil
+}
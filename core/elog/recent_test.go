@@ -0,0 +1,37 @@
+package elog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecentLines(t *testing.T) {
+	defer SetRecentLines(0)
+	logger := DefaultContainer().Build(WithZapCore(zapcore.NewNopCore()))
+
+	logger.Info("before enabled")
+	assert.Empty(t, RecentLines())
+
+	SetRecentLines(2)
+	logger.With(String("tid", "abc")).Info("first")
+	logger.Debug("debug not enabled")
+	logger.Info("second")
+	logger.Warn("third")
+	lines := RecentLines()
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"second"`)
+	assert.Contains(t, lines[1], `"msg":"third"`)
+
+	// 扩容时保留已有的日志
+	SetRecentLines(3)
+	logger.With(String("tid", "abc")).Error("fourth")
+	lines = RecentLines()
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.Contains(lines[2], `"tid":"abc"`))
+
+	SetRecentLines(1)
+	assert.Equal(t, lines[2:], RecentLines())
+}
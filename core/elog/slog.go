@@ -0,0 +1,251 @@
+package elog
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"runtime"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// NewSlogHandler 返回输出到logger的slog.Handler，使用log/slog的第三方库日志可以进入elog
+// slog的group对应zap的Namespace，ctx中有链路时添加tid字段
+func NewSlogHandler(logger *Component) slog.Handler {
+	return &slogHandler{
+		logger:    logger.desugar.WithOptions(zap.WithCaller(false)),
+		addCaller: logger.config != nil && logger.config.EnableAddCaller,
+	}
+}
+
+// NewSlogLogger 返回输出到logger的*slog.Logger
+func NewSlogLogger(logger *Component) *slog.Logger {
+	return slog.New(NewSlogHandler(logger))
+}
+
+type slogHandler struct {
+	logger    *zap.Logger
+	addCaller bool
+}
+
+// Enabled ...
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(zapLevel(level))
+}
+
+// Handle ...
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	ce := h.logger.Check(zapLevel(record.Level), record.Message)
+	if ce == nil {
+		return nil
+	}
+	if !record.Time.IsZero() {
+		ce.Time = record.Time
+	}
+	if h.addCaller && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	fields := make([]Field, 0, record.NumAttrs()+1)
+	if tid := etrace.ExtractTraceID(ctx); tid != "" {
+		fields = append(fields, FieldTid(tid))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, attr)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs ...
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendAttr(fields, attr)
+	}
+	return &slogHandler{logger: h.logger.With(fields...), addCaller: h.addCaller}
+}
+
+// WithGroup ...
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger.With(zap.Namespace(name)), addCaller: h.addCaller}
+}
+
+// zapLevel slog的级别可以是任意整数，按照区间对应
+func zapLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
+
+// appendAttr 按照slog的约定，忽略空的attr，key为空的group展开到上一级
+func appendAttr(fields []Field, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, attr.Value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, attr.Value.Time()))
+	case slog.KindGroup:
+		attrs := attr.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			for _, a := range attrs {
+				fields = appendAttr(fields, a)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(attrs)))
+	default:
+		return append(fields, zap.Any(attr.Key, attr.Value.Any()))
+	}
+}
+
+// slogGroup 将slog的group编码为zap的对象
+type slogGroup []slog.Attr
+
+// MarshalLogObject ...
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []Field
+	for _, attr := range g {
+		fields = appendAttr(fields, attr)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+// RedirectSlog 将slog的默认logger输出到logger，slog.SetDefault同时会接管标准库log，返回恢复的函数
+func RedirectSlog(logger *Component) func() {
+	prev := slog.Default()
+	flags, output := log.Flags(), log.Writer()
+	slog.SetDefault(NewSlogLogger(logger.With(FieldComponentName("slog"))))
+	return func() {
+		slog.SetDefault(prev)
+		log.SetFlags(flags)
+		log.SetOutput(output)
+	}
+}
+
+// NewSlogCore 返回输出到slog.Handler的zapcore.Core，enabler为nil时由handler判断级别
+func NewSlogCore(handler slog.Handler, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &slogCore{handler: handler, enabler: enabler}
+}
+
+type slogCore struct {
+	handler slog.Handler
+	enabler zapcore.LevelEnabler
+}
+
+// Enabled ...
+func (c *slogCore) Enabled(level zapcore.Level) bool {
+	if c.enabler != nil && !c.enabler.Enabled(level) {
+		return false
+	}
+	return c.handler.Enabled(context.Background(), slogLevel(level))
+}
+
+// With ...
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slogCore{handler: c.handler.WithAttrs(slogAttrs(fields)), enabler: c.enabler}
+}
+
+// Check ...
+func (c *slogCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write ...
+func (c *slogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if entry.Caller.Defined {
+		pc = entry.Caller.PC
+	}
+	record := slog.NewRecord(entry.Time, slogLevel(entry.Level), entry.Message, pc)
+	record.AddAttrs(slogAttrs(fields)...)
+	if entry.Stack != "" {
+		record.AddAttrs(slog.String("stack", entry.Stack))
+	}
+	return c.handler.Handle(context.Background(), record)
+}
+
+// Sync ...
+func (c *slogCore) Sync() error {
+	return nil
+}
+
+func slogLevel(level zapcore.Level) slog.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// slogAttrs 通过MapObjectEncoder编码zap字段，Namespace编码为嵌套的group
+func slogAttrs(fields []zapcore.Field) []slog.Attr {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return mapAttrs(enc.Fields)
+}
+
+func mapAttrs(m map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(m))
+	for _, key := range keys {
+		val := m[key]
+		if sub, ok := val.(map[string]interface{}); ok {
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(mapAttrs(sub)...)})
+			continue
+		}
+		attrs = append(attrs, slog.Any(key, val))
+	}
+	return attrs
+}
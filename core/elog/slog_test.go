@@ -0,0 +1,69 @@
+package elog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogHandler(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewSlogLogger(DefaultContainer().Build(WithZapCore(core)))
+	logger.Debug("ignored")
+	logger.With("app", "svc").WithGroup("req").Warn("slow request",
+		"cost", 2*time.Second,
+		slog.Group("peer", "ip", "127.0.0.1"),
+		slog.Group("", "inline", true),
+		"error", errors.New("timeout"),
+	)
+	logger.Log(context.Background(), slog.LevelError+4, "fatal error")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, WarnLevel, entries[0].Level)
+	assert.Equal(t, "slow request", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"app": "svc",
+		"req": map[string]interface{}{
+			"cost":   2 * time.Second,
+			"peer":   map[string]interface{}{"ip": "127.0.0.1"},
+			"inline": true,
+			"error":  "timeout",
+		},
+	}, entries[0].ContextMap())
+	assert.Equal(t, ErrorLevel, entries[1].Level)
+}
+
+func TestSlogCore(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}})
+	logger := DefaultContainer().Build(WithSlogHandler(handler), WithLevel("info"))
+	logger.Debug("ignored")
+	logger.With(String("app", "svc")).Info("hello", Int("count", 1), Namespace("req"), String("method", "GET"))
+	logger.Error("fail", FieldErr(errors.New("timeout")))
+	assert.Equal(t, "level=INFO msg=hello app=svc count=1 req.method=GET\nlevel=ERROR msg=fail error=timeout\n", buf.String())
+}
+
+func TestRedirectSlog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	restore := RedirectSlog(DefaultContainer().Build(WithZapCore(core)))
+	slog.Info("hello", "count", 1)
+	restore()
+	slog.Info("not redirected")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{"compName": "slog", "count": int64(1)}, entries[0].ContextMap())
+}
@@ -0,0 +1,176 @@
+package emaintenance
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "core.emaintenance"
+
+var (
+	mu        sync.RWMutex
+	refreshMu sync.Mutex // 保证状态变化的通知按顺序执行
+	config    = DefaultConfig()
+	bodyTpl   = template.Must(template.New("maintenance").Parse(config.Body))
+	override  *bool       // 运行时设置的状态，优先于配置，为nil时跟随配置
+	listeners []*listener // 维护模式状态变化的回调
+	enabled   atomic.Bool // 当前是否处于维护模式，用于请求路径上的快速判断
+)
+
+// listener 状态变化的回调，按指针取消注册
+type listener struct {
+	fn func(bool)
+}
+
+// bodyData 响应body模板的变量
+type bodyData struct {
+	App        string
+	Path       string
+	RetryAfter string
+}
+
+// IsEnabled 当前是否处于维护模式
+func IsEnabled() bool {
+	return enabled.Load()
+}
+
+// Enable 运行时开启或者关闭维护模式，优先于配置，配置热更新不会覆盖该状态
+func Enable(enable bool) {
+	mu.Lock()
+	override = &enable
+	mu.Unlock()
+	refresh()
+}
+
+// Reset 清除运行时设置的状态，重新跟随配置
+func Reset() {
+	mu.Lock()
+	override = nil
+	mu.Unlock()
+	refresh()
+}
+
+// OnChange 注册维护模式状态变化的回调，返回取消注册的函数，组件停止时需要调用
+func OnChange(fn func(enabled bool)) (unregister func()) {
+	l := &listener{fn: fn}
+	mu.Lock()
+	listeners = append(listeners, l)
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, v := range listeners {
+			if v == l {
+				// 复制一份，不影响refresh中正在通知的回调
+				listeners = append(listeners[:i:i], listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// AllowPath 维护模式下是否允许访问该HTTP路由
+func AllowPath(path string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return match(config.AllowPaths, path)
+}
+
+// AllowMethod 维护模式下是否允许访问该gRPC方法
+func AllowMethod(method string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return match(config.AllowMethods, method)
+}
+
+// RetryAfter 返回给客户端的重试时间
+func RetryAfter() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.RetryAfter
+}
+
+// ContentType 维护模式响应的Content-Type
+func ContentType() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config.ContentType
+}
+
+// RenderBody 渲染维护模式响应的body
+func RenderBody(path string) []byte {
+	mu.RLock()
+	tpl, retryAfter := bodyTpl, config.RetryAfter
+	mu.RUnlock()
+
+	buf := &bytes.Buffer{}
+	err := tpl.Execute(buf, bodyData{
+		App:        eapp.Name(),
+		Path:       path,
+		RetryAfter: retryAfter.String(),
+	})
+	if err != nil {
+		elog.EgoLogger.Error("render maintenance body fail", elog.FieldComponent(PackageName), elog.FieldErr(err))
+		return []byte("service unavailable")
+	}
+	return buf.Bytes()
+}
+
+func setConfig(c *Config) error {
+	tpl, err := template.New("maintenance").Parse(c.Body)
+	if err != nil {
+		return fmt.Errorf("parse maintenance body template fail, %w", err)
+	}
+	mu.Lock()
+	config = c
+	bodyTpl = tpl
+	mu.Unlock()
+	refresh()
+	return nil
+}
+
+// refresh 重新计算维护模式状态，状态变化时通知回调
+func refresh() {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	mu.RLock()
+	next := config.Enable
+	if override != nil {
+		next = *override
+	}
+	fns := listeners
+	mu.RUnlock()
+
+	if enabled.Swap(next) == next {
+		return
+	}
+	elog.EgoLogger.Warn("maintenance mode changed", elog.FieldComponent(PackageName), elog.Bool("enabled", next))
+	for _, l := range fns {
+		l.fn(next)
+	}
+}
+
+func match(patterns []string, target string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(target, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if pattern == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package emaintenance
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestEnable(t *testing.T) {
+	var changes []bool
+	unregister := OnChange(func(enabled bool) {
+		changes = append(changes, enabled)
+	})
+	defer unregister()
+	assert.False(t, IsEnabled())
+
+	Enable(true)
+	assert.True(t, IsEnabled())
+	// 状态没有变化，不会通知
+	Enable(true)
+	Reset()
+	assert.False(t, IsEnabled())
+	assert.Equal(t, []bool{true, false}, changes)
+}
+
+func TestOnChangeUnregister(t *testing.T) {
+	defer Reset()
+	var first, second int
+	unregister := OnChange(func(bool) { first++ })
+	defer OnChange(func(bool) { second++ })()
+
+	Enable(true)
+	unregister()
+	// 重复调用不影响其他回调
+	unregister()
+	Enable(false)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
+
+func TestOverrideSurvivesConfigReload(t *testing.T) {
+	defer Reset()
+	assert.NoError(t, setConfig(&Config{Enable: true, Body: "maintenance"}))
+	assert.True(t, IsEnabled())
+
+	// 运行时关闭后，配置更新不会覆盖运行时状态
+	Enable(false)
+	assert.NoError(t, setConfig(&Config{Enable: true, Body: "maintenance"}))
+	assert.False(t, IsEnabled())
+
+	Reset()
+	assert.True(t, IsEnabled())
+	assert.NoError(t, setConfig(DefaultConfig()))
+	assert.False(t, IsEnabled())
+}
+
+func TestAllow(t *testing.T) {
+	defer func() {
+		assert.NoError(t, setConfig(DefaultConfig()))
+	}()
+	assert.NoError(t, setConfig(&Config{
+		AllowPaths:   []string{"/api/status", "/internal/*"},
+		AllowMethods: []string{"/grpc.health.v1.Health/*"},
+	}))
+	assert.True(t, AllowPath("/api/status"))
+	assert.False(t, AllowPath("/api/status/1"))
+	assert.True(t, AllowPath("/internal/users"))
+	assert.False(t, AllowPath("/api/users"))
+	assert.True(t, AllowMethod("/grpc.health.v1.Health/Check"))
+	assert.False(t, AllowMethod("/helloworld.Greeter/SayHello"))
+}
+
+func TestLoadAndRenderBody(t *testing.T) {
+	defer func() {
+		assert.NoError(t, setConfig(DefaultConfig()))
+	}()
+	conf := `
+[maintenance]
+enable = false
+retryAfter = "30s"
+body = "{{.Path}} retry after {{.RetryAfter}}"
+`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	Load("maintenance").Build()
+	assert.False(t, IsEnabled())
+	assert.Equal(t, "30s", RetryAfter().String())
+	assert.Equal(t, "/hello retry after 30s", string(RenderBody("/hello")))
+}
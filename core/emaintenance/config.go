@@ -0,0 +1,32 @@
+package emaintenance
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 维护模式配置
+type Config struct {
+	Enable       bool          // 是否开启维护模式，默认不开启
+	RetryAfter   time.Duration // 返回给客户端的Retry-After，默认60s
+	ContentType  string        // 维护模式响应的Content-Type，默认application/json
+	Body         string        // 维护模式响应的body模板，支持 {{.App}} {{.Path}} {{.RetryAfter}} 变量
+	AllowPaths   []string      // 维护模式下仍然允许访问的HTTP路由，以*结尾表示前缀匹配，例如 /api/status/*
+	AllowMethods []string      // 维护模式下仍然允许访问的gRPC方法，以*结尾表示前缀匹配，例如 /grpc.health.v1.Health/*
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enable:      false,
+		RetryAfter:  xtime.Duration("60s"),
+		ContentType: "application/json",
+		Body:        `{"code":503,"msg":"{{.App}} is under maintenance, please retry after {{.RetryAfter}}"}`,
+		AllowPaths:  []string{},
+		AllowMethods: []string{
+			"/grpc.health.v1.Health/*",
+			"/grpc.reflection.*",
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package emaintenance
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// Build 应用维护模式配置，并在配置热更新时重新加载
+// 通过 Enable 设置的运行时状态优先于配置，不会被配置热更新覆盖
+func (c *Container) Build(options ...Option) {
+	for _, option := range options {
+		option(c)
+	}
+	if err := setConfig(c.config); err != nil {
+		c.logger.Panic("build maintenance error", elog.FieldErr(err))
+	}
+	if c.name == "" {
+		return
+	}
+	econf.OnChange(func(newConf *econf.Configuration) {
+		config := DefaultConfig()
+		if err := newConf.UnmarshalKey(c.name, config); err != nil {
+			c.logger.Error("reload maintenance config fail", elog.FieldErr(err))
+			return
+		}
+		if err := setConfig(config); err != nil {
+			c.logger.Error("reload maintenance config fail", elog.FieldErr(err))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package emetric
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// ConfigReloadCollector 采集时读取 econf 热加载的统计，GitOps推送的配置长时间没有生效时可以告警，例如
+//
+//	ego_config_seconds_since_last_reload_success > 600 或者 increase(ego_config_reload_total{code="error"}[5m]) > 0
+var ConfigReloadCollector = newConfigCollector()
+
+func init() {
+	prometheus.MustRegister(ConfigReloadCollector)
+}
+
+type configCollector struct {
+	watchEvents      *prometheus.Desc
+	reloads          *prometheus.Desc
+	rebuilds         *prometheus.Desc
+	lastSuccess      *prometheus.Desc
+	sinceLastSuccess *prometheus.Desc
+	lastFailure      *prometheus.Desc
+}
+
+func newConfigCollector() *configCollector {
+	name := func(name string) string {
+		return prometheus.BuildFQName(DefaultNamespace, "config", name)
+	}
+	return &configCollector{
+		watchEvents:      prometheus.NewDesc(name("watch_events_total"), "config change events from data source", nil, nil),
+		reloads:          prometheus.NewDesc(name("reload_total"), "config reload attempts by result", []string{"code"}, nil),
+		rebuilds:         prometheus.NewDesc(name("rebuilds_total"), "component rebuild callbacks triggered by config reload", nil, nil),
+		lastSuccess:      prometheus.NewDesc(name("last_reload_success_timestamp_seconds"), "unix time of the last successful config load", nil, nil),
+		sinceLastSuccess: prometheus.NewDesc(name("seconds_since_last_reload_success"), "seconds since the last successful config load", nil, nil),
+		lastFailure:      prometheus.NewDesc(name("last_reload_failure_timestamp_seconds"), "unix time of the last failed config reload", nil, nil),
+	}
+}
+
+// Describe ...
+func (c *configCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.watchEvents
+	ch <- c.reloads
+	ch <- c.rebuilds
+	ch <- c.lastSuccess
+	ch <- c.sinceLastSuccess
+	ch <- c.lastFailure
+}
+
+// Collect 没有从数据源加载过配置时不输出时间相关的指标
+func (c *configCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := econf.GetReloadStats()
+	ch <- prometheus.MustNewConstMetric(c.watchEvents, prometheus.CounterValue, float64(stats.WatchEvents))
+	ch <- prometheus.MustNewConstMetric(c.reloads, prometheus.CounterValue, float64(stats.Successes), "ok")
+	ch <- prometheus.MustNewConstMetric(c.reloads, prometheus.CounterValue, float64(stats.Failures), "error")
+	ch <- prometheus.MustNewConstMetric(c.rebuilds, prometheus.CounterValue, float64(stats.Rebuilds))
+	if !stats.LastSuccess.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.lastSuccess, prometheus.GaugeValue, float64(stats.LastSuccess.Unix()))
+		ch <- prometheus.MustNewConstMetric(c.sinceLastSuccess, prometheus.GaugeValue, time.Since(stats.LastSuccess).Seconds())
+	}
+	if !stats.LastFailure.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.lastFailure, prometheus.GaugeValue, float64(stats.LastFailure.Unix()))
+	}
+}
@@ -0,0 +1,21 @@
+package emetric
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigReloadCollector(t *testing.T) {
+	// 没有从数据源加载配置时只输出计数
+	assert.Equal(t, 4, testutil.CollectAndCount(ConfigReloadCollector))
+	expected := `
+# HELP ego_config_reload_total config reload attempts by result
+# TYPE ego_config_reload_total counter
+ego_config_reload_total{code="error"} 0
+ego_config_reload_total{code="ok"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(ConfigReloadCollector, strings.NewReader(expected), "ego_config_reload_total"))
+}
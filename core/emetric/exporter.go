@@ -0,0 +1,36 @@
+package emetric
+
+import (
+	"sync"
+
+	"github.com/gotomicro/ego/core/eextension"
+)
+
+// Exporter 指标导出，框架启动时创建并启动，停止时关闭
+type Exporter interface {
+	Start() error
+	Close() error
+}
+
+// ExporterBuilder 根据配置key创建指标导出，key为 metricExporters.{name}
+type ExporterBuilder func(key string) (Exporter, error)
+
+var (
+	exporterMu sync.RWMutex
+	exporters  = make(map[string]ExporterBuilder)
+)
+
+// RegisterExporter 注册指标导出，例如推送到remote write，重复注册会覆盖
+func RegisterExporter(name string, builder ExporterBuilder) {
+	_ = eextension.Register(eextension.KindMetricExporter, name)
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	exporters[name] = builder
+}
+
+// GetExporter 返回指标导出，没有注册时返回nil
+func GetExporter(name string) ExporterBuilder {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporters[name]
+}
@@ -46,6 +46,11 @@ func (gv *GaugeVec) Inc(labels ...string) {
 	gv.WithLabelValues(labels...).Inc()
 }
 
+// Dec ...
+func (gv *GaugeVec) Dec(labels ...string) {
+	gv.WithLabelValues(labels...).Dec()
+}
+
 // Add ...
 func (gv *GaugeVec) Add(v float64, labels ...string) {
 	gv.WithLabelValues(labels...).Add(v)
@@ -0,0 +1,36 @@
+package emetric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// LoggerFallbackCollector 采集时读取日志文件无法创建、降级输出到stderr的次数
+var LoggerFallbackCollector = newLoggerCollector()
+
+func init() {
+	prometheus.MustRegister(LoggerFallbackCollector)
+}
+
+type loggerCollector struct {
+	fallbacks *prometheus.Desc
+}
+
+func newLoggerCollector() *loggerCollector {
+	return &loggerCollector{
+		fallbacks: prometheus.NewDesc(prometheus.BuildFQName(DefaultNamespace, "logger", "writer_fallback_total"), "log file writer fallback to stderr", []string{"name"}, nil),
+	}
+}
+
+// Describe ...
+func (c *loggerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.fallbacks
+}
+
+// Collect ...
+func (c *loggerCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, count := range elog.WriterFallbacks() {
+		ch <- prometheus.MustNewConstMetric(c.fallbacks, prometheus.CounterValue, float64(count), name)
+	}
+}
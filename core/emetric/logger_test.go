@@ -0,0 +1,12 @@
+package emetric
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerFallbackCollector(t *testing.T) {
+	assert.Equal(t, 0, testutil.CollectAndCount(LoggerFallbackCollector))
+}
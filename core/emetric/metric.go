@@ -19,6 +19,26 @@ var (
 	TypeGorm = "gorm"
 	// TypeWebsocket ...
 	TypeWebsocket = "ws"
+	// TypeOSS ...
+	TypeOSS = "oss"
+	// TypeES ...
+	TypeES = "es"
+	// TypeMongo ...
+	TypeMongo = "mongo"
+	// TypeClickHouse ...
+	TypeClickHouse = "clickhouse"
+	// TypeRabbitMQ ...
+	TypeRabbitMQ = "rabbitmq"
+	// TypeNATS ...
+	TypeNATS = "nats"
+	// TypeMQTT ...
+	TypeMQTT = "mqtt"
+	// TypeLock ...
+	TypeLock = "lock"
+	// TypeQuota ...
+	TypeQuota = "quota"
+	// TypePubSub ...
+	TypePubSub = "pubsub"
 	// TypeMySQL ...
 	TypeMySQL = "mysql"
 	// DefaultNamespace ...
@@ -42,6 +62,13 @@ var (
 		Labels:    []string{"type", "method", "peer", "rpc_service"},
 	}.Build()
 
+	// ServerInflightGauge ...
+	ServerInflightGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "server_inflight_requests",
+		Labels:    []string{"type", "method"},
+	}.Build()
+
 	// ServerHandleHistogram ...
 	ServerHandleHistogram = HistogramVecOpts{
 		Namespace: DefaultNamespace,
@@ -70,6 +97,13 @@ var (
 		Labels:    []string{"type", "name", "method", "peer"},
 	}.Build()
 
+	// ClientCollapsedCounter ...
+	ClientCollapsedCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "client_collapsed_total",
+		Labels:    []string{"type", "name", "method"},
+	}.Build()
+
 	// ClientStatsGauge ...
 	ClientStatsGauge = GaugeVecOpts{
 		Namespace: DefaultNamespace,
@@ -129,6 +163,209 @@ var (
 		Labels:    []string{"type", "name", "action"},
 	}.Build()
 
+	// RegistryKeepAliveFailureCounter ...
+	RegistryKeepAliveFailureCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "registry_keepalive_failures_total",
+		Labels:    []string{"name"},
+	}.Build()
+
+	// StreamSlowConsumerCounter ...
+	StreamSlowConsumerCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "stream_slow_consumer_total",
+		Labels:    []string{"type", "method", "peer", "action"},
+	}.Build()
+
+	// BudgetViolationCounter ...
+	BudgetViolationCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "budget_violations_total",
+		Labels:    []string{"name", "reason"},
+	}.Build()
+
+	// BulkheadRejectCounter ...
+	BulkheadRejectCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "bulkhead_rejected_total",
+		Labels:    []string{"name", "reason"},
+	}.Build()
+
+	// RetentionDeletedCounter ...
+	RetentionDeletedCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "retention_deleted_total",
+		Labels:    []string{"name", "target"},
+	}.Build()
+
+	// RetentionBatchCounter ...
+	RetentionBatchCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "retention_batches_total",
+		Labels:    []string{"name", "target", "code"},
+	}.Build()
+
+	// RetentionLastSuccessGauge ...
+	RetentionLastSuccessGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "retention_last_success_timestamp_seconds",
+		Labels:    []string{"name", "target"},
+	}.Build()
+
+	// PoolQueueGauge ...
+	PoolQueueGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "pool_queue_depth",
+		Labels:    []string{"name"},
+	}.Build()
+
+	// PoolWorkerGauge ...
+	PoolWorkerGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "pool_workers",
+		Labels:    []string{"name"},
+	}.Build()
+
+	// PoolRejectCounter ...
+	PoolRejectCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "pool_rejected_total",
+		Labels:    []string{"name", "reason"},
+	}.Build()
+
+	// PoolTaskHistogram ...
+	PoolTaskHistogram = HistogramVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "pool_task_seconds",
+		Labels:    []string{"name", "code"},
+	}.Build()
+
+	// BatchPendingGauge ...
+	BatchPendingGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "batch_pending_items",
+		Labels:    []string{"name"},
+	}.Build()
+
+	// BatchFlushCounter ...
+	BatchFlushCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "batch_flush_total",
+		Labels:    []string{"name", "code"},
+	}.Build()
+
+	// BatchFlushHistogram ...
+	BatchFlushHistogram = HistogramVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "batch_flush_seconds",
+		Labels:    []string{"name"},
+	}.Build()
+
+	// FlowTransitionCounter ...
+	FlowTransitionCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "flow_transitions_total",
+		Labels:    []string{"name", "workflow", "event", "code"},
+	}.Build()
+
+	// FlowStuckGauge ...
+	FlowStuckGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "flow_stuck_instances",
+		Labels:    []string{"name", "workflow", "state"},
+	}.Build()
+
+	// SagaCounter ...
+	SagaCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "saga_total",
+		Labels:    []string{"name", "saga", "status"},
+	}.Build()
+
+	// SagaCompensationCounter ...
+	SagaCompensationCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "saga_compensations_total",
+		Labels:    []string{"name", "saga", "step", "code"},
+	}.Build()
+
+	// TxnCounter ...
+	TxnCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "txn_total",
+		Labels:    []string{"name", "code"},
+	}.Build()
+
+	// TxnHookCounter ...
+	TxnHookCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "txn_hooks_total",
+		Labels:    []string{"name", "hook", "code"},
+	}.Build()
+
+	// AuthzDecisionCounter ...
+	AuthzDecisionCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "authz_decisions_total",
+		Labels:    []string{"kind", "resource", "decision"},
+	}.Build()
+
+	// IdentityExpiryGauge ...
+	IdentityExpiryGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "identity_svid_expiry_timestamp_seconds",
+		Labels:    []string{"name", "source"},
+	}.Build()
+
+	// IdentityRotateCounter ...
+	IdentityRotateCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "identity_svid_rotations_total",
+		Labels:    []string{"name", "source"},
+	}.Build()
+
+	// SecretRefreshCounter ...
+	SecretRefreshCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "secret_refresh_total",
+		Labels:    []string{"name", "result"},
+	}.Build()
+
+	// RetryCounter ...
+	RetryCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "retry_total",
+		Labels:    []string{"name", "result"},
+	}.Build()
+
+	// ResilienceCounter ...
+	ResilienceCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "resilience_total",
+		Labels:    []string{"name", "step"},
+	}.Build()
+
+	// DegradeCounter ...
+	DegradeCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "degrade_total",
+		Labels:    []string{"feature", "reason", "result"},
+	}.Build()
+
+	// GracefulUpgradeStateGauge ...
+	GracefulUpgradeStateGauge = GaugeVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "graceful_upgrade_state",
+		Labels:    []string{"state"},
+	}.Build()
+
+	// GracefulUpgradeCounter ...
+	GracefulUpgradeCounter = CounterVecOpts{
+		Namespace: DefaultNamespace,
+		Name:      "graceful_upgrade_total",
+		Labels:    []string{"result"},
+	}.Build()
+
 	// BuildInfoGauge ...
 	BuildInfoGauge = GaugeVecOpts{
 		Namespace: DefaultNamespace,
@@ -145,12 +382,13 @@ var (
 )
 
 func init() {
+	res := eapp.GetResource()
 	BuildInfoGauge.WithLabelValues(
-		eapp.Name(),
-		eapp.AppMode(),
-		eapp.AppRegion(),
-		eapp.AppZone(),
-		eapp.AppVersion(),
+		res.ServiceName,
+		res.Env,
+		res.Region,
+		res.Zone,
+		res.ServiceVersion,
 		eapp.EgoVersion(),
 		eapp.StartTime(),
 		eapp.BuildTime(),
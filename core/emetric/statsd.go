@@ -0,0 +1,282 @@
+package emetric
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+const (
+	// StatsdFlavorDogStatsd DogStatsD格式，标签通过 |#k:v 传递
+	StatsdFlavorDogStatsd = "dogstatsd"
+	// StatsdFlavorStatsd 原生StatsD格式，不支持标签，标签值拼接到指标名中
+	StatsdFlavorStatsd = "statsd"
+)
+
+// StatsdConfig StatsD导出配置
+type StatsdConfig struct {
+	Addr          string            // 地址，例如 udp://127.0.0.1:8125，unix:///var/run/datadog/dsd.socket
+	Flavor        string            // dogstatsd|statsd，默认 dogstatsd
+	Prefix        string            // 指标名前缀
+	FlushInterval time.Duration     // 上报间隔，默认 10s
+	MaxPacketSize int               // 单个数据包的最大字节数，默认UDP为1432，UDS为8192
+	Include       []string          // 需要上报的指标名前缀，默认 ego_
+	TagMapping    map[string]string // prometheus标签到StatsD标签的映射，映射为空字符串时丢弃该标签
+	Tags          map[string]string // 所有指标附加的标签，例如 env、service
+	// DisableResourceTags 禁止附加应用的资源标签 service、version、env、zone、instance
+	DisableResourceTags bool
+}
+
+// DefaultStatsdConfig 默认配置
+func DefaultStatsdConfig() *StatsdConfig {
+	return &StatsdConfig{
+		Addr:          "udp://127.0.0.1:8125",
+		Flavor:        StatsdFlavorDogStatsd,
+		FlushInterval: 10 * time.Second,
+		Include:       []string{DefaultNamespace + "_"},
+	}
+}
+
+// StatsdExporter 定时采集prometheus指标，通过StatsD/DogStatsD协议上报
+// counter、histogram、summary上报与上一次的差值，gauge上报当前值
+type StatsdExporter struct {
+	config   *StatsdConfig
+	gatherer prometheus.Gatherer
+	conn     net.Conn
+	mu       sync.Mutex
+	last     map[string]float64 // 上一次上报时的累计值
+	stop     chan struct{}
+	done     chan struct{}
+	started  bool
+	once     sync.Once
+}
+
+// NewStatsdExporter 创建StatsD导出，gatherer为nil时使用 prometheus.DefaultGatherer
+func NewStatsdExporter(config *StatsdConfig, gatherer prometheus.Gatherer) (*StatsdExporter, error) {
+	if gatherer == nil {
+		gatherer = prometheus.DefaultGatherer
+	}
+	network, address, ok := strings.Cut(config.Addr, "://")
+	if !ok {
+		network, address = "udp", config.Addr
+	}
+	if network == "unix" {
+		network = "unixgram"
+	}
+	if config.MaxPacketSize <= 0 {
+		config.MaxPacketSize = 1432
+		if network == "unixgram" {
+			config.MaxPacketSize = 8192
+		}
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if !config.DisableResourceTags {
+		config.Tags = withResourceTags(config.Tags, eapp.GetResource())
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("statsd dial fail, %w", err)
+	}
+	return &StatsdExporter{
+		config:   config,
+		gatherer: gatherer,
+		conn:     conn,
+		last:     make(map[string]float64),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start 按照FlushInterval定时上报
+func (e *StatsdExporter) Start() {
+	e.started = true
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.config.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				_ = e.Flush()
+			}
+		}
+	}()
+}
+
+// Close 停止定时上报，上报最后一次数据后关闭连接
+func (e *StatsdExporter) Close() error {
+	var err error
+	e.once.Do(func() {
+		close(e.stop)
+		if e.started {
+			<-e.done
+		}
+		err = e.Flush()
+		if closeErr := e.conn.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// Flush 立即采集并上报一次
+func (e *StatsdExporter) Flush() error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var (
+		packet bytes.Buffer
+		errs   []error
+	)
+	write := func(line string) {
+		if packet.Len() > 0 && packet.Len()+len(line)+1 > e.config.MaxPacketSize {
+			if _, err := e.conn.Write(packet.Bytes()); err != nil {
+				errs = append(errs, err)
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	for _, family := range families {
+		if !e.include(family.GetName()) {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			name, tags := e.nameAndTags(family.GetName(), m.GetLabel())
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				e.writeDelta(write, name, tags, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				write(e.line(name, m.GetGauge().GetValue(), "g", tags))
+			case dto.MetricType_UNTYPED:
+				write(e.line(name, m.GetUntyped().GetValue(), "g", tags))
+			case dto.MetricType_HISTOGRAM:
+				e.writeDelta(write, name+".count", tags, float64(m.GetHistogram().GetSampleCount()))
+				e.writeDelta(write, name+".sum", tags, m.GetHistogram().GetSampleSum())
+			case dto.MetricType_SUMMARY:
+				e.writeDelta(write, name+".count", tags, float64(m.GetSummary().GetSampleCount()))
+				e.writeDelta(write, name+".sum", tags, m.GetSummary().GetSampleSum())
+			}
+		}
+	}
+	if packet.Len() > 0 {
+		if _, err := e.conn.Write(packet.Bytes()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("statsd write fail, %w", errs[0])
+	}
+	return nil
+}
+
+func (e *StatsdExporter) include(name string) bool {
+	if len(e.config.Include) == 0 {
+		return true
+	}
+	for _, prefix := range e.config.Include {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeDelta 上报与上一次累计值的差值，累计值变小说明进程内指标被重置，直接上报当前值
+func (e *StatsdExporter) writeDelta(write func(string), name string, tags []string, value float64) {
+	key := name + "|" + strings.Join(tags, ",")
+	delta := value - e.last[key]
+	if delta < 0 {
+		delta = value
+	}
+	e.last[key] = value
+	if delta == 0 {
+		return
+	}
+	write(e.line(name, delta, "c", tags))
+}
+
+// nameAndTags 按照TagMapping转换标签，statsd格式将标签值拼接到指标名中
+func (e *StatsdExporter) nameAndTags(name string, labels []*dto.LabelPair) (string, []string) {
+	name = e.config.Prefix + name
+	tags := make([]string, 0, len(labels)+len(e.config.Tags))
+	for _, label := range labels {
+		tagName := label.GetName()
+		if mapped, ok := e.config.TagMapping[tagName]; ok {
+			if mapped == "" {
+				continue
+			}
+			tagName = mapped
+		}
+		if label.GetValue() == "" {
+			continue
+		}
+		if e.config.Flavor == StatsdFlavorStatsd {
+			name += "." + sanitizeStatsd(label.GetValue())
+			continue
+		}
+		tags = append(tags, tagName+":"+sanitizeStatsd(label.GetValue()))
+	}
+	if e.config.Flavor != StatsdFlavorStatsd {
+		for k, v := range e.config.Tags {
+			tags = append(tags, k+":"+sanitizeStatsd(v))
+		}
+	}
+	sort.Strings(tags)
+	return name, tags
+}
+
+// withResourceTags 附加资源标签，标签名与DogStatsD的统一服务标签一致，用户配置的标签优先
+func withResourceTags(tags map[string]string, res eapp.Resource) map[string]string {
+	out := map[string]string{
+		"service":  res.ServiceName,
+		"version":  res.ServiceVersion,
+		"env":      res.Env,
+		"zone":     res.Zone,
+		"instance": res.InstanceID,
+	}
+	for k, v := range out {
+		if v == "" {
+			delete(out, k)
+		}
+	}
+	for k, v := range tags {
+		out[k] = v
+	}
+	return out
+}
+
+func (e *StatsdExporter) line(name string, value float64, typ string, tags []string) string {
+	line := name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + typ
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+// statsdReplacer 替换StatsD协议中的保留字符
+var statsdReplacer = strings.NewReplacer(":", "_", "|", "_", ",", "_", "#", "_", "\n", "_", "@", "_")
+
+func sanitizeStatsd(s string) string {
+	return statsdReplacer.Replace(s)
+}
@@ -0,0 +1,91 @@
+package emetric
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+func readStatsd(t *testing.T, conn net.PacketConn) []string {
+	buf := make([]byte, 8192)
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	lines := strings.Split(string(buf[:n]), "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestStatsdExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ego_test_total"}, []string{"type", "peer"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "ego_test_gauge"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "ego_test_seconds"})
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "other_gauge"})
+	registry.MustRegister(counter, gauge, histogram, other)
+
+	config := DefaultStatsdConfig()
+	config.Addr = "udp://" + conn.LocalAddr().String()
+	config.TagMapping = map[string]string{"type": "kind", "peer": ""}
+	config.Tags = map[string]string{"env": "prod"}
+	config.DisableResourceTags = true
+	exporter, err := NewStatsdExporter(config, registry)
+	assert.NoError(t, err)
+
+	counter.WithLabelValues("http", "127.0.0.1").Add(3)
+	gauge.Set(5)
+	histogram.Observe(0.5)
+	assert.NoError(t, exporter.Flush())
+	assert.Equal(t, []string{
+		"ego_test_gauge:5|g|#env:prod",
+		"ego_test_seconds.count:1|c|#env:prod",
+		"ego_test_seconds.sum:0.5|c|#env:prod",
+		"ego_test_total:3|c|#env:prod,kind:http",
+	}, readStatsd(t, conn))
+
+	// counter只上报差值
+	counter.WithLabelValues("http", "127.0.0.1").Add(2)
+	assert.NoError(t, exporter.Close())
+	assert.Equal(t, []string{
+		"ego_test_gauge:5|g|#env:prod",
+		"ego_test_total:2|c|#env:prod,kind:http",
+	}, readStatsd(t, conn))
+}
+
+func TestStatsdExporterFlavorStatsd(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ego_test_total"}, []string{"type"})
+	registry.MustRegister(counter)
+	counter.WithLabelValues("grpc:unary").Inc()
+
+	config := DefaultStatsdConfig()
+	config.Addr = conn.LocalAddr().String()
+	config.Flavor = StatsdFlavorStatsd
+	config.Prefix = "app."
+	exporter, err := NewStatsdExporter(config, registry)
+	assert.NoError(t, err)
+	exporter.Start()
+	assert.NoError(t, exporter.Close())
+	assert.Equal(t, []string{"app.ego_test_total.grpc_unary:1|c"}, readStatsd(t, conn))
+}
+
+func TestWithResourceTags(t *testing.T) {
+	res := eapp.Resource{ServiceName: "svc", ServiceVersion: "v1", Env: "dev"}
+	tags := withResourceTags(map[string]string{"env": "prod"}, res)
+	assert.Equal(t, map[string]string{"service": "svc", "version": "v1", "env": "prod"}, tags)
+}
@@ -0,0 +1,225 @@
+package epaging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PackageName 包名
+const PackageName = "core.epaging"
+
+const (
+	// ParamLimit 每页条数的查询参数
+	ParamLimit = "limit"
+	// ParamCursor 游标的查询参数
+	ParamCursor = "cursor"
+	// ParamWithTotal 是否返回总数的查询参数
+	ParamWithTotal = "withTotal"
+)
+
+// TotalPolicy 总数的计算策略，count通常比查询更慢，大表应该避免每页都计算
+type TotalPolicy string
+
+const (
+	// TotalAlways 每页都返回总数
+	TotalAlways TotalPolicy = "always"
+	// TotalFirstPage 只在第一页返回总数
+	TotalFirstPage TotalPolicy = "firstPage"
+	// TotalOnDemand 请求携带 withTotal=true 时返回总数
+	TotalOnDemand TotalPolicy = "onDemand"
+	// TotalNever 不返回总数
+	TotalNever TotalPolicy = "never"
+)
+
+var (
+	// ErrInvalidLimit limit参数不合法
+	ErrInvalidLimit = errors.New("epaging: invalid limit")
+	// ErrInvalidCursor 游标不合法或者被篡改
+	ErrInvalidCursor = errors.New("epaging: invalid cursor")
+)
+
+// Paginator 统一列表接口的分页参数解析、条数限制和游标编解码
+type Paginator struct {
+	defaultLimit int
+	maxLimit     int
+	totalPolicy  TotalPolicy
+	secret       []byte
+}
+
+// Option 可选项
+type Option func(p *Paginator)
+
+// WithDefaultLimit 没有传limit时的每页条数，默认20
+func WithDefaultLimit(limit int) Option {
+	return func(p *Paginator) {
+		p.defaultLimit = limit
+	}
+}
+
+// WithMaxLimit 每页最大条数，超过时截断，默认100
+func WithMaxLimit(limit int) Option {
+	return func(p *Paginator) {
+		p.maxLimit = limit
+	}
+}
+
+// WithTotalPolicy 总数的计算策略，默认 TotalFirstPage
+func WithTotalPolicy(policy TotalPolicy) Option {
+	return func(p *Paginator) {
+		p.totalPolicy = policy
+	}
+}
+
+// WithSecret 游标签名的密钥，设置后客户端无法伪造或修改游标
+func WithSecret(secret string) Option {
+	return func(p *Paginator) {
+		p.secret = []byte(secret)
+	}
+}
+
+// New 创建Paginator
+func New(options ...Option) *Paginator {
+	p := &Paginator{
+		defaultLimit: 20,
+		maxLimit:     100,
+		totalPolicy:  TotalFirstPage,
+	}
+	for _, option := range options {
+		option(p)
+	}
+	if p.defaultLimit > p.maxLimit {
+		p.defaultLimit = p.maxLimit
+	}
+	return p
+}
+
+// Request 分页请求
+type Request struct {
+	Limit     int    // 已经按照最大条数截断
+	Cursor    string // 客户端传入的游标，第一页为空
+	WithTotal bool   // 按照总数策略是否需要计算总数
+}
+
+// IsFirstPage 是否为第一页
+func (r Request) IsFirstPage() bool {
+	return r.Cursor == ""
+}
+
+// Page 分页结果，通过 HasMore 和 NextCursor 翻页
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// ClampLimit 限制每页条数，小于等于0时使用默认值
+func (p *Paginator) ClampLimit(limit int) int {
+	if limit <= 0 {
+		return p.defaultLimit
+	}
+	if limit > p.maxLimit {
+		return p.maxLimit
+	}
+	return limit
+}
+
+// Parse 从查询参数中解析分页请求
+func (p *Paginator) Parse(values url.Values) (Request, error) {
+	req := Request{Cursor: values.Get(ParamCursor)}
+	limit := 0
+	if s := values.Get(ParamLimit); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+			return req, ErrInvalidLimit
+		}
+	}
+	req.Limit = p.ClampLimit(limit)
+	switch p.totalPolicy {
+	case TotalAlways:
+		req.WithTotal = true
+	case TotalFirstPage:
+		req.WithTotal = req.IsFirstPage()
+	case TotalOnDemand:
+		req.WithTotal, _ = strconv.ParseBool(values.Get(ParamWithTotal))
+	}
+	return req, nil
+}
+
+// EncodeCursor 将游标数据编码为不透明的字符串，例如最后一条记录的排序字段
+func (p *Paginator) EncodeCursor(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	if len(p.secret) > 0 {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(p.sign(data))
+	}
+	return cursor, nil
+}
+
+// DecodeCursor 解码游标到v，游标不合法时返回 ErrInvalidCursor
+func (p *Paginator) DecodeCursor(cursor string, v interface{}) error {
+	payload, signature, signed := strings.Cut(cursor, ".")
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if len(p.secret) > 0 {
+		sig, err := base64.RawURLEncoding.DecodeString(signature)
+		if !signed || err != nil || !hmac.Equal(sig, p.sign(data)) {
+			return ErrInvalidCursor
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (p *Paginator) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// NewPage 根据查询结果构造分页结果
+// items应该多查询一条（limit+1）用于判断是否还有下一页，next返回最后一条记录的游标数据
+func NewPage[T any](p *Paginator, req Request, items []T, next func(last T) interface{}) (Page[T], error) {
+	page := Page[T]{Items: items}
+	if len(items) > req.Limit {
+		page.Items = items[:req.Limit]
+		page.HasMore = true
+	}
+	if page.HasMore && len(page.Items) > 0 && next != nil {
+		cursor, err := p.EncodeCursor(next(page.Items[len(page.Items)-1]))
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = cursor
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page, nil
+}
+
+// SetTotal 按照请求设置总数，不需要总数时不会调用count
+func (page *Page[T]) SetTotal(req Request, count func() (int64, error)) error {
+	if !req.WithTotal {
+		return nil
+	}
+	total, err := count()
+	if err != nil {
+		return err
+	}
+	page.Total = &total
+	return nil
+}
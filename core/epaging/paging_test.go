@@ -0,0 +1,65 @@
+package epaging
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	p := New(WithDefaultLimit(10), WithMaxLimit(50))
+	req, err := p.Parse(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, Request{Limit: 10, WithTotal: true}, req)
+
+	req, err = p.Parse(url.Values{ParamLimit: {"500"}, ParamCursor: {"abc"}})
+	assert.NoError(t, err)
+	assert.Equal(t, Request{Limit: 50, Cursor: "abc"}, req)
+
+	_, err = p.Parse(url.Values{ParamLimit: {"-1"}})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+
+	p = New(WithTotalPolicy(TotalOnDemand))
+	req, _ = p.Parse(url.Values{ParamWithTotal: {"true"}, ParamCursor: {"abc"}})
+	assert.True(t, req.WithTotal)
+}
+
+func TestCursor(t *testing.T) {
+	type cursor struct {
+		ID int64 `json:"id"`
+	}
+	p := New(WithSecret("secret"))
+	s, err := p.EncodeCursor(cursor{ID: 42})
+	assert.NoError(t, err)
+	var out cursor
+	assert.NoError(t, p.DecodeCursor(s, &out))
+	assert.Equal(t, int64(42), out.ID)
+
+	// 篡改或者未签名的游标
+	unsigned, _ := New().EncodeCursor(cursor{ID: 43})
+	assert.ErrorIs(t, p.DecodeCursor(unsigned, &out), ErrInvalidCursor)
+	assert.ErrorIs(t, p.DecodeCursor(unsigned+s[len(unsigned)-1:], &out), ErrInvalidCursor)
+	assert.ErrorIs(t, New().DecodeCursor("!!", &out), ErrInvalidCursor)
+}
+
+func TestNewPage(t *testing.T) {
+	p := New()
+	req := Request{Limit: 2, WithTotal: true}
+	page, err := NewPage(p, req, []int{1, 2, 3}, func(last int) interface{} { return last })
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, page.Items)
+	assert.True(t, page.HasMore)
+	var last int
+	assert.NoError(t, p.DecodeCursor(page.NextCursor, &last))
+	assert.Equal(t, 2, last)
+	assert.NoError(t, page.SetTotal(req, func() (int64, error) { return 3, nil }))
+	assert.Equal(t, int64(3), *page.Total)
+
+	page, err = NewPage[int](p, Request{Limit: 2}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{}, page.Items)
+	assert.False(t, page.HasMore)
+	assert.NoError(t, page.SetTotal(Request{}, func() (int64, error) { panic("should not count") }))
+	assert.Nil(t, page.Total)
+}
@@ -0,0 +1,279 @@
+package epool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.epool"
+
+const (
+	// ReasonQueueFull 队列已满
+	ReasonQueueFull = "queue_full"
+	// ReasonCanceled 等待入队时ctx被取消
+	ReasonCanceled = "canceled"
+	// ReasonClosed 协程池已经关闭
+	ReasonClosed = "closed"
+)
+
+var (
+	// ErrFull 队列已满
+	ErrFull = errors.New("epool: queue full")
+	// ErrClosed 协程池已经关闭
+	ErrClosed = errors.New("epool: closed")
+)
+
+// Task 任务，ctx在协程池关闭超时后取消，与提交任务时的ctx无关
+type Task func(ctx context.Context)
+
+type task struct {
+	fn       Task
+	enqueued time.Time
+}
+
+// Component 固定worker数的协程池，替代业务中不受控的 go func()
+type Component struct {
+	name    string
+	config  *Config
+	logger  *elog.Component
+	queue   chan task
+	quit    chan struct{} // 缩容时通知空闲的worker退出
+	closing chan struct{} // 开始关闭，拒绝新的任务
+	drain   chan struct{} // 所有正在提交的任务已经入队，worker执行完队列中的任务后退出
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	submitMu  sync.RWMutex // 关闭时等待正在提交的任务入队
+	resizeMu  sync.Mutex
+	closeOnce sync.Once
+	size      int // 期望的worker数
+	workers   int64
+	running   int64
+	completed int64
+	wg        sync.WaitGroup
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) *Component {
+	c := &Component{
+		name:    name,
+		config:  config,
+		logger:  logger,
+		queue:   make(chan task, config.QueueSize),
+		quit:    make(chan struct{}),
+		closing: make(chan struct{}),
+		drain:   make(chan struct{}),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.Resize(config.Size)
+	return c
+}
+
+// Name 名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// Size 期望的worker数
+func (c *Component) Size() int {
+	c.resizeMu.Lock()
+	defer c.resizeMu.Unlock()
+	return c.size
+}
+
+// Workers 当前的worker数，缩容时空闲的worker退出后才会减少
+func (c *Component) Workers() int {
+	return int(atomic.LoadInt64(&c.workers))
+}
+
+// Running 正在执行的任务数
+func (c *Component) Running() int {
+	return int(atomic.LoadInt64(&c.running))
+}
+
+// Waiting 队列中等待执行的任务数
+func (c *Component) Waiting() int {
+	return len(c.queue)
+}
+
+// Submit 提交任务，队列满时阻塞，直到入队、ctx取消或者协程池关闭
+func (c *Component) Submit(ctx context.Context, fn Task) error {
+	c.submitMu.RLock()
+	defer c.submitMu.RUnlock()
+	select {
+	case <-c.closing:
+		c.reject(ReasonClosed)
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.queue <- task{fn: fn, enqueued: time.Now()}:
+		c.observeQueue()
+		return nil
+	case <-ctx.Done():
+		c.reject(ReasonCanceled)
+		return ctx.Err()
+	case <-c.closing:
+		c.reject(ReasonClosed)
+		return ErrClosed
+	}
+}
+
+// TrySubmit 提交任务，队列满时直接返回ErrFull
+func (c *Component) TrySubmit(fn Task) error {
+	c.submitMu.RLock()
+	defer c.submitMu.RUnlock()
+	select {
+	case <-c.closing:
+		c.reject(ReasonClosed)
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.queue <- task{fn: fn, enqueued: time.Now()}:
+		c.observeQueue()
+		return nil
+	default:
+		c.reject(ReasonQueueFull)
+		return ErrFull
+	}
+}
+
+// Resize 调整worker数，扩容立即生效，缩容时空闲的worker依次退出
+func (c *Component) Resize(size int) {
+	if size <= 0 {
+		size = 1
+	}
+	c.resizeMu.Lock()
+	defer c.resizeMu.Unlock()
+	select {
+	case <-c.closing:
+		return
+	default:
+	}
+	diff := size - c.size
+	c.size = size
+	for i := 0; i < diff; i++ {
+		c.wg.Add(1)
+		atomic.AddInt64(&c.workers, 1)
+		go c.worker()
+	}
+	if diff < 0 {
+		go func(n int) {
+			for i := 0; i < n; i++ {
+				select {
+				case c.quit <- struct{}{}:
+				case <-c.drain:
+					return
+				}
+			}
+		}(-diff)
+	}
+	if c.config.EnableMetric {
+		emetric.PoolWorkerGauge.Set(float64(size), c.name)
+	}
+}
+
+func (c *Component) worker() {
+	defer func() {
+		atomic.AddInt64(&c.workers, -1)
+		c.wg.Done()
+	}()
+	for {
+		select {
+		case t := <-c.queue:
+			c.run(t)
+		case <-c.quit:
+			return
+		case <-c.drain:
+			for {
+				select {
+				case t := <-c.queue:
+					c.run(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// run 执行任务，panic时记录日志，不影响worker
+func (c *Component) run(t task) {
+	c.observeQueue()
+	atomic.AddInt64(&c.running, 1)
+	beg := time.Now()
+	code := "OK"
+	defer func() {
+		if rec := recover(); rec != nil {
+			code = "Panic"
+			stack := make([]byte, 4096)
+			length := runtime.Stack(stack, false)
+			c.logger.Error("pool task panic", elog.FieldErr(fmt.Errorf("%v", rec)), elog.ByteString("stack", stack[:length]))
+		}
+		atomic.AddInt64(&c.running, -1)
+		atomic.AddInt64(&c.completed, 1)
+		if c.config.EnableMetric {
+			emetric.PoolTaskHistogram.Observe(time.Since(beg).Seconds(), c.name, code)
+		}
+	}()
+	t.fn(c.ctx)
+}
+
+// Close 拒绝新的任务，等待队列中的任务执行完，ctx超时后丢弃剩余的任务并取消任务的ctx
+// 返回关闭期间执行完的任务数和丢弃的任务数，可以多次调用
+func (c *Component) Close(ctx context.Context) (flushed int, dropped int, err error) {
+	start := atomic.LoadInt64(&c.completed)
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		// 等待正在提交的任务入队后，再通知worker退出
+		c.submitMu.Lock()
+		close(c.drain)
+		c.submitMu.Unlock()
+	})
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		for {
+			select {
+			case <-c.queue:
+				dropped++
+				continue
+			default:
+			}
+			break
+		}
+		err = fmt.Errorf("epool %s close, err: %w", c.name, context.Cause(ctx))
+	}
+	c.cancel()
+	c.observeQueue()
+	if dropped > 0 {
+		c.logger.Warn("pool close drop tasks", elog.Int("dropped", dropped))
+	}
+	return int(atomic.LoadInt64(&c.completed) - start), dropped, err
+}
+
+func (c *Component) reject(reason string) {
+	if c.config.EnableMetric {
+		emetric.PoolRejectCounter.Inc(c.name, reason)
+	}
+}
+
+func (c *Component) observeQueue() {
+	if c.config.EnableMetric {
+		emetric.PoolQueueGauge.Set(float64(len(c.queue)), c.name)
+	}
+}
@@ -0,0 +1,114 @@
+package epool
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+func TestSubmit(t *testing.T) {
+	pool := DefaultContainer().Build(WithName("submit"), WithSize(4))
+	var count int64
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) {
+			atomic.AddInt64(&count, 1)
+		}))
+	}
+	// 任务panic不影响worker
+	assert.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) {
+		panic("boom")
+	}))
+	flushed, dropped, err := pool.Close(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+	assert.LessOrEqual(t, flushed, 101)
+	assert.Equal(t, int64(100), atomic.LoadInt64(&count))
+	assert.Equal(t, 0, pool.Workers())
+	assert.ErrorIs(t, pool.Submit(context.Background(), func(ctx context.Context) {}), ErrClosed)
+}
+
+func TestTrySubmitFull(t *testing.T) {
+	pool := DefaultContainer().Build(WithName("full"), WithSize(1), WithQueueSize(1))
+	block := make(chan struct{})
+	started := make(chan struct{})
+	assert.NoError(t, pool.TrySubmit(func(ctx context.Context) {
+		close(started)
+		<-block
+	}))
+	<-started
+	assert.NoError(t, pool.TrySubmit(func(ctx context.Context) {}))
+	before := testutil.ToFloat64(emetric.PoolRejectCounter.WithLabelValues("full", ReasonQueueFull))
+	assert.ErrorIs(t, pool.TrySubmit(func(ctx context.Context) {}), ErrFull)
+	assert.Equal(t, before+1, testutil.ToFloat64(emetric.PoolRejectCounter.WithLabelValues("full", ReasonQueueFull)))
+	assert.Equal(t, 1, pool.Waiting())
+	assert.Equal(t, 1, pool.Running())
+
+	// 队列满时Submit阻塞，直到ctx取消
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Submit(ctx, func(ctx context.Context) {}), context.DeadlineExceeded)
+	close(block)
+	_, _, err := pool.Close(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestCloseTimeout(t *testing.T) {
+	pool := DefaultContainer().Build(WithName("timeout"), WithSize(1), WithQueueSize(10))
+	var canceled int32
+	assert.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		atomic.StoreInt32(&canceled, 1)
+	}))
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, pool.Submit(context.Background(), func(ctx context.Context) {}))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, dropped, err := pool.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 5, dropped)
+	// 超时后取消任务的ctx
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&canceled) == 1 }, time.Second, time.Millisecond)
+}
+
+func TestResize(t *testing.T) {
+	pool := DefaultContainer().Build(WithName("resize"), WithSize(2))
+	defer pool.Close(context.Background())
+	pool.Resize(5)
+	assert.Equal(t, 5, pool.Size())
+	assert.Equal(t, 5, pool.Workers())
+	pool.Resize(1)
+	assert.Eventually(t, func() bool { return pool.Workers() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(emetric.PoolWorkerGauge.WithLabelValues("resize")))
+}
+
+func TestReload(t *testing.T) {
+	conf := `
+[pool]
+size = 2
+queueSize = 10
+`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	c := Load("pool")
+	pool := c.Build()
+	defer pool.Close(context.Background())
+	assert.Equal(t, 2, pool.Size())
+
+	newConf := econf.New()
+	assert.NoError(t, newConf.LoadFromReader(strings.NewReader(`
+[pool]
+size = 4
+`), toml.Unmarshal))
+	c.reload(pool, newConf)
+	assert.Equal(t, 4, pool.Size())
+	assert.Equal(t, 4, pool.Workers())
+}
@@ -0,0 +1,19 @@
+package epool
+
+// Config 协程池配置
+type Config struct {
+	Size         int  // 并发执行的worker数，支持热加载，默认10
+	QueueSize    int  // 等待队列的长度，Submit在队列满时阻塞，TrySubmit在队列满时返回ErrFull，默认1000
+	EnableDrain  bool // ego停止时是否等待队列中的任务执行完，超时后丢弃剩余任务，默认开启
+	EnableMetric bool // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Size:         10,
+		QueueSize:    1000,
+		EnableDrain:  true,
+		EnableMetric: true,
+	}
+}
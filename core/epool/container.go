@@ -0,0 +1,77 @@
+package epool
+
+import (
+	"context"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Container 容器
+type Container struct {
+	config *Config
+	key    string // 配置key，为空时不支持热加载
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.key = key
+	c.name = key
+	return c
+}
+
+// Build 构建协程池，配置热更新时调整worker数
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.config.Size <= 0 {
+		c.config.Size = 1
+	}
+	if c.config.QueueSize < 0 {
+		c.config.QueueSize = 0
+	}
+	comp := newComponent(c.name, c.config, c.logger)
+	if c.config.EnableDrain {
+		ehooks.RegisterFlush(PackageName+"."+c.name, func(ctx context.Context) (int, int, error) {
+			return comp.Close(ctx)
+		})
+	}
+	if c.key == "" {
+		return comp
+	}
+	econf.OnChange(func(newConf *econf.Configuration) {
+		c.reload(comp, newConf)
+	})
+	return comp
+}
+
+// reload 重新读取worker数，队列长度不支持热加载
+func (c *Container) reload(comp *Component, newConf *econf.Configuration) {
+	config := DefaultConfig()
+	if err := newConf.UnmarshalKey(c.key, config); err != nil {
+		c.logger.Error("reload pool config fail", elog.FieldErr(err))
+		return
+	}
+	if config.Size > 0 && config.Size != comp.Size() {
+		c.logger.Info("resize pool", elog.Int("from", comp.Size()), elog.Int("to", config.Size))
+		comp.Resize(config.Size)
+	}
+}
@@ -0,0 +1,25 @@
+package epool
+
+// Option 可选项
+type Option func(c *Container)
+
+// WithName 设置名称，用于日志和监控
+func WithName(name string) Option {
+	return func(c *Container) {
+		c.name = name
+	}
+}
+
+// WithSize 设置worker数
+func WithSize(size int) Option {
+	return func(c *Container) {
+		c.config.Size = size
+	}
+}
+
+// WithQueueSize 设置等待队列的长度
+func WithQueueSize(size int) Option {
+	return func(c *Container) {
+		c.config.QueueSize = size
+	}
+}
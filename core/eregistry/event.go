@@ -0,0 +1,41 @@
+package eregistry
+
+import "context"
+
+// EventType 注册中心事件类型
+type EventType int
+
+const (
+	// EventDisconnected 与注册中心的连接断开
+	EventDisconnected EventType = iota + 1
+	// EventReconnected 与注册中心的连接恢复，之前注册的服务可能已经丢失
+	EventReconnected
+	// EventKeepAliveFailed 租约续期失败，每次失败上报一次
+	EventKeepAliveFailed
+)
+
+// String ...
+func (t EventType) String() string {
+	switch t {
+	case EventDisconnected:
+		return "disconnected"
+	case EventReconnected:
+		return "reconnected"
+	case EventKeepAliveFailed:
+		return "keepAliveFailed"
+	}
+	return "unknown"
+}
+
+// Event 注册中心事件
+type Event struct {
+	Type EventType
+	Err  error // 断开或者续期失败的原因
+}
+
+// EventNotifier 注册中心可选实现的接口
+// 实现后，框架会在连接恢复时自动重新注册服务，并在续期持续失败时重新注册
+type EventNotifier interface {
+	// Events 返回事件channel，ctx结束后实现方需要关闭该channel
+	Events(ctx context.Context) <-chan Event
+}
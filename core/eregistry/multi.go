@@ -0,0 +1,232 @@
+package eregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/server"
+)
+
+// Policy 部分注册中心失败时的处理策略
+type Policy string
+
+const (
+	// PolicyAll 所有注册中心都成功才算注册成功
+	PolicyAll Policy = "all"
+	// PolicyAny 任意一个注册中心成功即算注册成功，常用于注册中心迁移
+	PolicyAny Policy = "any"
+)
+
+// Status 单个注册中心的注册状态
+type Status struct {
+	Name       string    `json:"name"`
+	Registered int       `json:"registered"` // 当前注册成功的服务数
+	LastError  string    `json:"lastError"`  // 最近一次失败的原因，成功后清空
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// MultiOption 多注册中心选项
+type MultiOption func(m *Multi)
+
+// WithRegistry 添加注册中心，第一个添加的注册中心用于服务发现
+func WithRegistry(name string, reg Registry) MultiOption {
+	return func(m *Multi) {
+		m.registries = append(m.registries, namedRegistry{name: name, Registry: reg})
+	}
+}
+
+// WithPolicy 设置部分失败时的处理策略，默认 PolicyAll
+func WithPolicy(policy Policy) MultiOption {
+	return func(m *Multi) {
+		m.policy = policy
+	}
+}
+
+type namedRegistry struct {
+	name string
+	Registry
+}
+
+// Multi 将服务同时注册到多个注册中心，例如从etcd迁移到k8s期间同时注册
+// 服务注册、注销、同步会分发到所有注册中心，服务发现只使用第一个注册中心
+type Multi struct {
+	registries []namedRegistry
+	policy     Policy
+	mu         sync.RWMutex
+	services   map[string]map[string]struct{} // 每个注册中心注册成功的服务key
+	lastErrors map[string]error
+	updateTime map[string]time.Time
+}
+
+// NewMulti 创建多注册中心
+func NewMulti(options ...MultiOption) *Multi {
+	m := &Multi{
+		policy:     PolicyAll,
+		services:   make(map[string]map[string]struct{}),
+		lastErrors: make(map[string]error),
+		updateTime: make(map[string]time.Time),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	for _, reg := range m.registries {
+		m.services[reg.name] = make(map[string]struct{})
+	}
+	return m
+}
+
+// RegisterService 注册到所有注册中心，按照Policy判断是否成功
+func (m *Multi) RegisterService(ctx context.Context, info *server.ServiceInfo) error {
+	errs := m.each(func(reg namedRegistry) error {
+		err := reg.RegisterService(ctx, info)
+		m.record(reg.name, info, err == nil, err)
+		return err
+	})
+	return m.result("register", errs)
+}
+
+// UnregisterService 从所有注册中心注销
+func (m *Multi) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	errs := m.each(func(reg namedRegistry) error {
+		err := reg.UnregisterService(ctx, info)
+		m.record(reg.name, info, false, err)
+		return err
+	})
+	return joinErrors("unregister", errs)
+}
+
+// ListServices 使用第一个注册中心
+func (m *Multi) ListServices(ctx context.Context, target Target) ([]*server.ServiceInfo, error) {
+	if len(m.registries) == 0 {
+		return nil, errors.New("no registry")
+	}
+	return m.registries[0].ListServices(ctx, target)
+}
+
+// WatchServices 使用第一个注册中心
+func (m *Multi) WatchServices(ctx context.Context, target Target) (chan Endpoints, error) {
+	if len(m.registries) == 0 {
+		return nil, errors.New("no registry")
+	}
+	return m.registries[0].WatchServices(ctx, target)
+}
+
+// SyncServices 同步所有注册中心
+func (m *Multi) SyncServices(ctx context.Context, options SyncServicesOptions) error {
+	errs := m.each(func(reg namedRegistry) error {
+		return reg.SyncServices(ctx, options)
+	})
+	return joinErrors("sync", errs)
+}
+
+// Close 关闭所有注册中心
+func (m *Multi) Close() error {
+	errs := m.each(func(reg namedRegistry) error {
+		return reg.Close()
+	})
+	return joinErrors("close", errs)
+}
+
+// Events 合并所有注册中心的事件
+func (m *Multi) Events(ctx context.Context) <-chan Event {
+	out := make(chan Event)
+	var wg sync.WaitGroup
+	for _, reg := range m.registries {
+		notifier, ok := reg.Registry.(EventNotifier)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, events <-chan Event) {
+			defer wg.Done()
+			for event := range events {
+				if event.Err != nil {
+					event.Err = fmt.Errorf("registry %s: %w", name, event.Err)
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(reg.name, notifier.Events(ctx))
+	}
+	go func() {
+		wg.Wait()
+		<-ctx.Done()
+		close(out)
+	}()
+	return out
+}
+
+// Statuses 返回每个注册中心的注册状态，顺序与添加顺序一致
+func (m *Multi) Statuses() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make([]Status, 0, len(m.registries))
+	for _, reg := range m.registries {
+		status := Status{
+			Name:       reg.name,
+			Registered: len(m.services[reg.name]),
+			UpdateTime: m.updateTime[reg.name],
+		}
+		if err := m.lastErrors[reg.name]; err != nil {
+			status.LastError = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (m *Multi) record(name string, info *server.ServiceInfo, registered bool, err error) {
+	key := info.GetServiceKey("")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		if registered {
+			m.services[name][key] = struct{}{}
+		} else {
+			delete(m.services[name], key)
+		}
+	}
+	m.lastErrors[name] = err
+	m.updateTime[name] = time.Now()
+}
+
+// each 并发执行，返回每个注册中心的错误，下标与registries一致
+func (m *Multi) each(fn func(reg namedRegistry) error) []error {
+	errs := make([]error, len(m.registries))
+	var wg sync.WaitGroup
+	for i, reg := range m.registries {
+		wg.Add(1)
+		go func(i int, reg namedRegistry) {
+			defer wg.Done()
+			if err := fn(reg); err != nil {
+				errs[i] = fmt.Errorf("registry %s: %w", reg.name, err)
+			}
+		}(i, reg)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (m *Multi) result(action string, errs []error) error {
+	if m.policy == PolicyAny {
+		for _, err := range errs {
+			if err == nil {
+				return nil
+			}
+		}
+	}
+	return joinErrors(action, errs)
+}
+
+func joinErrors(action string, errs []error) error {
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("multi registry %s fail: %w", action, err)
+	}
+	return nil
+}
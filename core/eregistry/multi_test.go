@@ -0,0 +1,80 @@
+package eregistry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/server"
+)
+
+type testRegistry struct {
+	Nop
+	err          error
+	unregistered int32
+	events       chan Event
+}
+
+func (r *testRegistry) RegisterService(context.Context, *server.ServiceInfo) error {
+	return r.err
+}
+
+func (r *testRegistry) UnregisterService(context.Context, *server.ServiceInfo) error {
+	atomic.AddInt32(&r.unregistered, 1)
+	return nil
+}
+
+func (r *testRegistry) Events(ctx context.Context) <-chan Event {
+	return r.events
+}
+
+func TestMulti(t *testing.T) {
+	etcd := &testRegistry{}
+	k8s := &testRegistry{err: errors.New("forbidden")}
+	info := &server.ServiceInfo{Name: "svc", Scheme: "grpc", Address: "127.0.0.1:9002"}
+
+	m := NewMulti(WithRegistry("etcd", etcd), WithRegistry("k8s", k8s))
+	err := m.RegisterService(context.Background(), info)
+	assert.ErrorContains(t, err, "registry k8s: forbidden")
+	statuses := m.Statuses()
+	assert.Equal(t, "etcd", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Registered)
+	assert.Empty(t, statuses[0].LastError)
+	assert.Equal(t, 0, statuses[1].Registered)
+	assert.Equal(t, "forbidden", statuses[1].LastError)
+
+	// 迁移期间只要一个注册中心成功即可
+	m = NewMulti(WithRegistry("etcd", etcd), WithRegistry("k8s", k8s), WithPolicy(PolicyAny))
+	assert.NoError(t, m.RegisterService(context.Background(), info))
+
+	// 注销分发到所有注册中心
+	assert.NoError(t, m.UnregisterService(context.Background(), info))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&etcd.unregistered))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&k8s.unregistered))
+	assert.Equal(t, 0, m.Statuses()[0].Registered)
+}
+
+func TestMultiEvents(t *testing.T) {
+	etcd := &testRegistry{events: make(chan Event, 1)}
+	m := NewMulti(WithRegistry("etcd", etcd), WithRegistry("nop", Nop{}))
+	ctx, cancel := context.WithCancel(context.Background())
+	events := m.Events(ctx)
+
+	etcd.events <- Event{Type: EventDisconnected, Err: errors.New("lease expired")}
+	select {
+	case event := <-events:
+		assert.Equal(t, EventDisconnected, event.Type)
+		assert.EqualError(t, event.Err, "registry etcd: lease expired")
+	case <-time.After(time.Second):
+		t.Fatal("event timeout")
+	}
+
+	cancel()
+	close(etcd.events)
+	_, ok := <-events
+	assert.False(t, ok)
+}
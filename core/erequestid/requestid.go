@@ -0,0 +1,69 @@
+package erequestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+)
+
+// DefaultHeader 默认的请求ID header
+const DefaultHeader = "X-Request-ID"
+
+// maxLength 允许透传的请求ID最大长度，超过或者包含非法字符时重新生成
+const maxLength = 128
+
+var header atomic.Value
+
+func init() {
+	header.Store(DefaultHeader)
+}
+
+type requestIDKey struct{}
+
+// SetHeader 设置请求ID的header名称，服务端接收和客户端透传时使用同一个header
+func SetHeader(name string) {
+	if name == "" {
+		name = DefaultHeader
+	}
+	header.Store(name)
+}
+
+// Header 返回请求ID的header名称
+func Header() string {
+	return header.Load().(string)
+}
+
+// WithRequestID 把请求ID放入ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// FromContext 获取ctx中的请求ID，没有时返回空
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// New 生成32位十六进制的随机请求ID
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid 检查外部传入的请求ID，只允许可见的ASCII字符，避免日志注入
+func Valid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
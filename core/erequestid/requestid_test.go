@@ -0,0 +1,34 @@
+package erequestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", FromContext(context.Background()))
+	ctx := WithRequestID(context.Background(), "abc")
+	assert.Equal(t, "abc", FromContext(ctx))
+}
+
+func TestHeader(t *testing.T) {
+	assert.Equal(t, DefaultHeader, Header())
+	SetHeader("X-Trace-Request")
+	assert.Equal(t, "X-Trace-Request", Header())
+	SetHeader("")
+	assert.Equal(t, DefaultHeader, Header())
+}
+
+func TestNewAndValid(t *testing.T) {
+	id := New()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, New())
+	assert.True(t, Valid(id))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("a b"))
+	assert.False(t, Valid("a\nb"))
+	assert.False(t, Valid(strings.Repeat("a", 129)))
+}
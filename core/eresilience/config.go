@@ -0,0 +1,70 @@
+package eresilience
+
+import "time"
+
+// 组合的步骤，Order中从外到内排列
+const (
+	// StepFallback 调用失败后执行降级函数
+	StepFallback = "fallback"
+	// StepRetry 按照退避策略重试
+	StepRetry = "retry"
+	// StepHedge 调用超过HedgeDelay没有返回时发起对冲调用，使用最先成功的结果
+	StepHedge = "hedge"
+	// StepBreaker 熔断
+	StepBreaker = "breaker"
+	// StepBulkhead 舱壁，限制并发
+	StepBulkhead = "bulkhead"
+	// StepTimeout 每次调用的超时时间
+	StepTimeout = "timeout"
+)
+
+const (
+	// BackoffExponential 指数退避
+	BackoffExponential = "exponential"
+	// BackoffFibonacci 斐波那契退避
+	BackoffFibonacci = "fibonacci"
+	// BackoffConstant 固定间隔
+	BackoffConstant = "constant"
+)
+
+// Config 弹性策略配置，一个策略组合重试、对冲、熔断、舱壁、超时和降级，没有配置的步骤不生效
+type Config struct {
+	Order   []string      // 组合顺序，从外到内，默认为 fallback、retry、hedge、breaker、bulkhead、timeout
+	Timeout time.Duration // 每次调用的超时时间，默认0，不限制
+
+	MaxAttempts int           // 最多尝试次数，包括第一次调用，默认1，不重试
+	Backoff     string        // 退避策略，exponential、fibonacci、constant，默认exponential
+	MinBackoff  time.Duration // 第一次重试之前的等待时间，默认100ms
+	MaxBackoff  time.Duration // 最长等待时间，默认10s
+	Multiplier  float64       // 指数退避的倍数，默认2
+	Jitter      float64       `unit:"percent"` // 指数退避随机减少的比例，默认0.2
+
+	HedgeDelay time.Duration // 调用超过该时间没有返回时发起对冲调用，默认0，不对冲
+	MaxHedges  int           // 最多额外发起的对冲调用数，默认1
+
+	BreakerErrorRatio   float64       `unit:"percent"` // 错误率超过该值时熔断，取值0~1，默认0，只使用sentinel中已经配置的同名熔断规则
+	BreakerMinRequests  uint64        // 统计周期内请求数超过该值才会熔断，默认10
+	BreakerStatInterval time.Duration // 熔断的统计周期，默认10s
+	BreakerRetryTimeout time.Duration // 熔断后经过该时间进入半开状态，默认5s
+
+	MaxConcurrent int           // 最大并发调用数，默认0，不限制
+	MaxQueue      int           // 并发已满时最多等待的调用数，默认0
+	QueueTimeout  time.Duration // 最长排队时间，默认0，只受ctx控制
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Order:               []string{StepFallback, StepRetry, StepHedge, StepBreaker, StepBulkhead, StepTimeout},
+		MaxAttempts:         1,
+		Backoff:             BackoffExponential,
+		MinBackoff:          100 * time.Millisecond,
+		MaxBackoff:          10 * time.Second,
+		Multiplier:          2,
+		Jitter:              0.2,
+		MaxHedges:           1,
+		BreakerMinRequests:  10,
+		BreakerStatInterval: 10 * time.Second,
+		BreakerRetryTimeout: 5 * time.Second,
+	}
+}
@@ -0,0 +1,125 @@
+package eresilience
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alibaba/sentinel-golang/core/circuitbreaker"
+
+	"github.com/gotomicro/ego/core/ebulkhead"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eretry"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config    *Config
+	name      string
+	logger    *elog.Component
+	fallback  func(ctx context.Context, err error) error
+	retryable func(error) bool
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config:    DefaultConfig(),
+		logger:    elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		retryable: Retryable,
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithName 设置策略名称，用于熔断资源名、监控和链路，默认为配置的key
+func WithName(name string) Option {
+	return func(c *Container) {
+		c.name = name
+	}
+}
+
+// WithFallback 设置降级函数，调用最终失败后执行，返回nil表示降级成功
+func WithFallback(fn func(ctx context.Context, err error) error) Option {
+	return func(c *Container) {
+		c.fallback = fn
+	}
+}
+
+// WithRetryable 设置错误是否可以重试，默认 Retryable
+func WithRetryable(fn func(error) bool) Option {
+	return func(c *Container) {
+		c.retryable = fn
+	}
+}
+
+// Build 构建策略，配置了熔断错误率时加载同名的sentinel熔断规则
+func (c *Container) Build(options ...Option) *Policy {
+	for _, option := range options {
+		option(c)
+	}
+	if c.name == "" {
+		c.logger.Panic("resilience policy name is empty")
+	}
+	for _, step := range c.config.Order {
+		if _, ok := steps[step]; !ok {
+			c.logger.Panic("unknown resilience step", elog.String("step", step))
+		}
+	}
+	if c.config.BreakerErrorRatio > 0 {
+		_, err := circuitbreaker.LoadRulesOfResource(c.name, []*circuitbreaker.Rule{{
+			Resource:         c.name,
+			Strategy:         circuitbreaker.ErrorRatio,
+			RetryTimeoutMs:   uint32(c.config.BreakerRetryTimeout.Milliseconds()),
+			MinRequestAmount: c.config.BreakerMinRequests,
+			StatIntervalMs:   uint32(c.config.BreakerStatInterval.Milliseconds()),
+			Threshold:        c.config.BreakerErrorRatio,
+		}})
+		if err != nil {
+			c.logger.Panic("load circuit breaker rule fail", elog.FieldErr(err))
+		}
+	}
+	return newPolicy(c.name, c.config, c.fallback, c.retrier())
+}
+
+func (c *Container) retrier() *eretry.Retrier {
+	var policy eretry.Policy
+	switch c.config.Backoff {
+	case BackoffFibonacci:
+		policy = eretry.Fibonacci(c.config.MinBackoff, c.config.MaxBackoff)
+	case BackoffConstant:
+		policy = eretry.Constant(c.config.MinBackoff)
+	case BackoffExponential, "":
+		policy = eretry.Exponential(c.config.MinBackoff, c.config.MaxBackoff, c.config.Multiplier, c.config.Jitter)
+	default:
+		c.logger.Panic("unknown backoff", elog.String("backoff", c.config.Backoff))
+	}
+	return eretry.New(
+		eretry.WithName(c.name),
+		eretry.WithPolicy(policy),
+		eretry.WithMaxAttempts(max(c.config.MaxAttempts, 1)),
+		eretry.WithRetryable(c.retryable),
+	)
+}
+
+// Retryable 默认的错误分类，熔断和舱壁拒绝不重试，其他错误按照 eretry.Retryable 判断
+func Retryable(err error) bool {
+	if errors.Is(err, ebulkhead.ErrBreakerOpen) || errors.Is(err, ebulkhead.ErrRejected) {
+		return false
+	}
+	return eretry.Retryable(err)
+}
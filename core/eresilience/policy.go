@@ -0,0 +1,190 @@
+package eresilience
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotomicro/ego/core/ebulkhead"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/eretry"
+)
+
+// PackageName 包名
+const PackageName = "core.eresilience"
+
+type call = func(ctx context.Context) error
+
+// middleware 包装内层调用
+type middleware = func(next call) call
+
+// steps 创建每个步骤的middleware，步骤没有配置时返回nil
+var steps = map[string]func(p *Policy) middleware{
+	StepFallback: func(p *Policy) middleware {
+		if p.fallback == nil {
+			return nil
+		}
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				err := next(ctx)
+				if err == nil {
+					return nil
+				}
+				emetric.ResilienceCounter.Inc(p.name, StepFallback)
+				return p.fallback(ctx, err)
+			}
+		}
+	},
+	StepRetry: func(p *Policy) middleware {
+		if p.config.MaxAttempts <= 1 {
+			return nil
+		}
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				return p.retrier.Do(ctx, next)
+			}
+		}
+	},
+	StepHedge: func(p *Policy) middleware {
+		if p.config.HedgeDelay <= 0 || p.config.MaxHedges <= 0 {
+			return nil
+		}
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				return p.hedge(ctx, next)
+			}
+		}
+	},
+	StepBreaker: func(p *Policy) middleware {
+		// 不限制并发的舱壁只经过同名资源的熔断规则
+		breaker := ebulkhead.New(p.name, ebulkhead.Config{})
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				return breaker.Do(ctx, next)
+			}
+		}
+	},
+	StepBulkhead: func(p *Policy) middleware {
+		if p.config.MaxConcurrent <= 0 {
+			return nil
+		}
+		bulkhead := ebulkhead.New(p.name, ebulkhead.Config{
+			MaxConcurrent: p.config.MaxConcurrent,
+			MaxQueue:      p.config.MaxQueue,
+			QueueTimeout:  p.config.QueueTimeout,
+		})
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				release, err := bulkhead.Acquire(ctx)
+				if err != nil {
+					return err
+				}
+				defer release()
+				return next(ctx)
+			}
+		}
+	},
+	StepTimeout: func(p *Policy) middleware {
+		if p.config.Timeout <= 0 {
+			return nil
+		}
+		return func(next call) call {
+			return func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+				defer cancel()
+				return next(ctx)
+			}
+		}
+	},
+}
+
+// Policy 弹性策略，按照配置的顺序组合多个步骤，可以在多个goroutine中复用
+type Policy struct {
+	name        string
+	config      *Config
+	fallback    func(ctx context.Context, err error) error
+	retrier     *eretry.Retrier
+	middlewares []middleware // 从外到内
+}
+
+func newPolicy(name string, config *Config, fallback func(ctx context.Context, err error) error, retrier *eretry.Retrier) *Policy {
+	p := &Policy{name: name, config: config, fallback: fallback, retrier: retrier}
+	for _, step := range config.Order {
+		if m := steps[step](p); m != nil {
+			p.middlewares = append(p.middlewares, m)
+		}
+	}
+	return p
+}
+
+// Name 策略名称
+func (p *Policy) Name() string {
+	return p.name
+}
+
+// Do 按照策略执行fn，开启对冲时fn会被并发调用，需要是并发安全并且幂等的
+func (p *Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	next := fn
+	for i := len(p.middlewares) - 1; i >= 0; i-- {
+		next = p.middlewares[i](next)
+	}
+	return next(ctx)
+}
+
+// Do 按照策略执行fn，policy为nil时直接执行
+func Do(ctx context.Context, policy *Policy, fn func(ctx context.Context) error) error {
+	if policy == nil {
+		return fn(ctx)
+	}
+	return policy.Do(ctx, fn)
+}
+
+// hedge 先发起一次调用，超过HedgeDelay没有返回或者已经发起的调用都失败时，发起下一次调用，最多额外发起MaxHedges次
+// 返回最先成功的结果，其他调用被取消；都失败时返回第一个错误
+func (p *Policy) hedge(ctx context.Context, next call) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan error, p.config.MaxHedges+1)
+	launched, finished := 0, 0
+	launch := func() {
+		if launched > 0 {
+			emetric.ResilienceCounter.Inc(p.name, StepHedge)
+		}
+		launched++
+		go func() { results <- next(ctx) }()
+	}
+	launch()
+	timer := time.NewTimer(p.config.HedgeDelay)
+	defer timer.Stop()
+	var firstErr error
+	for {
+		select {
+		case err := <-results:
+			finished++
+			if err == nil {
+				return nil
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if finished < launched {
+				continue
+			}
+			if launched > p.config.MaxHedges {
+				return firstErr
+			}
+			launch()
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(p.config.HedgeDelay)
+		case <-timer.C:
+			if launched <= p.config.MaxHedges {
+				launch()
+				timer.Reset(p.config.HedgeDelay)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package eresilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alibaba/sentinel-golang/core/circuitbreaker"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ebulkhead"
+)
+
+func newTestPolicy(name string, fn func(config *Config), opts ...Option) *Policy {
+	c := DefaultContainer()
+	c.name = name
+	c.config.MinBackoff = time.Millisecond
+	fn(c.config)
+	return c.Build(opts...)
+}
+
+func TestRetryAndFallback(t *testing.T) {
+	var calls int32
+	p := newTestPolicy("test.retry", func(config *Config) {
+		config.MaxAttempts = 3
+		config.Backoff = BackoffConstant
+	}, WithFallback(func(ctx context.Context, err error) error {
+		assert.EqualError(t, err, "unavailable")
+		return nil
+	}))
+	err := Do(context.Background(), p, func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("unavailable")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls)
+	assert.NoError(t, Do(context.Background(), nil, func(context.Context) error { return nil }))
+}
+
+func TestHedgeAndTimeout(t *testing.T) {
+	var calls int32
+	p := newTestPolicy("test.hedge", func(config *Config) {
+		config.HedgeDelay = 10 * time.Millisecond
+		config.Timeout = time.Second
+	})
+	start := time.Now()
+	err := p.Do(context.Background(), func(ctx context.Context) error {
+		// 第一次调用很慢，对冲调用直接返回
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	p = newTestPolicy("test.timeout", func(config *Config) {
+		config.Timeout = 10 * time.Millisecond
+	})
+	err = p.Do(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBulkheadAndBreaker(t *testing.T) {
+	p := newTestPolicy("test.bulkhead", func(config *Config) {
+		config.MaxConcurrent = 1
+		config.MaxAttempts = 3
+	})
+	block, started := make(chan struct{}), make(chan struct{})
+	go func() {
+		_ = p.Do(context.Background(), func(context.Context) error {
+			close(started)
+			<-block
+			return nil
+		})
+	}()
+	<-started
+	// 舱壁拒绝不重试
+	err := p.Do(context.Background(), func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, ebulkhead.ErrRejected)
+	close(block)
+
+	p = newTestPolicy("test.breaker", func(config *Config) {
+		config.BreakerErrorRatio = 0.5
+		config.BreakerMinRequests = 2
+		config.BreakerStatInterval = time.Second
+	})
+	defer func() { _ = circuitbreaker.ClearRulesOfResource("test.breaker") }()
+	for i := 0; i < 5; i++ {
+		err = p.Do(context.Background(), func(context.Context) error { return errors.New("unavailable") })
+	}
+	assert.ErrorIs(t, err, ebulkhead.ErrBreakerOpen)
+
+	assert.Panics(t, func() {
+		newTestPolicy("test.order", func(config *Config) { config.Order = []string{"cache"} })
+	})
+}
@@ -0,0 +1,62 @@
+package eretry
+
+import (
+	"math"
+	"time"
+)
+
+// Policy 退避策略
+type Policy interface {
+	// Backoff 返回第attempt次重试之前的等待时间，attempt从1开始
+	Backoff(attempt int) time.Duration
+}
+
+// PolicyFunc 函数形式的退避策略
+type PolicyFunc func(attempt int) time.Duration
+
+// Backoff 实现 Policy
+func (f PolicyFunc) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// Exponential 指数退避，等待时间为 initial*multiplier^(attempt-1)，不超过maxDelay，maxDelay小于等于0时不限制
+// jitter为随机减少的比例，取值0~1，避免大量客户端同时重试
+func Exponential(initial, maxDelay time.Duration, multiplier, jitter float64) Policy {
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	return PolicyFunc(func(attempt int) time.Duration {
+		d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+		if maxDelay > 0 && d > float64(maxDelay) {
+			d = float64(maxDelay)
+		}
+		if jitter > 0 {
+			d *= 1 - math.Min(jitter, 1)*randomFloat()
+		}
+		return time.Duration(d)
+	})
+}
+
+// Fibonacci 按照斐波那契数列递增，依次为 initial、initial、2*initial、3*initial、5*initial...，不超过maxDelay，maxDelay小于等于0时不限制
+func Fibonacci(initial, maxDelay time.Duration) Policy {
+	return PolicyFunc(func(attempt int) time.Duration {
+		a, b := time.Duration(0), initial
+		for i := 1; i < attempt; i++ {
+			a, b = b, a+b
+			if maxDelay > 0 && b >= maxDelay {
+				return maxDelay
+			}
+		}
+		if maxDelay > 0 && b > maxDelay {
+			return maxDelay
+		}
+		return b
+	})
+}
+
+// Constant 固定间隔
+func Constant(d time.Duration) Policy {
+	return PolicyFunc(func(int) time.Duration {
+		return d
+	})
+}
@@ -0,0 +1,219 @@
+package eretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "core.eretry"
+
+// ErrBudgetExhausted 重试预算用完，不再重试
+var ErrBudgetExhausted = errors.New("eretry: retry budget exhausted")
+
+// Attempt 一次失败的尝试
+type Attempt struct {
+	Number int           // 第几次尝试，从1开始
+	Err    error         // 本次尝试的错误
+	Wait   time.Duration // 下一次重试之前的等待时间
+}
+
+// Retrier 按照退避策略重试，可以在多个goroutine中复用
+type Retrier struct {
+	name        string
+	policy      Policy
+	maxAttempts int
+	retryable   func(error) bool
+	onRetry     []func(ctx context.Context, attempt Attempt)
+	budget      *Budget
+}
+
+// Option 选项
+type Option func(r *Retrier)
+
+// WithName 设置名称，用于监控和链路中区分不同的重试
+func WithName(name string) Option {
+	return func(r *Retrier) {
+		r.name = name
+	}
+}
+
+// WithPolicy 设置退避策略，默认为 Exponential(100ms, 10s, 2, 0.2)
+func WithPolicy(policy Policy) Option {
+	return func(r *Retrier) {
+		r.policy = policy
+	}
+}
+
+// WithMaxAttempts 设置最多尝试的次数，包括第一次调用，默认为3，小于等于0时一直重试，直到ctx结束
+func WithMaxAttempts(n int) Option {
+	return func(r *Retrier) {
+		r.maxAttempts = n
+	}
+}
+
+// WithRetryable 设置错误是否可以重试，默认 Retryable
+func WithRetryable(fn func(error) bool) Option {
+	return func(r *Retrier) {
+		r.retryable = fn
+	}
+}
+
+// WithOnRetry 每次重试之前执行的回调，例如记录日志
+func WithOnRetry(fn func(ctx context.Context, attempt Attempt)) Option {
+	return func(r *Retrier) {
+		r.onRetry = append(r.onRetry, fn)
+	}
+}
+
+// WithBudget 设置重试预算，多个Retrier可以共享同一个预算，避免下游故障时重试放大流量
+func WithBudget(budget *Budget) Option {
+	return func(r *Retrier) {
+		r.budget = budget
+	}
+}
+
+// New 创建Retrier
+func New(opts ...Option) *Retrier {
+	r := &Retrier{
+		name:        "default",
+		policy:      Exponential(100*time.Millisecond, 10*time.Second, 2, 0.2),
+		maxAttempts: 3,
+		retryable:   Retryable,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Do 使用默认配置和opts创建Retrier并执行fn
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	return New(opts...).Do(ctx, fn)
+}
+
+// Do 执行fn，失败后按照退避策略重试，返回最后一次的错误
+// 错误不可重试、次数用完、预算用完、ctx结束或者等待时间超过ctx的截止时间时不再重试
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			r.budget.success()
+			if attempt > 1 {
+				emetric.RetryCounter.Inc(r.name, "success")
+			}
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			emetric.RetryCounter.Inc(r.name, "unretryable")
+			return permanent.err
+		}
+		if !r.retryable(err) {
+			emetric.RetryCounter.Inc(r.name, "unretryable")
+			return err
+		}
+		r.budget.failure()
+		if r.maxAttempts > 0 && attempt >= r.maxAttempts {
+			emetric.RetryCounter.Inc(r.name, "exhausted")
+			return err
+		}
+		if !r.budget.allow() {
+			emetric.RetryCounter.Inc(r.name, "budget")
+			return fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+		}
+		wait := r.policy.Backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			emetric.RetryCounter.Inc(r.name, "deadline")
+			return err
+		}
+		for _, onRetry := range r.onRetry {
+			onRetry(ctx, Attempt{Number: attempt, Err: err, Wait: wait})
+		}
+		etrace.AddRetryEvent(ctx, attempt, err.Error())
+		emetric.RetryCounter.Inc(r.name, "retry")
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			emetric.RetryCounter.Inc(r.name, "canceled")
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Retryable 默认的错误分类，ctx取消和超时不重试，实现了 Retryable() bool 的错误按照返回值判断，其他错误都重试
+func Retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var classified interface{ Retryable() bool }
+	if errors.As(err, &classified) {
+		return classified.Retryable()
+	}
+	return true
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 包装不需要重试的错误，Do直接返回被包装的错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Budget 重试预算，参考gRPC的重试限流
+// 每次失败消耗1个token，每次成功归还ratio个token，token不超过maxTokens，token低于maxTokens的一半时不再重试
+type Budget struct {
+	mu        sync.Mutex
+	maxTokens float64
+	ratio     float64
+	tokens    float64
+}
+
+// NewBudget 创建重试预算
+func NewBudget(maxTokens, ratio float64) *Budget {
+	return &Budget{maxTokens: maxTokens, ratio: ratio, tokens: maxTokens}
+}
+
+func (b *Budget) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+	b.mu.Unlock()
+}
+
+func (b *Budget) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.tokens = max(b.tokens-1, 0)
+	b.mu.Unlock()
+}
+
+func (b *Budget) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
@@ -0,0 +1,102 @@
+package eretry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	exp := Exponential(10*time.Millisecond, 50*time.Millisecond, 2, 0)
+	assert.Equal(t, 10*time.Millisecond, exp.Backoff(1))
+	assert.Equal(t, 40*time.Millisecond, exp.Backoff(3))
+	assert.Equal(t, 50*time.Millisecond, exp.Backoff(10))
+	jittered := Exponential(100*time.Millisecond, 0, 2, 0.5)
+	for i := 0; i < 10; i++ {
+		d := jittered.Backoff(2)
+		assert.True(t, d > 100*time.Millisecond && d <= 200*time.Millisecond, d)
+	}
+
+	fib := Fibonacci(10*time.Millisecond, 45*time.Millisecond)
+	var got []time.Duration
+	for i := 1; i <= 6; i++ {
+		got = append(got, fib.Backoff(i))
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 45 * time.Millisecond, 45 * time.Millisecond}, got)
+	assert.Equal(t, time.Second, Constant(time.Second).Backoff(5))
+}
+
+type classifiedErr bool
+
+func (e classifiedErr) Error() string   { return "classified" }
+func (e classifiedErr) Retryable() bool { return bool(e) }
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	var attempts []Attempt
+	calls := 0
+	err := Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, WithPolicy(Constant(time.Millisecond)), WithOnRetry(func(_ context.Context, a Attempt) {
+		attempts = append(attempts, a)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, 2, attempts[1].Number)
+	assert.Equal(t, time.Millisecond, attempts[1].Wait)
+
+	// 次数用完返回最后一次的错误
+	calls = 0
+	err = Do(ctx, func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	}, WithPolicy(Constant(0)), WithMaxAttempts(4))
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, 4, calls)
+
+	// 不可重试的错误
+	calls = 0
+	err = Do(ctx, func(context.Context) error {
+		calls++
+		return Permanent(errors.New("invalid argument"))
+	})
+	assert.EqualError(t, err, "invalid argument")
+	assert.Equal(t, 1, calls)
+	assert.False(t, Retryable(classifiedErr(false)))
+	assert.True(t, Retryable(classifiedErr(true)))
+	assert.False(t, Retryable(context.Canceled))
+
+	// 等待时间超过截止时间时不再等待
+	deadlineCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = Do(deadlineCtx, func(context.Context) error { return errors.New("slow") }, WithPolicy(Constant(time.Second)))
+	assert.EqualError(t, err, "slow")
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(4, 1)
+	r := New(WithBudget(budget), WithPolicy(Constant(0)), WithMaxAttempts(0))
+	calls := 0
+	err := r.Do(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("unavailable")
+	})
+	// token从4依次减少，低于2时不再重试
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.Equal(t, 2, calls)
+
+	// 成功后归还token
+	assert.NoError(t, r.Do(context.Background(), func(context.Context) error { return nil }))
+	assert.NoError(t, r.Do(context.Background(), func(context.Context) error { return nil }))
+	assert.True(t, budget.allow())
+}
@@ -12,16 +12,16 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package retry contains code to perform retries with exponential backoff.
+// Package eretry contains code to perform retries with exponential backoff, fibonacci or constant policies.
 //
 // Example: loop until doSomething() returns true or context hits deadline or is canceled.
 //
-//	for r := retry.Begin(); r.Continue(ctx); {
+//	for r := eretry.Begin(); r.Continue(ctx); {
 //	  if doSomething() {
 //	    break
 //	  }
 //	}
-package retry
+package eretry
 
 import (
 	"context"
@@ -66,11 +66,11 @@ func Begin() *Retry {
 //
 // Example: Sleep 1 second, then 2 seconds, then 4 seconds, and so on.
 //
-//	opts := retry.Options{
+//	opts := eretry.Options{
 //	  BackoffMultiplier: 2.0,
 //	  BackoffMinDuration: time.Second,
 //	}
-//	for r := retry.Begin(); r.Continue(ctx); {
+//	for r := eretry.Begin(); r.Continue(ctx); {
 //	  // Do nothing.
 //	}
 func BeginWithOptions(options Options) *Retry {
@@ -93,7 +93,7 @@ func (r *Retry) Continue(ctx context.Context) bool {
 // retry an operation with exponential backoff, but only if it is failing. For
 // example:
 //
-//	for r := retry.Begin(); r.Continue(ctx); {
+//	for r := eretry.Begin(); r.Continue(ctx); {
 //	    if err := doSomething(); err != nil {
 //	        // Retry with backoff if we fail.
 //	        continue
@@ -0,0 +1,268 @@
+package esaga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "core.esaga"
+
+var (
+	// ErrUnknownSaga saga没有注册
+	ErrUnknownSaga = errors.New("esaga: unknown saga")
+	// ErrCompensateFail 补偿失败，Recover会继续重试
+	ErrCompensateFail = errors.New("esaga: compensate fail")
+)
+
+// Action 步骤的动作或者补偿，可以读写 Saga.Data，修改随步骤一起保存
+// 补偿可能被重复执行，需要保证幂等
+type Action func(ctx context.Context, saga *Saga) error
+
+// Step saga的一个步骤，通常是一个本地事务或者一次下游调用
+type Step struct {
+	Name       string
+	Action     Action
+	Compensate Action // 后续步骤失败时执行，为空时不需要补偿
+}
+
+// Component saga协调者，顺序执行步骤，失败时按相反顺序执行已完成步骤的补偿
+type Component struct {
+	name   string
+	config *Config
+	store  Store
+	logger *elog.Component
+	tracer *etrace.Tracer
+
+	mu    sync.RWMutex
+	sagas map[string][]Step
+}
+
+func newComponent(name string, config *Config, store Store, logger *elog.Component) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		store:  store,
+		logger: logger,
+		tracer: etrace.NewTracer(trace.SpanKindInternal),
+		sagas:  make(map[string][]Step),
+	}
+}
+
+// Register 注册saga的步骤，重名时覆盖
+func (c *Component) Register(name string, steps ...Step) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sagas[name] = steps
+}
+
+func (c *Component) steps(name string) ([]Step, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	steps, ok := c.sagas[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownSaga, name)
+	}
+	return steps, nil
+}
+
+// Get 读取saga的状态
+func (c *Component) Get(ctx context.Context, id string) (*Saga, error) {
+	return c.store.Get(ctx, id)
+}
+
+// Execute 执行saga，某个步骤失败时执行补偿，并返回步骤的错误
+// 补偿重试MaxRetries次仍然失败时，返回的错误包含ErrCompensateFail，由Recover继续补偿
+func (c *Component) Execute(ctx context.Context, name, id string, data map[string]interface{}) (*Saga, error) {
+	steps, err := c.steps(name)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	now := time.Now()
+	saga := &Saga{ID: id, Name: name, Status: StatusRunning, Data: data, CreatedAt: now, UpdatedAt: now}
+
+	ctx, span := c.startSpan(ctx, "saga "+name, saga)
+	defer span.End()
+	if c.config.EnableTrace {
+		carrier := propagation.MapCarrier{}
+		etrace.Inject(ctx, carrier)
+		saga.TraceParent = carrier.Get("traceparent")
+	}
+	if err := c.save(ctx, saga); err != nil {
+		return nil, err
+	}
+
+	for i, step := range steps {
+		if err := c.run(ctx, "saga.step "+step.Name, step.Action, saga); err != nil {
+			c.logger.Error("saga step fail, compensate", elog.FieldName(name), elog.FieldKey(id), elog.String("step", step.Name), elog.FieldErr(err))
+			saga.Status = StatusCompensating
+			saga.Error = fmt.Sprintf("step %s fail, %v", step.Name, err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, saga.Error)
+			if compErr := c.compensate(ctx, steps, saga); compErr != nil {
+				return saga, fmt.Errorf("esaga: step %s fail, %w, %w", step.Name, err, compErr)
+			}
+			return saga, fmt.Errorf("esaga: step %s fail, %w", step.Name, err)
+		}
+		saga.Completed = i + 1
+		if err := c.save(ctx, saga); err != nil {
+			return saga, err
+		}
+	}
+	saga.Status = StatusCompleted
+	c.metric(saga)
+	return saga, c.save(ctx, saga)
+}
+
+// Recover 继续补偿失败的saga，以及协调者退出时没有执行完的saga，可以作为ecron的任务定时执行
+// 多副本部署时需要开启ecron的分布式任务，避免重复补偿
+func (c *Component) Recover(ctx context.Context) error {
+	pending, err := c.store.Pending(ctx, time.Now().Add(-c.config.StaleAfter), c.config.RecoverLimit)
+	if err != nil {
+		return fmt.Errorf("esaga: list pending sagas fail, %w", err)
+	}
+	var errs []error
+	for _, saga := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := c.recover(ctx, saga); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", saga.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Component) recover(ctx context.Context, saga *Saga) error {
+	steps, err := c.steps(saga.Name)
+	if err != nil {
+		return err
+	}
+	// 恢复时已经不在原来的请求中，使用link关联原来的saga链路
+	var opts []trace.SpanStartOption
+	if saga.TraceParent != "" {
+		opts = append(opts, trace.WithLinks(etrace.Links(propagation.MapCarrier{"traceparent": saga.TraceParent})...))
+	}
+	ctx, span := c.startSpan(ctx, "saga.recover "+saga.Name, saga, opts...)
+	defer span.End()
+	if saga.Status == StatusRunning {
+		c.logger.Warn("saga coordinator lost, compensate", elog.FieldName(saga.Name), elog.FieldKey(saga.ID))
+		saga.Status = StatusCompensating
+		saga.Error = "coordinator lost"
+	}
+	return c.compensate(ctx, steps, saga)
+}
+
+// compensate 按相反顺序补偿已完成的步骤，每个步骤重试MaxRetries次
+func (c *Component) compensate(ctx context.Context, steps []Step, saga *Saga) error {
+	saga.Attempts++
+	for saga.Completed > 0 {
+		step := steps[saga.Completed-1]
+		if step.Compensate != nil {
+			if err := c.retry(ctx, step, saga); err != nil {
+				if saga.Attempts >= c.config.MaxAttempts {
+					saga.Status = StatusFailed
+					c.logger.Error("saga compensate fail, need manual intervention", elog.FieldName(saga.Name), elog.FieldKey(saga.ID), elog.String("step", step.Name), elog.FieldErr(err))
+				}
+				saga.Error = fmt.Sprintf("compensate %s fail, %v", step.Name, err)
+				c.metric(saga)
+				if saveErr := c.save(ctx, saga); saveErr != nil {
+					return saveErr
+				}
+				return fmt.Errorf("%w, step: %s, %w", ErrCompensateFail, step.Name, err)
+			}
+		}
+		saga.Completed--
+		if err := c.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+	saga.Status = StatusCompensated
+	c.metric(saga)
+	return c.save(ctx, saga)
+}
+
+func (c *Component) retry(ctx context.Context, step Step, saga *Saga) error {
+	backoff := c.config.RetryBackoff
+	var err error
+	for i := 0; i <= c.config.MaxRetries; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > c.config.MaxBackoff {
+				backoff = c.config.MaxBackoff
+			}
+		}
+		err = c.run(ctx, "saga.compensate "+step.Name, step.Compensate, saga)
+		if c.config.EnableMetric {
+			code := "ok"
+			if err != nil {
+				code = "error"
+			}
+			emetric.SagaCompensationCounter.Inc(c.name, saga.Name, step.Name, code)
+		}
+		if err == nil {
+			return nil
+		}
+		c.logger.Warn("saga compensate fail, retry", elog.FieldName(saga.Name), elog.FieldKey(saga.ID), elog.String("step", step.Name), elog.Int("retry", i), elog.FieldErr(err))
+	}
+	return err
+}
+
+// run 在子span中执行动作，恢复panic
+func (c *Component) run(ctx context.Context, operation string, fn Action, saga *Saga) (err error) {
+	ctx, span := c.startSpan(ctx, operation, saga)
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	return fn(ctx, saga)
+}
+
+func (c *Component) startSpan(ctx context.Context, operation string, saga *Saga, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !c.config.EnableTrace {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	opts = append(opts, trace.WithAttributes(attribute.String("saga.name", saga.Name), attribute.String("saga.id", saga.ID)))
+	return c.tracer.Start(ctx, operation, nil, opts...)
+}
+
+func (c *Component) save(ctx context.Context, saga *Saga) error {
+	saga.UpdatedAt = time.Now()
+	if err := c.store.Save(ctx, saga); err != nil {
+		c.logger.Error("save saga fail", elog.FieldName(saga.Name), elog.FieldKey(saga.ID), elog.FieldErr(err))
+		return fmt.Errorf("esaga: save saga %s fail, %w", saga.ID, err)
+	}
+	return nil
+}
+
+func (c *Component) metric(saga *Saga) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.SagaCounter.Inc(c.name, saga.Name, string(saga.Status))
+}
@@ -0,0 +1,147 @@
+package esaga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+func newTestComponent(t *testing.T) (*Component, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	old := otel.GetTracerProvider()
+	etrace.SetGlobalTracer(tracesdk.NewTracerProvider(tracesdk.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(old) })
+	c := DefaultContainer()
+	c.config.RetryBackoff = time.Millisecond
+	return c.Build(), exporter
+}
+
+// recorder 记录动作的执行顺序
+type recorder []string
+
+func (r *recorder) step(name string, err error) Action {
+	return func(ctx context.Context, saga *Saga) error {
+		*r = append(*r, name)
+		saga.Data[name] = true
+		return err
+	}
+}
+
+func TestExecute(t *testing.T) {
+	comp, exporter := newTestComponent(t)
+	var calls recorder
+	comp.Register("order",
+		Step{Name: "pay", Action: calls.step("pay", nil), Compensate: calls.step("refund", nil)},
+		Step{Name: "reserve", Action: calls.step("reserve", nil), Compensate: calls.step("release", nil)},
+		Step{Name: "notify", Action: calls.step("notify", nil)},
+	)
+	saga, err := comp.Execute(context.Background(), "order", "1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, saga.Status)
+	assert.Equal(t, 3, saga.Completed)
+	assert.Equal(t, recorder{"pay", "reserve", "notify"}, calls)
+	assert.NotEmpty(t, saga.TraceParent)
+
+	// 每个步骤是saga span的子span
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+	root := spans[3]
+	assert.Equal(t, "saga order", root.Name)
+	for _, span := range spans[:3] {
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID())
+	}
+
+	_, err = comp.Execute(context.Background(), "unknown", "2", nil)
+	assert.ErrorIs(t, err, ErrUnknownSaga)
+}
+
+func TestCompensate(t *testing.T) {
+	comp, _ := newTestComponent(t)
+	var calls recorder
+	comp.Register("order",
+		Step{Name: "pay", Action: calls.step("pay", nil), Compensate: calls.step("refund", nil)},
+		Step{Name: "audit", Action: calls.step("audit", nil)},
+		Step{Name: "reserve", Action: calls.step("reserve", errors.New("out of stock"))},
+	)
+	saga, err := comp.Execute(context.Background(), "order", "1", nil)
+	assert.ErrorContains(t, err, "out of stock")
+	assert.NotErrorIs(t, err, ErrCompensateFail)
+	assert.Equal(t, StatusCompensated, saga.Status)
+	assert.Equal(t, 0, saga.Completed)
+	assert.Equal(t, recorder{"pay", "audit", "reserve", "refund"}, calls)
+
+	stored, err := comp.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompensated, stored.Status)
+	assert.Equal(t, true, stored.Data["pay"])
+}
+
+func TestRecover(t *testing.T) {
+	comp, exporter := newTestComponent(t)
+	comp.config.MaxRetries = 1
+	refundErr := errors.New("payment service unavailable")
+	var refunds int
+	comp.Register("order",
+		Step{Name: "pay", Action: func(ctx context.Context, saga *Saga) error { return nil }, Compensate: func(ctx context.Context, saga *Saga) error {
+			refunds++
+			return refundErr
+		}},
+		Step{Name: "reserve", Action: func(ctx context.Context, saga *Saga) error { return errors.New("out of stock") }},
+	)
+	saga, err := comp.Execute(context.Background(), "order", "1", nil)
+	assert.ErrorIs(t, err, ErrCompensateFail)
+	assert.ErrorIs(t, err, refundErr)
+	assert.Equal(t, StatusCompensating, saga.Status)
+	assert.Equal(t, 2, refunds)
+
+	// 下游恢复后，Recover继续补偿，并通过link关联原来的链路
+	refundErr = nil
+	exporter.Reset()
+	require.NoError(t, comp.Recover(context.Background()))
+	saga, err = comp.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompensated, saga.Status)
+	assert.Equal(t, 2, saga.Attempts)
+
+	spans := exporter.GetSpans()
+	root := spans[len(spans)-1]
+	assert.Equal(t, "saga.recover order", root.Name)
+	require.Len(t, root.Links, 1)
+	assert.Equal(t, saga.TraceParent[3:35], root.Links[0].SpanContext.TraceID().String())
+}
+
+func TestRecoverStale(t *testing.T) {
+	comp, _ := newTestComponent(t)
+	comp.config.StaleAfter = time.Minute
+	comp.config.MaxRetries = 0
+	comp.config.MaxAttempts = 1
+	comp.Register("order", Step{Name: "pay", Compensate: func(ctx context.Context, saga *Saga) error {
+		return errors.New("refund fail")
+	}})
+	// 协调者执行完第一个步骤后退出
+	stale := &Saga{ID: "1", Name: "order", Status: StatusRunning, Data: map[string]interface{}{}, Completed: 1, UpdatedAt: time.Now().Add(-time.Hour)}
+	require.NoError(t, comp.store.Save(context.Background(), stale))
+	fresh := &Saga{ID: "2", Name: "order", Status: StatusRunning, Data: map[string]interface{}{}, Completed: 1, UpdatedAt: time.Now()}
+	require.NoError(t, comp.store.Save(context.Background(), fresh))
+
+	assert.ErrorIs(t, comp.Recover(context.Background()), ErrCompensateFail)
+	saga, err := comp.Get(context.Background(), "1")
+	require.NoError(t, err)
+	// 超过MaxAttempts，需要人工处理，不再恢复
+	assert.Equal(t, StatusFailed, saga.Status)
+	assert.Contains(t, saga.Error, "refund fail")
+	assert.NoError(t, comp.Recover(context.Background()))
+
+	saga, err = comp.Get(context.Background(), "2")
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, saga.Status)
+}
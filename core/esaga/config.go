@@ -0,0 +1,33 @@
+package esaga
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config saga配置
+type Config struct {
+	MaxRetries   int           // 补偿失败时立即重试的次数，默认3
+	RetryBackoff time.Duration // 第一次重试的等待时间，之后每次翻倍，默认100ms
+	MaxBackoff   time.Duration // 重试等待时间的上限，默认10s
+	MaxAttempts  int           // Recover最多补偿的轮数，超过后标记为failed，需要人工处理，默认10
+	StaleAfter   time.Duration // 运行中的saga超过该时间没有进展，认为协调者已经退出，Recover时执行补偿，默认10m
+	RecoverLimit int           // 每次Recover最多处理的saga数，默认100
+	EnableTrace  bool          // 是否开启链路追踪，默认开启
+	EnableMetric bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		MaxRetries:   3,
+		RetryBackoff: xtime.Duration("100ms"),
+		MaxBackoff:   xtime.Duration("10s"),
+		MaxAttempts:  10,
+		StaleAfter:   xtime.Duration("10m"),
+		RecoverLimit: 100,
+		EnableTrace:  true,
+		EnableMetric: true,
+	}
+}
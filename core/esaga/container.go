@@ -0,0 +1,55 @@
+package esaga
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// Container 容器
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	store  Store
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// WithStore 设置saga状态的存储，默认使用内存存储，多副本部署时需要使用共享的存储
+func WithStore(store Store) Option {
+	return func(c *Container) {
+		c.store = store
+	}
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.store == nil {
+		c.store = NewMemoryStore()
+	}
+	return newComponent(c.name, c.config, c.store, c.logger)
+}
@@ -0,0 +1,105 @@
+package esaga
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound saga不存在
+var ErrNotFound = errors.New("esaga: saga not found")
+
+// Status saga的状态
+type Status string
+
+const (
+	// StatusRunning 正在执行步骤
+	StatusRunning Status = "running"
+	// StatusCompensating 某个步骤失败，正在执行补偿
+	StatusCompensating Status = "compensating"
+	// StatusCompleted 所有步骤执行成功
+	StatusCompleted Status = "completed"
+	// StatusCompensated 补偿全部执行成功
+	StatusCompensated Status = "compensated"
+	// StatusFailed 补偿多次失败，需要人工处理
+	StatusFailed Status = "failed"
+)
+
+// Saga saga的执行状态，每个步骤结束后保存
+type Saga struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Status      Status                 `json:"status"`
+	Data        map[string]interface{} `json:"data"`      // 步骤之间共享的数据，例如支付单号，补偿时使用
+	Completed   int                    `json:"completed"` // 已经完成并且没有补偿的步骤数
+	Attempts    int                    `json:"attempts"`  // 补偿的轮数
+	Error       string                 `json:"error,omitempty"`
+	TraceParent string                 `json:"traceParent,omitempty"` // 执行saga时的链路，Recover时作为span link
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+func (s *Saga) clone() *Saga {
+	out := *s
+	out.Data = make(map[string]interface{}, len(s.Data))
+	for k, v := range s.Data {
+		out.Data[k] = v
+	}
+	return &out
+}
+
+// Store saga状态的存储
+type Store interface {
+	// Save 保存saga，不存在时创建
+	Save(ctx context.Context, saga *Saga) error
+	// Get 读取saga，不存在时返回ErrNotFound
+	Get(ctx context.Context, id string) (*Saga, error)
+	// Pending 返回需要恢复的saga：补偿中的saga，以及staleBefore之后没有进展的运行中saga
+	Pending(ctx context.Context, staleBefore time.Time, limit int) ([]*Saga, error)
+}
+
+// memoryStore 内存存储，适用于单实例应用和测试，进程退出后数据丢失
+type memoryStore struct {
+	mu    sync.RWMutex
+	sagas map[string]*Saga
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() Store {
+	return &memoryStore{sagas: make(map[string]*Saga)}
+}
+
+func (m *memoryStore) Save(ctx context.Context, saga *Saga) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sagas[saga.ID] = saga.clone()
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, id string) (*Saga, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	saga, ok := m.sagas[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return saga.clone(), nil
+}
+
+func (m *memoryStore) Pending(ctx context.Context, staleBefore time.Time, limit int) ([]*Saga, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*Saga, 0)
+	for _, saga := range m.sagas {
+		if saga.Status == StatusCompensating || (saga.Status == StatusRunning && saga.UpdatedAt.Before(staleBefore)) {
+			out = append(out, saga.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt.Before(out[j].UpdatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
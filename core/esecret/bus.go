@@ -0,0 +1,117 @@
+package esecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// Credential 凭证
+type Credential struct {
+	Username  string            `json:"username"`
+	Password  string            `json:"password"`
+	Token     string            `json:"token"`
+	Extra     map[string]string `json:"extra"`
+	Version   string            `json:"-"` // 凭证版本，例如Vault的lease id、文件内容的摘要，版本相同时不会重复通知
+	ExpiresAt time.Time         `json:"-"` // 过期时间，零值表示不过期
+}
+
+// CredentialRefresher 使用凭证的组件实现该接口，凭证轮换后使用新凭证建立连接，不需要重启服务
+// 返回错误时组件需要继续使用旧的连接
+type CredentialRefresher interface {
+	RefreshCredential(ctx context.Context, cred Credential) error
+}
+
+// Bus 凭证轮换的事件总线，凭证后端发布新凭证，组件按凭证名称订阅
+type Bus struct {
+	mu          sync.Mutex
+	seq         int
+	latest      map[string]Credential
+	subscribers map[string]map[int]CredentialRefresher
+	logger      *elog.Component
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		latest:      make(map[string]Credential),
+		subscribers: make(map[string]map[int]CredentialRefresher),
+		logger:      elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Subscribe 订阅凭证轮换，返回取消订阅的函数
+func (b *Bus) Subscribe(name string, r CredentialRefresher) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	id := b.seq
+	if b.subscribers[name] == nil {
+		b.subscribers[name] = make(map[int]CredentialRefresher)
+	}
+	b.subscribers[name][id] = r
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[name], id)
+	}
+}
+
+// Current 返回最近一次发布的凭证，组件启动时使用
+func (b *Bus) Current(name string) (Credential, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cred, ok := b.latest[name]
+	return cred, ok
+}
+
+// Publish 发布新凭证，依次通知订阅的组件，返回所有组件刷新的错误
+// 版本和上次相同时不通知
+func (b *Bus) Publish(ctx context.Context, name string, cred Credential) error {
+	b.mu.Lock()
+	if old, ok := b.latest[name]; ok && cred.Version != "" && old.Version == cred.Version {
+		b.mu.Unlock()
+		return nil
+	}
+	b.latest[name] = cred
+	refreshers := make([]CredentialRefresher, 0, len(b.subscribers[name]))
+	for _, r := range b.subscribers[name] {
+		refreshers = append(refreshers, r)
+	}
+	b.mu.Unlock()
+
+	var errs []error
+	for _, r := range refreshers {
+		if err := r.RefreshCredential(ctx, cred); err != nil {
+			emetric.SecretRefreshCounter.Inc(name, "fail")
+			b.logger.Error("refresh credential fail", elog.FieldName(name), elog.FieldType(fmt.Sprintf("%T", r)), elog.FieldErr(err))
+			errs = append(errs, err)
+			continue
+		}
+		emetric.SecretRefreshCounter.Inc(name, "ok")
+		b.logger.Info("credential refreshed", elog.FieldName(name), elog.FieldType(fmt.Sprintf("%T", r)), elog.String("version", cred.Version))
+	}
+	return errors.Join(errs...)
+}
+
+var defaultBus = NewBus()
+
+// Subscribe 订阅默认事件总线的凭证轮换
+func Subscribe(name string, r CredentialRefresher) (unsubscribe func()) {
+	return defaultBus.Subscribe(name, r)
+}
+
+// Current 返回默认事件总线中最近一次发布的凭证
+func Current(name string) (Credential, bool) {
+	return defaultBus.Current(name)
+}
+
+// Publish 向默认事件总线发布新凭证
+func Publish(ctx context.Context, name string, cred Credential) error {
+	return defaultBus.Publish(ctx, name, cred)
+}
@@ -0,0 +1,88 @@
+package esecret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "core.esecret"
+
+// Component 凭证组件，监听凭证后端，凭证轮换时通过事件总线通知订阅的组件
+type Component struct {
+	name      string
+	config    *Config
+	logger    *elog.Component
+	provider  Provider
+	bus       *Bus
+	ready     chan struct{}
+	readyOnce sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+func newComponent(name string, config *Config, logger *elog.Component, provider Provider, bus *Bus) *Component {
+	return &Component{
+		name:     name,
+		config:   config,
+		logger:   logger,
+		provider: provider,
+		bus:      bus,
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Name 凭证名称
+func (c *Component) Name() string {
+	return c.config.Name
+}
+
+// Credential 返回当前凭证
+func (c *Component) Credential() Credential {
+	cred, _ := c.bus.Current(c.config.Name)
+	return cred
+}
+
+// start 启动凭证后端，等待第一个凭证
+func (c *Component) start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go func() {
+		defer close(c.done)
+		if err := c.provider.Watch(ctx, c.publish); err != nil {
+			c.logger.Error("credential provider stopped", elog.FieldErr(err))
+		}
+	}()
+	select {
+	case <-c.ready:
+		return nil
+	case <-c.done:
+		return errors.New("credential provider stopped before fetching credential")
+	case <-time.After(c.config.FetchTimeout):
+		return fmt.Errorf("fetch credential timeout after %s", c.config.FetchTimeout)
+	}
+}
+
+func (c *Component) publish(cred Credential) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.RefreshTimeout)
+	defer cancel()
+	if err := c.bus.Publish(ctx, c.config.Name, cred); err != nil {
+		c.logger.Error("publish credential fail", elog.FieldErr(err))
+	}
+	c.readyOnce.Do(func() { close(c.ready) })
+}
+
+// Close 停止监听凭证后端
+func (c *Component) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package esecret
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	creds []Credential
+	err   error
+}
+
+func (r *recorder) RefreshCredential(_ context.Context, cred Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.creds = append(r.creds, cred)
+	return nil
+}
+
+func (r *recorder) last() (Credential, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.creds) == 0 {
+		return Credential{}, 0
+	}
+	return r.creds[len(r.creds)-1], len(r.creds)
+}
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	ctx := context.Background()
+	db, cache := &recorder{}, &recorder{err: errors.New("redis down")}
+	unsubscribe := bus.Subscribe("mysql", db)
+	bus.Subscribe("mysql", cache)
+	bus.Subscribe("redis", &recorder{})
+
+	err := bus.Publish(ctx, "mysql", Credential{Username: "app", Password: "p1", Version: "1"})
+	assert.ErrorContains(t, err, "redis down")
+	cred, n := db.last()
+	assert.Equal(t, "p1", cred.Password)
+	assert.Equal(t, 1, n)
+	current, ok := bus.Current("mysql")
+	assert.True(t, ok)
+	assert.Equal(t, "1", current.Version)
+
+	// 相同版本不重复通知
+	cache.err = nil
+	assert.NoError(t, bus.Publish(ctx, "mysql", Credential{Username: "app", Password: "p1", Version: "1"}))
+	_, n = db.last()
+	assert.Equal(t, 1, n)
+
+	unsubscribe()
+	assert.NoError(t, bus.Publish(ctx, "mysql", Credential{Username: "app", Password: "p2", Version: "2"}))
+	_, n = db.last()
+	assert.Equal(t, 1, n)
+	cred, _ = cache.last()
+	assert.Equal(t, "p2", cred.Password)
+
+	_, ok = bus.Current("kafka")
+	assert.False(t, ok)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "username"), []byte("app\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("p1\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "host"), []byte("db.local"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0700))
+
+	bus := NewBus()
+	r := &recorder{}
+	bus.Subscribe("mysql", r)
+	c := DefaultContainer()
+	c.config.Name = "mysql"
+	c.config.Path = dir
+	c.config.RefreshInterval = 10 * time.Millisecond
+	comp := c.Build(WithBus(bus))
+	defer comp.Close()
+	cred := comp.Credential()
+	assert.Equal(t, "app", cred.Username)
+	assert.Equal(t, "p1", cred.Password)
+	assert.Equal(t, map[string]string{"host": "db.local"}, cred.Extra)
+	assert.NotEmpty(t, cred.Version)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("p2\n"), 0600))
+	assert.Eventually(t, func() bool {
+		cred, n := r.last()
+		return n == 2 && cred.Password == "p2"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "p2", comp.Credential().Password)
+
+	// JSON格式的文件
+	file := filepath.Join(t.TempDir(), "redis.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"username": "default", "password": "secret"}`), 0600))
+	cred, err := NewFileProvider(file, time.Second).(*fileProvider).load()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", cred.Password)
+}
+
+func TestLeaseProvider(t *testing.T) {
+	var mu sync.Mutex
+	leases := 0
+	provider := NewLeaseProvider(func(ctx context.Context) (Credential, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		leases++
+		if leases == 2 {
+			return Credential{}, errors.New("vault sealed")
+		}
+		return Credential{Username: "v-app", Version: "lease-" + string(rune('0'+leases)), ExpiresAt: time.Now().Add(30 * time.Millisecond)}, nil
+	}, 10*time.Millisecond)
+
+	bus := NewBus()
+	r := &recorder{}
+	bus.Subscribe("vault", r)
+	c := DefaultContainer()
+	c.config.Name = "vault"
+	comp := c.Build(WithBus(bus), WithProvider(provider))
+	assert.Equal(t, "lease-1", comp.Credential().Version)
+	// 第二次申请失败后重试
+	assert.Eventually(t, func() bool {
+		cred, _ := r.last()
+		return cred.Version == "lease-3"
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, comp.Close())
+}
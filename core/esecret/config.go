@@ -0,0 +1,28 @@
+package esecret
+
+import "time"
+
+const (
+	// SourceFile 从文件读取凭证
+	SourceFile = "file"
+)
+
+// Config 凭证配置
+type Config struct {
+	Name            string        // 凭证名称，组件通过名称订阅凭证，默认为配置的key
+	Source          string        // 凭证来源，默认file，其他后端通过 WithProvider 设置
+	Path            string        // file模式的凭证文件或者目录
+	RefreshInterval time.Duration // file模式检查文件变化的间隔，默认10s
+	FetchTimeout    time.Duration // 启动时等待第一个凭证的超时时间，默认10s
+	RefreshTimeout  time.Duration // 通知组件刷新凭证的超时时间，默认30s
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Source:          SourceFile,
+		RefreshInterval: 10 * time.Second,
+		FetchTimeout:    10 * time.Second,
+		RefreshTimeout:  30 * time.Second,
+	}
+}
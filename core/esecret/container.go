@@ -0,0 +1,83 @@
+package esecret
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehooks"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config   *Config
+	name     string
+	logger   *elog.Component
+	provider Provider
+	bus      *Bus
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		bus:    defaultBus,
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithProvider 设置凭证后端，例如 NewLeaseProvider 对接Vault，优先于配置中的Source
+func WithProvider(provider Provider) Option {
+	return func(c *Container) {
+		c.provider = provider
+	}
+}
+
+// WithBus 设置事件总线，默认使用全局的事件总线
+func WithBus(bus *Bus) Option {
+	return func(c *Container) {
+		c.bus = bus
+	}
+}
+
+// Build 构建凭证组件，等待获取到第一个凭证，凭证组件需要在使用凭证的组件之前构建
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.config.Name == "" {
+		c.config.Name = c.name
+	}
+	if c.config.Name == "" {
+		c.logger.Panic("credential name is empty")
+	}
+	if c.provider == nil {
+		switch c.config.Source {
+		case SourceFile:
+			c.provider = NewFileProvider(c.config.Path, c.config.RefreshInterval)
+		default:
+			c.logger.Panic("unknown credential source", elog.String("source", c.config.Source))
+		}
+	}
+	comp := newComponent(c.name, c.config, c.logger, c.provider, c.bus)
+	if err := comp.start(); err != nil {
+		_ = comp.Close()
+		c.logger.Panic("start credential fail", elog.FieldErr(err))
+	}
+	ehooks.Register(ehooks.StageAfterStop, comp.Close)
+	return comp
+}
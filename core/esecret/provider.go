@@ -0,0 +1,166 @@
+package esecret
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Provider 凭证后端，Watch阻塞运行直到ctx取消，获取到新凭证时调用fn
+type Provider interface {
+	Watch(ctx context.Context, fn func(Credential)) error
+}
+
+// fileProvider 从文件读取凭证，定时检查文件内容变化
+type fileProvider struct {
+	path     string
+	interval time.Duration
+	logger   *elog.Component
+}
+
+// NewFileProvider 创建从文件读取凭证的后端
+// path为目录时，按照Kubernetes Secret挂载的格式读取，文件名username、password、token对应凭证字段，其他文件放入Extra
+// path为文件时，按照JSON格式读取，例如 {"username": "app", "password": "***"}
+func NewFileProvider(path string, interval time.Duration) Provider {
+	return &fileProvider{
+		path:     path,
+		interval: interval,
+		logger:   elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+func (p *fileProvider) Watch(ctx context.Context, fn func(Credential)) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	var version string
+	for {
+		cred, err := p.load()
+		if err != nil {
+			p.logger.Error("load credential from file fail", elog.FieldErr(err), elog.String("path", p.path))
+		} else if cred.Version != version {
+			version = cred.Version
+			fn(cred)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *fileProvider) load() (Credential, error) {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return Credential{}, err
+	}
+	var cred Credential
+	hash := sha256.New()
+	if !info.IsDir() {
+		content, err := os.ReadFile(p.path)
+		if err != nil {
+			return Credential{}, err
+		}
+		if err = json.Unmarshal(content, &cred); err != nil {
+			return Credential{}, fmt.Errorf("parse %s fail, %w", p.path, err)
+		}
+		hash.Write(content)
+		cred.Version = hex.EncodeToString(hash.Sum(nil))[:16]
+		return cred, nil
+	}
+	entries, err := os.ReadDir(p.path)
+	if err != nil {
+		return Credential{}, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		// Kubernetes使用 ..data 等隐藏目录原子替换Secret
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(p.path, entry.Name())
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return Credential{}, err
+		}
+		hash.Write([]byte(entry.Name()))
+		hash.Write(content)
+		value := strings.TrimRight(string(content), "\r\n")
+		switch entry.Name() {
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		case "token":
+			cred.Token = value
+		default:
+			if cred.Extra == nil {
+				cred.Extra = make(map[string]string)
+			}
+			cred.Extra[entry.Name()] = value
+		}
+	}
+	cred.Version = hex.EncodeToString(hash.Sum(nil))[:16]
+	return cred, nil
+}
+
+// LeaseFunc 申请凭证，例如从Vault申请数据库的动态凭证，返回的凭证需要设置ExpiresAt和Version
+type LeaseFunc func(ctx context.Context) (Credential, error)
+
+// leaseProvider 在凭证过期之前重新申请凭证
+type leaseProvider struct {
+	fetch  LeaseFunc
+	retry  time.Duration
+	logger *elog.Component
+}
+
+// NewLeaseProvider 创建有租期的凭证后端，在租期过去2/3时重新申请，申请失败时按照retry间隔重试
+func NewLeaseProvider(fetch LeaseFunc, retry time.Duration) Provider {
+	return &leaseProvider{
+		fetch:  fetch,
+		retry:  retry,
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+func (p *leaseProvider) Watch(ctx context.Context, fn func(Credential)) error {
+	for {
+		wait := p.retry
+		cred, err := p.fetch(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			p.logger.Error("lease credential fail", elog.FieldErr(err))
+		case cred.ExpiresAt.IsZero():
+			// 不过期的凭证不需要续期
+			fn(cred)
+			<-ctx.Done()
+			return nil
+		default:
+			fn(cred)
+			// 已经过期的凭证按照重试间隔重新申请
+			if ttl := time.Until(cred.ExpiresAt) * 2 / 3; ttl > 0 {
+				wait = ttl
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
@@ -38,6 +38,9 @@ func newComponent(config *Config, logger *elog.Component) error {
 	configEntity := sentinelconfig.NewDefaultConfig()
 	configEntity.Sentinel.App.Name = config.AppName
 	configEntity.Sentinel.Log.Dir = config.LogPath
+	if config.EnableElog {
+		configEntity.Sentinel.Log.Logger = newSentinelLogger(logger)
+	}
 	return sentinelapi.InitWithConfig(configEntity)
 }
 
@@ -9,6 +9,8 @@ type Config struct {
 	AppName       string `json:"appName"`       // 应用名，默认从ego框架内部获取
 	LogPath       string `json:"logPath"`       // 日志路径，默认./logs
 	FlowRulesFile string `json:"flowRulesFile"` // 限流配置路径
+	// 是否将sentinel的框架日志输出到elog，默认关闭，输出到LogPath下的sentinel-record.log
+	EnableElog bool `json:"enableElog"`
 }
 
 // DefaultConfig returns default config for sentinel
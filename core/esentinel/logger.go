@@ -0,0 +1,73 @@
+package esentinel
+
+import (
+	"fmt"
+
+	"github.com/alibaba/sentinel-golang/logging"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var _ logging.Logger = (*sentinelLogger)(nil)
+
+// sentinelLogger 将sentinel的框架日志输出到elog
+type sentinelLogger struct {
+	logger *elog.Component
+}
+
+func newSentinelLogger(logger *elog.Component) *sentinelLogger {
+	return &sentinelLogger{logger: logger.With(elog.FieldComponentName("component.sentinel"))}
+}
+
+// Debug ...
+func (l *sentinelLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, fields(keysAndValues)...)
+}
+
+// DebugEnabled ...
+func (l *sentinelLogger) DebugEnabled() bool {
+	return l.logger.ZapLogger().Core().Enabled(elog.DebugLevel)
+}
+
+// Info ...
+func (l *sentinelLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(msg, fields(keysAndValues)...)
+}
+
+// InfoEnabled ...
+func (l *sentinelLogger) InfoEnabled() bool {
+	return l.logger.ZapLogger().Core().Enabled(elog.InfoLevel)
+}
+
+// Warn ...
+func (l *sentinelLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Warn(msg, fields(keysAndValues)...)
+}
+
+// WarnEnabled ...
+func (l *sentinelLogger) WarnEnabled() bool {
+	return l.logger.ZapLogger().Core().Enabled(elog.WarnLevel)
+}
+
+// Error ...
+func (l *sentinelLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, append(fields(keysAndValues), elog.FieldErr(err))...)
+}
+
+// ErrorEnabled ...
+func (l *sentinelLogger) ErrorEnabled() bool {
+	return l.logger.ZapLogger().Core().Enabled(elog.ErrorLevel)
+}
+
+// fields 将sentinel的key、value转换为elog字段
+func fields(keysAndValues []interface{}) []elog.Field {
+	res := make([]elog.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			res = append(res, elog.Any("extra", keysAndValues[i]))
+			break
+		}
+		res = append(res, elog.Any(fmt.Sprintf("%v", keysAndValues[i]), keysAndValues[i+1]))
+	}
+	return res
+}
@@ -0,0 +1,30 @@
+package esentinel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func TestSentinelLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newSentinelLogger(elog.DefaultContainer().Build(elog.WithZapCore(core)))
+	assert.False(t, l.DebugEnabled())
+	assert.True(t, l.InfoEnabled())
+
+	l.Debug("ignored")
+	l.Info("load rules", "count", 2, "odd")
+	l.Error(errors.New("invalid rule"), "load rules fail", "resource", "/hello")
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, map[string]interface{}{"compName": "component.sentinel", "count": int64(2), "extra": "odd"}, entries[0].ContextMap())
+	assert.Equal(t, elog.ErrorLevel, entries[1].Level)
+	assert.Equal(t, "invalid rule", entries[1].ContextMap()["error"])
+	assert.Equal(t, "/hello", entries[1].ContextMap()["resource"])
+}
@@ -0,0 +1,162 @@
+package esetting
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cast"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/internal/ienv"
+)
+
+// PackageName 包名
+const PackageName = "core.esetting"
+
+// Source 配置值的来源
+type Source string
+
+const (
+	// SourceFlag 命令行参数
+	SourceFlag Source = "flag"
+	// SourceEnv 环境变量
+	SourceEnv Source = "env"
+	// SourceConfig 配置文件
+	SourceConfig Source = "config"
+	// SourceDefault 默认值
+	SourceDefault Source = "default"
+	// SourceNone 没有任何来源
+	SourceNone Source = "none"
+)
+
+// Setting 一个配置项可以同时由命令行参数、环境变量、配置文件设置
+// 优先级为 flag > env > config > default
+type Setting struct {
+	Key     string      `json:"key"`              // 配置项名称
+	Flag    string      `json:"flag,omitempty"`   // 命令行参数名称，需要通过eflag注册
+	Env     string      `json:"env,omitempty"`    // 环境变量名称
+	Config  string      `json:"config,omitempty"` // 配置文件中的key，为空时不从配置文件读取
+	Default interface{} `json:"default,omitempty"`
+	Usage   string      `json:"usage,omitempty"`
+}
+
+// Value 配置项的生效值和来源
+type Value struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+	Origin string      `json:"origin,omitempty"` // 来源的名称，例如命令行参数名、环境变量名、配置文件中的key
+	Usage  string      `json:"usage,omitempty"`
+}
+
+var (
+	mu       sync.RWMutex
+	settings = make(map[string]Setting)
+)
+
+// Register 注册配置项，重名时覆盖
+func Register(items ...Setting) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, item := range items {
+		settings[item.Key] = item
+	}
+}
+
+// Resolve 按照 flag > env > config > default 的优先级返回配置项的生效值
+// 没有注册的配置项只从配置文件读取
+func Resolve(key string) Value {
+	mu.RLock()
+	item, ok := settings[key]
+	mu.RUnlock()
+	if !ok {
+		item = Setting{Key: key, Config: key}
+	}
+	return resolve(item)
+}
+
+func resolve(item Setting) Value {
+	val := Value{Key: item.Key, Usage: item.Usage}
+	if item.Flag != "" && eflag.IsSet(item.Flag) {
+		val.Value, val.Source, val.Origin = eflag.String(item.Flag), SourceFlag, "--"+item.Flag
+		return val
+	}
+	if item.Env != "" {
+		if env, ok := os.LookupEnv(item.Env); ok && env != "" {
+			val.Value, val.Source, val.Origin = env, SourceEnv, item.Env
+			return val
+		}
+	}
+	if item.Config != "" {
+		if conf := econf.Get(item.Config); conf != nil {
+			val.Value, val.Source, val.Origin = conf, SourceConfig, item.Config
+			return val
+		}
+	}
+	if item.Default != nil {
+		val.Value, val.Source = item.Default, SourceDefault
+		return val
+	}
+	val.Source = SourceNone
+	return val
+}
+
+// All 返回所有注册的配置项的生效值和来源，按照key排序
+func All() []Value {
+	mu.RLock()
+	items := make([]Setting, 0, len(settings))
+	for _, item := range settings {
+		items = append(items, item)
+	}
+	mu.RUnlock()
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	out := make([]Value, 0, len(items))
+	for _, item := range items {
+		out = append(out, resolve(item))
+	}
+	return out
+}
+
+// String 返回string类型的配置项
+func String(key string) string {
+	return cast.ToString(Resolve(key).Value)
+}
+
+// Bool 返回bool类型的配置项
+func Bool(key string) bool {
+	return cast.ToBool(Resolve(key).Value)
+}
+
+// Int 返回int类型的配置项
+func Int(key string) int {
+	return cast.ToInt(Resolve(key).Value)
+}
+
+// Int64 返回int64类型的配置项
+func Int64(key string) int64 {
+	return cast.ToInt64(Resolve(key).Value)
+}
+
+// Float64 返回float64类型的配置项
+func Float64(key string) float64 {
+	return cast.ToFloat64(Resolve(key).Value)
+}
+
+// Duration 返回time.Duration类型的配置项
+func Duration(key string) time.Duration {
+	return cast.ToDuration(Resolve(key).Value)
+}
+
+// StringSlice 返回[]string类型的配置项，命令行参数和环境变量使用逗号分隔
+func StringSlice(key string) []string {
+	val := Resolve(key)
+	if str, ok := val.Value.(string); ok && (val.Source == SourceFlag || val.Source == SourceEnv) {
+		return ienv.SplitStrSlice(str)
+	}
+	return cast.ToStringSlice(val.Value)
+}
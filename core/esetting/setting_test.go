@@ -0,0 +1,59 @@
+package esetting
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eflag"
+)
+
+func TestResolve(t *testing.T) {
+	fs := eflag.NewFlagSet(flag.NewFlagSet("test", flag.ContinueOnError),
+		&eflag.StringFlag{Name: "addr", Default: "127.0.0.1:9001"},
+		&eflag.StringFlag{Name: "level", Default: "info"},
+		&eflag.StringFlag{Name: "peers", Default: ""},
+	)
+	eflag.SetFlagSet(fs)
+	assert.NoError(t, fs.ParseWithArgs([]string{"--addr=0.0.0.0:9002", "--peers=a,b"}))
+	t.Setenv("EGO_TEST_ADDR", "0.0.0.0:9003")
+	t.Setenv("EGO_TEST_LEVEL", "debug")
+	assert.NoError(t, econf.LoadFromReader(bytes.NewBufferString(`
+[test]
+level = "warn"
+timeout = "3s"
+ratio = 0.5
+`), toml.Unmarshal))
+
+	Register(
+		Setting{Key: "addr", Flag: "addr", Env: "EGO_TEST_ADDR", Config: "test.addr"},
+		// 没有在命令行中设置的flag不生效，即使有默认值
+		Setting{Key: "level", Flag: "level", Env: "EGO_TEST_LEVEL", Config: "test.level"},
+		Setting{Key: "timeout", Env: "EGO_TEST_TIMEOUT", Config: "test.timeout", Default: time.Second},
+		Setting{Key: "retry", Config: "test.retry", Default: 3},
+		Setting{Key: "peers", Flag: "peers"},
+		Setting{Key: "empty"},
+	)
+
+	assert.Equal(t, Value{Key: "addr", Value: "0.0.0.0:9002", Source: SourceFlag, Origin: "--addr"}, Resolve("addr"))
+	assert.Equal(t, Value{Key: "level", Value: "debug", Source: SourceEnv, Origin: "EGO_TEST_LEVEL"}, Resolve("level"))
+	assert.Equal(t, Value{Key: "timeout", Value: "3s", Source: SourceConfig, Origin: "test.timeout"}, Resolve("timeout"))
+	assert.Equal(t, Value{Key: "retry", Value: 3, Source: SourceDefault}, Resolve("retry"))
+	assert.Equal(t, SourceNone, Resolve("empty").Source)
+	// 没有注册的配置项从配置文件读取
+	assert.Equal(t, SourceConfig, Resolve("test.ratio").Source)
+
+	assert.Equal(t, 3*time.Second, Duration("timeout"))
+	assert.Equal(t, 3, Int("retry"))
+	assert.Equal(t, 0.5, Float64("test.ratio"))
+	assert.Equal(t, []string{"a", "b"}, StringSlice("peers"))
+
+	all := All()
+	assert.Len(t, all, 6)
+	assert.Equal(t, "addr", all[0].Key)
+}
@@ -0,0 +1,220 @@
+package eslow
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PackageName 包名
+const PackageName = "core.eslow"
+
+// defaultCapacity 默认保留的慢请求记录数
+const defaultCapacity = 100
+
+// maxStackSize 采集所有goroutine栈时的最大字节数
+const maxStackSize = 1 << 20
+
+// Call 下游调用
+type Call struct {
+	Type   string  `json:"type"` // http、grpc、redis、mysql等
+	Name   string  `json:"name"` // 客户端组件名称
+	Method string  `json:"method"`
+	Peer   string  `json:"peer,omitempty"`
+	Offset float64 `json:"offsetMs"` // 相对于请求开始的时间
+	Cost   float64 `json:"costMs"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// Timing 耗时分解
+type Timing struct {
+	Name string  `json:"name"`
+	Cost float64 `json:"costMs"`
+}
+
+// Record 慢请求诊断记录
+type Record struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`  // http、grpc.unary、grpc.stream
+	Route     string    `json:"route"` // 路由或者gRPC方法
+	Path      string    `json:"path,omitempty"`
+	TraceID   string    `json:"traceId,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+	Code      int       `json:"code"`
+	Cost      float64   `json:"costMs"`
+	Timings   []Timing  `json:"timings"`
+	Calls     []Call    `json:"calls"`
+	Stack     string    `json:"stack,omitempty"` // 超过阈值时处理请求的goroutine栈
+}
+
+var (
+	mu       sync.Mutex
+	capacity = defaultCapacity
+	records  = make([]Record, 0, defaultCapacity)
+)
+
+type recorderKey struct{}
+
+// Recorder 记录单个请求的下游调用和耗时
+type Recorder struct {
+	threshold time.Duration
+	beg       time.Time
+	timer     *time.Timer
+	mu        sync.Mutex
+	calls     []Call
+	phases    []Timing
+	stack     string
+}
+
+// Start 开始记录请求，threshold<=0时不记录，返回的Recorder为nil
+// 请求超过threshold仍未结束时，采集处理请求的goroutine栈
+func Start(ctx context.Context, threshold time.Duration) (context.Context, *Recorder) {
+	if threshold <= 0 {
+		return ctx, nil
+	}
+	r := &Recorder{threshold: threshold, beg: time.Now()}
+	if gid := goroutineID(); gid != "" {
+		r.timer = time.AfterFunc(threshold, func() {
+			stack := goroutineStack(gid)
+			r.mu.Lock()
+			r.stack = stack
+			r.mu.Unlock()
+		})
+	}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// FromContext 获取请求的Recorder
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// AddCall 客户端组件记录一次下游调用，没有Recorder时不做处理
+func AddCall(ctx context.Context, call Call, beg time.Time, err error) {
+	r := FromContext(ctx)
+	if r == nil {
+		return
+	}
+	call.Offset = ms(beg.Sub(r.beg))
+	call.Cost = ms(time.Since(beg))
+	if err != nil {
+		call.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+}
+
+// Phase 记录一段代码的耗时，用于耗时分解，例如 defer eslow.Phase(ctx, "render")()
+func Phase(ctx context.Context, name string) func() {
+	r := FromContext(ctx)
+	if r == nil {
+		return func() {}
+	}
+	beg := time.Now()
+	return func() {
+		r.mu.Lock()
+		r.phases = append(r.phases, Timing{Name: name, Cost: ms(time.Since(beg))})
+		r.mu.Unlock()
+	}
+}
+
+// Finish 请求结束，耗时超过阈值时保存记录
+func (r *Recorder) Finish(record Record) {
+	if r == nil {
+		return
+	}
+	cost := time.Since(r.beg)
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if cost <= r.threshold {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var downstream float64
+	for _, call := range r.calls {
+		downstream += call.Cost
+	}
+	record.Time = r.beg
+	record.Cost = ms(cost)
+	record.Calls = r.calls
+	record.Stack = r.stack
+	record.Timings = append([]Timing{
+		{Name: "total", Cost: record.Cost},
+		{Name: "downstream", Cost: downstream},
+	}, r.phases...)
+	push(record)
+}
+
+func push(record Record) {
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) >= capacity {
+		n := copy(records, records[len(records)-capacity+1:])
+		records = records[:n]
+	}
+	records = append(records, record)
+}
+
+// SetCapacity 设置保留的慢请求记录数
+func SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	capacity = n
+	if len(records) > n {
+		records = append(records[:0:0], records[len(records)-n:]...)
+	}
+}
+
+// Records 返回慢请求记录，最新的记录在前，traceID不为空时只返回该trace的记录
+func Records(traceID string) []Record {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Record, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if traceID != "" && records[i].TraceID != traceID {
+			continue
+		}
+		out = append(out, records[i])
+	}
+	return out
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// goroutineID 当前goroutine的id，栈的第一行格式为 "goroutine 18 [running]:"
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if idx := bytes.IndexByte(buf, ' '); idx > 0 {
+		if _, err := strconv.ParseUint(string(buf[:idx]), 10, 64); err == nil {
+			return string(buf[:idx])
+		}
+	}
+	return ""
+}
+
+// goroutineStack 从所有goroutine的栈中找到指定goroutine的栈
+func goroutineStack(gid string) string {
+	buf := make([]byte, maxStackSize)
+	buf = buf[:runtime.Stack(buf, true)]
+	prefix := []byte("goroutine " + gid + " [")
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, prefix) {
+			return string(stack)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package eslow
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx, rec := Start(context.Background(), 10*time.Millisecond)
+	assert.Equal(t, rec, FromContext(ctx))
+
+	beg := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	AddCall(ctx, Call{Type: "grpc", Name: "user", Method: "/user.User/Info"}, beg, errors.New("unavailable"))
+	done := Phase(ctx, "render")
+	time.Sleep(30 * time.Millisecond)
+	done()
+	rec.Finish(Record{Type: "http", Route: "GET./hello", TraceID: "trace-recorder"})
+
+	records := Records("trace-recorder")
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "GET./hello", record.Route)
+	assert.Greater(t, record.Cost, float64(10))
+	assert.Len(t, record.Calls, 1)
+	assert.Equal(t, "unavailable", record.Calls[0].Error)
+	assert.Equal(t, []string{"total", "downstream", "render"}, []string{record.Timings[0].Name, record.Timings[1].Name, record.Timings[2].Name})
+	// 超过阈值时采集的是处理请求的goroutine栈
+	assert.Contains(t, record.Stack, "TestRecorder")
+}
+
+func TestRecorderFast(t *testing.T) {
+	ctx, rec := Start(context.Background(), time.Second)
+	AddCall(ctx, Call{Type: "http", Name: "fast"}, time.Now(), nil)
+	rec.Finish(Record{Route: "GET./fast", TraceID: "trace-fast"})
+	assert.Empty(t, Records("trace-fast"))
+
+	// 未开启时Recorder为nil，调用不会panic
+	ctx, rec = Start(context.Background(), 0)
+	assert.Nil(t, rec)
+	AddCall(ctx, Call{Type: "http"}, time.Now(), nil)
+	Phase(ctx, "noop")()
+	rec.Finish(Record{})
+}
+
+func TestSetCapacity(t *testing.T) {
+	SetCapacity(3)
+	defer SetCapacity(defaultCapacity)
+	for i := 0; i < 5; i++ {
+		push(Record{Route: strconv.Itoa(i), TraceID: "trace-capacity"})
+	}
+	records := Records("trace-capacity")
+	assert.Len(t, records, 3)
+	assert.Equal(t, "4", records[0].Route)
+	assert.Equal(t, "2", records[2].Route)
+}
@@ -0,0 +1,148 @@
+package estophook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// PackageName 包名
+const PackageName = "core.estophook"
+
+// 停止阶段
+const (
+	// PhaseDrainStart 开始停止，注销注册中心和停止服务之前，通常用于从外部负载均衡摘除实例
+	PhaseDrainStart = "drainStart"
+	// PhaseServersStopped 服务和定时任务已经停止，写入组件缓冲数据之前
+	PhaseServersStopped = "serversStopped"
+	// PhaseCleanupDone 停止后清理已经完成，日志flush之前，通常用于通知发布系统
+	PhaseCleanupDone = "cleanupDone"
+)
+
+var phases = map[string]struct{}{PhaseDrainStart: {}, PhaseServersStopped: {}, PhaseCleanupDone: {}}
+
+// Event webhook的请求body
+type Event struct {
+	App      string `json:"app"`
+	Phase    string `json:"phase"`
+	HostName string `json:"hostName"`
+	Pid      int    `json:"pid"`
+	Time     string `json:"time"`
+}
+
+// Component 停止阶段的外部钩子
+type Component struct {
+	name   string
+	config *Config
+	logger *elog.Component
+	client *http.Client
+}
+
+func newComponent(name string, config *Config, logger *elog.Component) (*Component, error) {
+	for i := range config.Hooks {
+		hook := &config.Hooks[i]
+		if _, ok := phases[hook.Phase]; !ok {
+			return nil, fmt.Errorf("hook %d has unknown phase %q", i, hook.Phase)
+		}
+		if (len(hook.Command) == 0) == (hook.URL == "") {
+			return nil, fmt.Errorf("hook %d should set one of command and url", i)
+		}
+		if hook.Name == "" {
+			hook.Name = hook.URL
+			if len(hook.Command) > 0 {
+				hook.Name = strings.Join(hook.Command, " ")
+			}
+		}
+		if hook.Method == "" {
+			hook.Method = http.MethodPost
+		}
+		if hook.Timeout <= 0 {
+			hook.Timeout = config.Timeout
+		}
+	}
+	return &Component{name: name, config: config, logger: logger, client: &http.Client{}}, nil
+}
+
+// Name 配置的名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// Run 按照配置的顺序执行该阶段的钩子，单个钩子失败不影响后续的钩子，返回聚合错误
+// 每个钩子的超时时间不会超过ctx的deadline
+func (c *Component) Run(ctx context.Context, phase string) error {
+	var errs []error
+	for _, hook := range c.config.Hooks {
+		if hook.Phase != phase {
+			continue
+		}
+		beg := time.Now()
+		err := c.runHook(ctx, hook)
+		fields := []elog.Field{elog.FieldName(hook.Name), elog.String("phase", phase), elog.FieldCost(time.Since(beg))}
+		if err != nil {
+			c.logger.Error("run stop hook fail", append(fields, elog.FieldErr(err))...)
+			errs = append(errs, fmt.Errorf("stop hook %s fail, %w", hook.Name, err))
+			continue
+		}
+		c.logger.Info("run stop hook", fields...)
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Component) runHook(ctx context.Context, hook HookConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, hook.Timeout)
+	defer cancel()
+	if len(hook.Command) > 0 {
+		cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+		cmd.Env = append(os.Environ(), "EGO_STOP_PHASE="+hook.Phase, "EGO_APP_NAME="+eapp.Name())
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w, output: %s", err, bytes.TrimSpace(out))
+		}
+		return nil
+	}
+
+	body, err := json.Marshal(Event{
+		App:      eapp.Name(),
+		Phase:    hook.Phase,
+		HostName: eapp.HostName(),
+		Pid:      os.Getpid(),
+		Time:     time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, hook.Method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %d, body: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
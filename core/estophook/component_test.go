@@ -0,0 +1,85 @@
+package estophook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestRun(t *testing.T) {
+	var events []Event
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+	}))
+	defer ts.Close()
+	out := filepath.Join(t.TempDir(), "phase")
+
+	conf := `
+[stopHooks]
+[[stopHooks.hooks]]
+phase = "drainStart"
+url = "` + ts.URL + `"
+headers = { Authorization = "token" }
+[[stopHooks.hooks]]
+phase = "serversStopped"
+command = ["/bin/sh", "-c", "echo $EGO_STOP_PHASE > ` + out + `"]
+`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	comp := Load("stopHooks").Build()
+
+	assert.NoError(t, comp.Run(context.Background(), PhaseDrainStart))
+	assert.Len(t, events, 1)
+	assert.Equal(t, PhaseDrainStart, events[0].Phase)
+
+	assert.NoError(t, comp.Run(context.Background(), PhaseServersStopped))
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "serversStopped\n", string(data))
+
+	// 没有该阶段的钩子
+	assert.NoError(t, comp.Run(context.Background(), PhaseCleanupDone))
+}
+
+func TestRunFail(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("lb unavailable"))
+	}))
+	defer ts.Close()
+
+	var ran bool
+	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { ran = true }))
+	defer ts2.Close()
+
+	comp := DefaultContainer().Build(
+		WithHook(HookConfig{Name: "lb", Phase: PhaseDrainStart, URL: ts.URL}),
+		WithHook(HookConfig{Phase: PhaseDrainStart, Command: []string{"sleep", "1"}, Timeout: 10 * time.Millisecond}),
+		WithHook(HookConfig{Name: "deploy", Phase: PhaseDrainStart, URL: ts2.URL}),
+	)
+	err := comp.Run(context.Background(), PhaseDrainStart)
+	assert.ErrorContains(t, err, "stop hook lb fail, webhook returned 502, body: lb unavailable")
+	assert.ErrorContains(t, err, "stop hook sleep 1 fail, signal: killed")
+	// 失败不影响后续的钩子
+	assert.True(t, ran)
+
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithHook(HookConfig{Phase: "afterStop", URL: ts.URL}))
+	})
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithHook(HookConfig{Phase: PhaseDrainStart}))
+	})
+}
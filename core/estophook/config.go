@@ -0,0 +1,32 @@
+package estophook
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 停止阶段的外部钩子配置
+type Config struct {
+	Timeout time.Duration // 单个钩子的默认超时时间，默认5s
+	Hooks   []HookConfig  // 钩子列表，同一阶段按照配置的顺序执行
+}
+
+// HookConfig 单个钩子的配置，Command 和 URL 只能设置一个
+type HookConfig struct {
+	Name    string            // 钩子名称，用于日志，默认为Command或者URL
+	Phase   string            // 执行的阶段，drainStart、serversStopped、cleanupDone
+	Command []string          // 执行的命令和参数，例如 ["/bin/sh", "-c", "lb-cli deregister $EGO_APP_NAME"]，环境变量中包含 EGO_STOP_PHASE
+	URL     string            // 调用的webhook地址，请求body为JSON格式的停止事件，返回非2xx时为失败
+	Method  string            // webhook的请求方法，默认POST
+	Headers map[string]string // webhook的请求头
+	Timeout time.Duration     // 超时时间，默认使用Config.Timeout
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout: xtime.Duration("5s"),
+		Hooks:   []HookConfig{},
+	}
+}
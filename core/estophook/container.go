@@ -0,0 +1,56 @@
+package estophook
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option overrides a Container's default configuration.
+type Option func(c *Container)
+
+// Container defines a component instance.
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+}
+
+// DefaultContainer returns an default container.
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load parses container configuration from configuration provider, such as a toml file,
+// then use the configuration to construct a component container.
+func Load(key string) *Container {
+	c := DefaultContainer()
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.name = key
+	return c
+}
+
+// WithHook 通过代码添加钩子，和配置中的钩子一起按照阶段执行
+func WithHook(hook HookConfig) Option {
+	return func(c *Container) {
+		c.config.Hooks = append(c.config.Hooks, hook)
+	}
+}
+
+// Build 校验钩子配置并创建组件，阶段不存在或者Command和URL设置错误时panic
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	comp, err := newComponent(c.name, c.config, c.logger)
+	if err != nil {
+		c.logger.Panic("build stop hooks error", elog.FieldErr(err))
+	}
+	return comp
+}
@@ -0,0 +1,189 @@
+package estream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// PackageName 包名
+const PackageName = "core.estream"
+
+const (
+	// PolicyBackpressure 缓冲区已满时阻塞发送方，超过SendTimeout后断开，默认策略
+	PolicyBackpressure = "backpressure"
+	// PolicyDrop 缓冲区已满时丢弃新的消息，适合行情、进度等只关心最新值的推送
+	PolicyDrop = "drop"
+	// PolicyDisconnect 缓冲区已满时立即断开
+	PolicyDisconnect = "disconnect"
+)
+
+const (
+	// ActionBlocked 发送方被阻塞
+	ActionBlocked = "blocked"
+	// ActionDropped 消息被丢弃
+	ActionDropped = "dropped"
+	// ActionDisconnected 流被断开
+	ActionDisconnected = "disconnected"
+)
+
+// ErrSlowConsumer 对端读取太慢，流被断开
+var ErrSlowConsumer = errors.New("estream: slow consumer")
+
+// Config 慢消费者保护配置
+type Config struct {
+	BufferSize  int           // 每个流的发送缓冲区消息数，0表示不开启，直接同步发送
+	Policy      string        // 缓冲区已满时的策略，backpressure、drop、disconnect，默认backpressure
+	SendTimeout time.Duration // backpressure策略下最长阻塞时间，超过后断开，默认0，只受ctx控制
+}
+
+// Enabled 是否开启慢消费者保护
+func (c Config) Enabled() bool {
+	return c.BufferSize > 0
+}
+
+// Labels 指标的标签，用于定位慢消费者
+type Labels struct {
+	Type   string // 流的类型，例如 stream、sse
+	Method string // gRPC方法或者HTTP路由
+	Peer   string // 对端应用名称或者IP
+}
+
+// Sender 带缓冲区的发送方，后台goroutine按顺序调用send，send不需要是并发安全的
+// 缓冲区已满说明对端读取的速度跟不上，按照Policy处理，避免服务端为慢消费者堆积内存
+type Sender struct {
+	config Config
+	labels Labels
+	send   func(msg any) error
+	queue  chan any
+	done   chan struct{}
+	cancel context.CancelFunc
+	ctx    context.Context
+
+	mu     sync.Mutex
+	err    error // 第一次发送失败或者断开的错误，之后的发送直接返回
+	closed bool
+}
+
+// NewSender 创建发送方，ctx为流的ctx，断开时取消ctx，需要调用 Close 等待缓冲区的消息发送完
+func NewSender(ctx context.Context, config Config, labels Labels, send func(msg any) error) *Sender {
+	if config.Policy == "" {
+		config.Policy = PolicyBackpressure
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Sender{
+		config: config,
+		labels: labels,
+		send:   send,
+		queue:  make(chan any, config.BufferSize),
+		done:   make(chan struct{}),
+		cancel: cancel,
+		ctx:    ctx,
+	}
+	go s.run()
+	return s
+}
+
+// Context 流的ctx，慢消费者被断开后取消
+func (s *Sender) Context() context.Context {
+	return s.ctx
+}
+
+// Send 把消息放入缓冲区，缓冲区已满时按照Policy处理，丢弃消息时返回nil
+func (s *Sender) Send(msg any) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	default:
+	}
+
+	switch s.config.Policy {
+	case PolicyDrop:
+		s.record(ActionDropped)
+		return nil
+	case PolicyDisconnect:
+		return s.disconnect()
+	}
+
+	s.record(ActionBlocked)
+	var timeout <-chan time.Time
+	if s.config.SendTimeout > 0 {
+		timer := time.NewTimer(s.config.SendTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case s.queue <- msg:
+		return nil
+	case <-timeout:
+		return s.disconnect()
+	case <-s.ctx.Done():
+		if err := s.Err(); err != nil {
+			return err
+		}
+		return s.ctx.Err()
+	}
+}
+
+// Close 停止接收新的消息，等待缓冲区的消息发送完，返回发送失败或者断开的错误，Close之后不能再调用Send
+func (s *Sender) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	select {
+	case <-s.done:
+	case <-s.ctx.Done():
+		// 已经断开或者对端已经关闭，阻塞中的发送在流结束后返回，不再等待
+	}
+	s.cancel()
+	return s.Err()
+}
+
+// Err 发送失败或者断开的错误
+func (s *Sender) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Sender) run() {
+	defer close(s.done)
+	for msg := range s.queue {
+		// 失败或者断开后丢弃剩下的消息
+		if s.Err() != nil || s.ctx.Err() != nil {
+			continue
+		}
+		if err := s.send(msg); err != nil {
+			s.setErr(err)
+			s.cancel()
+		}
+	}
+}
+
+func (s *Sender) disconnect() error {
+	s.record(ActionDisconnected)
+	s.setErr(ErrSlowConsumer)
+	s.cancel()
+	return ErrSlowConsumer
+}
+
+func (s *Sender) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Sender) record(action string) {
+	emetric.StreamSlowConsumerCounter.Inc(s.labels.Type, s.labels.Method, s.labels.Peer, action)
+}
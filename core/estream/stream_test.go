@@ -0,0 +1,100 @@
+package estream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowConsumer 在unblock之前阻塞发送
+type slowConsumer struct {
+	mu       sync.Mutex
+	received []any
+	unblock  chan struct{}
+	entered  chan struct{}
+}
+
+func newSlowConsumer() *slowConsumer {
+	return &slowConsumer{unblock: make(chan struct{}), entered: make(chan struct{}, 100)}
+}
+
+func (s *slowConsumer) send(msg any) error {
+	s.entered <- struct{}{}
+	<-s.unblock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, msg)
+	return nil
+}
+
+func (s *slowConsumer) messages() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]any(nil), s.received...)
+}
+
+// fill 第一条消息被后台goroutine取出阻塞在send，之后填满缓冲区
+func fill(t *testing.T, s *Sender, consumer *slowConsumer, n int) {
+	assert.NoError(t, s.Send(0))
+	<-consumer.entered
+	for i := 1; i <= n; i++ {
+		assert.NoError(t, s.Send(i))
+	}
+}
+
+func TestPolicyDrop(t *testing.T) {
+	consumer := newSlowConsumer()
+	s := NewSender(context.Background(), Config{BufferSize: 2, Policy: PolicyDrop}, Labels{Type: "test"}, consumer.send)
+	fill(t, s, consumer, 2)
+	assert.NoError(t, s.Send(3))
+	close(consumer.unblock)
+	assert.NoError(t, s.Close())
+	assert.Equal(t, []any{0, 1, 2}, consumer.messages())
+}
+
+func TestPolicyDisconnect(t *testing.T) {
+	consumer := newSlowConsumer()
+	s := NewSender(context.Background(), Config{BufferSize: 1, Policy: PolicyDisconnect}, Labels{Type: "test"}, consumer.send)
+	fill(t, s, consumer, 1)
+	assert.ErrorIs(t, s.Send(2), ErrSlowConsumer)
+	assert.ErrorIs(t, s.Send(3), ErrSlowConsumer)
+	assert.Error(t, s.Context().Err())
+	// 断开后不等待阻塞中的发送
+	assert.ErrorIs(t, s.Close(), ErrSlowConsumer)
+	close(consumer.unblock)
+}
+
+func TestPolicyBackpressure(t *testing.T) {
+	consumer := newSlowConsumer()
+	s := NewSender(context.Background(), Config{BufferSize: 1}, Labels{Type: "test"}, consumer.send)
+	fill(t, s, consumer, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(consumer.unblock)
+	}()
+	// 阻塞到对端读取
+	assert.NoError(t, s.Send(2))
+	assert.NoError(t, s.Close())
+	assert.Equal(t, []any{0, 1, 2}, consumer.messages())
+
+	// 超过SendTimeout后断开
+	consumer = newSlowConsumer()
+	s = NewSender(context.Background(), Config{BufferSize: 1, SendTimeout: 20 * time.Millisecond}, Labels{Type: "test"}, consumer.send)
+	fill(t, s, consumer, 1)
+	assert.ErrorIs(t, s.Send(2), ErrSlowConsumer)
+	assert.ErrorIs(t, s.Close(), ErrSlowConsumer)
+	close(consumer.unblock)
+}
+
+func TestSendError(t *testing.T) {
+	errSend := errors.New("broken pipe")
+	s := NewSender(context.Background(), Config{BufferSize: 4}, Labels{Type: "test"}, func(any) error { return errSend })
+	assert.NoError(t, s.Send(1))
+	assert.Eventually(t, func() bool { return s.Err() != nil }, time.Second, time.Millisecond)
+	assert.ErrorIs(t, s.Send(2), errSend)
+	assert.ErrorIs(t, s.Close(), errSend)
+}
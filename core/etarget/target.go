@@ -0,0 +1,116 @@
+package etarget
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// PackageName 包名
+const PackageName = "core.etarget"
+
+const (
+	// ConfigKey 别名配置的key，例如 targets.user-service = "grpc://user.prod.svc:9002"
+	ConfigKey = "targets"
+	// Scheme 客户端地址的scheme，例如 target:///user-service
+	Scheme = "target"
+)
+
+// 业务代码只使用逻辑服务名称，每个环境的真实地址只在配置中维护，例如
+//
+//	[targets]
+//	user-service = "grpc://user.prod.svc:9002"
+//	order-service = "etcd:///svc-order"
+//	pay-service = "https://pay.example.com"
+//
+// 客户端的addr配置为 user-service 或者 target:///user-service 时，创建客户端时替换为别名对应的地址，
+// 配置热更新后客户端使用新的地址
+
+// Lookup 返回别名对应的地址
+func Lookup(name string) (string, bool) {
+	addr, ok := econf.GetStringMapString(ConfigKey)[name]
+	if !ok || addr == "" {
+		return "", false
+	}
+	return addr, true
+}
+
+// Parse 判断客户端地址是否为别名，返回别名名称
+// target:///user-service、target://user-service 为显式的别名，别名不存在时返回错误；
+// 与别名同名的地址，例如 user-service，视为别名；其他地址原样使用
+func Parse(addr string) (name string, ok bool, err error) {
+	if rest, found := strings.CutPrefix(addr, Scheme+"://"); found {
+		name = strings.Trim(rest, "/")
+		if _, exist := Lookup(name); !exist {
+			return "", false, fmt.Errorf("target %q not found in config %q", name, ConfigKey)
+		}
+		return name, true, nil
+	}
+	if _, exist := Lookup(addr); exist {
+		return addr, true, nil
+	}
+	return "", false, nil
+}
+
+// Resolve 将别名替换为 target:///name，客户端通过target的resolver解析真实地址并监听配置变化
+// 不是别名时原样返回
+func Resolve(addr string) (string, error) {
+	name, ok, err := Parse(addr)
+	if err != nil || !ok {
+		return addr, err
+	}
+	return Scheme + ":///" + name, nil
+}
+
+// SplitScheme 拆分真实地址的scheme，没有scheme时返回空，例如 grpc://user.prod.svc:9002 返回 grpc、user.prod.svc:9002
+func SplitScheme(addr string) (scheme string, rest string) {
+	if idx := strings.Index(addr, "://"); idx > 0 {
+		return addr[:idx], addr[idx+3:]
+	}
+	return "", addr
+}
+
+// Watcher 监听别名对应地址的变化
+type Watcher struct {
+	name   string
+	fn     func(addr string)
+	mu     sync.Mutex
+	addr   string
+	closed bool
+}
+
+// Watch 监听别名，配置热更新后地址变化时回调fn，别名被删除时保留原来的地址
+func Watch(name string, fn func(addr string)) *Watcher {
+	addr, _ := Lookup(name)
+	w := &Watcher{name: name, fn: fn, addr: addr}
+	econf.OnChange(func(conf *econf.Configuration) {
+		w.reload(conf.GetStringMapString(ConfigKey)[w.name])
+	})
+	return w
+}
+
+// Addr 返回当前的地址
+func (w *Watcher) Addr() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.addr
+}
+
+// Close 停止监听
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
+
+func (w *Watcher) reload(addr string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed || addr == "" || addr == w.addr {
+		return
+	}
+	w.addr = addr
+	w.fn(addr)
+}
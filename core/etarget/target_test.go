@@ -0,0 +1,95 @@
+package etarget
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+// memoryDataSource 内存中的配置，修改content后调用 econf.Reload
+type memoryDataSource struct {
+	content string
+}
+
+func (m *memoryDataSource) Parse(string, bool) econf.ConfigType { return econf.ConfigTypeToml }
+func (m *memoryDataSource) ReadConfig() ([]byte, error)         { return []byte(m.content), nil }
+func (m *memoryDataSource) IsConfigChanged() <-chan struct{}    { return nil }
+func (m *memoryDataSource) Close() error                        { return nil }
+
+func TestResolve(t *testing.T) {
+	econf.Reset()
+	defer econf.Reset()
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(`
+[targets]
+user-service = "grpc://user.prod.svc:9002"
+`), toml.Unmarshal))
+
+	addr, ok := Lookup("user-service")
+	assert.True(t, ok)
+	assert.Equal(t, "grpc://user.prod.svc:9002", addr)
+
+	for in, out := range map[string]string{
+		"user-service":           "target:///user-service",
+		"target:///user-service": "target:///user-service",
+		"target://user-service":  "target:///user-service",
+		"127.0.0.1:9002":         "127.0.0.1:9002",
+		"etcd:///svc-user":       "etcd:///svc-user",
+	} {
+		got, err := Resolve(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, out, got, in)
+	}
+	_, err := Resolve("target:///order-service")
+	assert.ErrorContains(t, err, `target "order-service" not found`)
+
+	scheme, rest := SplitScheme("grpc://user.prod.svc:9002")
+	assert.Equal(t, "grpc", scheme)
+	assert.Equal(t, "user.prod.svc:9002", rest)
+	scheme, rest = SplitScheme("user.prod.svc:9002")
+	assert.Equal(t, "", scheme)
+	assert.Equal(t, "user.prod.svc:9002", rest)
+}
+
+func TestWatch(t *testing.T) {
+	econf.Reset()
+	defer econf.Reset()
+	ds := &memoryDataSource{content: `
+[targets]
+user-service = "grpc://user.test.svc:9002"
+`}
+	assert.NoError(t, econf.LoadFromDataSource(ds, toml.Unmarshal))
+
+	changed := make(chan string, 10)
+	w := Watch("user-service", func(addr string) { changed <- addr })
+	assert.Equal(t, "grpc://user.test.svc:9002", w.Addr())
+
+	ds.content = `
+[targets]
+user-service = "grpc://user.prod.svc:9002"
+`
+	assert.NoError(t, econf.Reload())
+	select {
+	case addr := <-changed:
+		assert.Equal(t, "grpc://user.prod.svc:9002", addr)
+	case <-time.After(time.Second):
+		t.Fatal("target not changed")
+	}
+	assert.Equal(t, "grpc://user.prod.svc:9002", w.Addr())
+
+	// 别名删除后保留原来的地址，关闭后不再回调
+	ds.content = `foo = "bar"`
+	assert.NoError(t, econf.Reload())
+	assert.Equal(t, "grpc://user.prod.svc:9002", w.Addr())
+	w.Close()
+	ds.content = `
+[targets]
+user-service = "grpc://user.gray.svc:9002"
+`
+	assert.NoError(t, econf.Reload())
+	assert.Empty(t, changed)
+}
@@ -0,0 +1,96 @@
+package etrace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// VerbosityNone 只记录错误和span状态
+	VerbosityNone = "none"
+	// VerbosityBasic 在none的基础上记录慢调用、重试和熔断拒绝事件，默认
+	VerbosityBasic = "basic"
+	// VerbosityDetailed 在basic的基础上记录错误的堆栈
+	VerbosityDetailed = "detailed"
+)
+
+const (
+	verbosityNone int32 = iota
+	verbosityBasic
+	verbosityDetailed
+)
+
+var verbosity atomic.Int32
+
+func init() {
+	verbosity.Store(verbosityBasic)
+}
+
+// SetVerbosity 设置服务端、客户端自动记录到span上的信息，支持none、basic、detailed
+func SetVerbosity(v string) error {
+	switch strings.ToLower(v) {
+	case VerbosityNone:
+		verbosity.Store(verbosityNone)
+	case VerbosityBasic, "":
+		verbosity.Store(verbosityBasic)
+	case VerbosityDetailed:
+		verbosity.Store(verbosityDetailed)
+	default:
+		return fmt.Errorf("unknown span verbosity %q", v)
+	}
+	return nil
+}
+
+// RecordError 记录错误并将span状态设置为Error，detailed时记录错误的堆栈
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	if verbosity.Load() >= verbosityDetailed {
+		span.RecordError(err, trace.WithStackTrace(true))
+	} else {
+		span.RecordError(err)
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// RecordSlow 调用耗时超过阈值时，添加slow事件，threshold小于等于0时不记录
+func RecordSlow(span trace.Span, cost, threshold time.Duration) {
+	if threshold <= 0 || cost <= threshold || verbosity.Load() < verbosityBasic {
+		return
+	}
+	span.SetAttributes(attribute.Bool("ego.slow", true))
+	span.AddEvent("slow", trace.WithAttributes(
+		attribute.Float64("ego.cost_ms", float64(cost.Microseconds())/1000),
+		attribute.Float64("ego.threshold_ms", float64(threshold.Microseconds())/1000),
+	))
+}
+
+// AddRetryEvent 在ctx的span上添加retry事件，attempt为第几次重试，reason为上一次调用失败的原因
+func AddRetryEvent(ctx context.Context, attempt int, reason string) {
+	if verbosity.Load() < verbosityBasic {
+		return
+	}
+	trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+		attribute.Int("ego.retry.attempt", attempt),
+		attribute.String("ego.retry.reason", reason),
+	))
+}
+
+// AddRejectEvent 在ctx的span上添加reject事件，记录熔断器或者舱壁拒绝调用的原因
+func AddRejectEvent(ctx context.Context, name string, reason string) {
+	if verbosity.Load() < verbosityBasic {
+		return
+	}
+	trace.SpanFromContext(ctx).AddEvent("reject", trace.WithAttributes(
+		attribute.String("ego.reject.name", name),
+		attribute.String("ego.reject.reason", reason),
+	))
+}
@@ -0,0 +1,61 @@
+package etrace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAnnotate(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	defer func() { _ = SetVerbosity(VerbosityBasic) }()
+
+	assert.Error(t, SetVerbosity("verbose"))
+	for _, v := range []string{VerbosityNone, VerbosityBasic, VerbosityDetailed} {
+		require.NoError(t, SetVerbosity(v))
+		ctx, span := tracer.Start(context.Background(), v)
+		RecordError(span, nil)
+		RecordError(span, errors.New("boom"))
+		RecordSlow(span, time.Second, 500*time.Millisecond)
+		RecordSlow(span, time.Second, 0)
+		AddRetryEvent(ctx, 1, "Service Unavailable")
+		AddRejectEvent(ctx, "user", "breaker_open")
+		span.End()
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	events := func(i int) []string {
+		names := make([]string, 0)
+		for _, e := range spans[i].Events() {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	for _, span := range spans {
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Equal(t, "boom", span.Status().Description)
+	}
+	assert.Equal(t, []string{"exception"}, events(0))
+	assert.Equal(t, []string{"exception", "slow", "retry", "reject"}, events(1))
+	assert.Equal(t, []string{"exception", "slow", "retry", "reject"}, events(2))
+
+	hasStack := func(i int) bool {
+		for _, attr := range spans[i].Events()[0].Attributes {
+			if attr.Key == "exception.stacktrace" {
+				return true
+			}
+		}
+		return false
+	}
+	assert.False(t, hasStack(1))
+	assert.True(t, hasStack(2))
+}
@@ -2,13 +2,10 @@ package otel
 
 import (
 	"context"
-	//lint:ignore SA1019
-	jaegerv2 "go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/gotomicro/ego/core/eapp"
@@ -21,11 +18,19 @@ import (
 type Config struct {
 	ServiceName  string
 	OtelType     string  // type: otlp ,jaeger
-	Fraction     float64 // 采样率： 默认0不会采集
+	Fraction     float64 `unit:"percent"` // 采样率： 默认0不会采集
 	PanicOnError bool
-	options      []tracesdk.TracerProviderOption
-	Jaeger       jaegerConfig // otel jaeger 配置
-	Otlp         otlpConfig   // otel otlp 配置
+	// 是否给所有span附加 deployment.environment、service.instance.id 等标准属性，默认开启
+	EnableStandardAttributes bool
+	Attributes               map[string]string // 所有span都会附加的属性
+	RedactAttributes         []string          // 上报前需要脱敏的属性key，例如 http.url
+	Propagators              []string          // 链路信息的传递格式，支持tracecontext、baggage、b3、b3multi、jaeger，默认tracecontext、baggage
+	SpanVerbosity            string            // 服务端、客户端自动记录到span上的信息，none只记录错误，basic还记录慢调用、重试和熔断事件，detailed还记录错误堆栈，默认basic
+	options                  []tracesdk.TracerProviderOption
+	processors               []tracesdk.SpanProcessor
+	hooks                    []AttributeHook
+	Jaeger                   jaegerConfig // otel jaeger 配置
+	Otlp                     otlpConfig   // otel otlp 配置
 }
 
 // otlpConfig otlp上报协议配置
@@ -73,8 +78,11 @@ func DefaultConfig() *Config {
 			Endpoint:       ienv.EnvOrStr("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
 			EnableInsecure: true,
 		},
-		OtelType:     "otlp",
-		PanicOnError: true,
+		Propagators:              []string{"tracecontext", "baggage"},
+		SpanVerbosity:            "basic",
+		OtelType:                 "otlp",
+		PanicOnError:             true,
+		EnableStandardAttributes: true,
 	}
 }
 
@@ -112,44 +120,6 @@ func (config *Config) Build(options ...Option) trace.TracerProvider {
 	return nil
 }
 
-func (config *Config) buildJaegerTP() trace.TracerProvider {
-	var endpoint jaegerv2.EndpointOption
-	switch config.Jaeger.EndpointType {
-	case "agent":
-		// Create the Jaeger exporter
-		endpoint = jaegerv2.WithAgentEndpoint(
-			jaegerv2.WithAgentHost(config.Jaeger.AgentHost),
-			jaegerv2.WithAgentPort(config.Jaeger.AgentPort),
-		)
-	case "collector":
-		endpoint = jaegerv2.WithCollectorEndpoint(
-			jaegerv2.WithEndpoint(config.Jaeger.CollectorEndpoint),
-			jaegerv2.WithUsername(config.Jaeger.CollectorUser),
-			jaegerv2.WithPassword(config.Jaeger.CollectorPassword),
-		)
-	default:
-		elog.Panic("jaeger type error", elog.FieldName(config.Jaeger.EndpointType))
-	}
-
-	exp, err := jaegerv2.New(endpoint)
-	if err != nil {
-		return nil
-	}
-	options := []tracesdk.TracerProviderOption{
-		// Set the sampling rate based on the parent span to 100%
-		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(config.Fraction))),
-		// Always be sure to batch in production.
-		tracesdk.WithBatcher(exp),
-		// Record information about this application in a Resource.
-		tracesdk.WithResource(resource.NewSchemaless(
-			semconv.ServiceNameKey.String(config.ServiceName),
-		)),
-	}
-	options = append(options, config.options...)
-	tp := tracesdk.NewTracerProvider(options...)
-	return tp
-}
-
 func (config *Config) buildOtlpTP() trace.TracerProvider {
 	// otlp exporter
 	options := []otlptracegrpc.Option{
@@ -165,20 +135,19 @@ func (config *Config) buildOtlpTP() trace.TracerProvider {
 	options = append(options, config.Otlp.options...)
 	traceClient := otlptracegrpc.NewClient(options...)
 	ctx := context.Background()
-	traceExp, err := otlptrace.New(ctx, traceClient)
+	otlpExp, err := otlptrace.New(ctx, traceClient)
 	if err != nil {
 		elog.Error("otlp exporter error", elog.FieldErr(err))
 		return nil
 	}
+	traceExp, spanOptions := config.spanOptions(otlpExp)
 
 	// res
 	resOptions := []resource.Option{
 		resource.WithTelemetrySDK(), // WithTelemetrySDK adds TelemetrySDK version info to the configured resource.
 		resource.WithHost(),         // WithHost adds attributes from the host to the configured resource.
-		resource.WithAttributes(
-			// the service name used to display traces in backends
-			semconv.ServiceNameKey.String(config.ServiceName),
-		),
+		// the service name used to display traces in backends, and the application resource
+		resource.WithAttributes(config.resourceAttributes()...),
 	}
 	resOptions = append(resOptions, config.Otlp.resOptions...)
 	res, err := resource.New(ctx, resOptions...)
@@ -196,6 +165,7 @@ func (config *Config) buildOtlpTP() trace.TracerProvider {
 		// Record information about this application in a Resource.
 		tracesdk.WithResource(res),
 	}
+	tpOptions = append(tpOptions, spanOptions...)
 	tpOptions = append(tpOptions, config.options...)
 	tp := tracesdk.NewTracerProvider(tpOptions...)
 	return tp
@@ -0,0 +1,51 @@
+//go:build !ego_nojaeger
+
+package otel
+
+import (
+	//lint:ignore SA1019
+	jaegerv2 "go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func (config *Config) buildJaegerTP() trace.TracerProvider {
+	var endpoint jaegerv2.EndpointOption
+	switch config.Jaeger.EndpointType {
+	case "agent":
+		// Create the Jaeger exporter
+		endpoint = jaegerv2.WithAgentEndpoint(
+			jaegerv2.WithAgentHost(config.Jaeger.AgentHost),
+			jaegerv2.WithAgentPort(config.Jaeger.AgentPort),
+		)
+	case "collector":
+		endpoint = jaegerv2.WithCollectorEndpoint(
+			jaegerv2.WithEndpoint(config.Jaeger.CollectorEndpoint),
+			jaegerv2.WithUsername(config.Jaeger.CollectorUser),
+			jaegerv2.WithPassword(config.Jaeger.CollectorPassword),
+		)
+	default:
+		elog.Panic("jaeger type error", elog.FieldName(config.Jaeger.EndpointType))
+	}
+
+	jaegerExp, err := jaegerv2.New(endpoint)
+	if err != nil {
+		return nil
+	}
+	exp, spanOptions := config.spanOptions(jaegerExp)
+	options := []tracesdk.TracerProviderOption{
+		// Set the sampling rate based on the parent span to 100%
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(config.Fraction))),
+		// Always be sure to batch in production.
+		tracesdk.WithBatcher(exp),
+		// Record information about this application in a Resource.
+		tracesdk.WithResource(resource.NewSchemaless(config.resourceAttributes()...)),
+	}
+	options = append(options, spanOptions...)
+	options = append(options, config.options...)
+	tp := tracesdk.NewTracerProvider(options...)
+	return tp
+}
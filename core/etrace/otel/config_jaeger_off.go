@@ -0,0 +1,15 @@
+//go:build ego_nojaeger
+
+package otel
+
+import (
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// buildJaegerTP 使用ego_nojaeger构建时没有jaeger exporter
+func (config *Config) buildJaegerTP() trace.TracerProvider {
+	elog.Panic("otel type jaeger is not supported, binary is built with tag ego_nojaeger")
+	return nil
+}
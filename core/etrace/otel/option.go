@@ -1,4 +1,22 @@
 package otel
 
+import (
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
 // Option overrides a Container's default configuration.
 type Option func(c *Config)
+
+// WithSpanProcessor 注册span processor，可以在span创建、结束时补充属性
+func WithSpanProcessor(processors ...tracesdk.SpanProcessor) Option {
+	return func(c *Config) {
+		c.processors = append(c.processors, processors...)
+	}
+}
+
+// WithAttributeHook 注册上报前的属性处理函数，用于脱敏或者补充属性，按照注册顺序执行
+func WithAttributeHook(hooks ...AttributeHook) Option {
+	return func(c *Config) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
@@ -0,0 +1,122 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+// redactedValue 脱敏后的属性值
+const redactedValue = "******"
+
+// AttributeHook 在span上报前处理属性，可以用于脱敏或者补充属性，返回新的属性列表
+type AttributeHook func(span tracesdk.ReadOnlySpan, attrs []attribute.KeyValue) []attribute.KeyValue
+
+// standardAttributes 所有span都会附加的标准属性，值为空时不附加
+func (config *Config) standardAttributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(config.Attributes)+5)
+	add := func(key attribute.Key, value string) {
+		if value != "" {
+			attrs = append(attrs, key.String(value))
+		}
+	}
+	if config.EnableStandardAttributes {
+		res := eapp.GetResource()
+		add(semconv.DeploymentEnvironmentKey, res.Env)
+		add(semconv.ServiceInstanceIDKey, res.InstanceID)
+		add(semconv.ServiceVersionKey, res.ServiceVersion)
+		add(semconv.CloudRegionKey, res.Region)
+		add(semconv.CloudAvailabilityZoneKey, res.Zone)
+	}
+	for key, value := range config.Attributes {
+		add(attribute.Key(key), value)
+	}
+	return attrs
+}
+
+// resourceAttributes 链路资源的属性，服务名使用配置中的ServiceName
+func (config *Config) resourceAttributes() []attribute.KeyValue {
+	res := eapp.GetResource()
+	res.ServiceName = config.ServiceName
+	attrs := res.Attributes()
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, key := range res.Keys() {
+		out = append(out, attribute.String(key, attrs[key]))
+	}
+	return out
+}
+
+// attributeProcessor 在span创建时附加全局属性
+type attributeProcessor struct {
+	attrs []attribute.KeyValue
+}
+
+func (p *attributeProcessor) OnStart(_ context.Context, s tracesdk.ReadWriteSpan) {
+	s.SetAttributes(p.attrs...)
+}
+
+func (p *attributeProcessor) OnEnd(tracesdk.ReadOnlySpan)      {}
+func (p *attributeProcessor) Shutdown(context.Context) error   { return nil }
+func (p *attributeProcessor) ForceFlush(context.Context) error { return nil }
+
+// hookExporter 上报前执行AttributeHook
+type hookExporter struct {
+	tracesdk.SpanExporter
+	hooks []AttributeHook
+}
+
+func (e *hookExporter) ExportSpans(ctx context.Context, spans []tracesdk.ReadOnlySpan) error {
+	processed := make([]tracesdk.ReadOnlySpan, 0, len(spans))
+	for _, span := range spans {
+		attrs := span.Attributes()
+		for _, hook := range e.hooks {
+			attrs = hook(span, attrs)
+		}
+		stub := tracetest.SpanStubFromReadOnlySpan(span)
+		stub.Attributes = attrs
+		processed = append(processed, stub.Snapshot())
+	}
+	return e.SpanExporter.ExportSpans(ctx, processed)
+}
+
+// redactHook 对指定key的属性脱敏
+func redactHook(keys []string) AttributeHook {
+	redact := make(map[attribute.Key]struct{}, len(keys))
+	for _, key := range keys {
+		redact[attribute.Key(key)] = struct{}{}
+	}
+	return func(_ tracesdk.ReadOnlySpan, attrs []attribute.KeyValue) []attribute.KeyValue {
+		out := make([]attribute.KeyValue, len(attrs))
+		for i, attr := range attrs {
+			if _, ok := redact[attr.Key]; ok {
+				attr = attr.Key.String(redactedValue)
+			}
+			out[i] = attr
+		}
+		return out
+	}
+}
+
+// spanOptions 返回附加属性、自定义processor的TracerProvider选项，并包装exporter
+func (config *Config) spanOptions(exp tracesdk.SpanExporter) (tracesdk.SpanExporter, []tracesdk.TracerProviderOption) {
+	options := make([]tracesdk.TracerProviderOption, 0, len(config.processors)+1)
+	if attrs := config.standardAttributes(); len(attrs) > 0 {
+		options = append(options, tracesdk.WithSpanProcessor(&attributeProcessor{attrs: attrs}))
+	}
+	for _, processor := range config.processors {
+		options = append(options, tracesdk.WithSpanProcessor(processor))
+	}
+	hooks := config.hooks
+	if len(config.RedactAttributes) > 0 {
+		hooks = append([]AttributeHook{redactHook(config.RedactAttributes)}, hooks...)
+	}
+	if len(hooks) > 0 {
+		exp = &hookExporter{SpanExporter: exp, hooks: hooks}
+	}
+	return exp, options
+}
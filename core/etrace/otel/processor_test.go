@@ -0,0 +1,41 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+func TestSpanOptions(t *testing.T) {
+	config := DefaultConfig()
+	config.Attributes = map[string]string{"team": "infra"}
+	config.RedactAttributes = []string{"http.url"}
+	WithAttributeHook(func(span tracesdk.ReadOnlySpan, attrs []attribute.KeyValue) []attribute.KeyValue {
+		return append(attrs, attribute.String("span.name", span.Name()))
+	})(config)
+
+	memory := tracetest.NewInMemoryExporter()
+	exp, options := config.spanOptions(memory)
+	tp := tracesdk.NewTracerProvider(append(options, tracesdk.WithSyncer(exp))...)
+	_, span := tp.Tracer("test").Start(context.Background(), "GET /users")
+	span.SetAttributes(attribute.String("http.url", "/users?token=secret"))
+	span.End()
+
+	spans := memory.GetSpans()
+	assert.Len(t, spans, 1)
+	attrs := make(map[attribute.Key]string)
+	for _, attr := range spans[0].Attributes {
+		attrs[attr.Key] = attr.Value.AsString()
+	}
+	assert.Equal(t, eapp.AppInstance(), attrs[semconv.ServiceInstanceIDKey])
+	assert.Equal(t, "infra", attrs["team"])
+	assert.Equal(t, redactedValue, attrs["http.url"])
+	assert.Equal(t, "GET /users", attrs["span.name"])
+}
@@ -0,0 +1,43 @@
+package etrace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Inject 按照 Propagator 的格式将ctx中的链路信息写入消息头，生产者发送消息前调用
+// 例如 etrace.Inject(ctx, propagation.MapCarrier(msg.Headers))
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	Propagator().Inject(ctx, carrier)
+}
+
+// Extract 从消息头读取生产者的链路信息，消费者创建span前调用，使消费span成为生产span的子span
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return Propagator().Extract(ctx, carrier)
+}
+
+// Links 从每条消息头中读取生产者的链路信息，生成span links，用于批量消费
+// 批量消费的多条消息可能来自不同的trace，不能都作为父span，使用link关联
+func Links(carriers ...propagation.TextMapCarrier) []trace.Link {
+	links := make([]trace.Link, 0, len(carriers))
+	for _, carrier := range carriers {
+		spanCtx := trace.SpanContextFromContext(Propagator().Extract(context.Background(), carrier))
+		if !spanCtx.IsValid() {
+			continue
+		}
+		links = append(links, trace.Link{SpanContext: spanCtx})
+	}
+	return links
+}
+
+// StartBatch 批量消费时创建span，span通过link关联每条消息的生产者span
+func (t *Tracer) StartBatch(ctx context.Context, operation string, carriers []propagation.TextMapCarrier, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	opts = append(opts,
+		trace.WithLinks(Links(carriers...)...),
+		trace.WithAttributes(attribute.Int("messaging.batch.message_count", len(carriers))),
+	)
+	return t.Start(ctx, operation, nil, opts...)
+}
@@ -0,0 +1,45 @@
+package etrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newSpanContext(b byte) trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{b, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		SpanID:     trace.SpanID{b, 1, 2, 3, 4, 5, 6, 7},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestInjectExtract(t *testing.T) {
+	spanCtx := newSpanContext(1)
+	headers := propagation.MapCarrier{}
+	Inject(trace.ContextWithSpanContext(context.Background(), spanCtx), headers)
+	assert.Equal(t, "00-010102030405060708090a0b0c0d0e0f-0101020304050607-01", headers.Get("traceparent"))
+
+	got := trace.SpanContextFromContext(Extract(context.Background(), headers))
+	assert.Equal(t, spanCtx.TraceID(), got.TraceID())
+	assert.Equal(t, spanCtx.SpanID(), got.SpanID())
+	assert.True(t, got.IsRemote())
+}
+
+func TestLinks(t *testing.T) {
+	carriers := make([]propagation.TextMapCarrier, 0, 3)
+	for _, b := range []byte{1, 2} {
+		headers := propagation.MapCarrier{}
+		Inject(trace.ContextWithSpanContext(context.Background(), newSpanContext(b)), headers)
+		carriers = append(carriers, headers)
+	}
+	// 没有链路信息的消息不生成link
+	carriers = append(carriers, propagation.MapCarrier{})
+
+	links := Links(carriers...)
+	assert.Len(t, links, 2)
+	assert.Equal(t, newSpanContext(2).TraceID(), links[1].SpanContext.TraceID())
+}
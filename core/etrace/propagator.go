@@ -0,0 +1,247 @@
+package etrace
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// PropagatorTraceContext W3C tracecontext，请求头traceparent、tracestate
+	PropagatorTraceContext = "tracecontext"
+	// PropagatorBaggage W3C baggage，请求头baggage
+	PropagatorBaggage = "baggage"
+	// PropagatorB3 Zipkin B3单请求头格式，请求头b3
+	PropagatorB3 = "b3"
+	// PropagatorB3Multi Zipkin B3多请求头格式，请求头x-b3-traceid、x-b3-spanid等
+	PropagatorB3Multi = "b3multi"
+	// PropagatorJaeger Jaeger格式，请求头uber-trace-id
+	PropagatorJaeger = "jaeger"
+)
+
+type propagatorHolder struct {
+	propagator propagation.TextMapPropagator
+}
+
+var globalPropagator atomic.Pointer[propagatorHolder]
+
+func init() {
+	globalPropagator.Store(&propagatorHolder{propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})})
+}
+
+// NewPropagator 按照名称组合链路信息的传递格式，注入时写入所有格式，提取时依次解析，后面的格式解析成功时覆盖前面的结果
+// 支持 tracecontext、baggage、b3、b3multi、jaeger
+func NewPropagator(names ...string) (propagation.TextMapPropagator, error) {
+	propagators := make([]propagation.TextMapPropagator, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, B3{})
+		case PropagatorB3Multi:
+			propagators = append(propagators, B3{MultipleHeader: true})
+		case PropagatorJaeger:
+			propagators = append(propagators, Jaeger{})
+		default:
+			return nil, fmt.Errorf("unknown trace propagator %q", name)
+		}
+	}
+	if len(propagators) == 0 {
+		return nil, fmt.Errorf("trace propagators is empty")
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+// SetPropagator 设置所有服务端、客户端使用的链路信息传递格式
+func SetPropagator(p propagation.TextMapPropagator) {
+	globalPropagator.Store(&propagatorHolder{propagator: p})
+	otel.SetTextMapPropagator(p)
+}
+
+// Propagator 返回当前的链路信息传递格式，默认为 tracecontext、baggage
+func Propagator() propagation.TextMapPropagator {
+	return globalPropagator.Load().propagator
+}
+
+const (
+	b3ContextHeader      = "b3"
+	b3TraceIDHeader      = "x-b3-traceid"
+	b3SpanIDHeader       = "x-b3-spanid"
+	b3SampledHeader      = "x-b3-sampled"
+	b3ParentSpanIDHeader = "x-b3-parentspanid"
+	b3FlagsHeader        = "x-b3-flags"
+	jaegerHeader         = "uber-trace-id"
+)
+
+// B3 Zipkin B3格式，提取时同时支持单请求头和多请求头格式，注入时按照MultipleHeader选择格式
+// https://github.com/openzipkin/b3-propagation
+type B3 struct {
+	MultipleHeader bool
+}
+
+var _ propagation.TextMapPropagator = B3{}
+
+// Inject ...
+func (b B3) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	if b.MultipleHeader {
+		carrier.Set(b3TraceIDHeader, sc.TraceID().String())
+		carrier.Set(b3SpanIDHeader, sc.SpanID().String())
+		carrier.Set(b3SampledHeader, sampled)
+		return
+	}
+	carrier.Set(b3ContextHeader, sc.TraceID().String()+"-"+sc.SpanID().String()+"-"+sampled)
+}
+
+// Extract ...
+func (b B3) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	var (
+		sc  trace.SpanContext
+		err error
+	)
+	if value := carrier.Get(b3ContextHeader); value != "" {
+		sc, err = parseB3Single(value)
+	} else {
+		sc, err = parseB3Multi(carrier.Get(b3TraceIDHeader), carrier.Get(b3SpanIDHeader), carrier.Get(b3SampledHeader), carrier.Get(b3FlagsHeader))
+	}
+	if err != nil || !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields ...
+func (b B3) Fields() []string {
+	if b.MultipleHeader {
+		return []string{b3TraceIDHeader, b3SpanIDHeader, b3SampledHeader, b3ParentSpanIDHeader, b3FlagsHeader}
+	}
+	return []string{b3ContextHeader}
+}
+
+// parseB3Single 解析 {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}，后两段可以省略
+func parseB3Single(value string) (trace.SpanContext, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, fmt.Errorf("invalid b3 header %q", value)
+	}
+	sampled := ""
+	if len(parts) > 2 {
+		sampled = parts[2]
+	}
+	flags := ""
+	if sampled == "d" {
+		sampled, flags = "", "1"
+	}
+	return parseB3Multi(parts[0], parts[1], sampled, flags)
+}
+
+func parseB3Multi(traceID, spanID, sampled, flags string) (trace.SpanContext, error) {
+	tid, err := parseTraceID(traceID)
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return trace.SpanContext{}, err
+	}
+	config := trace.SpanContextConfig{TraceID: tid, SpanID: sid, Remote: true}
+	// debug标记也表示采样
+	if flags == "1" || sampled == "1" || strings.EqualFold(sampled, "true") {
+		config.TraceFlags = trace.FlagsSampled
+	}
+	return trace.NewSpanContext(config), nil
+}
+
+// Jaeger Jaeger格式，请求头为 uber-trace-id: {trace-id}:{span-id}:{parent-span-id}:{flags}
+// https://www.jaegertracing.io/docs/client-libraries/#propagation-format
+type Jaeger struct{}
+
+var _ propagation.TextMapPropagator = Jaeger{}
+
+// Inject ...
+func (Jaeger) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	flags := "0"
+	if sc.IsSampled() {
+		flags = "1"
+	}
+	carrier.Set(jaegerHeader, sc.TraceID().String()+":"+sc.SpanID().String()+":0:"+flags)
+}
+
+// Extract ...
+func (Jaeger) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	value := carrier.Get(jaegerHeader)
+	if value == "" {
+		return ctx
+	}
+	// 部分客户端会对请求头做URL编码
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		value = unescaped
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return ctx
+	}
+	tid, err := parseTraceID(parts[0])
+	if err != nil {
+		return ctx
+	}
+	sid, err := trace.SpanIDFromHex(leftPad(parts[1], 16))
+	if err != nil {
+		return ctx
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return ctx
+	}
+	config := trace.SpanContextConfig{TraceID: tid, SpanID: sid, Remote: true}
+	// 第1位表示采样，第2位表示debug
+	if flags&0x3 != 0 {
+		config.TraceFlags = trace.FlagsSampled
+	}
+	sc := trace.NewSpanContext(config)
+	if !sc.IsValid() {
+		return ctx
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields ...
+func (Jaeger) Fields() []string {
+	return []string{jaegerHeader}
+}
+
+// parseTraceID 兼容64位的trace id，左边补0
+func parseTraceID(traceID string) (trace.TraceID, error) {
+	if len(traceID) > 32 {
+		return trace.TraceID{}, fmt.Errorf("invalid trace id %q", traceID)
+	}
+	return trace.TraceIDFromHex(leftPad(traceID, 32))
+}
+
+func leftPad(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat("0", n-len(s)) + s
+}
@@ -0,0 +1,82 @@
+package etrace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPropagator(t *testing.T) {
+	_, err := NewPropagator("tracecontext", "zipkin")
+	assert.ErrorContains(t, err, "zipkin")
+	_, err = NewPropagator()
+	assert.Error(t, err)
+
+	p, err := NewPropagator("tracecontext", "baggage", "b3", "b3multi", "jaeger")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage", "b3", "x-b3-traceid", "x-b3-spanid", "x-b3-sampled", "x-b3-parentspanid", "x-b3-flags", "uber-trace-id"}, p.Fields())
+
+	// 注入时写入所有格式
+	header := http.Header{}
+	p.Inject(trace.ContextWithSpanContext(context.Background(), newSpanContext(1)), propagation.HeaderCarrier(header))
+	assert.Equal(t, "00-010102030405060708090a0b0c0d0e0f-0101020304050607-01", header.Get("traceparent"))
+	assert.Equal(t, "010102030405060708090a0b0c0d0e0f-0101020304050607-1", header.Get("b3"))
+	assert.Equal(t, "010102030405060708090a0b0c0d0e0f", header.Get("X-B3-TraceId"))
+	assert.Equal(t, "010102030405060708090a0b0c0d0e0f:0101020304050607:0:1", header.Get("uber-trace-id"))
+
+	old := Propagator()
+	defer SetPropagator(old)
+	SetPropagator(p)
+	got := trace.SpanContextFromContext(Extract(context.Background(), propagation.HeaderCarrier(http.Header{"X-B3-Traceid": {"0102030405060708"}, "X-B3-Spanid": {"0102030405060708"}, "X-B3-Sampled": {"1"}})))
+	assert.Equal(t, "00000000000000000102030405060708", got.TraceID().String())
+	assert.True(t, got.IsSampled())
+}
+
+func TestB3(t *testing.T) {
+	tests := []struct {
+		header  string
+		traceID string
+		sampled bool
+	}{
+		{header: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90", traceID: "80f198ee56343ba864fe8b2a57d3eff7", sampled: true},
+		{header: "64fe8b2a57d3eff7-e457b5a2e4d86bd1-d", traceID: "000000000000000064fe8b2a57d3eff7", sampled: true},
+		{header: "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1", traceID: "80f198ee56343ba864fe8b2a57d3eff7"},
+		{header: "0"},
+		{header: "invalid-e457b5a2e4d86bd1-1"},
+	}
+	for _, tt := range tests {
+		sc := trace.SpanContextFromContext(B3{}.Extract(context.Background(), propagation.MapCarrier{"b3": tt.header}))
+		if tt.traceID == "" {
+			assert.False(t, sc.IsValid(), tt.header)
+			continue
+		}
+		assert.Equal(t, tt.traceID, sc.TraceID().String(), tt.header)
+		assert.Equal(t, "e457b5a2e4d86bd1", sc.SpanID().String(), tt.header)
+		assert.Equal(t, tt.sampled, sc.IsSampled(), tt.header)
+		assert.True(t, sc.IsRemote())
+	}
+}
+
+func TestJaeger(t *testing.T) {
+	carrier := propagation.MapCarrier{"uber-trace-id": "64fe8b2a57d3eff7%3Ae457b5a2e4d86bd1%3A0%3A3"}
+	sc := trace.SpanContextFromContext(Jaeger{}.Extract(context.Background(), carrier))
+	assert.Equal(t, "000000000000000064fe8b2a57d3eff7", sc.TraceID().String())
+	assert.Equal(t, "e457b5a2e4d86bd1", sc.SpanID().String())
+	assert.True(t, sc.IsSampled())
+
+	sc = trace.SpanContextFromContext(Jaeger{}.Extract(context.Background(), propagation.MapCarrier{"uber-trace-id": "64fe8b2a57d3eff7:e457b5a2e4d86bd1:0:0"}))
+	assert.True(t, sc.IsValid())
+	assert.False(t, sc.IsSampled())
+
+	sc = trace.SpanContextFromContext(Jaeger{}.Extract(context.Background(), propagation.MapCarrier{"uber-trace-id": "broken"}))
+	assert.False(t, sc.IsValid())
+
+	out := propagation.MapCarrier{}
+	Jaeger{}.Inject(trace.ContextWithSpanContext(context.Background(), newSpanContext(2)), out)
+	assert.Equal(t, "020102030405060708090a0b0c0d0e0f:0201020304050607:0:1", out.Get("uber-trace-id"))
+}
@@ -26,7 +26,7 @@ var (
 func SetGlobalTracer(tp trace.TracerProvider) {
 	globalTracer = registeredTracer{true}
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetTextMapPropagator(Propagator())
 }
 
 // IsGlobalTracerRegistered returns a `bool` to indicate if a tracer has been globally registered
@@ -55,9 +55,7 @@ type Tracer struct {
 
 // NewTracer create tracer instance
 func NewTracer(kind trace.SpanKind, opts ...Option) *Tracer {
-	op := options{
-		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}),
-	}
+	op := options{}
 	for _, o := range opts {
 		o(&op)
 	}
@@ -67,18 +65,26 @@ func NewTracer(kind trace.SpanKind, opts ...Option) *Tracer {
 // Start tracing span
 func (t *Tracer) Start(ctx context.Context, operation string, carrier propagation.TextMapCarrier, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	if (t.kind == trace.SpanKindServer || t.kind == trace.SpanKindConsumer) && carrier != nil {
-		ctx = t.opt.propagator.Extract(ctx, carrier)
+		ctx = t.propagator().Extract(ctx, carrier)
 	}
 	opts = append(opts, trace.WithSpanKind(t.kind))
 
 	ctx, span := t.tracer.Start(ctx, operation, opts...)
 
 	if (t.kind == trace.SpanKindClient || t.kind == trace.SpanKindProducer) && carrier != nil {
-		t.opt.propagator.Inject(ctx, carrier)
+		t.propagator().Inject(ctx, carrier)
 	}
 	return ctx, span
 }
 
+// propagator 没有通过Option设置时，使用 SetPropagator 设置的全局格式
+func (t *Tracer) propagator() propagation.TextMapPropagator {
+	if t.opt.propagator != nil {
+		return t.opt.propagator
+	}
+	return Propagator()
+}
+
 type options struct {
 	propagator propagation.TextMapPropagator
 }
@@ -86,6 +92,13 @@ type options struct {
 // Option is tracing option.
 type Option func(*options)
 
+// WithPropagator 设置该Tracer使用的链路信息传递格式
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagator = p
+	}
+}
+
 // CustomTag ...
 func CustomTag(key string, val string) attribute.KeyValue {
 	return attribute.String(key, val)
@@ -0,0 +1,172 @@
+package etxn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// PackageName 包名
+const PackageName = "core.etxn"
+
+// Hook 事务提交后执行的回调，例如发送outbox消息、删除缓存
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	fn   Hook
+}
+
+// Tx 数据库事务，注册的回调在事务提交成功后按照注册顺序执行，回滚时丢弃
+type Tx struct {
+	*sql.Tx
+	hooks []namedHook
+}
+
+// AfterCommit 注册事务提交后执行的回调
+func (t *Tx) AfterCommit(name string, fn Hook) {
+	t.hooks = append(t.hooks, namedHook{name: name, fn: fn})
+}
+
+type txKey struct{}
+
+// FromContext 返回ctx中正在执行的事务
+func FromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*Tx)
+	return tx, ok
+}
+
+// Component 事务组件
+type Component struct {
+	name   string
+	config *Config
+	db     *sql.DB
+	logger *elog.Component
+	tracer *etrace.Tracer
+}
+
+func newComponent(name string, config *Config, db *sql.DB, logger *elog.Component) *Component {
+	return &Component{
+		name:   name,
+		config: config,
+		db:     db,
+		logger: logger,
+		tracer: etrace.NewTracer(trace.SpanKindInternal),
+	}
+}
+
+// AfterCommit 在ctx中的事务提交后执行回调，ctx中没有事务时立即执行
+// 业务代码不需要关心自己是否在事务中，例如删除缓存的函数可以同时在事务内外调用
+func (c *Component) AfterCommit(ctx context.Context, name string, fn Hook) {
+	if tx, ok := FromContext(ctx); ok {
+		tx.AfterCommit(name, fn)
+		return
+	}
+	c.runHook(ctx, namedHook{name: name, fn: fn})
+}
+
+// Do 在事务中执行fn，fn返回错误或者panic时回滚，否则提交，提交成功后执行注册的回调
+// ctx中已经有事务时加入该事务，回调在最外层事务提交后执行
+// 回调失败不影响已经提交的事务，只记录日志和监控，回调需要保证幂等，或者由outbox补偿
+func (c *Component) Do(ctx context.Context, fn func(ctx context.Context, tx *Tx) error, opts ...*sql.TxOptions) (err error) {
+	if tx, ok := FromContext(ctx); ok {
+		return fn(ctx, tx)
+	}
+	var txOpts *sql.TxOptions
+	if len(opts) > 0 {
+		txOpts = opts[0]
+	}
+	sqlTx, err := c.db.BeginTx(ctx, txOpts)
+	if err != nil {
+		c.metric("begin_error")
+		return fmt.Errorf("etxn: begin fail, %w", err)
+	}
+	tx := &Tx{Tx: sqlTx}
+	defer func() {
+		if rec := recover(); rec != nil {
+			_ = sqlTx.Rollback()
+			c.metric("rollback")
+			panic(rec)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			c.logger.Error("rollback fail", elog.FieldErr(rbErr))
+		}
+		c.metric("rollback")
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		c.metric("commit_error")
+		return fmt.Errorf("etxn: commit fail, %w", err)
+	}
+	c.metric("commit")
+	for _, hook := range tx.hooks {
+		c.runHook(ctx, hook)
+	}
+	return nil
+}
+
+// runHook 在子span中执行回调，失败时重试
+// 事务已经提交，调用方的ctx可能在回调执行前取消，因此回调使用独立的超时
+func (c *Component) runHook(ctx context.Context, hook namedHook) {
+	ctx = context.WithoutCancel(ctx)
+	var span trace.Span
+	if c.config.EnableTrace {
+		ctx, span = c.tracer.Start(ctx, "etxn.hook "+hook.name, nil, trace.WithAttributes(attribute.String("etxn.hook", hook.name)))
+		defer span.End()
+	}
+	var err error
+	for i := 0; i <= c.config.HookRetries; i++ {
+		if i > 0 {
+			time.Sleep(c.config.RetryBackoff)
+		}
+		if err = c.callHook(ctx, hook); err == nil {
+			c.hookMetric(hook.name, "ok")
+			return
+		}
+		c.logger.Warn("after commit hook fail", elog.FieldName(hook.name), elog.Int("retry", i), elog.FieldErr(err))
+	}
+	c.hookMetric(hook.name, "error")
+	c.logger.Error("after commit hook fail, give up", elog.FieldName(hook.name), elog.FieldErr(err))
+	if span != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func (c *Component) callHook(ctx context.Context, hook namedHook) (err error) {
+	if c.config.HookTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.HookTimeout)
+		defer cancel()
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return hook.fn(ctx)
+}
+
+func (c *Component) metric(code string) {
+	if c.config.EnableMetric {
+		emetric.TxnCounter.Inc(c.name, code)
+	}
+}
+
+func (c *Component) hookMetric(hook, code string) {
+	if c.config.EnableMetric {
+		emetric.TxnHookCounter.Inc(c.name, hook, code)
+	}
+}
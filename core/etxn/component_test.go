@@ -0,0 +1,138 @@
+package etxn
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+// fakeDB 记录事务的提交和回滚
+type fakeDB struct {
+	events    []string
+	commitErr error
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *fakeConn) Commit() error {
+	c.db.events = append(c.db.events, "commit")
+	return c.db.commitErr
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.events = append(c.db.events, "rollback")
+	return nil
+}
+
+func newTestComponent(t *testing.T, name string) (*Component, *fakeDB) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+	c := DefaultContainer()
+	c.name = name
+	c.config.RetryBackoff = 0
+	return c.Build(WithDB(db)), fake
+}
+
+func TestCommitHooks(t *testing.T) {
+	comp, fake := newTestComponent(t, "commit")
+	ctx := context.Background()
+	err := comp.Do(ctx, func(ctx context.Context, tx *Tx) error {
+		tx.AfterCommit("outbox", func(ctx context.Context) error {
+			fake.events = append(fake.events, "outbox")
+			return nil
+		})
+		// 嵌套调用加入外层事务
+		return comp.Do(ctx, func(ctx context.Context, inner *Tx) error {
+			assert.Same(t, tx, inner)
+			comp.AfterCommit(ctx, "cache", func(ctx context.Context) error {
+				fake.events = append(fake.events, "cache")
+				return nil
+			})
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"commit", "outbox", "cache"}, fake.events)
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.TxnCounter.WithLabelValues("commit", "commit")))
+
+	// 没有事务时立即执行
+	fake.events = nil
+	comp.AfterCommit(ctx, "cache", func(ctx context.Context) error {
+		fake.events = append(fake.events, "cache")
+		return nil
+	})
+	assert.Equal(t, []string{"cache"}, fake.events)
+}
+
+func TestRollback(t *testing.T) {
+	comp, fake := newTestComponent(t, "rollback")
+	errBiz := errors.New("insufficient balance")
+	err := comp.Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		tx.AfterCommit("outbox", func(ctx context.Context) error {
+			t.Fatal("hook should not run after rollback")
+			return nil
+		})
+		return errBiz
+	})
+	assert.ErrorIs(t, err, errBiz)
+	assert.Equal(t, []string{"rollback"}, fake.events)
+
+	assert.Panics(t, func() {
+		_ = comp.Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []string{"rollback", "rollback"}, fake.events)
+
+	fake.events = nil
+	fake.commitErr = errors.New("deadlock")
+	err = comp.Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		tx.AfterCommit("outbox", func(ctx context.Context) error {
+			t.Fatal("hook should not run after commit fail")
+			return nil
+		})
+		return nil
+	})
+	assert.ErrorContains(t, err, "deadlock")
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.TxnCounter.WithLabelValues("rollback", "commit_error")))
+}
+
+func TestHookRetry(t *testing.T) {
+	comp, _ := newTestComponent(t, "retry")
+	var calls int
+	err := comp.Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		tx.AfterCommit("flaky", func(ctx context.Context) error {
+			if calls++; calls < 2 {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		})
+		tx.AfterCommit("broken", func(ctx context.Context) error {
+			panic("boom")
+		})
+		return nil
+	})
+	// 回调失败不影响已经提交的事务
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.TxnHookCounter.WithLabelValues("retry", "flaky", "ok")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.TxnHookCounter.WithLabelValues("retry", "broken", "error")))
+}
@@ -0,0 +1,27 @@
+package etxn
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 事务配置
+type Config struct {
+	HookTimeout  time.Duration // 每个提交后回调的超时时间，默认5s
+	HookRetries  int           // 提交后回调失败时的重试次数，默认2
+	RetryBackoff time.Duration // 重试的等待时间，默认100ms
+	EnableTrace  bool          // 是否开启链路追踪，默认开启
+	EnableMetric bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		HookTimeout:  xtime.Duration("5s"),
+		HookRetries:  2,
+		RetryBackoff: xtime.Duration("100ms"),
+		EnableTrace:  true,
+		EnableMetric: true,
+	}
+}
@@ -0,0 +1,57 @@
+package etxn
+
+import (
+	"database/sql"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// Container 容器
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	db     *sql.DB
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// WithDB 设置数据库连接
+func WithDB(db *sql.DB) Option {
+	return func(c *Container) {
+		c.db = db
+	}
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.db == nil {
+		c.logger.Panic("etxn db can not be nil, use WithDB option")
+	}
+	return newComponent(c.name, c.config, c.db, c.logger)
+}
@@ -2,16 +2,26 @@ package ego
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gotomicro/ego/client/ecloudevents"
+	"github.com/gotomicro/ego/client/enotify"
+	"github.com/gotomicro/ego/core/econf"
 	// econf/file package should be imported first
 	_ "github.com/gotomicro/ego/core/econf/file"
 	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/core/ehooks"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/core/estophook"
+	"github.com/gotomicro/ego/core/standard"
 	"github.com/gotomicro/ego/core/util/xcycle"
 	"github.com/gotomicro/ego/core/util/xtime"
 	"github.com/gotomicro/ego/server"
@@ -40,13 +50,29 @@ type Ego struct {
 	orderServers []server.OrderServer // 有顺序的服务，需要监听health。成功后，才启动下一步
 	crons        []ecron.Ecron        // 定时任务
 	jobs         map[string]ejob.Ejob // 短时任务
+	phaseJobs    []PhaseJob           // 生命周期任务
 	registerer   eregistry.Registry   // 注册中心
+	stopHooks    *estophook.Component // 停止阶段的外部钩子
+
+	depends      map[standard.Component][]standard.Component // 服务、定时任务、短时任务的启动依赖
+	graph        *dependGraph                                // 由depends生成，Run时创建
+	declaredJobs map[standard.Component]bool                 // 通过Job注册的所有短时任务，包括没有通过 --job 指定的
+
+	healthNames []string // Run时注册的健康检查，Run返回后取消注册
+
+	diagnosticsOnce sync.Once // 诊断包只生成一次
 
 	// 第三部分 可选方法
 	opts opts
 
 	// stopStartTime
 	stopInfo stopInfo
+	stopping uint32        // 是否已经开始停止
+	stopDone chan struct{} // 停止完成后关闭
+	stopErr  error         // 停止过程中的聚合错误
+
+	check     *configChecker // --config-check 模式下的检查结果
+	upgrading uint32         // 是否正在热升级
 }
 type stopInfo struct {
 	stopStartTime  time.Time
@@ -58,12 +84,18 @@ type opts struct {
 	configPrefix      string          // 配置前缀
 	hang              bool            // 是否悬挂
 	disableBanner     bool            // 禁用banner
+	banner            string          // banner模板
 	disableFlagConfig bool            // 禁用flag config
 	beforeStopClean   []func() error  // 运行停止前清理
 	afterStopClean    []func() error  // 运行停止后清理
 	stopTimeout       time.Duration   // 运行停止超时时间
 	shutdownSignals   []os.Signal
-	arguments         []string // 命令行参数
+	upgradeSignals    []os.Signal      // 热升级信号量，为nil时由 ego.upgrade.enable 决定是否监听默认的SIGUSR2
+	upgradeTimeout    time.Duration    // 热升级等待子进程报告的超时时间
+	arguments         []string         // 命令行参数
+	admissionChecks   []AdmissionCheck // 服务注册前的准入检查
+	dependsOnTimeout  time.Duration    // 等待依赖就绪的超时时间
+	returnError       bool             // 由NewE创建，初始化失败时返回错误，不panic、不退出进程
 }
 
 // New new Ego
@@ -84,6 +116,7 @@ func New(options ...Option) *Ego {
 		crons:      make([]ecron.Ecron, 0),
 		jobs:       make(map[string]ejob.Ejob),
 		registerer: eregistry.Nop{},
+		stopDone:   make(chan struct{}),
 
 		// 第三部分 可选方法
 		opts: opts{
@@ -94,7 +127,9 @@ func New(options ...Option) *Ego {
 			afterStopClean:  make([]func() error, 0),
 			stopTimeout:     xtime.Duration("5s"),
 			shutdownSignals: shutdownSignals,
+			upgradeTimeout:  xtime.Duration("30s"),
 			arguments:       os.Args[1:],
+			banner:          DefaultBanner,
 		},
 	}
 
@@ -104,9 +139,18 @@ func New(options ...Option) *Ego {
 		options = append(options, WithBeforeStopClean(e.registerer.Close))
 	}
 
+	// 执行组件注册的停止前钩子
+	options = append(options, WithBeforeStopClean(func() error {
+		return ehooks.Do(ehooks.StageBeforeStop)
+	}))
+
 	// 设置运行后清理函数
-	// 设置清理日志函数
-	options = append(options, WithAfterStopClean(elog.DefaultLogger.Flush, elog.EgoLogger.Flush))
+	// 执行组件注册的停止后钩子，设置清理日志函数
+	options = append(options, WithAfterStopClean(func() error {
+		return ehooks.Do(ehooks.StageAfterStop)
+	}, func() error {
+		return e.runStopHooks(context.Background(), estophook.PhaseCleanupDone)
+	}, elog.DefaultLogger.Flush, elog.EgoLogger.Flush))
 
 	// 设置参数
 	for _, option := range options {
@@ -119,17 +163,36 @@ func New(options ...Option) *Ego {
 
 	// 设置初始函数
 	e.inits = []func() error{
-		e.parseFlags,
-		e.printBanner,
 		// printLogger,
-		loadConfig,
+		e.initPlugins,
+		e.loadConfig,
+		e.initDiagnostics,
 		initMaxProcs,
 		e.initLogger,
+		e.initExtensions,
 		e.initTracer,
 		e.initSentinel,
+		e.initMaintenance,
+		e.initNotify,
+		e.initCloudEvents,
+		e.initStatsd,
+		e.initMetricExporters,
+		e.initStopHooks,
+		e.initAdminActions,
 	}
 
 	// 初始化系统函数
+	if e.err = e.parseFlags(); e.err != nil {
+		return e
+	}
+	// 配置检查模式下记录每个函数的结果，不中断初始化
+	if eflag.Bool("config-check") {
+		e.check = newConfigChecker(eflag.Bool("config-check-strict"))
+		for _, fn := range e.inits {
+			e.check.run("init", fn)
+		}
+		return e
+	}
 	e.err = runSerialFuncReturnError(e.inits)
 	return e
 }
@@ -141,14 +204,34 @@ func (e *Ego) Invoker(fns ...func() error) *Ego {
 
 	e.invokers = append(e.invokers, fns...)
 
+	if e.check != nil {
+		for _, fn := range fns {
+			e.check.run("invoker", fn)
+		}
+		return e
+	}
+
 	// 初始化用户函数
 	e.err = runSerialFuncReturnError(e.invokers)
 	return e
 }
 
 // Registry 设置注册中心
-func (e *Ego) Registry(reg eregistry.Registry) *Ego {
-	e.registerer = reg
+// 传入多个注册中心时，服务会同时注册到所有注册中心，部分失败的策略由 ego.registry.policy 配置
+// 需要查看每个注册中心的状态时，可以直接传入 eregistry.NewMulti
+func (e *Ego) Registry(regs ...eregistry.Registry) *Ego {
+	if len(regs) == 1 {
+		e.registerer = regs[0]
+		return e
+	}
+	options := []eregistry.MultiOption{eregistry.WithPolicy(eregistry.PolicyAll)}
+	if policy := econf.GetString("ego.registry.policy"); policy != "" {
+		options = append(options, eregistry.WithPolicy(eregistry.Policy(policy)))
+	}
+	for i, reg := range regs {
+		options = append(options, eregistry.WithRegistry(strconv.Itoa(i), reg))
+	}
+	e.registerer = eregistry.NewMulti(options...)
 	return e
 }
 
@@ -157,6 +240,7 @@ func (e *Ego) Serve(s ...server.Server) *Ego {
 	e.smu.Lock()
 	defer e.smu.Unlock()
 	e.servers = append(e.servers, s...)
+	e.checkServers()
 	return e
 }
 
@@ -165,6 +249,7 @@ func (e *Ego) OrderServe(s ...server.OrderServer) *Ego {
 	e.smu.Lock()
 	defer e.smu.Unlock()
 	e.orderServers = append(e.orderServers, s...)
+	e.checkServers()
 	return e
 }
 
@@ -176,6 +261,13 @@ func (e *Ego) Cron(w ...ecron.Ecron) *Ego {
 
 // Job 设置短时任务
 func (e *Ego) Job(runners ...ejob.Ejob) *Ego {
+	if e.declaredJobs == nil {
+		e.declaredJobs = make(map[standard.Component]bool)
+	}
+	for _, runner := range runners {
+		e.declaredJobs[runner] = true
+	}
+
 	// start job by name
 	jobFlag := eflag.String("job")
 	if jobFlag == "" {
@@ -210,17 +302,44 @@ func (e *Ego) Job(runners ...ejob.Ejob) *Ego {
 
 // Run 运行程序
 func (e *Ego) Run() error {
+	// 配置检查模式下不启动服务，输出报告后退出
+	if e.check != nil {
+		osExit(e.runConfigCheck(os.Stdout))
+		return nil
+	}
 	if e.err != nil {
+		e.dumpDiagnostics("init fail: " + e.err.Error())
 		runSerialFuncLogError(e.opts.afterStopClean)
 		return e.err
 	}
+	graph, order, err := e.newDependGraph()
+	if err != nil {
+		runSerialFuncLogError(e.opts.afterStopClean)
+		return err
+	}
+	e.smu.Lock()
+	e.graph = graph
+	e.smu.Unlock()
+	e.logDependOrder(order)
+
 	// 如果存在短时任务，那么只执行短时任务
 	// 如果没有order server，说明job在前面执行
 	if len(e.jobs) > 0 && len(e.orderServers) == 0 {
+		e.printStartup(os.Stdout, eflag.Bool("startup-json"))
 		return e.startJobs()
 	}
 
+	// 服务启动前的任务失败时不启动服务
+	if err := e.runPhaseJobs(e.ctx, JobPhasePreStart); err != nil {
+		runSerialFuncLogError(e.opts.afterStopClean)
+		return err
+	}
+
+	e.registerHealth()
+	defer e.unregisterHealth()
 	e.waitSignals() // start signal listen task in goroutine
+	e.waitUpgradeSignals()
+	ecloudevents.Emit(ecloudevents.TypeStarted, nil)
 
 	// 当没有job，才启动服务
 	if len(e.jobs) == 0 {
@@ -232,73 +351,190 @@ func (e *Ego) Run() error {
 	// job情况不需要执行下面的操作
 	// order server执行有问题的也需要stop
 	if isNeedStop {
+		if err != nil {
+			enotify.Alert("ego run fail", err.Error())
+			ecloudevents.Emit(ecloudevents.TypeCrashed, map[string]string{"error": err.Error()})
+			e.dumpDiagnostics("run fail: " + err.Error())
+		}
 		return err
 	}
 
 	// 启动定时任务
 	_ = e.startCrons()
+	e.printStartup(os.Stdout, eflag.Bool("startup-json"))
+	if path := eflag.String("addr-file"); path != "" {
+		if err := e.writeAddrFile(path); err != nil {
+			e.logger.Error("write addr file fail", elog.FieldComponent("app"), elog.FieldErr(err))
+		}
+	}
+
+	if ecloudevents.Default() != nil {
+		ecloudevents.Emit(ecloudevents.TypeReady, e.StartupSummary())
+	}
+
+	// 服务健康后执行的任务
+	e.startPostStartJobs()
 
 	// 阻塞，等待信号量
-	if err := <-e.cycle.Wait(e.opts.hang); err != nil {
-		e.logger.Error("Ego shutdown with error", elog.FieldComponent("app"), elog.FieldErr(err), elog.FieldCost(time.Since(e.stopInfo.stopStartTime)), zap.Bool("grace", e.stopInfo.isGracefulStop), zap.String("stopTimeout", e.opts.stopTimeout.String()))
+	err = <-e.cycle.Wait(e.opts.hang)
+	// 服务停止后执行的任务
+	stopCtx, cancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
+	err = errors.Join(err, e.runPhaseJobs(stopCtx, JobPhaseOnStopped))
+	cancel()
+	info := e.getStopInfo()
+	if err != nil {
+		enotify.Alert("ego shutdown with error", err.Error())
+		ecloudevents.Emit(ecloudevents.TypeStopped, map[string]string{"error": err.Error()})
+		e.dumpDiagnostics("shutdown with error: " + err.Error())
+		e.logger.Error("Ego shutdown with error", elog.FieldComponent("app"), elog.FieldErr(err), elog.FieldCost(time.Since(info.stopStartTime)), zap.Bool("grace", info.isGracefulStop), zap.String("stopTimeout", e.opts.stopTimeout.String()))
 		runSerialFuncLogError(e.opts.afterStopClean)
 		return err
 	}
-	e.logger.Info("stop ego, bye!", elog.FieldComponent("app"), elog.FieldCost(time.Since(e.stopInfo.stopStartTime)), zap.Bool("grace", e.stopInfo.isGracefulStop), zap.String("stopTimeout", e.opts.stopTimeout.String()))
+	e.logger.Info("stop ego, bye!", elog.FieldComponent("app"), elog.FieldCost(time.Since(info.stopStartTime)), zap.Bool("grace", info.isGracefulStop), zap.String("stopTimeout", e.opts.stopTimeout.String()))
+	ecloudevents.Emit(ecloudevents.TypeStopped, nil)
 	// 运行停止后清理
 	runSerialFuncLogError(e.opts.afterStopClean)
 	return nil
 }
 
 // Stop 停止程序
+// Stop 是幂等的，只有第一次调用会执行停止流程，后续调用（包括在停止流程中的重入调用）会直接返回
+// 组件停止时的错误由Run返回，如需获取聚合错误请使用Shutdown
 func (e *Ego) Stop(ctx context.Context, isGraceful bool) (err error) {
+	if !atomic.CompareAndSwapUint32(&e.stopping, 0, 1) {
+		return nil
+	}
+	defer close(e.stopDone)
+
+	e.smu.Lock()
+	e.stopInfo = stopInfo{
+		stopStartTime:  time.Now(),
+		isGracefulStop: isGraceful,
+	}
+	e.smu.Unlock()
+	go e.watchStopTimeout(ctx)
+	e.stopErr = e.stop(ctx, isGraceful)
+	return nil
+}
+
+// Shutdown 优雅停止程序，可以多次、并发调用
+// 如果停止流程还没开始，会触发优雅停止；然后阻塞直到停止流程结束或者ctx超时，返回停止过程中的聚合错误
+// 停止流程使用 stopTimeout，不受调用方ctx影响，调用方ctx超时后停止流程仍会继续
+// 可以和信号量触发的停止同时使用，例如治理端触发的重启
+func (e *Ego) Shutdown(ctx context.Context) error {
+	if atomic.LoadUint32(&e.stopping) == 0 {
+		go func() {
+			stopCtx, cancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
+			defer cancel()
+			_ = e.Stop(stopCtx, true)
+		}()
+	}
+
+	select {
+	case <-e.stopDone:
+		return e.stopErr
+	case <-ctx.Done():
+		return fmt.Errorf("ego shutdown, err: %w", context.Cause(ctx))
+	}
+}
+
+// getStopInfo 停止流程开始时记录的信息
+func (e *Ego) getStopInfo() stopInfo {
+	e.smu.RLock()
+	defer e.smu.RUnlock()
+	return e.stopInfo
+}
+
+func (e *Ego) stop(ctx context.Context, isGraceful bool) error {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	// 记录每个组件停止时的错误，同时把错误交给cycle，由Run返回
+	collect := func(fn func() error) func() error {
+		return func() error {
+			err := fn()
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			return err
+		}
+	}
+
+	ecloudevents.Emit(ecloudevents.TypeStopping, map[string]bool{"graceful": isGraceful})
+
+	// 开始停止，先执行外部钩子，例如从外部负载均衡摘除实例
+	if err := e.runStopHooks(ctx, estophook.PhaseDrainStart); err != nil {
+		errs = append(errs, err)
+	}
+
 	// 运行停止前清理
-	runSerialFuncLogError(e.opts.beforeStopClean)
+	if err := runSerialFuncLogError(e.opts.beforeStopClean); err != nil {
+		errs = append(errs, err)
+	}
 
-	// 停止服务
+	// 停止服务，等待依赖的服务不再启动
 	e.smu.RLock()
+	e.graph.stop()
 	if isGraceful {
 		for _, s := range e.servers {
-			func(s server.Server) {
-				// todo
-				e.cycle.Run(func() error {
-					return s.GracefulStop(ctx)
-				})
-			}(s)
+			s := s
+			if e.graph.skipped(s) {
+				continue
+			}
+			e.cycle.Run(collect(func() error {
+				return s.GracefulStop(ctx)
+			}))
 		}
 		for _, s := range e.orderServers {
-			func(s server.OrderServer) {
-				// todo
-				e.cycle.Run(func() error {
-					return s.GracefulStop(ctx)
-				})
-			}(s)
+			s := s
+			e.cycle.Run(collect(func() error {
+				return s.GracefulStop(ctx)
+			}))
 		}
 	} else {
 		for _, s := range e.servers {
-			func(s server.Server) {
-				e.cycle.Run(s.Stop)
-			}(s)
+			if e.graph.skipped(s) {
+				continue
+			}
+			e.cycle.Run(collect(s.Stop))
 		}
 		for _, s := range e.orderServers {
-			func(s server.OrderServer) {
-				e.cycle.Run(s.Stop)
-			}(s)
+			e.cycle.Run(collect(s.Stop))
 		}
 	}
-	e.smu.RLock()
+	e.smu.RUnlock()
 
 	// 停止定时任务
 	for _, w := range e.crons {
-		func(w ecron.Ecron) {
-			e.cycle.Run(w.Stop)
-		}(w)
+		if e.graph.skipped(w) {
+			continue
+		}
+		e.cycle.Run(collect(w.Stop))
 	}
 	<-e.cycle.Done()
+	if err := e.runStopHooks(ctx, estophook.PhaseServersStopped); err != nil {
+		errs = append(errs, err)
+	}
+
+	// 服务停止后不再有新的数据，使用剩余的停止时间写入组件缓冲的数据
+	flushCtx := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		flushCtx, cancel = context.WithTimeout(ctx, e.opts.stopTimeout)
+		defer cancel()
+	}
+	if err := e.flushComponents(flushCtx); err != nil {
+		errs = append(errs, err)
+	}
 
 	// cancel 所有服务
 	e.cancel()
 	e.cycle.Close()
 
-	return err
+	mu.Lock()
+	defer mu.Unlock()
+	return errors.Join(errs...)
 }
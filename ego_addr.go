@@ -0,0 +1,131 @@
+package ego
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+var envNameReplacer = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// ServerInfos 返回所有服务的信息，服务初始化之后地址为实际监听的地址，端口配置为0时可以通过该方法获取系统分配的端口
+func (e *Ego) ServerInfos() []server.ServiceInfo {
+	infos := make([]server.ServiceInfo, 0)
+	e.rangeServers(func(_, _ string, info *server.ServiceInfo) {
+		infos = append(infos, *info)
+	})
+	return infos
+}
+
+// rangeServers 按照注册顺序遍历服务和Order服务
+func (e *Ego) rangeServers(fn func(name, packageName string, info *server.ServiceInfo)) {
+	e.smu.RLock()
+	defer e.smu.RUnlock()
+	for _, s := range e.servers {
+		fn(s.Name(), s.PackageName(), s.Info())
+	}
+	for _, s := range e.orderServers {
+		fn(s.Name(), s.PackageName(), s.Info())
+	}
+}
+
+// writeAddrFile 把服务实际监听的地址写入env格式的文件，便于测试程序和同机部署的进程获取端口
+// 例如服务名称为 server.http 时写入 SERVER_HTTP_ADDR=127.0.0.1:36271 和 SERVER_HTTP_PORT=36271
+func (e *Ego) writeAddrFile(path string) error {
+	var buf strings.Builder
+	e.rangeServers(func(name, _ string, info *server.ServiceInfo) {
+		if info.Address == "" {
+			return
+		}
+		name = strings.Trim(envNameReplacer.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+		fmt.Fprintf(&buf, "%s_ADDR=%s\n", name, info.Address)
+		if _, port, err := net.SplitHostPort(info.Address); err == nil {
+			fmt.Fprintf(&buf, "%s_PORT=%s\n", name, port)
+		}
+	})
+	// 先写临时文件再重命名，读取方不会读到写了一半的文件
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0o644); err != nil {
+		return fmt.Errorf("write addr file fail, %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename addr file fail, %w", err)
+	}
+	return nil
+}
+
+// checkServers 设置服务时检测冲突，冲突时Run返回错误，调用方需要持有smu
+func (e *Ego) checkServers() {
+	var names, addrs []string
+	add := func(s interface{ Name() string }) {
+		names = append(names, s.Name())
+		addr := ""
+		if a, ok := s.(interface{ Address() string }); ok {
+			addr = a.Address()
+		}
+		addrs = append(addrs, addr)
+	}
+	for _, s := range e.servers {
+		add(s)
+	}
+	for _, s := range e.orderServers {
+		add(s)
+	}
+	if err := checkServerConflicts(names, addrs); err != nil && e.err == nil {
+		e.logger.Error("server conflict", elog.FieldComponent("app"), elog.FieldErr(err))
+		e.err = err
+	}
+}
+
+// checkServerConflicts 检测服务名称和监听地址的冲突，同类型的多个服务需要使用不同的配置key和地址
+// 只检测实现了 Address() string 的服务，端口为0时由系统分配，不会冲突
+func checkServerConflicts(names []string, addrs []string) error {
+	seenNames := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, ok := seenNames[name]; ok {
+			return fmt.Errorf("duplicate server name %s, use different config keys for servers of the same type", name)
+		}
+		seenNames[name] = struct{}{}
+	}
+	for i := range addrs {
+		for j := 0; j < i; j++ {
+			if addrConflict(addrs[i], addrs[j]) {
+				return fmt.Errorf("server %s and %s listen on conflicting address %s and %s", names[j], names[i], addrs[j], addrs[i])
+			}
+		}
+	}
+	return nil
+}
+
+// addrConflict 端口相同并且host相同或者其中一个监听所有网卡时冲突，不是host:port格式的地址（例如unix socket）相同时冲突
+func addrConflict(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	hostA, portA, errA := net.SplitHostPort(a)
+	hostB, portB, errB := net.SplitHostPort(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	if portA != portB || portA == "0" {
+		return false
+	}
+	return hostA == hostB || isUnspecifiedHost(hostA) || isUnspecifiedHost(hostB)
+}
+
+func isUnspecifiedHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
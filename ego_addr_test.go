@@ -0,0 +1,43 @@
+package ego
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAddrFile(t *testing.T) {
+	app := New(WithDisableBanner(true))
+	app.Serve(&addrServer{addr: "127.0.0.1:36271"}, &addrServer{name: "server.worker"})
+	infos := app.ServerInfos()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, "127.0.0.1:36271", infos[0].Address)
+
+	path := filepath.Join(t.TempDir(), "ports.env")
+	assert.NoError(t, app.writeAddrFile(path))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	// 没有地址的服务不写入
+	assert.Equal(t, "TEST_SERVER_ADDR=127.0.0.1:36271\nTEST_SERVER_PORT=36271\n", string(data))
+	assert.Equal(t, 36271, app.StartupSummary().Servers[0].Port)
+}
+
+func TestCheckServerConflicts(t *testing.T) {
+	assert.NoError(t, checkServerConflicts(
+		[]string{"server.http", "server.admin", "server.grpc", "server.test"},
+		[]string{"0.0.0.0:9001", "127.0.0.1:9002", "127.0.0.1:0", "127.0.0.1:0"},
+	))
+	assert.EqualError(t, checkServerConflicts([]string{"server.http", "server.http"}, []string{"", ""}),
+		"duplicate server name server.http, use different config keys for servers of the same type")
+	assert.EqualError(t, checkServerConflicts([]string{"server.http", "server.admin"}, []string{"0.0.0.0:9001", "127.0.0.1:9001"}),
+		"server server.http and server.admin listen on conflicting address 0.0.0.0:9001 and 127.0.0.1:9001")
+	assert.Error(t, checkServerConflicts([]string{"server.a", "server.b"}, []string{"/tmp/ego.sock", "/tmp/ego.sock"}))
+	assert.NoError(t, checkServerConflicts([]string{"server.a", "server.b"}, []string{"10.0.0.1:9001", "10.0.0.2:9001"}))
+
+	// 冲突时Run返回错误
+	app := New(WithDisableBanner(true))
+	app.Serve(&addrServer{name: "server.http", addr: ":9001"}).Serve(&addrServer{name: "server.admin", addr: ":9001"})
+	assert.EqualError(t, app.Run(), "server server.http and server.admin listen on conflicting address :9001 and :9001")
+}
@@ -0,0 +1,123 @@
+package ego
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/efeature"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/util/xcolor"
+	"github.com/gotomicro/ego/server"
+)
+
+// DefaultBanner 默认的banner模板，变量参考 StartupSummary
+const DefaultBanner = `
+    _/_/_/_/    _/_/_/    _/_/
+   _/        _/        _/    _/
+  _/_/_/    _/  _/_/  _/    _/
+ _/        _/    _/  _/    _/
+_/_/_/_/    _/_/_/    _/_/
+
+Welcome to Ego, starting application {{.App}} ...
+version: {{.AppVersion}}, mode: {{.Mode}}, ego: {{.EgoVersion}}
+features: {{range $i, $f := .Features}}{{if $i}}, {{end}}{{$f}}{{end}}
+{{- range .Servers}}
+ => {{.Name}} {{.Scheme}}://{{.Address}}
+{{- end}}
+`
+
+// StartupSummary 启动摘要，--startup-json 时以JSON格式输出到标准输出，便于编排脚本获取监听地址和版本
+type StartupSummary struct {
+	App        string             `json:"app"`
+	AppVersion string             `json:"appVersion"`
+	EgoVersion string             `json:"egoVersion"`
+	GoVersion  string             `json:"goVersion"`
+	Mode       string             `json:"mode"`
+	Region     string             `json:"region"`
+	Zone       string             `json:"zone"`
+	HostName   string             `json:"hostName"`
+	Pid        int                `json:"pid"`
+	StartTime  string             `json:"startTime"`
+	Servers    []StartupServer    `json:"servers"`
+	Components []StartupComponent `json:"components"`
+	Features   []string           `json:"features"` // 编译进二进制的可选集成，参考 efeature
+}
+
+// StartupServer 启动的服务
+type StartupServer struct {
+	Name        string `json:"name"`
+	PackageName string `json:"packageName"`
+	Scheme      string `json:"scheme"`
+	Address     string `json:"address"`
+	Port        int    `json:"port"` // 实际监听的端口，端口配置为0时为系统分配的端口
+}
+
+// StartupComponent 创建的组件，组件都属于ego，版本为ego的版本
+type StartupComponent struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
+
+// StartupSummary 返回启动摘要，服务的地址在服务初始化之后才是实际监听的地址
+func (e *Ego) StartupSummary() StartupSummary {
+	summary := StartupSummary{
+		App:        eapp.Name(),
+		AppVersion: eapp.AppVersion(),
+		EgoVersion: eapp.EgoVersion(),
+		GoVersion:  eapp.GoVersion(),
+		Mode:       eapp.AppMode(),
+		Region:     eapp.AppRegion(),
+		Zone:       eapp.AppZone(),
+		HostName:   eapp.HostName(),
+		Pid:        os.Getpid(),
+		StartTime:  eapp.StartTime(),
+		Servers:    make([]StartupServer, 0),
+		Components: make([]StartupComponent, 0),
+		Features:   efeature.List(),
+	}
+	e.rangeServers(func(name, packageName string, info *server.ServiceInfo) {
+		item := StartupServer{Name: name, PackageName: packageName, Scheme: info.Scheme, Address: info.Address}
+		if _, port, err := net.SplitHostPort(info.Address); err == nil {
+			item.Port, _ = strconv.Atoi(port)
+		}
+		summary.Servers = append(summary.Servers, item)
+	})
+	for _, info := range ecomponent.List() {
+		summary.Components = append(summary.Components, StartupComponent{Name: info.Name, Type: info.Type, Version: eapp.EgoVersion()})
+	}
+	return summary
+}
+
+// printStartup 服务启动后输出banner，开启 --startup-json 时只输出JSON格式的启动摘要，避免banner影响解析
+func (e *Ego) printStartup(w io.Writer, asJSON bool) {
+	summary := e.StartupSummary()
+	if asJSON {
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			e.logger.Error("print startup summary fail", elog.FieldComponent("app"), elog.FieldErr(err))
+		}
+		return
+	}
+	if e.opts.disableBanner {
+		return
+	}
+	tpl, err := template.New("banner").Parse(e.opts.banner)
+	if err != nil {
+		e.logger.Error("parse banner fail", elog.FieldComponent("app"), elog.FieldErr(err))
+		return
+	}
+	buf := &strings.Builder{}
+	if err := tpl.Execute(buf, summary); err != nil {
+		e.logger.Error("render banner fail", elog.FieldComponent("app"), elog.FieldErr(err))
+		return
+	}
+	fmt.Fprintln(w, xcolor.Blue(buf.String()))
+}
@@ -0,0 +1,55 @@
+package ego
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/efeature"
+	"github.com/gotomicro/ego/server"
+)
+
+type addrServer struct {
+	testServer
+	name string
+	addr string
+}
+
+func (s *addrServer) Name() string {
+	if s.name == "" {
+		return s.testServer.Name()
+	}
+	return s.name
+}
+
+func (s *addrServer) Address() string {
+	return s.addr
+}
+
+func (s *addrServer) Info() *server.ServiceInfo {
+	return &server.ServiceInfo{Scheme: "http", Address: s.addr}
+}
+
+func TestPrintStartup(t *testing.T) {
+	app := New(WithBanner("{{.App}}{{range .Servers}} {{.Scheme}}://{{.Address}}{{end}}"))
+	app.Serve(&addrServer{addr: "127.0.0.1:9001"})
+
+	var buf bytes.Buffer
+	app.printStartup(&buf, false)
+	assert.Contains(t, buf.String(), " http://127.0.0.1:9001")
+
+	// 输出JSON格式的启动摘要时不输出banner
+	buf.Reset()
+	app.printStartup(&buf, true)
+	var summary StartupSummary
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &summary))
+	assert.Equal(t, []StartupServer{{Name: "test_server", PackageName: "server", Scheme: "http", Address: "127.0.0.1:9001", Port: 9001}}, summary.Servers)
+	assert.Equal(t, efeature.List(), summary.Features)
+
+	app.opts.banner = "{{.Unknown"
+	buf.Reset()
+	app.printStartup(&buf, false)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,139 @@
+package ego
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/core/util/xcolor"
+	"github.com/gotomicro/ego/core/util/xstring"
+)
+
+const (
+	checkStatusOK   = "ok"
+	checkStatusWarn = "warn"
+	checkStatusFail = "fail"
+)
+
+// osExit 配置检查结束后退出进程，测试时替换
+var osExit = os.Exit
+
+// checkResult 配置检查中单个步骤的结果
+type checkResult struct {
+	Stage  string // init、invoker、schema、server、cron
+	Name   string
+	Status string
+	Error  string
+	Cost   time.Duration
+}
+
+// configChecker --config-check 模式下记录每个步骤的结果，步骤失败或者panic时继续执行后面的步骤
+type configChecker struct {
+	strict  bool // 配置中存在组件不认识的字段时是否失败
+	results []checkResult
+}
+
+func newConfigChecker(strict bool) *configChecker {
+	// 记录组件配置中不认识的字段，通常是拼写错误
+	econf.EnableSchemaCheck()
+	return &configChecker{strict: strict}
+}
+
+// run 执行一个步骤，组件Build失败时通常会panic，这里转换为错误
+func (c *configChecker) run(stage string, fn func() error) {
+	beg := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		return fn()
+	}()
+	c.add(stage, functionName(fn), err, time.Since(beg))
+}
+
+func (c *configChecker) add(stage, name string, err error, cost time.Duration) {
+	res := checkResult{Stage: stage, Name: name, Status: checkStatusOK, Cost: cost}
+	if err != nil {
+		res.Status = checkStatusFail
+		res.Error = err.Error()
+	}
+	c.results = append(c.results, res)
+}
+
+// failed 是否存在失败的步骤
+func (c *configChecker) failed() bool {
+	for _, res := range c.results {
+		if res.Status == checkStatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// report 输出检查报告
+func (c *configChecker) report(w io.Writer) {
+	fmt.Fprintf(w, "config check: %s\n", eflag.String("config"))
+	for _, res := range c.results {
+		status := xcolor.Green(res.Status)
+		switch res.Status {
+		case checkStatusWarn:
+			status = xcolor.Yellow(res.Status)
+		case checkStatusFail:
+			status = xcolor.Red(res.Status)
+		}
+		fmt.Fprintf(w, "  [%s] %-8s %s (%s)\n", status, res.Stage, res.Name, res.Cost.Round(time.Microsecond))
+		if res.Error != "" {
+			fmt.Fprintf(w, "      %s\n", strings.ReplaceAll(res.Error, "\n", "\n      "))
+		}
+	}
+	if c.failed() {
+		fmt.Fprintln(w, xcolor.Red("config check fail"))
+		return
+	}
+	fmt.Fprintln(w, xcolor.Green("config check pass"))
+}
+
+// runConfigCheck 不启动服务，汇总系统初始化、用户初始化函数、组件配置校验的结果，失败时以1退出
+func (e *Ego) runConfigCheck(w io.Writer) int {
+	for _, issue := range econf.SchemaIssues() {
+		res := checkResult{Stage: "schema", Name: issue.Key + " (" + issue.Type + ")", Status: checkStatusWarn, Error: issue.Err}
+		if e.check.strict {
+			res.Status = checkStatusFail
+		}
+		e.check.results = append(e.check.results, res)
+	}
+	// 服务和定时任务在注册时已经完成Build，没有Init，不会监听端口
+	e.smu.RLock()
+	for _, s := range e.servers {
+		e.check.add("server", s.PackageName()+" "+s.Name(), nil, 0)
+	}
+	for _, s := range e.orderServers {
+		e.check.add("server", s.PackageName()+" "+s.Name(), nil, 0)
+	}
+	e.smu.RUnlock()
+	for _, c := range e.crons {
+		e.check.add("cron", c.Name(), nil, 0)
+	}
+
+	e.check.report(w)
+	_ = runSerialFuncLogError(e.opts.afterStopClean)
+	if e.check.failed() {
+		return 1
+	}
+	return 0
+}
+
+// functionName 返回函数名，去掉包路径和方法值的后缀
+func functionName(fn interface{}) string {
+	name := xstring.FunctionName(fn)
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
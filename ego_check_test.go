@@ -0,0 +1,58 @@
+package ego
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func TestConfigCheck(t *testing.T) {
+	app := New(WithDisableBanner(true))
+	app.check = &configChecker{}
+	app.Invoker(func() error {
+		return nil
+	}, func() error {
+		return errors.New("dial redis fail")
+	}, func() error {
+		elog.EgoLogger.Panic("parse config fail")
+		return nil
+	})
+	assert.Len(t, app.check.results, 3)
+	assert.Equal(t, checkStatusOK, app.check.results[0].Status)
+	assert.Equal(t, "dial redis fail", app.check.results[1].Error)
+	assert.Equal(t, "panic: parse config fail", app.check.results[2].Error)
+	assert.Contains(t, app.check.results[0].Name, "TestConfigCheck")
+
+	var buf bytes.Buffer
+	assert.Equal(t, 1, app.runConfigCheck(&buf))
+	assert.Contains(t, buf.String(), "dial redis fail")
+	assert.Contains(t, buf.String(), "config check fail")
+
+	// 没有失败的步骤时以0退出
+	app.check = &configChecker{}
+	app.Invoker(func() error {
+		return nil
+	})
+	buf.Reset()
+	assert.Equal(t, 0, app.runConfigCheck(&buf))
+	assert.Contains(t, buf.String(), "config check pass")
+}
+
+func TestRunConfigCheck(t *testing.T) {
+	var code = -1
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	app := New(WithDisableBanner(true))
+	app.check = &configChecker{}
+	app.Invoker(func() error {
+		return errors.New("build fail")
+	})
+	assert.NoError(t, app.Run())
+	assert.Equal(t, 1, code)
+}
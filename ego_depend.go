@@ -0,0 +1,266 @@
+package ego
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/standard"
+)
+
+// WithDependsOn 设置组件的启动依赖，同 Ego.DependsOn，用于在New之前创建好的组件
+func WithDependsOn(component standard.Component, deps ...standard.Component) Option {
+	return func(a *Ego) {
+		a.DependsOn(component, deps...)
+	}
+}
+
+// WithDependsOnTimeout 设置等待依赖就绪的超时时间，超时后应用启动失败，默认0，一直等待到应用停止
+func WithDependsOnTimeout(timeout time.Duration) Option {
+	return func(a *Ego) {
+		a.opts.dependsOnTimeout = timeout
+	}
+}
+
+// DependsOn 设置组件的启动依赖，component在deps全部就绪后才启动，没有依赖关系的服务、定时任务、短时任务仍然并行启动
+// component、deps需要通过Serve、Cron、Job注册，实现了 Health() bool 的组件健康后就绪，否则开始启动即就绪
+// 定时任务在服务之后启动，服务不能依赖定时任务，例如
+//
+//	ego.New().Serve(grpcServer, consumerServer).DependsOn(grpcServer, consumerServer).Run()
+//
+// 短时任务执行时不启动服务和定时任务，只能依赖短时任务，依赖的短时任务执行成功后才就绪，例如
+//
+//	ego.New().Job(migrateJob, seedJob).DependsOn(seedJob, migrateJob).Run()
+//
+// 依赖的短时任务没有通过 --job 指定时不执行，视为已经就绪
+func (e *Ego) DependsOn(component standard.Component, deps ...standard.Component) *Ego {
+	e.smu.Lock()
+	defer e.smu.Unlock()
+	if e.depends == nil {
+		e.depends = make(map[standard.Component][]standard.Component)
+	}
+	e.depends[component] = append(e.depends[component], deps...)
+	return e
+}
+
+// dependGraph 服务、定时任务、短时任务的启动依赖，每个组件就绪后关闭对应的channel
+type dependGraph struct {
+	deps    map[standard.Component][]standard.Component
+	ready   map[standard.Component]chan struct{}
+	timeout time.Duration
+
+	mu       sync.Mutex
+	started  map[standard.Component]bool // 等待依赖后开始启动的组件
+	stopped  bool
+	stopping chan struct{} // 应用开始停止后关闭，不再等待依赖
+}
+
+// newDependGraph 校验依赖的组件已经注册、服务没有依赖定时任务、短时任务只依赖短时任务、没有循环依赖，返回拓扑排序后的启动顺序
+func (e *Ego) newDependGraph() (*dependGraph, []standard.Component, error) {
+	e.smu.RLock()
+	defer e.smu.RUnlock()
+	g := &dependGraph{
+		ready:    make(map[standard.Component]chan struct{}),
+		timeout:  e.opts.dependsOnTimeout,
+		started:  make(map[standard.Component]bool),
+		stopping: make(chan struct{}),
+	}
+	kinds := make(map[standard.Component]string)
+	var nodes []standard.Component
+	for _, s := range e.servers {
+		kinds[s] = "server"
+		nodes = append(nodes, s)
+	}
+	for _, c := range e.crons {
+		kinds[c] = "cron"
+		nodes = append(nodes, c)
+	}
+	jobNames := make([]string, 0, len(e.jobs))
+	for name := range e.jobs {
+		jobNames = append(jobNames, name)
+	}
+	sort.Strings(jobNames)
+	for _, name := range jobNames {
+		kinds[e.jobs[name]] = "job"
+		nodes = append(nodes, e.jobs[name])
+	}
+	for _, node := range nodes {
+		g.ready[node] = make(chan struct{})
+	}
+	if len(e.depends) > 0 {
+		g.deps = make(map[standard.Component][]standard.Component, len(e.depends))
+	}
+	for component, deps := range e.depends {
+		// 没有通过 --job 指定的短时任务不执行，不需要等待，也不被等待
+		if e.declaredJobs[component] && kinds[component] == "" {
+			continue
+		}
+		if _, ok := kinds[component]; !ok {
+			return nil, nil, fmt.Errorf("depends on, component %s is not registered by Serve, Cron or Job", componentName(component))
+		}
+		for _, dep := range deps {
+			if e.declaredJobs[dep] && kinds[dep] == "" {
+				continue
+			}
+			if _, ok := kinds[dep]; !ok {
+				return nil, nil, fmt.Errorf("depends on, dependency %s of %s is not registered by Serve, Cron or Job", componentName(dep), componentName(component))
+			}
+			switch {
+			case kinds[component] == "server" && kinds[dep] == "cron":
+				return nil, nil, fmt.Errorf("depends on, server %s cannot depend on cron %s, crons start after servers", componentName(component), componentName(dep))
+			case (kinds[component] == "job") != (kinds[dep] == "job"):
+				return nil, nil, fmt.Errorf("depends on, %s %s cannot depend on %s %s, jobs run without servers and crons", kinds[component], componentName(component), kinds[dep], componentName(dep))
+			}
+			g.deps[component] = append(g.deps[component], dep)
+		}
+	}
+
+	// 深度优先遍历，依赖排在前面
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[standard.Component]int)
+	order := make([]standard.Component, 0, len(nodes))
+	var path []string
+	var visit func(node standard.Component) error
+	visit = func(node standard.Component) error {
+		switch state[node] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("depends on, cycle found: %s -> %s", strings.Join(path, " -> "), componentName(node))
+		}
+		state[node] = visiting
+		path = append(path, componentName(node))
+		for _, dep := range g.deps[node] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+		order = append(order, node)
+		return nil
+	}
+	for _, node := range nodes {
+		if err := visit(node); err != nil {
+			return nil, nil, err
+		}
+	}
+	return g, order, nil
+}
+
+// wait 等待组件的依赖全部就绪后开始启动，返回false时不启动组件
+// 等待超时返回错误，应用开始停止时不返回错误
+func (g *dependGraph) wait(ctx context.Context, component standard.Component) (bool, error) {
+	if g == nil {
+		return true, nil
+	}
+	deps := g.deps[component]
+	if len(deps) == 0 {
+		return true, nil
+	}
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, g.timeout, fmt.Errorf("wait dependencies timeout %v", g.timeout))
+		defer cancel()
+	}
+	for _, dep := range deps {
+		select {
+		case <-g.ready[dep]:
+		case <-g.stopping:
+		case <-ctx.Done():
+			return false, fmt.Errorf("start %s, wait dependency %s fail, %w", componentName(component), componentName(dep), context.Cause(ctx))
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stopped {
+		return false, nil
+	}
+	g.started[component] = true
+	return true, nil
+}
+
+// markReady 组件开始启动后执行，实现了Health的组件在健康后才就绪
+func (g *dependGraph) markReady(component standard.Component) {
+	if g == nil || !g.required(component) {
+		return
+	}
+	if hs, ok := component.(interface{ Health() bool }); ok {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for !hs.Health() {
+			select {
+			case <-g.stopping:
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+	close(g.ready[component])
+}
+
+// markDone 短时任务执行成功后就绪，失败时依赖它的短时任务不再执行
+func (g *dependGraph) markDone(component standard.Component) {
+	if g == nil || !g.required(component) {
+		return
+	}
+	close(g.ready[component])
+}
+
+// stop 应用开始停止，等待依赖的组件不再启动
+func (g *dependGraph) stop() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.stopped {
+		g.stopped = true
+		close(g.stopping)
+	}
+}
+
+// skipped 等待依赖的组件在应用停止前没有启动，停止时跳过
+func (g *dependGraph) skipped(component standard.Component) bool {
+	if g == nil || len(g.deps[component]) == 0 {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.started[component]
+}
+
+// required 是否有其他组件依赖该组件
+func (g *dependGraph) required(component standard.Component) bool {
+	for _, deps := range g.deps {
+		for _, dep := range deps {
+			if dep == component {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logDependOrder 有依赖时输出启动顺序
+func (e *Ego) logDependOrder(order []standard.Component) {
+	if len(e.depends) == 0 {
+		return
+	}
+	names := make([]string, 0, len(order))
+	for _, component := range order {
+		names = append(names, componentName(component))
+	}
+	e.logger.Info("start order by dependencies", elog.FieldComponent("app"), elog.Any("order", names))
+}
+
+func componentName(component standard.Component) string {
+	return component.PackageName() + "." + component.Name()
+}
@@ -0,0 +1,187 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/standard"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+// dependServer 记录Init、Stop是否执行
+type dependServer struct {
+	healthServer
+	name    string
+	inited  int32
+	stopped int32
+}
+
+func (s *dependServer) Name() string {
+	return s.name
+}
+
+func (s *dependServer) Init() error {
+	atomic.StoreInt32(&s.inited, 1)
+	return nil
+}
+
+func (s *dependServer) Stop() error {
+	atomic.StoreInt32(&s.stopped, 1)
+	return nil
+}
+
+func (s *dependServer) GracefulStop(context.Context) error {
+	return s.Stop()
+}
+
+func TestDependsOn(t *testing.T) {
+	t.Run("start after dependency healthy", func(t *testing.T) {
+		consumer := &dependServer{name: "consumer"}
+		grpc := &dependServer{name: "grpc", healthServer: healthServer{healthy: 1}}
+		other := &dependServer{name: "other", healthServer: healthServer{healthy: 1}}
+		app := New().Serve(grpc, other, consumer).DependsOn(grpc, consumer)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			// 没有依赖的服务并行启动
+			assert.Equal(t, int32(1), atomic.LoadInt32(&other.inited))
+			assert.Equal(t, int32(1), atomic.LoadInt32(&consumer.inited))
+			assert.Equal(t, int32(0), atomic.LoadInt32(&grpc.inited))
+			atomic.StoreInt32(&consumer.healthy, 1)
+			time.Sleep(200 * time.Millisecond)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&grpc.inited))
+			_ = app.Stop(context.Background(), true)
+		}()
+		assert.NoError(t, app.Run())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&grpc.stopped))
+	})
+
+	t.Run("stop while waiting dependency", func(t *testing.T) {
+		consumer := &dependServer{name: "consumer"}
+		grpc := &dependServer{name: "grpc"}
+		app := New().Serve(grpc, consumer).DependsOn(grpc, consumer)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = app.Stop(context.Background(), false)
+		}()
+		assert.NoError(t, app.Run())
+		// 没有启动的服务不执行Stop
+		assert.Equal(t, int32(0), atomic.LoadInt32(&grpc.inited))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&grpc.stopped))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&consumer.stopped))
+	})
+
+	t.Run("wait dependency timeout", func(t *testing.T) {
+		consumer := &dependServer{name: "consumer"}
+		grpc := &dependServer{name: "grpc"}
+		app := New(WithDependsOnTimeout(50*time.Millisecond)).Serve(grpc, consumer).DependsOn(grpc, consumer)
+		err := app.Run()
+		assert.EqualError(t, err, "start server.grpc, wait dependency server.consumer fail, wait dependencies timeout 50ms")
+	})
+
+	t.Run("invalid dependencies", func(t *testing.T) {
+		a := &dependServer{name: "a"}
+		b := &dependServer{name: "b"}
+		c := &dependServer{name: "c"}
+		err := New().Serve(a, b, c).DependsOn(a, b).DependsOn(b, c).DependsOn(c, a).Run()
+		assert.ErrorContains(t, err, "depends on, cycle found: server.")
+
+		err = New().Serve(a).DependsOn(a, b).Run()
+		assert.EqualError(t, err, "depends on, dependency server.b of server.a is not registered by Serve, Cron or Job")
+	})
+}
+
+func TestDependGraphOrder(t *testing.T) {
+	a := &dependServer{name: "a"}
+	b := &dependServer{name: "b"}
+	c := &dependServer{name: "c"}
+	app := New().Serve(c, b, a).DependsOn(c, b).DependsOn(b, a)
+	_, order, err := app.newDependGraph()
+	assert.NoError(t, err)
+	var names []string
+	for _, component := range order {
+		names = append(names, component.Name())
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
+// dependJob 记录执行顺序的短时任务
+type dependJob struct {
+	name string
+	err  error
+	mu   *sync.Mutex
+	runs *[]string
+}
+
+func (j *dependJob) Name() string        { return j.name }
+func (j *dependJob) PackageName() string { return ejob.PackageName }
+func (j *dependJob) Init() error         { return nil }
+func (j *dependJob) Stop() error         { return nil }
+func (j *dependJob) Start() error {
+	// 没有依赖的短时任务稍后结束，验证依赖它的短时任务确实在等待
+	time.Sleep(20 * time.Millisecond)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	*j.runs = append(*j.runs, j.name)
+	return j.err
+}
+
+// withJobs 模拟通过 --job 指定了jobs，declared为通过Job注册的所有短时任务
+func withJobs(app *Ego, declared []*dependJob, jobs ...*dependJob) *Ego {
+	app.jobs = make(map[string]ejob.Ejob)
+	app.declaredJobs = make(map[standard.Component]bool)
+	for _, j := range declared {
+		app.declaredJobs[j] = true
+	}
+	for _, j := range jobs {
+		app.jobs[j.name] = j
+	}
+	return app
+}
+
+func TestDependsOnJob(t *testing.T) {
+	var mu sync.Mutex
+	newJob := func(name string, err error, runs *[]string) *dependJob {
+		return &dependJob{name: name, err: err, mu: &mu, runs: runs}
+	}
+
+	t.Run("start after dependency done", func(t *testing.T) {
+		var runs []string
+		migrate, seed, report := newJob("migrate", nil, &runs), newJob("seed", nil, &runs), newJob("report", nil, &runs)
+		jobs := []*dependJob{migrate, seed, report}
+		app := withJobs(New(), jobs, jobs...).DependsOn(seed, migrate).DependsOn(report, seed)
+		assert.NoError(t, app.Run())
+		assert.Equal(t, []string{"migrate", "seed", "report"}, runs)
+	})
+
+	t.Run("dependency fail", func(t *testing.T) {
+		var runs []string
+		migrate, seed := newJob("migrate", errors.New("migrate fail"), &runs), newJob("seed", nil, &runs)
+		jobs := []*dependJob{migrate, seed}
+		app := withJobs(New(), jobs, jobs...).DependsOn(seed, migrate)
+		assert.EqualError(t, app.Run(), "migrate fail")
+		// 依赖失败的短时任务不执行
+		assert.Equal(t, []string{"migrate"}, runs)
+	})
+
+	t.Run("dependency not selected", func(t *testing.T) {
+		var runs []string
+		migrate, seed := newJob("migrate", nil, &runs), newJob("seed", nil, &runs)
+		app := withJobs(New(), []*dependJob{migrate, seed}, seed).DependsOn(seed, migrate)
+		assert.NoError(t, app.Run())
+		assert.Equal(t, []string{"seed"}, runs)
+	})
+
+	t.Run("job cannot depend on server", func(t *testing.T) {
+		var runs []string
+		grpc := &dependServer{name: "grpc"}
+		seed := newJob("seed", nil, &runs)
+		app := withJobs(New().Serve(grpc), []*dependJob{seed}, seed).DependsOn(seed, grpc)
+		assert.EqualError(t, app.Run(), "depends on, job task.ejob.seed cannot depend on server server.grpc, jobs run without servers and crons")
+	})
+}
@@ -0,0 +1,154 @@
+package ego
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esetting"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+// initDiagnostics 配置了诊断包目录时保留最近的日志
+func (e *Ego) initDiagnostics() error {
+	if esetting.String("ego.diagnostics.dir") == "" {
+		return nil
+	}
+	elog.SetRecentLines(esetting.Int("ego.diagnostics.logLines"))
+	return nil
+}
+
+// watchStopTimeout 停止超时时生成诊断包，保留卡住的goroutine
+func (e *Ego) watchStopTimeout(ctx context.Context) {
+	if esetting.String("ego.diagnostics.dir") == "" {
+		return
+	}
+	select {
+	case <-e.stopDone:
+		return
+	case <-ctx.Done():
+	}
+	select {
+	case <-e.stopDone:
+		return
+	default:
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		e.dumpDiagnostics(fmt.Sprintf("stop timeout: %v", context.Cause(ctx)))
+	}
+}
+
+// dumpDiagnostics 异常退出前生成诊断包，没有配置目录时不生成，同一个应用只生成一次
+func (e *Ego) dumpDiagnostics(reason string) {
+	dir := esetting.String("ego.diagnostics.dir")
+	if dir == "" {
+		return
+	}
+	e.diagnosticsOnce.Do(func() {
+		path, err := writeDiagnostics(dir, reason, time.Now())
+		if err != nil {
+			e.logger.Error("write diagnostics bundle fail", elog.FieldComponent("app"), elog.String("reason", reason), elog.FieldErr(err))
+			return
+		}
+		e.logger.Warn("write diagnostics bundle", elog.FieldComponent("app"), elog.String("reason", reason), elog.String("path", path))
+	})
+}
+
+// writeDiagnostics 将最近的日志、goroutine、脱敏后的配置、指标和事件写入 dir/{app}-{host}-{time}.tar.gz
+// 单项采集失败时写入错误信息，不影响其他内容
+func writeDiagnostics(dir string, reason string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	replacer := strings.NewReplacer("/", "_", "\\", "_", " ", "_")
+	name := fmt.Sprintf("%s-%s-%s.tar.gz", replacer.Replace(eapp.Name()), replacer.Replace(eapp.HostName()), now.Format("20060102T150405"))
+	path := filepath.Join(dir, name)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: now}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	addJSON := func(name string, fn func() (interface{}, error)) error {
+		v, err := fn()
+		if err != nil {
+			return add(name, []byte(err.Error()))
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return add(name, []byte(err.Error()))
+		}
+		return add(name, data)
+	}
+
+	var goroutines bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		goroutines.WriteString(err.Error())
+	}
+	err := errors.Join(
+		addJSON("meta.json", func() (interface{}, error) {
+			return map[string]interface{}{
+				"app":        eapp.Name(),
+				"appVersion": eapp.AppVersion(),
+				"egoVersion": eapp.EgoVersion(),
+				"goVersion":  runtime.Version(),
+				"host":       eapp.HostName(),
+				"pid":        os.Getpid(),
+				"startTime":  eapp.StartTime(),
+				"time":       now.Format(time.RFC3339),
+				"reason":     reason,
+			}, nil
+		}),
+		add("logs.jsonl", []byte(strings.Join(elog.RecentLines(), "\n"))),
+		add("goroutines.txt", goroutines.Bytes()),
+		addJSON("config.json", func() (interface{}, error) { return egovernor.RedactedConfig(), nil }),
+		add("metrics.txt", metricsText()),
+		addJSON("events.json", func() (interface{}, error) { return egovernor.Events(), nil }),
+		tw.Close(),
+		gw.Close(),
+	)
+	if err != nil {
+		return "", err
+	}
+	// 先写临时文件，避免留下不完整的诊断包
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// metricsText prometheus文本格式的全部指标，采集失败时返回错误信息
+func metricsText() []byte {
+	families, err := prometheus.DefaultGatherer.Gather()
+	var buf bytes.Buffer
+	for _, family := range families {
+		if _, writeErr := expfmt.MetricFamilyToText(&buf, family); writeErr != nil {
+			err = errors.Join(err, writeErr)
+		}
+	}
+	if err != nil {
+		buf.WriteString("# " + err.Error() + "\n")
+	}
+	return buf.Bytes()
+}
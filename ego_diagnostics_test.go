@@ -0,0 +1,77 @@
+package ego
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+func readBundle(t *testing.T, path string) map[string]string {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(tr)
+		files[header.Name] = string(data)
+	}
+	return files
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	elog.SetRecentLines(10)
+	defer elog.SetRecentLines(0)
+	elog.EgoLogger.Info("before crash")
+	egovernor.RecordEvent("test", "diagnostics event")
+
+	path, err := writeDiagnostics(t.TempDir(), "stop timeout", time.Now())
+	assert.NoError(t, err)
+	files := readBundle(t, path)
+	assert.Contains(t, files["meta.json"], `"reason": "stop timeout"`)
+	assert.Contains(t, files["logs.jsonl"], `"msg":"before crash"`)
+	assert.Contains(t, files["goroutines.txt"], "TestWriteDiagnostics")
+	assert.Contains(t, files["events.json"], "diagnostics event")
+	assert.Contains(t, files["metrics.txt"], "go_goroutines ")
+	assert.Contains(t, files, "config.json")
+}
+
+func TestWatchStopTimeout(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(constant.EgoDiagnosticsDir, dir)
+	New()
+	app := &Ego{logger: elog.EgoLogger, stopDone: make(chan struct{})}
+
+	// 停止完成时不生成诊断包
+	close(app.stopDone)
+	app.watchStopTimeout(context.Background())
+
+	app = &Ego{logger: elog.EgoLogger, stopDone: make(chan struct{})}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	app.watchStopTimeout(ctx)
+	// 只生成一次
+	app.dumpDiagnostics("shutdown with error")
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tar.gz"))
+	assert.Len(t, matches, 1)
+	assert.Contains(t, readBundle(t, matches[0])["meta.json"], `"reason": "stop timeout: context deadline exceeded"`)
+}
@@ -0,0 +1,59 @@
+package ego
+
+import (
+	"fmt"
+
+	"github.com/gotomicro/ego/server"
+)
+
+// NewE 同 New，命令行参数、配置等初始化失败时返回错误，不panic、不退出进程
+// 用于在更大的程序或者守护进程中嵌入Ego，由调用方处理创建失败，例如
+//
+//	app, err := ego.NewE()
+//	if err != nil {
+//		return err
+//	}
+//	if err = app.ServeE(server); err != nil {
+//		return err
+//	}
+//	return app.Run()
+func NewE(options ...Option) (e *Ego, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("ego new panic: %v", rec)
+		}
+	}()
+	e = New(append(options, func(a *Ego) {
+		a.opts.returnError = true
+	})...)
+	return e, e.err
+}
+
+// Err 返回创建、设置过程中的错误，Run时同样会返回该错误
+func (e *Ego) Err() error {
+	return e.err
+}
+
+// InvokerE 同 Invoker，函数返回错误或者panic时返回错误
+func (e *Ego) InvokerE(fns ...func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("ego invoker panic: %v", rec)
+			e.err = err
+		}
+	}()
+	e.Invoker(fns...)
+	return e.err
+}
+
+// ServeE 同 Serve，服务名称、监听地址冲突时返回错误
+func (e *Ego) ServeE(s ...server.Server) error {
+	e.Serve(s...)
+	return e.err
+}
+
+// OrderServeE 同 OrderServe，服务名称、监听地址冲突时返回错误
+func (e *Ego) OrderServeE(s ...server.OrderServer) error {
+	e.OrderServe(s...)
+	return e.err
+}
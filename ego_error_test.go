@@ -0,0 +1,58 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEBadFlag(t *testing.T) {
+	resetFlagSet()
+	defer resetFlagSet()
+	app, err := NewE(WithArguments([]string{"--unknown"}))
+	assert.ErrorContains(t, err, "flag provided but not defined")
+	assert.Equal(t, err, app.Err())
+	assert.Equal(t, err, app.Run())
+	// 解析后恢复全局flagset的错误处理方式，之后的New仍然在参数错误时退出
+	assert.Equal(t, flag.ExitOnError, flag.CommandLine.ErrorHandling())
+}
+
+func TestNewEBadConfig(t *testing.T) {
+	resetFlagSet()
+	defer resetFlagSet()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	assert.NoError(t, os.WriteFile(path, []byte("[server\nport = "), 0644))
+	_, err := NewE(WithArguments([]string{"--config", path, "--watch=false"}))
+	assert.ErrorContains(t, err, "data source: load config")
+}
+
+func TestEgoInvokerE(t *testing.T) {
+	app := &Ego{smu: &sync.RWMutex{}}
+	assert.NoError(t, app.InvokerE(func() error { return nil }))
+	assert.EqualError(t, app.InvokerE(func() error { return errors.New("init fail") }), "init fail")
+
+	app = &Ego{smu: &sync.RWMutex{}}
+	err := app.InvokerE(func() error { panic("boom") })
+	assert.EqualError(t, err, "ego invoker panic: boom")
+	assert.Equal(t, err, app.Err())
+}
+
+func TestEgoPhaseJobE(t *testing.T) {
+	app := New()
+	run := func(context.Context) error { return nil }
+	err := app.PhaseJobE(PhaseJob{Name: "ok", Phase: JobPhasePreStart, Run: run}, PhaseJob{Name: "nil"})
+	assert.EqualError(t, err, "phase job nil run func nil")
+	err = app.PhaseJobE(PhaseJob{Name: "bad", Phase: "unknown", Run: run})
+	assert.EqualError(t, err, `phase job bad phase "unknown" invalid`)
+	assert.Empty(t, app.phaseJobs)
+
+	assert.NoError(t, app.PhaseJobE(PhaseJob{Name: "ok", Phase: JobPhasePreStart, Run: run}))
+	assert.Equal(t, FailurePolicyStop, app.phaseJobs[0].FailurePolicy)
+	assert.Panics(t, func() { app.PhaseJob(PhaseJob{Name: "nil", Phase: JobPhasePreStart}) })
+}
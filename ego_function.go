@@ -4,28 +4,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
-	"time"
 
-	sentinelmetrics "github.com/alibaba/sentinel-golang/metrics"
-	"github.com/prometheus/client_golang/prometheus"
-	"go.uber.org/automaxprocs/maxprocs"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/gotomicro/ego/client/ecloudevents"
+	"github.com/gotomicro/ego/client/enotify"
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/econf/manager"
+	"github.com/gotomicro/ego/core/eextension"
 	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/core/egraceful"
+	"github.com/gotomicro/ego/core/ehooks"
 	"github.com/gotomicro/ego/core/elog"
-	"github.com/gotomicro/ego/core/esentinel"
+	"github.com/gotomicro/ego/core/emaintenance"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/eretry"
+	"github.com/gotomicro/ego/core/esetting"
+	"github.com/gotomicro/ego/core/estophook"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/etrace/otel"
-	"github.com/gotomicro/ego/core/util/xcolor"
-	"github.com/gotomicro/ego/internal/retry"
+	"github.com/gotomicro/ego/server/egovernor"
 )
 
 // waitSignals wait signal
@@ -37,15 +46,21 @@ func (e *Ego) waitSignals() {
 	)
 
 	go func() {
-		s := <-sig
+		var s os.Signal
+		select {
+		case s = <-sig:
+		case <-e.ctx.Done():
+			// e.ctx被取消，但根context没有被取消，说明是直接调用了Stop，无需再处理
+			if e.opts.ctx.Err() == nil {
+				return
+			}
+			// 根context被取消，与SIGTERM走同样的优雅退出流程
+			e.logger.Info("root context canceled, stop ego", elog.FieldComponent("app"), elog.FieldErr(context.Cause(e.opts.ctx)))
+			s = syscall.SIGTERM
+		}
 		// 区分强制退出、优雅退出
 		grace := s != syscall.SIGQUIT
 		go func() {
-			// todo 父节点传context待考虑
-			e.stopInfo = stopInfo{
-				stopStartTime:  time.Now(),
-				isGracefulStop: grace,
-			}
 			stopCtx, cancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
 
 			defer func() {
@@ -68,24 +83,40 @@ func (e *Ego) waitSignals() {
 }
 
 func (e *Ego) startServers(ctx context.Context) error {
+	// 热升级的子进程在所有服务创建监听后，向父进程报告
+	var inited sync.WaitGroup
+	inited.Add(len(e.servers))
 	// start multi servers
 	for _, s := range e.servers {
 		s := s
 		e.cycle.Run(func() (err error) {
-			_ = s.Init()
-			err = e.registerer.RegisterService(ctx, s.Info())
-			if err != nil {
-				e.logger.Error("register service err", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err))
+			// 有依赖的服务在依赖就绪后才创建监听
+			start, err := e.graph.wait(ctx, s)
+			if !start {
+				inited.Done()
+				return err
 			}
-			defer func() {
-				_ = e.registerer.UnregisterService(ctx, s.Info())
-			}()
+			_ = s.Init()
+			inited.Done()
+			go e.graph.markReady(s)
+			defer e.registerService(ctx, s)()
 			e.logger.Info("start server", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldAddr(s.Info().Label()))
 			defer e.logger.Info("stop server", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err), elog.FieldAddr(s.Info().Label()))
 			err = s.Start()
 			return
 		})
 	}
+	// 等待服务创建监听，之后输出的启动摘要中是实际监听的地址
+	inited.Wait()
+	if egraceful.IsChild() {
+		go func() {
+			if err := egraceful.Ready(); err != nil {
+				e.logger.Error("graceful report fail", elog.FieldComponent(egraceful.PackageName), elog.FieldErr(err))
+				return
+			}
+			e.logger.Info("graceful report to parent", elog.FieldComponent(egraceful.PackageName), elog.Any("listeners", egraceful.Listeners()))
+		}()
+	}
 	return nil
 }
 
@@ -102,20 +133,14 @@ func (e *Ego) startOrderServers(ctx context.Context) (err error, isNeedStop bool
 		}
 		_ = s.Init()
 		e.cycle.Run(func() (err error) {
-			err = e.registerer.RegisterService(ctx, s.Info())
-			if err != nil {
-				e.logger.Error("register service err", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err))
-			}
-			defer func() {
-				_ = e.registerer.UnregisterService(ctx, s.Info())
-			}()
+			defer e.registerService(ctx, s)()
 			e.logger.Info("start order server", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldAddr(s.Info().Label()))
 			defer e.logger.Info("stop order server", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err), elog.FieldAddr(s.Info().Label()))
 			err = s.Start()
 			return
 		})
 		isHealth := false
-		for r := retry.Begin(); r.Continue(ctx); {
+		for r := eretry.Begin(); r.Continue(ctx); {
 			// 检测server的health接口
 			// 如果成功，那么就跳出循环
 			if s.Health() {
@@ -135,6 +160,10 @@ func (e *Ego) startCrons() error {
 	for _, w := range e.crons {
 		w := w
 		e.cycle.Run(func() error {
+			if start, err := e.graph.wait(e.ctx, w); !start {
+				return err
+			}
+			go e.graph.markReady(w)
 			return w.Start()
 		})
 	}
@@ -146,16 +175,24 @@ func (e *Ego) startJobs() error {
 	if len(e.jobs) == 0 {
 		return nil
 	}
+	// 任意短时任务失败后，等待依赖的短时任务不再执行，应用停止时由graph结束等待
+	eg, ctx := errgroup.WithContext(context.Background())
 	var jobs = make([]func() error, 0)
 	// wrap jobs
 	for _, runner := range e.jobs {
 		runner := runner
 		jobs = append(jobs, func() error {
-			return runner.Start()
+			if start, err := e.graph.wait(ctx, runner); !start {
+				return err
+			}
+			if err := runner.Start(); err != nil {
+				return err
+			}
+			e.graph.markDone(runner)
+			return nil
 		})
 	}
 
-	eg := errgroup.Group{}
 	for _, fn := range jobs {
 		eg.Go(fn)
 	}
@@ -174,6 +211,21 @@ func (e *Ego) parseFlags() error {
 		})
 	}
 
+	eflag.Register(&eflag.StringFlag{
+		Name:    "config-format",
+		Usage:   "--config-format, config format: toml|yaml|json|json5|hcl|ini, default detected by file extension",
+		EnvVar:  constant.EgoConfigFormat,
+		Default: "",
+		Action:  func(string, *eflag.FlagSet) {},
+	})
+
+	eflag.Register(&eflag.BoolFlag{
+		Name:    "config-interpolate",
+		Usage:   "--config-interpolate, replace ${ENV_VAR:default} and ${func(arg)} expressions in config values",
+		EnvVar:  constant.EgoConfigInterpolate,
+		Default: false,
+	})
+
 	eflag.Register(&eflag.BoolFlag{
 		Name:    "watch",
 		Usage:   "--watch, watch config change event",
@@ -191,6 +243,41 @@ func (e *Ego) parseFlags() error {
 		},
 	})
 
+	eflag.Register(&eflag.BoolFlag{
+		Name:    "startup-json",
+		Usage:   "--startup-json, print startup summary in json instead of banner after servers started",
+		EnvVar:  constant.EgoStartupJSON,
+		Default: false,
+	})
+
+	eflag.Register(&eflag.StringFlag{
+		Name:    "addr-file",
+		Usage:   "--addr-file, write actual listening addresses of servers to the file in env format after servers started",
+		EnvVar:  constant.EgoAddrFile,
+		Default: "",
+		Action:  func(string, *eflag.FlagSet) {},
+	})
+
+	eflag.Register(&eflag.BoolFlag{
+		Name:    "config-check",
+		Usage:   "--config-check, load and validate config, build components without starting servers",
+		Default: false,
+	})
+
+	eflag.Register(&eflag.BoolFlag{
+		Name:    "config-check-strict",
+		Usage:   "--config-check-strict, config check fail when config has unknown keys",
+		Default: false,
+	})
+
+	eflag.Register(&eflag.StringFlag{
+		Name:    "plugins",
+		Usage:   "--plugins, go plugin files to load before loading config, separated by comma",
+		EnvVar:  constant.EgoPlugins,
+		Default: "",
+		Action:  func(string, *eflag.FlagSet) {},
+	})
+
 	eflag.Register(&eflag.StringFlag{
 		Name:    "host",
 		Usage:   "--host, print host",
@@ -198,30 +285,85 @@ func (e *Ego) parseFlags() error {
 		Default: "0.0.0.0",
 		Action:  func(string, *eflag.FlagSet) {},
 	})
+
+	// 框架的配置项，可以通过治理端 /config/provenance 查看生效值的来源
+	esetting.Register(
+		esetting.Setting{Key: "config", Flag: "config", Env: constant.EgoConfigPath, Default: constant.DefaultConfig, Usage: "config address"},
+		esetting.Setting{Key: "config-format", Flag: "config-format", Env: constant.EgoConfigFormat, Usage: "config format"},
+		esetting.Setting{Key: "config-interpolate", Flag: "config-interpolate", Env: constant.EgoConfigInterpolate, Default: false, Usage: "replace env and template func expressions in config values"},
+		esetting.Setting{Key: "watch", Flag: "watch", Env: "CONFIG_WATCH", Default: true, Usage: "watch config change event"},
+		esetting.Setting{Key: "host", Flag: "host", Env: constant.EnvAppHost, Default: "0.0.0.0", Usage: "server host"},
+		esetting.Setting{Key: "ego.maxProc", Config: "ego.maxProc", Usage: "GOMAXPROCS, default set by automaxprocs"},
+		esetting.Setting{Key: "ego.config.snapshotLimit", Config: "ego.config.snapshotLimit", Default: 10, Usage: "config snapshots to keep"},
+		esetting.Setting{Key: "ego.config.snapshotDir", Env: constant.EgoConfigSnapshotDir, Config: "ego.config.snapshotDir", Usage: "config snapshot dir"},
+		esetting.Setting{Key: "ego.config.onUnavailable", Env: constant.EgoConfigOnUnavailable, Default: StartupFailFast, Usage: "startup behavior when config data source is unreachable, failfast, retry or degraded"},
+		esetting.Setting{Key: "ego.config.cacheFile", Env: constant.EgoConfigCacheFile, Usage: "file to persist the last successfully loaded config"},
+		esetting.Setting{Key: "ego.config.cacheSecret", Env: constant.EgoConfigCacheSecret, Usage: "secret to encrypt config cache file"},
+		esetting.Setting{Key: "ego.config.retryTimeout", Env: constant.EgoConfigRetryTimeout, Default: "30s", Usage: "retry deadline of config data source at startup"},
+		esetting.Setting{Key: "ego.diagnostics.dir", Env: constant.EgoDiagnosticsDir, Config: "ego.diagnostics.dir", Usage: "dir to write diagnostics bundle on abnormal exit"},
+		esetting.Setting{Key: "ego.upgrade.enable", Env: constant.EgoUpgradeEnable, Config: "ego.upgrade.enable", Default: false, Usage: "start hot upgrade when receiving SIGUSR2"},
+		esetting.Setting{Key: "ego.diagnostics.logLines", Config: "ego.diagnostics.logLines", Default: 500, Usage: "recent log lines in diagnostics bundle"},
+	)
+	if e.opts.returnError {
+		return eflag.ParseWithArgsE(e.opts.arguments)
+	}
 	return eflag.ParseWithArgs(e.opts.arguments)
 }
 
 // loadConfig init
-func loadConfig() error {
+func (e *Ego) loadConfig() error {
 	var configAddr = eflag.String("config")
-	provider, parser, tag, err := manager.NewDataSource(configAddr, eflag.Bool("watch"))
+	// 配置检查模式下不需要监听配置变化
+	checking := eflag.Bool("config-check")
+	// 配置检查模式、NewE创建时返回错误，不panic
+	returnError := checking || e.opts.returnError
+	format := econf.ConfigType(eflag.String("config-format"))
+	provider, parser, tag, err := manager.NewDataSourceWithFormat(configAddr, eflag.Bool("watch") && !checking, format)
 
 	// 如果不存在配置，找不到该文件路径，该错误只存在file类型
 	if err == manager.ErrDefaultConfigNotExist {
+		if checking {
+			return fmt.Errorf("config %s not exist, %w", configAddr, err)
+		}
 		// 如果协议是file类型，并且是默认文件配置，那么判断下文件是否存在，如果不存在只告诉warning，什么都不做
 		elog.EgoLogger.Warn("no config... ", elog.FieldComponent(econf.PackageName), elog.String("addr", configAddr), elog.FieldErr(err))
 		return nil
 	}
 
 	// 如果存在错误，报错
+	if err != nil && returnError {
+		return fmt.Errorf("data source: provider error, %w", err)
+	}
 	if err != nil {
 		elog.EgoLogger.Panic("data source: provider error", elog.FieldComponent(econf.PackageName), elog.FieldErr(err))
 	}
 
+	// 配置缓存需要在加载之前设置，首次加载成功的配置也会写入缓存
+	if file := esetting.String("ego.config.cacheFile"); file != "" && !checking {
+		if err := econf.SetCacheFile(file, []byte(esetting.String("ego.config.cacheSecret"))); err != nil {
+			elog.EgoLogger.Error("init config cache file", elog.FieldComponent(econf.PackageName), elog.FieldErr(err))
+		}
+	}
+
+	// 表达式替换默认关闭，需要在加载之前开启
+	if esetting.Bool("config-interpolate") {
+		econf.EnableInterpolate()
+	}
+
 	// 如果不是，就要加载文件，加载不到panic
-	if err := econf.LoadFromDataSource(provider, parser, econf.WithTagName(tag)); err != nil {
+	if err := loadDataSource(configAddr, provider, parser, tag, checking); err != nil {
+		if returnError {
+			return fmt.Errorf("data source: load config, %w", err)
+		}
 		elog.EgoLogger.Panic("data source: load config", elog.FieldComponent(econf.PackageName), elog.FieldErrKind("unmarshal config err"), elog.FieldErr(err))
 	}
+	// 配置快照，用于热加载失败时回滚
+	econf.SetSnapshotLimit(esetting.Int("ego.config.snapshotLimit"))
+	if dir := esetting.String("ego.config.snapshotDir"); dir != "" {
+		if err := econf.SetSnapshotDir(dir); err != nil {
+			elog.EgoLogger.Error("init config snapshot dir", elog.FieldComponent(econf.PackageName), elog.FieldErr(err))
+		}
+	}
 	elog.EgoLogger.Info("init config", elog.FieldComponent(econf.PackageName), elog.String("addr", configAddr))
 	return nil
 }
@@ -255,6 +397,16 @@ func (e *Ego) initTracer() error {
 		container = otel.DefaultConfig()
 	}
 
+	// 禁用trace时也需要按照配置的格式透传链路信息
+	propagator, err := etrace.NewPropagator(container.Propagators...)
+	if err != nil {
+		return err
+	}
+	etrace.SetPropagator(propagator)
+	if err = etrace.SetVerbosity(container.SpanVerbosity); err != nil {
+		return err
+	}
+
 	// 禁用trace
 	if econf.GetBool(e.opts.configPrefix + "trace.disable") {
 		elog.EgoLogger.Info("disable trace", elog.FieldComponent("app"))
@@ -268,43 +420,188 @@ func (e *Ego) initTracer() error {
 	return nil
 }
 
-// initSentinel 启动sentinel
-func (e *Ego) initSentinel() error {
-	if econf.Get(e.opts.configPrefix+"sentinel") != nil {
-		esentinel.Load(e.opts.configPrefix + "sentinel").Build()
-		sentinelmetrics.RegisterSentinelMetrics(prometheus.DefaultRegisterer.(*prometheus.Registry))
+// initMaintenance 加载维护模式配置
+func (e *Ego) initMaintenance() error {
+	if econf.Get(e.opts.configPrefix+"maintenance") != nil {
+		emaintenance.Load(e.opts.configPrefix + "maintenance").Build()
 	}
 	return nil
 }
 
-// initMaxProcs init
-func initMaxProcs() error {
-	if maxProcs := econf.GetInt("ego.maxProc"); maxProcs != 0 {
-		runtime.GOMAXPROCS(maxProcs)
-	} else {
-		if _, err := maxprocs.Set(); err != nil {
-			elog.EgoLogger.Error("init max procs", elog.FieldComponent("app"), elog.FieldErr(err))
-		}
+// initNotify 初始化通知组件，框架在崩溃、配置热加载失败时发送告警
+func (e *Ego) initNotify() error {
+	if econf.Get(e.opts.configPrefix+"notify") == nil {
+		return nil
 	}
-	elog.EgoLogger.Info("init app", elog.FieldComponent("app"), elog.Int("pid", os.Getpid()), elog.Int("coreNum", runtime.GOMAXPROCS(-1)))
+	comp := enotify.Load(e.opts.configPrefix + "notify").Build()
+	// 需要在日志flush之前发送完队列中的告警
+	e.opts.afterStopClean = append([]func() error{comp.Close}, e.opts.afterStopClean...)
+	econf.OnReloadError(func(err error) {
+		enotify.Alert("config reload fail", err.Error())
+	})
 	return nil
 }
 
-// printBanner init
-func (e *Ego) printBanner() error {
-	if e.opts.disableBanner {
+// initCloudEvents 配置了cloudEvents时，把框架的生命周期事件以CloudEvents格式发送到外部通道
+func (e *Ego) initCloudEvents() error {
+	key := e.opts.configPrefix + "cloudEvents"
+	if econf.Get(key) == nil {
 		return nil
 	}
-	const banner = `
-    _/_/_/_/    _/_/_/    _/_/   
-   _/        _/        _/    _/   
-  _/_/_/    _/  _/_/  _/    _/    
- _/        _/    _/  _/    _/     
-_/_/_/_/    _/_/_/    _/_/  
+	comp := ecloudevents.Load(key).Build()
+	// 需要在日志flush之前发送完队列中的事件
+	e.opts.afterStopClean = append([]func() error{comp.Close}, e.opts.afterStopClean...)
+	econf.OnChange(func(*econf.Configuration) {
+		ecloudevents.Emit(ecloudevents.TypeConfigChanged, map[string]string{"status": "applied"})
+	})
+	econf.OnReloadError(func(err error) {
+		ecloudevents.Emit(ecloudevents.TypeConfigChanged, map[string]string{"status": "fail", "error": err.Error()})
+	})
+	econf.OnRollback(func(event econf.RollbackEvent) {
+		ecloudevents.Emit(ecloudevents.TypeConfigChanged, map[string]interface{}{"status": "rollback", "from": event.From, "to": event.To, "reason": event.Reason})
+	})
+	return nil
+}
+
+// initStopHooks 配置了停止钩子时，在停止的各个阶段执行外部命令或者webhook
+func (e *Ego) initStopHooks() error {
+	key := e.opts.configPrefix + "stopHooks"
+	if econf.Get(key) == nil {
+		return nil
+	}
+	e.stopHooks = estophook.Load(key).Build()
+	return nil
+}
+
+// runStopHooks 执行停止阶段的外部钩子，没有配置时直接返回
+func (e *Ego) runStopHooks(ctx context.Context, phase string) error {
+	if e.stopHooks == nil {
+		return nil
+	}
+	return e.stopHooks.Run(ctx, phase)
+}
+
+// initStatsd 配置了statsd时，通过StatsD/DogStatsD协议上报框架指标
+func (e *Ego) initStatsd() error {
+	key := e.opts.configPrefix + "statsd"
+	if econf.Get(key) == nil {
+		return nil
+	}
+	config := emetric.DefaultStatsdConfig()
+	if err := econf.UnmarshalKey(key, config); err != nil {
+		return fmt.Errorf("parse statsd config fail, %w", err)
+	}
+	exporter, err := emetric.NewStatsdExporter(config, nil)
+	if err != nil {
+		e.logger.Error("init statsd fail", elog.FieldComponent("app"), elog.FieldErr(err))
+		return nil
+	}
+	exporter.Start()
+	// 停止时上报最后一次数据
+	e.opts.afterStopClean = append([]func() error{exporter.Close}, e.opts.afterStopClean...)
+	e.logger.Info("init statsd", elog.FieldComponent("app"), elog.FieldAddr(config.Addr))
+	return nil
+}
+
+// initPlugins 加载 --plugins 指定的Go plugin，需要在加载配置之前执行，plugin可以注册配置数据源
+func (e *Ego) initPlugins() error {
+	paths := eflag.String("plugins")
+	if paths == "" {
+		return nil
+	}
+	if err := eextension.LoadPlugins(strings.Split(paths, ",")); err != nil {
+		return err
+	}
+	e.logger.Info("load plugins", elog.FieldComponent("app"), elog.String("plugins", paths))
+	return nil
+}
+
+// initExtensions 输出扩展的冲突，不同的包注册了同一个扩展时，后注册的生效
+func (e *Ego) initExtensions() error {
+	for _, ext := range eextension.Conflicts() {
+		e.logger.Warn("extension conflict", elog.FieldComponent("app"), elog.String("kind", ext.Kind), elog.FieldName(ext.Name),
+			elog.String("package", ext.Package), elog.Any("packages", ext.Packages))
+	}
+	return nil
+}
+
+// initMetricExporters 创建 metricExporters 下配置的指标导出，导出需要通过空导入注册
+func (e *Ego) initMetricExporters() error {
+	key := e.opts.configPrefix + "metricExporters"
+	names := make([]string, 0)
+	for name := range econf.GetStringMap(key) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		builder := emetric.GetExporter(name)
+		if builder == nil {
+			return fmt.Errorf("metric exporter %s not registered, please import the package of the exporter", name)
+		}
+		exporter, err := builder(key + "." + name)
+		if err != nil {
+			return fmt.Errorf("build metric exporter %s fail, %w", name, err)
+		}
+		if err := exporter.Start(); err != nil {
+			return fmt.Errorf("start metric exporter %s fail, %w", name, err)
+		}
+		e.opts.afterStopClean = append([]func() error{exporter.Close}, e.opts.afterStopClean...)
+		e.logger.Info("init metric exporter", elog.FieldComponent("app"), elog.FieldName(name))
+	}
+	return nil
+}
 
-Welcome to Ego, starting application ...
-`
-	fmt.Println(xcolor.Blue(banner))
+// initAdminActions 注册治理端的运维操作
+func (e *Ego) initAdminActions() error {
+	egovernor.RegisterAdminAction(egovernor.AdminActionShutdown, func(context.Context, url.Values) error {
+		// 治理端自身也会被停止，需要异步执行，避免等待当前请求结束
+		go func() {
+			ctx, cancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
+			defer cancel()
+			if err := e.Shutdown(ctx); err != nil {
+				e.logger.Error("admin shutdown fail", elog.FieldComponent("app"), elog.FieldErr(err))
+			}
+		}()
+		return nil
+	})
+	egovernor.RegisterAdminAction(egovernor.AdminActionReload, func(context.Context, url.Values) error {
+		return econf.Reload()
+	})
+	egovernor.RegisterAdminAction(egovernor.AdminActionUpgrade, func(ctx context.Context, _ url.Values) error {
+		return e.upgrade(ctx)
+	})
+	egovernor.RegisterAdminAction(egovernor.AdminActionConfigRollback, func(_ context.Context, params url.Values) error {
+		var version int
+		if v := params.Get("version"); v != "" {
+			var err error
+			if version, err = strconv.Atoi(v); err != nil {
+				return fmt.Errorf("invalid version param, %w", err)
+			}
+		}
+		return econf.Rollback(version)
+	})
+	// 配置变更记录到治理端的事件日志
+	econf.OnChange(func(*econf.Configuration) {
+		egovernor.RecordEvent("config", "config applied")
+	})
+	econf.OnReloadError(func(err error) {
+		egovernor.RecordEvent("config", "config reload fail: "+err.Error())
+	})
+	econf.OnRollback(func(event econf.RollbackEvent) {
+		e.logger.Warn("config rollback", elog.FieldComponent(econf.PackageName), elog.Int("from", event.From), elog.Int("to", event.To), elog.String("reason", event.Reason))
+		egovernor.RecordEvent("config", fmt.Sprintf("config rollback from version %d to %d, reason: %s", event.From, event.To, event.Reason))
+	})
+	return nil
+}
+
+// initMaxProcs init
+func initMaxProcs() error {
+	if maxProcs := esetting.Int("ego.maxProc"); maxProcs != 0 {
+		runtime.GOMAXPROCS(maxProcs)
+	} else {
+		setMaxProcs()
+	}
+	elog.EgoLogger.Info("init app", elog.FieldComponent("app"), elog.Int("pid", os.Getpid()), elog.Int("coreNum", runtime.GOMAXPROCS(-1)))
 	return nil
 }
 
@@ -318,11 +615,40 @@ func runSerialFuncReturnError(fns []func() error) error {
 	return nil
 }
 
-func runSerialFuncLogError(fns []func() error) {
+// flushComponents 写入组件注册的缓冲数据，记录每个组件写入和丢弃的条数
+func (e *Ego) flushComponents(ctx context.Context) error {
+	var errs []error
+	for _, res := range ehooks.Flush(ctx) {
+		fields := []elog.Field{
+			elog.FieldComponent("app"),
+			elog.FieldName(res.Name),
+			elog.Int("flushed", res.Flushed),
+			elog.Int("dropped", res.Dropped),
+			elog.FieldCost(res.Cost),
+		}
+		if res.Err == nil && res.Dropped == 0 {
+			e.logger.Info("flush buffered data", fields...)
+			continue
+		}
+		err := res.Err
+		if err == nil {
+			err = fmt.Errorf("dropped %d records", res.Dropped)
+		}
+		e.logger.Error("flush buffered data fail", append(fields, elog.FieldErr(err))...)
+		egovernor.RecordEvent("flush", fmt.Sprintf("%s flushed %d, dropped %d", res.Name, res.Flushed, res.Dropped))
+		errs = append(errs, fmt.Errorf("flush %s fail, %w", res.Name, err))
+	}
+	return errors.Join(errs...)
+}
+
+func runSerialFuncLogError(fns []func() error) error {
+	var errs []error
 	for _, clean := range fns {
 		err := clean()
 		if err != nil {
 			elog.EgoLogger.Error("beforeStopClean err", elog.FieldComponent("app"), elog.FieldErr(err))
+			errs = append(errs, err)
 		}
 	}
+	return errors.Join(errs...)
 }
@@ -1,6 +1,7 @@
 package ego
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -15,7 +16,9 @@ import (
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/core/ehooks"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
 	"github.com/gotomicro/ego/task/ejob"
 )
 
@@ -31,7 +34,7 @@ func Test_loadConfig(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := loadConfig(); (err != nil) != tt.wantErr {
+			if err := (&Ego{}).loadConfig(); (err != nil) != tt.wantErr {
 				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -136,6 +139,19 @@ func Test_runSerialFuncLogError(t *testing.T) {
 	assert.Contains(t, string(logged), `"Test_runSerialFuncLogError"`)
 }
 
+func Test_flushComponents(t *testing.T) {
+	app := &Ego{logger: elog.EgoLogger}
+	ehooks.RegisterFlush("test.writer", func(ctx context.Context) (int, int, error) {
+		return 10, 0, nil
+	})
+	ehooks.RegisterFlush("test.producer", func(ctx context.Context) (int, int, error) {
+		return 8, 2, nil
+	})
+	err := app.flushComponents(context.Background())
+	assert.EqualError(t, err, "flush test.producer fail, dropped 2 records")
+	assert.NoError(t, app.flushComponents(context.Background()))
+}
+
 func Test_initLogger(t *testing.T) {
 	app := &Ego{}
 	err := os.Setenv(constant.EgoDebug, "true")
@@ -259,3 +275,49 @@ func Test_initSysLogger(t *testing.T) {
 		assert.Equal(t, "ego.sys.log", app.logger.ConfigName())
 	})
 }
+
+type testExporter struct {
+	key     string
+	started bool
+	closed  bool
+}
+
+func (e *testExporter) Start() error { e.started = true; return nil }
+
+func (e *testExporter) Close() error { e.closed = true; return nil }
+
+func Test_initMetricExporters(t *testing.T) {
+	exporter := &testExporter{}
+	emetric.RegisterExporter("test", func(key string) (emetric.Exporter, error) {
+		exporter.key = key
+		return exporter, nil
+	})
+	defer econf.Reset()
+
+	t.Run("注册过的导出", func(t *testing.T) {
+		econf.Reset()
+		err := econf.LoadFromReader(strings.NewReader(`
+[metricExporters.test]
+   addr = "127.0.0.1:9090"
+`), toml.Unmarshal)
+		assert.NoError(t, err)
+		app := &Ego{logger: elog.EgoLogger}
+		assert.NoError(t, app.initMetricExporters())
+		assert.Equal(t, "metricExporters.test", exporter.key)
+		assert.True(t, exporter.started)
+		assert.Len(t, app.opts.afterStopClean, 1)
+		assert.NoError(t, app.opts.afterStopClean[0]())
+		assert.True(t, exporter.closed)
+	})
+
+	t.Run("没有注册的导出", func(t *testing.T) {
+		econf.Reset()
+		err := econf.LoadFromReader(strings.NewReader(`
+[metricExporters.remote]
+   addr = "127.0.0.1:9090"
+`), toml.Unmarshal)
+		assert.NoError(t, err)
+		app := &Ego{logger: elog.EgoLogger}
+		assert.EqualError(t, app.initMetricExporters(), "metric exporter remote not registered, please import the package of the exporter")
+	})
+}
@@ -0,0 +1,91 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/standard"
+	"github.com/gotomicro/ego/server"
+)
+
+// appHealthName 应用自身的健康检查，开始停止后不再就绪
+const appHealthName = "app"
+
+// Health 汇总服务、定时任务、客户端组件的健康状态，同治理端的 /health/ready、/health/live
+// 客户端组件在Build时注册，实现了 Health() bool 的服务、定时任务在Run时注册，名称为 server/{name}、cron/{name}
+// 默认所有关键组件就绪后才注册服务，未就绪时一直重试直到服务停止，设置 ego.registry.readyGate=false 关闭
+func Health(ctx context.Context) ehealth.Report {
+	return ehealth.NewReport(ehealth.Check(ctx))
+}
+
+// registerHealth 注册应用、服务和定时任务的健康检查，Run返回后取消注册
+func (e *Ego) registerHealth() {
+	ehealth.Register(appHealthName, func(context.Context) error {
+		if atomic.LoadUint32(&e.stopping) == 1 {
+			return errors.New("app stopping")
+		}
+		return nil
+	}, ehealth.WithType("app"))
+	names := []string{appHealthName}
+
+	// 客户端组件使用配置名称注册，服务、定时任务加上前缀，避免同名覆盖
+	add := func(kind string, component standard.Component) {
+		hs, ok := component.(interface{ Health() bool })
+		if !ok {
+			return
+		}
+		name := component.Name()
+		if name == "" {
+			name = component.PackageName()
+		}
+		name = kind + "/" + name
+		ehealth.Register(name, func(context.Context) error {
+			if !hs.Health() {
+				return fmt.Errorf("%s not healthy", componentName(component))
+			}
+			return nil
+		}, ehealth.WithType(component.PackageName()))
+		names = append(names, name)
+	}
+	e.smu.Lock()
+	defer e.smu.Unlock()
+	for _, s := range e.servers {
+		add("server", s)
+	}
+	for _, s := range e.orderServers {
+		add("server", s)
+	}
+	for _, c := range e.crons {
+		add("cron", c)
+	}
+	e.healthNames = names
+}
+
+// unregisterHealth 取消注册Run时注册的健康检查
+func (e *Ego) unregisterHealth() {
+	e.smu.Lock()
+	defer e.smu.Unlock()
+	for _, name := range e.healthNames {
+		ehealth.Unregister(name)
+	}
+	e.healthNames = nil
+}
+
+// checkReady 所有关键组件就绪后才注册服务，包括其他服务、定时任务和客户端组件
+func checkReady(ctx context.Context, _ server.Server) error {
+	report := Health(ctx)
+	if report.Ready {
+		return nil
+	}
+	var names []string
+	for _, result := range report.Components {
+		if result.Critical && !result.Healthy {
+			names = append(names, result.Name)
+		}
+	}
+	return fmt.Errorf("components not ready: %s", strings.Join(names, ", "))
+}
@@ -0,0 +1,102 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+func TestHealth(t *testing.T) {
+	srv := &healthServer{}
+	app := &Ego{smu: &sync.RWMutex{}, servers: []server.Server{srv, &testServer{}}}
+	app.registerHealth()
+
+	report := Health(context.Background())
+	assert.False(t, report.Ready)
+	assert.True(t, report.Live)
+	assert.Len(t, report.Components, 2)
+	assert.Equal(t, "server/test_server", report.Components[1].Name)
+	assert.Equal(t, "server.test_server not healthy", report.Components[1].Error)
+
+	atomic.StoreInt32(&srv.healthy, 1)
+	assert.True(t, Health(context.Background()).Ready)
+
+	// 开始停止后不再就绪
+	atomic.StoreUint32(&app.stopping, 1)
+	report = Health(context.Background())
+	assert.False(t, report.Ready)
+	assert.Equal(t, "app stopping", report.Components[0].Error)
+
+	app.unregisterHealth()
+	assert.Empty(t, Health(context.Background()).Components)
+}
+
+func TestHealthNameNotCollideWithClient(t *testing.T) {
+	// 同名的客户端组件先注册，服务的健康检查不会覆盖
+	ehealth.Register("test_server", func(context.Context) error { return nil })
+	defer ehealth.Unregister("test_server")
+	app := &Ego{smu: &sync.RWMutex{}, servers: []server.Server{&healthServer{}}}
+	app.registerHealth()
+	defer app.unregisterHealth()
+
+	var names []string
+	for _, result := range Health(context.Background()).Components {
+		names = append(names, result.Name)
+	}
+	assert.ElementsMatch(t, []string{appHealthName, "test_server", "server/test_server"}, names)
+}
+
+func TestReadyGateDefault(t *testing.T) {
+	// 开启readyGate时默认一直等待组件就绪，不放弃注册
+	config := loadRegistryConfig()
+	assert.True(t, config.ReadyGate)
+	assert.Equal(t, -1, config.MaxRetry)
+	loadTestConfig(t, `
+[ego.registry]
+readyGate = false
+`)
+	defer loadTestConfig(t, `
+[ego.registry]
+readyGate = true
+`)
+	config = loadRegistryConfig()
+	assert.False(t, config.ReadyGate)
+	assert.Equal(t, 60, config.MaxRetry)
+}
+
+func Test_registerServiceReadyGate(t *testing.T) {
+	loadTestConfig(t, `
+[ego.registry]
+readyGate = true
+[ego.admission]
+maxRetry = -1
+retryInterval = "10ms"
+`)
+	var ready atomic.Bool
+	ehealth.Register("test.redis", func(context.Context) error {
+		if !ready.Load() {
+			return errors.New("dial fail")
+		}
+		return nil
+	})
+	defer ehealth.Unregister("test.redis")
+
+	reg := &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	unregister := app.registerService(context.Background(), &testServer{})
+	defer unregister()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.registered))
+	ready.Store(true)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+}
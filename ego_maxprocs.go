@@ -0,0 +1,16 @@
+//go:build !ego_nomaxprocs
+
+package ego
+
+import (
+	"go.uber.org/automaxprocs/maxprocs"
+
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// setMaxProcs 根据cgroup的CPU限制设置GOMAXPROCS
+func setMaxProcs() {
+	if _, err := maxprocs.Set(); err != nil {
+		elog.EgoLogger.Error("init max procs", elog.FieldComponent("app"), elog.FieldErr(err))
+	}
+}
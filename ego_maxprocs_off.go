@@ -0,0 +1,6 @@
+//go:build ego_nomaxprocs
+
+package ego
+
+// setMaxProcs 使用ego_nomaxprocs构建时保持Go的默认值，可以通过ego.maxProc配置
+func setMaxProcs() {}
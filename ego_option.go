@@ -1,13 +1,23 @@
 package ego
 
 import (
+	"context"
 	"os"
 	"time"
+
+	"github.com/gotomicro/ego/server"
 )
 
 // Option overrides a Container's default configuration.
 type Option func(a *Ego)
 
+// WithContext 设置根context，当该context被取消时，会与收到SIGTERM信号一样触发优雅退出
+func WithContext(ctx context.Context) Option {
+	return func(a *Ego) {
+		a.opts.ctx = ctx
+	}
+}
+
 // WithHang 是否允许系统悬挂起来，0 表示不悬挂， 1 表示悬挂。目的是一些脚本操作的时候，不想主线程停止
 func WithHang(flag bool) Option {
 	return func(a *Ego) {
@@ -22,6 +32,13 @@ func WithDisableBanner(disableBanner bool) Option {
 	}
 }
 
+// WithBanner 设置banner模板，使用 text/template 语法，变量参考 StartupSummary，例如 {{.App}} {{range .Servers}}{{.Address}}{{end}}
+func WithBanner(banner string) Option {
+	return func(a *Ego) {
+		a.opts.banner = banner
+	}
+}
+
 // WithArguments 传入arguments
 func WithArguments(arguments []string) Option {
 	return func(a *Ego) {
@@ -70,3 +87,27 @@ func WithShutdownSignal(signals ...os.Signal) Option {
 		e.opts.shutdownSignals = append(e.opts.shutdownSignals, signals...)
 	}
 }
+
+// WithUpgradeSignal 设置热升级信号量并开启热升级，默认不开启，ego.upgrade.enable=true 时监听SIGUSR2
+func WithUpgradeSignal(signals ...os.Signal) Option {
+	return func(e *Ego) {
+		e.opts.upgradeSignals = signals
+	}
+}
+
+// WithUpgradeTimeout 设置热升级等待子进程报告的超时时间，默认30s
+func WithUpgradeTimeout(timeout time.Duration) Option {
+	return func(e *Ego) {
+		e.opts.upgradeTimeout = timeout
+	}
+}
+
+// AdmissionCheck 服务注册前的准入检查，返回错误时不注册，按配置重试
+type AdmissionCheck func(ctx context.Context, s server.Server) error
+
+// WithAdmissionCheck 设置服务注册前的准入检查，例如缓存未预热、数据迁移未完成时拒绝注册
+func WithAdmissionCheck(fns ...AdmissionCheck) Option {
+	return func(e *Ego) {
+		e.opts.admissionChecks = append(e.opts.admissionChecks, fns...)
+	}
+}
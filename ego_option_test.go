@@ -1,12 +1,15 @@
 package ego
 
 import (
+	"context"
 	"os"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
 )
 
 func TestWithHang(t *testing.T) {
@@ -46,6 +49,17 @@ func TestWithHang(t *testing.T) {
 	}
 }
 
+func TestWithContext(t *testing.T) {
+	app := New()
+	assert.Equal(t, context.Background(), app.opts.ctx)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "ego")
+	app = New(WithContext(ctx))
+	assert.Equal(t, ctx, app.opts.ctx)
+	assert.Equal(t, "ego", app.ctx.Value(ctxKey{}))
+}
+
 func TestWithArguments(t *testing.T) {
 	// arguments default
 	app := New()
@@ -202,3 +216,15 @@ func TestWithShutdownSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestWithUpgradeSignal(t *testing.T) {
+	// 默认不开启热升级，SIGUSR2保持默认的处理方式
+	app := New()
+	assert.Empty(t, app.upgradeSignals())
+
+	t.Setenv(constant.EgoUpgradeEnable, "true")
+	assert.Equal(t, upgradeSignals, app.upgradeSignals())
+
+	app = New(WithUpgradeSignal(syscall.SIGHUP))
+	assert.Equal(t, []os.Signal{syscall.SIGHUP}, app.upgradeSignals())
+}
@@ -0,0 +1,182 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
+)
+
+// JobPhase 生命周期任务的执行阶段
+type JobPhase string
+
+const (
+	// JobPhasePreStart 服务启动前执行，例如数据迁移
+	JobPhasePreStart JobPhase = "preStart"
+	// JobPhasePostStart 所有服务健康后执行，例如预热缓存、注册到外部系统
+	JobPhasePostStart JobPhase = "postStart"
+	// JobPhaseOnStopped 服务停止后执行，例如从外部系统注销
+	JobPhaseOnStopped JobPhase = "onStopped"
+)
+
+// FailurePolicy 生命周期任务失败后的处理策略
+type FailurePolicy string
+
+const (
+	// FailurePolicyStop preStart失败时不启动服务，postStart失败时优雅停止应用，onStopped失败时由Run返回错误
+	FailurePolicyStop FailurePolicy = "stop"
+	// FailurePolicyIgnore 记录日志后继续运行
+	FailurePolicyIgnore FailurePolicy = "ignore"
+)
+
+// PhaseJob 在应用生命周期的某个阶段执行的任务，同一阶段的任务按照注册顺序依次执行
+type PhaseJob struct {
+	Name          string                          // 任务名称
+	Phase         JobPhase                        // 执行阶段
+	Timeout       time.Duration                   // 每次执行的超时时间，0为不限制
+	Retries       int                             // 失败后的重试次数
+	FailurePolicy FailurePolicy                   // 失败后的处理策略，默认stop
+	Run           func(ctx context.Context) error // 任务函数
+}
+
+// PhaseJob 设置生命周期任务，任务无效时panic
+func (e *Ego) PhaseJob(jobs ...PhaseJob) *Ego {
+	if err := e.PhaseJobE(jobs...); err != nil {
+		e.logger.Panic("phase job invalid", elog.FieldComponent("app"), elog.FieldErr(err))
+	}
+	return e
+}
+
+// PhaseJobE 设置生命周期任务，任务无效时返回错误，所有任务都不会注册
+func (e *Ego) PhaseJobE(jobs ...PhaseJob) error {
+	for _, job := range jobs {
+		if job.Run == nil {
+			return fmt.Errorf("phase job %s run func nil", job.Name)
+		}
+		switch job.Phase {
+		case JobPhasePreStart, JobPhasePostStart, JobPhaseOnStopped:
+		default:
+			return fmt.Errorf("phase job %s phase %q invalid", job.Name, job.Phase)
+		}
+	}
+	for _, job := range jobs {
+		if job.FailurePolicy == "" {
+			job.FailurePolicy = FailurePolicyStop
+		}
+		e.phaseJobs = append(e.phaseJobs, job)
+	}
+	return nil
+}
+
+// runPhaseJobs 依次执行某个阶段的任务，返回策略为stop的任务的错误
+func (e *Ego) runPhaseJobs(ctx context.Context, phase JobPhase) error {
+	var errs []error
+	for _, job := range e.phaseJobs {
+		if job.Phase != phase {
+			continue
+		}
+		fields := []elog.Field{elog.FieldComponent("app"), elog.FieldName(job.Name), elog.String("phase", string(phase))}
+		beg := time.Now()
+		err := runPhaseJob(ctx, job)
+		if err == nil {
+			e.logger.Info("phase job success", append(fields, elog.FieldCost(time.Since(beg)))...)
+			continue
+		}
+		fields = append(fields, elog.FieldCost(time.Since(beg)), elog.FieldErr(err))
+		if job.FailurePolicy == FailurePolicyIgnore {
+			e.logger.Warn("phase job fail, ignore", fields...)
+			continue
+		}
+		e.logger.Error("phase job fail", fields...)
+		errs = append(errs, fmt.Errorf("phase job %s fail, %w", job.Name, err))
+		// preStart、postStart失败后不再执行后面的任务；onStopped需要尽量执行所有清理
+		if phase != JobPhaseOnStopped {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPhaseJob 执行任务，失败时按照Retries重试
+func runPhaseJob(ctx context.Context, job PhaseJob) (err error) {
+	for attempt := 0; attempt <= job.Retries; attempt++ {
+		if err = runPhaseJobOnce(ctx, job); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func runPhaseJobOnce(ctx context.Context, job PhaseJob) (err error) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, job.Timeout, fmt.Errorf("phase job timeout %v", job.Timeout))
+		defer cancel()
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			stack := make([]byte, 4096)
+			length := runtime.Stack(stack, false)
+			err = fmt.Errorf("panic: %v\n%s", rec, stack[:length])
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// startPostStartJobs 所有服务健康后执行postStart任务，失败时优雅停止应用
+func (e *Ego) startPostStartJobs() {
+	if !e.hasPhaseJobs(JobPhasePostStart) {
+		return
+	}
+	go func() {
+		if !e.waitServersHealthy(e.ctx) {
+			return
+		}
+		if err := e.runPhaseJobs(e.ctx, JobPhasePostStart); err != nil {
+			ctx, cancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
+			defer cancel()
+			_ = e.Shutdown(ctx)
+		}
+	}()
+}
+
+// waitServersHealthy 等待所有服务健康，没有实现Health的服务直接通过，ctx取消时返回false
+func (e *Ego) waitServersHealthy(ctx context.Context) bool {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if e.serversHealthy(ctx) {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Ego) serversHealthy(ctx context.Context) bool {
+	e.smu.RLock()
+	servers := append([]server.Server{}, e.servers...)
+	e.smu.RUnlock()
+	for _, s := range servers {
+		if checkHealth(ctx, s) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Ego) hasPhaseJobs(phase JobPhase) bool {
+	for _, job := range e.phaseJobs {
+		if job.Phase == phase {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseJob(t *testing.T) {
+	t.Run("phases run in order", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			phases []JobPhase
+		)
+		record := func(phase JobPhase) func(context.Context) error {
+			return func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				phases = append(phases, phase)
+				return nil
+			}
+		}
+		svc := &healthServer{healthy: 0}
+		app := New().Serve(svc).PhaseJob(
+			PhaseJob{Name: "stopped", Phase: JobPhaseOnStopped, Run: record(JobPhaseOnStopped)},
+			PhaseJob{Name: "warmup", Phase: JobPhasePostStart, Run: record(JobPhasePostStart)},
+			PhaseJob{Name: "migrate", Phase: JobPhasePreStart, Run: record(JobPhasePreStart)},
+		)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			// 服务健康前不执行postStart
+			mu.Lock()
+			assert.Equal(t, []JobPhase{JobPhasePreStart}, phases)
+			mu.Unlock()
+			atomic.StoreInt32(&svc.healthy, 1)
+			time.Sleep(200 * time.Millisecond)
+			_ = app.Stop(context.Background(), true)
+		}()
+		assert.NoError(t, app.Run())
+		assert.Equal(t, []JobPhase{JobPhasePreStart, JobPhasePostStart, JobPhaseOnStopped}, phases)
+	})
+
+	t.Run("preStart failure stops run", func(t *testing.T) {
+		var started int32
+		app := New().Serve(&testServer{}).PhaseJob(
+			PhaseJob{Name: "migrate", Phase: JobPhasePreStart, Run: func(context.Context) error {
+				return errors.New("migration fail")
+			}},
+			PhaseJob{Name: "next", Phase: JobPhasePreStart, Run: func(context.Context) error {
+				atomic.AddInt32(&started, 1)
+				return nil
+			}},
+		)
+		err := app.Run()
+		assert.EqualError(t, err, "phase job migrate fail, migration fail")
+		assert.Equal(t, int32(0), atomic.LoadInt32(&started))
+	})
+
+	t.Run("postStart failure shuts down", func(t *testing.T) {
+		app := New().Serve(&testServer{}).PhaseJob(
+			PhaseJob{Name: "register", Phase: JobPhasePostStart, Retries: 2, Run: func(context.Context) error {
+				return errors.New("external system unavailable")
+			}},
+		)
+		done := make(chan error, 1)
+		go func() { done <- app.Run() }()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+			assert.True(t, app.stopInfo.isGracefulStop)
+		case <-time.After(3 * time.Second):
+			t.Fatal("app not stopped after postStart failure")
+		}
+	})
+
+	t.Run("onStopped error returned by run", func(t *testing.T) {
+		var cleaned int32
+		app := New().Serve(&testServer{}).PhaseJob(
+			PhaseJob{Name: "deregister", Phase: JobPhaseOnStopped, Run: func(context.Context) error {
+				return errors.New("deregister fail")
+			}},
+			PhaseJob{Name: "ignore", Phase: JobPhaseOnStopped, FailurePolicy: FailurePolicyIgnore, Run: func(context.Context) error {
+				atomic.AddInt32(&cleaned, 1)
+				return errors.New("ignored")
+			}},
+		)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = app.Stop(context.Background(), true)
+		}()
+		assert.EqualError(t, app.Run(), "phase job deregister fail, deregister fail")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&cleaned))
+	})
+}
+
+func Test_runPhaseJob(t *testing.T) {
+	var attempts int32
+	err := runPhaseJob(context.Background(), PhaseJob{Retries: 2, Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		atomic.AddInt32(&attempts, 1)
+		<-ctx.Done()
+		return context.Cause(ctx)
+	}})
+	assert.EqualError(t, err, "phase job timeout 10ms")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	err = runPhaseJob(context.Background(), PhaseJob{Run: func(context.Context) error {
+		panic("boom")
+	}})
+	assert.ErrorContains(t, err, "panic: boom")
+}
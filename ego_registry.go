@@ -0,0 +1,317 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/client/enotify"
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+// registryConfig 服务注册相关配置
+type registryConfig struct {
+	HealthGate    bool          // 是否在服务Health()为true后才注册
+	ReadyGate     bool          // 是否在所有关键组件就绪后才注册，包括其他服务、定时任务和客户端组件，默认开启，设置 ego.registry.readyGate=false 关闭
+	WarmUp        time.Duration // 健康后等待的预热时间，等待缓存等数据填充
+	MaxRetry      int           // 准入检查最大重试次数，小于0时一直重试，直到服务停止，默认60次，开启readyGate时默认一直重试
+	RetryInterval time.Duration // 准入检查重试间隔
+	// 续期连续失败多少次后认为注册丢失，标记为未就绪并重新注册
+	KeepAliveFailureThreshold int
+	MinBackoff                time.Duration // 重新注册的最小退避时间
+	MaxBackoff                time.Duration // 重新注册的最大退避时间
+	OnUnavailable             string        // 启动时注册中心不可用的处理方式，failfast、retry、degraded，默认为degraded
+	RetryTimeout              time.Duration // OnUnavailable为retry时重试的截止时间
+}
+
+func loadRegistryConfig() registryConfig {
+	config := registryConfig{
+		HealthGate:    econf.GetBool("ego.registry.healthGate"),
+		ReadyGate:     econf.GetBool("ego.registry.readyGate"),
+		WarmUp:        econf.GetDuration("ego.registry.warmUp"),
+		MaxRetry:      econf.GetInt("ego.admission.maxRetry"),
+		RetryInterval: econf.GetDuration("ego.admission.retryInterval"),
+
+		KeepAliveFailureThreshold: econf.GetInt("ego.registry.keepAliveFailureThreshold"),
+		MinBackoff:                econf.GetDuration("ego.registry.minBackoff"),
+		MaxBackoff:                econf.GetDuration("ego.registry.maxBackoff"),
+		OnUnavailable:             econf.GetString("ego.registry.onUnavailable"),
+		RetryTimeout:              econf.GetDuration("ego.registry.retryTimeout"),
+	}
+	if econf.Get("ego.registry.readyGate") == nil {
+		config.ReadyGate = true
+	}
+	if econf.Get("ego.admission.maxRetry") == nil {
+		config.MaxRetry = 60
+		// 关键组件可能需要较长时间才能就绪，默认一直等待，不放弃注册
+		if config.ReadyGate {
+			config.MaxRetry = -1
+		}
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = time.Second
+	}
+	if config.KeepAliveFailureThreshold <= 0 {
+		config.KeepAliveFailureThreshold = 3
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.OnUnavailable == "" {
+		config.OnUnavailable = StartupDegraded
+	}
+	if config.RetryTimeout <= 0 {
+		config.RetryTimeout = 30 * time.Second
+	}
+	return config
+}
+
+// registerService 将服务注册到注册中心，返回注销函数
+// 存在准入检查、健康检查或者注册中心支持事件通知时，在后台完成注册，避免阻塞服务启动
+func (e *Ego) registerService(ctx context.Context, s server.Server) (unregister func()) {
+	// 后台worker没有监听，不需要注册
+	if s.Info().Kind == constant.ServiceWorker {
+		return func() {}
+	}
+	config := loadRegistryConfig()
+	checks := e.admissionChecks(config)
+	_, isNotifier := e.registerer.(eregistry.EventNotifier)
+	degraded := false
+	if len(checks) == 0 && config.WarmUp <= 0 && !isNotifier {
+		if e.registerOnStartup(ctx, s, config) == nil {
+			return func() {
+				e.unregisterService(s)
+			}
+		}
+		degraded = true
+	}
+
+	regCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	registered := false
+	go func() {
+		defer close(done)
+		if err := e.admit(regCtx, s, checks, config); err != nil {
+			if regCtx.Err() != nil {
+				return
+			}
+			// 重试次数用完后放弃注册，需要告警，避免服务一直不在注册中心而无人感知
+			e.logger.Error("admission check fail, skip register service", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("maxRetry", config.MaxRetry), elog.FieldErr(err))
+			enotify.Alert("admission check fail, skip register service", fmt.Sprintf("server: %s, maxRetry: %d, err: %v", s.Name(), config.MaxRetry, err))
+			egovernor.RecordEvent("registry", fmt.Sprintf("%s skip register, admission check fail: %v", s.Name(), err))
+			return
+		}
+		if config.WarmUp > 0 {
+			e.logger.Info("server warm up", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Duration("warmUp", config.WarmUp))
+			select {
+			case <-regCtx.Done():
+				return
+			case <-time.After(config.WarmUp):
+			}
+		}
+		// 降级启动时在后台重新注册，直到成功或者服务停止
+		if (degraded || e.registerOnStartup(regCtx, s, config) != nil) && !e.reRegisterService(regCtx, s, config) {
+			return
+		}
+		registered = true
+		e.watchRegistry(regCtx, s, config)
+	}()
+	return func() {
+		// 服务已经停止，不再等待准入检查和注册中心事件
+		cancel()
+		<-done
+		if registered {
+			e.unregisterService(s)
+		}
+	}
+}
+
+// unregisterService 从注册中心注销服务
+// 根context取消时ctx也已经取消，注销使用独立的ctx，只受stopTimeout限制
+func (e *Ego) unregisterService(s server.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.stopTimeout)
+	defer cancel()
+	if err := e.registerer.UnregisterService(ctx, s.Info()); err != nil {
+		e.logger.Error("unregister service err", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err))
+	}
+}
+
+// registerOnStartup 启动时注册服务，注册中心不可用时按照 ego.registry.onUnavailable 处理
+// failfast和retry模式下注册失败会panic，degraded模式下返回错误，由调用方在后台重新注册
+func (e *Ego) registerOnStartup(ctx context.Context, s server.Server, config registryConfig) error {
+	err := e.registerer.RegisterService(ctx, s.Info())
+	if err == nil {
+		return nil
+	}
+	fields := []elog.Field{elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.String("mode", config.OnUnavailable), elog.FieldErr(err)}
+	switch config.OnUnavailable {
+	case StartupFailFast:
+		e.logger.Panic("register service err, fail fast", fields...)
+	case StartupRetry:
+		e.logger.Warn("register service err, retry before deadline", append(fields, elog.Duration("timeout", config.RetryTimeout))...)
+		retryCtx, cancel := context.WithTimeout(ctx, config.RetryTimeout)
+		defer cancel()
+		if e.reRegisterService(retryCtx, s, config) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		e.logger.Panic("register service err after retry, fail fast", fields...)
+	}
+	e.logger.Error("register service err, start degraded and register in background", fields...)
+	egovernor.RecordEvent("registry", fmt.Sprintf("%s start degraded: %v", s.Name(), err))
+	return err
+}
+
+func (e *Ego) doRegisterService(ctx context.Context, s server.Server) {
+	if err := e.registerer.RegisterService(ctx, s.Info()); err != nil {
+		e.logger.Error("register service err", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(err))
+	}
+}
+
+// watchRegistry 监听注册中心事件，连接恢复或者续期持续失败后重新注册服务
+// 续期持续失败期间，服务的就绪检查返回失败
+func (e *Ego) watchRegistry(ctx context.Context, s server.Server, config registryConfig) {
+	notifier, ok := e.registerer.(eregistry.EventNotifier)
+	if !ok {
+		return
+	}
+	var lost atomic.Bool
+	healthName := "registry." + s.Name()
+	ehealth.Register(healthName, func(context.Context) error {
+		if lost.Load() {
+			return errors.New("registry keepalive lost")
+		}
+		return nil
+	})
+	defer ehealth.Unregister(healthName)
+
+	failures := 0
+	events := notifier.Events(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			switch event.Type {
+			case eregistry.EventDisconnected:
+				e.logger.Warn("registry disconnected", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldErr(event.Err))
+				egovernor.RecordEvent("registry", fmt.Sprintf("%s disconnected: %v", s.Name(), event.Err))
+			case eregistry.EventReconnected:
+				e.logger.Info("registry reconnected, register service again", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()))
+				egovernor.RecordEvent("registry", s.Name()+" reconnected")
+				failures = 0
+				e.doRegisterService(ctx, s)
+			case eregistry.EventKeepAliveFailed:
+				failures++
+				emetric.RegistryKeepAliveFailureCounter.Inc(s.Name())
+				e.logger.Warn("registry keepalive fail", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("failures", failures), elog.FieldErr(event.Err))
+				if failures < config.KeepAliveFailureThreshold {
+					continue
+				}
+				e.logger.Error("registry keepalive lost, register service again", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("failures", failures), elog.FieldErr(event.Err))
+				enotify.Alert("registry keepalive lost", fmt.Sprintf("server: %s, failures: %d, err: %v", s.Name(), failures, event.Err))
+				egovernor.RecordEvent("registry", fmt.Sprintf("%s keepalive lost, failures: %d, err: %v", s.Name(), failures, event.Err))
+				lost.Store(true)
+				if e.reRegisterService(ctx, s, config) {
+					failures = 0
+					lost.Store(false)
+				}
+			default:
+				e.logger.Info("registry event", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.FieldEvent(event.Type.String()))
+			}
+		}
+	}
+}
+
+// reRegisterService 按指数退避重新注册服务，直到成功或者服务停止
+func (e *Ego) reRegisterService(ctx context.Context, s server.Server, config registryConfig) bool {
+	backoff := config.MinBackoff
+	for attempt := 1; ; attempt++ {
+		err := e.registerer.RegisterService(ctx, s.Info())
+		if err == nil {
+			e.logger.Info("re-register service", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("attempt", attempt))
+			egovernor.RecordEvent("registry", fmt.Sprintf("%s re-registered, attempt: %d", s.Name(), attempt))
+			return true
+		}
+		e.logger.Error("re-register service err", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("attempt", attempt), elog.FieldErr(err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > config.MaxBackoff {
+			backoff = config.MaxBackoff
+		}
+	}
+}
+
+// admissionChecks 返回注册前需要执行的检查，开启healthGate时先检查服务健康，开启readyGate时检查所有组件就绪
+func (e *Ego) admissionChecks(config registryConfig) []AdmissionCheck {
+	var checks []AdmissionCheck
+	if config.HealthGate {
+		checks = append(checks, checkHealth)
+	}
+	if config.ReadyGate {
+		checks = append(checks, checkReady)
+	}
+	return append(checks, e.opts.admissionChecks...)
+}
+
+// checkHealth 没有实现Health的服务直接通过
+func checkHealth(_ context.Context, s server.Server) error {
+	if hs, ok := s.(interface{ Health() bool }); ok && !hs.Health() {
+		return errors.New("server not healthy")
+	}
+	return nil
+}
+
+// admit 执行准入检查，未通过时按 ego.admission 配置重试
+func (e *Ego) admit(ctx context.Context, s server.Server, checks []AdmissionCheck, config registryConfig) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	var err error
+	for attempt := 0; config.MaxRetry < 0 || attempt <= config.MaxRetry; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w, last err: %v", context.Cause(ctx), err)
+			case <-time.After(config.RetryInterval):
+			}
+		}
+		if err = runChecks(ctx, s, checks); err == nil {
+			return nil
+		}
+		e.logger.Warn("admission check not pass", elog.FieldComponent(s.PackageName()), elog.FieldComponentName(s.Name()), elog.Int("attempt", attempt), elog.FieldErr(err))
+	}
+	return err
+}
+
+func runChecks(ctx context.Context, s server.Server, checks []AdmissionCheck) error {
+	for _, fn := range checks {
+		if err := fn(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
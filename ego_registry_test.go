@@ -0,0 +1,282 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/eregistry"
+	"github.com/gotomicro/ego/server"
+)
+
+type countRegistry struct {
+	eregistry.Nop
+	registered   int32
+	unregistered int32
+	failures     int32 // 接下来注册失败的次数
+}
+
+func (r *countRegistry) RegisterService(context.Context, *server.ServiceInfo) error {
+	if atomic.AddInt32(&r.failures, -1) >= 0 {
+		return errors.New("registry unavailable")
+	}
+	atomic.AddInt32(&r.registered, 1)
+	return nil
+}
+
+func (r *countRegistry) UnregisterService(context.Context, *server.ServiceInfo) error {
+	atomic.AddInt32(&r.unregistered, 1)
+	return nil
+}
+
+// ctxRegistry 记录注销时ctx的状态
+type ctxRegistry struct {
+	countRegistry
+	unregisterErr error
+}
+
+func (r *ctxRegistry) UnregisterService(ctx context.Context, info *server.ServiceInfo) error {
+	r.unregisterErr = ctx.Err()
+	return r.countRegistry.UnregisterService(ctx, info)
+}
+
+type notifyRegistry struct {
+	countRegistry
+	events chan eregistry.Event
+}
+
+func (r *notifyRegistry) Events(ctx context.Context) <-chan eregistry.Event {
+	return r.events
+}
+
+type healthServer struct {
+	testServer
+	healthy int32
+}
+
+func (s *healthServer) Health() bool {
+	return atomic.LoadInt32(&s.healthy) == 1
+}
+
+func loadTestConfig(t *testing.T, conf string) {
+	err := econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal)
+	assert.NoError(t, err)
+}
+
+func Test_registerServiceAdmission(t *testing.T) {
+	loadTestConfig(t, `
+[ego.admission]
+maxRetry = 3
+retryInterval = "10ms"
+`)
+	var attempts int32
+	reg := &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	WithAdmissionCheck(func(ctx context.Context, s server.Server) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("cache not warmed")
+		}
+		return nil
+	})(app)
+
+	unregister := app.registerService(context.Background(), &testServer{})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	unregister()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.unregistered))
+}
+
+func Test_registerServiceAdmissionVeto(t *testing.T) {
+	loadTestConfig(t, `
+[ego.admission]
+maxRetry = 2
+retryInterval = "10ms"
+`)
+	var attempts int32
+	reg := &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	WithAdmissionCheck(func(ctx context.Context, s server.Server) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("migration pending")
+	})(app)
+
+	err := app.admit(context.Background(), &testServer{}, app.opts.admissionChecks, loadRegistryConfig())
+	assert.EqualError(t, err, "migration pending")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	// 服务停止时不再等待准入检查，也不注册
+	unregister := app.registerService(context.Background(), &testServer{})
+	unregister()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.registered))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.unregistered))
+}
+
+func Test_registerServiceHealthGate(t *testing.T) {
+	loadTestConfig(t, `
+[ego.registry]
+healthGate = true
+warmUp = "50ms"
+[ego.admission]
+maxRetry = -1
+retryInterval = "10ms"
+`)
+	reg := &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	srv := &healthServer{}
+	unregister := app.registerService(context.Background(), srv)
+	defer unregister()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.registered))
+
+	atomic.StoreInt32(&srv.healthy, 1)
+	healthyAt := time.Now()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+	// 健康后还需要等待预热时间
+	assert.GreaterOrEqual(t, time.Since(healthyAt), 40*time.Millisecond)
+}
+
+func Test_registerServiceReconnect(t *testing.T) {
+	loadTestConfig(t, `
+[ego.registry]
+healthGate = false
+warmUp = "0s"
+`)
+	reg := &notifyRegistry{events: make(chan eregistry.Event)}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	unregister := app.registerService(context.Background(), &testServer{})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+
+	reg.events <- eregistry.Event{Type: eregistry.EventDisconnected, Err: errors.New("lease expired")}
+	reg.events <- eregistry.Event{Type: eregistry.EventReconnected}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 2 }, time.Second, 5*time.Millisecond)
+
+	unregister()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.unregistered))
+}
+
+func Test_registerServiceKeepAliveLost(t *testing.T) {
+	loadTestConfig(t, `
+[ego.registry]
+healthGate = false
+warmUp = "0s"
+keepAliveFailureThreshold = 2
+minBackoff = "10ms"
+maxBackoff = "20ms"
+`)
+	reg := &notifyRegistry{events: make(chan eregistry.Event)}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	srv := &testServer{}
+	unregister := app.registerService(context.Background(), srv)
+	defer unregister()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+	assert.True(t, ehealth.Healthy(ehealth.Check(context.Background())))
+
+	// 续期连续失败达到阈值后，标记为未就绪，并按退避重新注册
+	atomic.StoreInt32(&reg.failures, 3)
+	reg.events <- eregistry.Event{Type: eregistry.EventKeepAliveFailed, Err: errors.New("keepalive timeout")}
+	reg.events <- eregistry.Event{Type: eregistry.EventKeepAliveFailed, Err: errors.New("keepalive timeout")}
+	assert.Eventually(t, func() bool { return !ehealth.Healthy(ehealth.Check(context.Background())) }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool { return ehealth.Healthy(ehealth.Check(context.Background())) }, time.Second, 5*time.Millisecond)
+}
+
+func TestEgo_RegistryMulti(t *testing.T) {
+	etcd, k8s := &countRegistry{}, &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger}
+	app.Registry(etcd, k8s)
+	assert.IsType(t, &eregistry.Multi{}, app.registerer)
+
+	assert.NoError(t, app.registerer.RegisterService(context.Background(), &server.ServiceInfo{}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&etcd.registered))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&k8s.registered))
+}
+
+type workerServer struct {
+	testServer
+}
+
+func (s *workerServer) Info() *server.ServiceInfo {
+	return &server.ServiceInfo{Kind: constant.ServiceWorker}
+}
+
+func Test_registerServiceSkipWorker(t *testing.T) {
+	reg := &countRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	unregister := app.registerService(context.Background(), &workerServer{})
+	unregister()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.registered))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reg.unregistered))
+}
+
+func Test_unregisterServiceAfterRootCanceled(t *testing.T) {
+	loadTestConfig(t, `
+[ego.registry]
+readyGate = false
+`)
+	defer loadTestConfig(t, `
+[ego.registry]
+readyGate = true
+`)
+	reg := &ctxRegistry{}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg, opts: opts{stopTimeout: time.Second}}
+	ctx, cancel := context.WithCancel(context.Background())
+	unregister := app.registerService(ctx, &testServer{})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.registered))
+
+	// 根context取消后，注销使用的ctx仍然有效
+	cancel()
+	unregister()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.unregistered))
+	assert.NoError(t, reg.unregisterErr)
+}
+
+func Test_registerServiceOnUnavailable(t *testing.T) {
+	// 关闭就绪检查，启动时同步注册
+	loadTestConfig(t, `
+[ego.registry]
+readyGate = false
+minBackoff = "10ms"
+retryTimeout = "1s"
+`)
+	defer loadTestConfig(t, `
+[ego.registry]
+readyGate = true
+`)
+	// 默认降级启动，在后台重新注册
+	reg := &countRegistry{failures: 2}
+	app := &Ego{logger: elog.EgoLogger, registerer: reg}
+	unregister := app.registerService(context.Background(), &testServer{})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&reg.registered) == 1 }, time.Second, 5*time.Millisecond)
+	unregister()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.unregistered))
+
+	loadTestConfig(t, `
+[ego.registry]
+minBackoff = "10ms"
+retryTimeout = "1s"
+onUnavailable = "retry"
+`)
+	reg = &countRegistry{failures: 2}
+	app.registerer = reg
+	unregister = app.registerService(context.Background(), &testServer{})
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reg.registered))
+	unregister()
+
+	loadTestConfig(t, `
+[ego.registry]
+onUnavailable = "failfast"
+`)
+	app.registerer = &countRegistry{failures: 1}
+	assert.Panics(t, func() { app.registerService(context.Background(), &testServer{}) })
+}
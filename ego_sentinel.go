@@ -0,0 +1,20 @@
+//go:build !ego_nosentinel
+
+package ego
+
+import (
+	sentinelmetrics "github.com/alibaba/sentinel-golang/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/esentinel"
+)
+
+// initSentinel 启动sentinel
+func (e *Ego) initSentinel() error {
+	if econf.Get(e.opts.configPrefix+"sentinel") != nil {
+		esentinel.Load(e.opts.configPrefix + "sentinel").Build()
+		sentinelmetrics.RegisterSentinelMetrics(prometheus.DefaultRegisterer.(*prometheus.Registry))
+	}
+	return nil
+}
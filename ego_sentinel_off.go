@@ -0,0 +1,16 @@
+//go:build ego_nosentinel
+
+package ego
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// initSentinel 使用ego_nosentinel构建时没有sentinel，配置不生效
+func (e *Ego) initSentinel() error {
+	if econf.Get(e.opts.configPrefix+"sentinel") != nil {
+		e.logger.Warn("sentinel config is ignored, binary is built with tag ego_nosentinel", elog.FieldComponent("app"))
+	}
+	return nil
+}
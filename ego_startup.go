@@ -0,0 +1,131 @@
+package ego
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esetting"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+// 启动时配置中心、注册中心不可用的处理方式
+const (
+	// StartupFailFast 直接启动失败
+	StartupFailFast = "failfast"
+	// StartupRetry 在截止时间之前重试，超时后启动失败
+	StartupRetry = "retry"
+	// StartupDegraded 降级启动，配置使用缓存文件或者最近落盘的快照，服务暂不注册，之后在后台重试
+	StartupDegraded = "degraded"
+)
+
+// startupMaxBackoff 启动时重试数据源的最大间隔
+const startupMaxBackoff = 10 * time.Second
+
+// configHealthName 配置降级启动时注册的健康检查
+const configHealthName = "config"
+
+// loadDataSource 从数据源加载配置，数据源不可用时按照 ego.config.onUnavailable 处理
+// 配置检查模式和配置解析失败时直接返回错误
+func loadDataSource(configAddr string, provider econf.DataSource, parser econf.Unmarshaller, tag econf.ConfigType, checking bool) error {
+	err := econf.LoadFromDataSource(provider, parser, econf.WithTagName(tag))
+	if err == nil || checking || !errors.Is(err, econf.ErrDataSourceUnavailable) {
+		return err
+	}
+	mode := esetting.String("ego.config.onUnavailable")
+	logger := elog.EgoLogger.With(elog.FieldComponent(econf.PackageName), elog.String("addr", configAddr), elog.String("mode", mode))
+	switch mode {
+	case StartupRetry:
+		timeout := esetting.Duration("ego.config.retryTimeout")
+		logger.Warn("config data source unavailable, retry before deadline", elog.Duration("timeout", timeout), elog.FieldErr(err))
+		deadline := time.Now().Add(timeout)
+		for backoff := time.Second; time.Now().Before(deadline); backoff = min(backoff*2, startupMaxBackoff) {
+			time.Sleep(min(backoff, time.Until(deadline)))
+			if err = econf.LoadFromDataSource(provider, parser); err == nil {
+				logger.Info("config data source recovered")
+				return nil
+			}
+			if !errors.Is(err, econf.ErrDataSourceUnavailable) {
+				return err
+			}
+		}
+		logger.Error("config data source unavailable after retry, fail fast", elog.FieldErr(err))
+		return err
+	case StartupDegraded:
+		content, source, offlineErr := offlineConfig()
+		if offlineErr != nil {
+			logger.Error("config data source unavailable and no offline config to start degraded, fail fast", elog.FieldErr(err), elog.String("offlineErr", offlineErr.Error()))
+			return err
+		}
+		if loadErr := econf.LoadFromReader(bytes.NewReader(content), parser); loadErr != nil {
+			logger.Error("config data source unavailable and load offline config fail, fail fast", elog.FieldErr(err), elog.String("offline", source), elog.String("offlineErr", loadErr.Error()))
+			return err
+		}
+		logger.Warn("config data source unavailable, start degraded with offline config", elog.String("offline", source), elog.FieldErr(err))
+		egovernor.RecordEvent("config", "start degraded with offline config "+source)
+		// 降级期间健康状态为degraded，不影响就绪检查
+		var degraded atomic.Bool
+		degraded.Store(true)
+		ehealth.Register(configHealthName, func(context.Context) error {
+			if degraded.Load() {
+				return fmt.Errorf("config data source unavailable, using offline config %s", source)
+			}
+			return nil
+		}, ehealth.WithType("config"), ehealth.WithCritical(false), ehealth.WithTarget(configAddr))
+		go func() {
+			if recoverDataSource(logger, provider, parser, content) {
+				degraded.Store(false)
+			}
+		}()
+		return nil
+	}
+	logger.Error("config data source unavailable, fail fast", elog.FieldErr(err))
+	return err
+}
+
+// offlineConfig 返回离线启动使用的配置，优先使用缓存文件，其次使用最近落盘的快照
+func offlineConfig() ([]byte, string, error) {
+	var errs []error
+	if file := esetting.String("ego.config.cacheFile"); file != "" {
+		content, err := econf.ReadCacheFile(file, []byte(esetting.String("ego.config.cacheSecret")))
+		if err == nil {
+			return content, file, nil
+		}
+		errs = append(errs, err)
+	}
+	if dir := esetting.String("ego.config.snapshotDir"); dir != "" {
+		content, file, err := econf.LatestSnapshot(dir)
+		if err == nil {
+			return content, file, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, "", errors.New("neither ego.config.cacheFile nor ego.config.snapshotDir is set")
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+// recoverDataSource 降级启动后在后台重试数据源，恢复后加载配置、执行 OnChange 回调并开始监听配置变化
+func recoverDataSource(logger *elog.Component, provider econf.DataSource, parser econf.Unmarshaller, offline []byte) bool {
+	for backoff := time.Second; ; backoff = min(backoff*2, startupMaxBackoff) {
+		time.Sleep(backoff)
+		err := econf.LoadFromDataSource(provider, parser)
+		if err == nil {
+			changed := !bytes.Equal(econf.RawConfig(), offline)
+			logger.Info("config data source recovered, leave degraded mode", elog.Bool("changed", changed))
+			egovernor.RecordEvent("config", fmt.Sprintf("data source recovered, changed: %t", changed))
+			return true
+		}
+		logger.Warn("config data source still unavailable", elog.FieldErr(err))
+		if !errors.Is(err, econf.ErrDataSourceUnavailable) {
+			return false
+		}
+	}
+}
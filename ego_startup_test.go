@@ -0,0 +1,89 @@
+package ego
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/esetting"
+)
+
+// flakyDataSource 前failures次读取失败
+type flakyDataSource struct {
+	failures int32
+	content  string
+}
+
+func (d *flakyDataSource) Parse(string, bool) econf.ConfigType { return econf.ConfigTypeToml }
+
+func (d *flakyDataSource) ReadConfig() ([]byte, error) {
+	if atomic.AddInt32(&d.failures, -1) >= 0 {
+		return nil, errors.New("dial etcd timeout")
+	}
+	return []byte(d.content), nil
+}
+
+func (d *flakyDataSource) IsConfigChanged() <-chan struct{} { return make(chan struct{}) }
+
+func (d *flakyDataSource) Close() error { return nil }
+
+func Test_loadDataSource(t *testing.T) {
+	esetting.Register(
+		esetting.Setting{Key: "ego.config.snapshotDir", Env: constant.EgoConfigSnapshotDir},
+		esetting.Setting{Key: "ego.config.onUnavailable", Env: constant.EgoConfigOnUnavailable, Default: StartupFailFast},
+		esetting.Setting{Key: "ego.config.retryTimeout", Env: constant.EgoConfigRetryTimeout, Default: "30s"},
+		esetting.Setting{Key: "ego.config.cacheFile", Env: constant.EgoConfigCacheFile},
+		esetting.Setting{Key: "ego.config.cacheSecret", Env: constant.EgoConfigCacheSecret},
+	)
+	defer econf.Reset()
+	defer ehealth.Unregister(configHealthName)
+
+	// 默认直接失败
+	err := loadDataSource("etcd://", &flakyDataSource{failures: 1, content: `foo = "etcd"`}, toml.Unmarshal, econf.ConfigTypeToml, false)
+	assert.ErrorIs(t, err, econf.ErrDataSourceUnavailable)
+
+	t.Setenv(constant.EgoConfigOnUnavailable, StartupRetry)
+	t.Setenv(constant.EgoConfigRetryTimeout, "3s")
+	assert.NoError(t, loadDataSource("etcd://", &flakyDataSource{failures: 1, content: `foo = "etcd"`}, toml.Unmarshal, econf.ConfigTypeToml, false))
+	assert.Equal(t, "etcd", econf.GetString("foo"))
+
+	// 没有快照时无法降级启动
+	dir := t.TempDir()
+	t.Setenv(constant.EgoConfigOnUnavailable, StartupDegraded)
+	t.Setenv(constant.EgoConfigSnapshotDir, dir)
+	err = loadDataSource("etcd://", &flakyDataSource{failures: 1}, toml.Unmarshal, econf.ConfigTypeToml, false)
+	assert.ErrorIs(t, err, econf.ErrDataSourceUnavailable)
+
+	// 使用快照启动，数据源恢复后加载最新配置
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.20260101000000.000000.v1"), []byte(`foo = "snapshot"`), 0o644))
+	assert.NoError(t, loadDataSource("etcd://", &flakyDataSource{failures: 1, content: `foo = "recovered"`}, toml.Unmarshal, econf.ConfigTypeToml, false))
+	assert.Equal(t, "snapshot", econf.GetString("foo"))
+	assert.Equal(t, ehealth.StatusDegraded, ehealth.Status(ehealth.Check(context.Background())))
+	assert.Eventually(t, func() bool { return econf.GetString("foo") == "recovered" }, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ehealth.StatusOK, ehealth.Status(ehealth.Check(context.Background())))
+
+	// 优先使用加密的缓存文件
+	cache := filepath.Join(t.TempDir(), "config.cache")
+	cached := econf.New()
+	assert.NoError(t, cached.LoadFromDataSource(&flakyDataSource{content: `foo = "cache"`}, toml.Unmarshal))
+	assert.NoError(t, cached.SetCacheFile(cache, []byte("secret")))
+	t.Setenv(constant.EgoConfigCacheFile, cache)
+	t.Setenv(constant.EgoConfigCacheSecret, "secret")
+	assert.NoError(t, loadDataSource("etcd://", &flakyDataSource{failures: 1, content: `foo = "etcd"`}, toml.Unmarshal, econf.ConfigTypeToml, false))
+	assert.Equal(t, "cache", econf.GetString("foo"))
+	assert.Eventually(t, func() bool { return econf.GetString("foo") == "etcd" }, 3*time.Second, 10*time.Millisecond)
+
+	// 配置检查模式不降级
+	err = loadDataSource("etcd://", &flakyDataSource{failures: 1}, toml.Unmarshal, econf.ConfigTypeToml, true)
+	assert.ErrorIs(t, err, econf.ErrDataSourceUnavailable)
+}
@@ -3,10 +3,15 @@ package ego
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/gotomicro/ego/client/ecloudevents"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/estophook"
 	"github.com/gotomicro/ego/server"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,7 +46,133 @@ func TestEgoRun(t *testing.T) {
 		err := app.Run()
 		assert.EqualError(t, err, "when server call stop error")
 	})
+
+	t.Run("ego run stop by root context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		app := New(WithContext(ctx))
+		app.Serve(&testServer{})
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			cancel()
+		}()
+		err := app.Run()
+		assert.NoError(t, err)
+		assert.True(t, app.stopInfo.isGracefulStop)
+	})
+}
+
+func TestEgoShutdown(t *testing.T) {
+	t.Run("shutdown is idempotent and awaitable", func(t *testing.T) {
+		app := New()
+		app.Serve(&testServer{GstopErr: fmt.Errorf("when server call graceful stop error")})
+		go func() {
+			_ = app.Run()
+		}()
+		time.Sleep(time.Millisecond * 100)
+
+		errs := make(chan error, 3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				errs <- app.Shutdown(context.Background())
+			}()
+		}
+		for i := 0; i < 3; i++ {
+			assert.EqualError(t, <-errs, "when server call graceful stop error")
+		}
+		// 停止流程结束后再次调用，直接返回相同的结果
+		assert.EqualError(t, app.Shutdown(context.Background()), "when server call graceful stop error")
+		assert.NoError(t, app.Stop(context.Background(), true))
+	})
+
+	t.Run("shutdown returns when ctx expires", func(t *testing.T) {
+		app := New()
+		app.Serve(&testServer{GstopBlockTime: time.Second})
+		go func() {
+			_ = app.Run()
+		}()
+		time.Sleep(time.Millisecond * 100)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		err := app.Shutdown(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("concurrent shutdown stops on framework ctx", func(t *testing.T) {
+		app := New(WithStopTimeout(time.Second))
+		app.Serve(&ctxStopServer{blockTime: time.Millisecond * 200})
+		go func() {
+			_ = app.Run()
+		}()
+		time.Sleep(time.Millisecond * 100)
+
+		// 调用方ctx先超时，停止流程不受影响
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+				defer cancel()
+				assert.ErrorIs(t, app.Shutdown(ctx), context.DeadlineExceeded)
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, app.Shutdown(context.Background()))
+		assert.True(t, app.getStopInfo().isGracefulStop)
+	})
 }
+
+// ctxStopServer 停止时等待blockTime，ctx先结束时返回ctx的错误
+type ctxStopServer struct {
+	testServer
+	blockTime time.Duration
+}
+
+func (s *ctxStopServer) GracefulStop(ctx context.Context) error {
+	select {
+	case <-time.After(s.blockTime):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestEgoStopHooks(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "phases")
+	hook := func(phase string) estophook.Option {
+		return estophook.WithHook(estophook.HookConfig{Phase: phase, Command: []string{"/bin/sh", "-c", "echo $EGO_STOP_PHASE >> " + out}})
+	}
+	app := New()
+	app.stopHooks = estophook.DefaultContainer().Build(hook(estophook.PhaseCleanupDone), hook(estophook.PhaseServersStopped), hook(estophook.PhaseDrainStart))
+	app.Serve(&testServer{})
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		_ = app.Stop(context.Background(), true)
+	}()
+	assert.NoError(t, app.Run())
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "drainStart\nserversStopped\ncleanupDone\n", string(data))
+}
+
+func TestEgoCloudEvents(t *testing.T) {
+	var types []string
+	comp := ecloudevents.DefaultContainer().Build(ecloudevents.WithSink("test", ecloudevents.SinkFunc(func(ctx context.Context, event *ecloudevents.Event) error {
+		types = append(types, event.Type)
+		return nil
+	})))
+	app := New()
+	app.Serve(&testServer{})
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		_ = app.Stop(context.Background(), true)
+	}()
+	assert.NoError(t, app.Run())
+	assert.NoError(t, comp.Close())
+	assert.Equal(t, []string{ecloudevents.TypeStarted, ecloudevents.TypeReady, ecloudevents.TypeStopping, ecloudevents.TypeStopped}, types)
+}
+
 func TestEgoNew(t *testing.T) {
 	app := New()
 	assert.NotNil(t, app.logger)
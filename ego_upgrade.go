@@ -0,0 +1,81 @@
+package ego
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+
+	"github.com/gotomicro/ego/client/ecloudevents"
+	"github.com/gotomicro/ego/core/egraceful"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/esetting"
+	"github.com/gotomicro/ego/server/egovernor"
+)
+
+// waitUpgradeSignals 收到热升级信号后启动新的进程，没有开启热升级时不监听，信号量保持默认的处理方式
+func (e *Ego) waitUpgradeSignals() {
+	signals := e.upgradeSignals()
+	if len(signals) == 0 {
+		return
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, signals...)
+	go func() {
+		defer signal.Stop(sig)
+		for {
+			select {
+			case <-sig:
+				_ = e.upgrade(context.Background())
+			case <-e.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// upgradeSignals WithUpgradeSignal设置的信号量，没有设置时 ego.upgrade.enable 开启后使用默认的信号量
+func (e *Ego) upgradeSignals() []os.Signal {
+	if e.opts.upgradeSignals != nil {
+		return e.opts.upgradeSignals
+	}
+	if esetting.Bool("ego.upgrade.enable") {
+		return upgradeSignals
+	}
+	return nil
+}
+
+// upgrade 热升级，子进程报告的版本、监听与当前进程兼容时，优雅停止当前进程，否则终止子进程并继续提供服务
+func (e *Ego) upgrade(ctx context.Context) error {
+	if !atomic.CompareAndSwapUint32(&e.upgrading, 0, 1) {
+		return fmt.Errorf("graceful upgrade is already in progress")
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, e.opts.upgradeTimeout, fmt.Errorf("upgrade timeout %v", e.opts.upgradeTimeout))
+	defer cancel()
+	e.logger.Info("graceful upgrade start", elog.FieldComponent(egraceful.PackageName), elog.Any("listeners", egraceful.Listeners()))
+	report, err := egraceful.Upgrade(ctx)
+	if err != nil {
+		e.logger.Error("graceful upgrade abort", elog.FieldComponent(egraceful.PackageName), elog.FieldErr(err))
+		egovernor.RecordEvent("upgrade", "upgrade abort: "+err.Error())
+		// 升级失败后允许再次升级，成功后当前进程会停止，不再允许升级
+		atomic.StoreUint32(&e.upgrading, 0)
+		return err
+	}
+	e.logger.Info("graceful upgrade child ready", elog.FieldComponent(egraceful.PackageName), elog.Int("pid", report.PID), elog.String("version", report.Version), elog.String("configHash", report.ConfigHash))
+	egovernor.RecordEvent("upgrade", fmt.Sprintf("upgrade to pid %d, version %s", report.PID, report.Version))
+	ecloudevents.Emit(ecloudevents.TypeReload, map[string]interface{}{"pid": report.PID, "version": report.Version})
+	// 治理端自身也会被停止，需要异步执行，避免等待当前请求结束
+	go func() {
+		stopCtx, stopCancel := context.WithTimeoutCause(context.Background(), e.opts.stopTimeout, fmt.Errorf("stop timeout %v", e.opts.stopTimeout))
+		defer stopCancel()
+		egraceful.MarkDraining()
+		err := e.Shutdown(stopCtx)
+		if err != nil {
+			e.logger.Error("graceful upgrade stop fail", elog.FieldComponent(egraceful.PackageName), elog.FieldErr(err))
+		}
+		egraceful.MarkCompleted(err)
+	}()
+	return nil
+}
@@ -74,3 +74,37 @@ func GrpcToHTTPStatusCode(statusCode codes.Code) int {
 		return http.StatusInternalServerError
 	}
 }
+
+// HTTPToGrpcStatusCode HTTP Code转gRPC
+func HTTPToGrpcStatusCode(httpStatusCode int) codes.Code {
+	switch httpStatusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		if httpStatusCode >= 200 && httpStatusCode < 300 {
+			return codes.OK
+		}
+		if httpStatusCode >= 400 && httpStatusCode < 500 {
+			return codes.InvalidArgument
+		}
+		return codes.Unknown
+	}
+}
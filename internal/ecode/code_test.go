@@ -158,3 +158,12 @@ func TestGrpcToHTTPStatusCode(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPToGrpcStatusCode(t *testing.T) {
+	assert.Equal(t, codes.OK, HTTPToGrpcStatusCode(http.StatusNoContent))
+	assert.Equal(t, codes.NotFound, HTTPToGrpcStatusCode(http.StatusNotFound))
+	assert.Equal(t, codes.ResourceExhausted, HTTPToGrpcStatusCode(http.StatusTooManyRequests))
+	assert.Equal(t, codes.InvalidArgument, HTTPToGrpcStatusCode(http.StatusUnprocessableEntity))
+	assert.Equal(t, codes.Unavailable, HTTPToGrpcStatusCode(http.StatusServiceUnavailable))
+	assert.Equal(t, codes.Unknown, HTTPToGrpcStatusCode(http.StatusInternalServerError))
+}
@@ -1,8 +1,11 @@
 package ienv
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // EnvOrBool ...
@@ -48,3 +51,53 @@ func EnvOrStr(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// EnvOrDuration returns an env variable's duration value, such as "1s", or the default if not exists
+func EnvOrDuration(envVar string, defaultValue time.Duration) time.Duration {
+	if v, ok := os.LookupEnv(envVar); ok && v != "" {
+		duration, _ := time.ParseDuration(v)
+		return duration
+	}
+	return defaultValue
+}
+
+// EnvOrStrSlice returns an env variable's comma separated values or the default if not exists
+func EnvOrStrSlice(envVar string, defaultValue []string) []string {
+	if v, ok := os.LookupEnv(envVar); ok && v != "" {
+		return SplitStrSlice(v)
+	}
+	return defaultValue
+}
+
+// EnvOrStrMap returns an env variable's comma separated key=value pairs or the default if not exists
+func EnvOrStrMap(envVar string, defaultValue map[string]string) map[string]string {
+	if v, ok := os.LookupEnv(envVar); ok && v != "" {
+		out, _ := SplitStrMap(v)
+		return out
+	}
+	return defaultValue
+}
+
+// SplitStrSlice splits comma separated values and trims spaces, empty values are ignored
+func SplitStrSlice(s string) []string {
+	out := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// SplitStrMap splits comma separated key=value pairs
+func SplitStrMap(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, item := range SplitStrSlice(s) {
+		k, v, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return out, fmt.Errorf("invalid key=value pair %q", item)
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out, nil
+}
@@ -3,6 +3,7 @@ package ienv
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -71,3 +72,23 @@ func TestEnvOrStrHaveEnv(t *testing.T) {
 	flag := EnvOrStr("ego-env-test1", "test1")
 	assert.Equal(t, "test2", flag)
 }
+
+func TestEnvOrDuration(t *testing.T) {
+	assert.Equal(t, time.Second, EnvOrDuration("ego-env-test1", time.Second))
+	t.Setenv("ego-env-test1", "2m")
+	assert.Equal(t, 2*time.Minute, EnvOrDuration("ego-env-test1", time.Second))
+}
+
+func TestEnvOrStrSlice(t *testing.T) {
+	assert.Equal(t, []string{"a"}, EnvOrStrSlice("ego-env-test1", []string{"a"}))
+	t.Setenv("ego-env-test1", "b, c,,")
+	assert.Equal(t, []string{"b", "c"}, EnvOrStrSlice("ego-env-test1", []string{"a"}))
+}
+
+func TestEnvOrStrMap(t *testing.T) {
+	assert.Equal(t, map[string]string{"a": "1"}, EnvOrStrMap("ego-env-test1", map[string]string{"a": "1"}))
+	t.Setenv("ego-env-test1", "b=2, c=")
+	assert.Equal(t, map[string]string{"b": "2", "c": ""}, EnvOrStrMap("ego-env-test1", nil))
+	_, err := SplitStrMap("b")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 框架安装的拦截器名称，可以作为 Placement 的 Before、After 锚点，也可以在配置中修改优先级
+const (
+	InterceptorHealthCheck   = "healthcheck"
+	InterceptorRequestID     = "requestid"
+	InterceptorTrace         = "trace"
+	InterceptorAccess        = "access" // 访问日志、监控和panic恢复
+	InterceptorInflight      = "inflight"
+	InterceptorI18n          = "i18n"
+	InterceptorMaintenance   = "maintenance"
+	InterceptorSentinel      = "sentinel"
+	InterceptorIdentity      = "identity"
+	InterceptorAuthz         = "authz"
+	InterceptorErrorRenderer = "errorrenderer"
+	InterceptorTimeout       = "timeout"
+	InterceptorSlowConsumer  = "slowconsumer"
+)
+
+// PriorityUser 用户拦截器的默认优先级
+const PriorityUser = 1000
+
+// Placement 拦截器在链中的位置，优先级越小越靠外（越先执行），优先级相同时按照添加的顺序
+// 设置了 Before 或者 After 时放在对应拦截器的前面或者后面，忽略优先级，Before 和 After 只能设置一个
+type Placement struct {
+	Priority int
+	Before   string
+	After    string
+}
+
+// ChainItem 生效的拦截器
+type ChainItem struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+}
+
+// Chain 按照位置排序的拦截器链
+type Chain[T any] struct {
+	items    []ChainItem
+	handlers map[string]T
+}
+
+// NewChain 创建拦截器链
+func NewChain[T any]() *Chain[T] {
+	return &Chain[T]{handlers: make(map[string]T)}
+}
+
+// Add 添加拦截器，name在链中需要唯一
+func (c *Chain[T]) Add(name string, placement Placement, handler T) {
+	c.items = append(c.items, ChainItem{Name: name, Priority: placement.Priority, Before: placement.Before, After: placement.After})
+	c.handlers[name] = handler
+}
+
+// Has 链中是否有该名称的拦截器
+func (c *Chain[T]) Has(name string) bool {
+	_, ok := c.handlers[name]
+	return ok
+}
+
+// Build 返回排序后的拦截器和生效的顺序；名称重复、锚点不存在、同时设置 Before 和 After、锚点形成环时返回错误
+// priorities 覆盖对应名称的拦截器的优先级，通常来自配置，链中没有的名称会被忽略
+func (c *Chain[T]) Build(priorities map[string]int) ([]T, []ChainItem, error) {
+	seen := make(map[string]bool, len(c.items))
+	for _, item := range c.items {
+		if seen[item.Name] {
+			return nil, nil, fmt.Errorf("duplicate interceptor %q", item.Name)
+		}
+		seen[item.Name] = true
+	}
+	var ordered, anchored []ChainItem
+	for _, item := range c.items {
+		if p, ok := priorities[item.Name]; ok {
+			item.Priority = p
+		}
+		anchor := item.Before + item.After
+		switch {
+		case item.Before != "" && item.After != "":
+			return nil, nil, fmt.Errorf("interceptor %q can not set both before %q and after %q", item.Name, item.Before, item.After)
+		case anchor == item.Name:
+			return nil, nil, fmt.Errorf("interceptor %q can not be placed relative to itself", item.Name)
+		case anchor != "" && !seen[anchor]:
+			return nil, nil, fmt.Errorf("interceptor %q placed relative to unknown interceptor %q", item.Name, anchor)
+		case anchor != "":
+			anchored = append(anchored, item)
+		default:
+			ordered = append(ordered, item)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority < ordered[j].Priority })
+
+	// 锚点已经在链中时才能插入，一轮没有插入任何拦截器说明锚点形成了环
+	for len(anchored) > 0 {
+		var pending []ChainItem
+		for _, item := range anchored {
+			idx := indexOf(ordered, item.Before+item.After)
+			if idx < 0 {
+				pending = append(pending, item)
+				continue
+			}
+			if item.After != "" {
+				idx++
+			}
+			ordered = append(ordered[:idx], append([]ChainItem{item}, ordered[idx:]...)...)
+		}
+		if len(pending) == len(anchored) {
+			return nil, nil, fmt.Errorf("interceptor placement has a cycle, %v", names(pending))
+		}
+		anchored = pending
+	}
+
+	handlers := make([]T, 0, len(ordered))
+	for _, item := range ordered {
+		handlers = append(handlers, c.handlers[item.Name])
+	}
+	return handlers, ordered, nil
+}
+
+func indexOf(items []ChainItem, name string) int {
+	for i, item := range items {
+		if item.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func names(items []ChainItem) []string {
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, item.Name)
+	}
+	return list
+}
+
+var (
+	chainsMu sync.RWMutex
+	chains   = make(map[string][]ChainItem)
+)
+
+// RegisterChain 记录服务生效的拦截器链，key通常为 "组件名称/链的类型"，可以通过治理端 /debug/chains 查看
+func RegisterChain(key string, items []ChainItem) {
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+	chains[key] = items
+}
+
+// Chains 返回所有服务生效的拦截器链
+func Chains() map[string][]ChainItem {
+	chainsMu.RLock()
+	defer chainsMu.RUnlock()
+	res := make(map[string][]ChainItem, len(chains))
+	for key, items := range chains {
+		res[key] = append([]ChainItem(nil), items...)
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainBuild(t *testing.T) {
+	chain := NewChain[string]()
+	chain.Add(InterceptorTrace, Placement{Priority: 200}, "trace")
+	chain.Add(InterceptorRequestID, Placement{Priority: 100}, "requestid")
+	chain.Add(InterceptorAccess, Placement{Priority: 300}, "access")
+	chain.Add("user.a", Placement{Priority: PriorityUser}, "a")
+	chain.Add("user.b", Placement{Priority: PriorityUser}, "b")
+	chain.Add("auth", Placement{After: InterceptorTrace}, "auth")
+	chain.Add("audit", Placement{Before: "auth"}, "audit")
+
+	handlers, items, err := chain.Build(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"requestid", "trace", "audit", "auth", "access", "a", "b"}, handlers)
+	assert.Equal(t, ChainItem{Name: "auth", After: InterceptorTrace}, items[3])
+
+	// 配置覆盖优先级，不存在的名称被忽略
+	handlers, _, err = chain.Build(map[string]int{InterceptorTrace: 50, "user.b": 10, "unknown": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "trace", "audit", "auth", "requestid", "access", "a"}, handlers)
+}
+
+func TestChainBuildError(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []ChainItem
+		err   string
+	}{
+		{
+			name:  "duplicate",
+			items: []ChainItem{{Name: "a"}, {Name: "a"}},
+			err:   `duplicate interceptor "a"`,
+		},
+		{
+			name:  "both",
+			items: []ChainItem{{Name: "a"}, {Name: "b"}, {Name: "c", Before: "a", After: "b"}},
+			err:   `interceptor "c" can not set both before "a" and after "b"`,
+		},
+		{
+			name:  "self",
+			items: []ChainItem{{Name: "a", After: "a"}},
+			err:   `interceptor "a" can not be placed relative to itself`,
+		},
+		{
+			name:  "unknown",
+			items: []ChainItem{{Name: "a", After: "b"}},
+			err:   `interceptor "a" placed relative to unknown interceptor "b"`,
+		},
+		{
+			name:  "cycle",
+			items: []ChainItem{{Name: "x"}, {Name: "a", After: "b"}, {Name: "b", Before: "a"}},
+			err:   `interceptor placement has a cycle, [a b]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain[int]()
+			for i, item := range tt.items {
+				chain.Add(item.Name, Placement{Priority: item.Priority, Before: item.Before, After: item.After}, i)
+			}
+			_, _, err := chain.Build(nil)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -22,7 +22,10 @@ import (
 
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/egraceful"
+	"github.com/gotomicro/ego/core/eidentity"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
 	"github.com/gotomicro/ego/server"
 )
 
@@ -45,6 +48,12 @@ type Component struct {
 
 func newComponent(name string, config *Config, logger *elog.Component) *Component {
 	gin.SetMode(config.Mode)
+	if config.EnableOfficialGinLog {
+		// gin框架日志是全局的，路由注册的debug日志、gin.Logger中间件都会输出到这里
+		ginLogger := logger.With(elog.FieldComponentName("component.gin"))
+		gin.DefaultWriter = elog.NewWriter(ginLogger, elog.InfoLevel)
+		gin.DefaultErrorWriter = elog.NewWriter(ginLogger, elog.ErrorLevel)
+	}
 	comp := &Component{
 		name:             name,
 		config:           config,
@@ -76,6 +85,11 @@ func (c *Component) Name() string {
 	return c.name
 }
 
+// Address 服务地址，初始化之后为实际监听的地址
+func (c *Component) Address() string {
+	return c.config.Address()
+}
+
 // PackageName 包名
 func (c *Component) PackageName() string {
 	return PackageName
@@ -110,7 +124,7 @@ func (c *Component) defaultListener() error {
 	if c.config.Network == "local" {
 		c.listener = newLocalListener()
 	} else {
-		c.listener, err = net.Listen(c.config.Network, c.config.Address())
+		c.listener, err = egraceful.Listen(c.config.Network, c.config.Address())
 		if err != nil {
 			c.logger.Panic("new egin server err", elog.FieldErrKind("listen err"), elog.FieldErr(err))
 		}
@@ -119,7 +133,11 @@ func (c *Component) defaultListener() error {
 }
 
 // Health implements server.Component interface.
+// 维护模式下返回false
 func (c *Component) Health() bool {
+	if emaintenance.IsEnabled() {
+		return false
+	}
 	cli, err := resty.New().
 		SetHeader("app", eapp.Name()).
 		SetBaseURL("http://"+c.config.Address()).R().SetHeader(healthcheck.DefaultHeaderName, healthcheck.DefaultHeaderValue).Get("/")
@@ -158,8 +176,15 @@ func (c *Component) Start() error {
 		WriteTimeout:      c.config.ServerWriteTimeout,
 		// Handler:           http.TimeoutHandler(c, 1*time.Second, "timeout"),
 	}
+	if c.config.connRecycleEnabled() {
+		c.Server.Handler = c.recycleHandler(c)
+		c.Server.ConnContext = c.connContext
+	}
 	c.mu.Unlock()
-	if c.config.EnableTLS {
+	if c.config.EnableIdentity {
+		c.Server.TLSConfig = eidentity.Default().ServerTLSConfig()
+		err = c.Server.ServeTLS(c.listener, "", "")
+	} else if c.config.EnableTLS {
 		config, errTLS := c.buildTLSConfig()
 		if errTLS != nil {
 			return errTLS
@@ -206,6 +231,9 @@ func (c *Component) Info() *server.ServiceInfo {
 		server.WithAddress(c.listener.Addr().String()),
 		server.WithKind(constant.ServiceProvider),
 	)
+	for key, value := range c.config.Metadata {
+		info.Metadata[key] = value
+	}
 	return &info
 }
 
@@ -0,0 +1,123 @@
+package egin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gotomicro/ego/core/estream"
+)
+
+// SSE 注册Server-Sent Events路由，handler返回后连接关闭
+func (c *Component) SSE(pattern string, handler SSEFunc) gin.IRoutes {
+	return c.GET(pattern, func(ctx *gin.Context) {
+		w := c.NewSSEWriter(ctx)
+		err := handler(w)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = ctx.Error(err)
+		}
+	})
+}
+
+// SSEFunc SSE处理函数
+type SSEFunc func(*SSEWriter) error
+
+// NewSSEWriter 创建SSE的发送方，开启SSESendBufferSize后消息先放入缓冲区，客户端读取太慢时按照SSESlowConsumerPolicy处理
+func (c *Component) NewSSEWriter(ctx *gin.Context) *SSEWriter {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	ctx.Status(http.StatusOK)
+
+	w := &SSEWriter{GinCtx: ctx}
+	config := estream.Config{
+		BufferSize:  c.config.SSESendBufferSize,
+		Policy:      c.config.SSESlowConsumerPolicy,
+		SendTimeout: c.config.SSESendTimeout,
+	}
+	if config.Enabled() {
+		labels := estream.Labels{Type: "sse", Method: ctx.FullPath(), Peer: ctx.ClientIP()}
+		w.sender = estream.NewSender(ctx.Request.Context(), config, labels, func(msg any) error {
+			return w.write(msg.([]byte))
+		})
+	}
+	return w
+}
+
+// SSEWriter SSE的发送方
+type SSEWriter struct {
+	GinCtx *gin.Context
+	sender *estream.Sender
+}
+
+// Context 客户端断开或者慢消费者被断开后取消，handler可以及时停止生产消息
+func (w *SSEWriter) Context() context.Context {
+	if w.sender != nil {
+		return w.sender.Context()
+	}
+	return w.GinCtx.Request.Context()
+}
+
+// Send 发送事件，data为字符串、[]byte时原样发送，其他类型编码为JSON
+// 开启缓冲区时，缓冲区已满按照策略处理，被断开后返回 estream.ErrSlowConsumer
+func (w *SSEWriter) Send(event string, data any) error {
+	payload, err := encodeSSE(event, data)
+	if err != nil {
+		return err
+	}
+	if w.sender != nil {
+		return w.sender.Send(payload)
+	}
+	return w.write(payload)
+}
+
+// Close 等待缓冲区的消息发送完，返回发送失败或者断开的错误
+func (w *SSEWriter) Close() error {
+	if w.sender != nil {
+		return w.sender.Close()
+	}
+	return nil
+}
+
+func (w *SSEWriter) write(payload []byte) error {
+	if _, err := w.GinCtx.Writer.Write(payload); err != nil {
+		return err
+	}
+	w.GinCtx.Writer.Flush()
+	return nil
+}
+
+// encodeSSE 按照SSE协议编码事件，多行数据每行一个data字段
+func encodeSSE(event string, data any) ([]byte, error) {
+	var text string
+	switch v := data.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("encode sse data fail, %w", err)
+		}
+		text = string(raw)
+	}
+	var buf bytes.Buffer
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+package egin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/estream"
+)
+
+func TestSSE(t *testing.T) {
+	for _, bufferSize := range []int{0, 4} {
+		c := DefaultContainer()
+		c.config.SSESendBufferSize = bufferSize
+		comp := c.Build()
+		comp.SSE("/events", func(w *SSEWriter) error {
+			if err := w.Send("greeting", "hello\nworld"); err != nil {
+				return err
+			}
+			return w.Send("", map[string]int{"count": 1})
+		})
+
+		w := httptest.NewRecorder()
+		comp.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "event: greeting\ndata: hello\ndata: world\n\ndata: {\"count\":1}\n\n", w.Body.String())
+	}
+}
+
+// failedWriter 模拟客户端已经断开
+type failedWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (f failedWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestSSESendError(t *testing.T) {
+	c := DefaultContainer()
+	c.config.SSESendBufferSize = 4
+	c.config.SSESlowConsumerPolicy = estream.PolicyDisconnect
+	comp := c.Build()
+	var closeErr error
+	comp.GET("/events", func(ctx *gin.Context) {
+		w := comp.NewSSEWriter(ctx)
+		_ = w.Send("", "hello")
+		closeErr = w.Close()
+	})
+	comp.ServeHTTP(failedWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.EqualError(t, closeErr, "broken pipe")
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/elog"
@@ -338,3 +339,24 @@ func eginClient(ctx context.Context, gin *Component, url string) (err error) {
 	}
 	return nil
 }
+
+func TestOfficialGinLog(t *testing.T) {
+	defaultWriter, defaultErrorWriter := gin.DefaultWriter, gin.DefaultErrorWriter
+	defer func() {
+		gin.DefaultWriter, gin.DefaultErrorWriter = defaultWriter, defaultErrorWriter
+	}()
+
+	core, logs := observer.New(zap.DebugLevel)
+	cfg := DefaultConfig()
+	cfg.EnableOfficialGinLog = true
+	cmp := newComponent("test-cmp", cfg, elog.DefaultContainer().Build(elog.WithZapCore(core)))
+	cmp.Use(gin.Logger())
+	cmp.GET("/hello", func(ctx *gin.Context) {})
+	cmp.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hello", nil))
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, zap.InfoLevel, entries[0].Level)
+	assert.Contains(t, entries[0].Message, "/hello")
+	assert.Equal(t, "component.gin", entries[0].ContextMap()["compName"])
+}
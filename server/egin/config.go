@@ -4,62 +4,95 @@ import (
 	"crypto/tls"
 	"embed"
 	"fmt"
+	"html/template"
 	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/cel-go/cel"
 
 	"github.com/gotomicro/ego/core/eflag"
+	"github.com/gotomicro/ego/core/erequestid"
 	"github.com/gotomicro/ego/core/util/xtime"
+	"github.com/gotomicro/ego/server"
 )
 
 // Config HTTP config
 type Config struct {
-	Host                    string // IP地址，默认0.0.0.0
-	Port                    int    // PORT端口，默认9001
-	Mode                    string // gin的模式，默认是release模式
-	Network                 string
-	ServerReadTimeout       time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
-	ServerReadHeaderTimeout time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
-	ServerWriteTimeout      time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
+	Host                     string // IP地址，默认0.0.0.0
+	Port                     int    // PORT端口，默认9001
+	Mode                     string // gin的模式，默认是release模式
+	Network                  string
+	ServerReadTimeout        time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
+	ServerReadHeaderTimeout  time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
+	ServerWriteTimeout       time.Duration // 服务端，用于读取io报文过慢的timeout，通常用于互联网网络收包过慢，如果你的go在最外层，可以使用他，默认不启用。
+	MaxConnectionAge         time.Duration // 连接的最长存活时间，加上±10%的抖动，超过后HTTP/1.1响应 Connection: close，HTTP/2发送GOAWAY，扩容后流量可以逐步均衡，默认不启用
+	MaxRequestsPerConnection int64         // 单个连接处理的最大请求数，超过后和MaxConnectionAge一样关闭连接，默认不启用
 	// ServerHTTPTimout        time.Duration //  这个是HTTP包提供的，可以用于IO，或者密集型计算，做timeout处理，有一次goroutine操作，然后没走一些流程，cancel体验不好，暂时先不用
-	ContextTimeout                time.Duration // 只能用于IO操作，才能触发，默认不启用
-	EnableMetricInterceptor       bool          // 是否开启监控，默认开启
-	EnableTraceInterceptor        bool          // 是否开启链路追踪，默认开启
-	EnableLocalMainIP             bool          // 自动获取ip地址
-	SlowLogThreshold              time.Duration // 服务慢日志，默认500ms
-	EnableAccessInterceptor       bool          // 是否开启，记录请求数据
-	EnableAccessInterceptorReq    bool          // 是否开启记录请求参数，默认不开启
-	AccessInterceptorReqMaxLength int           // 默认4K
-	EnableAccessInterceptorRes    bool          // 是否开启记录响应参数，默认不开启
-	AccessInterceptorResMaxLength int           // 默认4K
-	AccessInterceptorReqResFilter string        // AccessInterceptorReq 过滤器，只有符合过滤器的请求才会记录 Req 和 Res
-	EnableTrustedCustomHeader     bool          // 是否开启自定义header头，记录数据往链路后传递，默认不开启
-	EnableSentinel                bool          // 是否开启限流，默认不开启
-	WebsocketHandshakeTimeout     time.Duration // 握手时间
-	WebsocketReadBufferSize       int           // WebsocketReadBufferSize
-	WebsocketWriteBufferSize      int           // WebsocketWriteBufferSize
-	EnableWebsocketCompression    bool          // 是否开通压缩
-	EnableWebsocketCheckOrigin    bool          // 是否支持跨域
-	EnableTLS                     bool          // 是否进入 https 模式
-	TLSCertFile                   string        // https 证书
-	TLSKeyFile                    string        // https 私钥
-	TLSClientAuth                 string        // https 客户端认证方式默认为 NoClientCert(NoClientCert,RequestClientCert,RequireAnyClientCert,VerifyClientCertIfGiven,RequireAndVerifyClientCert)
-	TLSClientCAs                  []string      // https client的ca，当需要双向认证的时候指定可以倒入自签证书
-	TrustedPlatform               string        // 需要用户换成自己的CDN名字，获取客户端IP地址
-	EmbedPath                     string        // 嵌入embed path数据
-	EnableH2C                     bool          // 开启HTTP2
-	embedFs                       embed.FS      // 需要在build时候注入embed.Fs
+	ContextTimeout                time.Duration          // 只能用于IO操作，才能触发，默认不启用
+	EnableMetricInterceptor       bool                   // 是否开启监控，默认开启
+	EnableTraceInterceptor        bool                   // 是否开启链路追踪，默认开启
+	EnableLocalMainIP             bool                   // 自动获取ip地址
+	SlowLogThreshold              time.Duration          // 服务慢日志，默认500ms
+	EnableSlowDump                bool                   // 是否开启慢请求诊断记录，超过SlowLogThreshold的请求记录耗时分解、处理栈和下游调用，通过governor /debug/slow 查看，默认不开启
+	EnableAccessInterceptor       bool                   // 是否开启，记录请求数据
+	EnableAccessInterceptorReq    bool                   // 是否开启记录请求参数，默认不开启
+	AccessInterceptorReqMaxLength int                    `unit:"size"` // 默认4K
+	EnableAccessInterceptorRes    bool                   // 是否开启记录响应参数，默认不开启
+	AccessInterceptorResMaxLength int                    `unit:"size"` // 默认4K
+	AccessInterceptorReqResFilter string                 // AccessInterceptorReq 过滤器，只有符合过滤器的请求才会记录 Req 和 Res
+	EnableTrustedCustomHeader     bool                   // 是否开启自定义header头，记录数据往链路后传递，默认不开启
+	EnableSentinel                bool                   // 是否开启限流，默认不开启
+	WebsocketHandshakeTimeout     time.Duration          // 握手时间
+	WebsocketReadBufferSize       int                    `unit:"size"` // WebsocketReadBufferSize
+	WebsocketWriteBufferSize      int                    `unit:"size"` // WebsocketWriteBufferSize
+	EnableWebsocketCompression    bool                   // 是否开通压缩
+	EnableWebsocketCheckOrigin    bool                   // 是否支持跨域
+	SSESendBufferSize             int                    // SSE每个连接的发送缓冲区消息数，缓冲区已满时按照SSESlowConsumerPolicy处理，默认0，同步发送
+	SSESlowConsumerPolicy         string                 // 客户端读取太慢时的策略，backpressure阻塞发送、drop丢弃消息、disconnect断开，默认backpressure
+	SSESendTimeout                time.Duration          // backpressure策略下发送最长阻塞时间，超过后断开，默认0，只受ctx控制
+	EnableTLS                     bool                   // 是否进入 https 模式
+	TLSCertFile                   string                 // https 证书
+	TLSKeyFile                    string                 // https 私钥
+	TLSClientAuth                 string                 // https 客户端认证方式默认为 NoClientCert(NoClientCert,RequestClientCert,RequireAnyClientCert,VerifyClientCertIfGiven,RequireAndVerifyClientCert)
+	TLSClientCAs                  []string               // https client的ca，当需要双向认证的时候指定可以倒入自签证书
+	TrustedPlatform               string                 // 需要用户换成自己的CDN名字，获取客户端IP地址
+	EmbedPath                     string                 // 嵌入embed path数据
+	EnableH2C                     bool                   // 开启HTTP2
+	EnableErrorRenderer           bool                   // 是否开启错误响应渲染，根据Accept头返回JSON或者HTML错误页，默认不开启
+	ErrorTemplates                map[string]string      // 错误页HTML模板文件，key为状态码或者default，例如 {"404" = "views/404.html", "default" = "views/error.html"}
+	EnableI18nInterceptor         bool                   // 是否开启国际化，根据query参数、header、Accept-Language协商语言，放入请求ctx，默认不开启
+	EnableAuthzInterceptor        bool                   // 是否开启授权，使用eauthz的规则按路由授权，用户从可信网关的header读取；用户由认证中间件放入ctx时，在认证中间件之后使用 AuthzMiddleware，默认不开启
+	EnableIdentity                bool                   // 是否开启mTLS服务身份认证，使用eidentity默认组件的证书，优先于EnableTLS，对端的SPIFFE ID放入ctx，默认不开启
+	EnableRequestIDInterceptor    bool                   // 是否开启请求ID，沿用请求携带的请求ID或者生成新的请求ID，放入请求ctx、日志、链路和错误响应，默认不开启
+	RequestIDHeader               string                 // 请求ID的header，客户端透传时也使用该header，默认X-Request-ID
+	Metadata                      map[string]string      // 注册到注册中心的服务元数据，用于区分同一个应用中的多个HTTP服务，例如 {"api" = "partner"}
+	InterceptorPriorities         map[string]int         // 覆盖中间件的优先级，越小越先执行，key为中间件名称，例如 {"trace" = 50}，名称不存在时启动失败
+	Routes                        map[string]RouteConfig // 按路由调整中间件，key为请求路径，以*结尾时为前缀匹配，例如 routes."/internal/*".disableMiddlewares = ["access"]，支持热更新
+	EnableOfficialGinLog          bool                   // 是否将gin框架日志gin.DefaultWriter、gin.DefaultErrorWriter输出到elog，[GIN-debug]前缀的使用debug级别，[WARNING]前缀的使用warn级别，默认关闭
+	embedFs                       embed.FS               // 需要在build时候注入embed.Fs
 	TLSSessionCache               tls.ClientSessionCache
+	errorTemplates                map[int]*template.Template // 通过option设置的错误页HTML模板
+	errorRenderer                 *errorRenderer
 	blockFallback                 func(*gin.Context)
 	resourceExtract               func(*gin.Context) string
 	aiReqResCelPrg                cel.Program
-	mu                            sync.RWMutex     // mutex for EnableAccessInterceptorReq、EnableAccessInterceptorRes、AccessInterceptorReqResFilter、aiReqResCelPrg
-	recoveryFunc                  gin.RecoveryFunc // recoveryFunc 处理接口没有被 recover 的 panic，默认返回 500 并且没有任何 response body
-	listener                      net.Listener     // a generic network listener 默认是net.Listen()方法生成,如果有需要自行传入可采用option方式进行替换
+	mu                            sync.RWMutex               // mutex for EnableAccessInterceptorReq、EnableAccessInterceptorRes、AccessInterceptorReqResFilter、aiReqResCelPrg
+	recoveryFunc                  gin.RecoveryFunc           // recoveryFunc 处理接口没有被 recover 的 panic，默认返回 500 并且没有任何 response body
+	listener                      net.Listener               // a generic network listener 默认是net.Listen()方法生成,如果有需要自行传入可采用option方式进行替换
+	requestIDGenerator            func() string              // 生成请求ID，默认32位十六进制随机字符串
+	middlewares                   []placedMiddleware         // 通过 WithMiddleware 注入的中间件
+	routeRules                    atomic.Pointer[routeRules] // 由Routes解析的规则
+}
+
+// placedMiddleware 指定了位置的中间件
+type placedMiddleware struct {
+	name      string
+	placement server.Placement
+	handler   gin.HandlerFunc
 }
 
 // DefaultConfig ...
@@ -78,7 +111,9 @@ func DefaultConfig() *Config {
 		SlowLogThreshold:              xtime.Duration("500ms"),
 		EnableWebsocketCheckOrigin:    false,
 		TrustedPlatform:               "",
+		RequestIDHeader:               erequestid.DefaultHeader,
 		recoveryFunc:                  defaultRecoveryFunc,
+		requestIDGenerator:            erequestid.New,
 	}
 }
 
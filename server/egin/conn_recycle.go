@@ -0,0 +1,48 @@
+package egin
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type connStateKey struct{}
+
+// connState 连接的回收状态，HTTP/2连接上的所有stream共享同一个状态
+type connState struct {
+	deadline time.Time // 零值表示不限制存活时间
+	requests atomic.Int64
+}
+
+// connRecycleEnabled 是否开启了连接回收
+func (config *Config) connRecycleEnabled() bool {
+	return config.MaxConnectionAge > 0 || config.MaxRequestsPerConnection > 0
+}
+
+// connContext 新建连接时记录回收时间，存活时间加上±10%的抖动，避免同一时间建立的连接同时断开
+func (c *Component) connContext(ctx context.Context, _ net.Conn) context.Context {
+	state := &connState{}
+	if age := c.config.MaxConnectionAge; age > 0 {
+		jitter := time.Duration(rand.Int63n(int64(age)/5+1)) - age/10
+		state.deadline = time.Now().Add(age + jitter)
+	}
+	return context.WithValue(ctx, connStateKey{}, state)
+}
+
+// recycleHandler 连接达到最长存活时间或者最大请求数时设置 Connection: close
+// HTTP/1.1在响应之后关闭连接，HTTP/2发送GOAWAY，连接上正在处理的请求不受影响
+func (c *Component) recycleHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if state, ok := r.Context().Value(connStateKey{}).(*connState); ok {
+			requests := state.requests.Add(1)
+			if (c.config.MaxRequestsPerConnection > 0 && requests >= c.config.MaxRequestsPerConnection) ||
+				(!state.deadline.IsZero() && time.Now().After(state.deadline)) {
+				w.Header().Set("Connection", "close")
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,36 @@
+package egin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecycleHandler(t *testing.T) {
+	cmp := DefaultContainer().Build()
+	cmp.config.MaxRequestsPerConnection = 3
+	cmp.config.MaxConnectionAge = time.Hour
+	h := cmp.recycleHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func(ctx context.Context) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		return w.Header().Get("Connection")
+	}
+
+	ctx := cmp.connContext(context.Background(), nil)
+	state := ctx.Value(connStateKey{}).(*connState)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), state.deadline, 6*time.Minute+time.Second)
+	assert.Equal(t, "", serve(ctx))
+	assert.Equal(t, "", serve(ctx))
+	// 达到最大请求数
+	assert.Equal(t, "close", serve(ctx))
+
+	// 超过最长存活时间
+	ctx = cmp.connContext(context.Background(), nil)
+	ctx.Value(connStateKey{}).(*connState).deadline = time.Now().Add(-time.Second)
+	assert.Equal(t, "close", serve(ctx))
+}
@@ -4,14 +4,34 @@ import (
 	"fmt"
 
 	healthcheck "github.com/RaMin0/gin-health-check"
+	"github.com/gin-gonic/gin"
 	"github.com/google/cel-go/cel"
 	"github.com/google/cel-go/checker/decls"
 	rpcpb "google.golang.org/genproto/googleapis/rpc/context/attribute_context"
 
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/efeature"
+	"github.com/gotomicro/ego/core/eidentity"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/erequestid"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/util/xnet"
+	"github.com/gotomicro/ego/server"
+)
+
+// 框架安装的中间件的默认优先级，越小越先执行，用户中间件默认的优先级大于框架中间件
+const (
+	priorityHealthCheck   = 100
+	priorityRequestID     = 200
+	priorityAccess        = 300
+	priorityMaintenance   = 400
+	priorityIdentity      = 500
+	priorityI18n          = 600
+	priorityAuthz         = 700
+	priorityErrorRenderer = 800
+	priorityTimeout       = 850
+	priorityTrace         = 900
+	prioritySentinel      = 950
 )
 
 // Container defines a component instance.
@@ -101,11 +121,42 @@ func (c *Container) Build(options ...Option) *Component {
 		option(c)
 	}
 
-	server := newComponent(c.name, c.config, c.logger)
-	server.Use(healthcheck.Default())
-	server.Use(c.defaultServerInterceptor())
+	comp := newComponent(c.name, c.config, c.logger)
+	// 框架中间件和 WithMiddleware 注入的中间件按照优先级排序，可以通过 InterceptorPriorities 配置修改
+	chain := server.NewChain[gin.HandlerFunc]()
+	add := func(name string, priority int, middleware gin.HandlerFunc) {
+		chain.Add(name, server.Placement{Priority: priority}, middleware)
+	}
+	add(server.InterceptorHealthCheck, priorityHealthCheck, healthcheck.Default())
+	if c.config.EnableRequestIDInterceptor {
+		// 客户端组件使用同一个header透传请求ID
+		erequestid.SetHeader(c.config.RequestIDHeader)
+		add(server.InterceptorRequestID, priorityRequestID, requestIDMiddleware(erequestid.Header(), c.config.requestIDGenerator))
+	}
+	add(server.InterceptorAccess, priorityAccess, c.defaultServerInterceptor())
+	add(server.InterceptorMaintenance, priorityMaintenance, maintenanceMiddleware())
+	if c.config.EnableIdentity {
+		if eidentity.Default() == nil {
+			c.logger.Panic("identity enabled but eidentity component is not built")
+		}
+		add(server.InterceptorIdentity, priorityIdentity, identityMiddleware())
+	}
+	if c.config.EnableI18nInterceptor {
+		add(server.InterceptorI18n, priorityI18n, i18nMiddleware())
+	}
+	if c.config.EnableAuthzInterceptor {
+		add(server.InterceptorAuthz, priorityAuthz, AuthzMiddleware())
+	}
+	if c.config.EnableErrorRenderer {
+		renderer, err := newErrorRenderer(c.config.ErrorTemplates, c.config.errorTemplates)
+		if err != nil {
+			c.logger.Panic("build error renderer fail", elog.FieldErr(err))
+		}
+		c.config.errorRenderer = renderer
+		add(server.InterceptorErrorRenderer, priorityErrorRenderer, renderer.middleware())
+	}
 	if c.config.ContextTimeout > 0 {
-		server.Use(timeoutMiddleware(c.config.ContextTimeout))
+		add(server.InterceptorTimeout, priorityTimeout, timeoutMiddleware(c.config.ContextTimeout))
 	}
 
 	//if c.config.EnableMetricInterceptor {
@@ -113,13 +164,35 @@ func (c *Container) Build(options ...Option) *Component {
 	//}
 
 	if c.config.EnableTraceInterceptor && etrace.IsGlobalTracerRegistered() {
-		server.Use(traceServerInterceptor())
+		add(server.InterceptorTrace, priorityTrace, traceServerInterceptor(c.config.SlowLogThreshold))
 	}
 
-	if c.config.EnableSentinel {
-		server.Use(c.sentinelMiddleware())
+	if c.config.EnableSentinel && efeature.Enabled(efeature.Sentinel) {
+		add(server.InterceptorSentinel, prioritySentinel, c.sentinelMiddleware())
 	}
 
+	// 通过 WithMiddleware 注入的中间件，没有指定位置时放在框架中间件之后；Build 之后通过 Use 添加的中间件始终在最后
+	for _, item := range c.config.middlewares {
+		chain.Add(item.name, item.placement, item.handler)
+	}
+	for name := range c.config.InterceptorPriorities {
+		if !chain.Has(name) {
+			c.logger.Panic("priority of unknown middleware", elog.FieldName(name))
+		}
+	}
+	middlewares, items, err := chain.Build(c.config.InterceptorPriorities)
+	if err != nil {
+		c.logger.Panic("build middleware chain fail", elog.FieldErr(err))
+	}
+	server.RegisterChain(c.name+"/http", items)
+	if err := c.setRoutes(c.config.Routes, chain.Has); err != nil {
+		c.logger.Panic("build route middlewares fail", elog.FieldErr(err))
+	}
+	for i := range middlewares {
+		middlewares[i] = c.switchableMiddleware(items[i].Name, middlewares[i])
+	}
+	comp.Use(middlewares...)
+
 	econf.OnChange(func(newConf *econf.Configuration) {
 		c.config.mu.Lock()
 		cf := newConf.Sub(c.name)
@@ -131,8 +204,16 @@ func (c *Container) Build(options ...Option) *Component {
 				c.logger.Warn("init AccessInterceptorReqResFilter fail", elog.FieldErr(err), elog.String("AccessInterceptorReqResFilter", c.config.AccessInterceptorReqResFilter))
 			}
 		}
+		var routes map[string]RouteConfig
+		err := cf.UnmarshalKey("routes", &routes)
+		if err == nil {
+			err = c.setRoutes(routes, chain.Has)
+		}
+		if err != nil {
+			c.logger.Warn("reload routes fail", elog.FieldErr(err))
+		}
 		c.config.mu.Unlock()
 	})
 
-	return server
+	return comp
 }
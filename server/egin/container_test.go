@@ -5,10 +5,12 @@ import (
 	"testing"
 
 	"github.com/BurntSushi/toml"
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gotomicro/ego/core/econf"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
 )
 
 func TestLoadAndBuild(t *testing.T) {
@@ -25,3 +27,22 @@ EnableSentinel = true`
 	c := load.Build()
 	assert.NotNil(t, c)
 }
+
+func TestMiddlewarePlacement(t *testing.T) {
+	conf := `[http.chain]
+EnableRequestIDInterceptor = true
+[http.chain.interceptorPriorities]
+tenant = 1`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	noop := func(c *gin.Context) { c.Next() }
+	Load("http.chain").Build(
+		WithMiddleware("audit", server.Placement{Before: server.InterceptorAccess}, noop),
+		WithMiddleware("tenant", server.Placement{}, noop),
+		WithMiddleware("user", server.Placement{Priority: server.PriorityUser}, noop),
+	)
+	names := make([]string, 0)
+	for _, item := range server.Chains()["http.chain/http"] {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"tenant", server.InterceptorHealthCheck, server.InterceptorRequestID, "audit", server.InterceptorAccess, server.InterceptorMaintenance, server.InterceptorSentinel, "user"}, names)
+}
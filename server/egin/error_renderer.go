@@ -0,0 +1,148 @@
+package egin
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/ei18n"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// defaultErrorTemplate 没有配置HTML模板时使用的默认错误页
+const defaultErrorTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Code}} {{.Status}}</title></head>
+<body>
+<h1>{{.Code}} {{.Status}}</h1>
+<p>{{.Msg}}</p>
+{{if .TraceID}}<p>Trace ID: <code>{{.TraceID}}</code></p>{{end}}
+{{if .RequestID}}<p>Request ID: <code>{{.RequestID}}</code></p>{{end}}
+</body>
+</html>
+`
+
+// ErrorPage 错误响应数据，JSON响应直接序列化该结构体，HTML模板可以使用该结构体的字段
+type ErrorPage struct {
+	Code      int    `json:"code"`
+	Status    string `json:"-"`
+	Reason    string `json:"reason,omitempty"`
+	Msg       string `json:"msg"`
+	TraceID   string `json:"traceId,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+	Path      string `json:"path"`
+}
+
+// errorRenderer 根据Accept头，对API客户端返回JSON，对浏览器返回HTML错误页
+type errorRenderer struct {
+	templates   map[int]*template.Template // 状态码对应的HTML模板
+	defaultTmpl *template.Template         // 默认HTML模板
+}
+
+// newErrorRenderer 加载错误页模板，files的key为状态码或者default，value为模板文件路径
+func newErrorRenderer(files map[string]string, templates map[int]*template.Template) (*errorRenderer, error) {
+	r := &errorRenderer{
+		templates:   make(map[int]*template.Template),
+		defaultTmpl: template.Must(template.New("error").Parse(defaultErrorTemplate)),
+	}
+	for key, file := range files {
+		tmpl, err := template.ParseFiles(file)
+		if err != nil {
+			return nil, fmt.Errorf("parse error template fail, key: %s, err: %w", key, err)
+		}
+		if key == "default" {
+			r.defaultTmpl = tmpl
+			continue
+		}
+		code, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error template key %s, must be status code or default", key)
+		}
+		r.templates[code] = tmpl
+	}
+	for code, tmpl := range templates {
+		r.templates[code] = tmpl
+	}
+	return r, nil
+}
+
+// middleware 处理函数没有写入响应body，并且返回了错误或者错误状态码时，渲染错误响应
+func (r *errorRenderer) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Size() > 0 {
+			return
+		}
+		code := c.Writer.Status()
+		var err error
+		if last := c.Errors.Last(); last != nil {
+			err = last.Err
+		}
+		if code < http.StatusBadRequest && err == nil {
+			return
+		}
+		r.render(c, code, err)
+	}
+}
+
+// render 渲染错误响应
+func (r *errorRenderer) render(c *gin.Context, code int, err error) {
+	page := newErrorPage(c, code, err)
+	switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) {
+	case gin.MIMEHTML:
+		tmpl, ok := r.templates[page.Code]
+		if !ok {
+			tmpl = r.defaultTmpl
+		}
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(page.Code)
+		if err := tmpl.Execute(c.Writer, page); err != nil {
+			_ = c.Error(err)
+		}
+	default:
+		c.JSON(page.Code, page)
+	}
+}
+
+func newErrorPage(c *gin.Context, code int, err error) ErrorPage {
+	page := ErrorPage{
+		Code:      code,
+		TraceID:   etrace.ExtractTraceID(c.Request.Context()),
+		RequestID: erequestid.FromContext(c.Request.Context()),
+		Path:      c.Request.URL.Path,
+	}
+	// 开启国际化时，按照请求的语言翻译错误信息
+	err = ei18n.LocalizeError(c.Request.Context(), err)
+	egoErr := &eerrors.EgoError{}
+	if errors.As(err, &egoErr) {
+		page.Reason = egoErr.GetReason()
+		page.Msg = egoErr.GetMessage()
+		if code < http.StatusBadRequest {
+			page.Code = egoErr.ToHTTPStatusCode()
+		}
+	} else if err != nil && code < http.StatusBadRequest {
+		page.Code = http.StatusInternalServerError
+	}
+	page.Status = http.StatusText(page.Code)
+	if page.Msg == "" {
+		page.Msg = page.Status
+	}
+	return page
+}
+
+// RenderError 根据Accept头渲染错误响应，对API客户端返回JSON，对浏览器返回HTML错误页
+// 如果没有开启EnableErrorRenderer，使用默认的错误页模板
+func (c *Component) RenderError(ctx *gin.Context, code int, err error) {
+	r := c.config.errorRenderer
+	if r == nil {
+		r, _ = newErrorRenderer(nil, nil)
+	}
+	r.render(ctx, code, err)
+	ctx.Abort()
+}
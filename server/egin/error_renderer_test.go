@@ -0,0 +1,81 @@
+package egin
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/erequestid"
+)
+
+func TestErrorRenderer(t *testing.T) {
+	r, err := newErrorRenderer(nil, map[int]*template.Template{
+		http.StatusNotFound: template.Must(template.New("404").Parse(`not found: {{.Path}}`)),
+	})
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(r.middleware())
+	router.GET("/ego-error", func(c *gin.Context) {
+		_ = c.Error(eerrors.New(int(codes.InvalidArgument), "bad.param", "invalid param"))
+	})
+	router.GET("/unknown", func(c *gin.Context) {
+		_ = c.Error(errors.New("db password leaked"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusBadRequest, "custom")
+	})
+
+	do := func(path, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/ego-error", "application/json")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"code":400,"reason":"bad.param","msg":"invalid param","path":"/ego-error"}`, w.Body.String())
+
+	w = do("/unknown", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "leaked")
+
+	w = do("/ego-error", "text/html")
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<h1>400 Bad Request</h1>")
+
+	w = do("/not-exist", "text/html,application/xhtml+xml")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "not found: /not-exist", w.Body.String())
+
+	w = do("/written", "text/html")
+	assert.Equal(t, "custom", w.Body.String())
+}
+
+func TestErrorRendererRequestID(t *testing.T) {
+	r, err := newErrorRenderer(nil, nil)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(requestIDMiddleware(erequestid.DefaultHeader, erequestid.New), r.middleware())
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(errors.New("fail"))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(erequestid.DefaultHeader, "rid-1")
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"code":500,"msg":"Internal Server Error","requestId":"rid-1","path":"/fail"}`, w.Body.String())
+}
@@ -13,12 +13,11 @@ import (
 	"strings"
 	"time"
 
-	sentinel "github.com/alibaba/sentinel-golang/api"
-	"github.com/alibaba/sentinel-golang/core/base"
 	"github.com/gin-gonic/gin"
 	"github.com/google/cel-go/common/types"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
 	"go.opentelemetry.io/otel/trace"
@@ -27,9 +26,16 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/eauthz"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/ei18n"
+	"github.com/gotomicro/ego/core/eidentity"
+	"github.com/gotomicro/ego/core/einflight"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
 	"github.com/gotomicro/ego/core/emetric"
-	"github.com/gotomicro/ego/core/esentinel"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/eslow"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/transport"
 	"github.com/gotomicro/ego/internal/tools"
@@ -107,6 +113,103 @@ func timeoutMiddleware(timeout time.Duration) func(c *gin.Context) {
 	}
 }
 
+// maintenanceMiddleware 维护模式下，不在白名单内的路由返回503
+func maintenanceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !emaintenance.IsEnabled() || emaintenance.AllowPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(int(emaintenance.RetryAfter().Seconds())))
+		c.Data(http.StatusServiceUnavailable, emaintenance.ContentType(), emaintenance.RenderBody(c.Request.URL.Path))
+		c.Abort()
+	}
+}
+
+// requestIDMiddleware 沿用请求携带的请求ID，没有或者不合法时生成新的请求ID，放入请求ctx，并在响应中返回
+func requestIDMiddleware(header string, generate func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(header)
+		if !erequestid.Valid(requestID) {
+			requestID = generate()
+			c.Request.Header.Set(header, requestID)
+		}
+		c.Request = c.Request.WithContext(erequestid.WithRequestID(c.Request.Context(), requestID))
+		c.Header(header, requestID)
+		c.Next()
+	}
+}
+
+// i18nMiddleware 协商请求的语言，放入请求ctx，通过 ei18n.T 翻译消息
+// AuthzMiddleware 使用eauthz的规则对路由授权，资源为 "方法 路由"，例如 GET /api/users/:id
+// 没有构建eauthz组件时不做授权，未登录返回401，没有权限返回403
+func AuthzMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comp := eauthz.Default()
+		if comp == nil {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx := c.Request.Context()
+		subject, ok := comp.Subject(ctx, c.GetHeader)
+		decision := comp.Authorize(ctx, eauthz.Request{Kind: eauthz.KindHTTP, Resource: c.Request.Method + " " + route, Subject: subject})
+		if decision.Allowed {
+			c.Next()
+			return
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": http.StatusUnauthorized, "msg": "unauthenticated"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "msg": "permission denied"})
+	}
+}
+
+// OverrideMiddleware 按照fn返回的组件替换当前请求ctx中的组件，handler通过 ecomponent.Resolve 获取组件
+// 用于测试中注入fake组件，或者灰度流量使用指向其他地址的客户端，fn返回空时不替换
+func OverrideMiddleware(fn func(c *gin.Context) map[string]any) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if comps := fn(c); len(comps) > 0 {
+			c.Request = c.Request.WithContext(ecomponent.WithOverrides(c.Request.Context(), comps))
+		}
+		c.Next()
+	}
+}
+
+// identityMiddleware 把对端证书中的SPIFFE ID放入ctx，ctx中没有eauthz用户时，使用对端身份作为用户，SPIFFE ID同时作为角色
+func identityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil {
+			c.Next()
+			return
+		}
+		id, err := eidentity.PeerID(*c.Request.TLS)
+		if err != nil {
+			c.Next()
+			return
+		}
+		ctx := eidentity.WithPeerID(c.Request.Context(), id)
+		if _, ok := eauthz.SubjectFromContext(ctx); !ok {
+			ctx = eauthz.WithSubject(ctx, eauthz.Subject{ID: id, Roles: []string{id}})
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func i18nMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if comp := ei18n.Default(); comp != nil {
+			c.Request = c.Request.WithContext(ei18n.WithLocale(c.Request.Context(), comp.NegotiateHTTP(c.Request)))
+		}
+		c.Next()
+	}
+}
+
 // defaultServerInterceptor 默认拦截器，包含日志记录、Recover、监控功能
 // 监控放里面是因为，例如panic会改写http status。这样才能统计准确
 func (c *Container) defaultServerInterceptor() gin.HandlerFunc {
@@ -141,6 +244,27 @@ func (c *Container) defaultServerInterceptor() gin.HandlerFunc {
 			getHeaderValue(ctx, key, c.config.EnableTrustedCustomHeader)
 		}
 
+		// 正在处理的请求数
+		if c.config.EnableMetricInterceptor {
+			defer einflight.Begin(emetric.TypeHTTP, ctx.Request.Method+"."+ctx.FullPath())()
+		}
+
+		// 慢请求诊断记录，需要在日志的defer之后执行，拿到recover之后的状态码
+		if c.config.EnableSlowDump && ctx.GetHeader("Accept") != "text/event-stream" {
+			reqCtx, slow := eslow.Start(ctx.Request.Context(), c.config.SlowLogThreshold)
+			ctx.Request = ctx.Request.WithContext(reqCtx)
+			defer func() {
+				slow.Finish(eslow.Record{
+					Type:      emetric.TypeHTTP,
+					Route:     ctx.Request.Method + "." + ctx.FullPath(),
+					Path:      ctx.Request.URL.RequestURI(),
+					TraceID:   etrace.ExtractTraceID(ctx.Request.Context()),
+					RequestID: erequestid.FromContext(ctx.Request.Context()),
+					Code:      ctx.Writer.Status(),
+				})
+			}()
+		}
+
 		defer func() {
 			cost := time.Since(beg)
 			fields = append(fields,
@@ -163,6 +287,9 @@ func (c *Container) defaultServerInterceptor() gin.HandlerFunc {
 			if etrace.IsGlobalTracerRegistered() {
 				fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx.Request.Context())))
 			}
+			if requestID := erequestid.FromContext(ctx.Request.Context()); requestID != "" {
+				fields = append(fields, elog.FieldRequestID(requestID))
+			}
 
 			c.config.mu.RLock()
 			if c.config.EnableAccessInterceptorReq || c.config.EnableAccessInterceptorRes {
@@ -349,7 +476,7 @@ func (c *Container) metricServerInterceptor(ctx *gin.Context, cost time.Duration
 }
 
 // todo 如果业务崩了，logger recover
-func traceServerInterceptor() gin.HandlerFunc {
+func traceServerInterceptor(slowThreshold time.Duration) gin.HandlerFunc {
 	tracer := etrace.NewTracer(trace.SpanKindServer)
 	attrs := []attribute.KeyValue{
 		semconv.RPCSystemKey.String("http"),
@@ -366,51 +493,25 @@ func traceServerInterceptor() gin.HandlerFunc {
 			semconv.HTTPClientIPKey.String(c.ClientIP()),
 			etrace.CustomTag("http.full_path", c.FullPath()),
 		)
+		if requestID := erequestid.FromContext(ctx); requestID != "" {
+			span.SetAttributes(etrace.CustomTag("http.request_id", requestID))
+		}
 		c.Request = c.Request.WithContext(ctx)
 		c.Header(eapp.EgoTraceIDName(), span.SpanContext().TraceID().String())
+		beg := time.Now()
 		c.Next()
 		span.SetAttributes(
 			semconv.HTTPStatusCodeKey.Int64(int64(c.Writer.Status())),
 		)
-		span.End()
-	}
-}
-
-// sentinelMiddleware returns new gin.HandlerFunc
-// Default resource name is {method}:{path}, such as "GET:/api/users/:id"
-// Default block fallback is returning 429 code
-// Define your own behavior by setting options
-func (c *Container) sentinelMiddleware() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		resourceName := ctx.Request.Method + "." + ctx.FullPath()
-
-		if c.config.resourceExtract != nil {
-			resourceName = c.config.resourceExtract(ctx)
+		if last := c.Errors.Last(); last != nil {
+			etrace.RecordError(span, last.Err)
 		}
-
-		if !esentinel.IsResExist(resourceName) {
-			ctx.Next()
-			return
+		// 服务端只把5xx作为错误，4xx是调用方的问题
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(c.Writer.Status()))
 		}
-
-		entry, err := sentinel.Entry(
-			resourceName,
-			sentinel.WithResourceType(base.ResTypeWeb),
-			sentinel.WithTrafficType(base.Inbound),
-		)
-
-		if err != nil {
-			if c.config.blockFallback != nil {
-				c.config.blockFallback(ctx)
-			} else {
-				ctx.AbortWithStatus(http.StatusTooManyRequests)
-			}
-			return
-		}
-
-		defer entry.Exit()
-
-		ctx.Next()
+		etrace.RecordSlow(span, time.Since(beg), slowThreshold)
+		span.End()
 	}
 }
 
@@ -12,12 +12,18 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/gotomicro/ego/core/eauthz"
+	"github.com/gotomicro/ego/core/ecomponent"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/eslow"
 	"github.com/gotomicro/ego/core/transport"
 )
 
@@ -235,3 +241,126 @@ func Test_copyBody(t *testing.T) {
 		t.Fatalf("ReadFull(r, dst) = %d, %v; want %d, nil", n, err, len(src))
 	}
 }
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(maintenanceMiddleware())
+	router.GET("/hello", func(c *gin.Context) {
+		c.String(200, "hello")
+	})
+	emaintenance.Enable(true)
+	defer emaintenance.Reset()
+
+	w := performRequest(router, "GET", "/hello")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "under maintenance")
+
+	emaintenance.Enable(false)
+	w = performRequest(router, "GET", "/hello")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello", w.Body.String())
+}
+
+func TestAuthzMiddleware(t *testing.T) {
+	eauthz.DefaultContainer().Build(eauthz.WithRules(
+		eauthz.Rule{Resources: []string{"GET /public"}, Roles: []string{"*"}},
+		eauthz.Rule{Resources: []string{"GET /users/:id"}, Roles: []string{"admin"}},
+	))
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if role := c.GetHeader("X-Role"); role != "" {
+			c.Request = c.Request.WithContext(eauthz.WithSubject(c.Request.Context(), eauthz.Subject{ID: "1", Roles: []string{role}}))
+		}
+	}, AuthzMiddleware())
+	router.GET("/public", func(c *gin.Context) { c.String(200, "public") })
+	router.GET("/users/:id", func(c *gin.Context) { c.String(200, "user") })
+
+	w := performRequest(router, "GET", "/public")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = performRequest(router, "GET", "/users/1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("X-Role", "guest")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 资源使用路由模版匹配
+	req = httptest.NewRequest("GET", "/users/2", nil)
+	req.Header.Set("X-Role", "admin")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user", w.Body.String())
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(requestIDMiddleware("X-Req-ID", func() string { return "generated" }))
+	router.GET("/hello", func(c *gin.Context) {
+		c.String(200, erequestid.FromContext(c.Request.Context()))
+	})
+
+	w := performRequest(router, "GET", "/hello")
+	assert.Equal(t, "generated", w.Body.String())
+	assert.Equal(t, "generated", w.Header().Get("X-Req-ID"))
+
+	// 沿用请求携带的请求ID
+	w = performRequest(router, "GET", "/hello", header{Key: "X-Req-ID", Value: "upstream"})
+	assert.Equal(t, "upstream", w.Body.String())
+	assert.Equal(t, "upstream", w.Header().Get("X-Req-ID"))
+
+	// 不合法的请求ID重新生成
+	w = performRequest(router, "GET", "/hello", header{Key: "X-Req-ID", Value: "bad id"})
+	assert.Equal(t, "generated", w.Body.String())
+}
+
+func TestSlowDump(t *testing.T) {
+	router := gin.New()
+	container := DefaultContainer()
+	container.config.EnableSlowDump = true
+	container.config.SlowLogThreshold = 5 * time.Millisecond
+	container.Build(WithLogger(elog.DefaultContainer().Build(elog.WithEnableAsync(false))))
+	router.Use(requestIDMiddleware("X-Req-ID", func() string { return "slow-dump" }))
+	router.Use(container.defaultServerInterceptor())
+	router.GET("/slow/:id", func(c *gin.Context) {
+		eslow.AddCall(c.Request.Context(), eslow.Call{Type: "http", Name: "downstream", Method: "GET./user"}, time.Now(), nil)
+		time.Sleep(20 * time.Millisecond)
+		c.String(http.StatusAccepted, "slow")
+	})
+
+	performRequest(router, "GET", "/slow/1")
+	var record *eslow.Record
+	for _, r := range eslow.Records("") {
+		if r.RequestID == "slow-dump" {
+			record = &r
+			break
+		}
+	}
+	assert.NotNil(t, record)
+	assert.Equal(t, "GET./slow/:id", record.Route)
+	assert.Equal(t, "/slow/1", record.Path)
+	assert.Equal(t, http.StatusAccepted, record.Code)
+	assert.Len(t, record.Calls, 1)
+	assert.NotEmpty(t, record.Stack)
+}
+
+func TestOverrideMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(OverrideMiddleware(func(c *gin.Context) map[string]any {
+		if c.GetHeader("X-Canary") == "" {
+			return nil
+		}
+		return map[string]any{"http.user": "canary"}
+	}))
+	router.GET("/hello", func(c *gin.Context) {
+		c.String(200, ecomponent.Resolve(c.Request.Context(), "http.user", "stable"))
+	})
+
+	w := performRequest(router, "GET", "/hello")
+	assert.Equal(t, "stable", w.Body.String())
+	w = performRequest(router, "GET", "/hello", header{Key: "X-Canary", Value: "1"})
+	assert.Equal(t, "canary", w.Body.String())
+}
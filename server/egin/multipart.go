@@ -0,0 +1,235 @@
+package egin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrRequestTooLarge 请求body超过限制
+	ErrRequestTooLarge = errors.New("multipart request too large")
+	// ErrPartTooLarge 单个文件超过限制
+	ErrPartTooLarge = errors.New("multipart part too large")
+	// ErrTooManyParts part数量超过限制
+	ErrTooManyParts = errors.New("multipart too many parts")
+	// ErrValuesTooLarge 非文件表单字段超过限制
+	ErrValuesTooLarge = errors.New("multipart values too large")
+	// ErrTypeNotAllowed 文件类型不允许上传
+	ErrTypeNotAllowed = errors.New("multipart file type not allowed")
+)
+
+// sniffLen 探测文件类型需要的字节数
+const sniffLen = 512
+
+// MultipartConfig 流式处理multipart请求的限制
+type MultipartConfig struct {
+	MaxTotalSize int64    // 整个请求body的最大字节数，默认128MB
+	MaxPartSize  int64    // 单个文件的最大字节数，默认32MB
+	MaxParts     int      // 最多的part数量，默认32
+	MaxValueSize int64    // 非文件表单字段总的最大字节数，默认1MB
+	AllowedTypes []string // 允许上传的文件类型，根据文件内容探测，以*结尾表示前缀匹配，例如 image/*，为空时不限制
+}
+
+// DefaultMultipartConfig 默认的multipart限制
+func DefaultMultipartConfig() MultipartConfig {
+	return MultipartConfig{
+		MaxTotalSize: 128 << 20,
+		MaxPartSize:  32 << 20,
+		MaxParts:     32,
+		MaxValueSize: 1 << 20,
+	}
+}
+
+// UploadPart 上传的文件
+type UploadPart struct {
+	FieldName   string               // 表单字段名
+	FileName    string               // 客户端上传的文件名
+	ContentType string               // 根据文件内容探测的类型，不信任客户端声明的类型
+	Header      textproto.MIMEHeader // part的header
+	Size        int64                // 已经读取的字节数，处理完成后为文件大小
+	Key         string               // 写入对象存储的key，使用StoragePartHandler时设置
+}
+
+// MultipartResult 流式处理的结果
+type MultipartResult struct {
+	Values url.Values    // 非文件表单字段
+	Files  []*UploadPart // 已经处理的文件
+}
+
+// PartHandler 处理上传的文件，r只能读取一次，读取超过MaxPartSize时返回ErrPartTooLarge
+type PartHandler func(ctx context.Context, part *UploadPart, r io.Reader) error
+
+// StreamMultipart 流式处理multipart请求，文件不会整体缓存在内存或者临时文件中，每个文件依次交给handler处理
+// 返回错误时，result中包含出错前已经处理的文件，需要由调用方决定是否清理
+func StreamMultipart(c *gin.Context, config MultipartConfig, handler PartHandler) (*MultipartResult, error) {
+	result := &MultipartResult{Values: make(url.Values), Files: make([]*UploadPart, 0)}
+	if config.MaxTotalSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxTotalSize)
+	}
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return result, err
+	}
+
+	ctx := c.Request.Context()
+	valueSize := int64(0)
+	for parts := 0; ; parts++ {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, multipartError(err)
+		}
+		if config.MaxParts > 0 && parts >= config.MaxParts {
+			return result, ErrTooManyParts
+		}
+
+		// 非文件表单字段
+		if part.FileName() == "" {
+			var r io.Reader = part
+			if config.MaxValueSize > 0 {
+				r = io.LimitReader(part, config.MaxValueSize-valueSize+1)
+			}
+			value, err := io.ReadAll(r)
+			if err != nil {
+				return result, multipartError(err)
+			}
+			valueSize += int64(len(value))
+			if config.MaxValueSize > 0 && valueSize > config.MaxValueSize {
+				return result, ErrValuesTooLarge
+			}
+			result.Values.Add(part.FormName(), string(value))
+			continue
+		}
+
+		// 根据文件内容探测类型
+		br := bufio.NewReaderSize(part, sniffLen)
+		head, err := br.Peek(sniffLen)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return result, multipartError(err)
+		}
+		upload := &UploadPart{
+			FieldName:   part.FormName(),
+			FileName:    part.FileName(),
+			ContentType: http.DetectContentType(head),
+			Header:      part.Header,
+		}
+		if !allowContentType(config.AllowedTypes, upload.ContentType) {
+			return result, fmt.Errorf("%w, file: %s, type: %s", ErrTypeNotAllowed, upload.FileName, upload.ContentType)
+		}
+
+		lr := &partReader{r: br, part: upload, limit: config.MaxPartSize}
+		if err := handler(ctx, upload, lr); err != nil {
+			return result, multipartError(err)
+		}
+		result.Files = append(result.Files, upload)
+	}
+}
+
+// ObjectStorage 对象存储接口，用于把上传的文件直接流式写入对象存储，size未知时为-1
+type ObjectStorage interface {
+	PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// FileScanner 文件扫描接口，例如病毒扫描，返回错误时拒绝该文件
+type FileScanner interface {
+	Scan(ctx context.Context, part *UploadPart, r io.Reader) error
+}
+
+// FileScannerFunc 函数形式的FileScanner
+type FileScannerFunc func(ctx context.Context, part *UploadPart, r io.Reader) error
+
+// Scan 扫描文件
+func (f FileScannerFunc) Scan(ctx context.Context, part *UploadPart, r io.Reader) error {
+	return f(ctx, part, r)
+}
+
+// StoragePartHandler 把文件流式写入对象存储，keyFunc生成对象的key
+// scanner不为nil时，扫描和写入同时进行，扫描失败会取消写入，已经写入的对象会被删除
+func StoragePartHandler(storage ObjectStorage, keyFunc func(part *UploadPart) string, scanner FileScanner) PartHandler {
+	return func(ctx context.Context, part *UploadPart, r io.Reader) error {
+		part.Key = keyFunc(part)
+		if scanner == nil {
+			return storage.PutObject(ctx, part.Key, r, -1, part.ContentType)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		pr, pw := io.Pipe()
+		scanErr := make(chan error, 1)
+		go func() {
+			err := scanner.Scan(ctx, part, pr)
+			if err != nil {
+				cancel()
+			}
+			// 扫描器可能不读取全部内容，需要继续读取，避免阻塞写入
+			_, _ = io.Copy(io.Discard, pr)
+			scanErr <- err
+		}()
+
+		putErr := storage.PutObject(ctx, part.Key, io.TeeReader(r, pw), -1, part.ContentType)
+		_ = pw.CloseWithError(putErr)
+		if err := <-scanErr; err != nil {
+			if putErr == nil {
+				_ = storage.DeleteObject(context.WithoutCancel(ctx), part.Key)
+			}
+			return fmt.Errorf("scan file %s fail, %w", part.FileName, err)
+		}
+		return putErr
+	}
+}
+
+// partReader 限制单个文件的大小，并记录已经读取的字节数
+type partReader struct {
+	r     io.Reader
+	part  *UploadPart
+	limit int64
+}
+
+func (p *partReader) Read(b []byte) (int, error) {
+	if p.limit > 0 && int64(len(b)) > p.limit-p.part.Size+1 {
+		b = b[:p.limit-p.part.Size+1]
+	}
+	n, err := p.r.Read(b)
+	p.part.Size += int64(n)
+	if p.limit > 0 && p.part.Size > p.limit {
+		return n, fmt.Errorf("%w, file: %s", ErrPartTooLarge, p.part.FileName)
+	}
+	return n, err
+}
+
+func allowContentType(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	for _, pattern := range allowed {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+		if pattern == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// multipartError 把body超过限制的错误转换为ErrRequestTooLarge
+func multipartError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return ErrRequestTooLarge
+	}
+	return err
+}
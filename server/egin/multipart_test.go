@@ -0,0 +1,109 @@
+package egin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *memoryStorage) PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryStorage) DeleteObject(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func newMultipartRequest(t *testing.T, files map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	assert.NoError(t, w.WriteField("name", "ego"))
+	for name, content := range files {
+		fw, err := w.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		_, _ = fw.Write([]byte(content))
+	}
+	assert.NoError(t, w.Close())
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestStreamMultipart(t *testing.T) {
+	storage := &memoryStorage{objects: make(map[string][]byte)}
+	scanner := FileScannerFunc(func(ctx context.Context, part *UploadPart, r io.Reader) error {
+		data, _ := io.ReadAll(r)
+		if strings.Contains(string(data), "virus") {
+			return errors.New("virus found")
+		}
+		return nil
+	})
+	handler := StoragePartHandler(storage, func(part *UploadPart) string {
+		return "upload/" + part.FileName
+	}, scanner)
+
+	upload := func(config MultipartConfig, files map[string]string) (*MultipartResult, error) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = newMultipartRequest(t, files)
+		return StreamMultipart(c, config, handler)
+	}
+
+	config := DefaultMultipartConfig()
+	config.AllowedTypes = []string{"text/*"}
+	res, err := upload(config, map[string]string{"a.txt": "hello ego"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ego", res.Values.Get("name"))
+	assert.Len(t, res.Files, 1)
+	assert.Equal(t, int64(9), res.Files[0].Size)
+	assert.Equal(t, "text/plain; charset=utf-8", res.Files[0].ContentType)
+	assert.Equal(t, []byte("hello ego"), storage.objects["upload/a.txt"])
+
+	// 扫描失败，删除已经写入的对象
+	_, err = upload(config, map[string]string{"b.txt": "a virus inside"})
+	assert.ErrorContains(t, err, "virus found")
+	assert.NotContains(t, storage.objects, "upload/b.txt")
+
+	// 根据内容探测类型，而不是文件名
+	_, err = upload(config, map[string]string{"c.txt": "\x89PNG\r\n\x1a\n0000"})
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+
+	config.MaxPartSize = 4
+	_, err = upload(config, map[string]string{"d.txt": "hello ego"})
+	assert.ErrorIs(t, err, ErrPartTooLarge)
+
+	config = DefaultMultipartConfig()
+	config.MaxTotalSize = 64
+	_, err = upload(config, map[string]string{"e.txt": strings.Repeat("a", 1024)})
+	assert.ErrorIs(t, err, ErrRequestTooLarge)
+
+	config = DefaultMultipartConfig()
+	config.MaxParts = 1
+	_, err = upload(config, map[string]string{"f.txt": "hello"})
+	assert.ErrorIs(t, err, ErrTooManyParts)
+}
@@ -3,12 +3,14 @@ package egin
 import (
 	"crypto/tls"
 	"embed"
+	"html/template"
 	"net"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
 )
 
 // Option overrides a Container's default configuration.
@@ -115,8 +117,34 @@ func WithRecoveryFunc(f gin.RecoveryFunc) Option {
 	}
 }
 
+// WithErrorTemplate 设置状态码对应的错误页HTML模板，需要开启EnableErrorRenderer
+func WithErrorTemplate(code int, tmpl *template.Template) Option {
+	return func(c *Container) {
+		if c.config.errorTemplates == nil {
+			c.config.errorTemplates = make(map[int]*template.Template)
+		}
+		c.config.errorTemplates[code] = tmpl
+	}
+}
+
+// WithRequestIDGenerator 设置请求ID生成函数，例如eid.Component.RequestIDGenerator，设置后会开启EnableRequestIDInterceptor
+func WithRequestIDGenerator(fn func() string) Option {
+	return func(c *Container) {
+		c.config.EnableRequestIDInterceptor = true
+		c.config.requestIDGenerator = fn
+	}
+}
+
 func WithListener(listener net.Listener) Option {
 	return func(c *Container) {
 		c.config.listener = listener
 	}
 }
+
+// WithMiddleware 按照指定的位置注入中间件，name在链中需要唯一，例如放在trace之后 server.Placement{After: server.InterceptorTrace}
+// 没有设置位置时优先级为0，需要放在框架中间件之后时使用 server.Placement{Priority: server.PriorityUser}
+func WithMiddleware(name string, placement server.Placement, middleware gin.HandlerFunc) Option {
+	return func(c *Container) {
+		c.config.middlewares = append(c.config.middlewares, placedMiddleware{name: name, placement: placement, handler: middleware})
+	}
+}
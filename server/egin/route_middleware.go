@@ -0,0 +1,88 @@
+package egin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteConfig 单个路由的配置
+type RouteConfig struct {
+	DisableMiddlewares []string // 该路由不执行的中间件名称，例如 ["access", "authz"]，名称参考 /debug/chains
+}
+
+// routeRule 匹配路由后不执行的中间件
+type routeRule struct {
+	pattern  string
+	prefix   bool
+	disabled map[string]struct{}
+}
+
+// routeRules 按路由禁用中间件的规则，配置更新时整体替换
+type routeRules struct {
+	rules []routeRule
+}
+
+// newRouteRules 解析路由配置，路径以 * 结尾时为前缀匹配，中间件名称不在链中时返回错误
+func newRouteRules(routes map[string]RouteConfig, known func(name string) bool) (*routeRules, error) {
+	res := &routeRules{}
+	for pattern, route := range routes {
+		if len(route.DisableMiddlewares) == 0 {
+			continue
+		}
+		rule := routeRule{pattern: pattern, disabled: make(map[string]struct{}, len(route.DisableMiddlewares))}
+		if strings.HasSuffix(pattern, "*") {
+			rule.pattern, rule.prefix = strings.TrimSuffix(pattern, "*"), true
+		}
+		for _, name := range route.DisableMiddlewares {
+			if !known(name) {
+				return nil, fmt.Errorf("route %s disables unknown middleware %q", pattern, name)
+			}
+			rule.disabled[name] = struct{}{}
+		}
+		res.rules = append(res.rules, rule)
+	}
+	// 顺序固定，便于排查问题
+	sort.Slice(res.rules, func(i, j int) bool { return res.rules[i].pattern < res.rules[j].pattern })
+	return res, nil
+}
+
+// disabled 路径匹配的任意一条规则禁用了该中间件时返回true
+func (r *routeRules) disabled(path string, name string) bool {
+	if r == nil {
+		return false
+	}
+	for _, rule := range r.rules {
+		if _, ok := rule.disabled[name]; !ok {
+			continue
+		}
+		if path == rule.pattern || (rule.prefix && strings.HasPrefix(path, rule.pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setRoutes 校验并替换路由规则，校验失败时保留原来的规则
+func (c *Container) setRoutes(routes map[string]RouteConfig, known func(name string) bool) error {
+	rules, err := newRouteRules(routes, known)
+	if err != nil {
+		return err
+	}
+	c.config.Routes = routes
+	c.config.routeRules.Store(rules)
+	return nil
+}
+
+// switchableMiddleware 按照当前的路由规则决定是否执行中间件
+func (c *Container) switchableMiddleware(name string, middleware gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c.config.routeRules.Load().disabled(ctx.Request.URL.Path, name) {
+			ctx.Next()
+			return
+		}
+		middleware(ctx)
+	}
+}
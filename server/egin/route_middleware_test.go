@@ -0,0 +1,49 @@
+package egin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server"
+)
+
+func TestRouteDisableMiddlewares(t *testing.T) {
+	conf := `[http.route.routes."/internal/*"]
+disableMiddlewares = ["tenant"]`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(conf), toml.Unmarshal))
+	container := Load("http.route")
+	cmp := container.Build(WithMiddleware("tenant", server.Placement{Priority: server.PriorityUser}, func(c *gin.Context) {
+		c.Header("X-Tenant", "default")
+		c.Next()
+	}))
+	cmp.GET("/internal/status", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	cmp.GET("/api/status", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	tenant := func(path string) string {
+		w := httptest.NewRecorder()
+		cmp.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Header().Get("X-Tenant")
+	}
+	assert.Equal(t, "", tenant("/internal/status"))
+	assert.Equal(t, "default", tenant("/api/status"))
+
+	// 热更新时中间件名称不存在，保留原来的规则
+	known := func(name string) bool { return name == "tenant" }
+	assert.EqualError(t, container.setRoutes(map[string]RouteConfig{"/api/status": {DisableMiddlewares: []string{"auth"}}}, known), `route /api/status disables unknown middleware "auth"`)
+	assert.Equal(t, "", tenant("/internal/status"))
+
+	assert.NoError(t, container.setRoutes(map[string]RouteConfig{"/api/status": {DisableMiddlewares: []string{"tenant"}}}, known))
+	assert.Equal(t, "default", tenant("/internal/status"))
+	assert.Equal(t, "", tenant("/api/status"))
+
+	// 启动时中间件名称不存在
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(`[http.unknown.routes."/internal"]
+disableMiddlewares = ["auth"]`), toml.Unmarshal))
+	assert.Panics(t, func() { Load("http.unknown").Build() })
+}
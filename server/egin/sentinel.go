@@ -0,0 +1,51 @@
+//go:build !ego_nosentinel
+
+package egin
+
+import (
+	"net/http"
+
+	sentinel "github.com/alibaba/sentinel-golang/api"
+	"github.com/alibaba/sentinel-golang/core/base"
+	"github.com/gin-gonic/gin"
+
+	"github.com/gotomicro/ego/core/esentinel"
+)
+
+// sentinelMiddleware returns new gin.HandlerFunc
+// Default resource name is {method}:{path}, such as "GET:/api/users/:id"
+// Default block fallback is returning 429 code
+// Define your own behavior by setting options
+func (c *Container) sentinelMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		resourceName := ctx.Request.Method + "." + ctx.FullPath()
+
+		if c.config.resourceExtract != nil {
+			resourceName = c.config.resourceExtract(ctx)
+		}
+
+		if !esentinel.IsResExist(resourceName) {
+			ctx.Next()
+			return
+		}
+
+		entry, err := sentinel.Entry(
+			resourceName,
+			sentinel.WithResourceType(base.ResTypeWeb),
+			sentinel.WithTrafficType(base.Inbound),
+		)
+
+		if err != nil {
+			if c.config.blockFallback != nil {
+				c.config.blockFallback(ctx)
+			} else {
+				ctx.AbortWithStatus(http.StatusTooManyRequests)
+			}
+			return
+		}
+
+		defer entry.Exit()
+
+		ctx.Next()
+	}
+}
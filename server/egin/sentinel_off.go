@@ -0,0 +1,14 @@
+//go:build ego_nosentinel
+
+package egin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// sentinelMiddleware 使用ego_nosentinel构建时没有sentinel，Build不会添加该中间件
+func (c *Container) sentinelMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+	}
+}
@@ -0,0 +1,209 @@
+package egovernor
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/edegrade"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
+)
+
+const (
+	// AdminActionShutdown 优雅停止
+	AdminActionShutdown = "shutdown"
+	// AdminActionReload 热加载
+	AdminActionReload = "reload"
+	// AdminActionUpgrade 热升级，启动新的进程接管监听，兼容性检查通过后优雅停止当前进程
+	AdminActionUpgrade = "upgrade"
+	// AdminActionMaintenance 维护模式
+	AdminActionMaintenance = "maintenance"
+	// AdminActionConfigRollback 配置回滚，version参数为空时回滚到上一个可用的配置
+	AdminActionConfigRollback = "config/rollback"
+	// AdminActionComponentRestart 重建组件，name参数为组件名称
+	AdminActionComponentRestart = "component/restart"
+	// AdminActionDegrade 功能降级开关，feature参数为功能名称
+	AdminActionDegrade = "degrade"
+	// AdminActionProfileUpload 采集profile上传到对象存储，profile参数为profile名称，默认goroutine
+	AdminActionProfileUpload = "profile/upload"
+	// AdminTokenHeader 运维操作的确认token header
+	AdminTokenHeader = "X-Ego-Admin-Token"
+)
+
+// AdminAction 治理端运维操作，params为请求的query参数，返回错误时治理端响应500
+// 对于会停止治理端自身的操作（例如shutdown），需要异步执行，否则治理端会等待当前请求结束而无法及时停止
+type AdminAction func(ctx context.Context, params url.Values) error
+
+// AdminResultAction 返回结果的运维操作，结果以data字段响应，例如上传后的对象地址
+type AdminResultAction func(ctx context.Context, params url.Values) (interface{}, error)
+
+var (
+	adminMu      sync.RWMutex
+	adminToken   string
+	adminActions = make(map[string]AdminResultAction)
+)
+
+func init() {
+	HandleFunc("/admin/"+AdminActionShutdown, adminHandler(AdminActionShutdown))
+	HandleFunc("/admin/"+AdminActionReload, adminHandler(AdminActionReload))
+	HandleFunc("/admin/"+AdminActionUpgrade, adminHandler(AdminActionUpgrade))
+	HandleFunc("/admin/"+AdminActionMaintenance, adminHandler(AdminActionMaintenance))
+	HandleFunc("/admin/"+AdminActionConfigRollback, adminHandler(AdminActionConfigRollback))
+	HandleFunc("/admin/"+AdminActionComponentRestart, adminHandler(AdminActionComponentRestart))
+	HandleFunc("/admin/"+AdminActionDegrade, adminHandler(AdminActionDegrade))
+	HandleFunc("/admin/"+AdminActionProfileUpload, adminHandler(AdminActionProfileUpload))
+	HandleFunc("/maintenance", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": emaintenance.IsEnabled(),
+		})
+	})
+	RegisterAdminAction(AdminActionMaintenance, maintenanceAction)
+	RegisterAdminAction(AdminActionComponentRestart, componentRestartAction)
+	RegisterAdminAction(AdminActionDegrade, degradeAction)
+	RegisterAdminResultAction(AdminActionProfileUpload, profileUploadAction)
+}
+
+// RegisterAdminAction 注册运维操作，重复注册会覆盖之前的操作
+func RegisterAdminAction(name string, action AdminAction) {
+	RegisterAdminResultAction(name, func(ctx context.Context, params url.Values) (interface{}, error) {
+		return nil, action(ctx, params)
+	})
+}
+
+// RegisterAdminResultAction 注册返回结果的运维操作，重复注册会覆盖之前的操作
+func RegisterAdminResultAction(name string, action AdminResultAction) {
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	adminActions[name] = action
+}
+
+func setAdminToken(token string) {
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	adminToken = token
+}
+
+func getAdminAction(name string) (string, AdminResultAction) {
+	adminMu.RLock()
+	defer adminMu.RUnlock()
+	return adminToken, adminActions[name]
+}
+
+// adminHandler 运维操作路由，只允许POST，必须携带和配置一致的确认token，所有请求都会记录审计日志
+func adminHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fields := []elog.Field{
+			elog.FieldComponent(PackageName),
+			elog.FieldEvent("admin"),
+			elog.FieldName(name),
+			elog.FieldPeerIP(r.RemoteAddr),
+			elog.String("reason", r.URL.Query().Get("reason")),
+		}
+		if r.Method != http.MethodPost {
+			writeAdminResult(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		token, action := getAdminAction(name)
+		// 没有配置token，说明没有开启运维操作
+		if token == "" {
+			elog.EgoLogger.Warn("admin action denied, admin token not configured", fields...)
+			writeAdminResult(w, http.StatusForbidden, "admin action disabled")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(AdminTokenHeader)), []byte(token)) != 1 {
+			elog.EgoLogger.Warn("admin action denied, invalid token", fields...)
+			writeAdminResult(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		if action == nil {
+			elog.EgoLogger.Warn("admin action not registered", fields...)
+			writeAdminResult(w, http.StatusNotImplemented, "admin action not registered")
+			return
+		}
+
+		elog.EgoLogger.Info("admin action start", fields...)
+		data, err := action(r.Context(), r.URL.Query())
+		if err != nil {
+			elog.EgoLogger.Error("admin action fail", append(fields, elog.FieldErr(err))...)
+			RecordEvent("admin", name+" fail: "+err.Error())
+			writeAdminResult(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if data != nil {
+			fields = append(fields, elog.Any("data", data))
+		}
+		elog.EgoLogger.Info("admin action accepted", fields...)
+		RecordEvent("admin", name+" accepted, peer: "+r.RemoteAddr)
+		writeAdminData(w, http.StatusAccepted, "ok", data)
+	}
+}
+
+// maintenanceAction 开启或者关闭维护模式
+// enable=true|false 设置运行时状态，reset=true 清除运行时状态，重新跟随配置
+func maintenanceAction(_ context.Context, params url.Values) error {
+	if params.Get("reset") == "true" {
+		emaintenance.Reset()
+		return nil
+	}
+	enable, err := strconv.ParseBool(params.Get("enable"))
+	if err != nil {
+		return fmt.Errorf("invalid enable param, %w", err)
+	}
+	emaintenance.Enable(enable)
+	return nil
+}
+
+// componentRestartAction 重建组件，例如重新建立数据库连接池，不需要重启进程
+func componentRestartAction(ctx context.Context, params url.Values) error {
+	name := params.Get("name")
+	if name == "" {
+		return errors.New("name param is required")
+	}
+	return ecomponent.Restart(ctx, name)
+}
+
+// degradeAction 打开或者关闭功能的降级开关
+// enable=true|false 设置运行时开关，reset=true 清除运行时开关，重新跟随配置，feature为空时清除所有功能
+func degradeAction(_ context.Context, params url.Values) error {
+	name := params.Get("feature")
+	if params.Get("reset") == "true" {
+		edegrade.Reset(name)
+		return nil
+	}
+	if name == "" {
+		return errors.New("feature param is required")
+	}
+	enable, err := strconv.ParseBool(params.Get("enable"))
+	if err != nil {
+		return fmt.Errorf("invalid enable param, %w", err)
+	}
+	edegrade.Set(name, enable)
+	return nil
+}
+
+func writeAdminResult(w http.ResponseWriter, code int, msg string) {
+	writeAdminData(w, code, msg, nil)
+}
+
+// writeAdminData data为空时不输出data字段
+func writeAdminData(w http.ResponseWriter, code int, msg string, data interface{}) {
+	resp := map[string]interface{}{
+		"code": code,
+		"msg":  msg,
+	}
+	if data != nil {
+		resp["data"] = data
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,84 @@
+package egovernor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/edegrade"
+)
+
+func TestAdminHandler(t *testing.T) {
+	var called int
+	RegisterAdminAction("test", func(ctx context.Context, params url.Values) error {
+		called++
+		return nil
+	})
+	RegisterAdminAction("fail", func(ctx context.Context, params url.Values) error {
+		return errors.New("action fail")
+	})
+	defer setAdminToken("")
+
+	doRequest := func(name, method, token string) int {
+		req := httptest.NewRequest(method, "/admin/"+name, nil)
+		if token != "" {
+			req.Header.Set(AdminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		adminHandler(name).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 没有配置token，禁用运维操作
+	assert.Equal(t, http.StatusForbidden, doRequest("test", http.MethodPost, "secret"))
+
+	setAdminToken("secret")
+	assert.Equal(t, http.StatusMethodNotAllowed, doRequest("test", http.MethodGet, "secret"))
+	assert.Equal(t, http.StatusUnauthorized, doRequest("test", http.MethodPost, ""))
+	assert.Equal(t, http.StatusUnauthorized, doRequest("test", http.MethodPost, "wrong"))
+	assert.Equal(t, http.StatusNotImplemented, doRequest("unknown", http.MethodPost, "secret"))
+	assert.Equal(t, http.StatusInternalServerError, doRequest("fail", http.MethodPost, "secret"))
+	assert.Equal(t, 0, called)
+	assert.Equal(t, http.StatusAccepted, doRequest("test", http.MethodPost, "secret"))
+	assert.Equal(t, 1, called)
+}
+
+func TestComponentRestartAction(t *testing.T) {
+	var restarts int
+	ecomponent.Register("test.http", "client.ehttp", func(ctx context.Context) error {
+		restarts++
+		return nil
+	})
+	defer ecomponent.Unregister("test.http")
+
+	assert.EqualError(t, componentRestartAction(context.Background(), url.Values{}), "name param is required")
+	assert.ErrorIs(t, componentRestartAction(context.Background(), url.Values{"name": {"test.mysql"}}), ecomponent.ErrNotFound)
+	assert.NoError(t, componentRestartAction(context.Background(), url.Values{"name": {"test.http"}}))
+	assert.Equal(t, 1, restarts)
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/components", nil))
+	assert.Contains(t, w.Body.String(), `"name":"test.http"`)
+	assert.Contains(t, w.Body.String(), `"restarts":1`)
+}
+
+func TestDegradeAction(t *testing.T) {
+	defer edegrade.Reset("")
+	assert.EqualError(t, degradeAction(context.Background(), url.Values{"enable": {"true"}}), "feature param is required")
+	assert.Error(t, degradeAction(context.Background(), url.Values{"feature": {"test.recommend"}}))
+	assert.NoError(t, degradeAction(context.Background(), url.Values{"feature": {"test.recommend"}, "enable": {"true"}}))
+	assert.True(t, edegrade.IsDegraded("test.recommend"))
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/degrade", nil))
+	assert.Contains(t, w.Body.String(), `"name":"test.recommend","registered":false,"degraded":true,"source":"runtime"`)
+
+	assert.NoError(t, degradeAction(context.Background(), url.Values{"feature": {"test.recommend"}, "reset": {"true"}}))
+	assert.False(t, edegrade.IsDegraded("test.recommend"))
+}
@@ -9,13 +9,22 @@ import (
 	"net/http/pprof"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/gotomicro/ego/core/ecomponent"
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/edegrade"
+	"github.com/gotomicro/ego/core/eextension"
+	"github.com/gotomicro/ego/core/efeature"
+	"github.com/gotomicro/ego/core/egraceful"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/esetting"
 	"github.com/gotomicro/ego/task/ejob"
+	"github.com/gotomicro/ego/task/emigrate"
 
 	"github.com/felixge/fgprof"
 	"github.com/gotomicro/ego/core/constant"
@@ -68,29 +77,98 @@ func init() {
 		})
 	}
 
+	// 配置快照的版本信息，不包含配置内容，可以通过 admin/config/rollback 回滚
+	HandleFunc("/config/versions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(econf.Snapshots())
+	})
+
+	// 配置项的生效值来源，没有开启配置输出时不返回值，敏感配置项脱敏
+	HandleFunc("/config/provenance", func(w http.ResponseWriter, r *http.Request) {
+		values := esetting.All()
+		hide := !eapp.IsDevelopmentMode() && !eapp.EgoGovernorEnableConfig()
+		for i := range values {
+			switch {
+			case hide:
+				values[i].Value = nil
+			case isSensitiveKey(values[i].Key) && values[i].Value != nil && values[i].Value != "":
+				values[i].Value = redactedValue
+			}
+		}
+		encoder := json.NewEncoder(w)
+		if r.URL.Query().Get("pretty") == "true" {
+			encoder.SetIndent("", "    ")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = encoder.Encode(values)
+	})
+
 	HandleFunc("/env/info", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		_ = jsoniter.NewEncoder(w).Encode(os.Environ())
 	})
-	HandleFunc("/build/info", func(w http.ResponseWriter, r *http.Request) {
-		serverStats := map[string]string{
-			"name":       eapp.Name(),
-			"appMode":    eapp.AppMode(),
-			"appVersion": eapp.AppVersion(),
-			"egoVersion": eapp.EgoVersion(),
-			"buildUser":  eapp.BuildUser(),
-			"buildHost":  eapp.BuildHost(),
-			"buildTime":  eapp.BuildTime(),
-			"startTime":  eapp.StartTime(),
-			"hostName":   eapp.HostName(),
-			"goVersion":  eapp.GoVersion(),
+	// 客户端组件的健康检查，有组件不健康时返回503
+	HandleFunc("/health/components", func(w http.ResponseWriter, r *http.Request) {
+		results := ehealth.Check(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !ehealth.Healthy(results) {
+			w.WriteHeader(http.StatusServiceUnavailable)
 		}
-		_ = jsoniter.NewEncoder(w).Encode(serverStats)
+		_ = json.NewEncoder(w).Encode(results)
+	})
+	// 已经创建的组件，重建组件使用 /admin/component/restart
+	HandleFunc("/components", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ecomponent.List())
+	})
+	// 功能降级开关的状态
+	HandleFunc("/degrade", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(edegrade.List())
+	})
+	// 服务生效的拦截器链，key为 "组件名称/链的类型"
+	HandleFunc("/debug/chains", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(server.Chains())
+	})
+	// 通过空导入或者plugin注册的扩展，packages不为空时表示有冲突
+	HandleFunc("/extensions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(eextension.List())
+	})
+	HandleFunc("/build/info", func(w http.ResponseWriter, r *http.Request) {
+		_ = jsoniter.NewEncoder(w).Encode(buildInfo())
+	})
+	// 最近一次热升级的状态
+	HandleFunc("/upgrade/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(egraceful.GetStatus())
+	})
+	// 数据库迁移的版本
+	HandleFunc("/migrate/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(emigrate.Statuses(r.Context()))
 	})
 	HandleFunc("/jobs", ejob.Handle)
 	HandleFunc("/job/list", ejob.HandleJobList)
 }
 
+func buildInfo() map[string]string {
+	return map[string]string{
+		"name":       eapp.Name(),
+		"appMode":    eapp.AppMode(),
+		"appVersion": eapp.AppVersion(),
+		"egoVersion": eapp.EgoVersion(),
+		"buildUser":  eapp.BuildUser(),
+		"buildHost":  eapp.BuildHost(),
+		"buildTime":  eapp.BuildTime(),
+		"startTime":  eapp.StartTime(),
+		"hostName":   eapp.HostName(),
+		"goVersion":  eapp.GoVersion(),
+		"features":   strings.Join(efeature.List(), ","),
+	}
+}
+
 // Component ...
 type Component struct {
 	name   string
@@ -118,6 +196,11 @@ func (c *Component) Name() string {
 	return c.name
 }
 
+// Address 服务地址
+func (c *Component) Address() string {
+	return c.config.Address()
+}
+
 // PackageName 包名
 func (c *Component) PackageName() string {
 	return PackageName
@@ -125,7 +208,7 @@ func (c *Component) PackageName() string {
 
 // Init 初始化
 func (c *Component) Init() error {
-	var listener, err = net.Listen("tcp4", c.config.Address())
+	var listener, err = egraceful.Listen("tcp4", c.config.Address())
 	if err != nil {
 		elog.Panic("governor start error", elog.FieldErr(err))
 	}
@@ -2,6 +2,7 @@ package egovernor
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gotomicro/ego/core/eflag"
 )
@@ -14,6 +15,20 @@ type Config struct {
 	EnableConnTcpMetric bool
 	ConnTcpMetricPorts  []uint64
 	Network             string
+	AdminToken          string               // 运维操作（/admin/*）的确认token，为空时禁用运维操作
+	ProfileStorage      ProfileStorageConfig // 运维操作 profile/upload 上传profile的对象存储
+}
+
+// ProfileStorageConfig S3协议的对象存储，阿里云OSS、腾讯云COS、MinIO等兼容S3协议的存储同样适用
+type ProfileStorageConfig struct {
+	Endpoint  string        // 例如 https://s3.us-east-1.amazonaws.com、https://oss-cn-hangzhou.aliyuncs.com
+	Region    string        // 签名使用的区域，默认us-east-1
+	Bucket    string        // 为空时不开启上传
+	AccessKey string        // 访问密钥ID
+	SecretKey string        // 访问密钥
+	Prefix    string        // 对象key的前缀，默认profiles/
+	PathStyle bool          // 使用 endpoint/bucket/key 格式的地址，MinIO需要开启，默认使用 bucket.endpoint/key
+	Timeout   time.Duration // 上传超时时间，默认30s
 }
 
 // DefaultConfig 默认配置
@@ -58,5 +58,9 @@ func (c *Container) Build(options ...Option) *Component {
 		obj := emetric.NewTCPStatCollector(c.config.ConnTcpMetricPorts)
 		obj.Update()
 	}
+	setAdminToken(c.config.AdminToken)
+	if c.config.ProfileStorage.Bucket != "" {
+		SetProfileStorage(NewS3ProfileStorage(c.config.ProfileStorage))
+	}
 	return newComponent(c.name, c.config, c.logger)
 }
@@ -0,0 +1,52 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxEvents 事件日志保留的最大条数
+const maxEvents = 200
+
+// Event 治理端事件，例如运维操作、配置热加载、注册中心状态变化
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+}
+
+var (
+	eventsMu sync.Mutex
+	events   = make([]Event, 0, maxEvents)
+)
+
+func init() {
+	HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Events())
+	})
+}
+
+// RecordEvent 记录事件，超过最大条数时丢弃最早的事件
+func RecordEvent(kind, message string) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if len(events) == maxEvents {
+		copy(events, events[1:])
+		events = events[:maxEvents-1]
+	}
+	events = append(events, Event{Time: time.Now(), Kind: kind, Message: message})
+}
+
+// Events 返回事件日志，最新的事件在前
+func Events() []Event {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	out := make([]Event, len(events))
+	for i := range events {
+		out[len(events)-1-i] = events[i]
+	}
+	return out
+}
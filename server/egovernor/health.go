@@ -0,0 +1,115 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/ehealth"
+)
+
+// HealthGraph 服务和依赖组件的健康关系图
+type HealthGraph struct {
+	App    string            `json:"app"`
+	Status string            `json:"status"`
+	Nodes  []ehealth.Result  `json:"nodes"`
+	Edges  []HealthGraphEdge `json:"edges"`
+}
+
+// HealthGraphEdge 依赖关系，From依赖To
+type HealthGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func init() {
+	// 就绪检查，关键组件不健康时返回503，非关键组件不健康时返回200，status为degraded
+	HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		results := ehealth.Check(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !ehealth.Ready(results) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":     ehealth.Status(results),
+			"components": results,
+		})
+	})
+	// 汇总服务、定时任务、客户端组件的就绪状态，未就绪时返回503，用于k8s的readinessProbe
+	HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		report := ehealth.NewReport(ehealth.Check(r.Context()))
+		writeHealthReport(w, report, report.Ready)
+	})
+	// 存活检查，只有参与存活检查的组件不健康时返回503，用于k8s的livenessProbe
+	HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
+		report := ehealth.NewReport(ehealth.Check(r.Context()))
+		writeHealthReport(w, report, report.Live)
+	})
+	// 依赖组件的健康关系图，默认返回JSON，?format=dot 返回Graphviz格式
+	HandleFunc("/health/graph", func(w http.ResponseWriter, r *http.Request) {
+		graph := newHealthGraph(eapp.Name(), ehealth.Check(r.Context()))
+		if r.URL.Query().Get("format") == "dot" {
+			w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+			_, _ = w.Write([]byte(graph.Dot()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(graph)
+	})
+}
+
+func writeHealthReport(w http.ResponseWriter, report ehealth.Report, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// newHealthGraph 服务直接依赖没有被其他组件依赖的组件，组件之间按照DependsOn连接
+func newHealthGraph(app string, results []ehealth.Result) HealthGraph {
+	graph := HealthGraph{App: app, Status: ehealth.Status(results), Nodes: results, Edges: make([]HealthGraphEdge, 0)}
+	dependent := make(map[string]bool)
+	for _, result := range results {
+		for _, dep := range result.DependsOn {
+			dependent[dep] = true
+		}
+	}
+	for _, result := range results {
+		if !dependent[result.Name] {
+			graph.Edges = append(graph.Edges, HealthGraphEdge{From: app, To: result.Name})
+		}
+		for _, dep := range result.DependsOn {
+			graph.Edges = append(graph.Edges, HealthGraphEdge{From: result.Name, To: dep})
+		}
+	}
+	return graph
+}
+
+// Dot 生成Graphviz格式，健康的组件为绿色，不健康的关键组件为红色，不健康的非关键组件为橙色
+func (g HealthGraph) Dot() string {
+	var b strings.Builder
+	b.WriteString("digraph health {\n")
+	fmt.Fprintf(&b, "  %q [shape=box, label=%q];\n", g.App, g.App+"\n"+g.Status)
+	for _, node := range g.Nodes {
+		color := "green"
+		if !node.Healthy {
+			color = "orange"
+			if node.Critical {
+				color = "red"
+			}
+		}
+		label := node.Name
+		if node.Type != "" {
+			label += "\n" + node.Type
+		}
+		fmt.Fprintf(&b, "  %q [color=%s, label=%q];\n", node.Name, color, label)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q;\n", edge.From, edge.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
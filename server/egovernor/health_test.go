@@ -0,0 +1,71 @@
+package egovernor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/ehealth"
+)
+
+func TestReadyz(t *testing.T) {
+	ehealth.Register("test.redis", func(ctx context.Context) error { return nil }, ehealth.WithType("redis"))
+	ehealth.Register("test.search", func(ctx context.Context) error { return errors.New("down") }, ehealth.WithCritical(false), ehealth.WithDependsOn("test.redis"))
+	defer ehealth.Unregister("test.redis")
+	defer ehealth.Unregister("test.search")
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"degraded"`)
+
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/graph", nil))
+	var graph HealthGraph
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &graph))
+	assert.Equal(t, ehealth.StatusDegraded, graph.Status)
+	assert.Len(t, graph.Nodes, 2)
+	assert.Equal(t, []HealthGraphEdge{{From: graph.App, To: "test.search"}, {From: "test.search", To: "test.redis"}}, graph.Edges)
+
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/graph?format=dot", nil))
+	assert.Contains(t, w.Body.String(), `"test.search" [color=orange`)
+	assert.Contains(t, w.Body.String(), `"test.search" -> "test.redis";`)
+
+	ehealth.Register("test.mysql", func(ctx context.Context) error { return errors.New("down") })
+	defer ehealth.Unregister("test.mysql")
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"unavailable"`)
+}
+
+func TestHealthReadyLive(t *testing.T) {
+	ehealth.Register("test.worker", func(ctx context.Context) error { return nil }, ehealth.WithLiveness(true))
+	ehealth.Register("test.mysql", func(ctx context.Context) error { return errors.New("down") })
+	defer ehealth.Unregister("test.worker")
+	defer ehealth.Unregister("test.mysql")
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report ehealth.Report
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.False(t, report.Ready)
+	assert.True(t, report.Live)
+
+	// 依赖的组件不健康时不影响存活
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	ehealth.Register("test.worker", func(ctx context.Context) error { return errors.New("stuck") }, ehealth.WithLiveness(true))
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
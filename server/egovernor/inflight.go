@@ -0,0 +1,31 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gotomicro/ego/core/einflight"
+)
+
+// defaultInflightTop 默认返回的路由数
+const defaultInflightTop = 20
+
+func init() {
+	// 正在处理的请求数最多的路由，用于排查停止服务或者故障时占用连接的接口，例如 ?top=10&pretty=true
+	HandleFunc("/debug/inflight", func(w http.ResponseWriter, r *http.Request) {
+		top, err := strconv.Atoi(r.URL.Query().Get("top"))
+		if err != nil || top <= 0 {
+			top = defaultInflightTop
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		if r.URL.Query().Get("pretty") == "true" {
+			encoder.SetIndent("", "    ")
+		}
+		_ = encoder.Encode(map[string]interface{}{
+			"total":  einflight.Total(),
+			"routes": einflight.Top(top),
+		})
+	})
+}
@@ -0,0 +1,29 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/einflight"
+)
+
+func TestDebugInflight(t *testing.T) {
+	done := einflight.Begin("http", "GET./governor/poll")
+	defer done()
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/inflight?top=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res struct {
+		Total  int               `json:"total"`
+		Routes []einflight.Route `json:"routes"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, 1, res.Total)
+	assert.Len(t, res.Routes, 1)
+	assert.Equal(t, "GET./governor/poll", res.Routes[0].Route)
+}
@@ -0,0 +1,114 @@
+package egovernor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/eapp"
+)
+
+// ProfileStorage 保存profile的对象存储，meta为对象的元数据，返回对象的地址
+type ProfileStorage interface {
+	Put(ctx context.Context, key string, data []byte, meta map[string]string) (url string, err error)
+}
+
+var (
+	profileMu      sync.RWMutex
+	profileStorage ProfileStorage
+)
+
+// SetProfileStorage 设置运维操作 profile/upload 使用的对象存储，配置了 ProfileStorage.Bucket 时使用S3协议的存储
+func SetProfileStorage(storage ProfileStorage) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profileStorage = storage
+}
+
+func getProfileStorage() ProfileStorage {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	return profileStorage
+}
+
+// ProfileUploadResult 运维操作 profile/upload 的结果
+type ProfileUploadResult struct {
+	URL  string `json:"url"`
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+}
+
+// profileUploadAction 采集profile上传到对象存储，用于实例即将被销毁、pprof端口无法访问时保留现场
+// profile=goroutine|heap|mutex|block|allocs|threadcreate，debug>0时为文本格式，reason写入对象的元数据
+func profileUploadAction(ctx context.Context, params url.Values) (interface{}, error) {
+	storage := getProfileStorage()
+	if storage == nil {
+		return nil, errors.New("profile storage not configured")
+	}
+	name := params.Get("profile")
+	if name == "" {
+		name = "goroutine"
+	}
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found", name)
+	}
+	var debug int
+	if v := params.Get("debug"); v != "" {
+		var err error
+		if debug, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid debug param, %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := profile.WriteTo(&buf, debug); err != nil {
+		return nil, fmt.Errorf("write profile %s fail, %w", name, err)
+	}
+	now := time.Now()
+	key := profileKey(name, debug, now)
+	objectURL, err := storage.Put(ctx, key, buf.Bytes(), map[string]string{
+		"app":       eapp.Name(),
+		"instance":  eapp.AppInstance(),
+		"timestamp": now.Format(time.RFC3339),
+		"reason":    params.Get("reason"),
+		"profile":   name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("upload profile %s fail, %w", name, err)
+	}
+	RecordEvent("profile", fmt.Sprintf("upload profile %s to %s", name, objectURL))
+	return ProfileUploadResult{URL: objectURL, Key: key, Size: buf.Len()}, nil
+}
+
+// profileKey 按照应用、实例分目录，例如 svc-user/host-1/20240102T150405Z-goroutine.pb.gz
+func profileKey(name string, debug int, t time.Time) string {
+	ext := ".pb.gz"
+	if debug > 0 {
+		ext = ".txt"
+	}
+	return safeKey(eapp.Name()) + "/" + safeKey(eapp.AppInstance()) + "/" + t.UTC().Format("20060102T150405Z") + "-" + name + ext
+}
+
+// safeKey 对象key只保留字母、数字和 -_. ，避免签名时的编码差异
+func safeKey(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+	if s == "" || s == "." || s == ".." {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package egovernor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NewS3ProfileStorage 返回S3协议的对象存储，使用AWS Signature V4签名，元数据以 x-amz-meta-* 保存
+func NewS3ProfileStorage(config ProfileStorageConfig) ProfileStorage {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "profiles/"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	return &s3Storage{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+	}
+}
+
+type s3Storage struct {
+	config ProfileStorageConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// Put 上传对象，返回不带签名的对象地址
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte, meta map[string]string) (string, error) {
+	objectURL, err := s.objectURL(s.config.Prefix + key)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	for k, v := range meta {
+		if v == "" {
+			continue
+		}
+		// 元数据只支持ASCII，其他字符按照RFC 2047编码
+		req.Header.Set("X-Amz-Meta-"+k, mime.QEncoding.Encode("utf-8", v))
+	}
+	sum := sha256.Sum256(data)
+	signV4(req, hex.EncodeToString(sum[:]), s.config.Region, s.config.AccessKey, s.config.SecretKey, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("put object status %d, %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return objectURL, nil
+}
+
+// objectURL PathStyle为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *s3Storage) objectURL(key string) (string, error) {
+	u, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %s, %w", s.config.Endpoint, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid endpoint %s, scheme and host required", s.config.Endpoint)
+	}
+	if s.config.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return u.String(), nil
+}
+
+// signV4 按照AWS Signature V4对请求签名，签名所有已经设置的header
+func signV4(req *http.Request, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonicalRequest)
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
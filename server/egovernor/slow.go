@@ -0,0 +1,20 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gotomicro/ego/core/eslow"
+)
+
+func init() {
+	// 慢请求诊断记录，可以通过traceId参数查看某个链路的记录，例如 ?traceId=xxx&pretty=true
+	HandleFunc("/debug/slow", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		if r.URL.Query().Get("pretty") == "true" {
+			encoder.SetIndent("", "    ")
+		}
+		_ = encoder.Encode(eslow.Records(r.URL.Query().Get("traceId")))
+	})
+}
@@ -0,0 +1,28 @@
+package egovernor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/eslow"
+)
+
+func TestDebugSlow(t *testing.T) {
+	_, rec := eslow.Start(context.Background(), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	rec.Finish(eslow.Record{Type: "http", Route: "GET./governor", TraceID: "governor-trace"})
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/slow?traceId=governor-trace", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var records []eslow.Record
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+	assert.Len(t, records, 1)
+	assert.Equal(t, "GET./governor", records[0].Route)
+}
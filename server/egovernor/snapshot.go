@@ -0,0 +1,159 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func init() {
+	// JSON格式的指标快照，方便curl调试和轻量的采集程序
+	// 可以通过多个name参数过滤指标，以*结尾表示前缀匹配，例如 ?name=ego_server_*&name=go_goroutines
+	HandleFunc("/metrics.json", func(w http.ResponseWriter, r *http.Request) {
+		samples, err := metricsSnapshot(prometheus.DefaultGatherer, nameMatcher(r.URL.Query()["name"]))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		if r.URL.Query().Get("pretty") == "true" {
+			encoder.SetIndent("", "    ")
+		}
+		_ = encoder.Encode(map[string]interface{}{
+			"timestamp": time.Now().UnixMilli(),
+			"metrics":   samples,
+		})
+	})
+	// 兼容expvar，框架指标发布在ego变量下
+	expvar.Publish("ego", expvar.Func(func() interface{} {
+		samples, _ := metricsSnapshot(prometheus.DefaultGatherer, func(name string) bool {
+			return strings.HasPrefix(name, "ego_")
+		})
+		vars := make(map[string]float64, len(samples))
+		for _, sample := range samples {
+			vars[sample.key()] = sample.Value
+		}
+		return vars
+	}))
+	HandleFunc("/debug/vars", expvar.Handler().ServeHTTP)
+}
+
+// MetricSample 某个时间序列在当前时刻的值
+type MetricSample struct {
+	Name   string            `json:"name"`
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`           // counter、gauge的值，histogram、summary为sum
+	Count  uint64            `json:"count,omitempty"` // histogram、summary的样本数
+}
+
+// key 返回prometheus风格的时间序列名称，例如 ego_server_handle_total{code="OK",type="http"}
+func (s MetricSample) key() string {
+	if len(s.Labels) == 0 {
+		return s.Name
+	}
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(s.Name)
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(s.Labels[name])
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// nameMatcher 按照名称过滤指标，没有传入名称时不过滤
+func nameMatcher(names []string) func(name string) bool {
+	if len(names) == 0 {
+		return nil
+	}
+	return func(name string) bool {
+		for _, pattern := range names {
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				if strings.HasPrefix(name, prefix) {
+					return true
+				}
+			} else if name == pattern {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// metricsSnapshot 采集当前的指标，match为nil时返回全部指标
+func metricsSnapshot(gatherer prometheus.Gatherer, match func(name string) bool) ([]MetricSample, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]MetricSample, 0)
+	for _, family := range families {
+		name := family.GetName()
+		if match != nil && !match(name) {
+			continue
+		}
+		typ := family.GetType()
+		for _, m := range family.GetMetric() {
+			sample := MetricSample{
+				Name: name,
+				Type: metricTypeName(typ),
+			}
+			if len(m.GetLabel()) > 0 {
+				sample.Labels = make(map[string]string, len(m.GetLabel()))
+				for _, label := range m.GetLabel() {
+					sample.Labels[label.GetName()] = label.GetValue()
+				}
+			}
+			switch typ {
+			case dto.MetricType_COUNTER:
+				sample.Value = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				sample.Value = m.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				sample.Value = m.GetHistogram().GetSampleSum()
+				sample.Count = m.GetHistogram().GetSampleCount()
+			case dto.MetricType_SUMMARY:
+				sample.Value = m.GetSummary().GetSampleSum()
+				sample.Count = m.GetSummary().GetSampleCount()
+			default:
+				sample.Value = m.GetUntyped().GetValue()
+			}
+			samples = append(samples, sample)
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+	return samples, nil
+}
+
+func metricTypeName(typ dto.MetricType) string {
+	switch typ {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	case dto.MetricType_HISTOGRAM:
+		return "histogram"
+	case dto.MetricType_SUMMARY:
+		return "summary"
+	}
+	return "untyped"
+}
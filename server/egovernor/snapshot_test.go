@@ -0,0 +1,54 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsSnapshot(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_handle_total"}, []string{"code", "type"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "test_handle_seconds"})
+	registry.MustRegister(counter, histogram)
+	counter.WithLabelValues("OK", "http").Add(3)
+	histogram.Observe(0.5)
+	histogram.Observe(1.5)
+
+	samples, err := metricsSnapshot(registry, nameMatcher([]string{"test_handle_*"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []MetricSample{
+		{Name: "test_handle_seconds", Type: "histogram", Value: 2, Count: 2},
+		{Name: "test_handle_total", Type: "counter", Labels: map[string]string{"code": "OK", "type": "http"}, Value: 3},
+	}, samples)
+	assert.Equal(t, `test_handle_total{code="OK",type="http"}`, samples[1].key())
+
+	samples, err = metricsSnapshot(registry, nameMatcher([]string{"test_handle_total"}))
+	assert.NoError(t, err)
+	assert.Len(t, samples, 1)
+}
+
+func TestMetricsJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics.json?name=go_goroutines", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Timestamp int64          `json:"timestamp"`
+		Metrics   []MetricSample `json:"metrics"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotZero(t, resp.Timestamp)
+	assert.Len(t, resp.Metrics, 1)
+	assert.Equal(t, "go_goroutines", resp.Metrics[0].Name)
+
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	var vars map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+	assert.Contains(t, vars, "ego")
+	assert.Contains(t, vars, "memstats")
+}
@@ -0,0 +1,91 @@
+package egovernor
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/emaintenance"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+//go:embed ui/index.html
+var uiIndex []byte
+
+// redactedValue 脱敏后的配置值
+const redactedValue = "******"
+
+// sensitiveKeys 配置key的最后一段包含这些词时脱敏
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "dsn", "credential", "accesskey", "privatekey"}
+
+// uiData 控制台页面的数据
+type uiData struct {
+	Build       map[string]string      `json:"build"`
+	Healthy     bool                   `json:"healthy"`
+	Health      []ehealth.Result       `json:"health"`
+	Maintenance bool                   `json:"maintenance"`
+	Config      map[string]interface{} `json:"config"`
+	Metrics     []MetricSample         `json:"metrics"`
+	Crons       []ecron.ScheduleInfo   `json:"crons"`
+	Events      []Event                `json:"events"`
+}
+
+func init() {
+	// 内嵌的控制台页面，汇总健康检查、配置、指标、定时任务、事件，并提供热加载、停止操作
+	HandleFunc("/ui", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(uiIndex)
+	})
+	HandleFunc("/ui/data", func(w http.ResponseWriter, r *http.Request) {
+		results := ehealth.Check(r.Context())
+		metrics, _ := metricsSnapshot(prometheus.DefaultGatherer, func(name string) bool {
+			return strings.HasPrefix(name, "ego_")
+		})
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(uiData{
+			Build:       buildInfo(),
+			Healthy:     ehealth.Healthy(results),
+			Health:      results,
+			Maintenance: emaintenance.IsEnabled(),
+			Config:      redactConfig(econf.Traverse(".")),
+			Metrics:     metrics,
+			Crons:       ecron.Schedules(),
+			Events:      Events(),
+		})
+	})
+}
+
+// RedactedConfig 返回脱敏后的全部配置，用于诊断包等需要导出配置的场景
+func RedactedConfig() map[string]interface{} {
+	return redactConfig(econf.Traverse("."))
+}
+
+// redactConfig 对密码、token等敏感配置脱敏
+func redactConfig(conf map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(conf))
+	for key, value := range conf {
+		if isSensitiveKey(key) {
+			value = redactedValue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func isSensitiveKey(key string) bool {
+	if idx := strings.LastIndex(key, "."); idx >= 0 {
+		key = key[idx+1:]
+	}
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
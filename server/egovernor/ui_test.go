@@ -0,0 +1,51 @@
+package egovernor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestUI(t *testing.T) {
+	err := econf.LoadFromReader(strings.NewReader(`
+[mysql.user]
+dsn = "root:pass@tcp(127.0.0.1:3306)/user"
+maxIdleConns = 10
+[redis]
+password = "pass"
+`), toml.Unmarshal)
+	assert.NoError(t, err)
+	RecordEvent("test", "ui event")
+
+	w := httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Ego Governor")
+
+	w = httptest.NewRecorder()
+	DefaultServeMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ui/data", nil))
+	var data uiData
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, redactedValue, data.Config["mysql.user.dsn"])
+	assert.Equal(t, redactedValue, data.Config["redis.password"])
+	assert.Equal(t, float64(10), data.Config["mysql.user.maxIdleConns"])
+	assert.NotEmpty(t, data.Metrics)
+	assert.Equal(t, "ui event", data.Events[0].Message)
+}
+
+func TestRecordEvent(t *testing.T) {
+	for i := 0; i < maxEvents+10; i++ {
+		RecordEvent("test", "event")
+	}
+	RecordEvent("test", "latest")
+	events := Events()
+	assert.Len(t, events, maxEvents)
+	assert.Equal(t, "latest", events[0].Message)
+}
@@ -7,7 +7,9 @@ import (
 
 	"github.com/gotomicro/ego/core/constant"
 	"github.com/gotomicro/ego/core/eapp"
+	"github.com/gotomicro/ego/core/egraceful"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
 	"github.com/gotomicro/ego/internal/egrpclog"
 	"github.com/gotomicro/ego/server"
 	"go.uber.org/zap/zapgrpc"
@@ -33,6 +35,8 @@ type Component struct {
 	serverInfo *server.ServiceInfo
 	quit       chan error
 	invokers   []func() error // 用户初始化函数
+
+	unregisterMaintenance func() // 取消监听维护模式，停止后不再更新健康检查状态
 }
 
 func newComponent(name string, config *Config, logger *elog.Component) *Component {
@@ -46,17 +50,29 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 	// server should register all the services manually
 	// use empty service name for all etcd services' health status,
 	// see https://github.com/grpc/grpc/blob/master/doc/health-checking.md for more
-	healthSvc.SetServingStatus(eapp.Name(), healthpb.HealthCheckResponse_SERVING)
+	healthSvc.SetServingStatus(eapp.Name(), servingStatus(emaintenance.IsEnabled()))
+	// 维护模式下，健康检查返回NOT_SERVING
+	unregister := emaintenance.OnChange(func(enabled bool) {
+		healthSvc.SetServingStatus(eapp.Name(), servingStatus(enabled))
+	})
 	healthpb.RegisterHealthServer(newServer, healthSvc)
 	return &Component{
-		name:       name,
-		config:     config,
-		logger:     logger,
-		Server:     newServer,
-		listener:   nil,
-		serverInfo: nil,
-		quit:       make(chan error),
+		name:                  name,
+		config:                config,
+		logger:                logger,
+		Server:                newServer,
+		listener:              nil,
+		serverInfo:            nil,
+		quit:                  make(chan error),
+		unregisterMaintenance: unregister,
+	}
+}
+
+func servingStatus(maintenance bool) healthpb.HealthCheckResponse_ServingStatus {
+	if maintenance {
+		return healthpb.HealthCheckResponse_NOT_SERVING
 	}
+	return healthpb.HealthCheckResponse_SERVING
 }
 
 // Name 配置名称
@@ -82,12 +98,6 @@ func (c *Component) Prepare() error {
 
 // Init 初始化一些信息
 func (c *Component) Init() error {
-	info := server.ApplyOptions(
-		server.WithScheme("grpc"),
-		server.WithAddress(c.config.Address()),
-		server.WithKind(constant.ServiceProvider),
-	)
-	c.serverInfo = &info
 	var (
 		listener net.Listener
 		err      error
@@ -96,21 +106,36 @@ func (c *Component) Init() error {
 	if c.config.Network == "bufnet" {
 		listener = bufconn.Listen(1024 * 1024)
 		c.listener = listener
+		c.setServerInfo()
 		return nil
 	}
 	// 正式listener
-	listener, err = net.Listen(c.config.Network, c.config.Address())
+	listener, err = egraceful.Listen(c.config.Network, c.config.Address())
 	if err != nil {
 		c.logger.Panic("new grpc server err", elog.FieldErrKind("listen err"), elog.FieldErr(err))
 	}
+	// 端口配置为0时使用系统分配的端口
 	tcpInfo, flag := listener.Addr().(*net.TCPAddr)
 	if flag {
 		c.config.Port = tcpInfo.Port
 	}
 	c.listener = listener
+	c.setServerInfo()
 	return nil
 }
 
+func (c *Component) setServerInfo() {
+	info := server.ApplyOptions(
+		server.WithScheme("grpc"),
+		server.WithAddress(c.config.Address()),
+		server.WithKind(constant.ServiceProvider),
+	)
+	for key, value := range c.config.Metadata {
+		info.Metadata[key] = value
+	}
+	c.serverInfo = &info
+}
+
 // Start implements server.Component interface.
 func (c *Component) Start() error {
 	return c.Server.Serve(c.listener)
@@ -143,6 +168,7 @@ func (c *Component) Health() bool {
 // Stop implements server.Component interface
 // it will terminate echo server immediately
 func (c *Component) Stop() error {
+	c.unregisterMaintenance()
 	c.Server.Stop()
 	return nil
 }
@@ -150,6 +176,7 @@ func (c *Component) Stop() error {
 // GracefulStop implements server.Component interface
 // it will stop echo server gracefully
 func (c *Component) GracefulStop(ctx context.Context) error {
+	c.unregisterMaintenance()
 	go func() {
 		c.Server.GracefulStop()
 		close(c.quit)
@@ -48,3 +48,16 @@ func TestNewComponent(t *testing.T) {
 
 	t.Log("done")
 }
+
+func TestInitWithRandomPort(t *testing.T) {
+	cmp := newComponent("test-cmp", &Config{Host: "127.0.0.1", Port: 0, Network: "tcp4", Metadata: map[string]string{"api": "internal"}}, elog.DefaultLogger)
+	assert.NoError(t, cmp.Prepare())
+	assert.NoError(t, cmp.Init())
+	defer func() { _ = cmp.listener.Close() }()
+
+	// 使用系统分配的端口
+	assert.NotEqual(t, 0, cmp.config.Port)
+	assert.Equal(t, cmp.listener.Addr().String(), cmp.Info().Address)
+	assert.Equal(t, cmp.Info().Address, cmp.Address())
+	assert.Equal(t, "internal", cmp.Info().Metadata["api"])
+}
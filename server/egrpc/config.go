@@ -11,33 +11,55 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/gotomicro/ego/core/util/xtime"
+	"github.com/gotomicro/ego/server"
 )
 
 // Config ...
 type Config struct {
-	Host                          string        // IP地址，默认0.0.0.0
-	Port                          int           // Port端口，默认9002
-	Deployment                    string        // 部署区域
-	Network                       string        // 网络类型，默认tcp4
-	EnableMetricInterceptor       bool          // 是否开启监控，默认开启
-	EnableTraceInterceptor        bool          // 是否开启链路追踪，默认开启
-	EnableOfficialGrpcLog         bool          // 是否开启官方grpc日志，默认关闭
-	EnableSkipHealthLog           bool          // 是否屏蔽探活日志，默认开启
-	SlowLogThreshold              time.Duration // 服务慢日志，默认500ms
-	EnableAccessInterceptor       bool          // 是否开启，记录请求数据
-	EnableSentinel                bool          // 是否开启限流，默认不开启
-	EnableAccessInterceptorReq    bool          // 是否开启记录请求参数，默认不开启
-	AccessInterceptorReqMaxLength int           // 默认4K
-	EnableAccessInterceptorRes    bool          // 是否开启记录响应参数，默认不开启
-	AccessInterceptorResMaxLength int           // 默认4K
-	EnableLocalMainIP             bool          // 自动获取ip地址
+	Host                          string            // IP地址，默认0.0.0.0
+	Port                          int               // Port端口，默认9002
+	Deployment                    string            // 部署区域
+	Network                       string            // 网络类型，默认tcp4
+	EnableMetricInterceptor       bool              // 是否开启监控，默认开启
+	EnableTraceInterceptor        bool              // 是否开启链路追踪，默认开启
+	EnableOfficialGrpcLog         bool              // 是否开启官方grpc日志，默认关闭
+	EnableSkipHealthLog           bool              // 是否屏蔽探活日志，默认开启
+	SlowLogThreshold              time.Duration     // 服务慢日志，默认500ms
+	EnableSlowDump                bool              // 是否开启慢请求诊断记录，超过SlowLogThreshold的请求记录耗时分解、处理栈和下游调用，通过governor /debug/slow 查看，默认不开启
+	EnableAccessInterceptor       bool              // 是否开启，记录请求数据
+	EnableSentinel                bool              // 是否开启限流，默认不开启
+	EnableAccessInterceptorReq    bool              // 是否开启记录请求参数，默认不开启
+	AccessInterceptorReqMaxLength int               `unit:"size"` // 默认4K
+	EnableAccessInterceptorRes    bool              // 是否开启记录响应参数，默认不开启
+	AccessInterceptorResMaxLength int               `unit:"size"` // 默认4K
+	EnableAccessInterceptorPeer   bool              // 是否在访问日志和链路中记录对端身份和TLS信息，包括认证的用户（mTLS证书SAN、JWT sub）、TLS版本、加密套件和协商的协议，用于审计，默认不开启
+	EnableLocalMainIP             bool              // 自动获取ip地址
+	EnableI18nInterceptor         bool              // 是否开启国际化，根据metadata协商语言，并翻译返回的EgoError，默认不开启
+	EnableAuthzInterceptor        bool              // 是否开启授权，使用eauthz的规则按方法授权，在自定义拦截器之后执行，默认不开启
+	EnableIdentity                bool              // 是否开启mTLS服务身份认证，使用eidentity默认组件的证书，对端的SPIFFE ID放入ctx，默认不开启
+	Metadata                      map[string]string // 注册到注册中心的服务元数据，用于区分同一个应用中的多个gRPC服务，例如 {"api" = "internal"}
+	StreamSendBufferSize          int               // 服务端流每个流的发送缓冲区消息数，缓冲区已满时按照StreamSlowConsumerPolicy处理，默认0，不开启
+	StreamSlowConsumerPolicy      string            // 对端读取太慢时的策略，backpressure阻塞发送、drop丢弃消息、disconnect断开，默认backpressure
+	StreamSendTimeout             time.Duration     // backpressure策略下发送最长阻塞时间，超过后断开，默认0，只受ctx控制
+	MaxConnectionAge              time.Duration     // 连接的最长存活时间，grpc会加上±10%的抖动，超过后发送GOAWAY，客户端重新建立连接，扩容后流量可以逐步均衡，默认不启用；grpc没有按连接限制请求数的能力，不支持最大请求数
+	MaxConnectionAgeGrace         time.Duration     // 发送GOAWAY之后等待正在处理的请求的时间，超过后强制关闭连接，默认不限制
+	InterceptorPriorities         map[string]int    // 修改拦截器的优先级，越小越靠外，key为拦截器名称，例如 {"trace" = 50}，通过governor /debug/chains 查看生效的顺序
 	serverOptions                 []grpc.ServerOption
 	streamInterceptors            []grpc.StreamServerInterceptor
 	unaryInterceptors             []grpc.UnaryServerInterceptor
+	placedUnaryInterceptors       []placed[grpc.UnaryServerInterceptor]
+	placedStreamInterceptors      []placed[grpc.StreamServerInterceptor]
 	unaryServerResourceExtract    func(context.Context, interface{}, *grpc.UnaryServerInfo) string // sentinel 的限流策略
 	unaryServerBlockFallback      func(context.Context, interface{}, *grpc.UnaryServerInfo, *base.BlockError) (interface{}, error)
 }
 
+// placed 指定了位置的自定义拦截器
+type placed[T any] struct {
+	name      string
+	placement server.Placement
+	handler   T
+}
+
 // DefaultConfig represents default config
 // User should construct config base on DefaultConfig
 func DefaultConfig() *Config {
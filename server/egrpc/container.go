@@ -1,11 +1,32 @@
 package egrpc
 
 import (
+	"fmt"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/efeature"
+	"github.com/gotomicro/ego/core/eidentity"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/estream"
 	"github.com/gotomicro/ego/core/util/xnet"
+	"github.com/gotomicro/ego/server"
+)
+
+// 框架安装的拦截器的默认优先级，越小越靠外
+const (
+	priorityRequestID   = 100
+	priorityTrace       = 200
+	priorityAccess      = 300
+	priorityInflight    = 400
+	prioritySlowStream  = 450
+	priorityI18n        = 500
+	priorityMaintenance = 600
+	prioritySentinel    = 700
+	priorityIdentity    = 800
+	priorityAuthz       = 1100
 )
 
 // Container defines a component instance.
@@ -50,16 +71,28 @@ func Load(key string) *Container {
 }
 
 // Build ...
+// 框架安装的拦截器和用户拦截器按照优先级排序，优先级越小越靠外，可以通过 InterceptorPriorities 配置修改
 func (c *Container) Build(options ...Option) *Component {
-	var streamInterceptors []grpc.StreamServerInterceptor
-	var unaryInterceptors []grpc.UnaryServerInterceptor
-	// trace 必须在最外层，否则无法取到trace信息，传递到其他中间件
+	unaryChain := server.NewChain[grpc.UnaryServerInterceptor]()
+	streamChain := server.NewChain[grpc.StreamServerInterceptor]()
+	add := func(name string, priority int, unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor) {
+		if unary != nil {
+			unaryChain.Add(name, server.Placement{Priority: priority}, unary)
+		}
+		if stream != nil {
+			streamChain.Add(name, server.Placement{Priority: priority}, stream)
+		}
+	}
+	// 请求ID需要在日志拦截器之前放入ctx
+	add(server.InterceptorRequestID, priorityRequestID, requestIDUnaryServerInterceptor(), requestIDStreamServerInterceptor())
+	// trace 必须在日志拦截器外层，否则无法取到trace信息，传递到其他中间件
 	if c.config.EnableTraceInterceptor {
-		unaryInterceptors = []grpc.UnaryServerInterceptor{traceUnaryServerInterceptor(), c.defaultUnaryServerInterceptor()}
-		streamInterceptors = []grpc.StreamServerInterceptor{traceStreamServerInterceptor(), c.defaultStreamServerInterceptor()}
-	} else {
-		unaryInterceptors = []grpc.UnaryServerInterceptor{c.defaultUnaryServerInterceptor()}
-		streamInterceptors = []grpc.StreamServerInterceptor{c.defaultStreamServerInterceptor()}
+		add(server.InterceptorTrace, priorityTrace, traceUnaryServerInterceptor(c.config.SlowLogThreshold, c.config.EnableAccessInterceptorPeer), traceStreamServerInterceptor(c.config.EnableAccessInterceptorPeer))
+	}
+	add(server.InterceptorAccess, priorityAccess, c.defaultUnaryServerInterceptor(), c.defaultStreamServerInterceptor())
+	// 正在处理的请求数
+	if c.config.EnableMetricInterceptor {
+		add(server.InterceptorInflight, priorityInflight, inflightUnaryServerInterceptor(), inflightStreamServerInterceptor())
 	}
 
 	// prometheus metric 必须在业务拦截器执行完之后
@@ -68,24 +101,84 @@ func (c *Container) Build(options ...Option) *Component {
 	//streamInterceptors = append(streamInterceptors, prometheusStreamServerInterceptor)
 	//}
 
+	// 慢消费者保护，在访问日志内层，断开的流记录为ResourceExhausted
+	if c.config.StreamSendBufferSize > 0 {
+		add(server.InterceptorSlowConsumer, prioritySlowStream, nil, slowConsumerStreamServerInterceptor(estream.Config{
+			BufferSize:  c.config.StreamSendBufferSize,
+			Policy:      c.config.StreamSlowConsumerPolicy,
+			SendTimeout: c.config.StreamSendTimeout,
+		}))
+	}
+
+	// 国际化
+	if c.config.EnableI18nInterceptor {
+		add(server.InterceptorI18n, priorityI18n, i18nUnaryServerInterceptor(), i18nStreamServerInterceptor())
+	}
+
+	// 维护模式
+	add(server.InterceptorMaintenance, priorityMaintenance, maintenanceUnaryServerInterceptor(), maintenanceStreamServerInterceptor())
+
 	// 启用sentinel
-	if c.config.EnableSentinel {
-		unaryInterceptors = append(unaryInterceptors, c.sentinelInterceptor())
+	if c.config.EnableSentinel && efeature.Enabled(efeature.Sentinel) {
+		add(server.InterceptorSentinel, prioritySentinel, c.sentinelInterceptor(), nil)
+	}
+
+	// 服务身份，对端身份在自定义拦截器之前放入ctx
+	if c.config.EnableIdentity {
+		identity := eidentity.Default()
+		if identity == nil {
+			c.logger.Panic("identity enabled but eidentity component is not built")
+		}
+		c.config.serverOptions = append(c.config.serverOptions, grpc.Creds(identity.GRPCServerCredentials()))
+		add(server.InterceptorIdentity, priorityIdentity, identityUnaryServerInterceptor(), identityStreamServerInterceptor())
+	}
+
+	// 授权在自定义拦截器之后执行，认证拦截器可以先把用户放入ctx
+	if c.config.EnableAuthzInterceptor {
+		add(server.InterceptorAuthz, priorityAuthz, authzUnaryServerInterceptor(), authzStreamServerInterceptor())
 	}
 
 	for _, option := range options {
 		option(c)
 	}
 
-	streamInterceptors = append(
-		streamInterceptors,
-		c.config.streamInterceptors...,
-	)
+	// 自定义拦截器，没有指定位置时按照添加的顺序放在授权之前
+	for i, interceptor := range c.config.unaryInterceptors {
+		unaryChain.Add(fmt.Sprintf("unary.%d", i), server.Placement{Priority: server.PriorityUser}, interceptor)
+	}
+	for i, interceptor := range c.config.streamInterceptors {
+		streamChain.Add(fmt.Sprintf("stream.%d", i), server.Placement{Priority: server.PriorityUser}, interceptor)
+	}
+	for _, item := range c.config.placedUnaryInterceptors {
+		unaryChain.Add(item.name, item.placement, item.handler)
+	}
+	for _, item := range c.config.placedStreamInterceptors {
+		streamChain.Add(item.name, item.placement, item.handler)
+	}
 
-	unaryInterceptors = append(
-		unaryInterceptors,
-		c.config.unaryInterceptors...,
-	)
+	for name := range c.config.InterceptorPriorities {
+		if !unaryChain.Has(name) && !streamChain.Has(name) {
+			c.logger.Panic("priority of unknown interceptor", elog.FieldName(name))
+		}
+	}
+	unaryInterceptors, unaryItems, err := unaryChain.Build(c.config.InterceptorPriorities)
+	if err != nil {
+		c.logger.Panic("build unary interceptor chain fail", elog.FieldErr(err))
+	}
+	streamInterceptors, streamItems, err := streamChain.Build(c.config.InterceptorPriorities)
+	if err != nil {
+		c.logger.Panic("build stream interceptor chain fail", elog.FieldErr(err))
+	}
+	server.RegisterChain(c.name+"/unary", unaryItems)
+	server.RegisterChain(c.name+"/stream", streamItems)
+
+	// 连接达到最长存活时间后发送GOAWAY，放在最前面，通过 WithServerOption 设置的 grpc.KeepaliveParams 优先
+	if c.config.MaxConnectionAge > 0 {
+		c.config.serverOptions = append([]grpc.ServerOption{grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge:      c.config.MaxConnectionAge,
+			MaxConnectionAgeGrace: c.config.MaxConnectionAgeGrace,
+		})}, c.config.serverOptions...)
+	}
 
 	c.config.serverOptions = append(c.config.serverOptions,
 		grpc.ChainStreamInterceptor(streamInterceptors...),
@@ -8,8 +8,10 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 
 	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/server"
 )
 
 func TestDefaultContainer(t *testing.T) {
@@ -41,3 +43,38 @@ host = "127.0.0.1"
 	})
 	t.Log("done")
 }
+
+func TestInterceptorPlacement(t *testing.T) {
+	cfg := `
+[grpc.chain]
+enableTraceInterceptor = false
+[grpc.chain.interceptorPriorities]
+access = 50
+`
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(cfg), toml.Unmarshal))
+	noop := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+	Load("grpc.chain").Build(
+		WithUnaryInterceptor(noop),
+		WithUnaryInterceptorPlacement("tenant", server.Placement{After: server.InterceptorRequestID}, noop),
+	)
+	names := make([]string, 0)
+	for _, item := range server.Chains()["grpc.chain/unary"] {
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{server.InterceptorAccess, server.InterceptorRequestID, "tenant", server.InterceptorInflight, server.InterceptorMaintenance, server.InterceptorSentinel, "unary.0"}, names)
+
+	// 不存在的拦截器名称
+	assert.NoError(t, econf.LoadFromReader(strings.NewReader(`[grpc.unknown.interceptorPriorities]
+tenant = 1`), toml.Unmarshal))
+	assert.Panics(t, func() { Load("grpc.unknown").Build() })
+}
+
+func TestMaxConnectionAge(t *testing.T) {
+	c := DefaultContainer()
+	c.config.MaxConnectionAge = time.Minute
+	cmp := c.Build()
+	// keepalive、stream和unary拦截器
+	assert.Equal(t, 3, len(cmp.config.serverOptions))
+}
@@ -3,6 +3,7 @@ package egrpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -11,8 +12,6 @@ import (
 	"strings"
 	"time"
 
-	sentinel "github.com/alibaba/sentinel-golang/api"
-	sentinelBase "github.com/alibaba/sentinel-golang/core/base"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -20,14 +19,23 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	grpcCode "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
+	"github.com/gotomicro/ego/core/eauthz"
+	"github.com/gotomicro/ego/core/ecomponent"
 	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/ei18n"
+	"github.com/gotomicro/ego/core/eidentity"
+	"github.com/gotomicro/ego/core/einflight"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emaintenance"
 	"github.com/gotomicro/ego/core/emetric"
-	"github.com/gotomicro/ego/core/esentinel"
+	"github.com/gotomicro/ego/core/erequestid"
+	"github.com/gotomicro/ego/core/eslow"
+	"github.com/gotomicro/ego/core/estream"
 	"github.com/gotomicro/ego/core/etrace"
 	"github.com/gotomicro/ego/core/transport"
 	"github.com/gotomicro/ego/core/util/xstring"
@@ -36,7 +44,8 @@ import (
 	"github.com/gotomicro/ego/internal/tools"
 )
 
-func traceUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+// traceUnaryServerInterceptor enablePeer为true时记录对端身份和TLS信息
+func traceUnaryServerInterceptor(slowThreshold time.Duration, enablePeer bool) grpc.UnaryServerInterceptor {
 	tracer := etrace.NewTracer(trace.SpanKindServer)
 	attrs := []attribute.KeyValue{
 		egrpcinteceptor.RPCSystemGRPC,
@@ -55,16 +64,20 @@ func traceUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 			semconv.NetPeerNameKey.String(getPeerName(ctx)),
 			semconv.NetPeerIPKey.String(getPeerIP(ctx)),
 		)
+		if enablePeer {
+			span.SetAttributes(getPeerInfo(ctx).attributes()...)
+		}
+		beg := time.Now()
 		defer func() {
 			if err != nil {
-				span.RecordError(err)
 				if e := eerrors.FromError(err); e != nil {
 					span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(e.Code)))
 				}
-				span.SetStatus(codes.Error, err.Error())
+				etrace.RecordError(span, err)
 			} else {
 				span.SetStatus(codes.Ok, "OK")
 			}
+			etrace.RecordSlow(span, time.Since(beg), slowThreshold)
 			span.End()
 		}()
 		return handler(ctx, req)
@@ -104,7 +117,21 @@ func (css *contextedServerStream) Context() context.Context {
 	return css.ctx
 }
 
-func traceStreamServerInterceptor() grpc.StreamServerInterceptor {
+// newContextedServerStream 替换stream的context，已经是contextedServerStream时不再嵌套，避免重复记录消息事件
+func newContextedServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if css, ok := ss.(*contextedServerStream); ok {
+		return &contextedServerStream{
+			ServerStream:      css.ServerStream,
+			ctx:               ctx,
+			receivedMessageID: css.receivedMessageID,
+			sentMessageID:     css.sentMessageID,
+		}
+	}
+	return &contextedServerStream{ServerStream: ss, ctx: ctx}
+}
+
+// traceStreamServerInterceptor enablePeer为true时记录对端身份和TLS信息
+func traceStreamServerInterceptor(enablePeer bool) grpc.StreamServerInterceptor {
 	tracer := etrace.NewTracer(trace.SpanKindServer)
 	attrs := []attribute.KeyValue{
 		semconv.RPCSystemKey.String("grpc"),
@@ -124,17 +151,19 @@ func traceStreamServerInterceptor() grpc.StreamServerInterceptor {
 			semconv.NetPeerIPKey.String(getPeerIP(ctx)),
 			etrace.CustomTag("rpc.grpc.kind", "stream"),
 		)
+		if enablePeer {
+			span.SetAttributes(getPeerInfo(ctx).attributes()...)
+		}
 		defer span.End()
 		err := handler(srv, &contextedServerStream{
 			ServerStream: ss,
 			ctx:          ctx,
 		})
 		if err != nil {
-			span.RecordError(err)
 			if e := eerrors.FromError(err); e != nil {
 				span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int64(int64(e.Code)))
 			}
-			span.SetStatus(codes.Error, err.Error())
+			etrace.RecordError(span, err)
 		} else {
 			span.SetStatus(codes.Ok, "OK")
 		}
@@ -147,6 +176,14 @@ func (c *Container) defaultStreamServerInterceptor() grpc.StreamServerIntercepto
 		var beg = time.Now()
 		var fields = make([]elog.Field, 0, 20)
 		var event = "normal"
+		// 慢请求诊断记录，需要在日志的defer之后执行，拿到recover之后的错误码
+		if c.config.EnableSlowDump {
+			ctx, slow := eslow.Start(stream.Context(), c.config.SlowLogThreshold)
+			stream = &contextedServerStream{ServerStream: stream, ctx: ctx}
+			defer func() {
+				slow.Finish(slowRecord(ctx, "grpc."+emetric.TypeGRPCStream, info.FullMethod, err))
+			}()
+		}
 		defer func() {
 			cost := time.Since(beg)
 			if rec := recover(); rec != nil {
@@ -174,6 +211,9 @@ func (c *Container) defaultStreamServerInterceptor() grpc.StreamServerIntercepto
 				elog.FieldPeerName(getPeerName(stream.Context())),
 				elog.FieldPeerIP(getPeerIP(stream.Context())),
 			)
+			if c.config.EnableAccessInterceptorPeer {
+				fields = append(fields, getPeerInfo(stream.Context()).fields()...)
+			}
 			isSlowLog := false
 			if c.config.SlowLogThreshold > time.Duration(0) && c.config.SlowLogThreshold < cost {
 				event = "slow"
@@ -212,6 +252,17 @@ func (c *Container) prometheusStreamServerInterceptor(ss grpc.ServerStream, info
 	emetric.ServerHandleCounter.Inc(emetric.TypeGRPCStream, info.FullMethod, getPeerName(ss.Context()), pbStatus.Message(), strconv.Itoa(ecode.GrpcToHTTPStatusCode(pbStatus.Code())), serviceName)
 }
 
+// slowRecord 慢请求诊断记录的请求信息
+func slowRecord(ctx context.Context, typ string, method string, err error) eslow.Record {
+	return eslow.Record{
+		Type:      typ,
+		Route:     method,
+		TraceID:   etrace.ExtractTraceID(ctx),
+		RequestID: erequestid.FromContext(ctx),
+		Code:      int(ecode.Convert(err).Code()),
+	}
+}
+
 type ctxStore struct {
 	kvs map[string]any
 }
@@ -248,6 +299,15 @@ func (c *Container) defaultUnaryServerInterceptor() grpc.UnaryServerInterceptor
 			}
 		}
 
+		// 慢请求诊断记录，需要在日志的defer之后执行，拿到recover之后的错误码
+		if c.config.EnableSlowDump {
+			var slow *eslow.Recorder
+			ctx, slow = eslow.Start(ctx, c.config.SlowLogThreshold)
+			defer func() {
+				slow.Finish(slowRecord(ctx, "grpc."+emetric.TypeGRPCUnary, info.FullMethod, err))
+			}()
+		}
+
 		// 此处必须使用defer来recover handler内部可能出现的panic
 		defer func() {
 			cost := time.Since(beg)
@@ -296,6 +356,9 @@ func (c *Container) defaultUnaryServerInterceptor() grpc.UnaryServerInterceptor
 				elog.FieldPeerName(getPeerName(ctx)),
 				elog.FieldPeerIP(getPeerIP(ctx)),
 			)
+			if c.config.EnableAccessInterceptorPeer {
+				fields = append(fields, getPeerInfo(ctx).fields()...)
+			}
 
 			skv, skvOk := ctx.Value(ctxStoreStruct{}).(*ctxStore)
 			for _, key := range loggerKeys {
@@ -312,6 +375,9 @@ func (c *Container) defaultUnaryServerInterceptor() grpc.UnaryServerInterceptor
 			if etrace.IsGlobalTracerRegistered() {
 				fields = append(fields, elog.FieldTid(etrace.ExtractTraceID(ctx)))
 			}
+			if requestID := erequestid.FromContext(ctx); requestID != "" {
+				fields = append(fields, elog.FieldRequestID(requestID))
+			}
 
 			if c.config.EnableAccessInterceptorReq {
 				reqStr := xstring.JSON(req)
@@ -432,43 +498,250 @@ func getPeerIP(ctx context.Context) string {
 	return ""
 }
 
-// NewUnaryServerInterceptor creates the unary server interceptor wrapped with Sentinel entry.
-func (c *Container) sentinelInterceptor() grpc.UnaryServerInterceptor {
-	return func(
-		ctx context.Context,
-		req interface{},
-		info *grpc.UnaryServerInfo,
-		handler grpc.UnaryHandler,
-	) (interface{}, error) {
-		// method as resource name by default
-		resourceName := info.FullMethod
-		if c.config.unaryServerResourceExtract != nil {
-			resourceName = c.config.unaryServerResourceExtract(ctx, req, info)
+// i18nUnaryServerInterceptor 协商请求的语言放入ctx，并翻译返回的EgoError
+func i18nUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		comp := ei18n.Default()
+		if comp == nil {
+			return handler(ctx, req)
+		}
+		ctx = ei18n.WithLocale(ctx, comp.NegotiateGRPC(ctx))
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, comp.LocalizeGRPCError(ctx, err)
 		}
+		return resp, nil
+	}
+}
 
-		if !esentinel.IsResExist(resourceName) {
-			return handler(ctx, req)
+// i18nStreamServerInterceptor 协商请求的语言放入ctx，并翻译返回的EgoError
+func i18nStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		comp := ei18n.Default()
+		if comp == nil {
+			return handler(srv, ss)
+		}
+		ctx := ei18n.WithLocale(ss.Context(), comp.NegotiateGRPC(ss.Context()))
+		err := handler(srv, newContextedServerStream(ss, ctx))
+		if err != nil {
+			return comp.LocalizeGRPCError(ctx, err)
 		}
+		return nil
+	}
+}
 
-		// var entry *sentinelBase.SentinelEntry = nil
-		entry, blockErr := sentinel.Entry(
-			resourceName,
-			sentinel.WithResourceType(sentinelBase.ResTypeRPC),
-			sentinel.WithTrafficType(sentinelBase.Inbound),
-		)
-		if blockErr != nil {
-			if c.config.unaryServerBlockFallback != nil {
-				return c.config.unaryServerBlockFallback(ctx, req, info, blockErr)
-			}
+// requestIDIncomingContext 把metadata中的请求ID放入ctx，继续透传给下游
+func requestIDIncomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(strings.ToLower(erequestid.Header())); len(values) > 0 && erequestid.Valid(values[0]) {
+		return erequestid.WithRequestID(ctx, values[0])
+	}
+	return ctx
+}
+
+// requestIDUnaryServerInterceptor 接收上游透传的请求ID
+func requestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(requestIDIncomingContext(ctx), req)
+	}
+}
+
+// requestIDStreamServerInterceptor 接收上游透传的请求ID
+func requestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := requestIDIncomingContext(ss.Context())
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, newContextedServerStream(ss, ctx))
+	}
+}
 
-			return nil, eerrors.New(int(grpcCode.ResourceExhausted), "blocked by sentinel", blockErr.Error())
+// maintenanceUnaryServerInterceptor 维护模式下，不在白名单内的方法返回Unavailable
+func maintenanceUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := maintenanceError(ctx, info.FullMethod); err != nil {
+			return nil, err
 		}
-		defer entry.Exit()
+		return handler(ctx, req)
+	}
+}
 
-		res, err := handler(ctx, req)
-		if err != nil {
-			sentinel.TraceError(entry, err)
+// maintenanceStreamServerInterceptor 维护模式下，不在白名单内的方法返回Unavailable
+func maintenanceStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := maintenanceError(ss.Context(), info.FullMethod); err != nil {
+			return err
 		}
-		return res, err
+		return handler(srv, ss)
+	}
+}
+
+// inflightUnaryServerInterceptor 统计每个方法正在处理的请求数
+func inflightUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := einflight.Begin(emetric.TypeGRPCUnary, info.FullMethod)
+		defer done()
+		return handler(ctx, req)
+	}
+}
+
+// inflightStreamServerInterceptor 统计每个方法正在处理的流
+func inflightStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := einflight.Begin(emetric.TypeGRPCStream, info.FullMethod)
+		defer done()
+		return handler(srv, ss)
+	}
+}
+
+// identityUnaryServerInterceptor 把对端证书中的SPIFFE ID放入ctx
+func identityUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(identityIncomingContext(ctx), req)
+	}
+}
+
+// identityStreamServerInterceptor 把对端证书中的SPIFFE ID放入ctx
+func identityStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := identityIncomingContext(ss.Context())
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, newContextedServerStream(ss, ctx))
+	}
+}
+
+// identityIncomingContext ctx中没有eauthz用户时，使用对端身份作为用户，SPIFFE ID同时作为角色，授权规则中可以直接配置服务身份
+func identityIncomingContext(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ctx
+	}
+	id, err := eidentity.PeerID(info.State)
+	if err != nil {
+		return ctx
+	}
+	ctx = eidentity.WithPeerID(ctx, id)
+	if _, ok := eauthz.SubjectFromContext(ctx); !ok {
+		ctx = eauthz.WithSubject(ctx, eauthz.Subject{ID: id, Roles: []string{id}})
+	}
+	return ctx
+}
+
+// OverrideUnaryServerInterceptor 按照fn返回的组件替换当前请求ctx中的组件，handler通过 ecomponent.Resolve 获取组件
+// 用于测试中注入fake组件，或者灰度流量使用指向其他地址的客户端，fn返回空时不替换
+func OverrideUnaryServerInterceptor(fn func(ctx context.Context, method string) map[string]any) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ecomponent.WithOverrides(ctx, fn(ctx, info.FullMethod)), req)
+	}
+}
+
+// OverrideStreamServerInterceptor 按照fn返回的组件替换当前流ctx中的组件
+func OverrideStreamServerInterceptor(fn func(ctx context.Context, method string) map[string]any) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ecomponent.WithOverrides(ss.Context(), fn(ss.Context(), info.FullMethod))
+		if ctx == ss.Context() {
+			return handler(srv, ss)
+		}
+		return handler(srv, newContextedServerStream(ss, ctx))
+	}
+}
+
+// authzUnaryServerInterceptor 使用eauthz的规则对方法授权
+func authzUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authzError(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authzStreamServerInterceptor 使用eauthz的规则对方法授权
+func authzStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authzError(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authzError 没有构建eauthz组件时不做授权，健康检查不需要授权
+func authzError(ctx context.Context, method string) error {
+	comp := eauthz.Default()
+	if comp == nil || strings.HasPrefix(method, "/grpc.health.v1.Health/") {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	subject, ok := comp.Subject(ctx, func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+	decision := comp.Authorize(ctx, eauthz.Request{Kind: eauthz.KindGRPC, Resource: method, Subject: subject})
+	if decision.Allowed {
+		return nil
+	}
+	if !ok {
+		return eerrors.New(int(grpcCode.Unauthenticated), "authz", "unauthenticated")
+	}
+	return eerrors.New(int(grpcCode.PermissionDenied), "authz", "permission denied")
+}
+
+func maintenanceError(ctx context.Context, method string) error {
+	if !emaintenance.IsEnabled() || emaintenance.AllowMethod(method) {
+		return nil
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(emaintenance.RetryAfter().Seconds()))))
+	return eerrors.New(int(grpcCode.Unavailable), "maintenance", string(emaintenance.RenderBody(method)))
+}
+
+// slowConsumerStreamServerInterceptor 服务端流的消息先放入缓冲区，由后台goroutine发送，对端读取太慢时按照策略处理
+// 消息放入缓冲区之前先编码，handler可以复用消息对象
+func slowConsumerStreamServerInterceptor(config estream.Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !info.IsServerStream {
+			return handler(srv, ss)
+		}
+		labels := estream.Labels{Type: emetric.TypeGRPCStream, Method: info.FullMethod, Peer: getPeerName(ss.Context())}
+		if labels.Peer == "" {
+			labels.Peer = getPeerIP(ss.Context())
+		}
+		sender := estream.NewSender(ss.Context(), config, labels, ss.SendMsg)
+		err := handler(srv, &bufferedServerStream{ServerStream: ss, sender: sender})
+		if closeErr := sender.Close(); err == nil {
+			err = closeErr
+		}
+		if errors.Is(err, estream.ErrSlowConsumer) {
+			return status.Error(grpcCode.ResourceExhausted, "slow consumer, stream send buffer is full")
+		}
+		return err
+	}
+}
+
+type bufferedServerStream struct {
+	grpc.ServerStream
+	sender *estream.Sender
+}
+
+// Context 慢消费者被断开后取消，handler可以及时停止生产消息
+func (s *bufferedServerStream) Context() context.Context {
+	return s.sender.Context()
+}
+
+// SendMsg ...
+func (s *bufferedServerStream) SendMsg(m interface{}) error {
+	msg := &grpc.PreparedMsg{}
+	if err := msg.Encode(s.ServerStream, m); err != nil {
+		return err
 	}
+	return s.sender.Send(msg)
 }
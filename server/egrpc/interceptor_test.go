@@ -2,22 +2,36 @@ package egrpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 
+	"github.com/gotomicro/ego/core/eauthz"
+	"github.com/gotomicro/ego/core/ecomponent"
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/eidentity"
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/erequestid"
 	"github.com/gotomicro/ego/internal/test/helloworld"
 )
 
@@ -250,3 +264,195 @@ func TestCtxStoreSet(t *testing.T) {
 	assert.Equal(t, "world", storeValue)
 	assert.True(t, true, out)
 }
+
+func TestRequestIDUnaryServerInterceptor(t *testing.T) {
+	interceptor := requestIDUnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return erequestid.FromContext(ctx), nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "rid-1"))
+	res, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "rid-1", res)
+
+	// 不合法的请求ID被忽略
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "bad id"))
+	res, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "", res)
+}
+
+func TestOverrideUnaryServerInterceptor(t *testing.T) {
+	interceptor := OverrideUnaryServerInterceptor(func(ctx context.Context, method string) map[string]any {
+		if method != "/helloworld.Greeter/SayHello" {
+			return nil
+		}
+		return map[string]any{"grpc.user": "fake"}
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return ecomponent.Resolve(ctx, "grpc.user", "real"), nil
+	}
+	res, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", res)
+	res, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayBye"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "real", res)
+}
+
+func TestPeerInfo(t *testing.T) {
+	state := tls.ConnectionState{
+		Version:            tls.VersionTLS13,
+		CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+		NegotiatedProtocol: "h2",
+		PeerCertificates:   []*x509.Certificate{{DNSNames: []string{"svc-order.internal"}}},
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	info := getPeerInfo(ctx)
+	assert.Equal(t, peerInfo{principal: "svc-order.internal", tlsVersion: "TLS 1.3", tlsCipher: "TLS_AES_128_GCM_SHA256", protocol: "h2"}, info)
+	assert.Len(t, info.fields(), 4)
+	assert.Len(t, info.attributes(), 4)
+
+	// 认证拦截器记录的用户优先
+	ctx = context.WithValue(ctx, ctxStoreStruct{}, &ctxStore{kvs: map[string]any{}})
+	SetPeerPrincipal(ctx, "user-1")
+	assert.Equal(t, "user-1", getPeerInfo(ctx).principal)
+
+	// 没有TLS时只记录认证的用户
+	ctx = eauthz.WithSubject(context.Background(), eauthz.Subject{ID: "user-2"})
+	assert.Equal(t, peerInfo{principal: "user-2"}, getPeerInfo(ctx))
+	assert.Empty(t, peerInfo{}.fields())
+}
+
+func TestAccessLoggerPeer(t *testing.T) {
+	logger := elog.DefaultContainer().Build(
+		elog.WithDebug(false),
+		elog.WithEnableAsync(false),
+		elog.WithFileName("peer.log"),
+	)
+	defer os.Remove(path.Join(logger.ConfigDir(), logger.ConfigName()))
+	c := DefaultContainer()
+	c.config.EnableAccessInterceptorPeer = true
+	c.logger = logger
+	interceptor := c.defaultUnaryServerInterceptor()
+	state := tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		SetPeerPrincipal(ctx, "user-1")
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	logged, err := os.ReadFile(path.Join(logger.ConfigDir(), logger.ConfigName()))
+	assert.NoError(t, err)
+	assert.Contains(t, string(logged), `"peerPrincipal":"user-1"`)
+	assert.Contains(t, string(logged), `"peerTlsVersion":"TLS 1.2"`)
+	assert.Contains(t, string(logged), `"peerTlsCipher":"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"`)
+}
+
+func TestAuthzUnaryServerInterceptor(t *testing.T) {
+	eauthz.DefaultContainer().Build(eauthz.WithRules(
+		eauthz.Rule{Resources: []string{"/helloworld.Greeter/*"}, Roles: []string{"reader"}},
+	))
+	interceptor := authzUnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+
+	_, err := interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.Unauthenticated, eerrors.FromError(err).GRPCStatus().Code())
+
+	ctx := eauthz.WithSubject(context.Background(), eauthz.Subject{ID: "1", Roles: []string{"writer"}})
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, codes.PermissionDenied, eerrors.FromError(err).GRPCStatus().Code())
+
+	ctx = eauthz.WithSubject(context.Background(), eauthz.Subject{ID: "1", Roles: []string{"reader"}})
+	res, err := interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+
+	// 健康检查不需要鉴权
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	assert.NoError(t, err)
+}
+
+func TestIdentityIncomingContext(t *testing.T) {
+	u, _ := url.Parse("spiffe://example.org/ns/default/sa/order")
+	info := credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{u}}}}}
+	ctx := identityIncomingContext(peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info}))
+	id, ok := eidentity.PeerIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, u.String(), id)
+	subject, ok := eauthz.SubjectFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, eauthz.Subject{ID: u.String(), Roles: []string{u.String()}}, subject)
+
+	// 非TLS连接不处理
+	ctx = identityIncomingContext(peer.NewContext(context.Background(), &peer.Peer{}))
+	_, ok = eidentity.PeerIDFromContext(ctx)
+	assert.False(t, ok)
+}
+
+type streamGreeter struct {
+	helloworld.UnimplementedGreeterServer
+	count int
+	size  int
+}
+
+func (g streamGreeter) SayHelloUnary2Stream(_ *helloworld.HelloRequest, stream helloworld.Greeter_SayHelloUnary2StreamServer) error {
+	resp := &helloworld.HelloResponse{}
+	for i := 0; i < g.count; i++ {
+		// 复用消息对象，缓冲区中保存的是编码后的消息
+		resp.Message = fmt.Sprintf("%d:%s", i, strings.Repeat("x", g.size))
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startStreamGreeter(t *testing.T, g streamGreeter, policy string) helloworld.GreeterClient {
+	c := DefaultContainer()
+	c.config.Network = "bufnet"
+	c.config.StreamSendBufferSize = 2
+	c.config.StreamSlowConsumerPolicy = policy
+	cmp := c.Build()
+	helloworld.RegisterGreeterServer(cmp.Server, g)
+	assert.NoError(t, cmp.Init())
+	go func() { _ = cmp.Start() }()
+	t.Cleanup(func() { _ = cmp.Stop() })
+	cc, err := grpc.Dial("",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return cmp.Listener().(*bufconn.Listener).Dial()
+		}))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+	return helloworld.NewGreeterClient(cc)
+}
+
+func TestSlowConsumerStream(t *testing.T) {
+	// 正常读取时所有消息按顺序发送完
+	cli := startStreamGreeter(t, streamGreeter{count: 5}, "")
+	stream, err := cli.SayHelloUnary2Stream(context.Background(), &helloworld.HelloRequest{})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		resp, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d:", i), resp.Message)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// 客户端不读取，流控窗口和缓冲区写满后断开
+	cli = startStreamGreeter(t, streamGreeter{count: 1000, size: 32 * 1024}, "disconnect")
+	stream, err = cli.SayHelloUnary2Stream(context.Background(), &helloworld.HelloRequest{})
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -7,6 +7,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/server"
 )
 
 // Option overrides a Container's default configuration.
@@ -45,6 +46,20 @@ func WithUnaryInterceptor(interceptors ...grpc.UnaryServerInterceptor) Option {
 	}
 }
 
+// WithUnaryInterceptorPlacement 按照指定的位置注入unary拦截器，name在链中需要唯一，例如放在trace之后 server.Placement{After: server.InterceptorTrace}
+func WithUnaryInterceptorPlacement(name string, placement server.Placement, interceptor grpc.UnaryServerInterceptor) Option {
+	return func(c *Container) {
+		c.config.placedUnaryInterceptors = append(c.config.placedUnaryInterceptors, placed[grpc.UnaryServerInterceptor]{name: name, placement: placement, handler: interceptor})
+	}
+}
+
+// WithStreamInterceptorPlacement 按照指定的位置注入stream拦截器，参考 WithUnaryInterceptorPlacement
+func WithStreamInterceptorPlacement(name string, placement server.Placement, interceptor grpc.StreamServerInterceptor) Option {
+	return func(c *Container) {
+		c.config.placedStreamInterceptors = append(c.config.placedStreamInterceptors, placed[grpc.StreamServerInterceptor]{name: name, placement: placement, handler: interceptor})
+	}
+}
+
 // WithUnaryServerResourceExtractor sets the resource extractor of unary server request.
 func WithUnaryServerResourceExtractor(fn func(context.Context, interface{}, *grpc.UnaryServerInfo) string) Option {
 	return func(c *Container) {
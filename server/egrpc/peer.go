@@ -0,0 +1,104 @@
+package egrpc
+
+import (
+	"context"
+	"crypto/tls"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/gotomicro/ego/core/eauthz"
+	"github.com/gotomicro/ego/core/eidentity"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/etrace"
+)
+
+// peerPrincipalKey ctxStore中认证用户的key
+const peerPrincipalKey = "peer.principal"
+
+// SetPeerPrincipal 记录认证拦截器解析出的用户，例如JWT的sub，开启EnableAccessInterceptorPeer后写入访问日志和链路
+// 认证拦截器在访问日志拦截器之后执行，ctx中的值无法传回外层，所以通过ctxStore记录
+func SetPeerPrincipal(ctx context.Context, principal string) {
+	CtxStoreSet(ctx, peerPrincipalKey, principal)
+	trace.SpanFromContext(ctx).SetAttributes(etrace.CustomTag("enduser.id", principal))
+}
+
+// peerInfo 对端的身份和TLS信息
+type peerInfo struct {
+	principal  string // 认证的用户，优先使用SetPeerPrincipal记录的用户，其次为eauthz的用户，最后为mTLS证书的SAN
+	tlsVersion string // TLS版本，例如 TLS 1.3
+	tlsCipher  string // 加密套件
+	protocol   string // ALPN协商的协议，例如 h2
+}
+
+func getPeerInfo(ctx context.Context) peerInfo {
+	var info peerInfo
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state := tlsInfo.State
+			info.tlsVersion = tls.VersionName(state.Version)
+			info.tlsCipher = tls.CipherSuiteName(state.CipherSuite)
+			info.protocol = state.NegotiatedProtocol
+			info.principal = certPrincipal(state)
+		}
+	}
+	if subject, ok := eauthz.SubjectFromContext(ctx); ok && subject.ID != "" {
+		info.principal = subject.ID
+	}
+	if skv, ok := ctx.Value(ctxStoreStruct{}).(*ctxStore); ok {
+		if principal, ok := skv.kvs[peerPrincipalKey].(string); ok && principal != "" {
+			info.principal = principal
+		}
+	}
+	return info
+}
+
+// certPrincipal 对端证书的身份，依次使用SPIFFE ID、URI SAN、DNS SAN、CN
+func certPrincipal(state tls.ConnectionState) string {
+	if id, err := eidentity.PeerID(state); err == nil {
+		return id
+	}
+	if len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	cert := state.PeerCertificates[0]
+	switch {
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+	return cert.Subject.CommonName
+}
+
+// fields 访问日志的字段，空值不记录
+func (p peerInfo) fields() []elog.Field {
+	fields := make([]elog.Field, 0, 4)
+	if p.principal != "" {
+		fields = append(fields, elog.String("peerPrincipal", p.principal))
+	}
+	if p.tlsVersion != "" {
+		fields = append(fields, elog.String("peerTlsVersion", p.tlsVersion), elog.String("peerTlsCipher", p.tlsCipher))
+	}
+	if p.protocol != "" {
+		fields = append(fields, elog.String("peerProtocol", p.protocol))
+	}
+	return fields
+}
+
+// attributes 链路的属性，空值不记录
+func (p peerInfo) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 4)
+	if p.principal != "" {
+		attrs = append(attrs, etrace.CustomTag("enduser.id", p.principal))
+	}
+	if p.tlsVersion != "" {
+		attrs = append(attrs, etrace.CustomTag("tls.protocol.version", p.tlsVersion), etrace.CustomTag("tls.cipher", p.tlsCipher))
+	}
+	if p.protocol != "" {
+		attrs = append(attrs, etrace.CustomTag("tls.next_protocol", p.protocol))
+	}
+	return attrs
+}
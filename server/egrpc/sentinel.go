@@ -0,0 +1,56 @@
+//go:build !ego_nosentinel
+
+package egrpc
+
+import (
+	"context"
+
+	sentinel "github.com/alibaba/sentinel-golang/api"
+	sentinelBase "github.com/alibaba/sentinel-golang/core/base"
+	"google.golang.org/grpc"
+	grpcCode "google.golang.org/grpc/codes"
+
+	"github.com/gotomicro/ego/core/eerrors"
+	"github.com/gotomicro/ego/core/esentinel"
+)
+
+// NewUnaryServerInterceptor creates the unary server interceptor wrapped with Sentinel entry.
+func (c *Container) sentinelInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		// method as resource name by default
+		resourceName := info.FullMethod
+		if c.config.unaryServerResourceExtract != nil {
+			resourceName = c.config.unaryServerResourceExtract(ctx, req, info)
+		}
+
+		if !esentinel.IsResExist(resourceName) {
+			return handler(ctx, req)
+		}
+
+		// var entry *sentinelBase.SentinelEntry = nil
+		entry, blockErr := sentinel.Entry(
+			resourceName,
+			sentinel.WithResourceType(sentinelBase.ResTypeRPC),
+			sentinel.WithTrafficType(sentinelBase.Inbound),
+		)
+		if blockErr != nil {
+			if c.config.unaryServerBlockFallback != nil {
+				return c.config.unaryServerBlockFallback(ctx, req, info, blockErr)
+			}
+
+			return nil, eerrors.New(int(grpcCode.ResourceExhausted), "blocked by sentinel", blockErr.Error())
+		}
+		defer entry.Exit()
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			sentinel.TraceError(entry, err)
+		}
+		return res, err
+	}
+}
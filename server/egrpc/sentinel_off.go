@@ -0,0 +1,16 @@
+//go:build ego_nosentinel
+
+package egrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// sentinelInterceptor 使用ego_nosentinel构建时没有sentinel，Build不会添加该拦截器
+func (c *Container) sentinelInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+}
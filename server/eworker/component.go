@@ -0,0 +1,190 @@
+package eworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/ehealth"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/server"
+)
+
+// PackageName 包名
+const PackageName = "server.eworker"
+
+// WorkFunc worker每次执行的函数，返回nil后间隔Interval再次执行，返回错误或者panic时按照退避策略重启
+// 长时间运行的函数需要定期调用Heartbeat，否则会被认为不健康
+type WorkFunc func(ctx context.Context) error
+
+type heartbeatKey struct{}
+
+// Heartbeat 在WorkFunc中调用，更新worker最近一次活跃的时间
+func Heartbeat(ctx context.Context) {
+	if c, ok := ctx.Value(heartbeatKey{}).(*Component); ok {
+		c.beat()
+	}
+}
+
+// Component 没有监听的后台worker，像服务一样注册到ego，由ego管理启动和停止
+type Component struct {
+	name     string
+	config   *Config
+	work     WorkFunc
+	logger   *elog.Component
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+	started  atomic.Bool
+	lastBeat atomic.Int64 // 最近一次活跃的时间，UnixNano
+	restarts atomic.Int64 // 连续失败的次数
+}
+
+func newComponent(name string, config *Config, work WorkFunc, logger *elog.Component) *Component {
+	c := &Component{
+		name:   name,
+		config: config,
+		work:   work,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	return c
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// Init 初始化
+func (c *Component) Init() error {
+	c.beat()
+	ehealth.Register(c.healthName(), func(context.Context) error {
+		if !c.Health() {
+			return fmt.Errorf("eworker %s inactive for more than %v", c.name, c.config.HealthTimeout)
+		}
+		return nil
+	}, ehealth.WithType("worker"), ehealth.WithLiveness(true))
+	return nil
+}
+
+// Start 循环执行WorkFunc，直到停止或者连续失败超过MaxRestarts
+func (c *Component) Start() error {
+	c.started.Store(true)
+	defer close(c.done)
+	defer ehealth.Unregister(c.healthName())
+	ctx := context.WithValue(c.ctx, heartbeatKey{}, c)
+	backoff := c.config.InitialBackoff
+	for {
+		err := c.run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		wait := c.config.Interval
+		if err == nil {
+			c.restarts.Store(0)
+			backoff = c.config.InitialBackoff
+		} else {
+			restarts := c.restarts.Add(1)
+			if c.config.MaxRestarts >= 0 && restarts > int64(c.config.MaxRestarts) {
+				c.logger.Error("worker exceeded max restarts", elog.Int("maxRestarts", c.config.MaxRestarts), elog.FieldErr(err))
+				return fmt.Errorf("eworker %s exceeded max restarts %d, last err: %w", c.name, c.config.MaxRestarts, err)
+			}
+			c.logger.Warn("worker restart", elog.Int64("restarts", restarts), elog.Duration("backoff", backoff), elog.FieldErr(err))
+			wait = backoff
+			if backoff *= 2; backoff > c.config.MaxBackoff {
+				backoff = c.config.MaxBackoff
+			}
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// run 执行一次WorkFunc，panic转换为错误
+func (c *Component) run(ctx context.Context) (err error) {
+	beg := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			stack := make([]byte, 4096)
+			length := runtime.Stack(stack, false)
+			err = fmt.Errorf("panic: %v", rec)
+			c.logger.Error("worker panic", elog.FieldErr(err), elog.ByteString("stack", stack[:length]))
+			emetric.JobHandleCounter.Inc("worker", c.name, "Panic")
+		} else if err != nil && !errors.Is(err, context.Canceled) {
+			emetric.JobHandleCounter.Inc("worker", c.name, "Error")
+		} else {
+			emetric.JobHandleCounter.Inc("worker", c.name, "OK")
+		}
+		emetric.JobHandleHistogram.Observe(time.Since(beg).Seconds(), "worker", c.name)
+		if err == nil {
+			c.beat()
+		}
+	}()
+	return c.work(ctx)
+}
+
+// Stop 停止worker，不等待当前执行结束
+func (c *Component) Stop() error {
+	c.stopOnce.Do(c.cancel)
+	return nil
+}
+
+// GracefulStop 停止worker，等待当前执行结束
+func (c *Component) GracefulStop(ctx context.Context) error {
+	c.stopOnce.Do(c.cancel)
+	if !c.started.Load() {
+		return nil
+	}
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("eworker %s graceful stop, err: %w", c.name, context.Cause(ctx))
+	}
+}
+
+// Health 最近一次执行完成或者心跳在HealthTimeout以内时健康
+func (c *Component) Health() bool {
+	if c.config.HealthTimeout <= 0 {
+		return true
+	}
+	return time.Since(time.Unix(0, c.lastBeat.Load())) <= c.config.HealthTimeout
+}
+
+// Info 服务信息，worker不会注册到注册中心
+func (c *Component) Info() *server.ServiceInfo {
+	info := server.ApplyOptions(
+		server.WithScheme("worker"),
+		server.WithKind(constant.ServiceWorker),
+	)
+	return &info
+}
+
+func (c *Component) beat() {
+	c.lastBeat.Store(time.Now().UnixNano())
+}
+
+func (c *Component) healthName() string {
+	return "worker." + c.name
+}
@@ -0,0 +1,107 @@
+package eworker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/constant"
+)
+
+func TestComponentRun(t *testing.T) {
+	var runs int32
+	comp := DefaultContainer().Build(
+		WithName("counter"),
+		WithInterval(10*time.Millisecond),
+		WithWorkFunc(func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}),
+	)
+	assert.NoError(t, comp.Init())
+	done := make(chan error, 1)
+	go func() { done <- comp.Start() }()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 3 }, time.Second, 5*time.Millisecond)
+	assert.True(t, comp.Health())
+	assert.Equal(t, constant.ServiceWorker, comp.Info().Kind)
+
+	assert.NoError(t, comp.GracefulStop(context.Background()))
+	assert.NoError(t, <-done)
+}
+
+func TestComponentRestart(t *testing.T) {
+	var runs int32
+	comp := DefaultContainer().Build(
+		WithName("flaky"),
+		WithMaxRestarts(2),
+		WithBackoff(5*time.Millisecond, 10*time.Millisecond),
+		WithWorkFunc(func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				panic("boom")
+			}
+			return errors.New("always fail")
+		}),
+	)
+	assert.NoError(t, comp.Init())
+	err := comp.Start()
+	assert.EqualError(t, err, "eworker flaky exceeded max restarts 2, last err: always fail")
+	// 一次执行 + 两次重启
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+}
+
+func TestComponentRestartReset(t *testing.T) {
+	var runs int32
+	comp := DefaultContainer().Build(
+		WithMaxRestarts(1),
+		WithInterval(time.Millisecond),
+		WithBackoff(time.Millisecond, time.Millisecond),
+		WithWorkFunc(func(ctx context.Context) error {
+			// 失败和成功交替出现，成功后重新计算连续失败次数
+			if atomic.AddInt32(&runs, 1)%2 == 1 {
+				return errors.New("fail")
+			}
+			return nil
+		}),
+	)
+	done := make(chan error, 1)
+	go func() { done <- comp.Start() }()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 10 }, time.Second, time.Millisecond)
+	assert.NoError(t, comp.Stop())
+	assert.NoError(t, <-done)
+}
+
+func TestComponentHealth(t *testing.T) {
+	comp := DefaultContainer().Build(
+		WithHealthTimeout(50*time.Millisecond),
+		WithWorkFunc(func(ctx context.Context) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+					Heartbeat(ctx)
+				}
+			}
+		}),
+	)
+	assert.NoError(t, comp.Init())
+	go func() { _ = comp.Start() }()
+	time.Sleep(150 * time.Millisecond)
+	// 长时间运行的函数通过心跳保持健康
+	assert.True(t, comp.Health())
+	assert.NoError(t, comp.GracefulStop(context.Background()))
+
+	comp.lastBeat.Store(time.Now().Add(-time.Second).UnixNano())
+	assert.False(t, comp.Health())
+}
+
+func TestGracefulStopNotStarted(t *testing.T) {
+	comp := DefaultContainer().Build(WithWorkFunc(func(ctx context.Context) error { return nil }))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, comp.GracefulStop(ctx))
+}
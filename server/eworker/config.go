@@ -0,0 +1,27 @@
+package eworker
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+// Config 配置
+type Config struct {
+	Interval       time.Duration // 两次执行之间的间隔，默认1s
+	HealthTimeout  time.Duration // 超过该时间没有完成执行或者心跳时认为不健康，默认1m
+	MaxRestarts    int           // 连续失败后的最大重启次数，超过后worker停止并返回错误，小于0时不限制，默认10
+	InitialBackoff time.Duration // 失败后第一次重启的等待时间，之后每次翻倍，默认1s
+	MaxBackoff     time.Duration // 失败后重启的最大等待时间，默认1m
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:       xtime.Duration("1s"),
+		HealthTimeout:  xtime.Duration("1m"),
+		MaxRestarts:    10,
+		InitialBackoff: xtime.Duration("1s"),
+		MaxBackoff:     xtime.Duration("1m"),
+	}
+}
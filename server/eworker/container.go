@@ -0,0 +1,51 @@
+package eworker
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Container 容器
+type Container struct {
+	config *Config
+	name   string
+	logger *elog.Component
+	work   WorkFunc
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.work == nil {
+		c.logger.Panic("work func can not be nil", elog.FieldKey("use WithWorkFunc option to set work func"))
+	}
+	if c.config.InitialBackoff <= 0 {
+		c.config.InitialBackoff = DefaultConfig().InitialBackoff
+	}
+	if c.config.MaxBackoff < c.config.InitialBackoff {
+		c.config.MaxBackoff = c.config.InitialBackoff
+	}
+	return newComponent(c.name, c.config, c.work, c.logger)
+}
@@ -0,0 +1,51 @@
+package eworker
+
+import (
+	"time"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// WithName 设置worker的名称，Load时默认为配置key
+func WithName(name string) Option {
+	return func(c *Container) {
+		c.name = name
+	}
+}
+
+// WithWorkFunc 设置worker每次执行的函数
+func WithWorkFunc(work WorkFunc) Option {
+	return func(c *Container) {
+		c.work = work
+	}
+}
+
+// WithInterval 设置两次执行之间的间隔
+func WithInterval(interval time.Duration) Option {
+	return func(c *Container) {
+		c.config.Interval = interval
+	}
+}
+
+// WithMaxRestarts 设置连续失败后的最大重启次数，小于0时不限制
+func WithMaxRestarts(maxRestarts int) Option {
+	return func(c *Container) {
+		c.config.MaxRestarts = maxRestarts
+	}
+}
+
+// WithBackoff 设置失败后重启的等待时间
+func WithBackoff(initial, max time.Duration) Option {
+	return func(c *Container) {
+		c.config.InitialBackoff = initial
+		c.config.MaxBackoff = max
+	}
+}
+
+// WithHealthTimeout 设置健康检查的超时时间
+func WithHealthTimeout(timeout time.Duration) Option {
+	return func(c *Container) {
+		c.config.HealthTimeout = timeout
+	}
+}
@@ -147,23 +147,25 @@ func WithKind(kind constant.ServiceKind) Option {
 }
 
 func defaultServiceInfo() ServiceInfo {
+	res := eapp.GetResource()
 	si := ServiceInfo{
-		Name:       eapp.Name(),
+		Name:       res.ServiceName,
 		Weight:     100,
 		Enable:     true,
 		Healthy:    true,
 		Metadata:   make(map[string]string),
-		Region:     eapp.AppRegion(),
-		Zone:       eapp.AppZone(),
+		Region:     res.Region,
+		Zone:       res.Zone,
 		Kind:       0,
 		Deployment: "",
 		Group:      "",
 	}
-	si.Metadata["appMode"] = eapp.AppMode()
+	si.Metadata["appMode"] = res.Env
 	si.Metadata["appHost"] = eflag.String("host")
 	si.Metadata["startTime"] = eapp.StartTime()
 	si.Metadata["buildTime"] = eapp.BuildTime()
-	si.Metadata["appVersion"] = eapp.AppVersion()
+	si.Metadata["appVersion"] = res.ServiceVersion
+	si.Metadata["instanceId"] = res.InstanceID
 	si.Metadata["egoVersion"] = eapp.EgoVersion()
 	si.Metadata["depEnv"] = os.Getenv(constant.EgoDeploymentEnv) // 部署环境
 	return si
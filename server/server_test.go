@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/gotomicro/ego/core/constant"
+	"github.com/gotomicro/ego/core/eapp"
 	"github.com/gotomicro/ego/core/util/xtime"
 )
 
@@ -42,6 +43,7 @@ func TestServiceInfo(t *testing.T) {
 					"appVersion": "",
 					"buildTime":  "",
 					"egoVersion": "unknown version",
+					"instanceId": eapp.AppInstance(),
 					"key":        "val",
 					"depEnv":     "",
 					"startTime":  xtime.TS.Format(time.Now()),
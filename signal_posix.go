@@ -8,3 +8,6 @@ import (
 )
 
 var shutdownSignals = []os.Signal{syscall.SIGQUIT, os.Interrupt, syscall.SIGTERM}
+
+// upgradeSignals 开启 ego.upgrade.enable 后默认的热升级信号量
+var upgradeSignals = []os.Signal{syscall.SIGUSR2}
@@ -8,3 +8,6 @@ import (
 )
 
 var shutdownSignals = []os.Signal{syscall.SIGQUIT, os.Interrupt}
+
+// windows不支持热升级
+var upgradeSignals []os.Signal
@@ -57,7 +57,7 @@ type Component struct {
 }
 
 func newComponent(name string, config *Config, logger *elog.Component) *Component {
-	return &Component{
+	comp := &Component{
 		config: config,
 		cron: cron.New(
 			cron.WithParser(config.parser),
@@ -68,6 +68,8 @@ func newComponent(name string, config *Config, logger *elog.Component) *Componen
 		name:   name,
 		logger: logger,
 	}
+	registerComponent(comp)
+	return comp
 }
 
 // Name 名称
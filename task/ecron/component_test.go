@@ -210,3 +210,26 @@ spec = "0/1 * * * * *"
 		return
 	}
 }
+
+func TestSchedules(t *testing.T) {
+	comp, err := testBuildComp("cron.schedule", `[cron.schedule]
+spec = "*/1 * * * * *"
+enableSeconds = true`)
+	if err != nil {
+		t.Fatalf("load config failed. err=%s", err.Error())
+	}
+	comp.config.job = func(ctx context.Context) error { return nil }
+	go func() { _ = comp.Start() }()
+	defer func() { _ = comp.Stop() }()
+
+	time.Sleep(100 * time.Millisecond)
+	var info ScheduleInfo
+	for _, item := range Schedules() {
+		if item.Name == "cron.schedule" {
+			info = item
+		}
+	}
+	if !info.Scheduled || info.Spec != "*/1 * * * * *" || info.Next.IsZero() {
+		t.Errorf("unexpected schedule info: %+v", info)
+	}
+}
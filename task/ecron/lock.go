@@ -9,6 +9,7 @@ import (
 // Lock ...
 // implementations:
 //		Redis: [ecronlock](github.com/gotomicro/eredis@v0.2.0+)
+//		Redis/etcd: elock.Component.CronLock(github.com/gotomicro/ego/client/elock)
 //
 type Lock interface {
 	Lock(ctx context.Context, ttl time.Duration) error
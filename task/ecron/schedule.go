@@ -0,0 +1,62 @@
+package ecron
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	componentsMu sync.RWMutex
+	components   = make(map[string]*Component)
+)
+
+// ScheduleInfo 定时任务的调度信息，用于治理端展示
+type ScheduleInfo struct {
+	Name        string    `json:"name"`
+	Spec        string    `json:"spec"`
+	Enable      bool      `json:"enable"`
+	Distributed bool      `json:"distributed"`
+	Scheduled   bool      `json:"scheduled"` // 是否已经加入调度，分布式任务未抢到锁时为false
+	Prev        time.Time `json:"prev"`
+	Next        time.Time `json:"next"`
+}
+
+func registerComponent(c *Component) {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	components[c.name] = c
+}
+
+// Schedules 返回所有定时任务的调度信息，按名称排序
+func Schedules() []ScheduleInfo {
+	componentsMu.RLock()
+	comps := make([]*Component, 0, len(components))
+	for _, c := range components {
+		comps = append(comps, c)
+	}
+	componentsMu.RUnlock()
+
+	infos := make([]ScheduleInfo, 0, len(comps))
+	for _, c := range comps {
+		infos = append(infos, c.ScheduleInfo())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// ScheduleInfo 返回调度信息
+func (c *Component) ScheduleInfo() ScheduleInfo {
+	info := ScheduleInfo{
+		Name:        c.name,
+		Spec:        c.config.Spec,
+		Enable:      c.config.Enable,
+		Distributed: c.config.EnableDistributedTask,
+	}
+	for _, entry := range c.cron.Entries() {
+		info.Scheduled = true
+		info.Prev = entry.Prev
+		info.Next = entry.Next
+	}
+	return info
+}
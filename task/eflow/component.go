@@ -0,0 +1,331 @@
+package eflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// PackageName 包名
+const PackageName = "task.eflow"
+
+var (
+	// ErrUnknownWorkflow 工作流没有注册
+	ErrUnknownWorkflow = errors.New("eflow: unknown workflow")
+	// ErrNoTransition 当前状态不能处理该事件
+	ErrNoTransition = errors.New("eflow: no transition")
+	// ErrNotRunning 工作流已经结束
+	ErrNotRunning = errors.New("eflow: instance not running")
+	// ErrRejected 状态转换被WithGuard拒绝
+	ErrRejected = errors.New("eflow: transition rejected")
+)
+
+// Component 工作流引擎，定时触发状态的超时事件，统计卡住的工作流
+type Component struct {
+	name   string
+	config *Config
+	store  Store
+	logger *elog.Component
+	cron   *ecron.Component
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.RWMutex
+	defs  map[string]*Definition
+	stuck map[StuckCount]struct{} // 上次上报的卡住的工作流和状态，Count为0
+}
+
+func newComponent(name string, config *Config, store Store, defs map[string]*Definition, logger *elog.Component, cronOptions []ecron.Option) *Component {
+	c := &Component{
+		name:   name,
+		config: config,
+		store:  store,
+		logger: logger,
+		defs:   defs,
+		stuck:  make(map[StuckCount]struct{}),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	opts := append([]ecron.Option{ecron.WithSpec(config.Spec), ecron.WithJob(c.run)}, cronOptions...)
+	c.cron = ecron.DefaultContainer().Build(opts...)
+	return c
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// Init 初始化
+func (c *Component) Init() error {
+	return nil
+}
+
+// Start 启动定时任务
+func (c *Component) Start() error {
+	return c.cron.Start()
+}
+
+// Stop 停止定时任务
+func (c *Component) Stop() error {
+	c.cancel()
+	return c.cron.Stop()
+}
+
+// Register 注册工作流定义，重名时覆盖
+func (c *Component) Register(def *Definition) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defs[def.name] = def
+	return nil
+}
+
+func (c *Component) definition(workflow string) (*Definition, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	def, ok := c.defs[workflow]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownWorkflow, workflow)
+	}
+	return def, nil
+}
+
+// Create 创建工作流实例，实例处于初始状态
+func (c *Component) Create(ctx context.Context, workflow, id string, data map[string]interface{}) (*Instance, error) {
+	def, err := c.definition(workflow)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	now := time.Now()
+	inst := &Instance{
+		ID:        id,
+		Workflow:  workflow,
+		Status:    StatusRunning,
+		Data:      data,
+		Version:   1,
+		CreatedAt: now,
+	}
+	enter(def, inst, def.initial, now)
+	if err := c.store.Create(ctx, inst); err != nil {
+		return nil, fmt.Errorf("eflow: create instance %s fail, %w", id, err)
+	}
+	return inst, nil
+}
+
+// Get 读取工作流实例
+func (c *Component) Get(ctx context.Context, id string) (*Instance, error) {
+	return c.store.Get(ctx, id)
+}
+
+// Fire 向工作流实例发送事件，执行状态转换
+// 动作执行失败时按相反顺序执行已完成转换的补偿动作，并返回动作的错误
+// 并发修改同一个实例时返回ErrConflict，调用方可以重试
+func (c *Component) Fire(ctx context.Context, id string, event Event) (*Instance, error) {
+	inst, err := c.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.fire(ctx, inst, event)
+}
+
+func (c *Component) fire(ctx context.Context, inst *Instance, event Event) (*Instance, error) {
+	def, err := c.definition(inst.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if inst.Status != StatusRunning {
+		return inst, fmt.Errorf("%w, id: %s, status: %s", ErrNotRunning, inst.ID, inst.Status)
+	}
+	t := def.transition(inst.State, event)
+	if t == nil {
+		return inst, fmt.Errorf("%w from %s on %s", ErrNoTransition, inst.State, event)
+	}
+	if t.guard != nil {
+		if err := t.guard(ctx, inst); err != nil {
+			c.metric(inst.Workflow, event, "rejected")
+			return inst, fmt.Errorf("%w, %v", ErrRejected, err)
+		}
+	}
+
+	next := inst.clone()
+	next.Version++
+	now := time.Now()
+	if t.action != nil {
+		if err := c.safeRun(ctx, t.action, next); err != nil {
+			c.logger.Error("workflow action fail, compensate", elog.FieldName(inst.Workflow), elog.FieldKey(inst.ID), elog.String("event", string(event)), elog.FieldErr(err))
+			// 补偿基于转换之前的实例，动作对Data的修改不保存
+			next = inst.clone()
+			next.Version++
+			code := c.compensate(ctx, def, next, err, now)
+			c.metric(inst.Workflow, event, code)
+			if saveErr := c.store.Update(ctx, next); saveErr != nil {
+				return inst, fmt.Errorf("eflow: save instance %s fail, %w", inst.ID, saveErr)
+			}
+			return next, fmt.Errorf("eflow: %s action fail, %w", event, err)
+		}
+	}
+	next.History = append(next.History, Step{From: inst.State, Event: event, To: t.to, At: now})
+	enter(def, next, t.to, now)
+	if err := c.store.Update(ctx, next); err != nil {
+		c.metric(inst.Workflow, event, "error")
+		return inst, fmt.Errorf("eflow: save instance %s fail, %w", inst.ID, err)
+	}
+	c.metric(inst.Workflow, event, "ok")
+	return next, nil
+}
+
+// Cancel 取消运行中的工作流实例，按相反顺序执行已完成转换的补偿动作
+func (c *Component) Cancel(ctx context.Context, id string, reason string) (*Instance, error) {
+	inst, err := c.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	def, err := c.definition(inst.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if inst.Status != StatusRunning {
+		return inst, fmt.Errorf("%w, id: %s, status: %s", ErrNotRunning, inst.ID, inst.Status)
+	}
+	next := inst.clone()
+	next.Version++
+	code := c.compensate(ctx, def, next, errors.New(reason), time.Now())
+	c.metric(inst.Workflow, "cancel", code)
+	if err := c.store.Update(ctx, next); err != nil {
+		return inst, fmt.Errorf("eflow: save instance %s fail, %w", inst.ID, err)
+	}
+	if next.Status == StatusFailed {
+		return next, fmt.Errorf("eflow: compensate instance %s fail, %s", inst.ID, next.Error)
+	}
+	return next, nil
+}
+
+// compensate 按相反顺序执行补偿动作，某个补偿失败时停止，实例标记为failed
+func (c *Component) compensate(ctx context.Context, def *Definition, inst *Instance, cause error, now time.Time) string {
+	inst.Status = StatusCompensated
+	inst.Error = cause.Error()
+	inst.TimeoutAt = time.Time{}
+	inst.UpdatedAt = now
+	for i := len(inst.History) - 1; i >= 0; i-- {
+		step := inst.History[i]
+		t := def.transition(step.From, step.Event)
+		if t == nil || t.compensate == nil {
+			continue
+		}
+		if err := c.safeRun(ctx, t.compensate, inst); err != nil {
+			c.logger.Error("workflow compensation fail", elog.FieldName(inst.Workflow), elog.FieldKey(inst.ID), elog.String("event", string(step.Event)), elog.FieldErr(err))
+			inst.Status = StatusFailed
+			inst.Error = fmt.Sprintf("%s; compensate %s fail, %v", inst.Error, step.Event, err)
+			return "failed"
+		}
+	}
+	return "compensated"
+}
+
+func (c *Component) safeRun(ctx context.Context, fn Action, inst *Instance) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return fn(ctx, inst)
+}
+
+// enter 进入新状态，设置定时器，进入终止状态时工作流完成
+func enter(def *Definition, inst *Instance, state State, now time.Time) {
+	inst.State = state
+	inst.UpdatedAt = now
+	inst.TimeoutAt = time.Time{}
+	if def.finals[state] {
+		inst.Status = StatusCompleted
+		return
+	}
+	if timeout, ok := def.timeouts[state]; ok {
+		inst.TimeoutAt = now.Add(timeout.after)
+	}
+}
+
+func (c *Component) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+	return c.Scan(ctx)
+}
+
+// Scan 触发到期的定时器，并更新卡住的工作流监控，由定时任务执行
+func (c *Component) Scan(ctx context.Context) error {
+	now := time.Now()
+	due, err := c.store.Due(ctx, now, c.config.ScanLimit)
+	if err != nil {
+		return fmt.Errorf("eflow: list due instances fail, %w", err)
+	}
+	for _, inst := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		def, err := c.definition(inst.Workflow)
+		if err != nil {
+			c.logger.Warn("skip timer of unknown workflow", elog.FieldName(inst.Workflow), elog.FieldKey(inst.ID))
+			continue
+		}
+		timeout, ok := def.timeouts[inst.State]
+		if !ok {
+			continue
+		}
+		// 其他副本可能已经处理了这个定时器
+		if _, err := c.fire(ctx, inst, timeout.event); err != nil && !errors.Is(err, ErrConflict) {
+			c.logger.Error("workflow timer fail", elog.FieldName(inst.Workflow), elog.FieldKey(inst.ID), elog.String("event", string(timeout.event)), elog.FieldErr(err))
+		}
+	}
+	return c.checkStuck(ctx, now)
+}
+
+func (c *Component) checkStuck(ctx context.Context, now time.Time) error {
+	if !c.config.EnableMetric || c.config.StuckThreshold <= 0 {
+		return nil
+	}
+	stuck, err := c.store.Stuck(ctx, now.Add(-c.config.StuckThreshold))
+	if err != nil {
+		return fmt.Errorf("eflow: count stuck instances fail, %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := make(map[StuckCount]struct{}, len(stuck))
+	for _, item := range stuck {
+		emetric.FlowStuckGauge.Set(float64(item.Count), c.name, item.Workflow, string(item.State))
+		c.logger.Warn("workflow stuck", elog.FieldName(item.Workflow), elog.String("state", string(item.State)), elog.Int("count", item.Count))
+		current[StuckCount{Workflow: item.Workflow, State: item.State}] = struct{}{}
+	}
+	// 不再卡住的状态归零
+	for item := range c.stuck {
+		if _, ok := current[item]; !ok {
+			emetric.FlowStuckGauge.Set(0, c.name, item.Workflow, string(item.State))
+		}
+	}
+	c.stuck = current
+	return nil
+}
+
+func (c *Component) metric(workflow string, event Event, code string) {
+	if !c.config.EnableMetric {
+		return
+	}
+	emetric.FlowTransitionCounter.Inc(c.name, workflow, string(event), code)
+}
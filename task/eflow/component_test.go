@@ -0,0 +1,194 @@
+package eflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/emetric"
+)
+
+const (
+	created  State = "created"
+	paid     State = "paid"
+	reserved State = "reserved"
+	shipped  State = "shipped"
+	closed   State = "closed"
+)
+
+func orderFlow(calls *[]string, reserveErr error) *Definition {
+	return NewDefinition("order", created).
+		Transition(created, "pay", paid,
+			WithGuard(func(ctx context.Context, inst *Instance) error {
+				if inst.Data["amount"] == nil {
+					return errors.New("amount required")
+				}
+				return nil
+			}),
+			WithAction(func(ctx context.Context, inst *Instance) error {
+				*calls = append(*calls, "charge")
+				inst.Data["charged"] = true
+				return nil
+			}),
+			WithCompensation(func(ctx context.Context, inst *Instance) error {
+				*calls = append(*calls, "refund")
+				return nil
+			})).
+		Transition(paid, "reserve", reserved,
+			WithAction(func(ctx context.Context, inst *Instance) error {
+				*calls = append(*calls, "reserve")
+				return reserveErr
+			})).
+		Transition(reserved, "ship", shipped).
+		Transition(created, "expire", closed).
+		Timeout(created, 10*time.Millisecond, "expire").
+		Final(shipped, closed)
+}
+
+func TestLoad(t *testing.T) {
+	conf := `
+[flow]
+spec = "@every 1m"
+dialect = "postgres"
+stuckThreshold = "10m"
+`
+	require.NoError(t, econf.LoadFromReader(bytes.NewBufferString(conf), toml.Unmarshal))
+	comp := Load("flow").Build(WithDB(nil))
+	assert.Equal(t, "@every 1m", comp.config.Spec)
+	assert.Equal(t, 10*time.Minute, comp.config.StuckThreshold)
+	assert.Equal(t, DialectPostgres, comp.store.(*sqlStore).dialect)
+
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithDefinitions(NewDefinition("bad", created).Timeout(created, time.Second, "expire")))
+	})
+}
+
+func TestFire(t *testing.T) {
+	var calls []string
+	comp := DefaultContainer().Build(WithDefinitions(orderFlow(&calls, nil)))
+	ctx := context.Background()
+
+	_, err := comp.Create(ctx, "order", "1", nil)
+	require.NoError(t, err)
+	_, err = comp.Create(ctx, "order", "1", nil)
+	assert.ErrorIs(t, err, ErrExists)
+	_, err = comp.Create(ctx, "unknown", "2", nil)
+	assert.ErrorIs(t, err, ErrUnknownWorkflow)
+
+	_, err = comp.Fire(ctx, "1", "pay")
+	assert.ErrorIs(t, err, ErrRejected)
+	_, err = comp.Fire(ctx, "1", "ship")
+	assert.ErrorIs(t, err, ErrNoTransition)
+
+	_, err = comp.Create(ctx, "order", "3", map[string]interface{}{"amount": 100})
+	require.NoError(t, err)
+	for _, event := range []Event{"pay", "reserve", "ship"} {
+		_, err = comp.Fire(ctx, "3", event)
+		require.NoError(t, err)
+	}
+	inst, err := comp.Get(ctx, "3")
+	require.NoError(t, err)
+	assert.Equal(t, shipped, inst.State)
+	assert.Equal(t, StatusCompleted, inst.Status)
+	assert.Equal(t, true, inst.Data["charged"])
+	assert.Equal(t, int64(4), inst.Version)
+	assert.Len(t, inst.History, 3)
+	assert.True(t, inst.TimeoutAt.IsZero())
+	assert.Equal(t, []string{"charge", "reserve"}, calls)
+
+	_, err = comp.Fire(ctx, "3", "ship")
+	assert.ErrorIs(t, err, ErrNotRunning)
+}
+
+func TestCompensate(t *testing.T) {
+	var calls []string
+	comp := DefaultContainer().Build(WithDefinitions(orderFlow(&calls, errors.New("out of stock"))))
+	ctx := context.Background()
+
+	_, err := comp.Create(ctx, "order", "1", map[string]interface{}{"amount": 100})
+	require.NoError(t, err)
+	_, err = comp.Fire(ctx, "1", "pay")
+	require.NoError(t, err)
+	inst, err := comp.Fire(ctx, "1", "reserve")
+	assert.ErrorContains(t, err, "out of stock")
+	assert.Equal(t, StatusCompensated, inst.Status)
+	assert.Equal(t, paid, inst.State)
+	assert.Equal(t, []string{"charge", "reserve", "refund"}, calls)
+
+	// 取消时执行补偿
+	calls = nil
+	_, err = comp.Create(ctx, "order", "2", map[string]interface{}{"amount": 100})
+	require.NoError(t, err)
+	_, err = comp.Fire(ctx, "2", "pay")
+	require.NoError(t, err)
+	inst, err = comp.Cancel(ctx, "2", "user canceled")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompensated, inst.Status)
+	assert.Equal(t, "user canceled", inst.Error)
+	assert.Equal(t, []string{"charge", "refund"}, calls)
+}
+
+func TestConflict(t *testing.T) {
+	var calls []string
+	comp := DefaultContainer().Build(WithDefinitions(orderFlow(&calls, nil)))
+	ctx := context.Background()
+	_, err := comp.Create(ctx, "order", "1", map[string]interface{}{"amount": 100})
+	require.NoError(t, err)
+	stale, err := comp.Get(ctx, "1")
+	require.NoError(t, err)
+	_, err = comp.Fire(ctx, "1", "pay")
+	require.NoError(t, err)
+	_, err = comp.fire(ctx, stale, "expire")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestScan(t *testing.T) {
+	var calls []string
+	config := DefaultConfig()
+	config.StuckThreshold = 20 * time.Millisecond
+	comp := newComponent("scan", config, NewMemoryStore(), map[string]*Definition{}, DefaultContainer().logger, nil)
+	require.NoError(t, comp.Register(orderFlow(&calls, nil)))
+	ctx := context.Background()
+
+	_, err := comp.Create(ctx, "order", "1", nil)
+	require.NoError(t, err)
+	_, err = comp.Create(ctx, "order", "2", map[string]interface{}{"amount": 100})
+	require.NoError(t, err)
+	_, err = comp.Fire(ctx, "2", "pay")
+	require.NoError(t, err)
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, comp.Scan(ctx))
+	// 定时器到期，自动关闭
+	inst, err := comp.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, closed, inst.State)
+	assert.Equal(t, StatusCompleted, inst.Status)
+	assert.Equal(t, float64(1), testutil.ToFloat64(emetric.FlowStuckGauge.WithLabelValues("scan", "order", string(paid))))
+
+	_, err = comp.Fire(ctx, "2", "reserve")
+	require.NoError(t, err)
+	require.NoError(t, comp.Scan(ctx))
+	assert.Equal(t, float64(0), testutil.ToFloat64(emetric.FlowStuckGauge.WithLabelValues("scan", "order", string(paid))))
+}
+
+func TestSQLBind(t *testing.T) {
+	mysql := &sqlStore{dialect: DialectMySQL}
+	postgres := &sqlStore{dialect: DialectPostgres}
+	query := "UPDATE t SET state = ? WHERE id = ? AND version = ?"
+	assert.Equal(t, query, mysql.bind(query))
+	assert.Equal(t, "UPDATE t SET state = $1 WHERE id = $2 AND version = $3", postgres.bind(query))
+
+	inst := &Instance{ID: "1", Workflow: "order", State: paid, Status: StatusRunning, Data: map[string]interface{}{"amount": 100}, Version: 2, CreatedAt: time.UnixMilli(1000), UpdatedAt: time.UnixMilli(2000)}
+	args, err := instanceArgs(inst)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"1", "order", "paid", "running", `{"amount":100}`, "null", "", int64(2), int64(0), int64(1000), int64(2000)}, args)
+}
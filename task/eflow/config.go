@@ -0,0 +1,36 @@
+package eflow
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// DialectMySQL 使用 ? 作为占位符
+	DialectMySQL = "mysql"
+	// DialectPostgres 使用 $1 作为占位符
+	DialectPostgres = "postgres"
+)
+
+// Config 工作流配置
+type Config struct {
+	Spec           string        // 检查定时器和卡住的工作流的定时任务，默认 "@every 10s"
+	Dialect        string        // 数据库方言，mysql或者postgres，默认mysql
+	Table          string        // 保存工作流实例的数据表，默认 eflow_instance
+	ScanLimit      int           // 每次最多处理的到期定时器数，默认100
+	StuckThreshold time.Duration // 运行中的工作流超过该时间没有状态变化，记为卡住，默认1h
+	EnableMetric   bool          // 是否开启监控，默认开启
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Spec:           "@every 10s",
+		Dialect:        DialectMySQL,
+		Table:          "eflow_instance",
+		ScanLimit:      100,
+		StuckThreshold: xtime.Duration("1h"),
+		EnableMetric:   true,
+	}
+}
@@ -0,0 +1,55 @@
+package eflow
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// Container 容器
+type Container struct {
+	config      *Config
+	name        string
+	logger      *elog.Component
+	store       Store
+	defs        []*Definition
+	cronOptions []ecron.Option
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.store == nil {
+		c.store = NewMemoryStore()
+	}
+	defs := make(map[string]*Definition, len(c.defs))
+	for _, def := range c.defs {
+		if err := def.validate(); err != nil {
+			c.logger.Panic("invalid workflow definition", elog.FieldErr(err))
+		}
+		defs[def.name] = def
+	}
+	return newComponent(c.name, c.config, c.store, defs, c.logger, c.cronOptions)
+}
@@ -0,0 +1,130 @@
+package eflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// State 工作流的状态
+type State string
+
+// Event 触发状态转换的事件
+type Event string
+
+// Action 状态转换时执行的动作，可以修改 Instance.Data，修改随状态一起保存
+type Action func(ctx context.Context, inst *Instance) error
+
+// Definition 工作流定义，描述状态、状态转换和补偿动作
+type Definition struct {
+	name        string
+	initial     State
+	finals      map[State]bool
+	timeouts    map[State]stateTimeout
+	transitions map[State]map[Event]*transition
+}
+
+type stateTimeout struct {
+	after time.Duration
+	event Event
+}
+
+type transition struct {
+	from       State
+	event      Event
+	to         State
+	guard      Action
+	action     Action
+	compensate Action
+}
+
+// TransitionOption 状态转换的可选项
+type TransitionOption func(t *transition)
+
+// WithGuard 状态转换的前置检查，返回错误时拒绝转换，不会触发补偿
+func WithGuard(fn Action) TransitionOption {
+	return func(t *transition) {
+		t.guard = fn
+	}
+}
+
+// WithAction 状态转换时执行的动作，返回错误时按相反顺序执行已完成转换的补偿动作
+func WithAction(fn Action) TransitionOption {
+	return func(t *transition) {
+		t.action = fn
+	}
+}
+
+// WithCompensation 补偿动作，后续的转换失败或者工作流被取消时执行，例如支付成功后库存不足时退款
+func WithCompensation(fn Action) TransitionOption {
+	return func(t *transition) {
+		t.compensate = fn
+	}
+}
+
+// NewDefinition 创建工作流定义，initial为初始状态
+func NewDefinition(name string, initial State) *Definition {
+	return &Definition{
+		name:        name,
+		initial:     initial,
+		finals:      make(map[State]bool),
+		timeouts:    make(map[State]stateTimeout),
+		transitions: make(map[State]map[Event]*transition),
+	}
+}
+
+// Name 工作流名称
+func (d *Definition) Name() string {
+	return d.name
+}
+
+// Final 设置终止状态，进入终止状态后工作流完成
+func (d *Definition) Final(states ...State) *Definition {
+	for _, state := range states {
+		d.finals[state] = true
+	}
+	return d
+}
+
+// Timeout 进入state之后超过after没有离开，由定时任务触发event，例如订单超时未支付自动关闭
+func (d *Definition) Timeout(state State, after time.Duration, event Event) *Definition {
+	d.timeouts[state] = stateTimeout{after: after, event: event}
+	return d
+}
+
+// Transition 添加状态转换，在from状态收到event时转换到to状态
+func (d *Definition) Transition(from State, event Event, to State, opts ...TransitionOption) *Definition {
+	t := &transition{from: from, event: event, to: to}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if d.transitions[from] == nil {
+		d.transitions[from] = make(map[Event]*transition)
+	}
+	d.transitions[from][event] = t
+	return d
+}
+
+func (d *Definition) transition(from State, event Event) *transition {
+	return d.transitions[from][event]
+}
+
+func (d *Definition) validate() error {
+	if d.name == "" || d.initial == "" {
+		return fmt.Errorf("eflow: workflow name and initial state can not be empty")
+	}
+	for state, timeout := range d.timeouts {
+		if timeout.after <= 0 {
+			return fmt.Errorf("eflow: workflow %s state %s timeout must be positive", d.name, state)
+		}
+		if d.transition(state, timeout.event) == nil {
+			return fmt.Errorf("eflow: workflow %s state %s timeout event %s has no transition", d.name, state, timeout.event)
+		}
+	}
+	for state := range d.finals {
+		if len(d.transitions[state]) > 0 {
+			return fmt.Errorf("eflow: workflow %s final state %s can not have transitions", d.name, state)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package eflow
+
+import (
+	"time"
+)
+
+// Status 工作流实例的运行状态
+type Status string
+
+const (
+	// StatusRunning 运行中
+	StatusRunning Status = "running"
+	// StatusCompleted 进入了终止状态
+	StatusCompleted Status = "completed"
+	// StatusCompensated 转换失败或者被取消，补偿动作已经全部执行
+	StatusCompensated Status = "compensated"
+	// StatusFailed 补偿动作执行失败，需要人工处理
+	StatusFailed Status = "failed"
+)
+
+// Step 一次状态转换的记录
+type Step struct {
+	From  State     `json:"from"`
+	Event Event     `json:"event"`
+	To    State     `json:"to"`
+	At    time.Time `json:"at"`
+}
+
+// Instance 工作流实例
+type Instance struct {
+	ID        string                 `json:"id"`
+	Workflow  string                 `json:"workflow"`
+	State     State                  `json:"state"`
+	Status    Status                 `json:"status"`
+	Data      map[string]interface{} `json:"data"`
+	History   []Step                 `json:"history"`
+	Error     string                 `json:"error,omitempty"` // 转换或者补偿失败的原因
+	Version   int64                  `json:"version"`         // 每次保存加1，用于乐观锁
+	TimeoutAt time.Time              `json:"timeoutAt"`       // 当前状态的定时器到期时间，零值表示没有定时器
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// clone 复制实例，Data只复制第一层
+func (i *Instance) clone() *Instance {
+	out := *i
+	out.Data = make(map[string]interface{}, len(i.Data))
+	for k, v := range i.Data {
+		out.Data[k] = v
+	}
+	out.History = append([]Step(nil), i.History...)
+	return &out
+}
@@ -0,0 +1,38 @@
+package eflow
+
+import (
+	"database/sql"
+
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// WithStore 设置工作流实例的存储，默认使用内存存储
+func WithStore(store Store) Option {
+	return func(c *Container) {
+		c.store = store
+	}
+}
+
+// WithDB 使用数据库保存工作流实例，数据表为配置中的Table
+func WithDB(db *sql.DB) Option {
+	return func(c *Container) {
+		c.store = NewSQLStore(db, c.config.Dialect, c.config.Table)
+	}
+}
+
+// WithDefinitions 注册工作流定义
+func WithDefinitions(defs ...*Definition) Option {
+	return func(c *Container) {
+		c.defs = append(c.defs, defs...)
+	}
+}
+
+// WithCronOptions 设置定时任务的可选项，例如 ecron.WithLock，多副本部署时只需要一个副本扫描定时器
+func WithCronOptions(opts ...ecron.Option) Option {
+	return func(c *Container) {
+		c.cronOptions = append(c.cronOptions, opts...)
+	}
+}
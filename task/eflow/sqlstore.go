@@ -0,0 +1,186 @@
+package eflow
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlStore 数据库存储，时间使用unix毫秒保存，数据表结构：
+//
+//	CREATE TABLE eflow_instance (
+//		id         VARCHAR(64) PRIMARY KEY,
+//		workflow   VARCHAR(64) NOT NULL,
+//		state      VARCHAR(64) NOT NULL,
+//		status     VARCHAR(16) NOT NULL,
+//		data       TEXT        NOT NULL,
+//		history    TEXT        NOT NULL,
+//		error      TEXT        NOT NULL,
+//		version    BIGINT      NOT NULL,
+//		timeout_at BIGINT      NOT NULL,
+//		created_at BIGINT      NOT NULL,
+//		updated_at BIGINT      NOT NULL
+//	);
+//	CREATE INDEX idx_eflow_instance_timeout ON eflow_instance (status, timeout_at);
+type sqlStore struct {
+	db      *sql.DB
+	table   string
+	dialect string
+}
+
+// NewSQLStore 创建数据库存储，dialect为mysql或者postgres
+func NewSQLStore(db *sql.DB, dialect, table string) Store {
+	return &sqlStore{db: db, table: table, dialect: dialect}
+}
+
+const instanceColumns = "id, workflow, state, status, data, history, error, version, timeout_at, created_at, updated_at"
+
+// bind postgres使用 $1 作为占位符
+func (s *sqlStore) bind(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *sqlStore) Create(ctx context.Context, inst *Instance) error {
+	if _, err := s.Get(ctx, inst.ID); err == nil {
+		return ErrExists
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	args, err := instanceArgs(inst)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.bind("INSERT INTO "+s.table+" ("+instanceColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"), args...)
+	return err
+}
+
+func (s *sqlStore) Get(ctx context.Context, id string) (*Instance, error) {
+	row := s.db.QueryRowContext(ctx, s.bind("SELECT "+instanceColumns+" FROM "+s.table+" WHERE id = ?"), id)
+	inst, err := scanInstance(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return inst, err
+}
+
+func (s *sqlStore) Update(ctx context.Context, inst *Instance) error {
+	args, err := instanceArgs(inst)
+	if err != nil {
+		return err
+	}
+	// 第一个参数是id，放到WHERE中
+	args = append(args[1:], inst.ID, inst.Version-1)
+	res, err := s.db.ExecContext(ctx, s.bind("UPDATE "+s.table+" SET workflow = ?, state = ?, status = ?, data = ?, history = ?, error = ?, version = ?, timeout_at = ?, created_at = ?, updated_at = ? WHERE id = ? AND version = ?"), args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *sqlStore) Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind("SELECT "+instanceColumns+" FROM "+s.table+" WHERE status = ? AND timeout_at > 0 AND timeout_at <= ? ORDER BY timeout_at LIMIT ?"), StatusRunning, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]*Instance, 0)
+	for rows.Next() {
+		inst, err := scanInstance(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inst)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqlStore) Stuck(ctx context.Context, before time.Time) ([]StuckCount, error) {
+	rows, err := s.db.QueryContext(ctx, s.bind("SELECT workflow, state, COUNT(*) FROM "+s.table+" WHERE status = ? AND updated_at < ? GROUP BY workflow, state"), StatusRunning, before.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]StuckCount, 0)
+	for rows.Next() {
+		var item StuckCount
+		if err := rows.Scan(&item.Workflow, &item.State, &item.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func instanceArgs(inst *Instance) ([]interface{}, error) {
+	data, err := json.Marshal(inst.Data)
+	if err != nil {
+		return nil, err
+	}
+	history, err := json.Marshal(inst.History)
+	if err != nil {
+		return nil, err
+	}
+	var timeoutAt int64
+	if !inst.TimeoutAt.IsZero() {
+		timeoutAt = inst.TimeoutAt.UnixMilli()
+	}
+	return []interface{}{
+		inst.ID, inst.Workflow, string(inst.State), string(inst.Status), string(data), string(history), inst.Error,
+		inst.Version, timeoutAt, inst.CreatedAt.UnixMilli(), inst.UpdatedAt.UnixMilli(),
+	}, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInstance(row scanner) (*Instance, error) {
+	var (
+		inst                            Instance
+		data, history                   string
+		timeoutAt, createdAt, updatedAt int64
+	)
+	err := row.Scan(&inst.ID, &inst.Workflow, &inst.State, &inst.Status, &data, &history, &inst.Error, &inst.Version, &timeoutAt, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(data), &inst.Data); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(history), &inst.History); err != nil {
+		return nil, err
+	}
+	if inst.Data == nil {
+		inst.Data = make(map[string]interface{})
+	}
+	if timeoutAt > 0 {
+		inst.TimeoutAt = time.UnixMilli(timeoutAt)
+	}
+	inst.CreatedAt = time.UnixMilli(createdAt)
+	inst.UpdatedAt = time.UnixMilli(updatedAt)
+	return &inst, nil
+}
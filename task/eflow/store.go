@@ -0,0 +1,120 @@
+package eflow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound 工作流实例不存在
+	ErrNotFound = errors.New("eflow: instance not found")
+	// ErrExists 工作流实例已经存在
+	ErrExists = errors.New("eflow: instance already exists")
+	// ErrConflict 工作流实例已经被其他请求修改，需要重新读取后重试
+	ErrConflict = errors.New("eflow: instance version conflict")
+)
+
+// StuckCount 卡住的工作流实例数
+type StuckCount struct {
+	Workflow string
+	State    State
+	Count    int
+}
+
+// Store 工作流实例的存储
+type Store interface {
+	// Create 保存新的实例，已经存在时返回ErrExists
+	Create(ctx context.Context, inst *Instance) error
+	// Get 读取实例，不存在时返回ErrNotFound
+	Get(ctx context.Context, id string) (*Instance, error)
+	// Update 保存实例，存储中的版本不等于inst.Version-1时返回ErrConflict
+	Update(ctx context.Context, inst *Instance) error
+	// Due 返回定时器在now之前到期的运行中实例
+	Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error)
+	// Stuck 按照工作流和状态统计before之后没有状态变化的运行中实例
+	Stuck(ctx context.Context, before time.Time) ([]StuckCount, error)
+}
+
+// memoryStore 内存存储，适用于单实例应用和测试，进程退出后数据丢失
+type memoryStore struct {
+	mu        sync.RWMutex
+	instances map[string]*Instance
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() Store {
+	return &memoryStore{instances: make(map[string]*Instance)}
+}
+
+func (m *memoryStore) Create(ctx context.Context, inst *Instance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.instances[inst.ID]; ok {
+		return ErrExists
+	}
+	m.instances[inst.ID] = inst.clone()
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, id string) (*Instance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	inst, ok := m.instances[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return inst.clone(), nil
+}
+
+func (m *memoryStore) Update(ctx context.Context, inst *Instance) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.instances[inst.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if old.Version != inst.Version-1 {
+		return ErrConflict
+	}
+	m.instances[inst.ID] = inst.clone()
+	return nil
+}
+
+func (m *memoryStore) Due(ctx context.Context, now time.Time, limit int) ([]*Instance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]*Instance, 0)
+	for _, inst := range m.instances {
+		if inst.Status == StatusRunning && !inst.TimeoutAt.IsZero() && !inst.TimeoutAt.After(now) {
+			out = append(out, inst.clone())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TimeoutAt.Before(out[j].TimeoutAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *memoryStore) Stuck(ctx context.Context, before time.Time) ([]StuckCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	type key struct {
+		workflow string
+		state    State
+	}
+	counts := make(map[key]int)
+	for _, inst := range m.instances {
+		if inst.Status == StatusRunning && inst.UpdatedAt.Before(before) {
+			counts[key{inst.Workflow, inst.State}]++
+		}
+	}
+	out := make([]StuckCount, 0, len(counts))
+	for k, n := range counts {
+		out = append(out, StuckCount{Workflow: k.workflow, State: k.state, Count: n})
+	}
+	return out, nil
+}
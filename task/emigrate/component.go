@@ -0,0 +1,378 @@
+package emigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ejob"
+)
+
+// PackageName 包名
+const PackageName = "task.emigrate"
+
+// ErrLockTimeout 等待迁移锁超时，其他实例正在执行迁移
+var ErrLockTimeout = errors.New("emigrate: lock timeout")
+
+// Applied 已经执行的迁移
+type Applied struct {
+	Version   int64     `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// Status 迁移状态，在治理端口的 /migrate/status 展示
+type Status struct {
+	Name      string      `json:"name"`
+	Current   int64       `json:"current"` // 已经执行的最大版本
+	Applied   []Applied   `json:"applied"`
+	Pending   []Migration `json:"pending"`
+	DryRun    bool        `json:"dryRun"`
+	LastRunAt time.Time   `json:"lastRunAt,omitempty"`
+	LastError string      `json:"lastError,omitempty"`
+	Error     string      `json:"error,omitempty"` // 读取状态失败的原因
+}
+
+// Component 数据库迁移组件
+type Component struct {
+	name       string
+	config     *Config
+	db         *sql.DB
+	fsys       fs.FS
+	migrations []Migration
+	logger     *elog.Component
+
+	mu        sync.Mutex
+	lastRunAt time.Time
+	lastError string
+}
+
+func newComponent(name string, config *Config, db *sql.DB, fsys fs.FS, migrations []Migration, logger *elog.Component) *Component {
+	return &Component{
+		name:       name,
+		config:     config,
+		db:         db,
+		fsys:       fsys,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// Job 返回执行迁移的ejob任务，使用 --job=migrate 执行，例如 ego.New().Job(comp.Job())
+func (c *Component) Job() *ejob.Component {
+	return ejob.Job("migrate", func(ctx ejob.Context) error {
+		return c.Up(ctx.Ctx)
+	})
+}
+
+// Up 获取锁后执行所有待执行的迁移，可以作为 ego.PhaseJob 在服务启动前执行
+func (c *Component) Up(ctx context.Context) (err error) {
+	defer func() { c.record(err) }()
+	migrations, err := c.load()
+	if err != nil {
+		return err
+	}
+	return c.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := c.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		pending := plan(migrations, applied, c.config.Target)
+		if len(pending) == 0 {
+			c.logger.Info("database is up to date", elog.Int64("version", current(applied)))
+			return nil
+		}
+		for _, m := range pending {
+			if c.config.DryRun {
+				c.logger.Info("dry run migration", elog.Int64("version", m.Version), elog.FieldName(m.Name), elog.Any("statements", statements(m.Up)))
+				continue
+			}
+			if err := c.apply(ctx, conn, m.Version, m.Name, m.Up, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近执行的steps个迁移
+func (c *Component) Down(ctx context.Context, steps int) (err error) {
+	defer func() { c.record(err) }()
+	migrations, err := c.load()
+	if err != nil {
+		return err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	return c.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := c.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			m, ok := byVersion[applied[i].Version]
+			if !ok || m.Down == "" {
+				return fmt.Errorf("emigrate: version %d has no down file", applied[i].Version)
+			}
+			if c.config.DryRun {
+				c.logger.Info("dry run rollback", elog.Int64("version", m.Version), elog.FieldName(m.Name), elog.Any("statements", statements(m.Down)))
+				continue
+			}
+			if err := c.apply(ctx, conn, m.Version, m.Name, m.Down, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status 返回当前版本和待执行的迁移
+func (c *Component) Status(ctx context.Context) (*Status, error) {
+	c.mu.Lock()
+	status := &Status{Name: c.name, DryRun: c.config.DryRun, LastRunAt: c.lastRunAt, LastError: c.lastError}
+	c.mu.Unlock()
+	migrations, err := c.load()
+	if err != nil {
+		return status, err
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return status, err
+	}
+	defer conn.Close()
+	if err := c.ensureTable(ctx, conn); err != nil {
+		return status, err
+	}
+	applied, err := c.applied(ctx, conn)
+	if err != nil {
+		return status, err
+	}
+	status.Applied = applied
+	status.Current = current(applied)
+	status.Pending = plan(migrations, applied, c.config.Target)
+	return status, nil
+}
+
+func (c *Component) load() ([]Migration, error) {
+	if c.migrations != nil {
+		return c.migrations, nil
+	}
+	return Parse(c.fsys, c.config.Dir)
+}
+
+func (c *Component) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastRunAt = time.Now()
+	c.lastError = ""
+	if err != nil {
+		c.lastError = err.Error()
+	}
+}
+
+// plan 返回没有执行过的迁移，target大于0时只返回不超过target的版本
+func plan(migrations []Migration, applied []Applied, target int64) []Migration {
+	done := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	pending := make([]Migration, 0)
+	for _, m := range migrations {
+		if done[m.Version] || (target > 0 && m.Version > target) {
+			continue
+		}
+		pending = append(pending, m)
+	}
+	return pending
+}
+
+func current(applied []Applied) int64 {
+	if len(applied) == 0 {
+		return 0
+	}
+	return applied[len(applied)-1].Version
+}
+
+// withLock 在同一个连接上加锁、执行迁移、释放锁，advisory lock属于数据库会话
+func (c *Component) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("emigrate: get conn fail, %w", err)
+	}
+	defer conn.Close()
+	if err := c.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.unlock(conn); err != nil {
+			c.logger.Error("release migrate lock fail", elog.FieldErr(err))
+		}
+	}()
+	if err := c.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (c *Component) lock(ctx context.Context, conn *sql.Conn) error {
+	if c.config.Dialect == DialectPostgres {
+		// pg_advisory_lock不支持超时，使用pg_try_advisory_lock轮询
+		deadline := time.Now().Add(c.config.LockTimeout)
+		for {
+			var ok bool
+			if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockID(c.config.LockKey)).Scan(&ok); err != nil {
+				return fmt.Errorf("emigrate: lock fail, %w", err)
+			}
+			if ok {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return ErrLockTimeout
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	}
+	var ok sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", c.config.LockKey, int(c.config.LockTimeout.Seconds())).Scan(&ok); err != nil {
+		return fmt.Errorf("emigrate: lock fail, %w", err)
+	}
+	if ok.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	return nil
+}
+
+func (c *Component) unlock(conn *sql.Conn) error {
+	// 迁移的ctx可能已经取消，使用新的ctx释放锁
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if c.config.Dialect == DialectPostgres {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID(c.config.LockKey))
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", c.config.LockKey)
+	return err
+}
+
+// lockID postgres的advisory lock使用int64作为key
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+func (c *Component) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+c.config.Table+" (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("emigrate: create table %s fail, %w", c.config.Table, err)
+	}
+	return nil
+}
+
+func (c *Component) applied(ctx context.Context, conn *sql.Conn) ([]Applied, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM "+c.config.Table+" ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("emigrate: query applied versions fail, %w", err)
+	}
+	defer rows.Close()
+	out := make([]Applied, 0)
+	for rows.Next() {
+		var (
+			a         Applied
+			appliedAt int64
+		)
+		if err := rows.Scan(&a.Version, &a.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		a.AppliedAt = time.UnixMilli(appliedAt)
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// apply 在事务中执行迁移并更新版本记录，MySQL的DDL会隐式提交，失败时需要手动处理
+func (c *Component) apply(ctx context.Context, conn *sql.Conn, version int64, name, content string, up bool) error {
+	beg := time.Now()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("emigrate: begin tx fail, %w", err)
+	}
+	for _, stmt := range statements(content) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("emigrate: version %d %s fail, %w", version, name, err)
+		}
+	}
+	if up {
+		_, err = tx.ExecContext(ctx, c.bind("INSERT INTO "+c.config.Table+" (version, name, applied_at) VALUES (?, ?, ?)"), version, name, time.Now().UnixMilli())
+	} else {
+		_, err = tx.ExecContext(ctx, c.bind("DELETE FROM "+c.config.Table+" WHERE version = ?"), version)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("emigrate: update version %d fail, %w", version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("emigrate: commit version %d fail, %w", version, err)
+	}
+	c.logger.Info("migration applied", elog.Int64("version", version), elog.FieldName(name), elog.Any("up", up), elog.FieldCost(time.Since(beg)))
+	return nil
+}
+
+// bind postgres使用 $1 作为占位符
+func (c *Component) bind(query string) string {
+	if c.config.Dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []*Component
+)
+
+// Statuses 返回所有迁移组件的状态
+func Statuses(ctx context.Context) []Status {
+	registryMu.RLock()
+	comps := append([]*Component(nil), registry...)
+	registryMu.RUnlock()
+	out := make([]Status, 0, len(comps))
+	for _, comp := range comps {
+		status, err := comp.Status(ctx)
+		if err != nil {
+			status.Error = err.Error()
+		}
+		out = append(out, *status)
+	}
+	return out
+}
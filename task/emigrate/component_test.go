@@ -0,0 +1,205 @@
+package emigrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB 记录执行的语句，模拟GET_LOCK和版本表
+type fakeDB struct {
+	mu       sync.Mutex
+	executed []string
+	applied  [][]driver.Value
+	locked   bool
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { return nil }
+func (c *fakeConn) Rollback() error           { return nil }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO schema_migrations"):
+		s.db.applied = append(s.db.applied, args)
+	case strings.HasPrefix(s.query, "DELETE FROM schema_migrations"):
+		s.db.applied = s.db.applied[:len(s.db.applied)-1]
+	case strings.HasPrefix(s.query, "SELECT RELEASE_LOCK"):
+		s.db.locked = false
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	default:
+		s.db.executed = append(s.db.executed, s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	if strings.HasPrefix(s.query, "SELECT GET_LOCK") {
+		if s.db.locked {
+			return &fakeRows{cols: []string{"lock"}, rows: [][]driver.Value{{int64(0)}}}, nil
+		}
+		s.db.locked = true
+		return &fakeRows{cols: []string{"lock"}, rows: [][]driver.Value{{int64(1)}}}, nil
+	}
+	return &fakeRows{cols: []string{"version", "name", "applied_at"}, rows: append([][]driver.Value(nil), s.db.applied...)}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var testFS = fstest.MapFS{
+	"migrations/1_create_user.up.sql":   {Data: []byte("-- 用户表\nCREATE TABLE user (id BIGINT);\nCREATE INDEX idx_id ON user (id);\n")},
+	"migrations/1_create_user.down.sql": {Data: []byte("DROP TABLE user;")},
+	"migrations/2_add_name.up.sql":      {Data: []byte("ALTER TABLE user ADD name VARCHAR(64);")},
+	"migrations/README.md":              {Data: []byte("ignored")},
+}
+
+func newTestComponent(t *testing.T, config *Config) (*Component, *fakeDB) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { _ = db.Close() })
+	c := DefaultContainer()
+	c.config = config
+	return c.Build(WithDB(db), WithFS(testFS)), fake
+}
+
+func TestParse(t *testing.T) {
+	migrations, err := Parse(testFS, "migrations")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_user", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE user;", migrations[0].Down)
+	assert.Equal(t, "", migrations[1].Down)
+
+	_, err = Parse(fstest.MapFS{"m/x_bad.up.sql": {}}, "m")
+	assert.ErrorContains(t, err, "invalid version")
+	_, err = Parse(fstest.MapFS{"m/1_a.down.sql": {Data: []byte("x")}}, "m")
+	assert.ErrorContains(t, err, "no up file")
+}
+
+func TestStatements(t *testing.T) {
+	content := `CREATE TABLE a (id INT);
+-- 注释
+INSERT INTO a VALUES (1),
+  (2);
+-- +emigrate StatementBegin
+CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END;
+-- +emigrate StatementEnd
+`
+	assert.Equal(t, []string{
+		"CREATE TABLE a (id INT);",
+		"INSERT INTO a VALUES (1),\n  (2);",
+		"CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END;",
+	}, statements(content))
+}
+
+func TestUp(t *testing.T) {
+	comp, fake := newTestComponent(t, DefaultConfig())
+	ctx := context.Background()
+	require.NoError(t, comp.Up(ctx))
+	assert.Equal(t, []string{
+		"CREATE TABLE user (id BIGINT);",
+		"CREATE INDEX idx_id ON user (id);",
+		"ALTER TABLE user ADD name VARCHAR(64);",
+	}, fake.executed)
+	assert.False(t, fake.locked)
+
+	status, err := comp.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), status.Current)
+	assert.Empty(t, status.Pending)
+	assert.Empty(t, status.LastError)
+
+	// 已经是最新版本，不再执行
+	fake.executed = nil
+	require.NoError(t, comp.Up(ctx))
+	assert.Empty(t, fake.executed)
+
+	// 版本2没有down文件
+	assert.ErrorContains(t, comp.Down(ctx, 1), "version 2 has no down file")
+	migrations, err := Parse(testFS, "migrations")
+	require.NoError(t, err)
+	migrations[1].Down = "ALTER TABLE user DROP name;"
+	comp.migrations = migrations
+	require.NoError(t, comp.Down(ctx, 2))
+	assert.Equal(t, []string{"ALTER TABLE user DROP name;", "DROP TABLE user;"}, fake.executed)
+	status, err = comp.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Current)
+	assert.Len(t, status.Pending, 2)
+}
+
+func TestDryRunAndTarget(t *testing.T) {
+	config := DefaultConfig()
+	config.DryRun = true
+	config.Target = 1
+	comp, fake := newTestComponent(t, config)
+	require.NoError(t, comp.Up(context.Background()))
+	assert.Empty(t, fake.executed)
+	assert.Empty(t, fake.applied)
+
+	statuses := Statuses(context.Background())
+	status := statuses[len(statuses)-1]
+	assert.True(t, status.DryRun)
+	require.Len(t, status.Pending, 1)
+	assert.Equal(t, int64(1), status.Pending[0].Version)
+}
+
+func TestLockTimeout(t *testing.T) {
+	comp, fake := newTestComponent(t, DefaultConfig())
+	fake.locked = true
+	err := comp.Up(context.Background())
+	assert.ErrorIs(t, err, ErrLockTimeout)
+	status, _ := comp.Status(context.Background())
+	assert.Equal(t, ErrLockTimeout.Error(), status.LastError)
+}
+
+func TestBind(t *testing.T) {
+	config := DefaultConfig()
+	config.Dialect = DialectPostgres
+	comp := &Component{config: config}
+	assert.Equal(t, "DELETE FROM t WHERE version = $1", comp.bind("DELETE FROM t WHERE version = ?"))
+}
@@ -0,0 +1,36 @@
+package emigrate
+
+import (
+	"time"
+
+	"github.com/gotomicro/ego/core/util/xtime"
+)
+
+const (
+	// DialectMySQL 使用GET_LOCK加锁
+	DialectMySQL = "mysql"
+	// DialectPostgres 使用pg_advisory_lock加锁
+	DialectPostgres = "postgres"
+)
+
+// Config 数据库迁移配置
+type Config struct {
+	Dir         string        // 迁移文件目录，文件名格式为 <版本号>_<名称>.up.sql、<版本号>_<名称>.down.sql，默认migrations
+	Dialect     string        // 数据库方言，mysql或者postgres，默认mysql
+	Table       string        // 记录已执行版本的数据表，默认schema_migrations
+	LockKey     string        // 多个实例同时启动时，只有获取到锁的实例执行迁移，默认emigrate
+	LockTimeout time.Duration // 等待锁的超时时间，默认1m
+	Target      int64         // 迁移到的目标版本，0为最新版本
+	DryRun      bool          // 只打印需要执行的迁移，不修改数据库
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Dir:         "migrations",
+		Dialect:     DialectMySQL,
+		Table:       "schema_migrations",
+		LockKey:     "emigrate",
+		LockTimeout: xtime.Duration("1m"),
+	}
+}
@@ -0,0 +1,82 @@
+package emigrate
+
+import (
+	"database/sql"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// Container 容器
+type Container struct {
+	config     *Config
+	name       string
+	logger     *elog.Component
+	db         *sql.DB
+	fsys       fs.FS
+	migrations []Migration
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		fsys:   os.DirFS("."),
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// WithDB 设置执行迁移的数据库连接
+func WithDB(db *sql.DB) Option {
+	return func(c *Container) {
+		c.db = db
+	}
+}
+
+// WithFS 从fs中读取迁移文件，例如使用embed打包到二进制中，默认读取当前目录
+func WithFS(fsys fs.FS) Option {
+	return func(c *Container) {
+		c.fsys = fsys
+	}
+}
+
+// WithMigrations 直接设置迁移，设置后不再读取迁移文件
+func WithMigrations(migrations ...Migration) Option {
+	return func(c *Container) {
+		c.migrations = append(c.migrations, migrations...)
+	}
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.db == nil {
+		c.logger.Panic("emigrate db can not be nil, use WithDB option")
+	}
+	sort.Slice(c.migrations, func(i, j int) bool { return c.migrations[i].Version < c.migrations[j].Version })
+	comp := newComponent(c.name, c.config, c.db, c.fsys, c.migrations, c.logger)
+	registryMu.Lock()
+	registry = append(registry, comp)
+	registryMu.Unlock()
+	return comp
+}
@@ -0,0 +1,112 @@
+package emigrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// Parse 读取目录下的迁移文件，按照版本号排序
+// 文件名格式为 <版本号>_<名称>.up.sql 和 <版本号>_<名称>.down.sql，down文件可以不存在
+func Parse(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("emigrate: read dir %s fail, %w", dir, err)
+	}
+	migrations := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			direction, base = "up", strings.TrimSuffix(base, ".up")
+		case strings.HasSuffix(base, ".down"):
+			direction, base = "down", strings.TrimSuffix(base, ".down")
+		default:
+			return nil, fmt.Errorf("emigrate: file %s must end with .up.sql or .down.sql", entry.Name())
+		}
+		versionStr, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("emigrate: file %s has invalid version", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("emigrate: read file %s fail, %w", entry.Name(), err)
+		}
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("emigrate: version %d has different names %s and %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+	out := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" {
+			return nil, fmt.Errorf("emigrate: version %d has no up file", m.Version)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// statements 按照行尾的分号拆分SQL语句，跳过注释行
+// 存储过程等语句体内包含分号时，使用 -- +emigrate StatementBegin 和 -- +emigrate StatementEnd 包裹
+func statements(content string) []string {
+	var (
+		out     []string
+		buf     strings.Builder
+		inBlock bool
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" {
+			out = append(out, stmt)
+		}
+		buf.Reset()
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "-- +emigrate StatementBegin":
+			flush()
+			inBlock = true
+			continue
+		case trimmed == "-- +emigrate StatementEnd":
+			flush()
+			inBlock = false
+			continue
+		case strings.HasPrefix(trimmed, "--") || trimmed == "":
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,98 @@
+package eretention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cleanerSQL  = "sql"
+	cleanerFile = "file"
+)
+
+// Cleaner 清理过期数据
+type Cleaner interface {
+	// Clean 删除一批早于before的数据，最多删除limit条，返回删除的数量
+	// 返回的数量小于limit时认为已经清理完成
+	Clean(ctx context.Context, target Target, before time.Time, limit int) (int64, error)
+}
+
+// CleanerFunc 函数形式的Cleaner
+type CleanerFunc func(ctx context.Context, target Target, before time.Time, limit int) (int64, error)
+
+// Clean ...
+func (f CleanerFunc) Clean(ctx context.Context, target Target, before time.Time, limit int) (int64, error) {
+	return f(ctx, target, before, limit)
+}
+
+// sqlCleaner 分批删除数据表中的过期数据
+type sqlCleaner struct {
+	db      *sql.DB
+	dialect string
+}
+
+func (s *sqlCleaner) Clean(ctx context.Context, target Target, before time.Time, limit int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.query(target, limit), before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// query 生成删除语句，表名和字段来自配置，不能来自用户输入
+func (s *sqlCleaner) query(target Target, limit int) string {
+	cond := target.Column + " < ?"
+	if s.dialect == DialectPostgres {
+		cond = target.Column + " < $1"
+	}
+	if target.Where != "" {
+		cond += " AND (" + target.Where + ")"
+	}
+	if s.dialect == DialectPostgres {
+		return fmt.Sprintf("DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)", target.Table, target.Table, cond, limit)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT %d", target.Table, cond, limit)
+}
+
+// fileCleaner 删除目录中修改时间早于before的文件
+type fileCleaner struct{}
+
+func (fileCleaner) Clean(ctx context.Context, target Target, before time.Time, limit int) (int64, error) {
+	var deleted int64
+	err := filepath.WalkDir(target.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ok, _ := filepath.Match(target.Pattern, d.Name()); !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(before) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		deleted++
+		if deleted >= int64(limit) {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,135 @@
+package eretention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/core/emetric"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// PackageName 包名
+const PackageName = "task.eretention"
+
+// Component 按照配置定时清理过期数据
+type Component struct {
+	name     string
+	config   *Config
+	cleaners map[string]Cleaner
+	logger   *elog.Component
+	cron     *ecron.Component
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newComponent(name string, config *Config, cleaners map[string]Cleaner, logger *elog.Component, cronOptions []ecron.Option) *Component {
+	c := &Component{
+		name:     name,
+		config:   config,
+		cleaners: cleaners,
+		logger:   logger,
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	opts := append([]ecron.Option{ecron.WithSpec(config.Spec), ecron.WithJob(c.run)}, cronOptions...)
+	c.cron = ecron.DefaultContainer().Build(opts...)
+	return c
+}
+
+// Name 配置名称
+func (c *Component) Name() string {
+	return c.name
+}
+
+// PackageName 包名
+func (c *Component) PackageName() string {
+	return PackageName
+}
+
+// Init 初始化
+func (c *Component) Init() error {
+	return nil
+}
+
+// Start 启动定时任务
+func (c *Component) Start() error {
+	return c.cron.Start()
+}
+
+// Stop 停止定时任务，正在执行的清理会在当前批次结束后退出
+func (c *Component) Stop() error {
+	c.cancel()
+	return c.cron.Stop()
+}
+
+func (c *Component) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+	return c.Run(ctx)
+}
+
+// Run 立即清理所有Target，可以在ejob中手动执行
+func (c *Component) Run(ctx context.Context) error {
+	var errs []error
+	for _, target := range c.config.Targets {
+		if _, err := c.Clean(ctx, target); err != nil {
+			errs = append(errs, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Clean 分批清理一个Target，返回删除的数量
+func (c *Component) Clean(ctx context.Context, target Target) (int64, error) {
+	cleaner := c.cleaners[target.Cleaner]
+	before := time.Now().Add(-target.TTL)
+	beg := time.Now()
+	var total int64
+	for batch := 0; c.config.MaxBatches <= 0 || batch < c.config.MaxBatches; batch++ {
+		batchBeg := time.Now()
+		n, err := cleaner.Clean(ctx, target, before, target.BatchSize)
+		total += n
+		emetric.RetentionDeletedCounter.Add(float64(n), c.name, target.Name)
+		if err != nil {
+			emetric.RetentionBatchCounter.Inc(c.name, target.Name, "Error")
+			c.logger.Error("retention clean fail", elog.FieldKey(target.Name), elog.Int64("deleted", total), elog.FieldErr(err))
+			return total, err
+		}
+		emetric.RetentionBatchCounter.Inc(c.name, target.Name, "OK")
+		if n < int64(target.BatchSize) {
+			break
+		}
+		if err := c.wait(ctx, batchBeg, n); err != nil {
+			c.logger.Warn("retention clean interrupted", elog.FieldKey(target.Name), elog.Int64("deleted", total), elog.FieldErr(err))
+			return total, err
+		}
+	}
+	emetric.RetentionLastSuccessGauge.Set(float64(time.Now().Unix()), c.name, target.Name)
+	c.logger.Info("retention clean", elog.FieldKey(target.Name), elog.Int64("deleted", total), elog.FieldCost(time.Since(beg)))
+	return total, nil
+}
+
+// wait 按照RateLimit限制删除速度，ctx取消时返回错误
+func (c *Component) wait(ctx context.Context, batchBeg time.Time, deleted int64) error {
+	var delay time.Duration
+	if c.config.RateLimit > 0 {
+		delay = time.Duration(deleted)*time.Second/time.Duration(c.config.RateLimit) - time.Since(batchBeg)
+	}
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
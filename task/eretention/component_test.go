@@ -0,0 +1,123 @@
+package eretention
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gotomicro/ego/core/econf"
+)
+
+func TestLoad(t *testing.T) {
+	conf := `
+[retention]
+spec = "0 4 * * *"
+batchSize = 500
+[[retention.targets]]
+table = "audit_log"
+ttl = "720h"
+[[retention.targets]]
+path = "/tmp/exports"
+pattern = "*.csv"
+ttl = "24h"
+`
+	err := econf.LoadFromReader(bytes.NewBufferString(conf), toml.Unmarshal)
+	assert.NoError(t, err)
+	comp := Load("retention").Build(WithDB(nil))
+	assert.Equal(t, "0 4 * * *", comp.config.Spec)
+	assert.Equal(t, Target{Name: "audit_log", Cleaner: "sql", Table: "audit_log", Column: "created_at", Pattern: "*", TTL: 720 * time.Hour, BatchSize: 500}, comp.config.Targets[0])
+	assert.Equal(t, "file", comp.config.Targets[1].Cleaner)
+	assert.Equal(t, "/tmp/exports", comp.config.Targets[1].Name)
+}
+
+func TestBuildPanic(t *testing.T) {
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithTargets(Target{Table: "audit_log"}))
+	})
+	// 没有设置WithDB
+	assert.Panics(t, func() {
+		DefaultContainer().Build(WithTargets(Target{Table: "audit_log", TTL: time.Hour}))
+	})
+}
+
+func TestCleanBatches(t *testing.T) {
+	var calls, remaining = 0, 25
+	cleaner := CleanerFunc(func(ctx context.Context, target Target, before time.Time, limit int) (int64, error) {
+		calls++
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Second)
+		n := limit
+		if remaining < n {
+			n = remaining
+		}
+		remaining -= n
+		return int64(n), nil
+	})
+	comp := DefaultContainer().Build(
+		WithCleaner("custom", cleaner),
+		WithTargets(Target{Name: "custom", Cleaner: "custom", TTL: time.Hour, BatchSize: 10}),
+	)
+	deleted, err := comp.Clean(context.Background(), comp.config.Targets[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(25), deleted)
+	assert.Equal(t, 3, calls)
+
+	// MaxBatches限制单次执行的批次
+	remaining, calls = 100, 0
+	comp.config.MaxBatches = 2
+	deleted, err = comp.Clean(context.Background(), comp.config.Targets[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), deleted)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCleanRateLimit(t *testing.T) {
+	cleaner := CleanerFunc(func(ctx context.Context, target Target, before time.Time, limit int) (int64, error) {
+		return int64(limit), nil
+	})
+	comp := DefaultContainer().Build(
+		WithCleaner("custom", cleaner),
+		WithTargets(Target{Name: "custom", Cleaner: "custom", TTL: time.Hour, BatchSize: 10}),
+	)
+	comp.config.RateLimit = 100
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	deleted, err := comp.Clean(ctx, comp.config.Targets[0])
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// 每秒100条，250ms内大约删除30条
+	assert.InDelta(t, 30, deleted, 10)
+}
+
+func TestFileCleaner(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+	for _, name := range []string{"a.csv", "b.csv", "c.log", "sub/d.csv"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte("x"), 0o644))
+		assert.NoError(t, os.Chtimes(path, old, old))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new.csv"), []byte("x"), 0o644))
+
+	comp := DefaultContainer().Build(WithTargets(Target{Path: dir, Pattern: "*.csv", TTL: time.Hour, BatchSize: 2}))
+	deleted, err := comp.Clean(context.Background(), comp.config.Targets[0])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	for name, exist := range map[string]bool{"a.csv": false, "b.csv": false, "c.log": true, "sub/d.csv": false, "new.csv": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		assert.Equal(t, exist, err == nil, name)
+	}
+}
+
+func TestSQLQuery(t *testing.T) {
+	target := Target{Table: "audit_log", Column: "created_at", Where: "status = 'done'"}
+	mysql := &sqlCleaner{dialect: DialectMySQL}
+	assert.Equal(t, "DELETE FROM audit_log WHERE created_at < ? AND (status = 'done') LIMIT 100", mysql.query(target, 100))
+	postgres := &sqlCleaner{dialect: DialectPostgres}
+	assert.Equal(t, "DELETE FROM audit_log WHERE ctid IN (SELECT ctid FROM audit_log WHERE created_at < $1 AND (status = 'done') LIMIT 100)", postgres.query(target, 100))
+}
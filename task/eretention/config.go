@@ -0,0 +1,44 @@
+package eretention
+
+import (
+	"time"
+)
+
+const (
+	// DialectMySQL 使用 DELETE ... LIMIT 分批删除
+	DialectMySQL = "mysql"
+	// DialectPostgres 使用 ctid 子查询分批删除
+	DialectPostgres = "postgres"
+)
+
+// Target 一个需要清理的数据表或者目录
+type Target struct {
+	Name      string        // 名称，用于日志和监控，默认为Table或者Path
+	Cleaner   string        // 清理器，sql、file或者通过WithCleaner注册的名称，默认根据Table、Path选择
+	Table     string        // 数据表名
+	Column    string        // 时间字段，默认created_at
+	Where     string        // 额外的过滤条件，例如 status = 'done'
+	Path      string        // 目录，清理修改时间早于TTL的文件
+	Pattern   string        // 文件名匹配规则，默认 *
+	TTL       time.Duration // 数据保留时间，必填
+	BatchSize int           // 每批删除的数量，默认使用Config.BatchSize
+}
+
+// Config 数据保留配置
+type Config struct {
+	Spec       string   // 执行周期，默认每天凌晨3点执行
+	Dialect    string   // 数据库方言，mysql或者postgres，默认mysql
+	BatchSize  int      // 每批删除的数量，默认1000
+	RateLimit  int      // 每秒最多删除的数量，0为不限制
+	MaxBatches int      // 每个Target单次执行最多删除的批次，0为不限制
+	Targets    []Target // 需要清理的数据
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Spec:      "0 3 * * *",
+		Dialect:   DialectMySQL,
+		BatchSize: 1000,
+	}
+}
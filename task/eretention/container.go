@@ -0,0 +1,80 @@
+package eretention
+
+import (
+	"github.com/gotomicro/ego/core/econf"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// Container 容器
+type Container struct {
+	config      *Config
+	name        string
+	logger      *elog.Component
+	cleaners    map[string]Cleaner
+	cronOptions []ecron.Option
+}
+
+// DefaultContainer 默认容器
+func DefaultContainer() *Container {
+	return &Container{
+		config: DefaultConfig(),
+		logger: elog.EgoLogger.With(elog.FieldComponent(PackageName)),
+		cleaners: map[string]Cleaner{
+			cleanerFile: fileCleaner{},
+		},
+	}
+}
+
+// Load 加载配置key
+func Load(key string) *Container {
+	c := DefaultContainer()
+	if err := econf.UnmarshalKey(key, &c.config); err != nil {
+		c.logger.Panic("parse config error", elog.FieldErr(err), elog.FieldKey(key))
+		return c
+	}
+	c.logger = c.logger.With(elog.FieldComponentName(key))
+	c.name = key
+	return c
+}
+
+// Build 构建组件
+func (c *Container) Build(options ...Option) *Component {
+	for _, option := range options {
+		option(c)
+	}
+	if c.config.BatchSize <= 0 {
+		c.config.BatchSize = 1000
+	}
+	for i := range c.config.Targets {
+		target := &c.config.Targets[i]
+		if target.Cleaner == "" {
+			target.Cleaner = cleanerFile
+			if target.Table != "" {
+				target.Cleaner = cleanerSQL
+			}
+		}
+		if target.Name == "" {
+			target.Name = target.Table
+			if target.Name == "" {
+				target.Name = target.Path
+			}
+		}
+		if target.Column == "" {
+			target.Column = "created_at"
+		}
+		if target.Pattern == "" {
+			target.Pattern = "*"
+		}
+		if target.BatchSize <= 0 {
+			target.BatchSize = c.config.BatchSize
+		}
+		if target.TTL <= 0 {
+			c.logger.Panic("retention ttl must be positive", elog.FieldKey(target.Name))
+		}
+		if _, ok := c.cleaners[target.Cleaner]; !ok {
+			c.logger.Panic("retention cleaner not found, use WithDB or WithCleaner option", elog.FieldKey(target.Name), elog.FieldValue(target.Cleaner))
+		}
+	}
+	return newComponent(c.name, c.config, c.cleaners, c.logger, c.cronOptions)
+}
@@ -0,0 +1,38 @@
+package eretention
+
+import (
+	"database/sql"
+
+	"github.com/gotomicro/ego/task/ecron"
+)
+
+// Option 可选项
+type Option func(c *Container)
+
+// WithDB 设置清理数据表使用的数据库连接
+func WithDB(db *sql.DB) Option {
+	return func(c *Container) {
+		c.cleaners[cleanerSQL] = &sqlCleaner{db: db, dialect: c.config.Dialect}
+	}
+}
+
+// WithCleaner 注册自定义清理器，Target.Cleaner为name时使用
+func WithCleaner(name string, cleaner Cleaner) Option {
+	return func(c *Container) {
+		c.cleaners[name] = cleaner
+	}
+}
+
+// WithTargets 追加需要清理的数据
+func WithTargets(targets ...Target) Option {
+	return func(c *Container) {
+		c.config.Targets = append(c.config.Targets, targets...)
+	}
+}
+
+// WithCronOptions 设置定时任务的可选项，例如 ecron.WithLocation
+func WithCronOptions(opts ...ecron.Option) Option {
+	return func(c *Container) {
+		c.cronOptions = append(c.cronOptions, opts...)
+	}
+}